@@ -0,0 +1,150 @@
+package opik
+
+import (
+	"encoding/json"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction is the kind of change recorded in an audit entry.
+type AuditAction string
+
+// Audit actions.
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+// Audited entity types.
+const (
+	AuditEntityTrace          = "trace"
+	AuditEntitySpan           = "span"
+	AuditEntityFeedbackScore  = "feedback_score"
+	AuditEntityProject        = "project"
+	AuditEntityDataset        = "dataset"
+	AuditEntityDatasetItem    = "dataset_item"
+	AuditEntityExperiment     = "experiment"
+	AuditEntityExperimentItem = "experiment_item"
+	AuditEntityPrompt         = "prompt"
+	AuditEntityPromptVersion  = "prompt_version"
+	AuditEntityAutomationRule = "automation_rule"
+)
+
+// AuditEntry is one line of the audit log: a create, update, or delete the
+// SDK sent to Opik.
+type AuditEntry struct {
+	// Time is when the request completed.
+	Time time.Time `json:"time"`
+	// Action is create, update, or delete.
+	Action AuditAction `json:"action"`
+	// Entity is the type of entity changed, such as "dataset" or "prompt".
+	Entity string `json:"entity"`
+	// IDs are the IDs of the changed entities, when known.
+	IDs []string `json:"ids,omitempty"`
+	// Name is the name of the changed entity, for entities such as projects
+	// and datasets that are addressed by name.
+	Name string `json:"name,omitempty"`
+	// Workspace is the client's workspace.
+	Workspace string `json:"workspace,omitempty"`
+	// Caller is the function outside the SDK that made the change, and
+	// CallerLocation its file and line.
+	Caller         string `json:"caller,omitempty"`
+	CallerLocation string `json:"caller_location,omitempty"`
+	// Error is set if the request failed. Failed requests are recorded too,
+	// since they may have been applied before the error was returned.
+	Error string `json:"error,omitempty"`
+}
+
+// auditLog writes audit entries as JSON lines.
+type auditLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// WithAuditLog records every create, update, and delete the client performs
+// to w, one JSON-encoded AuditEntry per line. Use it to keep a compliance
+// trail of programmatic changes to shared prompts, datasets, and
+// experiments. Entries are written synchronously after each request; errors
+// writing to w are ignored, so w should be reliable, such as a local file.
+func WithAuditLog(w io.Writer) Option {
+	return func(o *clientOptions) {
+		if w == nil {
+			o.auditLog = nil
+			return
+		}
+		o.auditLog = &auditLog{enc: json.NewEncoder(w)}
+	}
+}
+
+// audit records a change to a single entity if the client has an audit log.
+// name is set for entities that are also addressed by name.
+func (c *Client) audit(action AuditAction, entity, id, name string, err error) {
+	if c.auditLog == nil {
+		return
+	}
+	var ids []string
+	if id != "" {
+		ids = []string{id}
+	}
+	c.auditLog.write(c.config.Workspace, action, entity, ids, name, err)
+}
+
+// auditBatch records a change to several entities of the same type.
+func (c *Client) auditBatch(action AuditAction, entity string, ids []uuid.UUID, err error) {
+	if c.auditLog == nil {
+		return
+	}
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	c.auditLog.write(c.config.Workspace, action, entity, strs, "", err)
+}
+
+func (l *auditLog) write(workspace string, action AuditAction, entity string, ids []string, name string, err error) {
+	entry := AuditEntry{
+		Time:      time.Now().UTC(),
+		Action:    action,
+		Entity:    entity,
+		IDs:       ids,
+		Name:      name,
+		Workspace: workspace,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	entry.Caller, entry.CallerLocation = auditCaller()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.enc.Encode(entry)
+}
+
+// sdkPackage is the function name prefix of this package's functions.
+const sdkPackage = "github.com/plexusone/opik-go."
+
+// auditCaller returns the first function on the stack outside this
+// package's non-test files. It is empty for changes made from the SDK's own
+// goroutines.
+func auditCaller() (function, location string) {
+	var pcs [32]uintptr
+	n := runtime.Callers(4, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		sdk := strings.HasPrefix(frame.Function, sdkPackage) && !strings.HasSuffix(frame.File, "_test.go")
+		if !sdk && !strings.HasPrefix(frame.Function, "runtime.") && frame.Function != "" {
+			return frame.Function, frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return "", ""
+		}
+	}
+}
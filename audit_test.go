@@ -0,0 +1,115 @@
+package opik
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newAuditTestClient(t *testing.T, opts ...Option) *Client {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(ts.Close)
+
+	opts = append([]Option{WithURL(ts.URL), WithAPIKey("test-key"), WithWorkspace("team"), WithProjectName("proj")}, opts...)
+	client, err := NewClient(opts...)
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	return client
+}
+
+func readAuditEntries(t *testing.T, buf *bytes.Buffer) []AuditEntry {
+	t.Helper()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid audit line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	client := newAuditTestClient(t, WithAuditLog(&buf))
+	ctx := context.Background()
+
+	trace, err := client.Trace(ctx, "audited")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	if err := trace.End(ctx); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+	if err := trace.AddFeedbackScore(ctx, "quality", 0.9, ""); err != nil {
+		t.Fatalf("AddFeedbackScore error: %v", err)
+	}
+	datasetID := uuid.NewString()
+	if err := client.DeleteDataset(ctx, datasetID); err == nil {
+		t.Fatal("expected DeleteDataset to fail")
+	}
+
+	entries := readAuditEntries(t, &buf)
+	want := []struct {
+		action AuditAction
+		entity string
+		id     string
+	}{
+		{AuditCreate, AuditEntityTrace, trace.ID()},
+		{AuditUpdate, AuditEntityTrace, trace.ID()},
+		{AuditCreate, AuditEntityFeedbackScore, trace.ID()},
+		{AuditDelete, AuditEntityDataset, datasetID},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Action != w.action || e.Entity != w.entity || len(e.IDs) != 1 || e.IDs[0] != w.id {
+			t.Errorf("entry %d = %+v, want %s %s %s", i, e, w.action, w.entity, w.id)
+		}
+		if e.Workspace != "team" {
+			t.Errorf("entry %d workspace = %q, want team", i, e.Workspace)
+		}
+		if !strings.HasSuffix(e.Caller, ".TestAuditLog") || !strings.Contains(e.CallerLocation, "audit_test.go:") {
+			t.Errorf("entry %d caller = %q at %q, want TestAuditLog", i, e.Caller, e.CallerLocation)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("entry %d has no time", i)
+		}
+	}
+	if entries[2].Name != "quality" {
+		t.Errorf("feedback score name = %q, want quality", entries[2].Name)
+	}
+	if entries[0].Error != "" || entries[3].Error == "" {
+		t.Errorf("errors = %q, %q; want only the delete to fail", entries[0].Error, entries[3].Error)
+	}
+}
+
+func TestAuditLogDisabled(t *testing.T) {
+	client := newAuditTestClient(t)
+	if client.auditLog != nil {
+		t.Fatal("audit log should be nil without WithAuditLog")
+	}
+	// Must not panic without an audit log.
+	if _, err := client.Trace(context.Background(), "unaudited"); err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+}
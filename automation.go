@@ -0,0 +1,339 @@
+package opik
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// AutomationRule is an online scoring rule created with
+// CreateAutomationRule. The Opik server runs its check on new traces and
+// records the result as a feedback score.
+type AutomationRule struct {
+	ID           string
+	Name         string
+	ProjectID    string
+	SamplingRate float64
+	// Code is the Python metric the server runs.
+	Code string
+}
+
+// RuleFilter limits an automation rule to traces whose field matches, for
+// example RuleFilter{Field: "tags", Operator: "contains", Value: "prod"}.
+// Key names the entry of a map field, such as a metadata key.
+type RuleFilter struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Key      string `json:"key,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
+
+// ruleFilterOperators are the operators the server accepts in a filter.
+var ruleFilterOperators = []string{
+	"contains", "not_contains", "starts_with", "ends_with", "=", "!=",
+	">", ">=", "<", "<=", "is_empty", "is_not_empty",
+}
+
+// AutomationRuleOption configures CreateAutomationRule.
+type AutomationRuleOption func(*automationRuleOptions)
+
+type automationRuleOptions struct {
+	name        string
+	projectName string
+	disabled    bool
+	arguments   map[string]string
+}
+
+// WithRuleName sets the rule's name. It defaults to the metric's name.
+func WithRuleName(name string) AutomationRuleOption {
+	return func(o *automationRuleOptions) {
+		o.name = name
+	}
+}
+
+// WithRuleProject sets the project whose traces the rule scores. It
+// defaults to the client's project.
+func WithRuleProject(name string) AutomationRuleOption {
+	return func(o *automationRuleOptions) {
+		o.projectName = name
+	}
+}
+
+// WithRuleDisabled creates the rule disabled, so it can be reviewed in the
+// Opik UI before it runs.
+func WithRuleDisabled() AutomationRuleOption {
+	return func(o *automationRuleOptions) {
+		o.disabled = true
+	}
+}
+
+// WithRuleArgument maps an argument of the rule's metric, "output" or
+// "reference", to a trace field, such as "output.answer" or
+// "metadata.expected". The output defaults to the trace's whole "output";
+// checks that compare against an expected value need a reference.
+func WithRuleArgument(argument, field string) AutomationRuleOption {
+	return func(o *automationRuleOptions) {
+		o.arguments[argument] = field
+	}
+}
+
+// CreateAutomationRule creates an online scoring rule that runs metric's
+// check on the Opik server, on samplingRate (0 to 1) of the new traces that
+// match every filter, so simple checks run in the backend without a
+// client-side scheduler. The metric must implement evaluation.RuleSpecer,
+// as the string, regex, and JSON checks of the heuristic package do; its
+// check is converted to an equivalent Python metric.
+//
+// Regular expressions are run by Python's re module on the server, which
+// agrees with Go's regexp for common patterns but not for every construct.
+func (c *Client) CreateAutomationRule(ctx context.Context, metric evaluation.Metric, filters []RuleFilter, samplingRate float64, opts ...AutomationRuleOption) (*AutomationRule, error) {
+	options := &automationRuleOptions{arguments: map[string]string{"output": "output"}}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	specer, ok := metric.(evaluation.RuleSpecer)
+	if !ok {
+		return nil, fmt.Errorf("%w: metric %T cannot run as an automation rule", ErrInvalidInput, metric)
+	}
+	spec := specer.RuleSpec()
+	if err := validateRule(spec, filters, samplingRate, options); err != nil {
+		return nil, err
+	}
+	code, err := ruleCode(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	name := options.name
+	if name == "" {
+		name = spec.Name
+	}
+	projectName := options.projectName
+	if projectName == "" {
+		projectName = c.projectName
+	}
+	projectID, err := c.projectID(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]any{
+		"project_id":    projectID,
+		"name":          name,
+		"sampling_rate": samplingRate,
+		"enabled":       !options.disabled,
+		"type":          "user_defined_metric_python",
+		"action":        "evaluator",
+		"code":          map[string]any{"metric": code, "arguments": options.arguments},
+	}
+	if len(filters) > 0 {
+		body["filters"] = filters
+	}
+	location, err := c.postJSON(ctx, "/v1/private/automations/evaluators", body)
+	c.audit(AuditCreate, AuditEntityAutomationRule, path.Base(location), name, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AutomationRule{
+		ID:           path.Base(location),
+		Name:         name,
+		ProjectID:    projectID.String(),
+		SamplingRate: samplingRate,
+		Code:         code,
+	}, nil
+}
+
+func validateRule(spec evaluation.RuleSpec, filters []RuleFilter, samplingRate float64, options *automationRuleOptions) error {
+	var p evaluation.Problems
+	if samplingRate < 0 || samplingRate > 1 {
+		p.Addf("sampling rate must be between 0 and 1: %v", samplingRate)
+	}
+	if _, ok := ruleChecks[spec.Kind]; !ok {
+		p.Addf("metric %q: unsupported check %q", spec.Name, spec.Kind)
+	}
+	if spec.NeedsReference && options.arguments["reference"] == "" {
+		p.Addf("metric %q compares against an expected value; map it with WithRuleArgument(\"reference\", field)", spec.Name)
+	}
+	for arg := range options.arguments {
+		if arg != "output" && arg != "reference" {
+			p.Addf("unknown rule argument %q; use \"output\" or \"reference\"", arg)
+		}
+	}
+	for i, f := range filters {
+		if f.Field == "" {
+			p.Addf("filters[%d] has no field", i)
+		}
+		if !slices.Contains(ruleFilterOperators, f.Operator) {
+			p.Addf("filters[%d] has unknown operator %q", i, f.Operator)
+		}
+	}
+	return invalidOptions(p)
+}
+
+// projectID returns the ID of the named project.
+func (c *Client) projectID(ctx context.Context, name string) (uuid.UUID, error) {
+	resp, err := c.apiClient.FindProjects(ctx, api.FindProjectsParams{Name: api.NewOptString(name)})
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	for _, p := range resp.Content {
+		if p.Name == name && p.ID.Set {
+			return p.ID.Value, nil
+		}
+	}
+	return uuid.UUID{}, fmt.Errorf("%w: project %q not found", ErrInvalidInput, name)
+}
+
+// postJSON sends body to an API path the generated client cannot encode,
+// and returns the Location header of the response.
+func (c *Client) postJSON(ctx context.Context, path string, body any) (string, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.config.URL, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		details, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return "", &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode), Details: strings.TrimSpace(string(details))}
+	}
+	return resp.Header.Get("Location"), nil
+}
+
+// ruleChecks holds the Python body of _check for each supported check kind.
+// _check returns the score and its reason for output and reference, which
+// are strings; PARAMS holds the spec's parameters.
+var ruleChecks = map[string]string{
+	"equals":      `return _compare(_fold(output) == _fold(reference))`,
+	"contains":    `return _compare(_fold(reference) in _fold(output))`,
+	"starts_with": `return _compare(_fold(output).startswith(_fold(reference)))`,
+	"ends_with":   `return _compare(_fold(output).endswith(_fold(reference)))`,
+	"contains_any": `for value in PARAMS["values"]:
+        if _fold(value) in _fold(output):
+            return 1.0, "contains: " + value
+    return 0.0, "does not contain any expected value"`,
+	"contains_all": `missing = [v for v in PARAMS["values"] if _fold(v) not in _fold(output)]
+    if not missing:
+        return 1.0, "contains all expected values"
+    found = len(PARAMS["values"]) - len(missing)
+    return found / len(PARAMS["values"]), "missing: " + ", ".join(missing)`,
+	"regex_match": `if re.search(PARAMS["pattern"], output):
+        return 1.0, "matches pattern"
+    return 0.0, "does not match pattern"`,
+	"regex_not_match": `if re.search(PARAMS["pattern"], output):
+        return 0.0, "matches pattern (unexpected)"
+    return 1.0, "does not match pattern"`,
+	"is_json": `try:
+        json.loads(output)
+    except ValueError as e:
+        return 0.0, "invalid JSON: " + str(e)
+    return 1.0, "valid JSON"`,
+	"is_json_object": `return _json_type(output, dict, "object")`,
+	"is_json_array":  `return _json_type(output, list, "array")`,
+	"json_has_keys": `try:
+        obj = json.loads(output)
+    except ValueError:
+        obj = None
+    if not isinstance(obj, dict):
+        return 0.0, "not a valid JSON object"
+    keys = PARAMS["keys"]
+    missing = [k for k in keys if k not in obj]
+    if not missing:
+        return 1.0, "has all required keys"
+    return (len(keys) - len(missing)) / len(keys), "missing keys: " + ", ".join(missing)`,
+}
+
+const ruleCodeTemplate = `# Generated by the Opik Go SDK from the %[1]q metric.
+import json
+import re
+from typing import Any
+
+from opik.evaluation.metrics import base_metric, score_result
+
+PARAMS = json.loads(%[2]s)
+
+
+def _text(value: Any) -> str:
+    if value is None:
+        return ""
+    if isinstance(value, str):
+        return value
+    return json.dumps(value)
+
+
+def _fold(value: str) -> str:
+    return value if PARAMS.get("case_sensitive") else value.lower()
+
+
+def _compare(ok: bool):
+    return (1.0, "match") if ok else (0.0, "no match")
+
+
+def _json_type(output: str, kind: type, name: str):
+    try:
+        ok = isinstance(json.loads(output), kind)
+    except ValueError:
+        ok = False
+    if ok:
+        return 1.0, "valid JSON " + name
+    return 0.0, "not a valid JSON " + name
+
+
+def _check(output: str, reference: str):
+    %[3]s
+
+
+class GoSDKMetric(base_metric.BaseMetric):
+    def __init__(self, name: str = %[4]s):
+        super().__init__(name=name)
+
+    def score(self, output: Any = None, reference: Any = None, **ignored_kwargs: Any) -> score_result.ScoreResult:
+        value, reason = _check(_text(output), _text(reference))
+        return score_result.ScoreResult(value=value, name=self.name, reason=reason)
+`
+
+// ruleCode converts a rule spec to the Python metric the server runs.
+// Strings are embedded as JSON string literals, which Python reads alike.
+func ruleCode(spec evaluation.RuleSpec) (string, error) {
+	check, ok := ruleChecks[spec.Kind]
+	if !ok {
+		return "", fmt.Errorf("%w: unsupported check %q", ErrInvalidInput, spec.Kind)
+	}
+	params := spec.Params
+	if params == nil {
+		params = map[string]any{}
+	}
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("%w: encoding rule parameters: %v", ErrInvalidInput, err)
+	}
+	return fmt.Sprintf(ruleCodeTemplate, spec.Name, pyString(string(paramsJSON)), check, pyString(spec.Name)), nil
+}
+
+// pyString quotes s as a JSON string, which is also a valid Python string
+// literal.
+func pyString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
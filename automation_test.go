@@ -0,0 +1,102 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/heuristic"
+	"github.com/plexusone/opik-go/testutil"
+)
+
+const testProjectID = "0190a7b8-0000-7000-8000-000000000001"
+
+const rulesPath = "/v1/private/automations/evaluators"
+
+// newRuleServer serves the lookup of project and accepts created rules.
+func newRuleServer(project string) *testutil.MockServer {
+	ms := testutil.NewMockServer()
+	ms.OnGet("/v1/private/projects").RespondJSON(http.StatusOK, map[string]any{
+		"content": []map[string]any{{"id": testProjectID, "name": project}},
+	})
+	ms.OnPost(rulesPath).Respond(http.StatusCreated, nil).WithHeaders(map[string]string{"Location": rulesPath + "/rule-1"})
+	return ms
+}
+
+func TestCreateAutomationRule(t *testing.T) {
+	ts := newRuleServer("support-bot")
+	defer ts.Close()
+	client, err := NewClient(WithURL(ts.URL()), WithProjectName("support-bot"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	filters := []RuleFilter{{Field: "tags", Operator: "contains", Value: "prod"}}
+	rule, err := client.CreateAutomationRule(context.Background(), heuristic.MustRegexMatch(`order #\d+`), filters, 0.25,
+		WithRuleArgument("output", "output.answer"))
+	if err != nil {
+		t.Fatalf("CreateAutomationRule error: %v", err)
+	}
+	if rule.ID != "rule-1" || rule.ProjectID != testProjectID || rule.Name != "regex_match" {
+		t.Errorf("rule = %+v", rule)
+	}
+
+	var body map[string]any
+	if reqs := ts.RequestsFor(http.MethodPost, rulesPath); len(reqs) != 1 || reqs[0].DecodeJSON(&body) != nil {
+		t.Fatalf("rule requests = %d, want 1 with a JSON body", len(reqs))
+	}
+	if body["type"] != "user_defined_metric_python" || body["project_id"] != testProjectID || body["sampling_rate"] != 0.25 || body["enabled"] != true {
+		t.Errorf("body = %v", body)
+	}
+	code := body["code"].(map[string]any)
+	if args := code["arguments"].(map[string]any); args["output"] != "output.answer" {
+		t.Errorf("arguments = %v", args)
+	}
+	metric := code["metric"].(string)
+	if !strings.Contains(metric, `re.search(PARAMS["pattern"], output)`) || !strings.Contains(metric, `order #\\\\d+`) {
+		t.Errorf("metric code does not check the pattern:\n%s", metric)
+	}
+	if f := body["filters"].([]any); len(f) != 1 || f[0].(map[string]any)["operator"] != "contains" {
+		t.Errorf("filters = %v", body["filters"])
+	}
+}
+
+func TestCreateAutomationRuleValidation(t *testing.T) {
+	client, _ := NewClient(WithURL("http://localhost"))
+	ctx := context.Background()
+
+	scorer := evaluation.NewMetricFunc("custom", nil)
+	if _, err := client.CreateAutomationRule(ctx, scorer, nil, 1); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unsupported metric error = %v, want ErrInvalidInput", err)
+	}
+
+	_, err := client.CreateAutomationRule(ctx, heuristic.NewContains(false),
+		[]RuleFilter{{Field: "tags", Operator: "like"}}, 2)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Problems) != 3 {
+		t.Errorf("error = %v, want 3 problems: rate, missing reference, operator", err)
+	}
+}
+
+func TestRuleCode(t *testing.T) {
+	for _, metric := range []evaluation.Metric{
+		heuristic.NewEquals(true),
+		heuristic.NewContainsAll([]string{"a", `quote"d`}, false),
+		heuristic.MustRegexNotMatch(`(?i)sorry`),
+		heuristic.NewIsJSONObject(),
+		heuristic.NewJSONHasKeys([]string{"answer"}),
+	} {
+		spec := metric.(evaluation.RuleSpecer).RuleSpec()
+		code, err := ruleCode(spec)
+		if err != nil {
+			t.Errorf("%s: ruleCode error: %v", spec.Name, err)
+			continue
+		}
+		if !strings.Contains(code, "class GoSDKMetric(base_metric.BaseMetric)") || !strings.Contains(code, ruleChecks[spec.Kind]) {
+			t.Errorf("%s: code is missing its check:\n%s", spec.Name, code)
+		}
+	}
+}
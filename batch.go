@@ -0,0 +1,294 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ogen-go/ogen/validate"
+
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// ItemError records the failure of a single item in a batch operation.
+type ItemError struct {
+	// Index is the position of the item in the slice passed to the batch call.
+	Index int
+	// Err is the error returned for the request that carried the item.
+	Err error
+}
+
+func (e ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e ItemError) Unwrap() error {
+	return e.Err
+}
+
+// BatchResult reports the outcome of a batch operation item by item.
+type BatchResult struct {
+	// Succeeded is the number of items written successfully.
+	Succeeded int
+	// Failed lists the items that could not be written, in index order.
+	Failed []ItemError
+}
+
+// InsertItemsResult is the result of Dataset.InsertItemsWithResult.
+type InsertItemsResult = BatchResult
+
+// Err returns nil if every item succeeded, or a *BatchError describing the failures.
+func (r *BatchResult) Err() error {
+	if r == nil || len(r.Failed) == 0 {
+		return nil
+	}
+	return &BatchError{Succeeded: r.Succeeded, Failed: r.Failed}
+}
+
+// FailedIndexes returns the indexes of the failed items.
+func (r *BatchResult) FailedIndexes() []int {
+	indexes := make([]int, len(r.Failed))
+	for i, f := range r.Failed {
+		indexes[i] = f.Index
+	}
+	return indexes
+}
+
+// BatchError is returned when some items of a batch operation fail.
+// Use errors.As to inspect which items failed.
+type BatchError struct {
+	Succeeded int
+	Failed    []ItemError
+}
+
+func (e *BatchError) Error() string {
+	msg := fmt.Sprintf("opik: %d of %d items failed", len(e.Failed), len(e.Failed)+e.Succeeded)
+	if len(e.Failed) > 0 {
+		msg += ": " + e.Failed[0].Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the errors of the failed items.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f
+	}
+	return errs
+}
+
+// AddFeedbackScores logs feedback scores for traces and spans in batches and
+// reports the outcome of each item. Items with an invalid entity ID fail
+// without being sent. The returned error is always nil today and is kept for
+// setup failures.
+func (c *Client) AddFeedbackScores(ctx context.Context, items []FeedbackBatchItem) (*BatchResult, error) {
+	result := &BatchResult{}
+
+	scores := make([]api.FeedbackScoreBatchItem, len(items))
+	var traceIndexes, spanIndexes []int
+	for i, item := range items {
+		entityUUID, err := uuid.Parse(item.EntityID)
+		if err != nil {
+			result.Failed = append(result.Failed, ItemError{Index: i, Err: fmt.Errorf("invalid %s ID: %w", item.EntityType, err)})
+			continue
+		}
+		scores[i] = api.FeedbackScoreBatchItem{
+			ID:          entityUUID,
+			ProjectName: api.NewOptString(c.projectName),
+			Name:        item.Name,
+			Value:       item.Value,
+			Source:      api.FeedbackScoreBatchItemSourceSdk,
+		}
+		if item.Reason != "" {
+			scores[i].Reason = api.NewOptString(item.Reason)
+		}
+		if item.EntityType == "span" {
+			spanIndexes = append(spanIndexes, i)
+		} else {
+			traceIndexes = append(traceIndexes, i)
+		}
+	}
+
+	size := func(i int) int { return len(items[i].Name) + len(items[i].Reason) + 128 }
+	batch := func(indexes []int) api.OptFeedbackScoreBatch {
+		req := api.FeedbackScoreBatch{Scores: make([]api.FeedbackScoreBatchItem, len(indexes))}
+		for i, j := range indexes {
+			req.Scores[i] = scores[j]
+		}
+		return api.NewOptFeedbackScoreBatch(req)
+	}
+
+	for _, part := range []*BatchResult{
+		sendInBatches(ctx, c.batchConfig, traceIndexes, size, func(ctx context.Context, indexes []int) error {
+			err := c.apiClient.ScoreBatchOfTraces(ctx, batch(indexes))
+			c.auditBatch(AuditCreate, AuditEntityFeedbackScore, scoredIDs(scores, indexes), err)
+			return err
+		}),
+		sendInBatches(ctx, c.batchConfig, spanIndexes, size, func(ctx context.Context, indexes []int) error {
+			err := c.apiClient.ScoreBatchOfSpans(ctx, batch(indexes))
+			c.auditBatch(AuditCreate, AuditEntityFeedbackScore, scoredIDs(scores, indexes), err)
+			return err
+		}),
+	} {
+		result.Succeeded += part.Succeeded
+		result.Failed = append(result.Failed, part.Failed...)
+	}
+
+	sort.Slice(result.Failed, func(i, j int) bool { return result.Failed[i].Index < result.Failed[j].Index })
+	return result, nil
+}
+
+// batchConfig controls how batch operations are split and retried.
+type batchConfig struct {
+	// maxItems is the maximum number of items per request.
+	maxItems int
+	// maxBytes is the maximum estimated payload size per request.
+	maxBytes int
+	// maxRetries is the number of retries for transient failures.
+	maxRetries int
+	// retryDelay is the initial delay between retries (doubles each retry).
+	retryDelay time.Duration
+	// rateLimits, if set, stretches retries of throttled requests to the
+	// wait the server asked for.
+	rateLimits *rateLimiter
+	// transportRetries is set when the HTTP layer retries requests, as
+	// configured by WithRetryPolicy. Chunks are then not retried again for
+	// the failures it retries.
+	transportRetries bool
+}
+
+func defaultBatchConfig() batchConfig {
+	return batchConfig{
+		maxItems:   1000,
+		maxBytes:   4 << 20,
+		maxRetries: 3,
+		retryDelay: 200 * time.Millisecond,
+	}
+}
+
+// sendInBatches sends the items at indexes in chunks that respect the item and
+// size limits. A chunk rejected as too large, or rejected by validation, is
+// split in half so that only the offending items fail. Transient failures are
+// retried for the failed chunk only.
+func sendInBatches(ctx context.Context, cfg batchConfig, indexes []int, size func(i int) int, send func(ctx context.Context, indexes []int) error) *BatchResult {
+	result := &BatchResult{}
+
+	var chunk []int
+	chunkBytes := 0
+	for _, idx := range indexes {
+		n := size(idx)
+		if len(chunk) > 0 && (len(chunk) >= cfg.maxItems || chunkBytes+n > cfg.maxBytes) {
+			sendChunk(ctx, cfg, chunk, send, 0, result)
+			chunk = nil
+			chunkBytes = 0
+		}
+		chunk = append(chunk, idx)
+		chunkBytes += n
+	}
+	if len(chunk) > 0 {
+		sendChunk(ctx, cfg, chunk, send, 0, result)
+	}
+
+	sort.Slice(result.Failed, func(i, j int) bool { return result.Failed[i].Index < result.Failed[j].Index })
+	return result
+}
+
+func sendChunk(ctx context.Context, cfg batchConfig, chunk []int, send func(ctx context.Context, indexes []int) error, attempt int, result *BatchResult) {
+	if err := ctx.Err(); err != nil {
+		failChunk(result, chunk, err)
+		return
+	}
+
+	err := send(ctx, chunk)
+	if err == nil {
+		result.Succeeded += len(chunk)
+		return
+	}
+
+	code := statusCode(err)
+	switch {
+	case len(chunk) > 1 && isSplittable(code):
+		mid := len(chunk) / 2
+		sendChunk(ctx, cfg, chunk[:mid], send, 0, result)
+		sendChunk(ctx, cfg, chunk[mid:], send, 0, result)
+	case isRetryable(err, code) && attempt < cfg.maxRetries && !(cfg.transportRetries && retriedByTransport(code)):
+		delay := cfg.retryDelay << attempt
+		if code == http.StatusTooManyRequests {
+			delay = max(delay, cfg.rateLimits.wait())
+		}
+		select {
+		case <-ctx.Done():
+			failChunk(result, chunk, ctx.Err())
+		case <-time.After(delay):
+			sendChunk(ctx, cfg, chunk, send, attempt+1, result)
+		}
+	default:
+		failChunk(result, chunk, err)
+	}
+}
+
+func failChunk(result *BatchResult, chunk []int, err error) {
+	for _, idx := range chunk {
+		result.Failed = append(result.Failed, ItemError{Index: idx, Err: err})
+	}
+}
+
+// statusCode returns the HTTP status code carried by an API error, or 0.
+func statusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	var statusErr *validate.UnexpectedStatusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	return 0
+}
+
+// isSplittable reports whether a chunk rejected with code may succeed in parts:
+// the payload was too large, or one of its items failed validation.
+func isSplittable(code int) bool {
+	switch code {
+	case http.StatusRequestEntityTooLarge, http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+// isRetryable reports whether a failed request may succeed if sent again.
+func isRetryable(err error, code int) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	switch {
+	case code == http.StatusTooManyRequests:
+		return true
+	case code >= 500:
+		return true
+	case code == 0:
+		// Transport errors (connection reset, timeouts) carry no status code.
+		return true
+	}
+	return false
+}
+
+// retriedByTransport reports whether a failure with code has already been
+// retried by the HTTP layer: a transport error, or a status it retries.
+func retriedByTransport(code int) bool {
+	return code == 0 || retryableStatus(code)
+}
+
+// scoredIDs returns the IDs of the traces or spans scored by a batch.
+func scoredIDs(scores []api.FeedbackScoreBatchItem, indexes []int) []uuid.UUID {
+	ids := make([]uuid.UUID, len(indexes))
+	for i, j := range indexes {
+		ids[i] = scores[j].ID
+	}
+	return ids
+}
@@ -0,0 +1,274 @@
+package opik
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ogen-go/ogen/validate"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+func testBatchConfig() batchConfig {
+	return batchConfig{
+		maxItems:   10,
+		maxBytes:   1 << 20,
+		maxRetries: 2,
+		retryDelay: time.Millisecond,
+	}
+}
+
+func statusErr(code int) error {
+	return &validate.UnexpectedStatusCodeError{StatusCode: code}
+}
+
+func indexesUpTo(n int) []int {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+func TestSendInBatchesChunking(t *testing.T) {
+	cfg := testBatchConfig()
+	cfg.maxItems = 3
+	cfg.maxBytes = 25
+
+	var chunks [][]int
+	send := func(_ context.Context, indexes []int) error {
+		chunks = append(chunks, append([]int(nil), indexes...))
+		return nil
+	}
+
+	result := sendInBatches(context.Background(), cfg, indexesUpTo(7), func(int) int { return 10 }, send)
+
+	if result.Succeeded != 7 || len(result.Failed) != 0 {
+		t.Errorf("result = %+v, want 7 succeeded", result)
+	}
+	want := [][]int{{0, 1}, {2, 3}, {4, 5}, {6}}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("chunks = %v, want %v", chunks, want)
+	}
+}
+
+func TestSendInBatchesSplitsRejectedChunk(t *testing.T) {
+	for _, code := range []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge} {
+		bad := 5
+		send := func(_ context.Context, indexes []int) error {
+			for _, i := range indexes {
+				if i == bad {
+					return statusErr(code)
+				}
+			}
+			return nil
+		}
+
+		result := sendInBatches(context.Background(), testBatchConfig(), indexesUpTo(8), func(int) int { return 1 }, send)
+
+		if result.Succeeded != 7 {
+			t.Errorf("code %d: Succeeded = %d, want 7", code, result.Succeeded)
+		}
+		if got := result.FailedIndexes(); !reflect.DeepEqual(got, []int{bad}) {
+			t.Errorf("code %d: FailedIndexes = %v, want [%d]", code, got, bad)
+		}
+		if statusCode(result.Failed[0].Err) != code {
+			t.Errorf("code %d: failure error = %v", code, result.Failed[0].Err)
+		}
+	}
+}
+
+func TestSendInBatchesRetriesTransientFailures(t *testing.T) {
+	attempts := 0
+	send := func(_ context.Context, indexes []int) error {
+		attempts++
+		if attempts < 3 {
+			return statusErr(http.StatusServiceUnavailable)
+		}
+		return nil
+	}
+
+	result := sendInBatches(context.Background(), testBatchConfig(), indexesUpTo(4), func(int) int { return 1 }, send)
+
+	if result.Succeeded != 4 || len(result.Failed) != 0 {
+		t.Errorf("result = %+v, want all succeeded", result)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestSendInBatchesGivesUp(t *testing.T) {
+	t.Run("after max retries", func(t *testing.T) {
+		attempts := 0
+		send := func(_ context.Context, _ []int) error {
+			attempts++
+			return statusErr(http.StatusTooManyRequests)
+		}
+		result := sendInBatches(context.Background(), testBatchConfig(), indexesUpTo(2), func(int) int { return 1 }, send)
+		if len(result.Failed) != 2 {
+			t.Errorf("len(Failed) = %d, want 2", len(result.Failed))
+		}
+		if attempts != 3 {
+			t.Errorf("attempts = %d, want 3", attempts)
+		}
+	})
+
+	t.Run("on non-retryable status", func(t *testing.T) {
+		attempts := 0
+		send := func(_ context.Context, _ []int) error {
+			attempts++
+			return statusErr(http.StatusUnauthorized)
+		}
+		result := sendInBatches(context.Background(), testBatchConfig(), indexesUpTo(2), func(int) int { return 1 }, send)
+		if len(result.Failed) != 2 || attempts != 1 {
+			t.Errorf("len(Failed) = %d, attempts = %d, want 2 and 1", len(result.Failed), attempts)
+		}
+	})
+
+	t.Run("on cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		send := func(_ context.Context, _ []int) error {
+			t.Error("send should not be called")
+			return nil
+		}
+		result := sendInBatches(ctx, testBatchConfig(), indexesUpTo(2), func(int) int { return 1 }, send)
+		if !errors.Is(result.Err(), context.Canceled) {
+			t.Errorf("Err() = %v, want context.Canceled", result.Err())
+		}
+	})
+}
+
+func TestSendInBatchesLeavesTransportRetries(t *testing.T) {
+	cfg := testBatchConfig()
+	cfg.transportRetries = true
+	for _, tc := range []struct {
+		status int
+		want   int
+	}{
+		{http.StatusServiceUnavailable, 1},
+		{http.StatusTooManyRequests, 1},
+		{http.StatusNotImplemented, 3},
+	} {
+		attempts := 0
+		send := func(_ context.Context, _ []int) error {
+			attempts++
+			return statusErr(tc.status)
+		}
+		sendInBatches(context.Background(), cfg, indexesUpTo(2), func(int) int { return 1 }, send)
+		if attempts != tc.want {
+			t.Errorf("status %d: attempts = %d, want %d", tc.status, attempts, tc.want)
+		}
+	}
+}
+
+func TestBatchRetriedAtOneLayer(t *testing.T) {
+	ms := testutil.NewMockServer()
+	defer ms.Close()
+	ms.InjectFaults(testutil.Always(testutil.Fault{Status: http.StatusServiceUnavailable}))
+
+	client, err := NewClient(WithURL(ms.URL()), WithAPIKey("test-key"),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	client.batchConfig.maxRetries, client.batchConfig.retryDelay = 2, time.Millisecond
+
+	items := []FeedbackBatchItem{{EntityType: "trace", EntityID: uuid.NewString(), Name: "quality", Value: 1}}
+	result, err := client.AddFeedbackScores(context.Background(), items)
+	if err != nil {
+		t.Fatalf("AddFeedbackScores error: %v", err)
+	}
+	if len(result.Failed) != 1 {
+		t.Errorf("result = %+v, want the item failed", result)
+	}
+	if got := ms.RequestCount(); got != 3 {
+		t.Errorf("server got %d requests, want 1 + 2 transport retries", got)
+	}
+}
+
+func TestBatchResultErr(t *testing.T) {
+	if err := (&BatchResult{Succeeded: 3}).Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+
+	cause := errors.New("boom")
+	result := &BatchResult{Succeeded: 1, Failed: []ItemError{{Index: 2, Err: cause}}}
+	err := result.Err()
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Err() = %v, want *BatchError", err)
+	}
+	if batchErr.Error() != "opik: 1 of 2 items failed: boom" {
+		t.Errorf("Error() = %q", batchErr.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("BatchError should wrap the item errors")
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	if got := statusCode(statusErr(http.StatusConflict)); got != http.StatusConflict {
+		t.Errorf("statusCode = %d, want 409", got)
+	}
+	if got := statusCode(&APIError{StatusCode: http.StatusBadGateway}); got != http.StatusBadGateway {
+		t.Errorf("statusCode = %d, want 502", got)
+	}
+	if got := statusCode(errors.New("plain")); got != 0 {
+		t.Errorf("statusCode = %d, want 0", got)
+	}
+}
+
+// newRejectingServer returns a server that rejects any request whose body
+// contains marker with 400, and accepts everything else with 204.
+func newRejectingServer(marker string) *testutil.MockServer {
+	ms := testutil.NewMockServer()
+	ms.OnUnmatched().WithHandler(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte(marker)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return ms
+}
+
+func TestAddFeedbackScores(t *testing.T) {
+	ts := newRejectingServer("bad-score")
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	client.batchConfig = testBatchConfig()
+
+	id := uuid.NewString()
+	items := []FeedbackBatchItem{
+		{EntityType: "trace", EntityID: id, Name: "quality", Value: 1},
+		{EntityType: "span", EntityID: id, Name: "bad-score", Value: 0},
+		{EntityType: "trace", EntityID: "not-a-uuid", Name: "quality", Value: 1},
+		{EntityType: "span", EntityID: id, Name: "accuracy", Value: 0.5},
+	}
+
+	result, err := client.AddFeedbackScores(context.Background(), items)
+	if err != nil {
+		t.Fatalf("AddFeedbackScores error: %v", err)
+	}
+	if result.Succeeded != 2 {
+		t.Errorf("Succeeded = %d, want 2", result.Succeeded)
+	}
+	if got := result.FailedIndexes(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("FailedIndexes = %v, want [1 2]", got)
+	}
+}
@@ -2,6 +2,9 @@ package opik
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"sync"
 	"time"
 )
@@ -12,12 +15,22 @@ type BatcherConfig struct {
 	MaxBatchSize int
 	// FlushInterval is the maximum time to wait before flushing a batch.
 	FlushInterval time.Duration
+	// MaxFlushInterval caps the flush interval while the server is
+	// throttling requests. The interval doubles after each throttled flush
+	// and halves back toward FlushInterval after each flush that is not.
+	// Defaults to 8 times FlushInterval.
+	MaxFlushInterval time.Duration
 	// MaxRetries is the maximum number of retries for failed batches.
 	MaxRetries int
 	// RetryDelay is the initial delay between retries (doubles each retry).
 	RetryDelay time.Duration
 	// Workers is the number of background workers for processing batches.
+	// WithBatching sends from a single goroutine, so that updates follow
+	// their creates, and ignores it.
 	Workers int
+	// MaxQueueDepth is the number of writes WithBatching holds before
+	// callers block. Defaults to 10 times MaxBatchSize.
+	MaxQueueDepth int
 }
 
 // DefaultBatcherConfig returns the default batcher configuration.
@@ -67,12 +80,20 @@ type Batcher struct {
 	config   BatcherConfig
 	client   *Client
 	items    []BatchItem
+	inFlight int
 	mu       sync.Mutex
 	wg       sync.WaitGroup
 	ctx      context.Context
 	cancel   context.CancelFunc
 	itemChan chan BatchItem
 	flushCh  chan struct{}
+
+	// interval is the current flush interval, adapted to throttling.
+	interval time.Duration
+
+	// drainCtx bounds the final flush after Close cancels ctx. It is set
+	// before cancel is called, so workers see it once ctx is done.
+	drainCtx context.Context
 }
 
 // NewBatcher creates a new batcher with the given configuration.
@@ -87,6 +108,10 @@ func NewBatcher(client *Client, config BatcherConfig) *Batcher {
 		cancel:   cancel,
 		itemChan: make(chan BatchItem, config.MaxBatchSize*2),
 		flushCh:  make(chan struct{}, 1),
+		interval: config.FlushInterval,
+	}
+	if b.config.MaxFlushInterval < config.FlushInterval {
+		b.config.MaxFlushInterval = 8 * config.FlushInterval
 	}
 
 	// Start background workers
@@ -110,42 +135,58 @@ func (b *Batcher) Add(item BatchItem) {
 	}
 }
 
-// Flush forces a flush of all pending items.
+// Flush forces a flush of all pending items and waits until they are sent
+// or the timeout expires.
 func (b *Batcher) Flush(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return b.FlushContext(ctx)
+}
+
+// FlushContext forces a flush of all pending items and waits until they are
+// sent or ctx is done, in which case the returned error wraps ctx.Err().
+func (b *Batcher) FlushContext(ctx context.Context) error {
 	// Signal flush
 	select {
 	case b.flushCh <- struct{}{}:
 	default:
 	}
 
-	// Wait for items to drain
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
 	ticker := time.NewTicker(10 * time.Millisecond)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return fmt.Errorf("opik: flush: %w", ctx.Err())
 		case <-ticker.C:
 			b.mu.Lock()
-			empty := len(b.items) == 0
+			idle := len(b.items) == 0 && b.inFlight == 0
 			b.mu.Unlock()
-			if empty && len(b.itemChan) == 0 {
+			if idle && len(b.itemChan) == 0 {
 				return nil
 			}
+			// Items that reached a worker after the signal need another.
+			select {
+			case b.flushCh <- struct{}{}:
+			default:
+			}
 		}
 	}
 }
 
-// Close stops the batcher and flushes remaining items.
+// Close stops the batcher and flushes remaining items. It returns within
+// the timeout; items that could not be sent by then are dropped.
 func (b *Batcher) Close(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
 	// Flush remaining items
-	err := b.Flush(timeout)
+	err := b.FlushContext(ctx)
 
-	// Stop workers
+	// Stop workers, giving them what is left of the timeout to send any
+	// items added during the flush.
+	b.drainCtx = ctx
 	b.cancel()
 	b.wg.Wait()
 
@@ -169,11 +210,11 @@ func (b *Batcher) worker() {
 			b.mu.Unlock()
 
 			if shouldFlush {
-				b.doFlush()
+				b.doFlush(b.ctx)
 			}
 
 		case <-b.flushCh:
-			b.doFlush()
+			b.doFlush(b.ctx)
 		}
 	}
 }
@@ -181,22 +222,43 @@ func (b *Batcher) worker() {
 func (b *Batcher) flushTimer() {
 	defer b.wg.Done()
 
-	ticker := time.NewTicker(b.config.FlushInterval)
-	defer ticker.Stop()
+	timer := time.NewTimer(b.FlushInterval())
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-b.ctx.Done():
 			return
-		case <-ticker.C:
+		case <-timer.C:
 			select {
 			case b.flushCh <- struct{}{}:
 			default:
 			}
+			timer.Reset(b.FlushInterval())
 		}
 	}
 }
 
+// FlushInterval returns the current flush interval. It grows while the
+// server is throttling requests; see BatcherConfig.MaxFlushInterval.
+func (b *Batcher) FlushInterval() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.interval
+}
+
+// adaptInterval doubles the flush interval after a throttled flush and
+// halves it back toward the configured interval otherwise.
+func (b *Batcher) adaptInterval(throttled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if throttled {
+		b.interval = min(2*b.interval, b.config.MaxFlushInterval)
+	} else {
+		b.interval = max(b.interval/2, b.config.FlushInterval)
+	}
+}
+
 func (b *Batcher) drainAndFlush() {
 	// Drain channel
 	for {
@@ -206,13 +268,13 @@ func (b *Batcher) drainAndFlush() {
 			b.items = append(b.items, item)
 			b.mu.Unlock()
 		default:
-			b.doFlush()
+			b.doFlush(b.drainCtx)
 			return
 		}
 	}
 }
 
-func (b *Batcher) doFlush() {
+func (b *Batcher) doFlush(ctx context.Context) {
 	b.mu.Lock()
 	if len(b.items) == 0 {
 		b.mu.Unlock()
@@ -222,8 +284,25 @@ func (b *Batcher) doFlush() {
 	// Take items
 	items := b.items
 	b.items = make([]BatchItem, 0, b.config.MaxBatchSize)
+	b.inFlight++
 	b.mu.Unlock()
 
+	defer func() {
+		b.mu.Lock()
+		b.inFlight--
+		b.mu.Unlock()
+	}()
+
+	// Hold off while the server is throttling, then slow down or speed up
+	// later flushes depending on whether this one was throttled.
+	if !sleepContext(ctx, b.client.rateLimits.wait()) {
+		return
+	}
+	throttledBefore := b.client.rateLimits.throttled()
+	defer func() {
+		b.adaptInterval(b.client.rateLimits.throttled() > throttledBefore)
+	}()
+
 	// Group items by type
 	traceItems := make([]TraceBatchItem, 0)
 	spanItems := make([]SpanBatchItem, 0)
@@ -241,40 +320,46 @@ func (b *Batcher) doFlush() {
 	}
 
 	// Process each type with retries
-	ctx := context.Background()
-
 	if len(traceItems) > 0 {
-		b.processWithRetry(func() error {
+		b.processWithRetry(ctx, func() error {
 			return b.flushTraces(ctx, traceItems)
 		})
 	}
 
 	if len(spanItems) > 0 {
-		b.processWithRetry(func() error {
+		b.processWithRetry(ctx, func() error {
 			return b.flushSpans(ctx, spanItems)
 		})
 	}
 
 	if len(feedbackItems) > 0 {
-		b.processWithRetry(func() error {
-			return b.flushFeedback(ctx, feedbackItems)
+		// Only the items that failed are retried.
+		pending := feedbackItems
+		b.processWithRetry(ctx, func() error {
+			failed, err := b.flushFeedback(ctx, pending)
+			pending = failed
+			return err
 		})
 	}
 }
 
-func (b *Batcher) processWithRetry(fn func() error) {
+// processWithRetry calls fn until it succeeds, the retries are exhausted,
+// or ctx is done.
+func (b *Batcher) processWithRetry(ctx context.Context, fn func() error) {
 	delay := b.config.RetryDelay
 	for i := 0; i < b.config.MaxRetries; i++ {
 		err := fn()
-		if err == nil {
+		if err == nil || ctx.Err() != nil {
 			return
 		}
 
 		// Check if rate limited
-		if IsRateLimited(err) {
-			time.Sleep(delay * 2)
-		} else {
-			time.Sleep(delay)
+		wait := delay
+		if IsRateLimited(err) || statusCode(err) == http.StatusTooManyRequests {
+			wait = max(delay*2, b.client.rateLimits.wait())
+		}
+		if !sleepContext(ctx, wait) {
+			return
 		}
 		delay *= 2
 	}
@@ -301,22 +386,17 @@ func (b *Batcher) flushSpans(_ context.Context, items []SpanBatchItem) error {
 	return nil
 }
 
-func (b *Batcher) flushFeedback(_ context.Context, items []FeedbackBatchItem) error {
-	// Group by entity type
-	traceFeedback := make(map[string][]FeedbackBatchItem)
-	spanFeedback := make(map[string][]FeedbackBatchItem)
-
-	for _, item := range items {
-		if item.EntityType == "trace" {
-			traceFeedback[item.EntityID] = append(traceFeedback[item.EntityID], item)
-		} else {
-			spanFeedback[item.EntityID] = append(spanFeedback[item.EntityID], item)
-		}
+// flushFeedback sends feedback scores and returns the items that failed.
+func (b *Batcher) flushFeedback(ctx context.Context, items []FeedbackBatchItem) ([]FeedbackBatchItem, error) {
+	result, err := b.client.AddFeedbackScores(ctx, items)
+	if err != nil {
+		return items, err
 	}
-
-	// Process trace feedback - would use batch API
-	// Process span feedback - would use batch API
-	return nil
+	failed := make([]FeedbackBatchItem, 0, len(result.Failed))
+	for _, f := range result.Failed {
+		failed = append(failed, items[f.Index])
+	}
+	return failed, result.Err()
 }
 
 // BatchingClient wraps a Client with batching support.
@@ -360,9 +440,13 @@ func (c *BatchingClient) Flush(timeout time.Duration) error {
 	return c.batcher.Flush(timeout)
 }
 
-// Close closes the batching client and flushes pending operations.
+// Close flushes pending operations, stops the batcher, and closes the
+// underlying Client, all within timeout.
 func (c *BatchingClient) Close(timeout time.Duration) error {
-	return c.batcher.Close(timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := c.batcher.Close(timeout)
+	return errors.Join(err, c.Client.Close(ctx))
 }
 
 // AddFeedbackAsync adds a feedback score asynchronously via batching.
@@ -375,3 +459,18 @@ func (c *BatchingClient) AddFeedbackAsync(entityType, entityID, name string, val
 		Reason:     reason,
 	})
 }
+
+// sleepContext waits for d and reports whether it did before ctx was done.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
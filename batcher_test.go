@@ -1,8 +1,16 @@
 package opik
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/testutil"
 )
 
 func TestDefaultBatcherConfig(t *testing.T) {
@@ -133,3 +141,33 @@ func TestBatcherConfigZeroValues(t *testing.T) {
 		t.Errorf("zero Workers = %d, want 0", config.Workers)
 	}
 }
+
+func TestBatcherCloseStopsRetries(t *testing.T) {
+	testutil.WithGoroutineLeakCheck(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	client.batchConfig = testBatchConfig()
+
+	config := DefaultBatcherConfig()
+	config.RetryDelay = time.Minute
+	config.FlushInterval = time.Hour
+	batcher := NewBatcher(client, config)
+	batcher.Add(FeedbackBatchItem{EntityType: "trace", EntityID: uuid.NewString(), Name: "score", Value: 1})
+
+	start := time.Now()
+	err = batcher.Close(200 * time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Close took %v, want it to stop retrying promptly", elapsed)
+	}
+}
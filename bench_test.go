@@ -0,0 +1,204 @@
+package opik
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// nopTransport answers every request with an empty success response, so
+// benchmarks measure the SDK's own overhead rather than the network.
+type nopTransport struct{}
+
+func (nopTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		_ = req.Body.Close()
+	}
+	return &http.Response{
+		StatusCode: http.StatusNoContent,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func newBenchClient(b *testing.B) *Client {
+	b.Helper()
+	client, err := NewClient(
+		WithURL("http://opik.invalid/api"),
+		WithAPIKey("bench-key"),
+		WithHTTPClient(&http.Client{Transport: nopTransport{}}),
+		WithCapabilityCheck(false),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return client
+}
+
+var benchInput = map[string]any{
+	"messages": []map[string]any{
+		{"role": "system", "content": "You are a helpful assistant."},
+		{"role": "user", "content": "Summarize the quarterly report in three bullet points."},
+	},
+	"temperature": 0.2,
+}
+
+func BenchmarkTrace(b *testing.B) {
+	client := newBenchClient(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := client.Trace(ctx, "bench"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTraceWithPayload(b *testing.B) {
+	client := newBenchClient(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		_, err := client.Trace(ctx, "bench",
+			WithTraceInput(benchInput),
+			WithTraceMetadata(map[string]any{"env": "bench", "user_id": "u-123"}),
+			WithTraceTags("bench", "hot-path"),
+		)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSpan(b *testing.B) {
+	client := newBenchClient(b)
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := trace.Span(ctx, "step", WithSpanType(SpanTypeLLM), WithSpanModel("gpt-4o")); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSpanEnd(b *testing.B) {
+	client := newBenchClient(b)
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		b.StopTimer()
+		span, err := trace.Span(ctx, "step")
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		if err := span.End(ctx, WithSpanOutput("done")); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTraceOptions(b *testing.B) {
+	client := newBenchClient(b)
+	opts := []TraceOption{WithTraceInput(benchInput), WithTraceTags("bench")}
+	b.ReportAllocs()
+	for b.Loop() {
+		client.newTraceOptions(opts)
+	}
+}
+
+func BenchmarkSpanOptions(b *testing.B) {
+	client := newBenchClient(b)
+	opts := []SpanOption{WithSpanType(SpanTypeLLM), WithSpanModel("gpt-4o")}
+	b.ReportAllocs()
+	for b.Loop() {
+		client.newSpanOptions(opts)
+	}
+}
+
+func BenchmarkSpanOptionsWithDefaults(b *testing.B) {
+	client := newBenchClient(b)
+	client.SetDefaultSpanOptions(WithSpanTags("team-search"), WithSpanMetadata(map[string]any{"service": "api"}))
+	opts := []SpanOption{WithSpanType(SpanTypeLLM), WithSpanMetadata(map[string]any{"step": 1})}
+	b.ReportAllocs()
+	for b.Loop() {
+		client.newSpanOptions(opts)
+	}
+}
+
+// Allocation budgets for the hot tracing path. Most allocations creating a
+// trace or span are made by the generated API client and net/http; the
+// budgets leave a little headroom for those to change between Go versions
+// while catching regressions in the SDK's own code. See "Benchmarks" in
+// docs/getting-started/development.md before raising one.
+const (
+	traceOptionsAllocBudget        = 1  // the options struct
+	spanOptionsAllocBudget         = 1  // the options struct
+	spanOptionsDefaultsAllocBudget = 4  // plus merged metadata and tags
+	traceAllocBudget               = 60 // Client.Trace without payload
+	spanAllocBudget                = 60 // Trace.Span without payload
+)
+
+func TestAllocationBudgets(t *testing.T) {
+	client, err := NewClient(
+		WithURL("http://opik.invalid/api"),
+		WithAPIKey("bench-key"),
+		WithHTTPClient(&http.Client{Transport: nopTransport{}}),
+		WithCapabilityCheck(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "budget")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	traceOpts := []TraceOption{WithTraceInput(benchInput), WithTraceTags("bench")}
+	spanOpts := []SpanOption{WithSpanType(SpanTypeLLM), WithSpanModel("gpt-4o")}
+	withDefaults, _ := NewClient(WithURL("http://opik.invalid/api"), WithCapabilityCheck(false))
+	withDefaults.SetDefaultSpanOptions(WithSpanTags("team-search"), WithSpanMetadata(map[string]any{"service": "api"}))
+	defaultsOpts := []SpanOption{WithSpanMetadata(map[string]any{"step": 1})}
+
+	tests := []struct {
+		name   string
+		budget int
+		fn     func()
+	}{
+		{"trace options", traceOptionsAllocBudget, func() { client.newTraceOptions(traceOpts) }},
+		{"span options", spanOptionsAllocBudget, func() { client.newSpanOptions(spanOpts) }},
+		{"span options with defaults", spanOptionsDefaultsAllocBudget, func() { withDefaults.newSpanOptions(defaultsOpts) }},
+		{"trace", traceAllocBudget, func() { _, _ = client.Trace(ctx, "budget") }},
+		{"span", spanAllocBudget, func() { _, _ = trace.Span(ctx, "step", spanOpts...) }},
+	}
+	for _, tt := range tests {
+		if allocs := testing.AllocsPerRun(100, tt.fn); allocs > float64(tt.budget) {
+			t.Errorf("%s: %.0f allocs, budget %d", tt.name, allocs, tt.budget)
+		}
+	}
+}
+
+func BenchmarkNoopTrace(b *testing.B) {
+	ctx := context.Background()
+	tracer := NoopTracer()
+	b.ReportAllocs()
+	for b.Loop() {
+		trace, _ := tracer.Trace(ctx, "bench")
+		span, _ := trace.Span(ctx, "step")
+		_ = span.End(ctx)
+		_ = trace.End(ctx)
+	}
+}
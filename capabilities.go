@@ -0,0 +1,180 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Feature is a server feature that not every Opik version supports.
+type Feature string
+
+const (
+	// FeatureThreads groups traces into conversation threads.
+	FeatureThreads Feature = "threads"
+	// FeatureAttachments stores files attached to traces and spans.
+	FeatureAttachments Feature = "attachments"
+	// FeatureGuardrails records guardrail validations on traces.
+	FeatureGuardrails Feature = "guardrails"
+)
+
+// featureMinVersions is the first server version that supports each feature.
+var featureMinVersions = map[Feature]string{
+	FeatureAttachments: "1.4.0",
+	FeatureThreads:     "1.7.0",
+	FeatureGuardrails:  "1.7.0",
+}
+
+// UnsupportedServerError is returned when a feature is used against a server
+// that does not support it. It matches ErrUnsupportedServer with errors.Is.
+type UnsupportedServerError struct {
+	Feature       Feature
+	ServerVersion string
+	MinVersion    string
+	// Disabled is true if the server version is recent enough but the
+	// feature is turned off in the server configuration.
+	Disabled bool
+}
+
+func (e *UnsupportedServerError) Error() string {
+	if e.Disabled {
+		return fmt.Sprintf("opik: %s are disabled on this server", e.Feature)
+	}
+	return fmt.Sprintf("opik: %s require Opik server %s or later (server is %s)", e.Feature, e.MinVersion, e.ServerVersion)
+}
+
+// Is reports whether target is ErrUnsupportedServer.
+func (e *UnsupportedServerError) Is(target error) bool {
+	return target == ErrUnsupportedServer
+}
+
+// ServerCapabilities describes what the connected Opik server supports.
+type ServerCapabilities struct {
+	// Version is the server version, or empty if the server did not report one.
+	Version string
+	// GuardrailsEnabled reports whether guardrails are enabled in the server configuration.
+	GuardrailsEnabled bool
+}
+
+// Supports reports whether the server supports feature.
+// Servers that do not report a version are assumed to support everything.
+func (s *ServerCapabilities) Supports(feature Feature) bool {
+	return s.check(feature) == nil
+}
+
+func (s *ServerCapabilities) check(feature Feature) error {
+	minVersion, ok := featureMinVersions[feature]
+	if !ok || s.Version == "" {
+		return nil
+	}
+	if compareVersions(s.Version, minVersion) < 0 {
+		return &UnsupportedServerError{Feature: feature, ServerVersion: s.Version, MinVersion: minVersion}
+	}
+	if feature == FeatureGuardrails && !s.GuardrailsEnabled {
+		return &UnsupportedServerError{Feature: feature, ServerVersion: s.Version, MinVersion: minVersion, Disabled: true}
+	}
+	return nil
+}
+
+// capabilityCache holds the result of the capabilities probe.
+type capabilityCache struct {
+	mu   sync.Mutex
+	caps *ServerCapabilities
+}
+
+// Capabilities returns the capabilities of the connected server. The server is
+// queried on first use and the result is cached for the lifetime of the client.
+func (c *Client) Capabilities(ctx context.Context) (*ServerCapabilities, error) {
+	c.capabilities.mu.Lock()
+	defer c.capabilities.mu.Unlock()
+
+	if c.capabilities.caps != nil {
+		return c.capabilities.caps, nil
+	}
+
+	resp, err := c.apiClient.Version(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("opik: probing server version: %w", err)
+	}
+
+	caps := &ServerCapabilities{}
+	if resp.StatusCode == 200 {
+		var body struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(resp.Response, &body); err == nil {
+			caps.Version = body.Version
+		}
+	}
+
+	// Older servers have no toggles endpoint; guardrails are then
+	// gated by version alone.
+	if toggles, err := c.apiClient.GetServiceToggles(ctx); err == nil {
+		caps.GuardrailsEnabled = toggles.GuardrailsEnabled
+	} else {
+		caps.GuardrailsEnabled = true
+	}
+
+	c.capabilities.caps = caps
+	return caps, nil
+}
+
+// RequireFeature returns an *UnsupportedServerError if the connected server
+// does not support feature. Use it before calling newer endpoints through API()
+// to get a clear error instead of a 404 from an older self-hosted server.
+//
+// If the capabilities probe fails, or capability checks are disabled with
+// WithCapabilityCheck(false), RequireFeature returns nil and leaves the
+// decision to the server.
+func (c *Client) RequireFeature(ctx context.Context, feature Feature) error {
+	if !c.capabilityCheck {
+		return nil
+	}
+	caps, err := c.Capabilities(ctx)
+	if err != nil {
+		return nil
+	}
+	return caps.check(feature)
+}
+
+// compareVersions compares dotted version strings numerically, ignoring a
+// leading "v" and any pre-release or build suffix.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	parts := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,134 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/plexusone/opik-go/internal/api"
+	"github.com/plexusone/opik-go/testutil"
+)
+
+const probePath = "/is-alive/ver"
+
+// newVersionServer returns a server that reports version and guardrails from
+// the probe endpoints, and accepts every other request. Probes are counted
+// by the route of probePath.
+func newVersionServer(version string, guardrails bool) *testutil.MockServer {
+	ms := testutil.NewMockServer()
+	ms.OnGet(probePath).RespondJSON(http.StatusOK, map[string]string{"version": version})
+	ms.OnGet("/v1/private/toggles").RespondJSON(http.StatusOK, api.ServiceTogglesConfig{GuardrailsEnabled: guardrails})
+	ms.OnUnmatched().Respond(http.StatusNoContent, nil)
+	return ms
+}
+
+func TestCapabilities(t *testing.T) {
+	ts := newVersionServer("1.6.2", true)
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	caps, err := client.Capabilities(ctx)
+	if err != nil {
+		t.Fatalf("Capabilities error: %v", err)
+	}
+	if caps.Version != "1.6.2" || !caps.GuardrailsEnabled {
+		t.Errorf("caps = %+v", caps)
+	}
+	if !caps.Supports(FeatureAttachments) || caps.Supports(FeatureThreads) {
+		t.Errorf("Supports: attachments=%v threads=%v, want true/false",
+			caps.Supports(FeatureAttachments), caps.Supports(FeatureThreads))
+	}
+
+	err = client.RequireFeature(ctx, FeatureThreads)
+	if !errors.Is(err, ErrUnsupportedServer) {
+		t.Fatalf("RequireFeature error = %v, want ErrUnsupportedServer", err)
+	}
+	var unsupported *UnsupportedServerError
+	if !errors.As(err, &unsupported) || unsupported.MinVersion != "1.7.0" || unsupported.ServerVersion != "1.6.2" {
+		t.Errorf("error = %#v", err)
+	}
+
+	if _, err := client.Trace(ctx, "chat", WithTraceThreadID("thread-1")); !errors.Is(err, ErrUnsupportedServer) {
+		t.Errorf("Trace with thread error = %v, want ErrUnsupportedServer", err)
+	}
+	if _, err := client.Trace(ctx, "plain"); err != nil {
+		t.Errorf("Trace without thread error = %v", err)
+	}
+
+	if n := ts.RouteCallCount(http.MethodGet, probePath); n != 1 {
+		t.Errorf("probes = %d, want 1", n)
+	}
+}
+
+func TestCapabilitiesGuardrailsDisabled(t *testing.T) {
+	ts := newVersionServer("1.8.0", false)
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	if err := client.RequireFeature(context.Background(), FeatureThreads); err != nil {
+		t.Errorf("RequireFeature(threads) = %v, want nil", err)
+	}
+	err = client.RequireFeature(context.Background(), FeatureGuardrails)
+	var unsupported *UnsupportedServerError
+	if !errors.As(err, &unsupported) || !unsupported.Disabled {
+		t.Errorf("RequireFeature(guardrails) = %v, want disabled error", err)
+	}
+}
+
+func TestRequireFeatureWithoutVersion(t *testing.T) {
+	ts, _ := newRecordingServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if err := client.RequireFeature(context.Background(), FeatureThreads); err != nil {
+		t.Errorf("RequireFeature = %v, want nil for unknown version", err)
+	}
+}
+
+func TestWithCapabilityCheckDisabled(t *testing.T) {
+	ts := newVersionServer("1.0.0", true)
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithCapabilityCheck(false))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if err := client.RequireFeature(context.Background(), FeatureThreads); err != nil {
+		t.Errorf("RequireFeature = %v, want nil", err)
+	}
+	if n := ts.RouteCallCount(http.MethodGet, probePath); n != 0 {
+		t.Errorf("probes = %d, want 0", n)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.7.0", "1.7.0", 0},
+		{"1.6.9", "1.7.0", -1},
+		{"1.10.0", "1.9.3", 1},
+		{"v1.7", "1.7.0", 0},
+		{"1.7.0-rc1", "1.7.0", 0},
+		{"2", "1.99.99", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package opik
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// CaptureMode controls which parts of trace and span payloads are sent to
+// Opik. Parts that are not captured are replaced by their SHA-256 hash, so
+// identical prompts or responses can still be matched without storing
+// them. Names, metadata, tags, usage, cost, and timing are always sent, so
+// latency and cost stay observable.
+type CaptureMode int
+
+const (
+	// CaptureAll sends inputs and outputs. It is the default.
+	CaptureAll CaptureMode = iota
+	// CaptureInputOnly sends inputs and hashes outputs.
+	CaptureInputOnly
+	// CaptureOutputOnly sends outputs and hashes inputs.
+	CaptureOutputOnly
+	// CaptureMetadataOnly hashes inputs and outputs.
+	CaptureMetadataOnly
+)
+
+// String returns the mode's name, as recorded in withheld payloads.
+func (m CaptureMode) String() string {
+	switch m {
+	case CaptureAll:
+		return "all"
+	case CaptureInputOnly:
+		return "input_only"
+	case CaptureOutputOnly:
+		return "output_only"
+	case CaptureMetadataOnly:
+		return "metadata_only"
+	}
+	return "unknown"
+}
+
+// captures reports whether the mode sends inputs, or outputs if output is
+// true.
+func (m CaptureMode) captures(output bool) bool {
+	switch m {
+	case CaptureInputOnly:
+		return !output
+	case CaptureOutputOnly:
+		return output
+	case CaptureMetadataOnly:
+		return false
+	}
+	return true
+}
+
+// WithCaptureMode sets which payload parts of traces and spans are sent,
+// for deployments whose compliance rules forbid storing raw prompts or
+// responses. A withheld input or output is sent as
+//
+//	{"withheld": "metadata_only", "sha256": "<hash of the JSON payload>"}
+//
+// The hash is taken after any redactor has run. Short or guessable values
+// can be recovered from their hash by trying candidates, so redact them
+// too if that matters.
+func WithCaptureMode(mode CaptureMode) Option {
+	return func(o *clientOptions) {
+		o.config.CaptureMode = mode
+	}
+}
+
+// Arguments of marshalPayload.
+const (
+	payloadInput  = false
+	payloadOutput = true
+)
+
+// CaptureMode returns the capture mode set with WithCaptureMode.
+func (c *Client) CaptureMode() CaptureMode {
+	return c.captureMode
+}
+
+// Apply returns the JSON payload data as the mode sends it: unchanged if
+// the mode captures inputs, or outputs if output is true, and otherwise
+// replaced by its hash. Integrations that build API writes themselves use
+// it to honor the client's mode.
+func (m CaptureMode) Apply(data []byte, output bool) []byte {
+	if m.captures(output) {
+		return data
+	}
+	sum := sha256.Sum256(data)
+	withheld, _ := json.Marshal(struct {
+		Withheld string `json:"withheld"`
+		SHA256   string `json:"sha256"`
+	}{m.String(), hex.EncodeToString(sum[:])})
+	return withheld
+}
+
+// marshalPayload returns the JSON of an input or output after fn and the
+// client's redaction pipeline, or its hash if the client's capture mode
+// withholds it or it is over the client's size limit. A nil client
+// captures everything.
+func (c *Client) marshalPayload(fn RedactFunc, value any, output bool) []byte {
+	data, _ := json.Marshal(c.Redact(redact(fn, value)))
+	if c == nil {
+		return data
+	}
+	return limitPayload(c.captureMode.Apply(data, output), c.maxPayloadBytes)
+}
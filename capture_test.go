@@ -0,0 +1,86 @@
+package opik
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCaptureMode(t *testing.T) {
+	hash := func(v string) string {
+		sum := sha256.Sum256([]byte(`"` + v + `"`))
+		return hex.EncodeToString(sum[:])
+	}
+	withheld := func(mode CaptureMode, v string) any {
+		return map[string]any{"withheld": mode.String(), "sha256": hash(v)}
+	}
+
+	for _, tc := range []struct {
+		mode       CaptureMode
+		wantInput  func(string) any
+		wantOutput func(string) any
+	}{
+		{CaptureAll, nil, nil},
+		{CaptureInputOnly, nil, func(v string) any { return withheld(CaptureInputOnly, v) }},
+		{CaptureOutputOnly, func(v string) any { return withheld(CaptureOutputOnly, v) }, nil},
+		{CaptureMetadataOnly, func(v string) any { return withheld(CaptureMetadataOnly, v) }, func(v string) any { return withheld(CaptureMetadataOnly, v) }},
+	} {
+		t.Run(tc.mode.String(), func(t *testing.T) {
+			ts, created := newCreateServer()
+			defer ts.Close()
+			client, err := NewClient(WithURL(ts.URL()), WithCaptureMode(tc.mode))
+			if err != nil {
+				t.Fatalf("NewClient error: %v", err)
+			}
+			ctx := context.Background()
+
+			want := func(fn func(string) any, v string) any {
+				if fn == nil {
+					return v
+				}
+				return fn(v)
+			}
+			trace, err := client.Trace(ctx, "request", WithTraceInput("prompt"), WithTraceMetadata(map[string]any{"user": "u1"}))
+			if err != nil {
+				t.Fatalf("Trace error: %v", err)
+			}
+			got := created("POST /v1/private/traces/batch")
+			if !reflect.DeepEqual(got.Input, want(tc.wantInput, "prompt")) {
+				t.Errorf("trace input = %v, want %v", got.Input, want(tc.wantInput, "prompt"))
+			}
+			if got.Metadata["user"] != "u1" {
+				t.Errorf("trace metadata = %v, want it sent", got.Metadata)
+			}
+
+			span, err := trace.Span(ctx, "llm", WithSpanInput("span prompt"))
+			if err != nil {
+				t.Fatalf("Span error: %v", err)
+			}
+			if got := created("POST /v1/private/spans/batch"); !reflect.DeepEqual(got.Input, want(tc.wantInput, "span prompt")) {
+				t.Errorf("span input = %v, want %v", got.Input, want(tc.wantInput, "span prompt"))
+			}
+			if err := span.End(ctx, WithSpanOutput("completion")); err != nil {
+				t.Fatalf("span End error: %v", err)
+			}
+			if got := created("PATCH /v1/private/spans/batch"); !reflect.DeepEqual(got.Output, want(tc.wantOutput, "completion")) {
+				t.Errorf("span output = %v, want %v", got.Output, want(tc.wantOutput, "completion"))
+			}
+			if err := trace.End(ctx, WithTraceOutput("answer")); err != nil {
+				t.Fatalf("trace End error: %v", err)
+			}
+			if got := created("PATCH /v1/private/traces/batch"); !reflect.DeepEqual(got.Output, want(tc.wantOutput, "answer")) {
+				t.Errorf("trace output = %v, want %v", got.Output, want(tc.wantOutput, "answer"))
+			}
+		})
+	}
+}
+
+func TestWithCaptureModeValidation(t *testing.T) {
+	_, err := NewClient(WithURL("http://localhost"), WithCaptureMode(CaptureMode(9)))
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("NewClient error = %v, want ErrInvalidInput", err)
+	}
+}
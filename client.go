@@ -1,14 +1,18 @@
 package opik
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/plexusone/opik-go/cost"
 	"github.com/plexusone/opik-go/internal/api"
 )
 
@@ -22,6 +26,58 @@ type Client struct {
 
 	// Default project name for new traces
 	projectName string
+
+	// Metadata keys that are also written as filterable tags
+	indexedMetadata []string
+
+	// Limits and retries for batch operations
+	batchConfig batchConfig
+
+	// Server capabilities, probed on first use of a gated feature
+	capabilityCheck bool
+	capabilities    capabilityCache
+
+	// Prompt versions fetched by GetPromptByNameCached
+	promptCache promptCache
+
+	// Whether reasoning summaries are recorded or redacted
+	captureReasoning bool
+
+	// Which payload parts are sent rather than hashed
+	captureMode CaptureMode
+
+	// Size limit of each input and output; zero means no limit
+	maxPayloadBytes int
+
+	// Redactors applied to every trace and span payload
+	redactors []RedactFunc
+
+	// Decides which new traces are sent, if set
+	sampler Sampler
+
+	// Model prices that override registered and built-in ones
+	costTable cost.Table
+
+	// Options applied to every created trace and span
+	defaultsMu       sync.RWMutex
+	defaultTraceOpts []TraceOption
+	defaultSpanOpts  []SpanOption
+
+	// Audit trail of creates, updates, and deletes, if enabled
+	auditLog *auditLog
+
+	// Rate limit status reported by the server
+	rateLimits *rateLimiter
+
+	// Weighted routing of new traces between projects, if enabled
+	projectRouter *projectRouter
+
+	// Queue of trace and span writes sent in batches, if enabled
+	ingest *ingestQueue
+
+	// HTTP client with authentication, for requests the API client cannot
+	// make; its idle connections are closed by Close
+	http *authHTTPClient
 }
 
 // NewClient creates a new Opik client with the given options.
@@ -31,9 +87,10 @@ func NewClient(opts ...Option) (*Client, error) {
 		opt(options)
 	}
 
-	if err := options.config.Validate(); err != nil {
+	if err := options.validate(); err != nil {
 		return nil, err
 	}
+	options.applyTracing()
 
 	// Create HTTP client with auth headers
 	httpClient := options.httpClient
@@ -43,11 +100,15 @@ func NewClient(opts ...Option) (*Client, error) {
 		}
 	}
 
+	rateLimits := newRateLimiter(options.rateLimitCallback)
+
 	// Wrap with auth transport
 	authClient := &authHTTPClient{
-		client:    httpClient,
-		apiKey:    options.config.APIKey,
-		workspace: options.config.Workspace,
+		client:     httpClient,
+		apiKey:     options.config.APIKey,
+		workspace:  options.config.Workspace,
+		rateLimits: rateLimits,
+		retry:      options.retryPolicy,
 	}
 
 	// Create the ogen client
@@ -59,18 +120,71 @@ func NewClient(opts ...Option) (*Client, error) {
 		return nil, err
 	}
 
-	return &Client{
-		config:      options.config,
-		apiClient:   apiClient,
-		projectName: options.config.ProjectName,
-	}, nil
+	batchConfig := defaultBatchConfig()
+	batchConfig.rateLimits = rateLimits
+	batchConfig.transportRetries = options.retryPolicy.MaxRetries > 0
+
+	client := &Client{
+		config:           options.config,
+		apiClient:        apiClient,
+		projectName:      options.config.ProjectName,
+		indexedMetadata:  options.indexedMetadata,
+		batchConfig:      batchConfig,
+		capabilityCheck:  options.capabilityCheck,
+		captureReasoning: options.captureReasoning,
+		captureMode:      options.config.CaptureMode,
+		maxPayloadBytes:  options.config.MaxPayloadBytes,
+		redactors:        options.redactors,
+		sampler:          options.sampler,
+		costTable:        options.costTable,
+		auditLog:         options.auditLog,
+		rateLimits:       rateLimits,
+		projectRouter:    newProjectRouter(options.projectRoutes),
+		http:             authClient,
+	}
+	client.promptCache.ttl = cmp.Or(options.promptCacheTTL, DefaultPromptCacheTTL)
+	if options.batching != nil {
+		client.ingest = newIngestQueue(client, *options.batching)
+	}
+	return client, nil
+}
+
+// Close releases the client's resources: it sends the writes queued by
+// WithBatching, as Flush does, stops the goroutine that flushes them,
+// cancels the background refreshes of GetPromptByNameCached and waits for
+// them, and closes idle HTTP connections. Call it before the program exits, typically
+// with a deadline for the final flush:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := client.Close(ctx); err != nil {
+//		log.Printf("opik: %v", err)
+//	}
+//
+// Close may be called more than once. The client stays usable: writes made
+// during Close wait for its final flush, writes made after it are sent
+// synchronously, on new connections, and expired
+// cached prompts are refreshed by the call that finds them. An HTTP client
+// set with WithHTTPClient has its idle connections closed too.
+func (c *Client) Close(ctx context.Context) error {
+	var err error
+	if c.ingest != nil {
+		err = c.ingest.close(ctx)
+	}
+	c.promptCache.close()
+	if c.http != nil {
+		c.http.client.CloseIdleConnections()
+	}
+	return err
 }
 
 // authHTTPClient wraps an http.Client to add authentication headers.
 type authHTTPClient struct {
-	client    *http.Client
-	apiKey    string
-	workspace string
+	client     *http.Client
+	apiKey     string
+	workspace  string
+	rateLimits *rateLimiter
+	retry      RetryPolicy
 }
 
 // Do implements ht.Client interface.
@@ -88,7 +202,7 @@ func (c *authHTTPClient) Do(req *http.Request) (*http.Response, error) {
 	req.Header.Set("X-OPIK-DEBUG-SDK-LANG", "go")
 	// Note: Not requesting gzip as the ogen client doesn't auto-decompress
 
-	return c.client.Do(req) //nolint:gosec // G704: URL is configured by SDK user
+	return c.send(req)
 }
 
 // Config returns the client configuration.
@@ -111,23 +225,63 @@ func (c *Client) IsTracingEnabled() bool {
 	return !c.config.TracingDisabled
 }
 
-// Trace creates a new trace.
+// Trace creates a new trace. If tracing is disabled, it returns an inert
+// trace, as NoopTracer does.
 func (c *Client) Trace(ctx context.Context, name string, opts ...TraceOption) (*Trace, error) {
 	if c.config.TracingDisabled {
-		return nil, ErrTracingDisabled
+		return noopTrace, nil
 	}
 
-	options := defaultTraceOptions()
-	for _, opt := range opts {
-		opt(options)
+	options := c.newTraceOptions(opts)
+	if options.projectName == "" {
+		options.projectName = ProjectFromContext(ctx)
+	}
+
+	// Route traces without an explicit project, applying the route's
+	// options before the call-site options
+	var route *ProjectRoute
+	if options.projectName == "" {
+		if route = c.projectRouter.pick(); route != nil && len(route.TraceOptions) > 0 {
+			options = c.newTraceOptions(append(slices.Clip(route.TraceOptions), opts...))
+		}
+	}
+
+	if options.threadID == "" {
+		options.threadID = ThreadIDFromContext(ctx)
 	}
 
 	// Use default project if not specified
 	projectName := options.projectName
+	if route != nil && route.Project != "" {
+		projectName = route.Project
+	}
 	if projectName == "" {
 		projectName = c.projectName
 	}
 
+	// Sample before any request, so dropped traces cost nothing
+	var held *heldTrace
+	if c.sampler != nil && !options.sampled {
+		switch c.sampler.Sample(ctx, SamplingParams{
+			Name:        name,
+			ProjectName: projectName,
+			ThreadID:    options.threadID,
+			Metadata:    options.metadata,
+			Tags:        options.tags,
+		}) {
+		case SamplingDrop:
+			return noopTrace, nil
+		case SamplingKeepOnError:
+			held = &heldTrace{}
+		}
+	}
+
+	if options.threadID != "" {
+		if err := c.RequireFeature(ctx, FeatureThreads); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate trace ID (must be UUID v7 for Opik API)
 	traceUUID, err := uuid.NewV7()
 	if err != nil {
@@ -137,43 +291,47 @@ func (c *Client) Trace(ctx context.Context, name string, opts ...TraceOption) (*
 
 	// Prepare input/output/metadata as JSON
 	// Note: JsonListStringWrite is raw JSON bytes - use null for empty values
-	nullJSON := api.JsonListStringWrite([]byte("null"))
-	inputJSON := nullJSON
-	outputJSON := nullJSON
-	metadataJSON := nullJSON
+	inputJSON := nullJSONWrite
+	outputJSON := nullJSONWrite
+	metadataJSON := nullJSONWrite
 
 	if options.input != nil {
-		data, _ := json.Marshal(options.input)
+		data := c.marshalPayload(options.redact, options.input, payloadInput)
 		inputJSON = api.JsonListStringWrite(data)
 	}
 	if options.output != nil {
-		data, _ := json.Marshal(options.output)
+		data := c.marshalPayload(options.redact, options.output, payloadOutput)
 		outputJSON = api.JsonListStringWrite(data)
 	}
 	if len(options.metadata) > 0 {
-		data, _ := json.Marshal(options.metadata)
+		data := c.marshalMetadata(options.metadata)
 		metadataJSON = api.JsonListStringWrite(data)
 	}
 
+	tags := indexedTags(c.indexedMetadata, options.metadata, options.tags)
+
 	startTime := time.Now()
 
 	// Create trace request
-	req := api.TraceBatchWrite{
-		Traces: []api.TraceWrite{{
-			ID:          api.NewOptUUID(traceUUID),
-			ProjectName: api.NewOptString(projectName),
-			Name:        api.NewOptString(name),
-			StartTime:   startTime,
-			Input:       inputJSON,
-			Output:      outputJSON,
-			Metadata:    metadataJSON,
-			Tags:        options.tags,
-		}},
+	write := api.TraceWrite{
+		ID:          api.NewOptUUID(traceUUID),
+		ProjectName: api.NewOptString(projectName),
+		Name:        api.NewOptString(name),
+		StartTime:   startTime,
+		Input:       inputJSON,
+		Output:      outputJSON,
+		Metadata:    metadataJSON,
+		Tags:        tags,
+	}
+	if options.threadID != "" {
+		write.ThreadID = api.NewOptString(options.threadID)
+	}
+	if options.err != nil {
+		write.ErrorInfo = api.NewOptErrorInfoWrite(api.ErrorInfoWrite(c.errorInfo(options.redact, options.err)))
 	}
 
-	// Send to API
-	err = c.apiClient.CreateTraces(ctx, api.NewOptTraceBatchWrite(req))
-	if err != nil {
+	// Send to API, or queue when batching
+	if err := held.writeTrace(ctx, c, write); err != nil {
 		return nil, err
 	}
 
@@ -186,7 +344,11 @@ func (c *Client) Trace(ctx context.Context, name string, opts ...TraceOption) (*
 		input:       options.input,
 		output:      options.output,
 		metadata:    options.metadata,
-		tags:        options.tags,
+		tags:        tags,
+		sla:         options.sla,
+		redact:      options.redact,
+		route:       route,
+		held:        held,
 	}, nil
 }
 
@@ -286,6 +448,7 @@ func (c *Client) CreateProject(ctx context.Context, name string, opts ...Project
 	}
 
 	_, err := c.apiClient.CreateProject(ctx, api.NewOptProjectWrite(req))
+	c.audit(AuditCreate, AuditEntityProject, "", name, err)
 	if err != nil {
 		return nil, err
 	}
@@ -317,6 +480,12 @@ type TraceInfo struct {
 	Name      string
 	StartTime time.Time
 	EndTime   time.Time
+	Input     any
+	Output    any
+	Metadata  any
+	Tags      []string
+	// FeedbackScores are the scores logged on the trace.
+	FeedbackScores []FeedbackScoreInfo
 }
 
 // SpanInfo represents basic span information.
@@ -330,6 +499,24 @@ type SpanInfo struct {
 	EndTime      time.Time
 	Model        string
 	Provider     string
+	Input        any
+	Output       any
+	Metadata     any
+	Tags         []string
+	Usage        map[string]int
+	// FeedbackScores are the scores logged on the span.
+	FeedbackScores []FeedbackScoreInfo
+}
+
+// FeedbackScoreInfo represents a feedback score read back from Opik.
+type FeedbackScoreInfo struct {
+	Name         string
+	Value        float64
+	Reason       string
+	CategoryName string
+	// Source is where the score came from: "sdk", "ui", or
+	// "online_scoring".
+	Source string
 }
 
 // ListTraces lists recent traces.
@@ -345,24 +532,34 @@ func (c *Client) ListTraces(ctx context.Context, page, size int) ([]*TraceInfo,
 
 	traces := make([]*TraceInfo, 0, len(resp.Content))
 	for _, t := range resp.Content {
-		trace := &TraceInfo{
-			StartTime: t.StartTime,
-		}
-		if t.ID.Set {
-			trace.ID = t.ID.Value.String()
-		}
-		if t.Name.Set {
-			trace.Name = t.Name.Value
-		}
-		if t.EndTime.Set {
-			trace.EndTime = t.EndTime.Value
-		}
-		traces = append(traces, trace)
+		traces = append(traces, traceInfoFromAPI(&t))
 	}
 
 	return traces, nil
 }
 
+// traceInfoFromAPI converts a trace returned by the API.
+func traceInfoFromAPI(t *api.TracePublic) *TraceInfo {
+	trace := &TraceInfo{
+		StartTime: t.StartTime,
+	}
+	if t.ID.Set {
+		trace.ID = t.ID.Value.String()
+	}
+	if t.Name.Set {
+		trace.Name = t.Name.Value
+	}
+	if t.EndTime.Set {
+		trace.EndTime = t.EndTime.Value
+	}
+	trace.Input = decodeJSONValue(t.Input)
+	trace.Output = decodeJSONValue(t.Output)
+	trace.Metadata = decodeJSONValue(t.Metadata)
+	trace.Tags = t.Tags
+	trace.FeedbackScores = feedbackScoresFromAPI(t.FeedbackScores)
+	return trace
+}
+
 // ListSpans lists spans for a specific trace.
 func (c *Client) ListSpans(ctx context.Context, traceID string, page, size int) ([]*SpanInfo, error) {
 	traceUUID, err := uuid.Parse(traceID)
@@ -382,35 +579,82 @@ func (c *Client) ListSpans(ctx context.Context, traceID string, page, size int)
 
 	spans := make([]*SpanInfo, 0, len(resp.Content))
 	for _, s := range resp.Content {
-		span := &SpanInfo{
-			StartTime: s.StartTime,
-		}
-		if s.ID.Set {
-			span.ID = s.ID.Value.String()
-		}
-		if s.TraceID.Set {
-			span.TraceID = s.TraceID.Value.String()
-		}
-		if s.ParentSpanID.Set {
-			span.ParentSpanID = s.ParentSpanID.Value.String()
-		}
-		if s.Name.Set {
-			span.Name = s.Name.Value
-		}
-		if s.Type.Set {
-			span.Type = string(s.Type.Value)
-		}
-		if s.EndTime.Set {
-			span.EndTime = s.EndTime.Value
-		}
-		if s.Model.Set {
-			span.Model = s.Model.Value
-		}
-		if s.Provider.Set {
-			span.Provider = s.Provider.Value
-		}
-		spans = append(spans, span)
+		spans = append(spans, spanInfoFromAPI(&s))
 	}
 
 	return spans, nil
 }
+
+// spanInfoFromAPI converts a span returned by the API.
+func spanInfoFromAPI(s *api.SpanPublic) *SpanInfo {
+	span := &SpanInfo{
+		StartTime: s.StartTime,
+	}
+	if s.ID.Set {
+		span.ID = s.ID.Value.String()
+	}
+	if s.TraceID.Set {
+		span.TraceID = s.TraceID.Value.String()
+	}
+	if s.ParentSpanID.Set {
+		span.ParentSpanID = s.ParentSpanID.Value.String()
+	}
+	if s.Name.Set {
+		span.Name = s.Name.Value
+	}
+	if s.Type.Set {
+		span.Type = string(s.Type.Value)
+	}
+	if s.EndTime.Set {
+		span.EndTime = s.EndTime.Value
+	}
+	if s.Model.Set {
+		span.Model = s.Model.Value
+	}
+	if s.Provider.Set {
+		span.Provider = s.Provider.Value
+	}
+	span.Input = decodeJSONValue(s.Input)
+	span.Output = decodeJSONValue(s.Output)
+	span.Metadata = decodeJSONValue(s.Metadata)
+	span.Tags = s.Tags
+	if s.Usage.Set && len(s.Usage.Value) > 0 {
+		span.Usage = make(map[string]int, len(s.Usage.Value))
+		for k, v := range s.Usage.Value {
+			span.Usage[k] = int(v)
+		}
+	}
+	span.FeedbackScores = feedbackScoresFromAPI(s.FeedbackScores)
+	return span
+}
+
+// feedbackScoresFromAPI converts feedback scores returned by the API.
+func feedbackScoresFromAPI(scores []api.FeedbackScorePublic) []FeedbackScoreInfo {
+	if len(scores) == 0 {
+		return nil
+	}
+	infos := make([]FeedbackScoreInfo, 0, len(scores))
+	for _, s := range scores {
+		infos = append(infos, FeedbackScoreInfo{
+			Name:         s.Name,
+			Value:        s.Value,
+			Reason:       s.Reason.Or(""),
+			CategoryName: s.CategoryName.Or(""),
+			Source:       string(s.Source),
+		})
+	}
+	return infos
+}
+
+// decodeJSONValue decodes a raw JSON payload from the API, returning nil for
+// empty or null payloads.
+func decodeJSONValue(raw api.JsonListStringPublic) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return nil
+	}
+	return v
+}
@@ -2,8 +2,10 @@ package opik
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -274,9 +276,20 @@ func TestClientTraceWithTracingDisabled(t *testing.T) {
 		WithTracingDisabled(true),
 	)
 
-	_, err := client.Trace(context.Background(), "test-trace")
-	if err != ErrTracingDisabled {
-		t.Errorf("expected ErrTracingDisabled, got %v", err)
+	// Like StartTrace and the no-op tracer, Trace returns an inert trace.
+	trace, err := client.Trace(context.Background(), "test-trace")
+	if err != nil || !trace.IsNoop() {
+		t.Fatalf("Trace = %+v, %v, want a no-op trace", trace, err)
+	}
+	span, err := trace.Span(context.Background(), "test-span")
+	if err != nil || !span.IsNoop() {
+		t.Errorf("Span = %+v, %v, want a no-op span", span, err)
+	}
+	if _, span, err := client.ContinueTrace(context.Background(), DistributedTraceHeaders{TraceID: "t", ParentSpanID: "s"}, "remote"); err != nil || !span.IsNoop() {
+		t.Errorf("ContinueTrace = %+v, %v, want a no-op span", span, err)
+	}
+	if n := ms.RequestCount(); n != 0 {
+		t.Errorf("sent %d requests, want 0", n)
 	}
 }
 
@@ -387,3 +400,51 @@ func TestClientWithMatchersExample(t *testing.T) {
 		}
 	})
 }
+
+func TestClientClose(t *testing.T) {
+	var requests atomic.Int32
+	closed := make(chan struct{}, 1)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			select {
+			case closed <- struct{}{}:
+			default:
+			}
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"), WithBatching(batchingConfig()))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := client.Trace(ctx, "request"); err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+
+	if err := client.Close(ctx); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("got %d requests, want the queued trace sent by Close", got)
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Error("Close left the idle connection open")
+	}
+
+	// Close can be called again, and the client stays usable.
+	if err := client.Close(ctx); err != nil {
+		t.Errorf("second Close error: %v", err)
+	}
+	if _, err := client.Trace(ctx, "late"); err != nil {
+		t.Errorf("Trace after Close error: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// completeCommand is the hidden subcommand the completion scripts call to
+// get candidates for the word being completed.
+const completeCommand = "__complete"
+
+var completionShells = []string{"bash", "zsh", "fish"}
+
+const bashCompletion = `# bash completion for opik
+_opik() {
+    local IFS=$'\n'
+    COMPREPLY=($(opik __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _opik opik
+`
+
+const zshCompletion = `#compdef opik
+_opik() {
+    local -a candidates
+    candidates=(${(f)"$(opik __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    compadd -a candidates
+}
+compdef _opik opik
+`
+
+const fishCompletion = `# fish completion for opik
+function __opik_complete
+    set -l words (commandline -opc)[2..-1] (commandline -ct)
+    opik __complete $words 2>/dev/null
+end
+complete -c opik -f -a '(__opik_complete)'
+`
+
+func runCompletion(args []string) {
+	fs := flag.NewFlagSet("completion", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: opik completion bash|zsh|fish
+
+Load completions in the current shell:
+  bash: source <(opik completion bash)
+  zsh:  source <(opik completion zsh)
+  fish: opik completion fish | source
+`)
+	}
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing arguments: %v\n", err)
+		os.Exit(1)
+	}
+
+	script, err := completionScript(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fs.Usage()
+		os.Exit(1)
+	}
+	fmt.Print(script)
+}
+
+func completionScript(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashCompletion, nil
+	case "zsh":
+		return zshCompletion, nil
+	case "fish":
+		return fishCompletion, nil
+	default:
+		return "", fmt.Errorf("unsupported shell %q (want bash, zsh, or fish)", shell)
+	}
+}
+
+func runComplete(args []string) {
+	for _, c := range complete(args, commandNames(), commandFlags) {
+		fmt.Println(c)
+	}
+}
+
+// commandNames returns the built-in commands followed by the plugins.
+func commandNames() []string {
+	var names []string
+	for _, c := range builtinCommands() {
+		names = append(names, c.name)
+	}
+	for _, p := range findPlugins() {
+		names = append(names, p.name)
+	}
+	return names
+}
+
+// complete returns the candidates for the last of words, the arguments typed
+// after "opik" with the word being completed last. flagsFor returns the
+// flags of a command.
+func complete(words, commands []string, flagsFor func(command string) []string) []string {
+	if len(words) <= 1 {
+		current := ""
+		if len(words) == 1 {
+			current = words[0]
+		}
+		return withPrefix(commands, current)
+	}
+
+	cmd, current := words[0], words[len(words)-1]
+	switch {
+	case strings.HasPrefix(current, "-"):
+		return withPrefix(flagsFor(cmd), current)
+	case cmd == "completion" && len(words) == 2:
+		return withPrefix(completionShells, current)
+	case cmd == "traces" && len(words) == 2:
+		return withPrefix([]string{"get", "ingest"}, current)
+	case cmd == "prompts" && len(words) == 2:
+		return withPrefix([]string{"lock"}, current)
+	default:
+		return nil
+	}
+}
+
+func withPrefix(candidates []string, prefix string) []string {
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// commandFlags returns the flags of a built-in command or plugin by running
+// it with -h and reading the flag names from its usage message.
+func commandFlags(command string) []string {
+	var cmd *exec.Cmd
+	if isBuiltin(command) {
+		self, err := os.Executable()
+		if err != nil {
+			return nil
+		}
+		cmd = exec.Command(self, command, "-h") //nolint:gosec // G204: re-running this executable
+	} else if path, ok := lookupPlugin(command); ok {
+		cmd = exec.Command(path, "-h") //nolint:gosec // G204: path is a plugin found on PATH
+	} else {
+		return nil
+	}
+	// The flag package exits with status 0 after printing usage for -h,
+	// but plugins may not, so the exit status is ignored.
+	usage, _ := cmd.CombinedOutput()
+	return parseFlagNames(string(usage))
+}
+
+func isBuiltin(command string) bool {
+	for _, c := range builtinCommands() {
+		if c.name == command {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFlagNames reads flag names from a usage message in the format
+// printed by the flag package, where each flag starts an indented line:
+//
+//	-limit int
+//	    	Maximum number of traces to show
+func parseFlagNames(usage string) []string {
+	var names []string
+	scanner := bufio.NewScanner(strings.NewReader(usage))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "-") {
+			continue
+		}
+		name := strings.TrimRight(strings.Fields(line)[0], ",")
+		if name != "-" && name != "--" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
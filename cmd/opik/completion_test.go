@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	commands := []string{"traces", "datasets", "tui", "completion", "foo"}
+	flagsFor := func(cmd string) []string {
+		if cmd == "traces" {
+			return []string{"-limit", "-list", "-output"}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		words []string
+		want  []string
+	}{
+		{nil, commands},
+		{[]string{""}, commands},
+		{[]string{"t"}, []string{"traces", "tui"}},
+		{[]string{"traces", "-li"}, []string{"-limit", "-list"}},
+		{[]string{"traces", "-list", "-o"}, []string{"-output"}},
+		{[]string{"traces", "x"}, nil},
+		{[]string{"traces", "g"}, []string{"get"}},
+		{[]string{"traces", ""}, []string{"get", "ingest"}},
+		{[]string{"traces", "get", "x"}, nil},
+		{[]string{"prompts", "l"}, []string{"lock"}},
+		{[]string{"completion", "z"}, []string{"zsh"}},
+		{[]string{"completion", "zsh", ""}, nil},
+	}
+	for _, tt := range tests {
+		got := complete(tt.words, commands, flagsFor)
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("complete(%q) = %v, want %v", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestParseFlagNames(t *testing.T) {
+	usage := `Usage of traces:
+  -limit int
+    	Maximum number of traces to show (default 10)
+  -list
+    	List recent traces
+  -o string
+    	Output format (table, json, yaml) (default "table")
+`
+	got := parseFlagNames(usage)
+	if strings.Join(got, " ") != "-limit -list -o" {
+		t.Errorf("parseFlagNames = %v", got)
+	}
+}
+
+func TestCompletionScript(t *testing.T) {
+	for _, shell := range completionShells {
+		script, err := completionScript(shell)
+		if err != nil {
+			t.Fatalf("completionScript(%q) error: %v", shell, err)
+		}
+		if !strings.Contains(script, "opik "+completeCommand) {
+			t.Errorf("%s script does not call %s:\n%s", shell, completeCommand, script)
+		}
+	}
+	if _, err := completionScript("powershell"); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
+
+func TestFindPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are detected by file mode")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	writeFile := func(dir, name string, mode os.FileMode) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(first, "opik-foo", 0o755)
+	writeFile(second, "opik-foo", 0o755) // shadowed by the first directory
+	writeFile(second, "opik-bar", 0o755)
+	writeFile(second, "opik-notes.txt", 0o644) // not executable
+	writeFile(second, "opik-traces", 0o755)    // shadowed by a built-in command
+	writeFile(second, "other", 0o755)
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	plugins := findPlugins()
+	if len(plugins) != 2 {
+		t.Fatalf("plugins = %+v, want bar and foo", plugins)
+	}
+	if plugins[0].name != "bar" || plugins[1].name != "foo" || filepath.Dir(plugins[1].path) != first {
+		t.Errorf("plugins = %+v", plugins)
+	}
+
+	if path, ok := lookupPlugin("foo"); !ok || filepath.Dir(path) != first {
+		t.Errorf("lookupPlugin(foo) = %q, %v", path, ok)
+	}
+	if _, ok := lookupPlugin("../foo"); ok {
+		t.Error("lookupPlugin should reject paths")
+	}
+	if _, ok := lookupPlugin("missing"); ok {
+		t.Error("lookupPlugin(missing) should fail")
+	}
+}
+
+func TestRunPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script plugin")
+	}
+	path := filepath.Join(t.TempDir(), "opik-exit")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit \"$1\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if code := runPlugin(path, []string{"0"}); code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	if code := runPlugin(path, []string{"3"}); code != 3 {
+		t.Errorf("exit code = %d, want 3", code)
+	}
+}
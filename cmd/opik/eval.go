@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation/evalconfig"
+)
+
+// evalFlags are the flags of "opik eval" that describe the suite to run.
+type evalFlags struct {
+	config      string
+	dataset     string
+	datasetFile string
+	metrics     []string
+	threshold   *float64
+	concurrency int
+}
+
+// evalSuite returns the suite described by the flags: the -config file, or
+// one assembled from -metrics, with the dataset, threshold, and
+// concurrency flags applied on top.
+//
+// Each -metrics entry is a metric name, or the path of a YAML or JSON suite
+// file whose metrics, judge, and mapping are added, so LLM judges can be
+// configured in a file and combined with heuristic metrics by name.
+func evalSuite(f evalFlags) (*evalconfig.Suite, error) {
+	var suite *evalconfig.Suite
+	switch {
+	case f.config != "" && len(f.metrics) > 0:
+		return nil, errors.New("-config and -metrics are mutually exclusive")
+	case f.config != "":
+		s, err := evalconfig.Load(f.config)
+		if err != nil {
+			return nil, err
+		}
+		suite = s
+	case len(f.metrics) > 0:
+		s, err := metricsSuite(f.metrics)
+		if err != nil {
+			return nil, err
+		}
+		suite = s
+	default:
+		return nil, errors.New("either -config or -metrics is required")
+	}
+
+	if f.dataset != "" || f.datasetFile != "" {
+		suite.Dataset = f.dataset
+		suite.DatasetFile = ""
+		if f.datasetFile != "" {
+			// Resolve against the working directory, not the suite file.
+			path, err := filepath.Abs(f.datasetFile)
+			if err != nil {
+				return nil, err
+			}
+			suite.DatasetFile = path
+		}
+	}
+	if suite.Name == "" {
+		suite.Name = suite.Dataset
+		if suite.Name == "" && suite.DatasetFile != "" {
+			suite.Name = strings.TrimSuffix(filepath.Base(suite.DatasetFile), filepath.Ext(suite.DatasetFile))
+		}
+	}
+	if f.threshold != nil {
+		suite.Threshold = f.threshold
+	}
+	if f.concurrency > 0 {
+		suite.Concurrency = f.concurrency
+	}
+	if err := suite.Validate(); err != nil {
+		return nil, err
+	}
+	return suite, nil
+}
+
+// metricsSuite returns a suite with the metrics of a -metrics list.
+func metricsSuite(entries []string) (*evalconfig.Suite, error) {
+	suite := &evalconfig.Suite{}
+	var judgeFile string
+	for _, entry := range entries {
+		if !isSuiteFile(entry) {
+			suite.Metrics = append(suite.Metrics, evalconfig.MetricConfig{Name: entry})
+			continue
+		}
+
+		file, err := evalconfig.Load(entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range file.Metrics {
+			if m.Threshold == nil {
+				// Keep the file's default threshold with its own metrics.
+				m.Threshold = file.Threshold
+			}
+			suite.Metrics = append(suite.Metrics, m)
+		}
+		if file.Judge != (evalconfig.Judge{}) {
+			if judgeFile != "" && file.Judge != suite.Judge {
+				return nil, fmt.Errorf("%s and %s configure different judges", judgeFile, entry)
+			}
+			suite.Judge, judgeFile = file.Judge, entry
+		}
+		if file.Mapping != (evalconfig.Mapping{}) {
+			suite.Mapping = file.Mapping
+		}
+	}
+	return suite, nil
+}
+
+// isSuiteFile reports whether a -metrics entry names a suite file rather
+// than a metric.
+func isSuiteFile(entry string) bool {
+	switch strings.ToLower(filepath.Ext(entry)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseThreshold parses a -threshold value.
+func parseThreshold(dst **float64) func(string) error {
+	return func(s string) error {
+		var v float64
+		if _, err := fmt.Sscan(s, &v); err != nil {
+			return fmt.Errorf("invalid threshold %q", s)
+		}
+		*dst = &v
+		return nil
+	}
+}
+
+// saveEvalSummary writes an evaluation summary to path as indented JSON, so
+// CI pipelines can keep it as an artifact whatever the output format.
+func saveEvalSummary(path string, record evalRecord) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644) //nolint:gosec // G306: summaries are not secret
+}
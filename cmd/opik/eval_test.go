@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation/evalconfig"
+)
+
+const judgeSuiteYAML = `
+name: judges
+threshold: 0.7
+mapping:
+  input: question
+  output: answer
+judge:
+  provider: openai
+  model: gpt-4o-mini
+metrics:
+  - name: hallucination
+  - name: answer_relevance
+    threshold: 0.9
+`
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEvalSuiteFromMetrics(t *testing.T) {
+	judges := writeFile(t, "judges.yaml", judgeSuiteYAML)
+	threshold := 0.5
+	suite, err := evalSuite(evalFlags{
+		dataset:     "qa-golden",
+		metrics:     []string{"equals", judges, "contains"},
+		threshold:   &threshold,
+		concurrency: 4,
+	})
+	if err != nil {
+		t.Fatalf("evalSuite error: %v", err)
+	}
+
+	var names []string
+	var thresholds []any
+	for _, m := range suite.Metrics {
+		names = append(names, m.Name)
+		if m.Threshold == nil {
+			thresholds = append(thresholds, nil)
+		} else {
+			thresholds = append(thresholds, *m.Threshold)
+		}
+	}
+	if want := []string{"equals", "hallucination", "answer_relevance", "contains"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("metrics = %v, want %v", names, want)
+	}
+	// Metrics from the file keep its thresholds; the others use -threshold.
+	if want := []any{nil, 0.7, 0.9, nil}; !reflect.DeepEqual(thresholds, want) {
+		t.Errorf("thresholds = %v, want %v", thresholds, want)
+	}
+	if suite.Threshold == nil || *suite.Threshold != 0.5 {
+		t.Errorf("suite threshold = %v, want 0.5", suite.Threshold)
+	}
+	if suite.Name != "qa-golden" || suite.Dataset != "qa-golden" || suite.Concurrency != 4 {
+		t.Errorf("suite = %q, dataset %q, concurrency %d", suite.Name, suite.Dataset, suite.Concurrency)
+	}
+	if suite.Judge.Provider != "openai" || suite.Mapping.InputKey() != "question" {
+		t.Errorf("judge = %+v, mapping = %+v", suite.Judge, suite.Mapping)
+	}
+}
+
+func TestEvalSuiteOverridesConfig(t *testing.T) {
+	config := writeFile(t, "suite.yaml", judgeSuiteYAML+"dataset: remote\n")
+	suite, err := evalSuite(evalFlags{config: config, datasetFile: "items.jsonl"})
+	if err != nil {
+		t.Fatalf("evalSuite error: %v", err)
+	}
+	if suite.Dataset != "" || !filepath.IsAbs(suite.DatasetFile) || filepath.Base(suite.DatasetFile) != "items.jsonl" {
+		t.Errorf("dataset = %q, dataset file = %q", suite.Dataset, suite.DatasetFile)
+	}
+	if suite.Name != "judges" {
+		t.Errorf("name = %q, want the suite file's name", suite.Name)
+	}
+}
+
+func TestEvalSuiteInvalid(t *testing.T) {
+	judges := writeFile(t, "judges.yaml", judgeSuiteYAML)
+	anthropic := writeFile(t, "anthropic.yaml", strings.Replace(judgeSuiteYAML, "openai", "anthropic", 1))
+	tests := []struct {
+		name  string
+		flags evalFlags
+		want  string
+	}{
+		{"nothing to run", evalFlags{dataset: "qa"}, "-config or -metrics"},
+		{"config and metrics", evalFlags{config: judges, metrics: []string{"equals"}}, "mutually exclusive"},
+		{"two judges", evalFlags{metrics: []string{judges, anthropic}}, "different judges"},
+		{"missing file", evalFlags{metrics: []string{"missing.yaml"}}, "missing.yaml"},
+		{"both datasets", evalFlags{metrics: []string{"equals"}, dataset: "qa", datasetFile: "qa.jsonl"}, "mutually exclusive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := evalSuite(tt.flags)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveEvalSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	record := evalRecord{
+		Suite:    "qa",
+		Items:    3,
+		Summary:  map[string]float64{"equals": 0.5},
+		Failures: []evalconfig.ThresholdFailure{{Metric: "equals", Average: 0.5, Threshold: 0.8}},
+	}
+	if err := saveEvalSummary(path, record); err != nil {
+		t.Fatalf("saveEvalSummary error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got evalRecord
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(got, record) {
+		t.Errorf("saved %+v, want %+v", got, record)
+	}
+}
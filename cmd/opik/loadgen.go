@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/loadgen"
+)
+
+func runLoadgen(args []string) {
+	fs := flag.NewFlagSet("loadgen", flag.ExitOnError)
+	rate := fs.Float64("rate", 0, "Traces started per second (0 = as fast as possible)")
+	duration := fs.Duration("duration", 0, "Stop after this long (e.g. 30s)")
+	count := fs.Int("count", 0, "Stop after this many traces (default 100 without -duration)")
+	concurrency := fs.Int("concurrency", 4, "Number of traces written at once")
+	depth := fs.Int("depth", loadgen.DefaultShape.SpanDepth, "Levels of nested spans per trace")
+	fanout := fs.Int("fanout", loadgen.DefaultShape.SpansPerLevel, "Child spans per trace and per span")
+	payload := fs.Int("payload", loadgen.DefaultShape.PayloadBytes, "Approximate size of each input and output in bytes")
+	streaming := fs.Bool("streaming", false, "Write leaf LLM spans as streaming spans")
+	project := fs.String("project", "", "Project to write traces to")
+	seed := fs.Uint64("seed", 0, "Seed for generated payloads")
+	out := addOutputFlags(fs)
+	parseFlags(fs, out, args)
+
+	client, err := opik.NewClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := []loadgen.Option{
+		loadgen.WithRate(*rate),
+		loadgen.WithDuration(*duration),
+		loadgen.WithTraceCount(*count),
+		loadgen.WithConcurrency(*concurrency),
+		loadgen.WithShape(loadgen.Shape{
+			SpanDepth:     *depth,
+			SpansPerLevel: *fanout,
+			PayloadBytes:  *payload,
+			Streaming:     *streaming,
+		}),
+		loadgen.WithSeed(*seed),
+	}
+	if *project != "" {
+		opts = append(opts, loadgen.WithProject(*project))
+	}
+
+	// Stop on Ctrl-C and still print what was measured so far.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := loadgen.Run(ctx, client, opts...)
+	if report == nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	render(out, report, loadgenTable(report))
+	if report.Errors > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadgenTable returns the report as a table of measurements.
+func loadgenTable(r *loadgen.Report) *table {
+	t := keyValueTable(
+		"traces", fmt.Sprintf("%d (%.1f/s)", r.Traces, r.TracesPerSecond),
+		"spans", fmt.Sprintf("%d (%.1f/s)", r.Spans, r.SpansPerSecond),
+		"requests", fmt.Sprintf("%d (%.1f/s)", r.Requests, r.RequestsPerSecond),
+		"errors", fmt.Sprintf("%d (%.2f%%)", r.Errors, r.ErrorRate*100),
+		"payload", fmt.Sprintf("%d bytes", r.PayloadBytes),
+		"duration", r.Duration.String(),
+		"trace_latency", fmt.Sprintf("p50=%s p90=%s p99=%s max=%s",
+			r.TraceLatency.P50, r.TraceLatency.P90, r.TraceLatency.P99, r.TraceLatency.Max),
+	)
+	for _, msg := range r.ErrorSamples {
+		t.addRow("error", msg)
+	}
+	return t
+}
@@ -2,14 +2,50 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/evalconfig"
+	"github.com/plexusone/opik-go/evaluation/llm"
+	"github.com/plexusone/opik-go/integrations/anthropic"
+	"github.com/plexusone/opik-go/integrations/openai"
 )
 
+// command is a built-in subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+// builtinCommands returns the built-in subcommands in the order they are
+// listed in the usage message. Built-in commands take precedence over
+// plugins with the same name.
+func builtinCommands() []command {
+	return []command{
+		{"configure", "Configure Opik credentials", runConfigure},
+		{"projects", "Manage projects", runProjects},
+		{"traces", "View and manage traces", runTraces},
+		{"datasets", "Manage datasets", runDatasets},
+		{"experiments", "Manage experiments", runExperiments},
+		{"prompts", "Pin prompt versions in a lockfile", runPrompts},
+		{"eval", "Run an evaluation of a dataset from the terminal", runEval},
+		{"tui", "Browse traces interactively in the terminal", runTUI},
+		{"loadgen", "Generate synthetic traces to load test a server", runLoadgen},
+		{"completion", "Generate a shell completion script (bash, zsh, fish)", runCompletion},
+		{"help", "Show this help message", func([]string) { printUsage() }},
+	}
+}
+
 func main() {
 	if len(os.Args) < 2 {
 		printUsage()
@@ -19,24 +55,23 @@ func main() {
 	cmd := os.Args[1]
 	args := os.Args[2:]
 
-	switch cmd {
-	case "configure":
-		runConfigure(args)
-	case "projects":
-		runProjects(args)
-	case "traces":
-		runTraces(args)
-	case "datasets":
-		runDatasets(args)
-	case "experiments":
-		runExperiments(args)
-	case "help":
-		printUsage()
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd) //nolint:gosec // G705: CLI output to stderr, not web
-		printUsage()
-		os.Exit(1)
+	if cmd == completeCommand {
+		runComplete(args)
+		return
+	}
+	for _, c := range builtinCommands() {
+		if c.name == cmd {
+			c.run(args)
+			return
+		}
+	}
+	if path, ok := lookupPlugin(cmd); ok {
+		os.Exit(runPlugin(path, args))
 	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd) //nolint:gosec // G705: CLI output to stderr, not web
+	printUsage()
+	os.Exit(1)
 }
 
 func printUsage() {
@@ -45,14 +80,17 @@ func printUsage() {
 Usage:
   opik <command> [options]
 
-Commands:
-  configure    Configure Opik credentials
-  projects     Manage projects
-  traces       View and manage traces
-  datasets     Manage datasets
-  experiments  Manage experiments
-  help         Show this help message
-
+Commands:`)
+	for _, c := range builtinCommands() {
+		fmt.Printf("  %-12s %s\n", c.name, c.summary)
+	}
+	if plugins := findPlugins(); len(plugins) > 0 {
+		fmt.Println("\nPlugins:")
+		for _, p := range plugins {
+			fmt.Printf("  %-12s %s\n", p.name, p.path)
+		}
+	}
+	fmt.Println(`
 Use "opik <command> -h" for more information about a command.
 
 Environment Variables:
@@ -67,10 +105,8 @@ func runConfigure(args []string) {
 	apiKey := fs.String("api-key", "", "API key for Opik Cloud")
 	workspace := fs.String("workspace", "", "Workspace name")
 	url := fs.String("url", "", "Custom API endpoint URL")
-	if err := fs.Parse(args); err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing arguments: %v\n", err)
-		os.Exit(1)
-	}
+	out := addOutputFlags(fs)
+	parseFlags(fs, out, args)
 
 	// Check current configuration
 	cfg := opik.LoadConfig()
@@ -92,23 +128,20 @@ func runConfigure(args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Println("Configuration saved successfully.")
-	fmt.Printf("  URL: %s\n", cfg.URL)
-	fmt.Printf("  Workspace: %s\n", cfg.Workspace)
+	record := configRecord{URL: cfg.URL, Workspace: cfg.Workspace, APIKeySet: cfg.APIKey != ""}
+	t := keyValueTable("url", cfg.URL, "workspace", cfg.Workspace)
 	if cfg.APIKey != "" {
-		fmt.Printf("  API Key: %d characters (hidden)\n", len(cfg.APIKey))
+		t.addRow("api_key", fmt.Sprintf("%d characters (hidden)", len(cfg.APIKey)))
 	}
+	render(out, record, t)
 }
 
 func runProjects(args []string) {
 	fs := flag.NewFlagSet("projects", flag.ExitOnError)
 	list := fs.Bool("list", false, "List all projects")
 	create := fs.String("create", "", "Create a new project with the given name")
-	format := fs.String("format", "text", "Output format (text, json)")
-	if err := fs.Parse(args); err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing arguments: %v\n", err)
-		os.Exit(1)
-	}
+	out := addOutputFlags(fs)
+	parseFlags(fs, out, args)
 
 	ctx := context.Background()
 	client, err := opik.NewClient()
@@ -124,14 +157,14 @@ func runProjects(args []string) {
 			os.Exit(1)
 		}
 
-		if *format == "json" {
-			_ = json.NewEncoder(os.Stdout).Encode(projects)
-		} else {
-			fmt.Println("Projects:")
-			for _, p := range projects {
-				fmt.Printf("  - %s (ID: %s)\n", p.Name, p.ID)
-			}
+		records := make([]projectRecord, 0, len(projects))
+		t := &table{header: []string{"ID", "NAME", "DESCRIPTION"}}
+		for _, p := range projects {
+			records = append(records, newProjectRecord(p))
+			t.addRow(p.ID, p.Name, p.Description)
+			t.ids = append(t.ids, p.ID)
 		}
+		render(out, records, t)
 		return
 	}
 
@@ -141,7 +174,9 @@ func runProjects(args []string) {
 			fmt.Fprintf(os.Stderr, "Error creating project: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Created project: %s (ID: %s)\n", project.Name, project.ID)
+		t := keyValueTable("id", project.ID, "name", project.Name)
+		t.ids = []string{idOrName(project.ID, project.Name)}
+		render(out, newProjectRecord(project), t)
 		return
 	}
 
@@ -149,15 +184,27 @@ func runProjects(args []string) {
 }
 
 func runTraces(args []string) {
+	if len(args) > 0 && args[0] == "get" {
+		runTraceGet(args[1:])
+		return
+	}
+	if len(args) > 0 && args[0] == "ingest" {
+		runTraceIngest(args[1:])
+		return
+	}
+
 	fs := flag.NewFlagSet("traces", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: opik traces [options]\n       opik traces get <trace-id> [options]\n       opik traces ingest [options] < records.jsonl\n\n")
+		fs.PrintDefaults()
+	}
 	list := fs.Bool("list", false, "List recent traces")
 	project := fs.String("project", "", "Filter by project name")
 	limit := fs.Int("limit", 10, "Maximum number of traces to show")
-	format := fs.String("format", "text", "Output format (text, json)")
-	if err := fs.Parse(args); err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing arguments: %v\n", err)
-		os.Exit(1)
-	}
+	diagram := fs.String("diagram", "", "Print the span tree of the trace with the given ID as a diagram")
+	diagramFormat := fs.String("diagram-format", "mermaid", "Diagram format: mermaid or dot")
+	out := addOutputFlags(fs)
+	parseFlags(fs, out, args)
 
 	ctx := context.Background()
 	opts := []opik.Option{}
@@ -178,17 +225,31 @@ func runTraces(args []string) {
 			os.Exit(1)
 		}
 
-		if *format == "json" {
-			_ = json.NewEncoder(os.Stdout).Encode(traces)
+		records := make([]traceRecord, 0, len(traces))
+		t := &table{header: []string{"ID", "NAME", "START", "DURATION"}}
+		for _, tr := range traces {
+			records = append(records, newTraceRecord(tr))
+			t.addRow(tr.ID, tr.Name, tr.StartTime.Local().Format(time.DateTime), formatDuration(tr.StartTime, tr.EndTime))
+			t.ids = append(t.ids, tr.ID)
+		}
+		render(out, records, t)
+		return
+	}
+
+	if *diagram != "" {
+		if *diagramFormat != "mermaid" && *diagramFormat != "dot" {
+			fmt.Fprintf(os.Stderr, "Unknown diagram format %q: use mermaid or dot\n", *diagramFormat)
+			os.Exit(2)
+		}
+		tree, err := client.GetTraceTree(ctx, *diagram)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting trace: %v\n", err)
+			os.Exit(1)
+		}
+		if *diagramFormat == "dot" {
+			fmt.Print(tree.ToDOT())
 		} else {
-			fmt.Printf("Recent traces (limit %d):\n", *limit)
-			for _, t := range traces {
-				duration := ""
-				if !t.EndTime.IsZero() {
-					duration = fmt.Sprintf(" (%.2fs)", t.EndTime.Sub(t.StartTime).Seconds())
-				}
-				fmt.Printf("  - %s: %s%s\n", t.ID[:8], t.Name, duration)
-			}
+			fmt.Print(tree.ToMermaid())
 		}
 		return
 	}
@@ -202,11 +263,8 @@ func runDatasets(args []string) {
 	create := fs.String("create", "", "Create a new dataset with the given name")
 	get := fs.String("get", "", "Get a dataset by name")
 	deleteFlag := fs.String("delete", "", "Delete a dataset by name")
-	format := fs.String("format", "text", "Output format (text, json)")
-	if err := fs.Parse(args); err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing arguments: %v\n", err)
-		os.Exit(1)
-	}
+	out := addOutputFlags(fs)
+	parseFlags(fs, out, args)
 
 	ctx := context.Background()
 	client, err := opik.NewClient()
@@ -222,14 +280,14 @@ func runDatasets(args []string) {
 			os.Exit(1)
 		}
 
-		if *format == "json" {
-			_ = json.NewEncoder(os.Stdout).Encode(datasets)
-		} else {
-			fmt.Println("Datasets:")
-			for _, d := range datasets {
-				fmt.Printf("  - %s (ID: %s)\n", d.Name(), d.ID())
-			}
+		records := make([]datasetRecord, 0, len(datasets))
+		t := &table{header: []string{"ID", "NAME", "DESCRIPTION"}}
+		for _, d := range datasets {
+			records = append(records, newDatasetRecord(d))
+			t.addRow(d.ID(), d.Name(), d.Description())
+			t.ids = append(t.ids, d.ID())
 		}
+		render(out, records, t)
 		return
 	}
 
@@ -239,7 +297,9 @@ func runDatasets(args []string) {
 			fmt.Fprintf(os.Stderr, "Error creating dataset: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Created dataset: %s (ID: %s)\n", dataset.Name(), dataset.ID())
+		t := keyValueTable("id", dataset.ID(), "name", dataset.Name())
+		t.ids = []string{dataset.ID()}
+		render(out, newDatasetRecord(dataset), t)
 		return
 	}
 
@@ -250,13 +310,14 @@ func runDatasets(args []string) {
 			os.Exit(1)
 		}
 
-		if *format == "json" {
-			_ = json.NewEncoder(os.Stdout).Encode(dataset)
-		} else {
-			fmt.Printf("Dataset: %s\n", dataset.Name())
-			fmt.Printf("  ID: %s\n", dataset.ID())
-			fmt.Printf("  Description: %s\n", dataset.Description())
-		}
+		t := keyValueTable(
+			"id", dataset.ID(),
+			"name", dataset.Name(),
+			"description", dataset.Description(),
+			"tags", strings.Join(dataset.Tags(), ", "),
+		)
+		t.ids = []string{dataset.ID()}
+		render(out, newDatasetRecord(dataset), t)
 		return
 	}
 
@@ -271,7 +332,9 @@ func runDatasets(args []string) {
 			fmt.Fprintf(os.Stderr, "Error deleting dataset: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Deleted dataset: %s\n", *deleteFlag)
+		t := keyValueTable("id", dataset.ID(), "name", dataset.Name(), "deleted", "true")
+		t.ids = []string{dataset.ID()}
+		render(out, newDatasetRecord(dataset), t)
 		return
 	}
 
@@ -282,11 +345,15 @@ func runExperiments(args []string) {
 	fs := flag.NewFlagSet("experiments", flag.ExitOnError)
 	list := fs.Bool("list", false, "List experiments")
 	dataset := fs.String("dataset", "", "Filter by dataset name")
-	format := fs.String("format", "text", "Output format (text, json)")
-	if err := fs.Parse(args); err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing arguments: %v\n", err)
-		os.Exit(1)
-	}
+	importFile := fs.String("import", "", "Import offline results from a CSV, JSON Lines, or Parquet file as an experiment on -dataset")
+	format := fs.String("format", "", "Format of the -import file: csv, jsonl, or parquet (default from the file extension)")
+	name := fs.String("name", "", "Name of the imported experiment")
+	key := fs.String("key", "", "Column of the -import file that identifies each dataset item")
+	keyField := fs.String("key-field", "", "Dataset item field matched against -key (default: the dataset item ID)")
+	metrics := fs.String("metrics", "", "Comma-separated metric columns of the -import file, logged as feedback scores")
+	outputs := fs.String("outputs", "", "Comma-separated output columns of the -import file (default: all other columns)")
+	out := addOutputFlags(fs)
+	parseFlags(fs, out, args)
 
 	ctx := context.Background()
 	client, err := opik.NewClient()
@@ -313,16 +380,312 @@ func runExperiments(args []string) {
 			os.Exit(1)
 		}
 
-		if *format == "json" {
-			_ = json.NewEncoder(os.Stdout).Encode(experiments)
-		} else {
-			fmt.Printf("Experiments for dataset '%s':\n", *dataset)
-			for _, e := range experiments {
-				fmt.Printf("  - %s (ID: %s)\n", e.Name(), e.ID())
+		records := make([]experimentRecord, 0, len(experiments))
+		t := &table{header: []string{"ID", "NAME", "DATASET"}}
+		for _, e := range experiments {
+			record := newExperimentRecord(e)
+			if record.DatasetName == "" {
+				record.DatasetName = *dataset
 			}
+			records = append(records, record)
+			t.addRow(e.ID(), e.Name(), record.DatasetName)
+			t.ids = append(t.ids, e.ID())
 		}
+		render(out, records, t)
+		return
+	}
+
+	if *importFile != "" {
+		if *dataset == "" || *key == "" {
+			fmt.Fprintf(os.Stderr, "Error: -dataset and -key are required for importing results\n")
+			os.Exit(1)
+		}
+		importFormat, err := importFormatOf(*importFile, *format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		f, err := os.Open(*importFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening results file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		var opts []opik.ExperimentOption
+		if *name != "" {
+			opts = append(opts, opik.WithExperimentName(*name))
+		}
+		result, err := client.ImportExperiment(ctx, *dataset, f, importFormat, opik.ImportMapping{
+			KeyColumn:     *key,
+			KeyField:      *keyField,
+			OutputColumns: splitList(*outputs),
+			MetricColumns: splitList(*metrics),
+		}, opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error importing experiment: %v\n", err)
+			os.Exit(1)
+		}
+		for _, failed := range result.Failed {
+			fmt.Fprintf(os.Stderr, "Row %d not imported: %v\n", failed.Index+1, failed.Err)
+		}
+
+		e := result.Experiment
+		t := keyValueTable(
+			"id", e.ID(),
+			"name", e.Name(),
+			"dataset", e.DatasetName(),
+			"imported", fmt.Sprint(result.Imported),
+			"scores", fmt.Sprint(result.Scores),
+			"failed", fmt.Sprint(len(result.Failed)),
+		)
+		t.ids = []string{e.ID()}
+		render(out, newExperimentRecord(e), t)
 		return
 	}
 
 	fs.Usage()
 }
+
+// importFormatOf returns the format named by the -format flag, or the one
+// implied by the file extension.
+func importFormatOf(path, format string) (opik.ImportFormat, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	switch format {
+	case "csv":
+		return opik.ImportFormatCSV, nil
+	case "jsonl", "ndjson":
+		return opik.ImportFormatJSONL, nil
+	case "parquet":
+		return opik.ImportFormatParquet, nil
+	}
+	return "", fmt.Errorf("unknown results format %q; use -format csv, jsonl, or parquet", format)
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func runEval(args []string) {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	var f evalFlags
+	fs.StringVar(&f.config, "config", "", "Path to the suite config file (YAML or JSON)")
+	fs.StringVar(&f.dataset, "dataset", "", "Name of the Opik dataset to evaluate")
+	fs.StringVar(&f.datasetFile, "dataset-file", "", "Local JSON or JSON Lines file of items to evaluate")
+	metrics := fs.String("metrics", "", "Comma-separated metric names or judge config files (instead of -config)")
+	fs.Func("threshold", "Minimum average score for every metric without its own threshold", parseThreshold(&f.threshold))
+	fs.IntVar(&f.concurrency, "concurrency", 0, "Number of items evaluated in parallel")
+	savePath := fs.String("save", "", "Also write the summary as JSON to this file")
+	histograms := fs.Bool("histogram", false, "Print a histogram of each metric's scores after the summary table")
+	listMetrics := fs.Bool("list-metrics", false, "List metric names available to suites")
+	out := addOutputFlags(fs)
+	parseFlags(fs, out, args)
+	f.metrics = splitList(*metrics)
+
+	if *listMetrics {
+		names := evalconfig.MetricNames()
+		t := &table{header: []string{"METRIC"}, ids: names}
+		for _, name := range names {
+			t.addRow(name)
+		}
+		render(out, names, t)
+		return
+	}
+
+	if f.config == "" && len(f.metrics) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	suite, err := evalSuite(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading suite: %v\n", err)
+		os.Exit(1)
+	}
+
+	buildOpts := []evalconfig.BuildOption{}
+	if suite.Judge.Provider != "" {
+		provider, err := newJudgeProvider(suite.Judge)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating judge provider: %v\n", err)
+			os.Exit(1)
+		}
+		buildOpts = append(buildOpts, evalconfig.WithJudgeProvider(provider))
+	}
+
+	engine, err := suite.BuildEngine(buildOpts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error building suite: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	items, err := loadEvalItems(ctx, suite)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading dataset: %v\n", err)
+		os.Exit(1)
+	}
+
+	results := evaluation.NewDatasetEvaluator(engine, suite.InputMapper()).Evaluate(ctx, items)
+	summary := results.Summary()
+	thresholdErr := suite.CheckThresholds(engine.Metrics(), results)
+
+	record := evalRecord{
+		Suite:   suite.Name,
+		Items:   len(items),
+		Summary: summary,
+		Passed:  thresholdErr == nil,
+	}
+	var te *evalconfig.ThresholdError
+	if errors.As(thresholdErr, &te) {
+		record.Failures = te.Failures
+	}
+
+	thresholds := suite.Thresholds(engine.Metrics())
+	record.Distributions = make(map[string]evalDistribution)
+	for _, metric := range engine.Metrics() {
+		if n := results.CountByStatus(metric.Name())[evaluation.ScoreStatusNotScored]; n > 0 {
+			if record.NotScored == nil {
+				record.NotScored = make(map[string]int)
+			}
+			record.NotScored[metric.Name()] = n
+		}
+		var threshold *float64
+		if v, ok := thresholds[metric.Name()]; ok {
+			threshold = &v
+		}
+		record.Distributions[metric.Name()] = scoreDistribution(results, metric.Name(), threshold)
+	}
+
+	names := make([]string, 0, len(summary))
+	for name := range summary {
+		names = append(names, name)
+	}
+	for name := range record.NotScored {
+		if _, ok := summary[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	t := &table{header: []string{"METRIC", "AVERAGE", "NOT SCORED", "DISTRIBUTION", "PASSED"}}
+	for _, name := range names {
+		average := "-"
+		if v, ok := summary[name]; ok {
+			average = fmt.Sprintf("%.3f", v)
+		}
+		// Sub-scores have no distribution of their own.
+		spark, passed := "", ""
+		if d, ok := record.Distributions[name]; ok {
+			spark, passed = d.sparkline(), d.passBar(10)
+		}
+		t.addRow(name, average, strconv.Itoa(record.NotScored[name]), spark, passed)
+	}
+	render(out, record, t)
+	if *histograms && out.format == formatTable && !out.quiet {
+		for _, metric := range engine.Metrics() {
+			writeHistogram(out.w, metric.Name(), record.Distributions[metric.Name()], 40)
+		}
+	}
+	if *savePath != "" {
+		if err := saveEvalSummary(*savePath, record); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving summary: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if thresholdErr != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", thresholdErr)
+		os.Exit(1)
+	}
+}
+
+// parseFlags parses a subcommand's arguments and validates its output flags.
+func parseFlags(fs *flag.FlagSet, out *output, args []string) {
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing arguments: %v\n", err)
+		os.Exit(1)
+	}
+	if err := out.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// render prints a command's result, exiting if it cannot be written.
+func render(out *output, v any, t *table) {
+	if err := out.print(v, t); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// idOrName returns id, or name if the server did not return an ID.
+func idOrName(id, name string) string {
+	if id != "" {
+		return id
+	}
+	return name
+}
+
+// loadEvalItems reads the suite's items from its dataset file or Opik dataset.
+func loadEvalItems(ctx context.Context, suite *evalconfig.Suite) ([]map[string]any, error) {
+	if suite.DatasetFile != "" {
+		return suite.LoadItems()
+	}
+	if suite.Dataset == "" {
+		return nil, errors.New("suite must set dataset or dataset_file")
+	}
+
+	client, err := opik.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	dataset, err := client.GetDatasetByName(ctx, suite.Dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	const pageSize = 100
+	var items []map[string]any
+	for page := 1; ; page++ {
+		batch, err := dataset.GetItems(ctx, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range batch {
+			items = append(items, item.Data)
+		}
+		if len(batch) < pageSize {
+			return items, nil
+		}
+	}
+}
+
+// newJudgeProvider creates the LLM provider named in the suite's judge config.
+func newJudgeProvider(judge evalconfig.Judge) (llm.Provider, error) {
+	switch judge.Provider {
+	case "openai":
+		opts := []openai.Option{}
+		if judge.Model != "" {
+			opts = append(opts, openai.WithModel(judge.Model))
+		}
+		return openai.NewProvider(opts...), nil
+	case "anthropic":
+		opts := []anthropic.Option{}
+		if judge.Model != "" {
+			opts = append(opts, anthropic.WithModel(judge.Model))
+		}
+		return anthropic.NewProvider(opts...), nil
+	default:
+		return nil, fmt.Errorf("unsupported judge provider: %q", judge.Provider)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by -output.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// output renders command results in the format selected by the -output and
+// -quiet flags, so every subcommand can be composed into scripts the same
+// way.
+type output struct {
+	format string
+	quiet  bool
+	w      io.Writer
+}
+
+// addOutputFlags registers -output (and its short form -o), -quiet, and the
+// older -format flag on fs.
+func addOutputFlags(fs *flag.FlagSet) *output {
+	o := &output{w: os.Stdout}
+	usage := "Output format (table, json, yaml)"
+	fs.StringVar(&o.format, "output", formatTable, usage)
+	fs.StringVar(&o.format, "o", formatTable, usage)
+	fs.StringVar(&o.format, "format", formatTable, "Deprecated: use -output")
+	fs.BoolVar(&o.quiet, "quiet", false, "Print only IDs, one per line")
+	return o
+}
+
+// validate checks the selected format, accepting "text" as an alias for
+// table.
+func (o *output) validate() error {
+	switch o.format {
+	case formatTable, formatJSON, formatYAML:
+		return nil
+	case "text", "":
+		o.format = formatTable
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q (want table, json, or yaml)", o.format)
+	}
+}
+
+// table is the table form of a result. IDs are printed instead of the table
+// in quiet mode.
+type table struct {
+	header []string
+	rows   [][]string
+	ids    []string
+}
+
+// addRow appends a row to the table.
+func (t *table) addRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// keyValueTable returns a two-column table of field names and values.
+func keyValueTable(pairs ...string) *table {
+	t := &table{header: []string{"FIELD", "VALUE"}}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		t.addRow(pairs[i], pairs[i+1])
+	}
+	return t
+}
+
+// print writes v as JSON or YAML, t as aligned columns, or t's IDs in quiet
+// mode. Values are converted to YAML through JSON, so both formats use the
+// same field names.
+func (o *output) print(v any, t *table) error {
+	if o.quiet {
+		for _, id := range t.ids {
+			fmt.Fprintln(o.w, id)
+		}
+		return nil
+	}
+
+	switch o.format {
+	case formatJSON:
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		return writeYAML(o.w, v)
+	default:
+		return writeTable(o.w, t)
+	}
+}
+
+func writeTable(w io.Writer, t *table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(t.header) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	}
+	for _, row := range t.rows {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			// Tabs and newlines would break the column alignment.
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(c)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeYAML writes v as block-style YAML with the field names and order of
+// its JSON encoding.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle clears the flow and quoting styles that YAML decoding of JSON
+// leaves on every node. Strings that would be read back as another type are
+// still quoted by the encoder.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+func testRecords() ([]traceRecord, *table) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	traces := []*opik.TraceInfo{
+		{ID: "t-1", Name: "checkout", StartTime: start, EndTime: start.Add(1500 * time.Millisecond), Output: "true"},
+		{ID: "t-22", Name: "search\tquery", StartTime: start},
+	}
+	var records []traceRecord
+	t := &table{header: []string{"ID", "NAME"}}
+	for _, tr := range traces {
+		records = append(records, newTraceRecord(tr))
+		t.addRow(tr.ID, tr.Name)
+		t.ids = append(t.ids, tr.ID)
+	}
+	return records, t
+}
+
+func TestOutputTable(t *testing.T) {
+	records, tbl := testRecords()
+	var buf bytes.Buffer
+	out := &output{format: formatTable, w: &buf}
+	if err := out.print(records, tbl); err != nil {
+		t.Fatalf("print error: %v", err)
+	}
+
+	want := "ID    NAME\n" +
+		"t-1   checkout\n" +
+		"t-22  search query\n"
+	if buf.String() != want {
+		t.Errorf("table =\n%q\nwant\n%q", buf.String(), want)
+	}
+}
+
+func TestOutputJSON(t *testing.T) {
+	records, tbl := testRecords()
+	var buf bytes.Buffer
+	out := &output{format: formatJSON, w: &buf}
+	if err := out.print(records, tbl); err != nil {
+		t.Fatalf("print error: %v", err)
+	}
+
+	for _, want := range []string{`"id": "t-1"`, `"start_time": "2024-01-02T03:04:05Z"`, `"duration_ms": 1500`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("json output missing %s:\n%s", want, buf.String())
+		}
+	}
+	if strings.Count(buf.String(), "end_time") != 1 {
+		t.Errorf("end_time should be omitted for unfinished traces:\n%s", buf.String())
+	}
+}
+
+func TestOutputYAML(t *testing.T) {
+	records, tbl := testRecords()
+	var buf bytes.Buffer
+	out := &output{format: formatYAML, w: &buf}
+	if err := out.print(records, tbl); err != nil {
+		t.Fatalf("print error: %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"- id: t-1\n  name: checkout\n  start_time: \"2024-01-02T03:04:05Z\"",
+		"duration_ms: 1500",
+		`output: "true"`, // a string that would otherwise read back as a bool
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("yaml output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "{") {
+		t.Errorf("yaml output should use block style:\n%s", got)
+	}
+}
+
+func TestOutputQuiet(t *testing.T) {
+	records, tbl := testRecords()
+	var buf bytes.Buffer
+	out := &output{format: formatJSON, quiet: true, w: &buf}
+	if err := out.print(records, tbl); err != nil {
+		t.Fatalf("print error: %v", err)
+	}
+	if buf.String() != "t-1\nt-22\n" {
+		t.Errorf("quiet output = %q", buf.String())
+	}
+}
+
+func TestOutputFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{nil, formatTable, false},
+		{[]string{"-output=yaml"}, formatYAML, false},
+		{[]string{"-o", "json"}, formatJSON, false},
+		{[]string{"-format=text"}, formatTable, false},
+		{[]string{"-output=xml"}, "", true},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		out := addOutputFlags(fs)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatalf("%v: parse error: %v", tt.args, err)
+		}
+		err := out.validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: validate error = %v", tt.args, err)
+			continue
+		}
+		if !tt.wantErr && out.format != tt.want {
+			t.Errorf("%v: format = %q, want %q", tt.args, out.format, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// pluginPrefix is the prefix of plugin executables: "opik foo" runs the
+// first "opik-foo" found on PATH.
+const pluginPrefix = "opik-"
+
+// plugin is an executable on PATH that extends the CLI with a subcommand.
+type plugin struct {
+	name string
+	path string
+}
+
+// findPlugins returns the plugins on PATH, sorted by name. When several
+// directories contain the same plugin, the first one on PATH wins, as it
+// would when the plugin is run. Plugins named like built-in commands are
+// skipped because they can never be run.
+func findPlugins() []plugin {
+	builtin := make(map[string]bool)
+	for _, c := range builtinCommands() {
+		builtin[c.name] = true
+	}
+
+	seen := make(map[string]bool)
+	var plugins []plugin
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := pluginName(e.Name())
+			if !ok || seen[name] || builtin[name] {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, plugin{name: name, path: path})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].name < plugins[j].name })
+	return plugins
+}
+
+// pluginName returns the subcommand name of a plugin executable file name.
+func pluginName(file string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		file = strings.TrimSuffix(file, filepath.Ext(file))
+	}
+	name, ok := strings.CutPrefix(file, pluginPrefix)
+	if !ok || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode()&0o111 != 0
+}
+
+// lookupPlugin returns the path of the plugin for a subcommand.
+func lookupPlugin(name string) (string, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// runPlugin runs a plugin with the remaining arguments and returns its exit
+// code. The plugin inherits the environment, so it sees the same OPIK_*
+// configuration, and OPIK_CLI is set to this executable so the plugin can
+// call back into the CLI.
+func runPlugin(path string, args []string) int {
+	cmd := exec.Command(path, args...) //nolint:gosec // G204: running the plugin the user asked for is the point
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if self, err := os.Executable(); err == nil {
+		cmd.Env = append(cmd.Env, "OPIK_CLI="+self)
+	}
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr) && exitErr.ExitCode() > 0:
+		return exitErr.ExitCode()
+	default:
+		fmt.Fprintf(os.Stderr, "Error running plugin %s: %v\n", path, err)
+		return 1
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/plexusone/opik-go/prompts"
+)
+
+func TestLockTable(t *testing.T) {
+	lock := &prompts.Lockfile{Version: prompts.LockfileVersion, Prompts: map[string]prompts.LockedPrompt{
+		"summarizer":    {Commit: "cccc3333", VersionID: "v-2"},
+		"support-agent": {Commit: "bbbb2222", VersionID: "v-1"},
+	}}
+	var buf bytes.Buffer
+	if err := writeTable(&buf, lockTable(lock)); err != nil {
+		t.Fatalf("writeTable error: %v", err)
+	}
+	want := "NAME           COMMIT    VERSION ID\n" +
+		"summarizer     cccc3333  v-2\n" +
+		"support-agent  bbbb2222  v-1\n"
+	if buf.String() != want {
+		t.Errorf("table =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"time"
+
+	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/evaluation/evalconfig"
+)
+
+// The record types below define the field names of the json and yaml
+// output. They are part of the CLI's interface for scripts, so fields may be
+// added but existing names must not change.
+
+type projectRecord struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	LastUpdatedAt *time.Time `json:"last_updated_at,omitempty"`
+}
+
+func newProjectRecord(p *opik.Project) projectRecord {
+	return projectRecord{
+		ID:            p.ID,
+		Name:          p.Name,
+		Description:   p.Description,
+		CreatedAt:     optionalTime(p.CreatedAt),
+		LastUpdatedAt: optionalTime(p.LastUpdated),
+	}
+}
+
+type traceRecord struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	StartTime  time.Time  `json:"start_time"`
+	EndTime    *time.Time `json:"end_time,omitempty"`
+	DurationMs *int64     `json:"duration_ms,omitempty"`
+	Input      any        `json:"input"`
+	Output     any        `json:"output"`
+	Metadata   any        `json:"metadata"`
+}
+
+func newTraceRecord(t *opik.TraceInfo) traceRecord {
+	r := traceRecord{
+		ID:        t.ID,
+		Name:      t.Name,
+		StartTime: t.StartTime,
+		EndTime:   optionalTime(t.EndTime),
+		Input:     t.Input,
+		Output:    t.Output,
+		Metadata:  t.Metadata,
+	}
+	if r.EndTime != nil {
+		ms := t.EndTime.Sub(t.StartTime).Milliseconds()
+		r.DurationMs = &ms
+	}
+	return r
+}
+
+type traceImportRecord struct {
+	Traces   int               `json:"traces"`
+	Spans    int               `json:"spans"`
+	Failed   int               `json:"failed"`
+	TraceIDs []string          `json:"trace_ids"`
+	IDs      map[string]string `json:"ids,omitempty"`
+}
+
+func newTraceImportRecord(r *opik.TraceImportResult) traceImportRecord {
+	return traceImportRecord{
+		Traces:   r.Traces,
+		Spans:    r.Spans,
+		Failed:   len(r.Failed),
+		TraceIDs: append([]string{}, r.TraceIDs...),
+		IDs:      r.IDs,
+	}
+}
+
+type traceTreeRecord struct {
+	traceRecord
+	Spans []spanRecord `json:"spans"`
+}
+
+func newTraceTreeRecord(tree *opik.TraceTree) traceTreeRecord {
+	return traceTreeRecord{traceRecord: newTraceRecord(tree.Trace), Spans: newSpanRecords(tree.Roots)}
+}
+
+// spanRecord is a span with its child spans.
+type spanRecord struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	StartTime  time.Time      `json:"start_time"`
+	EndTime    *time.Time     `json:"end_time,omitempty"`
+	DurationMs *int64         `json:"duration_ms,omitempty"`
+	Model      string         `json:"model,omitempty"`
+	Provider   string         `json:"provider,omitempty"`
+	Usage      map[string]int `json:"usage,omitempty"`
+	Input      any            `json:"input"`
+	Output     any            `json:"output"`
+	Metadata   any            `json:"metadata"`
+	Spans      []spanRecord   `json:"spans,omitempty"`
+}
+
+func newSpanRecords(nodes []*opik.SpanNode) []spanRecord {
+	records := make([]spanRecord, 0, len(nodes))
+	for _, n := range nodes {
+		s := n.Span
+		r := spanRecord{
+			ID:        s.ID,
+			Name:      s.Name,
+			Type:      s.Type,
+			StartTime: s.StartTime,
+			EndTime:   optionalTime(s.EndTime),
+			Model:     s.Model,
+			Provider:  s.Provider,
+			Usage:     s.Usage,
+			Input:     s.Input,
+			Output:    s.Output,
+			Metadata:  s.Metadata,
+		}
+		if r.EndTime != nil {
+			ms := s.EndTime.Sub(s.StartTime).Milliseconds()
+			r.DurationMs = &ms
+		}
+		if len(n.Children) > 0 {
+			r.Spans = newSpanRecords(n.Children)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+type datasetRecord struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+func newDatasetRecord(d *opik.Dataset) datasetRecord {
+	tags := d.Tags()
+	if tags == nil {
+		tags = []string{}
+	}
+	return datasetRecord{
+		ID:          d.ID(),
+		Name:        d.Name(),
+		Description: d.Description(),
+		Tags:        tags,
+	}
+}
+
+type experimentRecord struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	DatasetName string         `json:"dataset_name"`
+	Metadata    map[string]any `json:"metadata"`
+}
+
+func newExperimentRecord(e *opik.Experiment) experimentRecord {
+	return experimentRecord{
+		ID:          e.ID(),
+		Name:        e.Name(),
+		DatasetName: e.DatasetName(),
+		Metadata:    e.Metadata(),
+	}
+}
+
+type configRecord struct {
+	URL       string `json:"url"`
+	Workspace string `json:"workspace"`
+	APIKeySet bool   `json:"api_key_set"`
+}
+
+type evalRecord struct {
+	Suite    string                        `json:"suite"`
+	Items    int                           `json:"items"`
+	Summary  map[string]float64            `json:"summary"`
+	Passed   bool                          `json:"passed"`
+	Failures []evalconfig.ThresholdFailure `json:"failures,omitempty"`
+	// NotScored counts the items each metric skipped.
+	NotScored map[string]int `json:"not_scored,omitempty"`
+	// Distributions are the score distributions of the metrics.
+	Distributions map[string]evalDistribution `json:"distributions,omitempty"`
+}
+
+// optionalTime returns nil for the zero time, so it is omitted from output.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
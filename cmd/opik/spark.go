@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// sparkBins is the number of bins of score distributions.
+const sparkBins = 10
+
+// sparkLevels are the characters of sparkline bars, from lowest to highest.
+// Empty bins are shown as sparkEmpty, so a bin with a single score is told
+// apart from one without any.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+const sparkEmpty = '·'
+
+// evalDistribution is the distribution of a metric's scores.
+type evalDistribution struct {
+	// Histogram counts the scores in bins of equal width from Min to Max,
+	// which is 0 to 1 unless scores fall outside it.
+	Histogram []int   `json:"histogram"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+	// Threshold is the metric's threshold, if it has one. Passed and Failed
+	// count the items scored at or above it, and the items scored below it
+	// or failed on.
+	Threshold *float64 `json:"threshold,omitempty"`
+	Passed    int      `json:"passed,omitempty"`
+	Failed    int      `json:"failed,omitempty"`
+}
+
+// scoreDistribution returns the distribution of the scores of a metric.
+// threshold is the metric's threshold, or nil if it has none.
+func scoreDistribution(results evaluation.EvaluationResults, name string, threshold *float64) evalDistribution {
+	var values []float64
+	d := evalDistribution{Min: 0, Max: 1, Threshold: threshold}
+	for _, res := range results {
+		score := res.Scores.ByName(name)
+		switch {
+		case score == nil || score.IsNotScored():
+			continue
+		case !score.IsSuccess():
+			if threshold != nil {
+				d.Failed++
+			}
+			continue
+		}
+		values = append(values, score.Value)
+		d.Min, d.Max = math.Min(d.Min, score.Value), math.Max(d.Max, score.Value)
+		if threshold != nil {
+			if score.Value >= *threshold {
+				d.Passed++
+			} else {
+				d.Failed++
+			}
+		}
+	}
+
+	d.Histogram = make([]int, sparkBins)
+	width := (d.Max - d.Min) / sparkBins
+	for _, v := range values {
+		d.Histogram[min(int((v-d.Min)/width), sparkBins-1)]++
+	}
+	return d
+}
+
+// sparkline returns the histogram as a line of bars scaled to the highest
+// bin.
+func (d evalDistribution) sparkline() string {
+	highest := 0
+	for _, n := range d.Histogram {
+		highest = max(highest, n)
+	}
+	var b strings.Builder
+	for _, n := range d.Histogram {
+		if n == 0 {
+			b.WriteRune(sparkEmpty)
+			continue
+		}
+		level := (n*len(sparkLevels) - 1) / highest
+		b.WriteRune(sparkLevels[level])
+	}
+	return b.String()
+}
+
+// passBar returns a bar of the share of items that passed the threshold,
+// followed by the counts, or "-" for a metric without a threshold.
+func (d evalDistribution) passBar(width int) string {
+	total := d.Passed + d.Failed
+	if d.Threshold == nil || total == 0 {
+		return "-"
+	}
+	filled := int(math.Round(float64(width*d.Passed) / float64(total)))
+	return fmt.Sprintf("%s%s %d/%d", strings.Repeat("█", filled), strings.Repeat("░", width-filled), d.Passed, total)
+}
+
+// writeHistogram writes the histogram of a metric as one bar per bin, with
+// the bin's range and count.
+func writeHistogram(w io.Writer, name string, d evalDistribution, width int) {
+	highest := 0
+	total := 0
+	for _, n := range d.Histogram {
+		highest = max(highest, n)
+		total += n
+	}
+	fmt.Fprintf(w, "\n%s (%d scored)\n", name, total)
+	binWidth := (d.Max - d.Min) / float64(len(d.Histogram))
+	for i, n := range d.Histogram {
+		bar := 0
+		if highest > 0 {
+			bar = int(math.Round(float64(width*n) / float64(highest)))
+		}
+		if n > 0 {
+			bar = max(bar, 1)
+		}
+		lo := d.Min + float64(i)*binWidth
+		fmt.Fprintf(w, "  %5.2f-%-5.2f │%s %d\n", lo, lo+binWidth, strings.Repeat("█", bar), n)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+func sparkResults(values ...float64) evaluation.EvaluationResults {
+	results := make(evaluation.EvaluationResults, len(values))
+	for i, v := range values {
+		results[i] = &evaluation.EvaluationResult{Scores: evaluation.ScoreResults{evaluation.NewScoreResult("equals", v)}}
+	}
+	return results
+}
+
+func TestScoreDistribution(t *testing.T) {
+	results := sparkResults(0, 0, 0, 0.55, 1, 1)
+	results = append(results,
+		&evaluation.EvaluationResult{Scores: evaluation.ScoreResults{evaluation.NewFailedScoreResult("equals", errors.New("boom"))}},
+		&evaluation.EvaluationResult{Scores: evaluation.ScoreResults{evaluation.NewNotScoredResult("equals", "no expected output")}},
+	)
+	threshold := 0.5
+
+	d := scoreDistribution(results, "equals", &threshold)
+	want := []int{3, 0, 0, 0, 0, 1, 0, 0, 0, 2}
+	for i := range want {
+		if d.Histogram[i] != want[i] {
+			t.Fatalf("histogram = %v, want %v", d.Histogram, want)
+		}
+	}
+	if d.Passed != 3 || d.Failed != 4 {
+		t.Errorf("passed %d, failed %d, want 3 and 4", d.Passed, d.Failed)
+	}
+	if got := d.sparkline(); got != "█····▃···▆" {
+		t.Errorf("sparkline = %q", got)
+	}
+	if got := d.passBar(7); got != "███░░░░ 3/7" {
+		t.Errorf("passBar = %q", got)
+	}
+
+	d = scoreDistribution(results, "equals", nil)
+	if d.Failed != 0 || d.passBar(7) != "-" {
+		t.Errorf("without a threshold: %+v, passBar %q", d, d.passBar(7))
+	}
+}
+
+func TestScoreDistributionWidensRange(t *testing.T) {
+	d := scoreDistribution(sparkResults(-1, 3), "equals", nil)
+	if d.Min != -1 || d.Max != 3 || d.Histogram[0] != 1 || d.Histogram[9] != 1 {
+		t.Errorf("distribution = %+v", d)
+	}
+}
+
+func TestWriteHistogram(t *testing.T) {
+	var b strings.Builder
+	writeHistogram(&b, "equals", scoreDistribution(sparkResults(0, 0, 0.95), "equals", nil), 4)
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 11 || lines[0] != "equals (3 scored)" {
+		t.Fatalf("histogram =\n%s", b.String())
+	}
+	if lines[1] != "   0.00-0.10  │████ 2" || lines[10] != "   0.90-1.00  │██ 1" || lines[5] != "   0.40-0.50  │ 0" {
+		t.Errorf("histogram =\n%s", b.String())
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// runTraceGet prints one trace with its span tree. The trace ID may come
+// before or after the flags.
+func runTraceGet(args []string) {
+	fs := flag.NewFlagSet("traces get", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: opik traces get <trace-id> [options]\n\n")
+		fs.PrintDefaults()
+	}
+	project := fs.String("project", "", "Project name of the trace")
+	out := addOutputFlags(fs)
+
+	var id string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, args = args[0], args[1:]
+	}
+	parseFlags(fs, out, args)
+	if id == "" {
+		id = fs.Arg(0)
+	}
+	if id == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	opts := []opik.Option{}
+	if *project != "" {
+		opts = append(opts, opik.WithProjectName(*project))
+	}
+	client, err := opik.NewClient(opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
+		os.Exit(1)
+	}
+
+	tree, err := client.GetTraceTree(context.Background(), id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting trace: %v\n", err)
+		os.Exit(1)
+	}
+	render(out, newTraceTreeRecord(tree), traceTreeTable(tree))
+}
+
+// runTraceIngest logs the trace and span records of a JSON Lines file, or
+// of stdin, so that scripts can log traces without an SDK. It exits with
+// status 1 if any record was not logged.
+func runTraceIngest(args []string) {
+	fs := flag.NewFlagSet("traces ingest", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: opik traces ingest [options] < records.jsonl\n\n"+
+			"Each line is a trace or span record, such as:\n"+
+			"  {\"type\": \"trace\", \"id\": \"job\", \"name\": \"backup\", \"start_time\": \"2024-05-01T02:00:00Z\"}\n"+
+			"  {\"type\": \"span\", \"trace_id\": \"job\", \"name\": \"pg_dump\", \"span_type\": \"tool\"}\n\n")
+		fs.PrintDefaults()
+	}
+	project := fs.String("project", "", "Project of traces without a project")
+	file := fs.String("file", "-", "Records file, or - for stdin")
+	out := addOutputFlags(fs)
+	parseFlags(fs, out, args)
+
+	opts := []opik.Option{}
+	if *project != "" {
+		opts = append(opts, opik.WithProjectName(*project))
+	}
+	client, err := opik.NewClient(opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
+		os.Exit(1)
+	}
+
+	in := os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening records file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	result, err := client.ImportTraces(context.Background(), in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error ingesting traces: %v\n", err)
+		os.Exit(1)
+	}
+	for _, failed := range result.Failed {
+		fmt.Fprintf(os.Stderr, "Record %d not logged: %v\n", failed.Index+1, failed.Err)
+	}
+
+	t := keyValueTable(
+		"traces", fmt.Sprint(result.Traces),
+		"spans", fmt.Sprint(result.Spans),
+		"failed", fmt.Sprint(len(result.Failed)),
+	)
+	t.ids = result.TraceIDs
+	render(out, newTraceImportRecord(result), t)
+	if len(result.Failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+// traceTreeTable returns the span tree of a trace as a table: the trace,
+// then each span indented under its parent, with its type, duration, model,
+// and token usage.
+func traceTreeTable(tree *opik.TraceTree) *table {
+	t := &table{header: []string{"NAME", "TYPE", "DURATION", "MODEL", "TOKENS"}}
+	total := make(map[string]int)
+	tree.Walk(func(n *opik.SpanNode) {
+		for k, v := range n.Span.Usage {
+			total[k] += v
+		}
+	})
+	t.addRow(tree.Trace.Name, "trace", formatDuration(tree.Trace.StartTime, tree.Trace.EndTime), "-", formatTokens(total))
+	t.ids = append(t.ids, tree.Trace.ID)
+
+	for _, row := range spanRows(tree.Roots) {
+		s := row.span
+		model := s.Model
+		if model == "" {
+			model = "-"
+		}
+		t.addRow(row.prefix+s.Name, s.Type, formatDuration(s.StartTime, s.EndTime), model, formatTokens(s.Usage))
+		t.ids = append(t.ids, s.ID)
+	}
+	return t
+}
+
+// formatTokens formats token usage as its total with the prompt and
+// completion tokens, such as "17 (12 in, 5 out)", or "-" if there is none.
+func formatTokens(usage map[string]int) string {
+	in, hasIn := usage[opik.UsagePromptTokens]
+	out, hasOut := usage[opik.UsageCompletionTokens]
+	total, hasTotal := usage[opik.UsageTotalTokens]
+	if !hasTotal {
+		if !hasIn && !hasOut {
+			return "-"
+		}
+		total = in + out
+	}
+	if !hasIn && !hasOut {
+		return fmt.Sprint(total)
+	}
+	return fmt.Sprintf("%d (%d in, %d out)", total, in, out)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+func testTraceTree() *opik.TraceTree {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	spans := []*opik.SpanInfo{
+		{ID: "s-1", Name: "agent", Type: "general", StartTime: start, EndTime: start.Add(2 * time.Second)},
+		{ID: "s-2", ParentSpanID: "s-1", Name: "plan", Type: "llm", Model: "gpt-4o", StartTime: start, EndTime: start.Add(800 * time.Millisecond),
+			Usage: map[string]int{opik.UsagePromptTokens: 12, opik.UsageCompletionTokens: 5, opik.UsageTotalTokens: 17}},
+		{ID: "s-3", ParentSpanID: "s-1", Name: "search", Type: "tool", StartTime: start.Add(time.Second)},
+	}
+	return &opik.TraceTree{
+		Trace: &opik.TraceInfo{ID: "t-1", Name: "checkout", StartTime: start, EndTime: start.Add(2500 * time.Millisecond)},
+		Spans: spans,
+		Roots: opik.BuildSpanTree(spans),
+	}
+}
+
+func TestTraceTreeTable(t *testing.T) {
+	tbl := traceTreeTable(testTraceTree())
+	var buf bytes.Buffer
+	if err := writeTable(&buf, tbl); err != nil {
+		t.Fatalf("writeTable error: %v", err)
+	}
+	want := "NAME          TYPE     DURATION  MODEL   TOKENS\n" +
+		"checkout      trace    2.50s     -       17 (12 in, 5 out)\n" +
+		"└─ agent      general  2.00s     -       -\n" +
+		"   ├─ plan    llm      800ms     gpt-4o  17 (12 in, 5 out)\n" +
+		"   └─ search  tool     -         -       -\n"
+	if buf.String() != want {
+		t.Errorf("table =\n%s\nwant\n%s", buf.String(), want)
+	}
+	if got := tbl.ids; len(got) != 4 || got[0] != "t-1" || got[3] != "s-3" {
+		t.Errorf("ids = %v, want the trace then its spans", got)
+	}
+}
+
+func TestTraceTreeRecord(t *testing.T) {
+	data, err := json.Marshal(newTraceTreeRecord(testTraceTree()))
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	var got struct {
+		ID         string `json:"id"`
+		DurationMs int64  `json:"duration_ms"`
+		Spans      []struct {
+			Name  string `json:"name"`
+			Spans []struct {
+				Name  string         `json:"name"`
+				Model string         `json:"model"`
+				Usage map[string]int `json:"usage"`
+				End   *string        `json:"end_time"`
+			} `json:"spans"`
+		} `json:"spans"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if got.ID != "t-1" || got.DurationMs != 2500 || len(got.Spans) != 1 || len(got.Spans[0].Spans) != 2 {
+		t.Fatalf("record = %s", data)
+	}
+	plan, search := got.Spans[0].Spans[0], got.Spans[0].Spans[1]
+	if plan.Name != "plan" || plan.Model != "gpt-4o" || plan.Usage[opik.UsageTotalTokens] != 17 {
+		t.Errorf("plan = %+v", plan)
+	}
+	if search.End != nil {
+		t.Errorf("unfinished span has end_time %v", *search.End)
+	}
+}
+
+func TestFormatTokens(t *testing.T) {
+	tests := []struct {
+		usage map[string]int
+		want  string
+	}{
+		{nil, "-"},
+		{map[string]int{opik.UsageTotalTokens: 30}, "30"},
+		{map[string]int{opik.UsagePromptTokens: 10, opik.UsageCompletionTokens: 2}, "12 (10 in, 2 out)"},
+	}
+	for _, tt := range tests {
+		if got := formatTokens(tt.usage); got != tt.want {
+			t.Errorf("formatTokens(%v) = %q, want %q", tt.usage, got, tt.want)
+		}
+	}
+}
+
+func TestTraceImportRecord(t *testing.T) {
+	data, err := json.Marshal(newTraceImportRecord(&opik.TraceImportResult{Spans: 2, Failed: []opik.ItemError{{Index: 1}}}))
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	want := `{"traces":0,"spans":2,"failed":1,"trace_ids":[]}`
+	if string(data) != want {
+		t.Errorf("record = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// ANSI escape sequences used by the terminal UI.
+const (
+	ansiReset   = "\033[0m"
+	ansiBold    = "\033[1m"
+	ansiDim     = "\033[2m"
+	ansiRed     = "\033[31m"
+	ansiGreen   = "\033[32m"
+	ansiYellow  = "\033[33m"
+	ansiMagenta = "\033[35m"
+	ansiCyan    = "\033[36m"
+	ansiClear   = "\033[H\033[2J"
+)
+
+func runTUI(args []string) {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	project := fs.String("project", "", "Project to browse")
+	pageSize := fs.Int("page-size", 20, "Number of traces per page")
+	noColor := fs.Bool("no-color", false, "Disable colors")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing arguments: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := []opik.Option{}
+	if *project != "" {
+		opts = append(opts, opik.WithProjectName(*project))
+	}
+
+	client, err := opik.NewClient(opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
+		os.Exit(1)
+	}
+
+	ui := &tui{
+		client:   client,
+		in:       bufio.NewScanner(os.Stdin),
+		out:      os.Stdout,
+		color:    !*noColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout),
+		pageSize: *pageSize,
+	}
+	if err := ui.run(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// tui is a line-driven terminal browser for traces and spans.
+// Each screen prints a numbered listing followed by a prompt; the user
+// selects entries by number and navigates with single-letter commands.
+type tui struct {
+	client   *opik.Client
+	in       *bufio.Scanner
+	out      io.Writer
+	color    bool
+	pageSize int
+
+	page   int
+	traces []*opik.TraceInfo
+}
+
+// errQuit ends the session from any screen.
+var errQuit = errors.New("quit")
+
+func (u *tui) run(ctx context.Context) error {
+	u.page = 1
+	if err := u.loadTraces(ctx); err != nil {
+		return err
+	}
+
+	for {
+		u.clear()
+		u.printTraceList()
+
+		cmd, arg, ok := u.prompt("traces")
+		if !ok {
+			return nil
+		}
+
+		switch cmd {
+		case "q":
+			return nil
+		case "n":
+			if len(u.traces) == u.pageSize {
+				u.page++
+				u.reloadTraces(ctx)
+			}
+		case "p":
+			if u.page > 1 {
+				u.page--
+				u.reloadTraces(ctx)
+			}
+		case "r":
+			u.reloadTraces(ctx)
+		case "?", "h":
+			u.printHelp()
+			u.pause()
+		default:
+			n, err := strconv.Atoi(cmd)
+			if err != nil || n < 1 || n > len(u.traces) {
+				u.errorf("unknown command %q (type ? for help)", strings.TrimSpace(cmd+" "+arg))
+				u.pause()
+				continue
+			}
+			if err := u.traceScreen(ctx, u.traces[n-1]); errors.Is(err, errQuit) {
+				return nil
+			}
+		}
+	}
+}
+
+func (u *tui) loadTraces(ctx context.Context) error {
+	traces, err := u.client.ListTraces(ctx, u.page, u.pageSize)
+	if err != nil {
+		return fmt.Errorf("listing traces: %w", err)
+	}
+	u.traces = traces
+	return nil
+}
+
+func (u *tui) reloadTraces(ctx context.Context) {
+	if err := u.loadTraces(ctx); err != nil {
+		u.errorf("%v", err)
+		u.pause()
+	}
+}
+
+func (u *tui) printTraceList() {
+	fmt.Fprintf(u.out, "%s (page %d)\n\n", u.style(ansiBold, "Traces in "+u.client.ProjectName()), u.page)
+	if len(u.traces) == 0 {
+		fmt.Fprintln(u.out, "  no traces")
+	}
+	for i, t := range u.traces {
+		fmt.Fprintf(u.out, "  %3d  %s  %-40s %s %s\n",
+			i+1,
+			u.style(ansiDim, shortID(t.ID)),
+			truncate(t.Name, 40),
+			u.style(ansiDim, t.StartTime.Local().Format(time.DateTime)),
+			u.style(ansiYellow, formatDuration(t.StartTime, t.EndTime)))
+	}
+	fmt.Fprintln(u.out)
+	fmt.Fprintln(u.out, u.style(ansiDim, "<n> open  n next  p prev  r refresh  ? help  q quit"))
+}
+
+func (u *tui) traceScreen(ctx context.Context, trace *opik.TraceInfo) error {
+	tree, err := u.client.GetTraceTree(ctx, trace.ID)
+	if err != nil {
+		u.errorf("loading spans: %v", err)
+		u.pause()
+		return nil
+	}
+	rows := spanRows(tree.Roots)
+
+	for {
+		u.clear()
+		fmt.Fprintf(u.out, "%s  %s  %s\n\n",
+			u.style(ansiBold, trace.Name),
+			u.style(ansiDim, trace.ID),
+			u.style(ansiYellow, formatDuration(trace.StartTime, trace.EndTime)))
+		if len(rows) == 0 {
+			fmt.Fprintln(u.out, "  no spans")
+		}
+		for i, row := range rows {
+			fmt.Fprintf(u.out, "  %3d  %s%s %s %s\n",
+				i+1,
+				u.style(ansiDim, row.prefix),
+				row.span.Name,
+				u.style(ansiCyan, "["+row.span.Type+"]"),
+				u.style(ansiYellow, formatDuration(row.span.StartTime, row.span.EndTime)))
+		}
+		fmt.Fprintln(u.out)
+		fmt.Fprintln(u.out, u.style(ansiDim, "<n> open span  i input/output  f <name> <value> [reason] feedback  b back  q quit"))
+
+		cmd, arg, ok := u.prompt("trace")
+		if !ok {
+			return errQuit
+		}
+
+		switch cmd {
+		case "q":
+			return errQuit
+		case "b":
+			return nil
+		case "i":
+			u.clear()
+			u.printPayloads(trace.Input, trace.Output, trace.Metadata)
+			u.pause()
+		case "f":
+			u.addFeedback(ctx, "trace", trace.ID, arg)
+		default:
+			n, err := strconv.Atoi(cmd)
+			if err != nil || n < 1 || n > len(rows) {
+				u.errorf("unknown command %q", strings.TrimSpace(cmd+" "+arg))
+				u.pause()
+				continue
+			}
+			if err := u.spanScreen(ctx, rows[n-1].span); errors.Is(err, errQuit) {
+				return errQuit
+			}
+		}
+	}
+}
+
+func (u *tui) spanScreen(ctx context.Context, span *opik.SpanInfo) error {
+	for {
+		u.clear()
+		fmt.Fprintf(u.out, "%s  %s\n", u.style(ansiBold, span.Name), u.style(ansiDim, span.ID))
+		fmt.Fprintf(u.out, "  type:     %s\n", span.Type)
+		if span.Model != "" {
+			fmt.Fprintf(u.out, "  model:    %s\n", span.Model)
+		}
+		if span.Provider != "" {
+			fmt.Fprintf(u.out, "  provider: %s\n", span.Provider)
+		}
+		fmt.Fprintf(u.out, "  duration: %s\n\n", formatDuration(span.StartTime, span.EndTime))
+		u.printPayloads(span.Input, span.Output, span.Metadata)
+		fmt.Fprintln(u.out, u.style(ansiDim, "f <name> <value> [reason] feedback  b back  q quit"))
+
+		cmd, arg, ok := u.prompt("span")
+		if !ok {
+			return errQuit
+		}
+
+		switch cmd {
+		case "q":
+			return errQuit
+		case "b":
+			return nil
+		case "f":
+			u.addFeedback(ctx, "span", span.ID, arg)
+		default:
+			u.errorf("unknown command %q", strings.TrimSpace(cmd+" "+arg))
+			u.pause()
+		}
+	}
+}
+
+func (u *tui) printPayloads(input, output, metadata any) {
+	for _, p := range []struct {
+		title string
+		value any
+	}{
+		{"Input", input},
+		{"Output", output},
+		{"Metadata", metadata},
+	} {
+		if p.value == nil {
+			continue
+		}
+		fmt.Fprintln(u.out, u.style(ansiBold, p.title))
+		data, err := json.MarshalIndent(p.value, "  ", "  ")
+		if err != nil {
+			fmt.Fprintf(u.out, "  %v\n\n", p.value)
+			continue
+		}
+		text := "  " + string(data)
+		if u.color {
+			text = highlightJSON(text)
+		}
+		fmt.Fprintf(u.out, "%s\n\n", text)
+	}
+}
+
+// addFeedback parses "<name> <value> [reason]" and logs a feedback score.
+func (u *tui) addFeedback(ctx context.Context, entityType, entityID, arg string) {
+	item, err := parseFeedback(entityType, entityID, arg)
+	if err != nil {
+		u.errorf("%v", err)
+		u.pause()
+		return
+	}
+
+	result, err := u.client.AddFeedbackScores(ctx, []opik.FeedbackBatchItem{item})
+	if err == nil {
+		err = result.Err()
+	}
+	if err != nil {
+		u.errorf("adding feedback: %v", err)
+	} else {
+		fmt.Fprintln(u.out, u.style(ansiGreen, fmt.Sprintf("Recorded %s = %g", item.Name, item.Value)))
+	}
+	u.pause()
+}
+
+func parseFeedback(entityType, entityID, arg string) (opik.FeedbackBatchItem, error) {
+	fields := strings.Fields(arg)
+	if len(fields) < 2 {
+		return opik.FeedbackBatchItem{}, fmt.Errorf("usage: f <name> <value> [reason]")
+	}
+	value, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return opik.FeedbackBatchItem{}, fmt.Errorf("invalid value %q: %w", fields[1], err)
+	}
+	return opik.FeedbackBatchItem{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Name:       fields[0],
+		Value:      value,
+		Reason:     strings.Join(fields[2:], " "),
+	}, nil
+}
+
+func (u *tui) printHelp() {
+	fmt.Fprintln(u.out, `
+Traces screen:
+  <n>        open trace number n
+  n / p      next / previous page
+  r          refresh
+
+Trace screen:
+  <n>        open span number n
+  i          show trace input, output and metadata
+  f <name> <value> [reason]
+             add a feedback score to the trace
+
+Span screen:
+  f <name> <value> [reason]
+             add a feedback score to the span
+
+Everywhere:
+  b          back
+  q          quit`)
+}
+
+// prompt reads a command line, returning the first word and the rest.
+// ok is false when input is exhausted.
+func (u *tui) prompt(screen string) (cmd, arg string, ok bool) {
+	fmt.Fprintf(u.out, "%s> ", screen)
+	if !u.in.Scan() {
+		fmt.Fprintln(u.out)
+		return "", "", false
+	}
+	line := strings.TrimSpace(u.in.Text())
+	cmd, arg, _ = strings.Cut(line, " ")
+	return strings.ToLower(cmd), strings.TrimSpace(arg), true
+}
+
+func (u *tui) pause() {
+	fmt.Fprint(u.out, u.style(ansiDim, "press enter to continue"))
+	u.in.Scan()
+}
+
+func (u *tui) clear() {
+	if u.color {
+		fmt.Fprint(u.out, ansiClear)
+	}
+}
+
+func (u *tui) errorf(format string, args ...any) {
+	fmt.Fprintln(u.out, u.style(ansiRed, fmt.Sprintf(format, args...)))
+}
+
+func (u *tui) style(code, s string) string {
+	if !u.color || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// spanRow is a span in display order with its tree-drawing prefix.
+type spanRow struct {
+	span   *opik.SpanInfo
+	prefix string
+}
+
+// spanRows flattens a span tree into display rows.
+func spanRows(roots []*opik.SpanNode) []spanRow {
+	var rows []spanRow
+	var walk func(nodes []*opik.SpanNode, indent string)
+	walk = func(nodes []*opik.SpanNode, indent string) {
+		for i, n := range nodes {
+			branch, next := "├─ ", "│  "
+			if i == len(nodes)-1 {
+				branch, next = "└─ ", "   "
+			}
+			rows = append(rows, spanRow{span: n.Span, prefix: indent + branch})
+			walk(n.Children, indent+next)
+		}
+	}
+	walk(roots, "")
+	return rows
+}
+
+// highlightJSON colors indented JSON: keys cyan, strings green, numbers
+// yellow, and true/false/null magenta.
+func highlightJSON(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '"':
+			j := i + 1
+			for j < len(text) && text[j] != '"' {
+				if text[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(text))
+			color := ansiGreen
+			if k := skipSpaces(text, j); k < len(text) && text[k] == ':' {
+				color = ansiCyan
+			}
+			b.WriteString(color + text[i:j] + ansiReset)
+			i = j
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(text) && strings.IndexByte("0123456789.eE+-", text[j]) >= 0 {
+				j++
+			}
+			b.WriteString(ansiYellow + text[i:j] + ansiReset)
+			i = j
+		case hasWordAt(text, i, "true"), hasWordAt(text, i, "false"), hasWordAt(text, i, "null"):
+			j := i
+			for j < len(text) && text[j] >= 'a' && text[j] <= 'z' {
+				j++
+			}
+			b.WriteString(ansiMagenta + text[i:j] + ansiReset)
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+func hasWordAt(s string, i int, word string) bool {
+	return strings.HasPrefix(s[i:], word)
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func formatDuration(start, end time.Time) string {
+	if start.IsZero() || end.IsZero() {
+		return "-"
+	}
+	d := end.Sub(start)
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
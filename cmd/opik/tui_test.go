@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+func TestSpanRows(t *testing.T) {
+	base := time.Now()
+	spans := []*opik.SpanInfo{
+		{ID: "c", ParentSpanID: "a", Name: "child-2", StartTime: base.Add(2 * time.Second)},
+		{ID: "a", Name: "root", StartTime: base},
+		{ID: "b", ParentSpanID: "a", Name: "child-1", StartTime: base.Add(time.Second)},
+		{ID: "d", ParentSpanID: "b", Name: "grandchild", StartTime: base.Add(time.Second)},
+		{ID: "e", ParentSpanID: "missing", Name: "orphan", StartTime: base.Add(3 * time.Second)},
+	}
+
+	rows := spanRows(opik.BuildSpanTree(spans))
+
+	var got []string
+	for _, r := range rows {
+		got = append(got, r.prefix+r.span.Name)
+	}
+	want := []string{
+		"├─ root",
+		"│  ├─ child-1",
+		"│  │  └─ grandchild",
+		"│  └─ child-2",
+		"└─ orphan",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestHighlightJSON(t *testing.T) {
+	got := highlightJSON(`{"key": "va\"l", "n": -1.5, "ok": true, "x": null}`)
+
+	for _, want := range []string{
+		ansiCyan + `"key"` + ansiReset,
+		ansiGreen + `"va\"l"` + ansiReset,
+		ansiYellow + `-1.5` + ansiReset,
+		ansiMagenta + `true` + ansiReset,
+		ansiMagenta + `null` + ansiReset,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("highlightJSON output missing %q:\n%q", want, got)
+		}
+	}
+}
+
+func TestParseFeedback(t *testing.T) {
+	item, err := parseFeedback("span", "id-1", "quality 0.8 looks good")
+	if err != nil {
+		t.Fatalf("parseFeedback error: %v", err)
+	}
+	if item.Name != "quality" || item.Value != 0.8 || item.Reason != "looks good" || item.EntityType != "span" {
+		t.Errorf("item = %+v", item)
+	}
+
+	for _, arg := range []string{"", "quality", "quality high"} {
+		if _, err := parseFeedback("trace", "id-1", arg); err == nil {
+			t.Errorf("parseFeedback(%q) expected error", arg)
+		}
+	}
+}
+
+func TestTUIPrompt(t *testing.T) {
+	var out bytes.Buffer
+	u := &tui{in: bufio.NewScanner(strings.NewReader("F quality 1\n")), out: &out}
+
+	cmd, arg, ok := u.prompt("trace")
+	if !ok || cmd != "f" || arg != "quality 1" {
+		t.Errorf("prompt = %q, %q, %v", cmd, arg, ok)
+	}
+	if _, _, ok := u.prompt("trace"); ok {
+		t.Error("prompt should report end of input")
+	}
+	if !strings.HasPrefix(out.String(), "trace> ") {
+		t.Errorf("output = %q", out.String())
+	}
+}
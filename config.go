@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/plexusone/opik-go/evaluation"
 )
 
 const (
@@ -52,6 +54,32 @@ type Config struct {
 	// CheckTLSCertificate enables TLS certificate verification.
 	// Defaults to true.
 	CheckTLSCertificate bool
+
+	// SampleRate is the share of traces sent, above 0 and at most 1, as by
+	// a ProbabilitySampler. Zero, the default, sends every trace. A sampler
+	// set with WithTraceSampler takes precedence.
+	SampleRate float64
+
+	// CaptureMode sets which payload parts of traces and spans are sent;
+	// see WithCaptureMode.
+	CaptureMode CaptureMode
+
+	// Redact names built-in redactors to run on every trace and span:
+	// "emails", "phone_numbers", "credit_cards", and "api_keys". They run
+	// before the redactors added with WithRedactor.
+	Redact []string
+
+	// MaxPayloadBytes limits the size of trace and span inputs and outputs;
+	// see WithMaxPayloadBytes. Zero means no limit.
+	MaxPayloadBytes int
+
+	// Batching, if set, queues trace and span writes and sends them in
+	// batches; see WithBatching, which takes precedence.
+	Batching *BatcherConfig
+
+	// envProblems are environment variables that did not parse, reported
+	// by NewClient.
+	envProblems evaluation.Problems
 }
 
 // NewConfig creates a new Config with default values.
@@ -67,6 +95,9 @@ func NewConfig() *Config {
 }
 
 // LoadConfig loads configuration from environment variables and config file.
+// Besides the connection settings, the environment can tune tracing per
+// deployment without code changes: see EnvSampleRate and the variables
+// after it. Values that do not parse are reported by NewClient.
 // Priority order (highest to lowest):
 // 1. Explicitly set values (via options)
 // 2. Environment variables
@@ -110,6 +141,7 @@ func (c *Config) loadFromEnv() {
 	if disable := os.Getenv(EnvTraceDisable); disable != "" {
 		c.TracingDisabled = strings.ToLower(disable) == "true" || disable == "1"
 	}
+	c.loadTracingFromEnv()
 }
 
 // loadFromFile loads configuration from the config file.
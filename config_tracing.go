@@ -0,0 +1,221 @@
+package opik
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// Environment variables of tracing behavior, read by LoadConfig. Invalid
+// values are reported by NewClient.
+const (
+	// EnvSampleRate is the share of traces sent, such as 0.05.
+	EnvSampleRate = "OPIK_SAMPLE_RATE"
+	// EnvCaptureMode is the capture mode: all, input_only, output_only, or
+	// metadata_only.
+	EnvCaptureMode = "OPIK_CAPTURE_MODE"
+	// EnvRedact is a comma-separated list of built-in redactors: emails,
+	// phone_numbers, credit_cards, and api_keys.
+	EnvRedact = "OPIK_REDACT"
+	// EnvMaxPayloadBytes is the size limit of trace and span inputs and
+	// outputs.
+	EnvMaxPayloadBytes = "OPIK_MAX_PAYLOAD_BYTES"
+	// EnvBatching enables or disables batching with true or false.
+	EnvBatching = "OPIK_BATCHING"
+	// EnvBatchSize, EnvBatchFlushInterval, EnvBatchMaxQueueDepth, and
+	// EnvBatchMaxRetries set the fields of the batching configuration, and
+	// enable batching unless EnvBatching disables it. The flush interval is
+	// a duration such as "2s".
+	EnvBatchSize          = "OPIK_BATCH_SIZE"
+	EnvBatchFlushInterval = "OPIK_BATCH_FLUSH_INTERVAL"
+	EnvBatchMaxQueueDepth = "OPIK_BATCH_MAX_QUEUE_DEPTH"
+	EnvBatchMaxRetries    = "OPIK_BATCH_MAX_RETRIES"
+)
+
+// builtinRedactors are the redactors Config.Redact can name.
+var builtinRedactors = map[string]RedactFunc{
+	"emails":        RedactEmails,
+	"phone_numbers": RedactPhoneNumbers,
+	"credit_cards":  RedactCreditCards,
+	"api_keys":      RedactAPIKeys,
+}
+
+// captureModes are the capture modes by name.
+var captureModes = map[string]CaptureMode{
+	CaptureAll.String():          CaptureAll,
+	CaptureInputOnly.String():    CaptureInputOnly,
+	CaptureOutputOnly.String():   CaptureOutputOnly,
+	CaptureMetadataOnly.String(): CaptureMetadataOnly,
+}
+
+// WithMaxPayloadBytes limits the size of the JSON of each trace and span
+// input and output. A larger payload is sent as
+//
+//	{"withheld": "size_limit", "size": <bytes>, "sha256": "<hash of the JSON payload>"}
+//
+// so one oversized document cannot slow down ingestion. Zero, the default,
+// means no limit.
+func WithMaxPayloadBytes(n int) Option {
+	return func(o *clientOptions) {
+		o.config.MaxPayloadBytes = n
+	}
+}
+
+// loadTracingFromEnv loads the tracing settings from environment
+// variables, recording values that do not parse.
+func (c *Config) loadTracingFromEnv() {
+	if v := os.Getenv(EnvSampleRate); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		switch {
+		case err != nil:
+			c.envProblems.Addf("%s: %q is not a number", EnvSampleRate, v)
+		case rate <= 0:
+			// Zero would send every trace, the opposite of what was meant.
+			c.envProblems.Addf("%s: %q is not above 0; set %s to disable tracing", EnvSampleRate, v, EnvTraceDisable)
+		}
+		c.SampleRate = rate
+	}
+	if v := os.Getenv(EnvCaptureMode); v != "" {
+		mode, ok := captureModes[strings.ToLower(v)]
+		if !ok {
+			c.envProblems.Addf("%s: unknown capture mode %q", EnvCaptureMode, v)
+		}
+		c.CaptureMode = mode
+	}
+	if v := os.Getenv(EnvRedact); v != "" {
+		c.Redact = nil
+		for name := range strings.SplitSeq(v, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				c.Redact = append(c.Redact, name)
+			}
+		}
+	}
+	c.MaxPayloadBytes = c.envInt(EnvMaxPayloadBytes, c.MaxPayloadBytes)
+
+	batching := DefaultBatcherConfig()
+	if c.Batching != nil {
+		batching = *c.Batching
+	}
+	set := false
+	for _, name := range []string{EnvBatchSize, EnvBatchFlushInterval, EnvBatchMaxQueueDepth, EnvBatchMaxRetries} {
+		set = set || os.Getenv(name) != ""
+	}
+	batching.MaxBatchSize = c.envInt(EnvBatchSize, batching.MaxBatchSize)
+	batching.MaxQueueDepth = c.envInt(EnvBatchMaxQueueDepth, batching.MaxQueueDepth)
+	batching.MaxRetries = c.envInt(EnvBatchMaxRetries, batching.MaxRetries)
+	if v := os.Getenv(EnvBatchFlushInterval); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			c.envProblems.Addf("%s: %q is not a duration", EnvBatchFlushInterval, v)
+		}
+		batching.FlushInterval = d
+	}
+	if v := os.Getenv(EnvBatching); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			c.envProblems.Addf("%s: %q is not true or false", EnvBatching, v)
+		}
+		set = enabled
+		if !enabled {
+			c.Batching = nil
+		}
+	}
+	if set {
+		c.Batching = &batching
+	}
+}
+
+// envInt returns the integer value of the environment variable name, or
+// fallback if it is not set.
+func (c *Config) envInt(name string, fallback int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		c.envProblems.Addf("%s: %q is not an integer", name, v)
+		return fallback
+	}
+	return n
+}
+
+// tracingProblems returns the problems of the tracing settings, including
+// environment variables that did not parse.
+func (c *Config) tracingProblems() evaluation.Problems {
+	p := slices.Clone(c.envProblems)
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		p.Addf("sample rate must be between 0 and 1: %v", c.SampleRate)
+	}
+	if c.CaptureMode < CaptureAll || c.CaptureMode > CaptureMetadataOnly {
+		p.Addf("unknown capture mode %d", int(c.CaptureMode))
+	}
+	for _, name := range c.Redact {
+		if _, ok := builtinRedactors[name]; !ok {
+			p.Addf("unknown redactor %q; want emails, phone_numbers, credit_cards, or api_keys", name)
+		}
+	}
+	if c.MaxPayloadBytes < 0 {
+		p.Addf("max payload bytes must not be negative: %d", c.MaxPayloadBytes)
+	}
+	if b := c.Batching; b != nil {
+		if b.MaxBatchSize <= 0 {
+			p.Addf("batching MaxBatchSize must be positive: %d", b.MaxBatchSize)
+		}
+		if b.FlushInterval <= 0 {
+			p.Addf("batching FlushInterval must be positive: %v", b.FlushInterval)
+		}
+		if b.MaxQueueDepth < 0 {
+			p.Addf("batching MaxQueueDepth must not be negative: %d", b.MaxQueueDepth)
+		}
+	}
+	return p
+}
+
+// applyTracing applies the tracing settings of the configuration to
+// options. Settings made with options take precedence: a sampler set with
+// WithTraceSampler replaces the sample rate, and batching set with
+// WithBatching replaces the configuration's. Redactors named in the
+// configuration run before those added with WithRedactor.
+func (o *clientOptions) applyTracing() {
+	c := o.config
+	if o.sampler == nil && c.SampleRate > 0 && c.SampleRate < 1 {
+		o.sampler = ProbabilitySampler(c.SampleRate)
+	}
+	if len(c.Redact) > 0 {
+		redactors := make([]RedactFunc, 0, len(c.Redact)+len(o.redactors))
+		for _, name := range c.Redact {
+			redactors = append(redactors, builtinRedactors[name])
+		}
+		o.redactors = append(redactors, o.redactors...)
+	}
+	if o.batching == nil && c.Batching != nil {
+		batching := *c.Batching
+		if batching.MaxQueueDepth == 0 {
+			batching.MaxQueueDepth = 10 * batching.MaxBatchSize
+		}
+		o.batching = &batching
+	}
+}
+
+// limitPayload returns the JSON payload data, or a marker with its size and
+// hash if it is larger than maxBytes. Zero maxBytes means no limit.
+func limitPayload(data []byte, maxBytes int) []byte {
+	if maxBytes <= 0 || len(data) <= maxBytes {
+		return data
+	}
+	sum := sha256.Sum256(data)
+	withheld, _ := json.Marshal(struct {
+		Withheld string `json:"withheld"`
+		Size     int    `json:"size"`
+		SHA256   string `json:"sha256"`
+	}{"size_limit", len(data), hex.EncodeToString(sum[:])})
+	return withheld
+}
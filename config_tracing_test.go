@@ -0,0 +1,177 @@
+package opik
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigTracingFromEnv(t *testing.T) {
+	t.Setenv(EnvSampleRate, "0.05")
+	t.Setenv(EnvCaptureMode, "METADATA_ONLY")
+	t.Setenv(EnvRedact, " emails, api_keys ,")
+	t.Setenv(EnvMaxPayloadBytes, "4096")
+	t.Setenv(EnvBatchSize, "50")
+	t.Setenv(EnvBatchFlushInterval, "2s")
+
+	cfg := LoadConfig()
+	if cfg.SampleRate != 0.05 {
+		t.Errorf("SampleRate = %v, want 0.05", cfg.SampleRate)
+	}
+	if cfg.CaptureMode != CaptureMetadataOnly {
+		t.Errorf("CaptureMode = %v, want metadata_only", cfg.CaptureMode)
+	}
+	if want := []string{"emails", "api_keys"}; !reflect.DeepEqual(cfg.Redact, want) {
+		t.Errorf("Redact = %v, want %v", cfg.Redact, want)
+	}
+	if cfg.MaxPayloadBytes != 4096 {
+		t.Errorf("MaxPayloadBytes = %d, want 4096", cfg.MaxPayloadBytes)
+	}
+	want := DefaultBatcherConfig()
+	want.MaxBatchSize = 50
+	want.FlushInterval = 2 * time.Second
+	if cfg.Batching == nil || *cfg.Batching != want {
+		t.Errorf("Batching = %+v, want %+v", cfg.Batching, want)
+	}
+	if len(cfg.envProblems) != 0 {
+		t.Errorf("envProblems = %v, want none", cfg.envProblems)
+	}
+}
+
+func TestLoadConfigBatchingToggle(t *testing.T) {
+	t.Setenv(EnvBatching, "true")
+	if cfg := LoadConfig(); cfg.Batching == nil || *cfg.Batching != DefaultBatcherConfig() {
+		t.Errorf("Batching = %+v, want the defaults", cfg.Batching)
+	}
+
+	t.Setenv(EnvBatching, "false")
+	t.Setenv(EnvBatchSize, "50")
+	if cfg := LoadConfig(); cfg.Batching != nil {
+		t.Errorf("Batching = %+v, want nil when disabled", cfg.Batching)
+	}
+}
+
+func TestNewClientReportsTracingEnvErrors(t *testing.T) {
+	t.Setenv(EnvSampleRate, "often")
+	t.Setenv(EnvCaptureMode, "everything")
+	t.Setenv(EnvRedact, "emails,names")
+	t.Setenv(EnvMaxPayloadBytes, "1MB")
+	t.Setenv(EnvBatchFlushInterval, "soon")
+	t.Setenv(EnvBatching, "maybe")
+
+	_, err := NewClient(WithURL("http://localhost"))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("NewClient error = %v, want a ValidationError", err)
+	}
+	for _, want := range []string{
+		`OPIK_SAMPLE_RATE: "often" is not a number`,
+		`OPIK_CAPTURE_MODE: unknown capture mode "everything"`,
+		`unknown redactor "names"`,
+		`OPIK_MAX_PAYLOAD_BYTES: "1MB" is not an integer`,
+		`OPIK_BATCH_FLUSH_INTERVAL: "soon" is not a duration`,
+		`OPIK_BATCHING: "maybe" is not true or false`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("NewClient error = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestLoadConfigSampleRateZero(t *testing.T) {
+	t.Setenv(EnvSampleRate, "0")
+	if _, err := NewClient(WithURL("http://localhost")); err == nil || !strings.Contains(err.Error(), "set OPIK_TRACK_DISABLE") {
+		t.Errorf("NewClient error = %v, want a pointer to OPIK_TRACK_DISABLE", err)
+	}
+}
+
+func TestNewClientAppliesTracingConfig(t *testing.T) {
+	t.Setenv(EnvSampleRate, "0.5")
+	t.Setenv(EnvCaptureMode, "input_only")
+	t.Setenv(EnvRedact, "emails")
+	t.Setenv(EnvMaxPayloadBytes, "100")
+	t.Setenv(EnvBatchSize, "10")
+
+	client, err := NewClient(WithURL("http://localhost"), WithRedactor(func(v any) any {
+		return strings.ReplaceAll(v.(string), "[REDACTED_EMAIL]", "<email>")
+	}))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	defer client.Close(t.Context())
+
+	if client.sampler == nil {
+		t.Error("sampler = nil, want a ProbabilitySampler")
+	}
+	if client.CaptureMode() != CaptureInputOnly {
+		t.Errorf("CaptureMode() = %v, want input_only", client.CaptureMode())
+	}
+	if got := client.Redact("mail ada@example.com"); got != "mail <email>" {
+		t.Errorf("Redact = %q, want the email redactor first", got)
+	}
+	if client.maxPayloadBytes != 100 {
+		t.Errorf("maxPayloadBytes = %d, want 100", client.maxPayloadBytes)
+	}
+	if client.ingest == nil || client.ingest.config.MaxBatchSize != 10 || client.ingest.config.MaxQueueDepth != 100 {
+		t.Errorf("ingest = %+v, want batches of 10 queued up to 100", client.ingest)
+	}
+}
+
+func TestTracingOptionsOverrideConfig(t *testing.T) {
+	t.Setenv(EnvSampleRate, "0.5")
+	t.Setenv(EnvCaptureMode, "input_only")
+	t.Setenv(EnvBatchSize, "10")
+
+	batching := DefaultBatcherConfig()
+	batching.MaxBatchSize = 3
+	client, err := NewClient(
+		WithURL("http://localhost"),
+		WithTraceSampler(NeverSample()),
+		WithCaptureMode(CaptureAll),
+		WithBatching(batching),
+	)
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	defer client.Close(t.Context())
+
+	if client.sampler.Sample(t.Context(), SamplingParams{}) != SamplingDrop {
+		t.Error("sampler is not the one from WithTraceSampler")
+	}
+	if client.CaptureMode() != CaptureAll {
+		t.Errorf("CaptureMode() = %v, want all", client.CaptureMode())
+	}
+	if client.ingest.config.MaxBatchSize != 3 {
+		t.Errorf("MaxBatchSize = %d, want 3 from WithBatching", client.ingest.config.MaxBatchSize)
+	}
+}
+
+func TestLimitPayload(t *testing.T) {
+	data := []byte(`"` + strings.Repeat("x", 20) + `"`)
+	if got := limitPayload(data, 0); string(got) != string(data) {
+		t.Errorf("limitPayload without a limit = %s", got)
+	}
+	if got := limitPayload(data, len(data)); string(got) != string(data) {
+		t.Errorf("limitPayload at the limit = %s", got)
+	}
+	sum := sha256.Sum256(data)
+	want := `{"withheld":"size_limit","size":22,"sha256":"` + hex.EncodeToString(sum[:]) + `"}`
+	if got := limitPayload(data, 10); string(got) != want {
+		t.Errorf("limitPayload over the limit = %s, want %s", got, want)
+	}
+
+	client, err := NewClient(WithURL("http://localhost"), WithMaxPayloadBytes(10))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if got := client.marshalPayload(nil, strings.Repeat("x", 20), false); string(got) != want {
+		t.Errorf("marshalPayload = %s, want %s", got, want)
+	}
+	if _, err := NewClient(WithURL("http://localhost"), WithMaxPayloadBytes(-1)); err == nil {
+		t.Error("NewClient with a negative payload limit succeeded")
+	}
+}
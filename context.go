@@ -11,6 +11,9 @@ const (
 	traceContextKey contextKey = iota
 	spanContextKey
 	clientContextKey
+	threadIDContextKey
+	remoteTraceContextKey
+	projectContextKey
 )
 
 // ContextWithTrace returns a new context with the trace attached.
@@ -52,10 +55,47 @@ func ClientFromContext(ctx context.Context) *Client {
 	return nil
 }
 
+// ContextWithThreadID returns a new context with the conversation thread ID
+// attached. Traces created with the context, or any context derived from
+// it, join the thread unless they set their own with WithTraceThreadID, so
+// a request handler can set the thread once for every trace it starts.
+func ContextWithThreadID(ctx context.Context, threadID string) context.Context {
+	return context.WithValue(ctx, threadIDContextKey, threadID)
+}
+
+// ThreadIDFromContext returns the thread ID from the context, or "" if none.
+func ThreadIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(threadIDContextKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// ContextWithProject returns a new context that sends traces created with
+// it, or any context derived from it, to the given project, so one client
+// can log each request to a different project, such as one per customer
+// tier. A project set with WithTraceProject takes precedence, and the
+// client's project routes are not applied. Spans always go to the project
+// of their trace.
+func ContextWithProject(ctx context.Context, projectName string) context.Context {
+	return context.WithValue(ctx, projectContextKey, projectName)
+}
+
+// ProjectFromContext returns the project set with ContextWithProject, or ""
+// if none.
+func ProjectFromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(projectContextKey).(string); ok {
+		return name
+	}
+	return ""
+}
+
 // StartTrace creates a new trace and attaches it to the context.
-// Returns the new context and the trace.
+// Returns the new context and the trace. If tracing is disabled, the trace
+// is an inert one from NoopTracer, so spans started from the context are
+// inert as well.
 func StartTrace(ctx context.Context, client *Client, name string, opts ...TraceOption) (context.Context, *Trace, error) {
-	trace, err := client.Trace(ctx, name, opts...)
+	trace, err := client.Tracer().Trace(ctx, name, opts...)
 	if err != nil {
 		return ctx, nil, err
 	}
@@ -65,7 +105,8 @@ func StartTrace(ctx context.Context, client *Client, name string, opts ...TraceO
 }
 
 // StartSpan creates a new span and attaches it to the context.
-// If there is no parent span in the context, it uses the trace from context.
+// If there is no parent span in the context, it uses the trace from context,
+// or the remote trace from ExtractTraceContext with the client from context.
 // Returns the new context and the span.
 func StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span, error) {
 	// Try to get parent span first
@@ -90,6 +131,17 @@ func StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Co
 		return newCtx, span, nil
 	}
 
+	// Fall back to a remote trace
+	remote, ok := ctx.Value(remoteTraceContextKey).(DistributedTraceHeaders)
+	if client := ClientFromContext(ctx); ok && client != nil {
+		span, err := client.createSpanWithParent(ctx, remote.TraceID, remote.ParentSpanID, name, opts...)
+		if err != nil {
+			return ctx, nil, err
+		}
+		newCtx := ContextWithSpan(ctx, span)
+		return newCtx, span, nil
+	}
+
 	return ctx, nil, ErrNoActiveTrace
 }
 
@@ -111,6 +163,16 @@ func EndSpan(ctx context.Context, opts ...SpanOption) error {
 	return span.End(ctx, opts...)
 }
 
+// AddSpanEvent adds an event to the current span in the context; see
+// Span.AddEvent.
+func AddSpanEvent(ctx context.Context, name string, attributes map[string]any) error {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return ErrNoActiveSpan
+	}
+	return span.AddEvent(ctx, name, attributes)
+}
+
 // CurrentTraceID returns the current trace ID from the context, or empty string if none.
 func CurrentTraceID(ctx context.Context) string {
 	if trace := TraceFromContext(ctx); trace != nil {
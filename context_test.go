@@ -318,3 +318,55 @@ func TestContextWithCancellation(t *testing.T) {
 		t.Error("TraceFromContext returned nil after cancellation")
 	}
 }
+
+func TestContextWithProject(t *testing.T) {
+	ts, created := newCreateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithProjectName("main"),
+		WithProjectRoutes(ProjectRoute{Project: "canary", Weight: 1}))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := ContextWithProject(context.Background(), "tier-gold")
+	if got := ProjectFromContext(ctx); got != "tier-gold" {
+		t.Errorf("ProjectFromContext = %q, want tier-gold", got)
+	}
+	if got := ProjectFromContext(context.Background()); got != "" {
+		t.Errorf("ProjectFromContext = %q, want empty", got)
+	}
+
+	// The context project replaces the default project and the routes.
+	ctx, trace, err := StartTrace(ctx, client, "request")
+	if err != nil {
+		t.Fatalf("StartTrace error: %v", err)
+	}
+	if _, _, err := StartSpan(ctx, "step"); err != nil {
+		t.Fatalf("StartSpan error: %v", err)
+	}
+	if trace.ProjectName() != "tier-gold" || created("POST /v1/private/traces/batch").ProjectName != "tier-gold" {
+		t.Errorf("trace project = %q", trace.ProjectName())
+	}
+	if got := created("POST /v1/private/spans/batch").ProjectName; got != "tier-gold" {
+		t.Errorf("span project = %q, want tier-gold", got)
+	}
+
+	// An explicit project takes precedence.
+	explicit, err := client.Trace(ctx, "request", WithTraceProject("audit"))
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	if explicit.ProjectName() != "audit" {
+		t.Errorf("explicit trace project = %q, want audit", explicit.ProjectName())
+	}
+
+	// Spans continuing a remote trace go to the context project.
+	remote := ExtractTraceContext(ContextWithClient(ContextWithProject(context.Background(), "tier-silver"), client),
+		traceHeader(HeaderTraceID, trace.ID()))
+	if _, _, err := StartSpan(remote, "handle"); err != nil {
+		t.Fatalf("StartSpan error: %v", err)
+	}
+	if got := created("POST /v1/private/spans/batch").ProjectName; got != "tier-silver" {
+		t.Errorf("continued span project = %q, want tier-silver", got)
+	}
+}
@@ -0,0 +1,159 @@
+// Package cost computes the cost of LLM calls from their token usage.
+//
+// A Price is a model's price in USD per million tokens, and a Table maps
+// model names to prices. Default returns the built-in table, which covers
+// current OpenAI, Anthropic, and Google models at their published list
+// prices. Provider prices change, and negotiated or batch prices differ, so
+// opik.WithCostTable and opik.RegisterModelPrice override it.
+//
+// Usage maps use the keys of opik.NormalizeUsage, which are the same as the
+// constants of this package:
+//
+//	price, _ := cost.Lookup("openai", "gpt-4o-mini-2024-07-18")
+//	usd := price.Cost(map[string]int{
+//	    cost.PromptTokens:     1200,
+//	    cost.CompletionTokens: 300,
+//	})
+package cost
+
+import "strings"
+
+// Usage keys read by Price.
+const (
+	PromptTokens     = "prompt_tokens"
+	CompletionTokens = "completion_tokens"
+	// CacheReadTokens counts prompt tokens served from the provider's prompt cache.
+	CacheReadTokens = "cache_read_tokens"
+	// CacheWriteTokens counts prompt tokens written to the provider's prompt cache.
+	CacheWriteTokens = "cache_write_tokens"
+	// ReasoningTokens counts completion tokens spent on hidden reasoning.
+	// They are included in CompletionTokens.
+	ReasoningTokens = "reasoning_tokens"
+)
+
+// Price is the price of a model in USD per million tokens.
+// Zero cache prices mean cached tokens are billed at the input price, and a
+// zero reasoning price means reasoning tokens are billed at the output price.
+type Price struct {
+	Input      float64
+	Output     float64
+	CacheRead  float64
+	CacheWrite float64
+	Reasoning  float64
+}
+
+// Cost returns the cost in USD of a call with the given usage.
+func (p Price) Cost(usage map[string]int) float64 {
+	cacheRead := usage[CacheReadTokens]
+	cacheWrite := usage[CacheWriteTokens]
+	uncached := max(usage[PromptTokens]-cacheRead-cacheWrite, 0)
+	reasoning := min(usage[ReasoningTokens], usage[CompletionTokens])
+
+	cost := float64(uncached)*p.Input +
+		float64(cacheRead)*p.cacheReadPrice() +
+		float64(cacheWrite)*p.cacheWritePrice() +
+		float64(usage[CompletionTokens]-reasoning)*p.Output +
+		float64(reasoning)*p.reasoningPrice()
+	return cost / 1e6
+}
+
+// CacheSavings returns how much less the call cost in USD than it would have
+// without prompt caching. It is negative if cache writes cost more than they saved.
+func (p Price) CacheSavings(usage map[string]int) float64 {
+	uncached := make(map[string]int, len(usage))
+	for k, v := range usage {
+		uncached[k] = v
+	}
+	delete(uncached, CacheReadTokens)
+	delete(uncached, CacheWriteTokens)
+	return p.Cost(uncached) - p.Cost(usage)
+}
+
+// ReasoningCost returns the cost in USD of the reasoning tokens in usage.
+func (p Price) ReasoningCost(usage map[string]int) float64 {
+	reasoning := min(usage[ReasoningTokens], usage[CompletionTokens])
+	return float64(reasoning) * p.reasoningPrice() / 1e6
+}
+
+func (p Price) reasoningPrice() float64 {
+	if p.Reasoning == 0 {
+		return p.Output
+	}
+	return p.Reasoning
+}
+
+func (p Price) cacheReadPrice() float64 {
+	if p.CacheRead == 0 {
+		return p.Input
+	}
+	return p.CacheRead
+}
+
+func (p Price) cacheWritePrice() float64 {
+	if p.CacheWrite == 0 {
+		return p.Input
+	}
+	return p.CacheWrite
+}
+
+// Table maps model names to prices. A name qualified by its provider, as in
+// "azure/gpt-4o", prices the model at that provider only.
+type Table map[string]Price
+
+// Lookup returns the price of model at provider. The provider-qualified name
+// is tried before the bare one, and each matches the model name exactly or,
+// failing that, the longest name that prefixes it before a hyphen, so
+// "claude-sonnet-4" prices "claude-sonnet-4-20250514". A model name that is
+// itself qualified, as gateways such as OpenRouter report them, is looked up
+// as the qualified name and then without its provider.
+func (t Table) Lookup(provider, model string) (Price, bool) {
+	if model == "" || len(t) == 0 {
+		return Price{}, false
+	}
+	var names []string
+	if provider != "" {
+		names = append(names, strings.ToLower(provider)+"/"+model)
+	}
+	names = append(names, model)
+	if i := strings.LastIndexByte(model, '/'); i >= 0 {
+		names = append(names, model[i+1:])
+	}
+	for _, name := range names {
+		if p, ok := t.match(name); ok {
+			return p, true
+		}
+	}
+	return Price{}, false
+}
+
+// match returns the price of the entry that equals name or is its longest
+// prefix before a hyphen.
+func (t Table) match(name string) (Price, bool) {
+	if p, ok := t[name]; ok {
+		return p, true
+	}
+	var best string
+	for entry := range t {
+		if strings.HasPrefix(name, entry+"-") && len(entry) > len(best) {
+			best = entry
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t[best], true
+}
+
+// Default returns a copy of the built-in price table.
+func Default() Table {
+	t := make(Table, len(builtin))
+	for name, p := range builtin {
+		t[name] = p
+	}
+	return t
+}
+
+// Lookup returns the built-in price of model at provider, as Table.Lookup.
+func Lookup(provider, model string) (Price, bool) {
+	return builtin.Lookup(provider, model)
+}
@@ -0,0 +1,71 @@
+package cost
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTableLookup(t *testing.T) {
+	table := Table{
+		"model":       {Input: 1},
+		"model-large": {Input: 2},
+		"azure/model": {Input: 3},
+	}
+	tests := []struct {
+		provider, model string
+		want            float64
+		found           bool
+	}{
+		{"", "model", 1, true},
+		{"", "model-20250101", 1, true},
+		{"", "model-large-20250101", 2, true},
+		{"openai", "model", 1, true},
+		{"azure", "model-20250101", 3, true},
+		{"Azure", "model", 3, true},
+		{"", "azure/model", 3, true},
+		{"", "openrouter/model-large", 2, true},
+		{"", "modelx", 0, false},
+		{"azure", "", 0, false},
+	}
+	for _, tt := range tests {
+		price, ok := table.Lookup(tt.provider, tt.model)
+		if ok != tt.found || price.Input != tt.want {
+			t.Errorf("Lookup(%q, %q) = %v, %v; want %v, %v", tt.provider, tt.model, price.Input, ok, tt.want, tt.found)
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	for _, model := range []string{"gpt-4o-mini-2024-07-18", "claude-sonnet-4-5-20250929", "gemini-2.5-flash", "o3-mini"} {
+		if _, ok := Lookup("", model); !ok {
+			t.Errorf("no built-in price for %q", model)
+		}
+	}
+	mini, _ := Lookup("openai", "gpt-4o-mini")
+	if full, _ := Lookup("openai", "gpt-4o"); mini == full {
+		t.Error("gpt-4o-mini is priced as gpt-4o")
+	}
+
+	table := Default()
+	table["gpt-4o"] = Price{}
+	if p, _ := Lookup("", "gpt-4o"); p.Input == 0 {
+		t.Error("changing the Default table changed the built-in prices")
+	}
+
+	for name, p := range builtin {
+		if p.Input <= 0 || p.Output < 0 || p.CacheRead < 0 || p.CacheWrite < 0 {
+			t.Errorf("built-in price for %q = %+v", name, p)
+		}
+	}
+}
+
+func TestPriceCost(t *testing.T) {
+	p := Price{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75}
+	usage := map[string]int{PromptTokens: 3_000_000, CompletionTokens: 1_000_000, CacheReadTokens: 1_000_000, CacheWriteTokens: 1_000_000}
+	if got := p.Cost(usage); math.Abs(got-(3+0.3+3.75+15)) > 1e-9 {
+		t.Errorf("Cost = %v, want 22.05", got)
+	}
+	if got := p.CacheSavings(usage); math.Abs(got-(9+15-22.05)) > 1e-9 {
+		t.Errorf("CacheSavings = %v, want 1.95", got)
+	}
+}
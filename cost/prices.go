@@ -0,0 +1,48 @@
+package cost
+
+// builtin holds published list prices in USD per million tokens, for
+// standard (not batch) requests and prompts within the models' base
+// context tier.
+var builtin = Table{
+	// OpenAI
+	"gpt-5":                  {Input: 1.25, Output: 10, CacheRead: 0.125},
+	"gpt-5-mini":             {Input: 0.25, Output: 2, CacheRead: 0.025},
+	"gpt-5-nano":             {Input: 0.05, Output: 0.4, CacheRead: 0.005},
+	"gpt-4.1":                {Input: 2, Output: 8, CacheRead: 0.5},
+	"gpt-4.1-mini":           {Input: 0.4, Output: 1.6, CacheRead: 0.1},
+	"gpt-4.1-nano":           {Input: 0.1, Output: 0.4, CacheRead: 0.025},
+	"gpt-4o":                 {Input: 2.5, Output: 10, CacheRead: 1.25},
+	"gpt-4o-mini":            {Input: 0.15, Output: 0.6, CacheRead: 0.075},
+	"gpt-4-turbo":            {Input: 10, Output: 30},
+	"gpt-4":                  {Input: 30, Output: 60},
+	"gpt-3.5-turbo":          {Input: 0.5, Output: 1.5},
+	"o1":                     {Input: 15, Output: 60, CacheRead: 7.5},
+	"o1-mini":                {Input: 1.1, Output: 4.4, CacheRead: 0.55},
+	"o3":                     {Input: 2, Output: 8, CacheRead: 0.5},
+	"o3-mini":                {Input: 1.1, Output: 4.4, CacheRead: 0.55},
+	"o4-mini":                {Input: 1.1, Output: 4.4, CacheRead: 0.275},
+	"text-embedding-3-small": {Input: 0.02},
+	"text-embedding-3-large": {Input: 0.13},
+	"text-embedding-ada-002": {Input: 0.1},
+
+	// Anthropic
+	"claude-opus-4":     {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+	"claude-opus-4-1":   {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+	"claude-sonnet-4":   {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-sonnet-4-5": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-haiku-4-5":  {Input: 1, Output: 5, CacheRead: 0.1, CacheWrite: 1.25},
+	"claude-3-7-sonnet": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-3-5-sonnet": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4, CacheRead: 0.08, CacheWrite: 1},
+	"claude-3-opus":     {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25, CacheRead: 0.03, CacheWrite: 0.3},
+
+	// Google
+	"gemini-2.5-pro":        {Input: 1.25, Output: 10, CacheRead: 0.31},
+	"gemini-2.5-flash":      {Input: 0.3, Output: 2.5, CacheRead: 0.075},
+	"gemini-2.5-flash-lite": {Input: 0.1, Output: 0.4, CacheRead: 0.025},
+	"gemini-2.0-flash":      {Input: 0.1, Output: 0.4, CacheRead: 0.025},
+	"gemini-2.0-flash-lite": {Input: 0.075, Output: 0.3},
+	"gemini-1.5-pro":        {Input: 1.25, Output: 5},
+	"gemini-1.5-flash":      {Input: 0.075, Output: 0.3},
+}
@@ -0,0 +1,153 @@
+package opik
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// TracedDB wraps a *sql.DB so that queries executed within a trace context
+// are recorded as general-type spans in the same trace tree.
+// Queries executed without an active trace or span run untraced.
+type TracedDB struct {
+	db *sql.DB
+}
+
+// WrapDB wraps a *sql.DB with span creation for queries.
+func WrapDB(db *sql.DB) *TracedDB {
+	return &TracedDB{db: db}
+}
+
+// DB returns the underlying *sql.DB.
+func (d *TracedDB) DB() *sql.DB {
+	return d.db
+}
+
+// QueryContext executes a query that returns rows.
+func (d *TracedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startStepSpan(ctx, "db.query", dbSpanInput(query, args))
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	endStepSpan(ctx, span, nil, err)
+	return rows, err
+}
+
+// QueryRowContext executes a query that is expected to return at most one row.
+func (d *TracedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := startStepSpan(ctx, "db.query", dbSpanInput(query, args))
+	row := d.db.QueryRowContext(ctx, query, args...)
+	endStepSpan(ctx, span, nil, row.Err())
+	return row
+}
+
+// ExecContext executes a query without returning any rows.
+func (d *TracedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startStepSpan(ctx, "db.exec", dbSpanInput(query, args))
+	result, err := d.db.ExecContext(ctx, query, args...)
+
+	var output map[string]any
+	if err == nil {
+		if n, rerr := result.RowsAffected(); rerr == nil {
+			output = map[string]any{"rows_affected": n}
+		}
+	}
+	endStepSpan(ctx, span, output, err)
+	return result, err
+}
+
+// PingContext verifies the connection to the database is still alive.
+func (d *TracedDB) PingContext(ctx context.Context) error {
+	return d.db.PingContext(ctx)
+}
+
+// Close closes the underlying database.
+func (d *TracedDB) Close() error {
+	return d.db.Close()
+}
+
+func dbSpanInput(query string, args []any) map[string]any {
+	return map[string]any{
+		"db.system":     "sql",
+		"db.statement":  query,
+		"db.operation":  sqlOperation(query),
+		"db.args_count": len(args),
+	}
+}
+
+// sqlOperation returns the leading SQL keyword of a statement (e.g. "SELECT").
+func sqlOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// RedisCommandFunc executes a single Redis command and returns its result.
+// It matches the shape of most Redis clients' generic Do call, for example
+// with go-redis:
+//
+//	do := opik.WrapRedis(func(ctx context.Context, args ...any) (any, error) {
+//	    return rdb.Do(ctx, args...).Result()
+//	})
+//	val, err := do(ctx, "GET", "session:42")
+type RedisCommandFunc func(ctx context.Context, args ...any) (any, error)
+
+// WrapRedis wraps a Redis command function so that commands executed within
+// a trace context are recorded as general-type spans.
+// Command arguments other than the command name and first key are not recorded.
+func WrapRedis(fn RedisCommandFunc) RedisCommandFunc {
+	return func(ctx context.Context, args ...any) (any, error) {
+		command := ""
+		if len(args) > 0 {
+			command = strings.ToUpper(fmt.Sprint(args[0]))
+		}
+		input := map[string]any{
+			"db.system":    "redis",
+			"db.operation": command,
+		}
+		if len(args) > 1 {
+			input["db.key"] = fmt.Sprint(args[1])
+		}
+
+		name := "redis"
+		if command != "" {
+			name += "." + strings.ToLower(command)
+		}
+		ctx, span := startStepSpan(ctx, name, input)
+		result, err := fn(ctx, args...)
+		endStepSpan(ctx, span, nil, err)
+		return result, err
+	}
+}
+
+// startStepSpan starts a general-type span for a non-LLM pipeline step if the
+// context has an active trace or span. It returns a nil span otherwise.
+func startStepSpan(ctx context.Context, name string, input map[string]any) (context.Context, *Span) {
+	if SpanFromContext(ctx) == nil && TraceFromContext(ctx) == nil {
+		return ctx, nil
+	}
+	newCtx, span, err := StartSpan(ctx, name,
+		WithSpanType(SpanTypeGeneral),
+		WithSpanInput(input),
+	)
+	if err != nil {
+		return ctx, nil
+	}
+	return newCtx, span
+}
+
+// endStepSpan ends a span started by startStepSpan, recording output and error.
+func endStepSpan(ctx context.Context, span *Span, output map[string]any, err error) {
+	if span == nil {
+		return
+	}
+	opts := []SpanOption{}
+	if output != nil {
+		opts = append(opts, WithSpanOutput(output))
+	}
+	if err != nil {
+		opts = append(opts, WithSpanError(err))
+	}
+	_ = span.End(ctx, opts...)
+}
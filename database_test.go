@@ -0,0 +1,234 @@
+package opik
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+// fakeDriver is a minimal database/sql driver for exercising TracedDB.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query: query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type fakeStmt struct{ query string }
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.Contains(s.query, "fail") {
+		return nil, errors.New("exec failed")
+	}
+	return driver.RowsAffected(3), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{ done bool }
+
+func (*fakeRows) Columns() []string { return []string{"n"} }
+func (*fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(42)
+	return nil
+}
+
+func init() {
+	sql.Register("opik-fake", fakeDriver{})
+}
+
+// newRecordingServer returns a server that accepts trace and span writes and
+// records the method and path of each request.
+func newRecordingServer() (*testutil.MockServer, func() []string) {
+	ms := testutil.NewMockServer()
+	ms.OnUnmatched().Respond(http.StatusNoContent, nil)
+	return ms, func() []string {
+		var calls []string
+		for _, r := range ms.Requests() {
+			calls = append(calls, r.Method+" "+r.Path)
+		}
+		return calls
+	}
+}
+
+func countCalls(calls []string, prefix string) int {
+	n := 0
+	for _, c := range calls {
+		if strings.HasPrefix(c, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestWrapDBWithoutTrace(t *testing.T) {
+	db, err := sql.Open("opik-fake", "")
+	if err != nil {
+		t.Fatalf("sql.Open error: %v", err)
+	}
+	tdb := WrapDB(db)
+	defer tdb.Close()
+
+	if tdb.DB() != db {
+		t.Error("DB() should return the wrapped *sql.DB")
+	}
+
+	var n int
+	if err := tdb.QueryRowContext(context.Background(), "SELECT n FROM t").Scan(&n); err != nil {
+		t.Fatalf("QueryRowContext error: %v", err)
+	}
+	if n != 42 {
+		t.Errorf("n = %d, want 42", n)
+	}
+
+	res, err := tdb.ExecContext(context.Background(), "UPDATE t SET n = 1")
+	if err != nil {
+		t.Fatalf("ExecContext error: %v", err)
+	}
+	if affected, _ := res.RowsAffected(); affected != 3 {
+		t.Errorf("RowsAffected = %d, want 3", affected)
+	}
+}
+
+func TestWrapDBWithTrace(t *testing.T) {
+	ts, calls := newRecordingServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	ctx, _, err := StartTrace(context.Background(), client, "pipeline")
+	if err != nil {
+		t.Fatalf("StartTrace error: %v", err)
+	}
+
+	db, _ := sql.Open("opik-fake", "")
+	tdb := WrapDB(db)
+	defer tdb.Close()
+
+	rows, err := tdb.QueryContext(ctx, "select n from t where id = ?", 1)
+	if err != nil {
+		t.Fatalf("QueryContext error: %v", err)
+	}
+	rows.Close()
+
+	if _, err := tdb.ExecContext(ctx, "DELETE fail"); err == nil {
+		t.Error("expected exec error to be returned")
+	}
+
+	got := calls()
+	if n := countCalls(got, "POST /v1/private/spans"); n != 2 {
+		t.Errorf("span creates = %d, want 2 (calls: %v)", n, got)
+	}
+	if n := countCalls(got, "PATCH /v1/private/spans/batch"); n != 2 {
+		t.Errorf("span updates = %d, want 2 (calls: %v)", n, got)
+	}
+}
+
+func TestSQLOperation(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM users", "SELECT"},
+		{"  insert into t values (1)", "INSERT"},
+		{"\n\tupdate t set a = 1", "UPDATE"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := sqlOperation(tt.query); got != tt.want {
+			t.Errorf("sqlOperation(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestWrapRedis(t *testing.T) {
+	var gotArgs []any
+	do := WrapRedis(func(ctx context.Context, args ...any) (any, error) {
+		gotArgs = args
+		if args[0] == "DEL" {
+			return nil, errors.New("redis down")
+		}
+		return "value", nil
+	})
+
+	t.Run("without trace", func(t *testing.T) {
+		val, err := do(context.Background(), "GET", "session:42")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if val != "value" {
+			t.Errorf("val = %v, want %q", val, "value")
+		}
+		if len(gotArgs) != 2 || gotArgs[1] != "session:42" {
+			t.Errorf("args = %v, want [GET session:42]", gotArgs)
+		}
+	})
+
+	t.Run("with trace", func(t *testing.T) {
+		ts, created := newCreateServer()
+		defer ts.Close()
+
+		client, _ := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+		ctx, _, err := StartTrace(context.Background(), client, "pipeline")
+		if err != nil {
+			t.Fatalf("StartTrace error: %v", err)
+		}
+
+		if _, err := do(ctx, "DEL", "k"); err == nil {
+			t.Error("expected command error to be returned")
+		}
+
+		if n := len(ts.RequestsFor(http.MethodPost, "/v1/private/spans/batch")); n != 1 {
+			t.Errorf("span creates = %d, want 1", n)
+		}
+		if name := created("POST /v1/private/spans/batch").Name; name != "redis.del" {
+			t.Errorf("span name = %q, want %q", name, "redis.del")
+		}
+		if info := created("PATCH /v1/private/spans/batch").ErrorInfo; info == nil || info.Message != "redis down" {
+			t.Errorf("span error info = %+v, want the command error", info)
+		}
+	})
+
+	t.Run("without arguments", func(t *testing.T) {
+		ts, created := newCreateServer()
+		defer ts.Close()
+
+		client, _ := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+		ctx, _, err := StartTrace(context.Background(), client, "pipeline")
+		if err != nil {
+			t.Fatalf("StartTrace error: %v", err)
+		}
+		noArgs := WrapRedis(func(context.Context, ...any) (any, error) { return nil, nil })
+		if _, err := noArgs(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if name := created("POST /v1/private/spans/batch").Name; name != "redis" {
+			t.Errorf("span name = %q, want %q", name, "redis")
+		}
+	})
+}
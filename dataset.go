@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/go-faster/jx"
 	"github.com/google/uuid"
@@ -27,6 +28,9 @@ type DatasetItem struct {
 	SpanID  string
 	Data    map[string]any
 	Tags    []string
+	// CreatedAt and LastUpdatedAt are set on items read from the server.
+	CreatedAt     time.Time
+	LastUpdatedAt time.Time
 }
 
 // ID returns the dataset ID.
@@ -93,6 +97,7 @@ func (c *Client) CreateDataset(ctx context.Context, name string, opts ...Dataset
 	}
 
 	resp, err := c.apiClient.CreateDataset(ctx, api.NewOptDatasetWrite(req))
+	c.audit(AuditCreate, AuditEntityDataset, datasetUUID.String(), name, err)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +227,9 @@ func (c *Client) DeleteDataset(ctx context.Context, datasetID string) error {
 		return err
 	}
 
-	return c.apiClient.DeleteDataset(ctx, api.DeleteDatasetParams{ID: datasetUUID})
+	err = c.apiClient.DeleteDataset(ctx, api.DeleteDatasetParams{ID: datasetUUID})
+	c.audit(AuditDelete, AuditEntityDataset, datasetID, "", err)
+	return err
 }
 
 // InsertItem inserts a single item into the dataset.
@@ -272,7 +279,24 @@ func jsonNodeToMap(node api.JsonNode) map[string]any {
 }
 
 // InsertItems inserts multiple items into the dataset.
+// If some items fail, the others are still inserted and a *BatchError
+// describing the failed items is returned.
 func (d *Dataset) InsertItems(ctx context.Context, items []map[string]any, opts ...DatasetItemOption) error {
+	result, err := d.InsertItemsWithResult(ctx, items, opts...)
+	if err != nil {
+		return err
+	}
+	return result.Err()
+}
+
+// InsertItemsWithResult inserts multiple items into the dataset and reports
+// the outcome of each item.
+//
+// Items are sent in batches that respect the server's request limits. A batch
+// rejected as too large or invalid is split so that only the offending items
+// fail, and transient failures are retried for the failed batch only.
+// The returned error is non-nil only if the request could not be prepared.
+func (d *Dataset) InsertItemsWithResult(ctx context.Context, items []map[string]any, opts ...DatasetItemOption) (*InsertItemsResult, error) {
 	options := &datasetItemOptions{
 		tags: []string{},
 	}
@@ -282,29 +306,57 @@ func (d *Dataset) InsertItems(ctx context.Context, items []map[string]any, opts
 
 	datasetUUID, err := uuid.Parse(d.id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	apiItems := make([]api.DatasetItemWrite, 0, len(items))
-	for _, item := range items {
+	sizes := make([]int, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	for i, item := range items {
 		itemUUID, err := uuid.NewV7()
 		if err != nil {
-			return fmt.Errorf("failed to generate dataset item UUID: %w", err)
+			return nil, fmt.Errorf("failed to generate dataset item UUID: %w", err)
 		}
+		data := mapToJsonNode(item)
 		apiItems = append(apiItems, api.DatasetItemWrite{
 			ID:     api.NewOptUUID(itemUUID),
 			Source: api.DatasetItemWriteSourceSdk,
-			Data:   mapToJsonNode(item),
+			Data:   data,
 			Tags:   options.tags,
 		})
+		sizes = append(sizes, jsonNodeSize(data))
+		indexes = append(indexes, i)
 	}
 
-	req := api.DatasetItemBatchWrite{
-		DatasetID: api.NewOptUUID(datasetUUID),
-		Items:     apiItems,
+	send := func(ctx context.Context, idx []int) error {
+		batch := make([]api.DatasetItemWrite, len(idx))
+		for i, j := range idx {
+			batch[i] = apiItems[j]
+		}
+		req := api.DatasetItemBatchWrite{
+			DatasetID: api.NewOptUUID(datasetUUID),
+			Items:     batch,
+		}
+		err := d.client.apiClient.CreateOrUpdateDatasetItems(ctx, api.NewOptDatasetItemBatchWrite(req))
+		ids := make([]uuid.UUID, len(batch))
+		for i, item := range batch {
+			ids[i] = item.ID.Value
+		}
+		d.client.auditBatch(AuditCreate, AuditEntityDatasetItem, ids, err)
+		return err
 	}
 
-	return d.client.apiClient.CreateOrUpdateDatasetItems(ctx, api.NewOptDatasetItemBatchWrite(req))
+	size := func(i int) int { return sizes[i] }
+	return sendInBatches(ctx, d.client.batchConfig, indexes, size, send), nil
+}
+
+// jsonNodeSize estimates the encoded size of a JSON node in bytes.
+func jsonNodeSize(node api.JsonNode) int {
+	n := 2
+	for k, v := range node {
+		n += len(k) + len(v) + 4
+	}
+	return n
 }
 
 // GetItems retrieves items from the dataset.
@@ -330,30 +382,37 @@ func (d *Dataset) GetItems(ctx context.Context, page, size int) ([]DatasetItem,
 	}
 
 	items := make([]DatasetItem, 0, len(resp.Content))
-	for _, item := range resp.Content {
-		var id, traceID, spanID string
-		if item.ID.Set {
-			id = item.ID.Value.String()
-		}
-		if item.TraceID.Set {
-			traceID = item.TraceID.Value.String()
-		}
-		if item.SpanID.Set {
-			spanID = item.SpanID.Value.String()
-		}
-
-		items = append(items, DatasetItem{
-			ID:      id,
-			TraceID: traceID,
-			SpanID:  spanID,
-			Data:    jsonNodeToMap(item.Data),
-			Tags:    item.Tags,
-		})
+	for i := range resp.Content {
+		items = append(items, datasetItemFromAPI(&resp.Content[i]))
 	}
 
 	return items, nil
 }
 
+// datasetItemFromAPI converts an item read from the server.
+func datasetItemFromAPI(item *api.DatasetItemPublic) DatasetItem {
+	var id, traceID, spanID string
+	if item.ID.Set {
+		id = item.ID.Value.String()
+	}
+	if item.TraceID.Set {
+		traceID = item.TraceID.Value.String()
+	}
+	if item.SpanID.Set {
+		spanID = item.SpanID.Value.String()
+	}
+
+	return DatasetItem{
+		ID:            id,
+		TraceID:       traceID,
+		SpanID:        spanID,
+		Data:          jsonNodeToMap(item.Data),
+		Tags:          item.Tags,
+		CreatedAt:     item.CreatedAt.Or(time.Time{}),
+		LastUpdatedAt: item.LastUpdatedAt.Or(time.Time{}),
+	}
+}
+
 // Delete deletes this dataset.
 func (d *Dataset) Delete(ctx context.Context) error {
 	return d.client.DeleteDataset(ctx, d.id)
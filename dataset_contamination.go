@@ -0,0 +1,223 @@
+package opik
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/plexusone/opik-go/evaluation/textnorm"
+)
+
+// defaultNGramSize is the n-gram size of contamination checks. Eight words
+// are long enough that a shared n-gram is rarely a coincidence, and short
+// enough for typical questions and answers.
+const defaultNGramSize = 8
+
+// ContaminationOption is a functional option for contamination checks.
+type ContaminationOption func(*contaminationOptions)
+
+type contaminationOptions struct {
+	fields    []string
+	ngramSize int
+	threshold float64
+}
+
+// WithContaminationFields checks only the named item data fields. By
+// default every string field is checked.
+func WithContaminationFields(fields ...string) ContaminationOption {
+	return func(o *contaminationOptions) {
+		o.fields = fields
+	}
+}
+
+// WithNGramSize sets the number of words in the n-grams compared with the
+// corpus. The default is 8.
+func WithNGramSize(n int) ContaminationOption {
+	return func(o *contaminationOptions) {
+		o.ngramSize = n
+	}
+}
+
+// WithOverlapThreshold sets the share of a field's n-grams that must
+// appear in the corpus for the field to count as contaminated, from 0 to
+// 1. The default is 0.5.
+func WithOverlapThreshold(threshold float64) ContaminationOption {
+	return func(o *contaminationOptions) {
+		o.threshold = threshold
+	}
+}
+
+// ContaminationReport lists the dataset items found in a corpus.
+type ContaminationReport struct {
+	// Items is the number of items checked.
+	Items int
+	// Contaminated are the items with at least one contaminated field, in
+	// the order they were given.
+	Contaminated []ContaminatedItem
+}
+
+// Rate returns the share of the checked items that are contaminated.
+func (r *ContaminationReport) Rate() float64 {
+	if r.Items == 0 {
+		return 0
+	}
+	return float64(len(r.Contaminated)) / float64(r.Items)
+}
+
+// ContaminatedItem is a dataset item found in the corpus.
+type ContaminatedItem struct {
+	// Index is the position of the item in the checked items.
+	Index int
+	Item  DatasetItem
+	// Fields are the contaminated fields, by name.
+	Fields []ContaminatedField
+}
+
+// ContaminatedField is an item data field found in the corpus.
+type ContaminatedField struct {
+	Field string
+	// Overlap is the share of the field's n-grams that appear in the
+	// corpus.
+	Overlap float64
+	// Verbatim is true if the whole field appears in a corpus document,
+	// ignoring case, punctuation, and whitespace.
+	Verbatim bool
+	// Document is the index of the corpus document sharing the most
+	// n-grams with the field.
+	Document int
+}
+
+// CheckContamination loads the dataset's items and checks them against
+// corpus; see CheckContamination.
+func (d *Dataset) CheckContamination(ctx context.Context, corpus []string, opts ...ContaminationOption) (*ContaminationReport, error) {
+	items, err := d.allItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return CheckContamination(items, corpus, opts...)
+}
+
+// CheckContamination reports the items whose text appears verbatim or
+// nearly verbatim in corpus, such as prompt templates or an export of
+// fine-tuning data, so scores inflated by memorization can be set aside.
+//
+// Text is compared as word n-grams, ignoring case, punctuation, and
+// whitespace: a field is contaminated when the share of its n-grams found
+// in the corpus reaches the overlap threshold. Fields with fewer words than
+// the n-gram size are too short to tell memorization from coincidence and
+// are not checked; use a smaller WithNGramSize for short items.
+func CheckContamination(items []DatasetItem, corpus []string, opts ...ContaminationOption) (*ContaminationReport, error) {
+	options := &contaminationOptions{ngramSize: defaultNGramSize, threshold: 0.5}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.ngramSize < 1 {
+		return nil, fmt.Errorf("%w: n-gram size must be positive, got %d", ErrInvalidInput, options.ngramSize)
+	}
+	if options.threshold <= 0 || options.threshold > 1 {
+		return nil, fmt.Errorf("%w: overlap threshold must be above 0 and at most 1, got %v", ErrInvalidInput, options.threshold)
+	}
+
+	n := options.ngramSize
+	docs := make([]string, len(corpus))
+	index := make(map[string][]int)
+	for i, doc := range corpus {
+		words := contaminationWords(doc)
+		docs[i] = " " + strings.Join(words, " ") + " "
+		for _, gram := range ngrams(words, n) {
+			if ids := index[gram]; len(ids) == 0 || ids[len(ids)-1] != i {
+				index[gram] = append(ids, i)
+			}
+		}
+	}
+
+	report := &ContaminationReport{Items: len(items)}
+	for i, item := range items {
+		var fields []ContaminatedField
+		for _, field := range contaminationFields(item, options.fields) {
+			text, _ := item.Data[field].(string)
+			words := contaminationWords(text)
+			grams := ngrams(words, n)
+			if len(grams) == 0 {
+				continue
+			}
+
+			shared := make(map[int]int)
+			found := 0
+			for _, gram := range grams {
+				ids, ok := index[gram]
+				if !ok {
+					continue
+				}
+				found++
+				for _, id := range ids {
+					shared[id]++
+				}
+			}
+			overlap := float64(found) / float64(len(grams))
+			if overlap < options.threshold {
+				continue
+			}
+
+			cf := ContaminatedField{Field: field, Overlap: overlap, Document: -1}
+			for id, count := range shared {
+				if cf.Document < 0 || count > shared[cf.Document] || count == shared[cf.Document] && id < cf.Document {
+					cf.Document = id
+				}
+			}
+			if overlap == 1 {
+				phrase := " " + strings.Join(words, " ") + " "
+				cf.Verbatim = slices.ContainsFunc(docs, func(doc string) bool { return strings.Contains(doc, phrase) })
+			}
+			fields = append(fields, cf)
+		}
+		if len(fields) > 0 {
+			report.Contaminated = append(report.Contaminated, ContaminatedItem{Index: i, Item: item, Fields: fields})
+		}
+	}
+	return report, nil
+}
+
+// contaminationFields returns the names of the item's fields to check: the
+// given fields, or every string field, sorted.
+func contaminationFields(item DatasetItem, fields []string) []string {
+	if len(fields) > 0 {
+		return fields
+	}
+	var names []string
+	for name, v := range item.Data {
+		if _, ok := v.(string); ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// contaminationWords returns the case-folded words of text, without the
+// punctuation around them.
+func contaminationWords(text string) []string {
+	words := textnorm.Words(textnorm.FoldCase(textnorm.NormalizeQuotes(text)))
+	out := words[:0]
+	for _, w := range words {
+		w = strings.TrimFunc(w, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if w != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// ngrams returns the n-grams of words, joined by spaces.
+func ngrams(words []string, n int) []string {
+	if len(words) < n {
+		return nil
+	}
+	grams := make([]string, 0, len(words)-n+1)
+	for i := 0; i+n <= len(words); i++ {
+		grams = append(grams, strings.Join(words[i:i+n], " "))
+	}
+	return grams
+}
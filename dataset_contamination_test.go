@@ -0,0 +1,85 @@
+package opik
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckContamination(t *testing.T) {
+	corpus := []string{
+		"Unrelated fine-tuning example about the weather in Lisbon.",
+		`Q: What is the capital of France, and why did it become the seat of government?
+A: Paris became the capital because of its central position on the Seine.`,
+	}
+	items := []DatasetItem{
+		{ID: "verbatim", Data: map[string]any{
+			"input":  "WHAT is the capital of France — and why did it become the seat of government",
+			"answer": "Paris",
+		}},
+		{ID: "clean", Data: map[string]any{
+			"input": "Which river runs through the middle of Budapest and divides Buda from Pest?",
+		}},
+		{ID: "near", Data: map[string]any{
+			"input": "Paris became the capital because of its central position on the Seine and its royal history",
+		}},
+		{ID: "numbers", Data: map[string]any{"input": 42}},
+	}
+
+	report, err := CheckContamination(items, corpus)
+	if err != nil {
+		t.Fatalf("CheckContamination error: %v", err)
+	}
+	if report.Items != 4 || len(report.Contaminated) != 2 || report.Rate() != 0.5 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	verbatim := report.Contaminated[0]
+	if verbatim.Index != 0 || verbatim.Item.ID != "verbatim" || len(verbatim.Fields) != 1 {
+		t.Fatalf("verbatim item = %+v", verbatim)
+	}
+	if f := verbatim.Fields[0]; f.Field != "input" || f.Overlap != 1 || !f.Verbatim || f.Document != 1 {
+		t.Errorf("verbatim field = %+v", f)
+	}
+
+	near := report.Contaminated[1]
+	if f := near.Fields[0]; near.Item.ID != "near" || f.Verbatim || f.Overlap <= 0.5 || f.Overlap >= 1 {
+		t.Errorf("near item = %+v", near)
+	}
+}
+
+func TestCheckContaminationOptions(t *testing.T) {
+	corpus := []string{"the quick brown fox jumps over the lazy dog"}
+	items := []DatasetItem{{Data: map[string]any{
+		"input":  "a quick brown fox",
+		"output": "quick brown fox jumps",
+	}}}
+
+	report, err := CheckContamination(items, corpus)
+	if err != nil || len(report.Contaminated) != 0 {
+		t.Fatalf("short fields were checked: %+v, %v", report, err)
+	}
+
+	report, err = CheckContamination(items, corpus, WithNGramSize(3), WithContaminationFields("input"))
+	if err != nil {
+		t.Fatalf("CheckContamination error: %v", err)
+	}
+	if len(report.Contaminated) != 1 || len(report.Contaminated[0].Fields) != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	if f := report.Contaminated[0].Fields[0]; f.Field != "input" || f.Overlap != 0.5 || f.Verbatim {
+		t.Errorf("field = %+v", f)
+	}
+
+	report, _ = CheckContamination(items, corpus, WithNGramSize(3), WithOverlapThreshold(0.6))
+	if len(report.Contaminated) != 1 || report.Contaminated[0].Fields[0].Field != "output" {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestCheckContaminationInvalid(t *testing.T) {
+	for _, opt := range []ContaminationOption{WithNGramSize(0), WithOverlapThreshold(0), WithOverlapThreshold(1.5)} {
+		if _, err := CheckContamination(nil, nil, opt); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("error = %v, want ErrInvalidInput", err)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package opik
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// DatasetFileOption configures the import and export of dataset files.
+type DatasetFileOption func(*datasetFileOptions)
+
+type datasetFileOptions struct {
+	fields      map[string]string // column -> item data field
+	columns     []string
+	jsonColumns []string
+	itemOpts    []DatasetItemOption
+	itemsOpts   []DatasetItemsOption
+	problems    evaluation.Problems
+}
+
+// WithFileColumn maps a file column to an item data field. On import the
+// column's values are stored under field, and on export field is written
+// to the column. Columns that are not mapped keep their name.
+func WithFileColumn(column, field string) DatasetFileOption {
+	return func(o *datasetFileOptions) {
+		if column == "" || field == "" {
+			o.problems.Addf("file column mapping needs a column and a field: %q -> %q", column, field)
+			return
+		}
+		o.fields[column] = field
+	}
+}
+
+// WithInputColumn maps a file column to the item's "input" field, which
+// evaluation reads by default.
+func WithInputColumn(column string) DatasetFileOption {
+	return WithFileColumn(column, "input")
+}
+
+// WithExpectedColumn maps a file column to the item's "expected" field,
+// which evaluation reads by default.
+func WithExpectedColumn(column string) DatasetFileOption {
+	return WithFileColumn(column, "expected")
+}
+
+// WithFileColumns limits the file to the given columns. On import, other
+// columns are ignored and every listed column must be present. On export,
+// the columns are written in this order, and a CSV file has them as its
+// header even if no item has a value for them.
+func WithFileColumns(columns ...string) DatasetFileOption {
+	return func(o *datasetFileOptions) {
+		o.columns = append(o.columns, columns...)
+	}
+}
+
+// WithJSONColumns decodes the cells of the given CSV columns as JSON on
+// import, so nested values written by ExportCSV are read back as they were.
+// It has no effect on JSON Lines files.
+func WithJSONColumns(columns ...string) DatasetFileOption {
+	return func(o *datasetFileOptions) {
+		o.jsonColumns = append(o.jsonColumns, columns...)
+	}
+}
+
+// WithFileItemOptions configures the items an import inserts, for example
+// their tags.
+func WithFileItemOptions(opts ...DatasetItemOption) DatasetFileOption {
+	return func(o *datasetFileOptions) {
+		o.itemOpts = append(o.itemOpts, opts...)
+	}
+}
+
+// WithFileItems selects the items an export writes, for example a version
+// or a filter; see Dataset.Items.
+func WithFileItems(opts ...DatasetItemsOption) DatasetFileOption {
+	return func(o *datasetFileOptions) {
+		o.itemsOpts = append(o.itemsOpts, opts...)
+	}
+}
+
+func newDatasetFileOptions(opts []DatasetFileOption) (*datasetFileOptions, error) {
+	options := &datasetFileOptions{fields: make(map[string]string)}
+	for _, opt := range opts {
+		opt(options)
+	}
+	columns := make(map[string]string, len(options.fields))
+	for _, column := range slices.Sorted(maps.Keys(options.fields)) {
+		field := options.fields[column]
+		if other, ok := columns[field]; ok {
+			options.problems.Addf("columns %q and %q are both mapped to field %q", other, column, field)
+			continue
+		}
+		columns[field] = column
+	}
+	return options, invalidOptions(options.problems)
+}
+
+// field returns the item data field of a file column.
+func (o *datasetFileOptions) field(column string) string {
+	if field, ok := o.fields[column]; ok {
+		return field
+	}
+	return column
+}
+
+// column returns the file column of an item data field.
+func (o *datasetFileOptions) column(field string) string {
+	for column, f := range o.fields {
+		if f == field {
+			return column
+		}
+	}
+	if _, ok := o.fields[field]; ok {
+		// The field's own name is mapped to another field, so it has no
+		// column.
+		return ""
+	}
+	return field
+}
+
+// ImportCSV inserts a dataset item for each row of a CSV file with a header
+// row. Each cell is stored as a string under its column's field; empty
+// cells are left out, and WithJSONColumns decodes cells holding JSON.
+//
+//	result, err := dataset.ImportCSV(ctx, file,
+//		opik.WithInputColumn("question"),
+//		opik.WithExpectedColumn("answer"),
+//	)
+//
+// Rows that cannot be inserted are reported in the result's Failed list by
+// their 0-based position in the file, excluding the header. An error is
+// returned if the file cannot be parsed or an option is invalid.
+func (d *Dataset) ImportCSV(ctx context.Context, r io.Reader, opts ...DatasetFileOption) (*InsertItemsResult, error) {
+	return d.importFile(ctx, r, ImportFormatCSV, opts)
+}
+
+// ImportJSONL inserts a dataset item for each object of a JSON Lines file.
+// Each key is stored under its column's field with its JSON value. Blank
+// lines are skipped.
+//
+// Rows that cannot be inserted are reported in the result's Failed list by
+// their 0-based position among the file's objects. An error is returned if
+// the file cannot be parsed or an option is invalid.
+func (d *Dataset) ImportJSONL(ctx context.Context, r io.Reader, opts ...DatasetFileOption) (*InsertItemsResult, error) {
+	return d.importFile(ctx, r, ImportFormatJSONL, opts)
+}
+
+func (d *Dataset) importFile(ctx context.Context, r io.Reader, format ImportFormat, opts []DatasetFileOption) (*InsertItemsResult, error) {
+	options, err := newDatasetFileOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	rows, columns, err := readImportRows(r, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(options.columns) > 0 {
+		for _, col := range options.columns {
+			if !slices.Contains(columns, col) {
+				return nil, fmt.Errorf("%w: column %q not found in the dataset file", ErrInvalidInput, col)
+			}
+		}
+		columns = options.columns
+	}
+
+	items := make([]map[string]any, 0, len(rows))
+	for i, row := range rows {
+		item := make(map[string]any, len(columns))
+		for _, col := range columns {
+			v, ok := row[col]
+			if !ok {
+				continue
+			}
+			if format == ImportFormatCSV {
+				if v == "" {
+					continue
+				}
+				if slices.Contains(options.jsonColumns, col) {
+					var decoded any
+					if err := json.Unmarshal([]byte(v.(string)), &decoded); err != nil {
+						return nil, fmt.Errorf("%w: row %d, column %q: %w", ErrInvalidInput, i, col, err)
+					}
+					v = decoded
+				}
+			}
+			item[options.field(col)] = v
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return &InsertItemsResult{}, nil
+	}
+	return d.InsertItemsWithResult(ctx, items, options.itemOpts...)
+}
+
+// ExportCSV writes the dataset's items to w as a CSV file with a header
+// row, and returns the number of items written. Columns are the item data
+// fields, sorted, unless set with WithFileColumns. Strings and numbers are
+// written as they are, missing values as empty cells, and other values as
+// JSON; see WithJSONColumns to read them back.
+//
+// The items are read before the file is written, to find the columns.
+func (d *Dataset) ExportCSV(ctx context.Context, w io.Writer, opts ...DatasetFileOption) (int, error) {
+	options, err := newDatasetFileOptions(opts)
+	if err != nil {
+		return 0, err
+	}
+	var rows []map[string]any
+	for item, err := range d.Items(ctx, options.itemsOpts...) {
+		if err != nil {
+			return 0, err
+		}
+		rows = append(rows, options.exportRow(item))
+	}
+
+	columns := options.columns
+	if len(columns) == 0 {
+		set := make(map[string]bool)
+		for _, row := range rows {
+			for col := range row {
+				set[col] = true
+			}
+		}
+		columns = slices.Sorted(maps.Keys(set))
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return 0, err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, col := range columns {
+			record[i] = importString(row[col])
+		}
+		if err := cw.Write(record); err != nil {
+			return 0, err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// ExportJSONL writes the dataset's items to w as a JSON Lines file, one
+// object per item, and returns the number of items written. Items are
+// written as they are read, so a large dataset is never held in memory.
+func (d *Dataset) ExportJSONL(ctx context.Context, w io.Writer, opts ...DatasetFileOption) (int, error) {
+	options, err := newDatasetFileOptions(opts)
+	if err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(w)
+	n := 0
+	for item, err := range d.Items(ctx, options.itemsOpts...) {
+		if err != nil {
+			return n, err
+		}
+		if err := enc.Encode(options.exportRow(item)); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// exportRow returns the file row of an item, keyed by column.
+func (o *datasetFileOptions) exportRow(item DatasetItem) map[string]any {
+	row := make(map[string]any, len(item.Data))
+	for field, v := range item.Data {
+		col := o.column(field)
+		if col == "" || (len(o.columns) > 0 && !slices.Contains(o.columns, col)) {
+			continue
+		}
+		row[col] = v
+	}
+	return row
+}
@@ -0,0 +1,211 @@
+package opik
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+// fileServer stores the items inserted into one dataset and serves them
+// back in insertion order.
+type fileServer struct {
+	*testutil.MockServer
+	mu    sync.Mutex
+	items []map[string]any
+	tags  [][]any
+}
+
+func newFileServer(t *testing.T) (*fileServer, *Dataset) {
+	t.Helper()
+	datasetID := uuid.Must(uuid.NewV7()).String()
+	s := &fileServer{MockServer: testutil.NewMockServer()}
+	s.OnPut("/v1/private/datasets/items").WithHandler(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Items []struct {
+				Data map[string]any `json:"data"`
+				Tags []any          `json:"tags"`
+			} `json:"items"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, item := range body.Items {
+			s.items = append(s.items, item.Data)
+			s.tags = append(s.tags, item.Tags)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.OnGet("/v1/private/datasets/" + datasetID + "/items").WithHandler(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		content := make([]map[string]any, len(s.items))
+		for i, data := range s.items {
+			content[i] = map[string]any{"id": uuid.NewString(), "source": "sdk", "data": data}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"page": 1, "size": len(content), "total": len(content), "content": content})
+	})
+	t.Cleanup(s.Close)
+
+	client, err := NewClient(WithURL(s.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	return s, &Dataset{client: client, id: datasetID, name: "qa"}
+}
+
+func TestDatasetImportCSV(t *testing.T) {
+	s, dataset := newFileServer(t)
+	csv := "question,answer,category,meta\n" +
+		"capital of France,Paris,geography,\"{\"\"level\"\":1}\"\n" +
+		"2+2,4,,\n"
+	result, err := dataset.ImportCSV(context.Background(), strings.NewReader(csv),
+		WithInputColumn("question"),
+		WithExpectedColumn("answer"),
+		WithJSONColumns("meta"),
+		WithFileItemOptions(WithDatasetItemTags("imported")),
+	)
+	if err != nil {
+		t.Fatalf("ImportCSV error: %v", err)
+	}
+	if result.Succeeded != 2 || len(result.Failed) != 0 {
+		t.Errorf("result = %+v, want 2 items inserted", result)
+	}
+
+	want := []map[string]any{
+		{"input": "capital of France", "expected": "Paris", "category": "geography", "meta": map[string]any{"level": 1.0}},
+		{"input": "2+2", "expected": "4"},
+	}
+	if !reflect.DeepEqual(s.items, want) {
+		t.Errorf("items = %v, want %v", s.items, want)
+	}
+	if !reflect.DeepEqual(s.tags[0], []any{"imported"}) {
+		t.Errorf("tags = %v, want [imported]", s.tags[0])
+	}
+}
+
+func TestDatasetImportJSONL(t *testing.T) {
+	s, dataset := newFileServer(t)
+	jsonl := `{"q": "2+2", "a": 4, "notes": "easy"}` + "\n\n" +
+		`{"q": "largest ocean", "a": "Pacific", "tags": ["geo"]}` + "\n"
+	result, err := dataset.ImportJSONL(context.Background(), strings.NewReader(jsonl),
+		WithInputColumn("q"),
+		WithExpectedColumn("a"),
+		WithFileColumns("q", "a"),
+	)
+	if err != nil {
+		t.Fatalf("ImportJSONL error: %v", err)
+	}
+	if result.Succeeded != 2 {
+		t.Errorf("inserted %d items, want 2", result.Succeeded)
+	}
+	want := []map[string]any{
+		{"input": "2+2", "expected": 4.0},
+		{"input": "largest ocean", "expected": "Pacific"},
+	}
+	if !reflect.DeepEqual(s.items, want) {
+		t.Errorf("items = %v, want %v", s.items, want)
+	}
+}
+
+func TestDatasetImportInvalid(t *testing.T) {
+	s, dataset := newFileServer(t)
+	tests := []struct {
+		name string
+		file string
+		opts []DatasetFileOption
+	}{
+		{"empty mapping", "a\n1\n", []DatasetFileOption{WithFileColumn("", "input")}},
+		{"duplicate field", "a,b\n1,2\n", []DatasetFileOption{WithInputColumn("a"), WithInputColumn("b")}},
+		{"missing column", "a\n1\n", []DatasetFileOption{WithFileColumns("a", "b")}},
+		{"bad JSON cell", "a\n{x\n", []DatasetFileOption{WithJSONColumns("a")}},
+		{"no header", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := dataset.ImportCSV(context.Background(), strings.NewReader(tt.file), tt.opts...); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+	if len(s.items) != 0 {
+		t.Errorf("server got %d items, want none", len(s.items))
+	}
+}
+
+func TestDatasetExportCSV(t *testing.T) {
+	s, dataset := newFileServer(t)
+	s.items = []map[string]any{
+		{"input": "capital of France", "expected": "Paris", "meta": map[string]any{"level": 1.0}},
+		{"input": "2+2", "expected": 4.0, "category": "math"},
+	}
+
+	var buf bytes.Buffer
+	n, err := dataset.ExportCSV(context.Background(), &buf, WithInputColumn("question"))
+	if err != nil {
+		t.Fatalf("ExportCSV error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("exported %d items, want 2", n)
+	}
+	want := "category,expected,meta,question\n" +
+		",Paris,\"{\"\"level\"\":1}\",capital of France\n" +
+		"math,4,,2+2\n"
+	if buf.String() != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	// The exported file imports back to the same items.
+	s2, other := newFileServer(t)
+	if _, err := other.ImportCSV(context.Background(), &buf, WithInputColumn("question"), WithJSONColumns("meta")); err != nil {
+		t.Fatalf("ImportCSV error: %v", err)
+	}
+	want2 := []map[string]any{s.items[0], {"input": "2+2", "expected": "4", "category": "math"}}
+	if !reflect.DeepEqual(s2.items, want2) {
+		t.Errorf("reimported items = %v, want %v", s2.items, want2)
+	}
+}
+
+func TestDatasetExportCSVColumns(t *testing.T) {
+	s, dataset := newFileServer(t)
+	s.items = []map[string]any{{"input": "2+2", "expected": "4", "category": "math"}}
+
+	var buf bytes.Buffer
+	if _, err := dataset.ExportCSV(context.Background(), &buf, WithFileColumns("expected", "input", "notes")); err != nil {
+		t.Fatalf("ExportCSV error: %v", err)
+	}
+	if want := "expected,input,notes\n4,2+2,\n"; buf.String() != want {
+		t.Errorf("CSV = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestDatasetExportJSONL(t *testing.T) {
+	s, dataset := newFileServer(t)
+	s.items = []map[string]any{
+		{"input": "2+2", "expected": 4.0, "question": "ignored"},
+		{"input": "largest ocean", "tags": []any{"geo"}},
+	}
+
+	var buf bytes.Buffer
+	n, err := dataset.ExportJSONL(context.Background(), &buf, WithInputColumn("question"))
+	if err != nil {
+		t.Fatalf("ExportJSONL error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("exported %d items, want 2", n)
+	}
+	want := `{"expected":4,"question":"2+2"}` + "\n" + `{"question":"largest ocean","tags":["geo"]}` + "\n"
+	if buf.String() != want {
+		t.Errorf("JSONL =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,166 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// itemsPageSize is the default page size used to iterate dataset items.
+const itemsPageSize = 100
+
+// DatasetItemsOption configures Dataset.Items.
+type DatasetItemsOption func(*datasetItemsOptions)
+
+type datasetItemsOptions struct {
+	pageSize     int
+	version      string
+	updatedAfter time.Time
+	filters      []itemFilter
+	problems     evaluation.Problems
+}
+
+// itemFilter is a dataset item filter in the server's format.
+type itemFilter struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Key      string `json:"key,omitempty"`
+	Value    string `json:"value"`
+}
+
+// WithItemsPageSize sets how many items are fetched per request. The
+// default is 100.
+func WithItemsPageSize(size int) DatasetItemsOption {
+	return func(o *datasetItemsOptions) {
+		if size > 0 {
+			o.pageSize = size
+		}
+	}
+}
+
+// WithItemsVersion reads the items of a dataset version instead of the
+// latest items.
+func WithItemsVersion(version string) DatasetItemsOption {
+	return func(o *datasetItemsOptions) {
+		o.version = version
+	}
+}
+
+// WithItemsUpdatedAfter limits the items to those created or changed after
+// t, so a job can pick up only what changed since its last run.
+func WithItemsUpdatedAfter(t time.Time) DatasetItemsOption {
+	return func(o *datasetItemsOptions) {
+		o.updatedAfter = t
+	}
+}
+
+// WithItemsFilter limits the items to those whose field compares to value
+// with operator, which is one of the operators RuleFilter accepts. Fields of
+// an item's data are named "data.<key>", for example
+//
+//	opik.WithItemsFilter("data.category", "=", "math")
+//
+// Other fields are "id", "tags", "trace_id", "span_id", "created_at", and
+// "last_updated_at". Filters are applied by the server, and an item must
+// match every filter.
+func WithItemsFilter(field, operator, value string) DatasetItemsOption {
+	return func(o *datasetItemsOptions) {
+		if field == "" {
+			o.problems.Addf("items filter has no field")
+		}
+		if !slices.Contains(ruleFilterOperators, operator) {
+			o.problems.Addf("items filter on %q has unknown operator %q", field, operator)
+		}
+		f := itemFilter{Field: field, Operator: operator, Value: value}
+		if key, ok := strings.CutPrefix(field, "data."); ok {
+			f.Field, f.Key = "data", key
+		}
+		o.filters = append(o.filters, f)
+	}
+}
+
+// Items returns an iterator over the dataset's items. Pages of items are
+// fetched as the iterator advances, so a large dataset is never held in
+// memory at once, and filters are applied by the server, so items that do
+// not match are not transferred. If an option is invalid or a request
+// fails, the iterator yields the error and stops.
+//
+//	for item, err := range dataset.Items(ctx, opik.WithItemsUpdatedAfter(lastRun)) {
+//		if err != nil {
+//			return err
+//		}
+//		// use item.Data
+//	}
+func (d *Dataset) Items(ctx context.Context, opts ...DatasetItemsOption) iter.Seq2[DatasetItem, error] {
+	options := &datasetItemsOptions{pageSize: itemsPageSize}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return func(yield func(DatasetItem, error) bool) {
+		if err := invalidOptions(options.problems); err != nil {
+			yield(DatasetItem{}, err)
+			return
+		}
+		datasetUUID, err := uuid.Parse(d.id)
+		if err != nil {
+			yield(DatasetItem{}, fmt.Errorf("%w: dataset ID %q: %v", ErrInvalidInput, d.id, err))
+			return
+		}
+
+		params := api.GetDatasetItemsParams{
+			ID:   datasetUUID,
+			Size: api.NewOptInt32(int32(options.pageSize)), //nolint:gosec // G115: size values are bounded by API limits
+		}
+		if options.version != "" {
+			params.Version = api.NewOptString(options.version)
+		}
+		filters := options.filters
+		if !options.updatedAfter.IsZero() {
+			filters = append(slices.Clip(filters), itemFilter{
+				Field:    "last_updated_at",
+				Operator: ">",
+				Value:    options.updatedAfter.UTC().Format(time.RFC3339Nano),
+			})
+		}
+		if len(filters) > 0 {
+			data, err := json.Marshal(filters)
+			if err != nil {
+				yield(DatasetItem{}, err)
+				return
+			}
+			params.Filters = api.NewOptString(string(data))
+		}
+
+		for page := int32(1); ; page++ {
+			params.Page = api.NewOptInt32(page)
+			resp, err := d.client.apiClient.GetDatasetItems(ctx, params)
+			if err != nil {
+				yield(DatasetItem{}, err)
+				return
+			}
+			for i := range resp.Content {
+				item := datasetItemFromAPI(&resp.Content[i])
+				// Servers that predate item filters return every item.
+				if !options.updatedAfter.IsZero() && !item.LastUpdatedAt.IsZero() && !item.LastUpdatedAt.After(options.updatedAfter) {
+					continue
+				}
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(resp.Content) < options.pageSize {
+				return
+			}
+		}
+	}
+}
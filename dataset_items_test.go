@@ -0,0 +1,179 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+// itemsServer serves count dataset items, item i last updated i minutes
+// after base, and records the query of each request.
+type itemsServer struct {
+	*testutil.MockServer
+}
+
+func newItemsServer(t *testing.T, count int, base time.Time) *itemsServer {
+	t.Helper()
+	s := &itemsServer{testutil.NewMockServer()}
+	s.OnUnmatched().WithHandler(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		page, _ := strconv.Atoi(q.Get("page"))
+		size, _ := strconv.Atoi(q.Get("size"))
+		var content []map[string]any
+		for i := (page - 1) * size; i < page*size && i < count; i++ {
+			content = append(content, map[string]any{
+				"id":              uuid.NewString(),
+				"source":          "sdk",
+				"data":            map[string]any{"index": i},
+				"last_updated_at": base.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"content": content, "page": page, "size": size, "total": count})
+	})
+	t.Cleanup(s.Close)
+	return s
+}
+
+// requests returns the query of each request.
+func (s *itemsServer) requests() []url.Values {
+	var queries []url.Values
+	for _, r := range s.Requests() {
+		queries = append(queries, r.Query)
+	}
+	return queries
+}
+
+func (s *itemsServer) dataset(t *testing.T) *Dataset {
+	t.Helper()
+	client, err := NewClient(WithURL(s.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	return &Dataset{client: client, id: uuid.NewString(), name: "test-dataset"}
+}
+
+func TestDatasetItems(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newItemsServer(t, 25, base)
+	d := s.dataset(t)
+
+	var got []int
+	for item, err := range d.Items(context.Background(), WithItemsPageSize(10)) {
+		if err != nil {
+			t.Fatalf("Items error: %v", err)
+		}
+		got = append(got, int(item.Data["index"].(float64)))
+		if want := base.Add(time.Duration(len(got)-1) * time.Minute); !item.LastUpdatedAt.Equal(want) {
+			t.Errorf("item %d LastUpdatedAt = %v, want %v", len(got)-1, item.LastUpdatedAt, want)
+		}
+	}
+	if len(got) != 25 || got[0] != 0 || got[24] != 24 {
+		t.Errorf("items = %v, want 0..24", got)
+	}
+	if n := len(s.requests()); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+	if q := s.requests()[0]; q.Get("size") != "10" || q.Get("filters") != "" {
+		t.Errorf("first query = %v, want size 10 and no filters", q)
+	}
+}
+
+func TestDatasetItemsLazy(t *testing.T) {
+	s := newItemsServer(t, 500, time.Now())
+	d := s.dataset(t)
+
+	n := 0
+	for _, err := range d.Items(context.Background(), WithItemsPageSize(50)) {
+		if err != nil {
+			t.Fatalf("Items error: %v", err)
+		}
+		if n++; n == 60 {
+			break
+		}
+	}
+	if got := len(s.requests()); got != 2 {
+		t.Errorf("requests after reading 60 items = %d, want 2", got)
+	}
+}
+
+func TestDatasetItemsFilters(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newItemsServer(t, 10, base)
+	d := s.dataset(t)
+
+	after := base.Add(6*time.Minute + 30*time.Second)
+	var got []int
+	for item, err := range d.Items(context.Background(),
+		WithItemsFilter("data.category", "=", "math"),
+		WithItemsFilter("tags", "contains", "reviewed"),
+		WithItemsUpdatedAfter(after),
+		WithItemsVersion("v3"),
+	) {
+		if err != nil {
+			t.Fatalf("Items error: %v", err)
+		}
+		got = append(got, int(item.Data["index"].(float64)))
+	}
+
+	q := s.requests()[0]
+	var filters []itemFilter
+	if err := json.Unmarshal([]byte(q.Get("filters")), &filters); err != nil {
+		t.Fatalf("filters %q: %v", q.Get("filters"), err)
+	}
+	want := []itemFilter{
+		{Field: "data", Operator: "=", Key: "category", Value: "math"},
+		{Field: "tags", Operator: "contains", Value: "reviewed"},
+		{Field: "last_updated_at", Operator: ">", Value: "2026-01-01T00:06:30Z"},
+	}
+	if !reflect.DeepEqual(filters, want) {
+		t.Errorf("filters = %+v\nwant %+v", filters, want)
+	}
+	if q.Get("version") != "v3" {
+		t.Errorf("version = %q, want v3", q.Get("version"))
+	}
+	// The test server ignores filters, so the update time is checked by the
+	// client too.
+	if fmt.Sprint(got) != "[7 8 9]" {
+		t.Errorf("items updated after %v = %v, want [7 8 9]", after, got)
+	}
+}
+
+func TestDatasetItemsInvalid(t *testing.T) {
+	s := newItemsServer(t, 10, time.Now())
+
+	tests := []struct {
+		name string
+		d    *Dataset
+		opts []DatasetItemsOption
+	}{
+		{"unknown operator", s.dataset(t), []DatasetItemsOption{WithItemsFilter("data.category", "like", "math")}},
+		{"no field", s.dataset(t), []DatasetItemsOption{WithItemsFilter("", "=", "math")}},
+		{"bad dataset ID", &Dataset{client: s.dataset(t).client, id: "not-a-uuid"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []error
+			for _, err := range tt.d.Items(context.Background(), tt.opts...) {
+				errs = append(errs, err)
+			}
+			if len(errs) != 1 || !errors.Is(errs[0], ErrInvalidInput) {
+				t.Errorf("Items yielded %v, want one ErrInvalidInput", errs)
+			}
+		})
+	}
+	if n := len(s.requests()); n != 0 {
+		t.Errorf("requests = %d, want 0", n)
+	}
+}
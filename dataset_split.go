@@ -0,0 +1,223 @@
+package opik
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// DatasetSplit is one part of a split dataset.
+type DatasetSplit struct {
+	// Name is the split name, e.g. "train".
+	Name string
+	// Items are the dataset items assigned to this split.
+	Items []DatasetItem
+	// Dataset is the derived dataset holding the items. It is set only when
+	// the split was made with WithDerivedDatasets.
+	Dataset *Dataset
+}
+
+// SplitOption is a functional option for splitting a dataset.
+type SplitOption func(*splitOptions)
+
+type splitOptions struct {
+	stratifyBy string
+	seed       uint64
+	names      []string
+	derived    bool
+}
+
+// WithStratifyBy splits each value of the item data field separately, so
+// every split has the same proportion of each value. Items without the
+// field form their own group.
+func WithStratifyBy(field string) SplitOption {
+	return func(o *splitOptions) {
+		o.stratifyBy = field
+	}
+}
+
+// WithSeed sets the seed used to shuffle items. The same seed, items and
+// ratios always produce the same split.
+func WithSeed(seed uint64) SplitOption {
+	return func(o *splitOptions) {
+		o.seed = seed
+	}
+}
+
+// WithSplitNames names the splits. By default two splits are named "train"
+// and "test", three are named "train", "dev" and "test", and others are
+// named "split-1", "split-2" and so on.
+func WithSplitNames(names ...string) SplitOption {
+	return func(o *splitOptions) {
+		o.names = names
+	}
+}
+
+// WithDerivedDatasets creates a dataset named "<dataset>-<split>" for each
+// split and inserts the split's items into it.
+func WithDerivedDatasets() SplitOption {
+	return func(o *splitOptions) {
+		o.derived = true
+	}
+}
+
+// Split divides the dataset's items into splits with the given ratios, for
+// example []float64{0.8, 0.1, 0.1}. Ratios are relative and need not sum to 1.
+// The split is deterministic for a given seed; see SplitItems.
+func (d *Dataset) Split(ctx context.Context, ratios []float64, opts ...SplitOption) ([]DatasetSplit, error) {
+	options := &splitOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	items, err := d.allItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	splits, err := SplitItems(items, ratios, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !options.derived {
+		return splits, nil
+	}
+
+	for i := range splits {
+		split := &splits[i]
+		derived, err := d.client.CreateDataset(ctx, d.name+"-"+split.Name,
+			WithDatasetDescription(fmt.Sprintf("%s split of dataset %s", split.Name, d.name)),
+			WithDatasetTags(d.tags...),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("create %s dataset: %w", split.Name, err)
+		}
+		data := make([]map[string]any, len(split.Items))
+		for j, item := range split.Items {
+			data[j] = item.Data
+		}
+		if err := derived.InsertItems(ctx, data); err != nil {
+			return nil, fmt.Errorf("insert %s items: %w", split.Name, err)
+		}
+		split.Dataset = derived
+	}
+	return splits, nil
+}
+
+// SplitItems divides items into splits with the given ratios without
+// contacting the server. WithDerivedDatasets is ignored.
+//
+// Items are ordered by ID before being shuffled with the seed, so the result
+// does not depend on the order the server returned them in. Split sizes are
+// rounded so that they sum to the number of items.
+func SplitItems(items []DatasetItem, ratios []float64, opts ...SplitOption) ([]DatasetSplit, error) {
+	options := &splitOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if len(ratios) < 2 {
+		return nil, fmt.Errorf("%w: need at least two split ratios", ErrInvalidInput)
+	}
+	var total float64
+	for _, r := range ratios {
+		if r <= 0 {
+			return nil, fmt.Errorf("%w: split ratios must be positive, got %v", ErrInvalidInput, r)
+		}
+		total += r
+	}
+	names := options.names
+	if names == nil {
+		names = defaultSplitNames(len(ratios))
+	}
+	if len(names) != len(ratios) {
+		return nil, fmt.Errorf("%w: %d split names for %d ratios", ErrInvalidInput, len(names), len(ratios))
+	}
+
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b DatasetItem) int { return cmp.Compare(a.ID, b.ID) })
+
+	groups := map[string][]DatasetItem{"": sorted}
+	if options.stratifyBy != "" {
+		groups = make(map[string][]DatasetItem)
+		for _, item := range sorted {
+			key := ""
+			if v, ok := item.Data[options.stratifyBy]; ok {
+				key = fmt.Sprint(v)
+			}
+			groups[key] = append(groups[key], item)
+		}
+	}
+
+	splits := make([]DatasetSplit, len(ratios))
+	for i, name := range names {
+		splits[i].Name = name
+	}
+
+	rng := rand.New(rand.NewPCG(options.seed, 0)) //nolint:gosec // G404: splits need reproducibility, not security
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		group := groups[key]
+		rng.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+		start := 0
+		for i, n := range splitSizes(len(group), ratios, total) {
+			splits[i].Items = append(splits[i].Items, group[start:start+n]...)
+			start += n
+		}
+	}
+	return splits, nil
+}
+
+// splitSizes distributes n items by ratio using the largest remainder method.
+func splitSizes(n int, ratios []float64, total float64) []int {
+	sizes := make([]int, len(ratios))
+	remainders := make([]float64, len(ratios))
+	assigned := 0
+	for i, r := range ratios {
+		exact := float64(n) * r / total
+		sizes[i] = int(exact)
+		remainders[i] = exact - float64(sizes[i])
+		assigned += sizes[i]
+	}
+
+	order := make([]int, len(ratios))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(remainders[b], remainders[a]) })
+	for _, i := range order[:n-assigned] {
+		sizes[i]++
+	}
+	return sizes
+}
+
+func defaultSplitNames(n int) []string {
+	switch n {
+	case 2:
+		return []string{"train", "test"}
+	case 3:
+		return []string{"train", "dev", "test"}
+	}
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("split-%d", i+1)
+	}
+	return names
+}
+
+// allItems retrieves every item in the dataset.
+func (d *Dataset) allItems(ctx context.Context) ([]DatasetItem, error) {
+	var items []DatasetItem
+	for item, err := range d.Items(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,196 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func makeItems(n int, category func(i int) string) []DatasetItem {
+	items := make([]DatasetItem, n)
+	for i := range items {
+		items[i] = DatasetItem{
+			ID:   fmt.Sprintf("item-%03d", i),
+			Data: map[string]any{"n": i, "category": category(i)},
+		}
+	}
+	return items
+}
+
+func splitIDs(splits []DatasetSplit) [][]string {
+	ids := make([][]string, len(splits))
+	for i, s := range splits {
+		for _, item := range s.Items {
+			ids[i] = append(ids[i], item.ID)
+		}
+	}
+	return ids
+}
+
+func TestSplitItems(t *testing.T) {
+	items := makeItems(10, func(int) string { return "a" })
+
+	splits, err := SplitItems(items, []float64{0.8, 0.1, 0.1}, WithSeed(42))
+	if err != nil {
+		t.Fatalf("SplitItems error: %v", err)
+	}
+	var names []string
+	var sizes []int
+	seen := make(map[string]bool)
+	for _, s := range splits {
+		names = append(names, s.Name)
+		sizes = append(sizes, len(s.Items))
+		for _, item := range s.Items {
+			if seen[item.ID] {
+				t.Errorf("item %s in more than one split", item.ID)
+			}
+			seen[item.ID] = true
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"train", "dev", "test"}) {
+		t.Errorf("names = %v", names)
+	}
+	if !reflect.DeepEqual(sizes, []int{8, 1, 1}) {
+		t.Errorf("sizes = %v, want [8 1 1]", sizes)
+	}
+
+	// Input order does not matter, the seed does.
+	reversed := make([]DatasetItem, len(items))
+	for i, item := range items {
+		reversed[len(items)-1-i] = item
+	}
+	again, _ := SplitItems(reversed, []float64{0.8, 0.1, 0.1}, WithSeed(42))
+	if !reflect.DeepEqual(splitIDs(splits), splitIDs(again)) {
+		t.Error("same seed should give the same split regardless of item order")
+	}
+	other, _ := SplitItems(items, []float64{0.8, 0.1, 0.1}, WithSeed(7))
+	if reflect.DeepEqual(splitIDs(splits), splitIDs(other)) {
+		t.Error("different seeds should give different splits")
+	}
+}
+
+func TestSplitItemsStratified(t *testing.T) {
+	items := makeItems(40, func(i int) string {
+		if i%4 == 0 {
+			return "rare"
+		}
+		return "common"
+	})
+
+	splits, err := SplitItems(items, []float64{1, 1}, WithStratifyBy("category"), WithSplitNames("a", "b"))
+	if err != nil {
+		t.Fatalf("SplitItems error: %v", err)
+	}
+	for _, s := range splits {
+		counts := make(map[any]int)
+		for _, item := range s.Items {
+			counts[item.Data["category"]]++
+		}
+		if counts["rare"] != 5 || counts["common"] != 15 {
+			t.Errorf("split %s counts = %v, want rare:5 common:15", s.Name, counts)
+		}
+	}
+}
+
+func TestSplitItemsInvalid(t *testing.T) {
+	items := makeItems(3, func(int) string { return "" })
+	for _, tt := range []struct {
+		name   string
+		ratios []float64
+		opts   []SplitOption
+	}{
+		{"one ratio", []float64{1}, nil},
+		{"zero ratio", []float64{1, 0}, nil},
+		{"negative ratio", []float64{1, -1}, nil},
+		{"name mismatch", []float64{1, 1}, []SplitOption{WithSplitNames("only")}},
+	} {
+		if _, err := SplitItems(items, tt.ratios, tt.opts...); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: err = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+
+	splits, err := SplitItems(items, []float64{1, 1, 1, 1})
+	if err != nil {
+		t.Fatalf("SplitItems error: %v", err)
+	}
+	if splits[3].Name != "split-4" || len(splits[3].Items) != 0 {
+		t.Errorf("split 4 = %s with %d items", splits[3].Name, len(splits[3].Items))
+	}
+}
+
+func TestDatasetSplitDerived(t *testing.T) {
+	var mu sync.Mutex
+	var created []string
+	inserted := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/items"):
+			var content []string
+			for i := range 10 {
+				content = append(content, fmt.Sprintf(`{"id": %q, "source": "manual", "data": {"n": %d}}`, uuid.NewString(), i))
+			}
+			fmt.Fprintf(w, `{"page": 1, "size": 10, "total": 10, "content": [%s]}`, strings.Join(content, ","))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/private/datasets":
+			var body struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			created = append(created, body.Name)
+			mu.Unlock()
+			w.Header().Set("Location", "/v1/private/datasets/"+body.ID)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/private/datasets/items":
+			var body struct {
+				DatasetName string           `json:"dataset_name"`
+				DatasetID   string           `json:"dataset_id"`
+				Items       []map[string]any `json:"items"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			inserted[body.DatasetID] += len(body.Items)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	dataset := &Dataset{client: client, id: uuid.NewString(), name: "qa"}
+
+	splits, err := dataset.Split(context.Background(), []float64{0.7, 0.3}, WithDerivedDatasets())
+	if err != nil {
+		t.Fatalf("Split error: %v", err)
+	}
+
+	if !reflect.DeepEqual(created, []string{"qa-train", "qa-test"}) {
+		t.Errorf("created = %v", created)
+	}
+	for _, s := range splits {
+		if s.Dataset == nil {
+			t.Fatalf("split %s has no derived dataset", s.Name)
+		}
+		if got := inserted[s.Dataset.ID()]; got != len(s.Items) {
+			t.Errorf("split %s inserted %d items, want %d", s.Name, got, len(s.Items))
+		}
+	}
+	if len(splits[0].Items) != 7 || len(splits[1].Items) != 3 {
+		t.Errorf("sizes = %d, %d, want 7, 3", len(splits[0].Items), len(splits[1].Items))
+	}
+}
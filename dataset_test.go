@@ -1,7 +1,12 @@
 package opik
 
 import (
+	"context"
+	"errors"
+	"reflect"
 	"testing"
+
+	"github.com/google/uuid"
 )
 
 func TestDatasetGetters(t *testing.T) {
@@ -184,3 +189,41 @@ func TestDatasetEmptyState(t *testing.T) {
 		t.Errorf("Tags() = %v, want nil", d.Tags())
 	}
 }
+
+func TestDatasetInsertItemsWithResult(t *testing.T) {
+	ts := newRejectingServer("bad-item")
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	client.batchConfig = testBatchConfig()
+	client.batchConfig.maxItems = 4
+
+	d := &Dataset{client: client, id: uuid.NewString(), name: "test-dataset"}
+
+	items := make([]map[string]any, 10)
+	for i := range items {
+		items[i] = map[string]any{"input": "ok"}
+	}
+	items[3] = map[string]any{"input": "bad-item"}
+	items[8] = map[string]any{"input": "bad-item"}
+
+	result, err := d.InsertItemsWithResult(context.Background(), items)
+	if err != nil {
+		t.Fatalf("InsertItemsWithResult error: %v", err)
+	}
+	if result.Succeeded != 8 {
+		t.Errorf("Succeeded = %d, want 8", result.Succeeded)
+	}
+	if got := result.FailedIndexes(); !reflect.DeepEqual(got, []int{3, 8}) {
+		t.Errorf("FailedIndexes = %v, want [3 8]", got)
+	}
+
+	err = d.InsertItems(context.Background(), items)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Failed) != 2 {
+		t.Errorf("InsertItems error = %v, want *BatchError with 2 failures", err)
+	}
+}
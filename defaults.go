@@ -0,0 +1,122 @@
+package opik
+
+import (
+	"slices"
+
+	"github.com/plexusone/opik-go/metadata"
+)
+
+// RedactFunc transforms an input or output value before it is sent to Opik,
+// for example to mask personal data. It must not modify value in place.
+type RedactFunc func(value any) any
+
+// SetDefaultTraceOptions sets options applied to every trace the client
+// creates, before the options passed to Trace. Use it to enforce
+// organization-wide conventions, such as a team tag or a redactor, in one
+// place instead of at every call site.
+//
+// Default metadata and tags are merged with those passed to Trace: call-site
+// metadata keys take precedence, and tags are combined. Other options passed
+// to Trace override the defaults. Calling SetDefaultTraceOptions again
+// replaces the previous defaults.
+func (c *Client) SetDefaultTraceOptions(opts ...TraceOption) {
+	c.defaultsMu.Lock()
+	defer c.defaultsMu.Unlock()
+	c.defaultTraceOpts = opts
+}
+
+// SetDefaultSpanOptions sets options applied to every span the client
+// creates, before the options passed to Span. Metadata and tags are merged
+// as described for SetDefaultTraceOptions.
+func (c *Client) SetDefaultSpanOptions(opts ...SpanOption) {
+	c.defaultsMu.Lock()
+	defer c.defaultsMu.Unlock()
+	c.defaultSpanOpts = opts
+}
+
+// newTraceOptions applies the client's default trace options and then opts.
+func (c *Client) newTraceOptions(opts []TraceOption) *traceOptions {
+	c.defaultsMu.RLock()
+	defaults := c.defaultTraceOpts
+	c.defaultsMu.RUnlock()
+
+	options := defaultTraceOptions()
+	if len(defaults) == 0 {
+		for _, opt := range opts {
+			opt(options)
+		}
+		return options
+	}
+
+	for _, opt := range defaults {
+		opt(options)
+	}
+	md, tags := options.metadata, options.tags
+	options.metadata = nil
+	for _, opt := range opts {
+		opt(options)
+	}
+	options.metadata = mergeMetadata(md, options.metadata)
+	options.tags = mergeTags(tags, options.tags)
+	return options
+}
+
+// newSpanOptions applies the client's default span options and then opts.
+func (c *Client) newSpanOptions(opts []SpanOption) *spanOptions {
+	c.defaultsMu.RLock()
+	defaults := c.defaultSpanOpts
+	c.defaultsMu.RUnlock()
+
+	options := defaultSpanOptions()
+	if len(defaults) == 0 {
+		for _, opt := range opts {
+			opt(options)
+		}
+		return options
+	}
+
+	for _, opt := range defaults {
+		opt(options)
+	}
+	md, tags := options.metadata, options.tags
+	options.metadata = nil
+	for _, opt := range opts {
+		opt(options)
+	}
+	options.metadata = mergeMetadata(md, options.metadata)
+	options.tags = mergeTags(tags, options.tags)
+	return options
+}
+
+// mergeMetadata returns a new map with the default metadata overlaid by the
+// call-site metadata, as metadata.Merge does. Neither input map is
+// modified, so a defaults map is never shared between traces or spans.
+func mergeMetadata(defaults, md map[string]any) map[string]any {
+	if len(defaults) == 0 && len(md) == 0 {
+		return md
+	}
+	return metadata.Merge(defaults, md)
+}
+
+// mergeTags returns the default tags followed by any call-site tags not
+// already present. Tag lists are short, so a linear scan is cheaper than a
+// set.
+func mergeTags(defaults, tags []string) []string {
+	merged := make([]string, 0, len(defaults)+len(tags))
+	for _, list := range [][]string{defaults, tags} {
+		for _, tag := range list {
+			if !slices.Contains(merged, tag) {
+				merged = append(merged, tag)
+			}
+		}
+	}
+	return merged
+}
+
+// redact applies fn to value, if both are set.
+func redact(fn RedactFunc, value any) any {
+	if fn == nil || value == nil {
+		return value
+	}
+	return fn(value)
+}
@@ -0,0 +1,167 @@
+package opik
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+type createdEntity struct {
+	Name        string         `json:"name"`
+	ProjectName string         `json:"project_name"`
+	Input       any            `json:"input"`
+	Output      any            `json:"output"`
+	Metadata    map[string]any `json:"metadata"`
+	Tags        []string       `json:"tags"`
+	EndTime     *time.Time     `json:"end_time"`
+	ErrorInfo   *struct {
+		ExceptionType string `json:"exception_type"`
+		Message       string `json:"message"`
+	} `json:"error_info"`
+}
+
+// newCreateServer accepts every request and returns a function reporting the
+// last trace or span written to a path, keyed by method and path, e.g.
+// "POST /v1/private/spans/batch".
+func newCreateServer() (*testutil.MockServer, func(key string) createdEntity) {
+	ms := testutil.NewMockServer()
+	ms.OnUnmatched().Respond(http.StatusNoContent, nil)
+	return ms, func(key string) createdEntity {
+		method, path, _ := strings.Cut(key, " ")
+		reqs := ms.RequestsFor(method, path)
+		for i := len(reqs) - 1; i >= 0; i-- {
+			var body struct {
+				Traces []createdEntity `json:"traces"`
+				Spans  []createdEntity `json:"spans"`
+				Update createdEntity   `json:"update"`
+			}
+			_ = reqs[i].DecodeJSON(&body)
+			switch {
+			case method == http.MethodPatch:
+				return body.Update
+			case len(body.Traces) > 0:
+				return body.Traces[0]
+			case len(body.Spans) > 0:
+				return body.Spans[0]
+			}
+		}
+		return createdEntity{}
+	}
+}
+
+func TestDefaultOptions(t *testing.T) {
+	ts, created := newCreateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	defaults := map[string]any{"team": "search", "env": "prod"}
+	client.SetDefaultTraceOptions(
+		WithTraceTags("org"),
+		WithTraceMetadata(defaults),
+	)
+	client.SetDefaultSpanOptions(
+		WithSpanTags("org"),
+		WithSpanMetadata(defaults),
+	)
+
+	trace, err := client.Trace(ctx, "request",
+		WithTraceTags("checkout", "org"),
+		WithTraceMetadata(map[string]any{"env": "staging"}),
+	)
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	got := created("POST /v1/private/traces/batch")
+	if strings.Join(got.Tags, ",") != "org,checkout" {
+		t.Errorf("trace tags = %v, want [org checkout]", got.Tags)
+	}
+	if got.Metadata["team"] != "search" || got.Metadata["env"] != "staging" {
+		t.Errorf("trace metadata = %v", got.Metadata)
+	}
+
+	span, err := trace.Span(ctx, "step")
+	if err != nil {
+		t.Fatalf("Span error: %v", err)
+	}
+	got = created("POST /v1/private/spans/batch")
+	if strings.Join(got.Tags, ",") != "org" || got.Metadata["env"] != "prod" {
+		t.Errorf("span = %+v", got)
+	}
+
+	// Ending the span must not modify the shared defaults.
+	if err := span.End(ctx, WithSpanMetadata(map[string]any{"done": true})); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+	if len(defaults) != 2 {
+		t.Errorf("defaults modified: %v", defaults)
+	}
+}
+
+func TestDefaultRedactor(t *testing.T) {
+	ts, created := newCreateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	mask := func(value any) any {
+		if s, ok := value.(string); ok {
+			return strings.ReplaceAll(s, "alice@example.com", "[email]")
+		}
+		return value
+	}
+	client.SetDefaultTraceOptions(WithTraceRedactor(mask))
+	client.SetDefaultSpanOptions(WithSpanRedactor(mask))
+
+	trace, err := client.Trace(ctx, "request", WithTraceInput("mail alice@example.com"))
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	if got := created("POST /v1/private/traces/batch").Input; got != "mail [email]" {
+		t.Errorf("trace input = %v", got)
+	}
+
+	span, err := trace.Span(ctx, "llm", WithSpanInput("to alice@example.com"))
+	if err != nil {
+		t.Fatalf("Span error: %v", err)
+	}
+	if got := created("POST /v1/private/spans/batch").Input; got != "to [email]" {
+		t.Errorf("span input = %v", got)
+	}
+	if err := span.End(ctx, WithSpanOutput("sent to alice@example.com")); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+	if got := created("PATCH /v1/private/spans/batch").Output; got != "sent to [email]" {
+		t.Errorf("span output = %v", got)
+	}
+
+	// A call-site option overrides the default redactor.
+	if _, err := client.Trace(ctx, "raw", WithTraceInput("alice@example.com"), WithTraceRedactor(nil)); err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	if got := created("POST /v1/private/traces/batch").Input; got != "alice@example.com" {
+		t.Errorf("trace input = %v, want unredacted", got)
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	got := mergeTags([]string{"a", "b"}, []string{"b", "c", "a"})
+	if strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("mergeTags = %v", got)
+	}
+	if got := mergeTags(nil, nil); len(got) != 0 {
+		t.Errorf("mergeTags(nil, nil) = %v", got)
+	}
+}
@@ -2,7 +2,11 @@ package opik
 
 import (
 	"context"
+	"encoding/hex"
 	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
 )
 
 // DistributedTraceHeaders contains trace context for cross-service propagation.
@@ -15,6 +19,9 @@ type DistributedTraceHeaders struct {
 const (
 	HeaderTraceID      = "X-Opik-Trace-ID"
 	HeaderParentSpanID = "X-Opik-Parent-Span-ID"
+
+	// HeaderTraceParent is the W3C Trace Context header.
+	HeaderTraceParent = "traceparent"
 )
 
 // GetDistributedTraceHeaders returns the current trace context from the context.
@@ -33,19 +40,105 @@ func GetDistributedTraceHeaders(ctx context.Context) DistributedTraceHeaders {
 		}
 	}
 
+	if headers.TraceID == "" {
+		if remote, ok := ctx.Value(remoteTraceContextKey).(DistributedTraceHeaders); ok {
+			headers = remote
+		}
+	}
+
 	return headers
 }
 
 // InjectDistributedTraceHeaders adds trace context headers to an HTTP request.
 func InjectDistributedTraceHeaders(ctx context.Context, req *http.Request) {
-	headers := GetDistributedTraceHeaders(ctx)
+	InjectTraceContext(ctx, req.Header)
+}
 
-	if headers.TraceID != "" {
-		req.Header.Set(HeaderTraceID, headers.TraceID)
+// InjectTraceContext adds the trace context of ctx to outgoing headers: the
+// Opik headers, which carry the full trace and span IDs, and a W3C
+// traceparent header, so proxies and services instrumented with
+// OpenTelemetry keep the trace ID. An existing traceparent header, such as
+// one set by OpenTelemetry instrumentation, is kept.
+//
+// The W3C parent ID is 8 bytes, too short for an Opik span ID, so it is
+// taken from the end of the span ID and a receiving service continues the
+// trace from the Opik headers when they are present.
+func InjectTraceContext(ctx context.Context, header http.Header) {
+	headers := GetDistributedTraceHeaders(ctx)
+	if headers.TraceID == "" {
+		return
 	}
+	header.Set(HeaderTraceID, headers.TraceID)
 	if headers.ParentSpanID != "" {
-		req.Header.Set(HeaderParentSpanID, headers.ParentSpanID)
+		header.Set(HeaderParentSpanID, headers.ParentSpanID)
+	}
+	if header.Get(HeaderTraceParent) == "" {
+		if traceparent := formatTraceParent(headers); traceparent != "" {
+			header.Set(HeaderTraceParent, traceparent)
+		}
+	}
+}
+
+// ExtractTraceContext returns a context carrying the trace context of
+// incoming headers. StartSpan continues the remote trace from the returned
+// context, using the client attached with ContextWithClient, and outgoing
+// requests made with it propagate the trace further.
+//
+//	ctx := opik.ExtractTraceContext(r.Context(), r.Header)
+//	ctx, span, err := opik.StartSpan(opik.ContextWithClient(ctx, client), "handle-request")
+//
+// The Opik headers are used when present. Otherwise the trace is taken from
+// a W3C traceparent header whose trace ID is an Opik trace ID (a version 7
+// UUID), and spans started from the context are top-level spans of that
+// trace. Without trace headers, ctx is returned unchanged.
+func ExtractTraceContext(ctx context.Context, header http.Header) context.Context {
+	headers := DistributedTraceHeaders{
+		TraceID:      header.Get(HeaderTraceID),
+		ParentSpanID: header.Get(HeaderParentSpanID),
+	}
+	if headers.TraceID == "" {
+		headers = DistributedTraceHeaders{TraceID: parseTraceParent(header.Get(HeaderTraceParent))}
+	}
+	if headers.TraceID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteTraceContextKey, headers)
+}
+
+// formatTraceParent returns the W3C traceparent header for headers, or ""
+// if the trace ID is not a UUID.
+func formatTraceParent(headers DistributedTraceHeaders) string {
+	traceID, err := uuid.Parse(headers.TraceID)
+	if err != nil {
+		return ""
+	}
+	parentID := traceID
+	if spanID, err := uuid.Parse(headers.ParentSpanID); err == nil {
+		parentID = spanID
+	}
+	return "00-" + hex.EncodeToString(traceID[:]) + "-" + hex.EncodeToString(parentID[8:]) + "-01"
+}
+
+// parseTraceParent returns the trace ID of a W3C traceparent header as an
+// Opik trace ID, or "" if the header is invalid or its trace ID is not a
+// version 7 UUID.
+func parseTraceParent(value string) string {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return ""
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return ""
+	}
+	raw, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	id, err := uuid.FromBytes(raw)
+	if err != nil || id.Version() != 7 {
+		return ""
 	}
+	return id.String()
 }
 
 // ExtractDistributedTraceHeaders extracts trace context from HTTP request headers.
@@ -85,7 +178,7 @@ func (c *Client) ContinueTrace(ctx context.Context, headers DistributedTraceHead
 
 // createSpanWithParent creates a span with explicit trace and parent span IDs.
 func (c *Client) createSpanWithParent(ctx context.Context, traceID, parentSpanID, name string, opts ...SpanOption) (*Span, error) {
-	return c.createSpan(ctx, traceID, parentSpanID, name, opts...)
+	return c.createSpan(ctx, traceID, parentSpanID, "", nil, nil, name, opts...)
 }
 
 // PropagatingRoundTripper wraps an http.RoundTripper to automatically inject
@@ -2,9 +2,13 @@ package opik
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/google/uuid"
 )
 
 func TestDistributedTraceHeaders(t *testing.T) {
@@ -233,3 +237,127 @@ func TestDistributedTraceHeadersJSON(t *testing.T) {
 		t.Error("struct fields not set correctly")
 	}
 }
+
+func TestInjectTraceContext(t *testing.T) {
+	traceID := uuid.Must(uuid.NewV7()).String()
+	spanID := uuid.Must(uuid.NewV7()).String()
+	ctx := ContextWithSpan(context.Background(), &Span{id: spanID, traceID: traceID})
+
+	header := http.Header{}
+	InjectTraceContext(ctx, header)
+	if header.Get(HeaderTraceID) != traceID || header.Get(HeaderParentSpanID) != spanID {
+		t.Errorf("opik headers = %v", header)
+	}
+	wantParent := "00-" + strings.ReplaceAll(traceID, "-", "") + "-" + strings.ReplaceAll(spanID, "-", "")[16:] + "-01"
+	if got := header.Get(HeaderTraceParent); got != wantParent {
+		t.Errorf("traceparent = %q, want %q", got, wantParent)
+	}
+
+	// An existing traceparent is kept.
+	header = traceHeader(HeaderTraceParent, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	InjectTraceContext(ctx, header)
+	if got := header.Get(HeaderTraceParent); got != "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01" {
+		t.Errorf("traceparent = %q, want the existing header", got)
+	}
+
+	// IDs that are not UUIDs are sent in the Opik headers only.
+	header = http.Header{}
+	InjectTraceContext(ContextWithTrace(context.Background(), &Trace{id: "trace-123"}), header)
+	if header.Get(HeaderTraceID) != "trace-123" || header.Get(HeaderTraceParent) != "" {
+		t.Errorf("headers = %v", header)
+	}
+}
+
+func TestExtractTraceContext(t *testing.T) {
+	traceID := uuid.Must(uuid.NewV7())
+	traceHex := strings.ReplaceAll(traceID.String(), "-", "")
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   DistributedTraceHeaders
+	}{
+		{
+			"opik headers",
+			traceHeader(
+				HeaderTraceID, "trace-123",
+				HeaderParentSpanID, "span-456",
+				HeaderTraceParent, "00-"+traceHex+"-b7ad6b7169203331-01",
+			),
+			DistributedTraceHeaders{TraceID: "trace-123", ParentSpanID: "span-456"},
+		},
+		{
+			"traceparent",
+			traceHeader(HeaderTraceParent, "00-"+traceHex+"-b7ad6b7169203331-01"),
+			DistributedTraceHeaders{TraceID: traceID.String()},
+		},
+		{
+			"future traceparent version",
+			traceHeader(HeaderTraceParent, "01-"+traceHex+"-b7ad6b7169203331-01-extra"),
+			DistributedTraceHeaders{TraceID: traceID.String()},
+		},
+		{"not an opik trace ID", traceHeader(HeaderTraceParent, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"), DistributedTraceHeaders{}},
+		{"invalid traceparent", traceHeader(HeaderTraceParent, "00-"+traceHex+"-01"), DistributedTraceHeaders{}},
+		{"no headers", http.Header{}, DistributedTraceHeaders{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ExtractTraceContext(context.Background(), tt.header)
+			if got := GetDistributedTraceHeaders(ctx); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// A service that forwards the request propagates the extracted trace.
+	ctx := ExtractTraceContext(context.Background(), traceHeader(HeaderTraceID, "trace-123", HeaderParentSpanID, "span-456"))
+	header := http.Header{}
+	InjectTraceContext(ctx, header)
+	if header.Get(HeaderTraceID) != "trace-123" || header.Get(HeaderParentSpanID) != "span-456" {
+		t.Errorf("forwarded headers = %v", header)
+	}
+}
+
+func TestStartSpanFromExtractedContext(t *testing.T) {
+	ts, created := newCreateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	traceID := uuid.Must(uuid.NewV7()).String()
+	parentID := uuid.Must(uuid.NewV7()).String()
+	header := traceHeader(HeaderTraceID, traceID, HeaderParentSpanID, parentID)
+
+	ctx := ExtractTraceContext(context.Background(), header)
+	if _, _, err := StartSpan(ctx, "handle-request"); !errors.Is(err, ErrNoActiveTrace) {
+		t.Errorf("StartSpan without a client = %v, want ErrNoActiveTrace", err)
+	}
+
+	ctx, span, err := StartSpan(ContextWithClient(ctx, client), "handle-request")
+	if err != nil {
+		t.Fatalf("StartSpan error: %v", err)
+	}
+	if span.TraceID() != traceID || span.ParentSpanID() != parentID {
+		t.Errorf("span trace = %q, parent = %q", span.TraceID(), span.ParentSpanID())
+	}
+	if got := GetDistributedTraceHeaders(ctx); got.ParentSpanID != span.ID() {
+		t.Errorf("outgoing parent = %q, want the new span", got.ParentSpanID)
+	}
+	if err := span.End(ctx); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+	if created("POST /v1/private/spans/batch").EndTime == nil && created("PATCH /v1/private/spans/batch").EndTime == nil {
+		t.Error("span was not sent")
+	}
+}
+
+// traceHeader returns headers with the given names and values.
+func traceHeader(pairs ...string) http.Header {
+	header := http.Header{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		header.Set(pairs[i], pairs[i+1])
+	}
+	return header
+}
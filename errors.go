@@ -13,7 +13,7 @@ var (
 	// ErrMissingWorkspace is returned when the workspace is required but not provided.
 	ErrMissingWorkspace = errors.New("opik: missing workspace for Opik Cloud")
 
-	// ErrTracingDisabled is returned when tracing is disabled.
+	// ErrTracingDisabled is returned by ImportTraces when tracing is disabled.
 	ErrTracingDisabled = errors.New("opik: tracing is disabled")
 
 	// ErrTraceNotFound is returned when a trace cannot be found.
@@ -39,6 +39,13 @@ var (
 
 	// ErrNoActiveSpan is returned when there is no active span in context.
 	ErrNoActiveSpan = errors.New("opik: no active span in context")
+
+	// ErrNoClient is returned when there is no client in context.
+	ErrNoClient = errors.New("opik: no client in context")
+
+	// ErrUnsupportedServer is returned when a feature is not supported by the
+	// connected Opik server. See UnsupportedServerError for details.
+	ErrUnsupportedServer = errors.New("opik: feature not supported by server")
 )
 
 // APIError represents an error returned by the Opik API.
@@ -0,0 +1,78 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBudgetExhausted is returned by judges whose engine's Budget has run
+// out. The engine records such metrics as not scored.
+var ErrBudgetExhausted = errors.New("evaluation budget exhausted")
+
+// Budget caps the judge tokens an evaluation run may spend. Judges built on
+// llm.BaseJudge check the budget of an engine configured with WithBudget
+// before each request and charge it the prompt and output tokens of each
+// response. Once it is spent, further judge metrics are not scored, while
+// metrics that need no judge are still scored. Requests already sent when
+// the budget runs out are still charged, so concurrent runs can overshoot
+// it slightly.
+//
+// A Budget can be shared by several engines to cap their total spend. It
+// is safe for concurrent use.
+type Budget struct {
+	mu     sync.Mutex
+	tokens int
+	spent  int
+}
+
+// NewTokenBudget creates a budget of tokens judge tokens. A budget of zero
+// or less is exhausted from the start.
+func NewTokenBudget(tokens int) *Budget {
+	return &Budget{tokens: tokens}
+}
+
+// Spend charges tokens to the budget.
+func (b *Budget) Spend(tokens int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spent += tokens
+}
+
+// Exhausted reports whether the budget has been spent.
+func (b *Budget) Exhausted() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.spent >= b.tokens
+}
+
+// Spent returns the number of tokens charged so far.
+func (b *Budget) Spent() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.spent
+}
+
+// Remaining returns the number of tokens left, or zero once the budget is
+// exhausted.
+func (b *Budget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return max(b.tokens-b.spent, 0)
+}
+
+type budgetContextKey struct{}
+
+// ContextWithBudget returns a new context with the budget attached. The
+// engine does this for each item when configured with WithBudget.
+func ContextWithBudget(ctx context.Context, b *Budget) context.Context {
+	return context.WithValue(ctx, budgetContextKey{}, b)
+}
+
+// BudgetFromContext returns the budget from the context, or nil if none.
+func BudgetFromContext(ctx context.Context) *Budget {
+	if b, ok := ctx.Value(budgetContextKey{}).(*Budget); ok {
+		return b
+	}
+	return nil
+}
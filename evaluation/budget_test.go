@@ -0,0 +1,63 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestBudget(t *testing.T) {
+	b := NewTokenBudget(100)
+	if b.Exhausted() || b.Remaining() != 100 {
+		t.Fatalf("new budget: exhausted = %v, remaining = %d", b.Exhausted(), b.Remaining())
+	}
+	b.Spend(60)
+	if b.Exhausted() || b.Remaining() != 40 || b.Spent() != 60 {
+		t.Errorf("after 60: exhausted = %v, remaining = %d, spent = %d", b.Exhausted(), b.Remaining(), b.Spent())
+	}
+	b.Spend(60)
+	if !b.Exhausted() || b.Remaining() != 0 || b.Spent() != 120 {
+		t.Errorf("after 120: exhausted = %v, remaining = %d, spent = %d", b.Exhausted(), b.Remaining(), b.Spent())
+	}
+
+	if !NewTokenBudget(0).Exhausted() {
+		t.Error("zero budget should be exhausted")
+	}
+}
+
+func TestEngineBudget(t *testing.T) {
+	budget := NewTokenBudget(25)
+	judge := NewMetricFunc("judge", func(ctx context.Context, input MetricInput) *ScoreResult {
+		b := BudgetFromContext(ctx)
+		if b == nil {
+			return NewFailedScoreResult("judge", fmt.Errorf("no budget in context"))
+		}
+		if b.Exhausted() {
+			return NewFailedScoreResult("judge", fmt.Errorf("scoring: %w", ErrBudgetExhausted))
+		}
+		b.Spend(10)
+		return NewScoreResult("judge", 1.0)
+	})
+	free := NewMetricFunc("free", func(ctx context.Context, input MetricInput) *ScoreResult {
+		return NewScoreResult("free", 0.5)
+	})
+
+	engine := NewEngine([]Metric{judge, free}, WithBudget(budget))
+	inputs := make([]MetricInput, 5)
+	results := engine.EvaluateMany(context.Background(), inputs)
+
+	counts := results.CountByStatus("judge")
+	if counts[ScoreStatusScored] != 3 || counts[ScoreStatusNotScored] != 2 || counts[ScoreStatusFailed] != 0 {
+		t.Errorf("judge CountByStatus = %v, want 3 scored and 2 not scored", counts)
+	}
+	if got := results.CountByStatus("free")[ScoreStatusScored]; got != 5 {
+		t.Errorf("free metric scored %d items, want 5", got)
+	}
+	last := results[4].Scores.ByName("judge")
+	if last.Reason != ErrBudgetExhausted.Error() || last.Error != nil {
+		t.Errorf("last judge result = %+v, want not scored with the budget reason", last)
+	}
+	if got := results.AverageByMetric("judge"); got != 1.0 {
+		t.Errorf("AverageByMetric(judge) = %v, want 1.0", got)
+	}
+}
@@ -0,0 +1,121 @@
+package evaluation
+
+import (
+	"context"
+	"sync"
+)
+
+// Coalescer shares the results of identical judge requests across metrics.
+// Different metrics often send the same prompt for the same item, and in
+// large multi-metric suites those duplicates add up. Judges built on
+// llm.BaseJudge look up the coalescer of an engine configured with
+// WithCoalescer and key their requests by provider, model, temperature, and
+// prompt hash, so only the first request is sent and the others wait for
+// and reuse its response.
+//
+// Successful results are kept until Reset, so a Coalescer is typically
+// created per evaluation run. It is safe for concurrent use.
+type Coalescer struct {
+	mu       sync.Mutex
+	calls    map[string]*coalescedCall
+	requests int
+	shared   int
+}
+
+type coalescedCall struct {
+	done chan struct{}
+	val  any
+	err  error
+}
+
+// CoalescerStats counts the requests seen by a Coalescer.
+type CoalescerStats struct {
+	// Requests is the number of calls to Do.
+	Requests int
+	// Shared is the number of calls answered with another call's result.
+	Shared int
+}
+
+// NewCoalescer creates an empty coalescer.
+func NewCoalescer() *Coalescer {
+	return &Coalescer{calls: make(map[string]*coalescedCall)}
+}
+
+// Do calls fn once per key and returns its result. Callers with the same
+// key, while fn is running or after it has succeeded, get the same result
+// and shared set to true. Failed calls are forgotten, so a caller that was
+// waiting on one runs fn itself. A waiting caller returns ctx's error if ctx
+// is done first.
+func (c *Coalescer) Do(ctx context.Context, key string, fn func() (any, error)) (v any, shared bool, err error) {
+	c.mu.Lock()
+	c.requests++
+	for {
+		call, ok := c.calls[key]
+		if !ok {
+			break
+		}
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+		if call.err == nil {
+			c.mu.Lock()
+			c.shared++
+			c.mu.Unlock()
+			return call.val, true, nil
+		}
+		c.mu.Lock()
+	}
+
+	call := &coalescedCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	call.val, call.err = fn()
+
+	if call.err != nil {
+		c.mu.Lock()
+		if c.calls[key] == call {
+			delete(c.calls, key)
+		}
+		c.mu.Unlock()
+	}
+	close(call.done)
+	return call.val, false, call.err
+}
+
+// Stats returns the number of requests seen and shared so far.
+func (c *Coalescer) Stats() CoalescerStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CoalescerStats{Requests: c.requests, Shared: c.shared}
+}
+
+// Reset forgets the stored results and counts. Calls in flight finish
+// normally but their results are not stored.
+func (c *Coalescer) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = make(map[string]*coalescedCall)
+	c.requests = 0
+	c.shared = 0
+}
+
+type coalescerContextKey struct{}
+
+// ContextWithCoalescer returns a new context with the coalescer attached.
+// The engine does this for each item when configured with WithCoalescer.
+func ContextWithCoalescer(ctx context.Context, c *Coalescer) context.Context {
+	return context.WithValue(ctx, coalescerContextKey{}, c)
+}
+
+// CoalescerFromContext returns the coalescer from the context, or nil if
+// none.
+func CoalescerFromContext(ctx context.Context) *Coalescer {
+	if c, ok := ctx.Value(coalescerContextKey{}).(*Coalescer); ok {
+		return c
+	}
+	return nil
+}
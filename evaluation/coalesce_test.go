@@ -0,0 +1,131 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCoalescerDo(t *testing.T) {
+	c := NewCoalescer()
+	release := make(chan struct{})
+	var calls atomic.Int32
+
+	var wg sync.WaitGroup
+	results := make([]any, 5)
+	var sharedCount atomic.Int32
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, shared, err := c.Do(context.Background(), "key", func() (any, error) {
+				calls.Add(1)
+				<-release
+				return "response", nil
+			})
+			if err != nil {
+				t.Errorf("Do error: %v", err)
+			}
+			if shared {
+				sharedCount.Add(1)
+			}
+			results[i] = v
+		}(i)
+	}
+	// Wait until every caller has joined the in-flight call.
+	for c.Stats().Requests < len(results) {
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("fn called %d times, want 1", calls.Load())
+	}
+	for _, v := range results {
+		if v != "response" {
+			t.Errorf("result = %v, want response", v)
+		}
+	}
+	if sharedCount.Load() != 4 {
+		t.Errorf("shared = %d, want 4", sharedCount.Load())
+	}
+
+	// Completed results are reused until Reset.
+	if _, shared, _ := c.Do(context.Background(), "key", func() (any, error) { return "again", nil }); !shared {
+		t.Error("expected the stored result to be shared")
+	}
+	if stats := c.Stats(); stats.Requests != 6 || stats.Shared != 5 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+	c.Reset()
+	if v, shared, _ := c.Do(context.Background(), "key", func() (any, error) { return "again", nil }); shared || v != "again" {
+		t.Errorf("after Reset: v = %v, shared = %v", v, shared)
+	}
+}
+
+func TestCoalescerForgetsErrors(t *testing.T) {
+	c := NewCoalescer()
+	boom := errors.New("boom")
+	if _, _, err := c.Do(context.Background(), "key", func() (any, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want boom", err)
+	}
+	v, shared, err := c.Do(context.Background(), "key", func() (any, error) { return "ok", nil })
+	if err != nil || shared || v != "ok" {
+		t.Errorf("retry: v = %v, shared = %v, err = %v", v, shared, err)
+	}
+}
+
+func TestCoalescerWaiterCancellation(t *testing.T) {
+	c := NewCoalescer()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go c.Do(context.Background(), "key", func() (any, error) { //nolint:errcheck
+		close(started)
+		<-release
+		return "slow", nil
+	})
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := c.Do(ctx, "key", func() (any, error) { return "other", nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	close(release)
+}
+
+func TestCoalescerContext(t *testing.T) {
+	if CoalescerFromContext(context.Background()) != nil {
+		t.Error("expected no coalescer in a plain context")
+	}
+	c := NewCoalescer()
+	if CoalescerFromContext(ContextWithCoalescer(context.Background(), c)) != c {
+		t.Error("CoalescerFromContext should return the attached coalescer")
+	}
+}
+
+func TestEngineWithCoalescer(t *testing.T) {
+	c := NewCoalescer()
+	var seen *Coalescer
+	metric := &contextMetric{BaseMetric: NewBaseMetric("ctx"), fn: func(ctx context.Context) { seen = CoalescerFromContext(ctx) }}
+
+	NewEngine([]Metric{metric}, WithCoalescer(c)).EvaluateOne(context.Background(), NewMetricInput("in", "out"))
+	if seen != c {
+		t.Error("metrics should see the engine's coalescer in their context")
+	}
+}
+
+// contextMetric calls fn with the context it is scored with.
+type contextMetric struct {
+	BaseMetric
+	fn func(ctx context.Context)
+}
+
+func (m *contextMetric) Score(ctx context.Context, _ MetricInput) *ScoreResult {
+	m.fn(ctx)
+	return NewScoreResult(m.Name(), 1)
+}
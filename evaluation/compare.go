@@ -0,0 +1,352 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// Comparison is the comparison of two evaluation runs over the same items,
+// run A as the baseline and run B as the candidate.
+type Comparison struct {
+	// Metrics are the comparisons of the metrics both runs scored, by name.
+	Metrics []MetricComparison `json:"metrics"`
+	// Unmatched is the number of results of either run without a result for
+	// the same item in the other run.
+	Unmatched int `json:"unmatched"`
+}
+
+// Metric returns the comparison of the named metric, or nil if it was not
+// compared.
+func (c *Comparison) Metric(name string) *MetricComparison {
+	for i := range c.Metrics {
+		if c.Metrics[i].Name == name {
+			return &c.Metrics[i]
+		}
+	}
+	return nil
+}
+
+// MetricComparison compares the scores of one metric over the items both
+// runs scored it on.
+type MetricComparison struct {
+	Name string `json:"name"`
+	// Pairs is the number of items both runs scored.
+	Pairs int `json:"pairs"`
+	// MeanA and MeanB are the mean scores of the paired items.
+	MeanA float64 `json:"mean_a"`
+	MeanB float64 `json:"mean_b"`
+	// Delta is MeanB minus MeanA, the mean of the paired differences.
+	Delta float64 `json:"delta"`
+	// TStatistic and PValue are the result of a two-sided paired t-test of
+	// the differences. PValue is 1 when there are fewer than two pairs, and
+	// TStatistic is 0 when the differences are all the same.
+	TStatistic float64 `json:"t_statistic"`
+	PValue     float64 `json:"p_value"`
+	// CILow and CIHigh are the bootstrap confidence interval of Delta.
+	CILow  float64 `json:"ci_low"`
+	CIHigh float64 `json:"ci_high"`
+	// Wins, Losses, and Ties count the items B scored higher than, lower
+	// than, and within the tie tolerance of A.
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Ties   int `json:"ties"`
+}
+
+// Significant reports whether the difference is significant at level alpha,
+// such as 0.05, by the t-test.
+func (m MetricComparison) Significant(alpha float64) bool {
+	return m.PValue < alpha
+}
+
+// CompareOption configures Compare.
+type CompareOption func(*comparer)
+
+type comparer struct {
+	metrics    []string
+	confidence float64
+	samples    int
+	seed       uint64
+	tolerance  float64
+
+	// problems are invalid option values, returned by Compare.
+	problems Problems
+}
+
+// WithCompareMetrics compares only the named metrics. By default every
+// metric both runs scored is compared.
+func WithCompareMetrics(names ...string) CompareOption {
+	return func(c *comparer) {
+		c.metrics = names
+	}
+}
+
+// WithConfidenceLevel sets the level of the bootstrap confidence intervals,
+// between 0 and 1. The default is 0.95.
+func WithConfidenceLevel(level float64) CompareOption {
+	return func(c *comparer) {
+		if level <= 0 || level >= 1 {
+			c.problems.Addf("confidence level must be between 0 and 1: %v", level)
+			return
+		}
+		c.confidence = level
+	}
+}
+
+// WithBootstrapSamples sets the number of bootstrap resamples. The default
+// is 10000.
+func WithBootstrapSamples(n int) CompareOption {
+	return func(c *comparer) {
+		if n <= 0 {
+			c.problems.Addf("bootstrap samples must be positive: %d", n)
+			return
+		}
+		c.samples = n
+	}
+}
+
+// WithBootstrapSeed sets the seed of the bootstrap resampling. The default
+// seed is 0, so comparing the same runs again gives the same intervals.
+func WithBootstrapSeed(seed uint64) CompareOption {
+	return func(c *comparer) {
+		c.seed = seed
+	}
+}
+
+// WithTieTolerance counts items whose scores differ by at most tolerance
+// as ties. The default is 0, so only equal scores tie.
+func WithTieTolerance(tolerance float64) CompareOption {
+	return func(c *comparer) {
+		if tolerance < 0 {
+			c.problems.Addf("tie tolerance must not be negative: %v", tolerance)
+			return
+		}
+		c.tolerance = tolerance
+	}
+}
+
+// Compare compares two evaluation runs over the same items, a as the
+// baseline and b as the candidate, metric by metric: the change of the
+// mean score, a paired t-test and a bootstrap confidence interval of the
+// change, and how many items improved, regressed, or stayed the same.
+//
+// Results are paired by ItemID, or by their input, expected output, and
+// context if they have no ItemID. Only items both runs scored a metric on
+// are compared for it; failed and not-scored results are left out. It
+// returns a *ValidationError for invalid options.
+func Compare(a, b EvaluationResults, opts ...CompareOption) (*Comparison, error) {
+	c := &comparer{confidence: 0.95, samples: 10000}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.problems.Err(); err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*EvaluationResult, len(a))
+	for _, res := range a {
+		byKey[pairKey(res)] = res
+	}
+	var pairs [][2]*EvaluationResult
+	matched := make(map[string]bool, len(b))
+	for _, res := range b {
+		key := pairKey(res)
+		if other, ok := byKey[key]; ok && !matched[key] {
+			pairs = append(pairs, [2]*EvaluationResult{other, res})
+			matched[key] = true
+		}
+	}
+
+	comparison := &Comparison{Unmatched: len(a) + len(b) - 2*len(pairs)}
+	names := c.metrics
+	if len(names) == 0 {
+		names = pairedMetrics(pairs)
+	}
+	for _, name := range names {
+		comparison.Metrics = append(comparison.Metrics, c.compareMetric(name, pairs))
+	}
+	return comparison, nil
+}
+
+// pairKey identifies the item of a result across runs.
+func pairKey(res *EvaluationResult) string {
+	if res.ItemID != "" {
+		return "id:" + res.ItemID
+	}
+	data, _ := json.Marshal([]any{res.Input.Input, res.Input.Expected, res.Input.Context, res.Input.ContextList})
+	return "input:" + string(data)
+}
+
+// pairedMetrics returns the sorted names of the metrics scored in both
+// results of a pair.
+func pairedMetrics(pairs [][2]*EvaluationResult) []string {
+	var names []string
+	for _, p := range pairs {
+		for _, score := range p[0].Scores {
+			if score.IsSuccess() && isScored(p[1].Scores.ByName(score.Name)) {
+				names = append(names, score.Name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func isScored(score *ScoreResult) bool {
+	return score != nil && score.IsSuccess()
+}
+
+func (c *comparer) compareMetric(name string, pairs [][2]*EvaluationResult) MetricComparison {
+	m := MetricComparison{Name: name, PValue: 1}
+	var diffs []float64
+	for _, p := range pairs {
+		sa, sb := p[0].Scores.ByName(name), p[1].Scores.ByName(name)
+		if !isScored(sa) || !isScored(sb) {
+			continue
+		}
+		m.MeanA += sa.Value
+		m.MeanB += sb.Value
+		d := sb.Value - sa.Value
+		diffs = append(diffs, d)
+		switch {
+		case math.Abs(d) <= c.tolerance:
+			m.Ties++
+		case d > 0:
+			m.Wins++
+		default:
+			m.Losses++
+		}
+	}
+	m.Pairs = len(diffs)
+	if m.Pairs == 0 {
+		return m
+	}
+	n := float64(m.Pairs)
+	m.MeanA /= n
+	m.MeanB /= n
+	m.Delta = mean(diffs)
+	m.TStatistic, m.PValue = pairedTTest(diffs)
+	m.CILow, m.CIHigh = c.bootstrap(diffs)
+	return m
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// pairedTTest returns the t statistic and two-sided p-value of a paired
+// t-test of the differences. The t statistic of differences that are all
+// the same is undefined and returned as 0, with a p-value of 1 if they are
+// zero and 0 otherwise.
+func pairedTTest(diffs []float64) (t, p float64) {
+	n := float64(len(diffs))
+	if len(diffs) < 2 {
+		return 0, 1
+	}
+	m := mean(diffs)
+	var ss float64
+	for _, d := range diffs {
+		ss += (d - m) * (d - m)
+	}
+	se := math.Sqrt(ss / (n - 1) / n)
+	if se == 0 {
+		if m == 0 {
+			return 0, 1
+		}
+		return 0, 0
+	}
+	t = m / se
+	df := n - 1
+	// The two-sided tail probability of Student's t distribution.
+	p = regularizedIncompleteBeta(df/(df+t*t), df/2, 0.5)
+	return t, p
+}
+
+// bootstrap returns the confidence interval of the mean of diffs by
+// percentile bootstrap.
+func (c *comparer) bootstrap(diffs []float64) (lo, hi float64) {
+	rng := rand.New(rand.NewPCG(c.seed, 0)) //nolint:gosec // G404: resampling needs reproducibility, not security
+	means := make([]float64, c.samples)
+	for i := range means {
+		var sum float64
+		for range diffs {
+			sum += diffs[rng.IntN(len(diffs))]
+		}
+		means[i] = sum / float64(len(diffs))
+	}
+	slices.Sort(means)
+	tail := (1 - c.confidence) / 2
+	return percentile(means, tail), percentile(means, 1-tail)
+}
+
+// percentile returns the q-th quantile of sorted values, interpolating
+// between neighbors.
+func percentile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(i)
+	return sorted[i] + frac*(sorted[i+1]-sorted[i])
+}
+
+// regularizedIncompleteBeta returns I_x(a, b), evaluated with the continued
+// fraction of Numerical Recipes.
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x >= 1:
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	const (
+		maxIterations = 300
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		for _, num := range []float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + num*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + num/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			h *= d * c
+		}
+		if math.Abs(d*c-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,135 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func runResults(metric string, values ...float64) EvaluationResults {
+	results := make(EvaluationResults, len(values))
+	for i, v := range values {
+		results[i] = &EvaluationResult{
+			ItemID: fmt.Sprintf("item-%d", i),
+			Scores: ScoreResults{NewScoreResult(metric, v)},
+		}
+	}
+	return results
+}
+
+func TestCompare(t *testing.T) {
+	a := runResults("accuracy", 0.5, 0.6, 0.7, 0.4, 0.5)
+	b := runResults("accuracy", 0.6, 0.8, 1.0, 0.8, 1.0)
+	b[4].Scores[0].Value = 0.5
+
+	comparison, err := Compare(a, b)
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	m := comparison.Metric("accuracy")
+	if m == nil || len(comparison.Metrics) != 1 || comparison.Unmatched != 0 {
+		t.Fatalf("comparison = %+v", comparison)
+	}
+	if m.Pairs != 5 || m.Wins != 4 || m.Losses != 0 || m.Ties != 1 {
+		t.Errorf("pairs %d, wins %d, losses %d, ties %d", m.Pairs, m.Wins, m.Losses, m.Ties)
+	}
+	// The differences are 0.1, 0.2, 0.3, 0.4, and 0: mean 0.2, standard
+	// error 0.0707, t 2.828 with 4 degrees of freedom, p 0.0474.
+	if math.Abs(m.Delta-0.2) > 1e-9 || math.Abs(m.MeanB-m.MeanA-m.Delta) > 1e-9 {
+		t.Errorf("delta = %v, means %v and %v", m.Delta, m.MeanA, m.MeanB)
+	}
+	if math.Abs(m.TStatistic-2.8284) > 1e-3 || math.Abs(m.PValue-0.0474) > 1e-3 {
+		t.Errorf("t = %v, p = %v, want 2.828 and 0.0474", m.TStatistic, m.PValue)
+	}
+	if !m.Significant(0.05) || m.Significant(0.01) {
+		t.Errorf("Significant wrong for p = %v", m.PValue)
+	}
+	if m.CILow <= 0 || m.CILow >= m.Delta || m.CIHigh <= m.Delta || m.CIHigh > 0.4 {
+		t.Errorf("confidence interval = [%v, %v]", m.CILow, m.CIHigh)
+	}
+
+	again, _ := Compare(a, b)
+	if again.Metrics[0] != *m {
+		t.Error("comparing the same runs again gave a different result")
+	}
+}
+
+func TestComparePairing(t *testing.T) {
+	a := EvaluationResults{
+		{Input: NewMetricInput("q1", ""), Scores: ScoreResults{NewScoreResult("m", 1)}},
+		{Input: NewMetricInput("q2", ""), Scores: ScoreResults{NewScoreResult("m", 0)}},
+		{Input: NewMetricInput("q3", ""), Scores: ScoreResults{NewFailedScoreResult("m", errors.New("boom"))}},
+		{Input: NewMetricInput("q4", ""), Scores: ScoreResults{NewScoreResult("m", 1)}},
+	}
+	b := EvaluationResults{
+		{Input: NewMetricInput("q3", "x"), Scores: ScoreResults{NewScoreResult("m", 1)}},
+		{Input: NewMetricInput("q2", "x"), Scores: ScoreResults{NewScoreResult("m", 1), NewScoreResult("other", 1)}},
+		{Input: NewMetricInput("q1", "x"), Scores: ScoreResults{NewScoreResult("m", 0)}},
+		{Input: NewMetricInput("q5", "x"), Scores: ScoreResults{NewScoreResult("m", 1)}},
+	}
+
+	comparison, err := Compare(a, b)
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if comparison.Unmatched != 2 || len(comparison.Metrics) != 1 {
+		t.Fatalf("comparison = %+v", comparison)
+	}
+	m := comparison.Metrics[0]
+	if m.Pairs != 2 || m.Wins != 1 || m.Losses != 1 || m.Delta != 0 || m.PValue != 1 {
+		t.Errorf("m = %+v", m)
+	}
+}
+
+func TestCompareNoPairs(t *testing.T) {
+	comparison, err := Compare(runResults("m", 1), nil, WithCompareMetrics("m"))
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	m := comparison.Metrics[0]
+	if m.Pairs != 0 || m.PValue != 1 || comparison.Unmatched != 1 {
+		t.Errorf("comparison = %+v", comparison)
+	}
+}
+
+func TestCompareTieTolerance(t *testing.T) {
+	comparison, err := Compare(runResults("m", 0.5, 0.5), runResults("m", 0.52, 0.9), WithTieTolerance(0.05))
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if m := comparison.Metrics[0]; m.Wins != 1 || m.Ties != 1 {
+		t.Errorf("wins %d, ties %d", m.Wins, m.Ties)
+	}
+}
+
+func TestCompareConstantDifference(t *testing.T) {
+	comparison, err := Compare(runResults("m", 0, 0, 0), runResults("m", 1, 1, 1))
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if m := comparison.Metrics[0]; m.PValue != 0 || m.TStatistic != 0 || m.CILow != 1 || m.CIHigh != 1 {
+		t.Errorf("m = %+v", m)
+	}
+}
+
+func TestCompareInvalidOptions(t *testing.T) {
+	_, err := Compare(nil, nil, WithConfidenceLevel(1), WithBootstrapSamples(0), WithTieTolerance(-1))
+	var ve *ValidationError
+	if !errors.As(err, &ve) || len(ve.Problems) != 3 {
+		t.Errorf("error = %v, want 3 problems", err)
+	}
+}
+
+func TestRegularizedIncompleteBeta(t *testing.T) {
+	tests := []struct{ x, a, b, want float64 }{
+		{0.5, 1, 1, 0.5},
+		{0.3, 2, 3, 0.3483},
+		{0.9, 5, 0.5, 0.3166},
+	}
+	for _, tt := range tests {
+		if got := regularizedIncompleteBeta(tt.x, tt.a, tt.b); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("I(%v; %v, %v) = %v, want %v", tt.x, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -9,6 +9,8 @@
 //
 //   - heuristic: Rule-based metrics (string matching, JSON validation, text similarity)
 //   - llm: LLM-based judge metrics (relevance, hallucination, factuality)
+//   - evalconfig: Declarative evaluation suites loaded from YAML or JSON files
+//   - report: Markdown, HTML, and JUnit XML reports of evaluation results
 //
 // # Basic Usage
 //
@@ -53,4 +55,17 @@
 //	    // Custom evaluation logic
 //	    return evaluation.NewScoreResult(m.Name(), 0.95)
 //	}
+//
+// # Metric Registry
+//
+// Register a factory to make a metric available by name to suite files and
+// the CLI (the heuristic and llm packages register their metrics this way):
+//
+//	func init() {
+//	    evaluation.Register("my_metric", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+//	        return NewMyMetric(), nil
+//	    })
+//	}
+//
+//	metric, err := evaluation.NewMetric("my_metric", evaluation.MetricParams{})
 package evaluation
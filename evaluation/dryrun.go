@@ -0,0 +1,109 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrDryRun is returned by judges during a dry run in place of calling
+// their provider.
+var ErrDryRun = errors.New("dry run: judge request not sent")
+
+// PromptMessage is one message of a previewed judge prompt.
+type PromptMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// PromptPreview is a judge request captured during a dry run: the exact
+// prompt that would have been sent, and to which provider and model.
+type PromptPreview struct {
+	Metric      string          `json:"metric"`
+	Provider    string          `json:"provider"`
+	Model       string          `json:"model"`
+	Temperature float64         `json:"temperature"`
+	Messages    []PromptMessage `json:"messages"`
+	// EstimatedTokens is a rough prompt token count, at four characters
+	// per token. Use the provider's tokenizer for exact counts.
+	EstimatedTokens int `json:"estimated_tokens"`
+}
+
+// DryRunResult holds the judge prompts an evaluation would send.
+type DryRunResult struct {
+	Input MetricInput
+	// Prompts are the judge requests in the order the metrics made them.
+	Prompts []PromptPreview
+	// Scores are the results of the metrics that need no judge, such as
+	// heuristic metrics. Judge metrics are not scored.
+	Scores ScoreResults
+}
+
+// EstimatedTokens returns the total estimated prompt tokens.
+func (r *DryRunResult) EstimatedTokens() int {
+	total := 0
+	for _, p := range r.Prompts {
+		total += p.EstimatedTokens
+	}
+	return total
+}
+
+// DryRun renders the judge prompts the engine's metrics would send for
+// input, without calling any provider, so template substitution and prompt
+// sizes can be checked before a large run. Judges built on llm.BaseJudge
+// record their request and return ErrDryRun instead of sending it. Judges
+// that send several requests, each built from the previous response, only
+// preview their first request.
+func (e *Engine) DryRun(ctx context.Context, input MetricInput) *DryRunResult {
+	rec := &dryRunRecorder{}
+	ctx = context.WithValue(ctx, dryRunContextKey{}, rec)
+	input = input.Preprocess(e.preprocessors...)
+
+	result := &DryRunResult{Input: input}
+	for _, metric := range e.metrics {
+		before := rec.len()
+		score := metric.Score(ctx, input)
+		if rec.len() == before && !errors.Is(score.Error, ErrDryRun) {
+			result.Scores = append(result.Scores, score)
+		}
+	}
+	result.Prompts = rec.previews
+	return result
+}
+
+type dryRunContextKey struct{}
+
+// dryRunRecorder collects previews. Metrics may send requests from
+// several goroutines, so it is locked.
+type dryRunRecorder struct {
+	mu       sync.Mutex
+	previews []PromptPreview
+}
+
+func (r *dryRunRecorder) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.previews)
+}
+
+// RecordPromptPreview records a judge request if ctx belongs to a dry run
+// and reports whether it did. Judges call it before sending a request and,
+// if it returns true, return ErrDryRun instead of sending it. The token
+// estimate is filled in if zero.
+func RecordPromptPreview(ctx context.Context, preview PromptPreview) bool {
+	rec, ok := ctx.Value(dryRunContextKey{}).(*dryRunRecorder)
+	if !ok {
+		return false
+	}
+	if preview.EstimatedTokens == 0 {
+		chars := 0
+		for _, msg := range preview.Messages {
+			chars += len(msg.Content)
+		}
+		preview.EstimatedTokens = (chars + 3) / 4
+	}
+	rec.mu.Lock()
+	rec.previews = append(rec.previews, preview)
+	rec.mu.Unlock()
+	return true
+}
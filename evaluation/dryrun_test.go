@@ -0,0 +1,56 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// previewMetric records a preview the way a judge does.
+type previewMetric struct {
+	BaseMetric
+}
+
+func (m *previewMetric) Score(ctx context.Context, input MetricInput) *ScoreResult {
+	preview := PromptPreview{
+		Metric:   m.Name(),
+		Provider: "test",
+		Messages: []PromptMessage{{Role: "user", Content: "Judge: " + input.Output}},
+	}
+	if RecordPromptPreview(ctx, preview) {
+		return NewFailedScoreResult(m.Name(), ErrDryRun)
+	}
+	return NewScoreResult(m.Name(), 1)
+}
+
+func TestEngineDryRun(t *testing.T) {
+	engine := NewEngine([]Metric{
+		&previewMetric{BaseMetric: NewBaseMetric("judge")},
+		&contextMetric{BaseMetric: NewBaseMetric("heuristic"), fn: func(context.Context) {}},
+	}, WithPreprocessors(TrimWhitespace))
+
+	result := engine.DryRun(context.Background(), NewMetricInput("q", "  twelve chars "))
+	if len(result.Prompts) != 1 {
+		t.Fatalf("prompts = %+v, want one", result.Prompts)
+	}
+	p := result.Prompts[0]
+	if p.Metric != "judge" || p.Messages[0].Content != "Judge: twelve chars" {
+		t.Errorf("preview = %+v, want the preprocessed output", p)
+	}
+	if p.EstimatedTokens != 5 || result.EstimatedTokens() != 5 {
+		t.Errorf("estimated tokens = %d, total %d, want 5", p.EstimatedTokens, result.EstimatedTokens())
+	}
+	if len(result.Scores) != 1 || result.Scores[0].Name != "heuristic" {
+		t.Errorf("scores = %+v, want only the heuristic metric", result.Scores)
+	}
+}
+
+func TestRecordPromptPreviewOutsideDryRun(t *testing.T) {
+	if RecordPromptPreview(context.Background(), PromptPreview{}) {
+		t.Error("RecordPromptPreview should report false outside a dry run")
+	}
+	m := &previewMetric{BaseMetric: NewBaseMetric("judge")}
+	if r := NewEngine([]Metric{m}).EvaluateOne(context.Background(), NewMetricInput("q", "a")); errors.Is(r.Scores[0].Error, ErrDryRun) {
+		t.Error("EvaluateOne should not be a dry run")
+	}
+}
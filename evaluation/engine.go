@@ -2,8 +2,32 @@ package evaluation
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
+
+	"github.com/plexusone/opik-go/internal/backoff"
+)
+
+// ErrMetricTimeout is the error of a metric that did not return within the
+// timeout set with WithMetricTimeout.
+var ErrMetricTimeout = errors.New("metric timed out")
+
+// ErrFailFast is the cause of the cancellation of the items left in a run
+// of an engine created with WithFailFast after a metric failed.
+var ErrFailFast = errors.New("evaluation stopped after a metric failed")
+
+// MetadataMetricAttempts is the score result metadata key of the number of
+// times a metric was run for an item, recorded when WithMetricRetries
+// retried it.
+const MetadataMetricAttempts = "metric_attempts"
+
+// Defaults for the waits between metric retries, unless set with
+// WithMetricRetryBackoff.
+const (
+	DefaultMetricRetryBackoff = 500 * time.Millisecond
+	DefaultMaxMetricRetryWait = 10 * time.Second
 )
 
 // EvaluationResult represents the result of evaluating a single item.
@@ -54,6 +78,7 @@ func (r EvaluationResults) Failed() EvaluationResults {
 }
 
 // AverageByMetric returns the average score for a specific metric across all items.
+// Items the metric failed on or skipped are not counted.
 func (r EvaluationResults) AverageByMetric(metricName string) float64 {
 	var sum float64
 	var count int
@@ -69,13 +94,54 @@ func (r EvaluationResults) AverageByMetric(metricName string) float64 {
 	return sum / float64(count)
 }
 
-// Summary returns a summary of scores by metric name.
+// AverageBySubScore returns the average of a metric's sub-score across all
+// items that report it.
+func (r EvaluationResults) AverageBySubScore(metricName, subScore string) float64 {
+	var sum float64
+	var count int
+	for _, res := range r {
+		if score := res.Scores.ByName(metricName); score != nil && score.IsSuccess() {
+			if v, ok := score.SubScores[subScore]; ok {
+				sum += v
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// CountByStatus returns how many items a metric scored, failed on, or
+// skipped. Items without a result for the metric are not counted.
+func (r EvaluationResults) CountByStatus(metricName string) map[ScoreStatus]int {
+	counts := make(map[ScoreStatus]int)
+	for _, res := range r {
+		if score := res.Scores.ByName(metricName); score != nil {
+			counts[score.status()]++
+		}
+	}
+	return counts
+}
+
+// Summary returns a summary of scores by metric name. Sub-scores are
+// included as "metric.subscore". Not-scored results are left out of the
+// averages, and a metric that was skipped for every item is left out
+// rather than reported as 0; see CountByStatus.
 func (r EvaluationResults) Summary() map[string]float64 {
-	// Collect all metric names
+	// Collect all metric and sub-score names
 	metricNames := make(map[string]bool)
+	subScores := make(map[[2]string]bool)
 	for _, res := range r {
 		for _, score := range res.Scores {
+			if score.IsNotScored() {
+				continue
+			}
 			metricNames[score.Name] = true
+			for sub := range score.SubScores {
+				subScores[[2]string{score.Name, sub}] = true
+			}
 		}
 	}
 
@@ -84,14 +150,29 @@ func (r EvaluationResults) Summary() map[string]float64 {
 	for name := range metricNames {
 		summary[name] = r.AverageByMetric(name)
 	}
+	for key := range subScores {
+		summary[key[0]+"."+key[1]] = r.AverageBySubScore(key[0], key[1])
+	}
 	return summary
 }
 
 // Engine runs evaluation metrics against data.
 type Engine struct {
-	metrics     []Metric
-	concurrency int
-	callbacks   []EvaluationCallback
+	metrics       []Metric
+	concurrency   int
+	callbacks     []EvaluationCallback
+	preprocessors []Preprocessor
+	pool          *Pool
+	coalescer     *Coalescer
+	budget        *Budget
+	metricTimeout time.Duration
+	metricRetries int
+	retryBackoff  time.Duration
+	maxRetryWait  time.Duration
+	failFast      bool
+
+	// problems are invalid option values, reported by Validate.
+	problems Problems
 }
 
 // EvaluationCallback is called during evaluation for progress updates.
@@ -100,27 +181,128 @@ type EvaluationCallback func(completed, total int, result *EvaluationResult)
 // EngineOption configures the evaluation engine.
 type EngineOption func(*Engine)
 
-// WithConcurrency sets the number of concurrent evaluations.
+// WithConcurrency sets the number of concurrent evaluations. Values below
+// one leave the default of one, and are reported by Validate.
 func WithConcurrency(n int) EngineOption {
 	return func(e *Engine) {
-		if n > 0 {
-			e.concurrency = n
+		if n <= 0 {
+			e.problems.Addf("concurrency must be positive: %d", n)
+			return
 		}
+		e.concurrency = n
 	}
 }
 
 // WithCallback adds a callback for progress updates.
 func WithCallback(cb EvaluationCallback) EngineOption {
 	return func(e *Engine) {
+		if cb == nil {
+			e.problems.Addf("WithCallback: callback is nil")
+			return
+		}
 		e.callbacks = append(e.callbacks, cb)
 	}
 }
 
+// WithPool runs evaluations through a shared worker pool. EvaluateOne is
+// scheduled as interactive work and EvaluateMany and EvaluateWithIDs as bulk
+// work, so single items are not stuck behind large runs on other engines
+// sharing the pool. The engine's concurrency still limits how many of its
+// own items are queued at once.
+func WithPool(pool *Pool) EngineOption {
+	return func(e *Engine) {
+		e.pool = pool
+	}
+}
+
+// WithCoalescer shares identical judge requests across the engine's metrics
+// through c, so a prompt that several metrics send for the same item reaches
+// the provider once. Use a new Coalescer per run, or call Reset between runs,
+// since it keeps successful responses.
+func WithCoalescer(c *Coalescer) EngineOption {
+	return func(e *Engine) {
+		e.coalescer = c
+	}
+}
+
+// WithBudget caps the judge tokens the engine spends with b. Once b is
+// exhausted, judge metrics are recorded as not scored instead of failing, so
+// a run that hits its budget still reports the scores it has.
+func WithBudget(b *Budget) EngineOption {
+	return func(e *Engine) {
+		e.budget = b
+	}
+}
+
+// WithMetricTimeout limits each metric's score of an item to d. The metric's
+// context is cancelled after d, and if it has not returned by then, its
+// result is a failure wrapping ErrMetricTimeout, so a hanging judge call
+// does not stall the run. A metric that ignores its context keeps running
+// in the background until it returns.
+func WithMetricTimeout(d time.Duration) EngineOption {
+	return func(e *Engine) {
+		if d <= 0 {
+			e.problems.Addf("metric timeout must be positive: %v", d)
+			return
+		}
+		e.metricTimeout = d
+	}
+}
+
+// WithMetricRetries runs a metric that fails on an item up to n more times,
+// for transient provider errors and timeouts, before recording the
+// failure. Metrics that are not scored, or fail because the budget is
+// exhausted or the run is cancelled, are not retried. A result that took
+// more than one attempt records the attempts in its metadata under
+// MetadataMetricAttempts. Attempts wait with exponential backoff, as set
+// with WithMetricRetryBackoff, and a run cancelled during a wait records
+// the last failure.
+func WithMetricRetries(n int) EngineOption {
+	return func(e *Engine) {
+		if n < 0 {
+			e.problems.Addf("metric retries must not be negative: %d", n)
+			return
+		}
+		e.metricRetries = n
+	}
+}
+
+// WithMetricRetryBackoff sets the wait before the first metric retry, which
+// doubles for each further retry up to maxWait, so retries give a
+// throttled or recovering provider time. It defaults to
+// DefaultMetricRetryBackoff and DefaultMaxMetricRetryWait.
+func WithMetricRetryBackoff(initial, maxWait time.Duration) EngineOption {
+	return func(e *Engine) {
+		if initial <= 0 {
+			e.problems.Addf("metric retry backoff must be positive: %v", initial)
+			return
+		}
+		if maxWait < initial {
+			e.problems.Addf("metric retry max wait %v is below the backoff %v", maxWait, initial)
+			return
+		}
+		e.retryBackoff = initial
+		e.maxRetryWait = maxWait
+	}
+}
+
+// WithFailFast stops the evaluation at the first metric failure, after its
+// retries. The item is not scored by its remaining metrics and its Error
+// names the failed metric; the items of the run that have not finished are
+// cancelled with a cause wrapping ErrFailFast.
+func WithFailFast(failFast bool) EngineOption {
+	return func(e *Engine) {
+		e.failFast = failFast
+	}
+}
+
 // NewEngine creates a new evaluation engine.
 func NewEngine(metrics []Metric, opts ...EngineOption) *Engine {
 	e := &Engine{
-		metrics:     metrics,
-		concurrency: 1,
+		metrics:      metrics,
+		concurrency:  1,
+		retryBackoff: DefaultMetricRetryBackoff,
+		maxRetryWait: DefaultMaxMetricRetryWait,
 	}
 	for _, opt := range opts {
 		opt(e)
@@ -130,55 +312,183 @@ func NewEngine(metrics []Metric, opts ...EngineOption) *Engine {
 
 // EvaluateOne evaluates a single input against all metrics.
 func (e *Engine) EvaluateOne(ctx context.Context, input MetricInput) *EvaluationResult {
+	var group uint64
+	if e.pool != nil {
+		group = e.pool.newGroup()
+	}
+	return e.evaluate(ctx, input, PriorityInteractive, group)
+}
+
+// evaluate evaluates an input, through the engine's pool if it has one.
+func (e *Engine) evaluate(ctx context.Context, input MetricInput, priority Priority, group uint64) *EvaluationResult {
+	if e.pool == nil {
+		return e.evaluateOne(ctx, input)
+	}
+	var result *EvaluationResult
+	if err := e.pool.run(ctx, priority, group, func() {
+		result = e.evaluateOne(ctx, input)
+	}); err != nil {
+		return &EvaluationResult{Input: input, Error: canceledError(ctx)}
+	}
+	return result
+}
+
+func (e *Engine) evaluateOne(ctx context.Context, input MetricInput) *EvaluationResult {
 	result := &EvaluationResult{
 		Input:  input,
 		Scores: make(ScoreResults, 0, len(e.metrics)),
 	}
+	input = input.Preprocess(e.preprocessors...)
+	if e.coalescer != nil {
+		ctx = ContextWithCoalescer(ctx, e.coalescer)
+	}
+	if e.budget != nil {
+		ctx = ContextWithBudget(ctx, e.budget)
+	}
 
 	for _, metric := range e.metrics {
-		select {
-		case <-ctx.Done():
-			result.Error = ctx.Err()
+		if ctx.Err() != nil {
+			result.Error = canceledError(ctx)
+			return result
+		}
+		score := e.score(ctx, metric, input)
+		result.Scores = append(result.Scores, score)
+		if e.failFast && score != nil && score.status() == ScoreStatusFailed {
+			result.Error = fmt.Errorf("metric %q failed: %w", metric.Name(), score.Error)
 			return result
-		default:
-			score := metric.Score(ctx, input)
-			result.Scores = append(result.Scores, score)
 		}
 	}
 
 	return result
 }
 
+// score scores input with metric, retrying failures with backoff as set
+// with WithMetricRetries.
+func (e *Engine) score(ctx context.Context, metric Metric, input MetricInput) *ScoreResult {
+	for attempt := 1; ; attempt++ {
+		score := e.scoreOnce(ctx, metric, input)
+		if score != nil && errors.Is(score.Error, ErrBudgetExhausted) {
+			return NewNotScoredResult(metric.Name(), ErrBudgetExhausted.Error())
+		}
+		if attempt > e.metricRetries || !retryable(ctx, score) ||
+			backoff.Sleep(ctx, backoff.Exponential(e.retryBackoff, e.maxRetryWait, 0.2, attempt-1)) != nil {
+			if attempt > 1 && score != nil {
+				if score.Metadata == nil {
+					score.Metadata = make(map[string]any)
+				}
+				score.Metadata[MetadataMetricAttempts] = attempt
+			}
+			return score
+		}
+	}
+}
+
+// retryable reports whether a metric's score is a failure worth retrying.
+func retryable(ctx context.Context, score *ScoreResult) bool {
+	return score != nil && score.status() == ScoreStatusFailed && ctx.Err() == nil && !errors.Is(score.Error, ErrDryRun)
+}
+
+// scoreOnce scores input with metric, within the timeout set with
+// WithMetricTimeout.
+func (e *Engine) scoreOnce(ctx context.Context, metric Metric, input MetricInput) *ScoreResult {
+	if e.metricTimeout <= 0 {
+		return metric.Score(ctx, input)
+	}
+
+	mctx, cancel := context.WithTimeout(ctx, e.metricTimeout)
+	defer cancel()
+	done := make(chan *ScoreResult, 1)
+	go func() {
+		done <- metric.Score(mctx, input)
+	}()
+
+	select {
+	case score := <-done:
+		if score != nil && score.status() == ScoreStatusFailed && ctx.Err() == nil && errors.Is(mctx.Err(), context.DeadlineExceeded) {
+			return NewFailedScoreResult(metric.Name(), fmt.Errorf("%w after %v: %w", ErrMetricTimeout, e.metricTimeout, score.Error))
+		}
+		return score
+	case <-mctx.Done():
+		if ctx.Err() != nil {
+			return NewFailedScoreResult(metric.Name(), canceledError(ctx))
+		}
+		return NewFailedScoreResult(metric.Name(), fmt.Errorf("%w after %v", ErrMetricTimeout, e.metricTimeout))
+	}
+}
+
+// canceledError wraps the context's cause, so errors.Is(err, context.Canceled)
+// or context.DeadlineExceeded holds for results of cancelled evaluations,
+// and errors.Is(err, ErrFailFast) for items cancelled by WithFailFast.
+func canceledError(ctx context.Context) error {
+	return fmt.Errorf("evaluation canceled: %w", context.Cause(ctx))
+}
+
+// runContext returns the context of a run of several items, which is
+// cancelled with a cause wrapping ErrFailFast when an item fails in an
+// engine created with WithFailFast, and the function that reports the
+// results.
+func (e *Engine) runContext(ctx context.Context) (context.Context, func(*EvaluationResult), context.CancelFunc) {
+	if !e.failFast {
+		return ctx, func(*EvaluationResult) {}, func() {}
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	failed := func(result *EvaluationResult) {
+		if result.Error != nil && ctx.Err() == nil {
+			cancel(fmt.Errorf("%w: %s: %w", ErrFailFast, result.ItemID, result.Error))
+		}
+	}
+	return ctx, failed, func() { cancel(nil) }
+}
+
 // EvaluateMany evaluates multiple inputs against all metrics.
+// If ctx is cancelled, evaluation stops promptly and the results of items
+// that were not evaluated have an Error wrapping ctx.Err().
 func (e *Engine) EvaluateMany(ctx context.Context, inputs []MetricInput) EvaluationResults {
 	results := make(EvaluationResults, len(inputs))
+	var group uint64
+	if e.pool != nil {
+		group = e.pool.newGroup()
+	}
+	ctx, report, cancel := e.runContext(ctx)
+	defer cancel()
 
 	if e.concurrency <= 1 {
 		// Sequential evaluation
 		for i, input := range inputs {
-			results[i] = e.EvaluateOne(ctx, input)
+			results[i] = e.evaluate(ctx, input, PriorityBulk, group)
 			results[i].ItemID = fmt.Sprintf("item-%d", i)
+			report(results[i])
 			e.notifyCallbacks(i+1, len(inputs), results[i])
 		}
 		return results
 	}
 
-	// Concurrent evaluation
+	// Concurrent evaluation. Workers are started only once a slot is free,
+	// so cancellation stops new work without leaving goroutines behind.
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	sem := make(chan struct{}, e.concurrency)
 	completed := 0
 
 	for i, input := range inputs {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			for j := i; j < len(inputs); j++ {
+				results[j] = &EvaluationResult{ItemID: fmt.Sprintf("item-%d", j), Input: inputs[j], Error: canceledError(ctx)}
+			}
+			return results
+		case sem <- struct{}{}:
+		}
+
 		wg.Add(1)
 		go func(idx int, inp MetricInput) {
 			defer wg.Done()
-
-			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			result := e.EvaluateOne(ctx, inp)
+			result := e.evaluate(ctx, inp, PriorityBulk, group)
 			result.ItemID = fmt.Sprintf("item-%d", idx)
+			report(result)
 
 			mu.Lock()
 			results[idx] = result
@@ -193,14 +503,22 @@ func (e *Engine) EvaluateMany(ctx context.Context, inputs []MetricInput) Evaluat
 }
 
 // EvaluateWithIDs evaluates inputs with explicit IDs.
+// Cancellation behaves as for EvaluateMany.
 func (e *Engine) EvaluateWithIDs(ctx context.Context, items map[string]MetricInput) EvaluationResults {
 	results := make(EvaluationResults, 0, len(items))
+	var group uint64
+	if e.pool != nil {
+		group = e.pool.newGroup()
+	}
+	ctx, report, cancel := e.runContext(ctx)
+	defer cancel()
 
 	if e.concurrency <= 1 {
 		i := 0
 		for id, input := range items {
-			result := e.EvaluateOne(ctx, input)
+			result := e.evaluate(ctx, input, PriorityBulk, group)
 			result.ItemID = id
+			report(result)
 			results = append(results, result)
 			i++
 			e.notifyCallbacks(i, len(items), result)
@@ -215,15 +533,31 @@ func (e *Engine) EvaluateWithIDs(ctx context.Context, items map[string]MetricInp
 	completed := 0
 
 	for id, input := range items {
+		select {
+		case <-ctx.Done():
+			// Items not yet started are reported as cancelled.
+			wg.Wait()
+			started := make(map[string]bool, len(results))
+			for _, r := range results {
+				started[r.ItemID] = true
+			}
+			for itemID, inp := range items {
+				if !started[itemID] {
+					results = append(results, &EvaluationResult{ItemID: itemID, Input: inp, Error: canceledError(ctx)})
+				}
+			}
+			return results
+		case sem <- struct{}{}:
+		}
+
 		wg.Add(1)
 		go func(itemID string, inp MetricInput) {
 			defer wg.Done()
-
-			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			result := e.EvaluateOne(ctx, inp)
+			result := e.evaluate(ctx, inp, PriorityBulk, group)
 			result.ItemID = itemID
+			report(result)
 
 			mu.Lock()
 			results = append(results, result)
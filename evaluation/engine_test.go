@@ -3,8 +3,13 @@ package evaluation
 import (
 	"context"
 	"errors"
+	"math"
+	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/plexusone/opik-go/testutil"
 )
 
 func TestEvaluationResult(t *testing.T) {
@@ -128,6 +133,59 @@ func TestEvaluationResultsSummary(t *testing.T) {
 	}
 }
 
+func TestEvaluationResultsNotScored(t *testing.T) {
+	results := EvaluationResults{
+		&EvaluationResult{Scores: ScoreResults{NewScoreResult("bleu", 0.6), NewNotScoredResult("recall", "no context")}},
+		&EvaluationResult{Scores: ScoreResults{NewNotScoredResult("bleu", "no expected output"), NewNotScoredResult("recall", "no context")}},
+		&EvaluationResult{Scores: ScoreResults{NewScoreResult("bleu", 0.8), NewNotScoredResult("recall", "no context")}},
+	}
+
+	if got := results.AverageByMetric("bleu"); math.Abs(got-0.7) > 1e-9 {
+		t.Errorf("AverageByMetric(bleu) = %v, want 0.7", got)
+	}
+	summary := results.Summary()
+	if _, ok := summary["recall"]; ok {
+		t.Errorf("summary = %v, should leave out a metric that was skipped for every item", summary)
+	}
+	if math.Abs(summary["bleu"]-0.7) > 1e-9 {
+		t.Errorf("summary[bleu] = %v, want 0.7", summary["bleu"])
+	}
+
+	counts := results.CountByStatus("recall")
+	if counts[ScoreStatusNotScored] != 3 || counts[ScoreStatusScored] != 0 {
+		t.Errorf("CountByStatus(recall) = %v", counts)
+	}
+}
+
+func TestEvaluationResultsSummarySubScores(t *testing.T) {
+	results := EvaluationResults{
+		&EvaluationResult{Scores: ScoreResults{
+			NewScoreResult("rouge_l", 0.5).WithSubScore("precision", 0.4).WithSubScore("recall", 0.6),
+		}},
+		&EvaluationResult{Scores: ScoreResults{
+			NewScoreResult("rouge_l", 0.7).WithSubScore("precision", 0.8),
+		}},
+		&EvaluationResult{Scores: ScoreResults{
+			NewFailedScoreResult("rouge_l", errors.New("failed")).WithSubScore("precision", 0),
+		}},
+	}
+
+	summary := results.Summary()
+	if len(summary) != 3 {
+		t.Errorf("summary = %v, want rouge_l and two sub-scores", summary)
+	}
+	if math.Abs(summary["rouge_l.precision"]-0.6) > 1e-9 {
+		t.Errorf("summary[rouge_l.precision] = %v, want 0.6", summary["rouge_l.precision"])
+	}
+	// Only the first item reports recall.
+	if summary["rouge_l.recall"] != 0.6 {
+		t.Errorf("summary[rouge_l.recall] = %v, want 0.6", summary["rouge_l.recall"])
+	}
+	if results.AverageBySubScore("rouge_l", "f1") != 0 {
+		t.Error("AverageBySubScore for a missing sub-score should be 0")
+	}
+}
+
 func TestEngineOptions(t *testing.T) {
 	t.Run("WithConcurrency", func(t *testing.T) {
 		engine := NewEngine(nil, WithConcurrency(4))
@@ -455,3 +513,170 @@ func TestEvaluateSingleConvenienceFunction(t *testing.T) {
 		t.Errorf("Score = %v, want 0.9", result.Scores[0].Value)
 	}
 }
+
+func TestEngineCancellation(t *testing.T) {
+	testutil.WithGoroutineLeakCheck(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var scored atomic.Int32
+	blocking := NewMetricFunc("blocking", func(ctx context.Context, _ MetricInput) *ScoreResult {
+		if scored.Add(1) == 2 {
+			cancel()
+		}
+		<-ctx.Done()
+		return NewFailedScoreResult("blocking", ctx.Err())
+	})
+
+	inputs := make([]MetricInput, 20)
+	for i := range inputs {
+		inputs[i] = NewMetricInput("q", "a")
+	}
+	results := NewEngine([]Metric{blocking}, WithConcurrency(2)).EvaluateMany(ctx, inputs)
+
+	if n := scored.Load(); n > 2 {
+		t.Errorf("metric ran %d times after cancellation, want at most 2", n)
+	}
+	var canceled int
+	for i, r := range results {
+		if r == nil {
+			t.Fatalf("result %d is nil", i)
+		}
+		if errors.Is(r.Error, context.Canceled) {
+			canceled++
+		}
+	}
+	if canceled != 18 {
+		t.Errorf("canceled results = %d, want 18", canceled)
+	}
+
+	withIDs := NewEngine([]Metric{blocking}, WithConcurrency(2)).EvaluateWithIDs(ctx, map[string]MetricInput{"a": inputs[0], "b": inputs[1]})
+	if len(withIDs) != 2 || !errors.Is(withIDs[0].Error, context.Canceled) || !errors.Is(withIDs[1].Error, context.Canceled) {
+		t.Errorf("EvaluateWithIDs after cancel = %+v", withIDs)
+	}
+}
+
+func TestEngineMetricTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	// hanging ignores its context, as a stuck judge call might.
+	hanging := NewMetricFunc("hanging", func(ctx context.Context, _ MetricInput) *ScoreResult {
+		<-release
+		return NewScoreResult("hanging", 1)
+	})
+	// observant fails with its context's error once it is cancelled.
+	observant := NewMetricFunc("observant", func(ctx context.Context, _ MetricInput) *ScoreResult {
+		<-ctx.Done()
+		return NewFailedScoreResult("observant", ctx.Err())
+	})
+	fast := NewMetricFunc("fast", func(ctx context.Context, _ MetricInput) *ScoreResult {
+		return NewScoreResult("fast", 1)
+	})
+
+	engine := NewEngine([]Metric{hanging, observant, fast}, WithMetricTimeout(10*time.Millisecond))
+	result := engine.EvaluateOne(context.Background(), NewMetricInput("q", "a"))
+	if result.Error != nil {
+		t.Fatalf("Error = %v", result.Error)
+	}
+	for _, name := range []string{"hanging", "observant"} {
+		if err := result.Scores.ByName(name).Error; !errors.Is(err, ErrMetricTimeout) {
+			t.Errorf("%s error = %v, want ErrMetricTimeout", name, err)
+		}
+	}
+	if score := result.Scores.ByName("fast"); !score.IsSuccess() {
+		t.Errorf("fast = %+v, want scored", score)
+	}
+}
+
+func TestEngineMetricRetries(t *testing.T) {
+	var calls atomic.Int32
+	flaky := NewMetricFunc("flaky", func(ctx context.Context, _ MetricInput) *ScoreResult {
+		if calls.Add(1) < 3 {
+			return NewFailedScoreResult("flaky", errors.New("503 service unavailable"))
+		}
+		return NewScoreResult("flaky", 0.9)
+	})
+	var skips atomic.Int32
+	skipped := NewMetricFunc("skipped", func(ctx context.Context, _ MetricInput) *ScoreResult {
+		skips.Add(1)
+		return NewNotScoredResult("skipped", "no context")
+	})
+
+	fastRetries := WithMetricRetryBackoff(time.Millisecond, time.Millisecond)
+	result := NewEngine([]Metric{flaky, skipped}, WithMetricRetries(2), fastRetries).EvaluateOne(context.Background(), NewMetricInput("q", "a"))
+	score := result.Scores.ByName("flaky")
+	if !score.IsSuccess() || score.Value != 0.9 {
+		t.Fatalf("flaky = %+v, want scored after retries", score)
+	}
+	if score.Metadata[MetadataMetricAttempts] != 3 {
+		t.Errorf("attempts = %v, want 3", score.Metadata[MetadataMetricAttempts])
+	}
+	if skips.Load() != 1 {
+		t.Errorf("not-scored metric ran %d times, want 1", skips.Load())
+	}
+
+	calls.Store(0)
+	result = NewEngine([]Metric{flaky}, WithMetricRetries(1), fastRetries).EvaluateOne(context.Background(), NewMetricInput("q", "a"))
+	if score := result.Scores.ByName("flaky"); score.IsSuccess() || score.Metadata[MetadataMetricAttempts] != 2 {
+		t.Errorf("flaky = %+v, want failed after 2 attempts", score)
+	}
+
+	// The wait between attempts stops with the run.
+	calls.Store(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	slow := NewEngine([]Metric{flaky}, WithMetricRetries(2), WithMetricRetryBackoff(time.Hour, time.Hour))
+	if score := slow.EvaluateOne(ctx, NewMetricInput("q", "a")).Scores.ByName("flaky"); score.IsSuccess() || calls.Load() != 1 {
+		t.Errorf("flaky = %+v after %d calls, want the first failure", score, calls.Load())
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("cancelled retry waited %v", time.Since(start))
+	}
+}
+
+func TestEngineFailFast(t *testing.T) {
+	var scored atomic.Int32
+	metric := NewMetricFunc("judge", func(ctx context.Context, input MetricInput) *ScoreResult {
+		scored.Add(1)
+		if input.Output == "bad" {
+			return NewFailedScoreResult("judge", errors.New("provider down"))
+		}
+		return NewScoreResult("judge", 1)
+	})
+	after := NewMetricFunc("after", func(ctx context.Context, _ MetricInput) *ScoreResult {
+		return NewScoreResult("after", 1)
+	})
+	inputs := []MetricInput{NewMetricInput("q", "good"), NewMetricInput("q", "bad"), NewMetricInput("q", "good")}
+
+	results := NewEngine([]Metric{metric, after}, WithFailFast(true)).EvaluateMany(context.Background(), inputs)
+	if !results[0].IsSuccess() || len(results[0].Scores) != 2 {
+		t.Errorf("item-0 = %+v, want scored", results[0])
+	}
+	if err := results[1].Error; err == nil || !strings.Contains(err.Error(), `metric "judge" failed: provider down`) {
+		t.Errorf("item-1 error = %v", err)
+	}
+	if len(results[1].Scores) != 1 {
+		t.Errorf("item-1 scored by %d metrics, want 1", len(results[1].Scores))
+	}
+	if err := results[2].Error; !errors.Is(err, ErrFailFast) || !strings.Contains(err.Error(), "item-1") {
+		t.Errorf("item-2 error = %v, want ErrFailFast", err)
+	}
+	if scored.Load() != 2 {
+		t.Errorf("judge ran %d times, want 2", scored.Load())
+	}
+
+	results = NewEngine([]Metric{metric}).EvaluateMany(context.Background(), inputs)
+	if len(results.Failed()) != 0 || scored.Load() != 5 {
+		t.Errorf("without fail fast: %d failed items after %d scores", len(results.Failed()), scored.Load())
+	}
+}
+
+func TestEngineRunOptionsValidate(t *testing.T) {
+	metric := NewMetricFunc("m", nil)
+	engine := NewEngine([]Metric{metric}, WithMetricTimeout(0), WithMetricRetries(-1),
+		WithMetricRetryBackoff(0, time.Second), WithMetricRetryBackoff(time.Second, time.Millisecond))
+	var verr *ValidationError
+	if err := engine.Validate(); !errors.As(err, &verr) || len(verr.Problems) != 4 {
+		t.Errorf("Validate() = %v, want 4 problems", err)
+	}
+}
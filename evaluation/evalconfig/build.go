@@ -0,0 +1,198 @@
+package evalconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/llm"
+)
+
+// BuildOption configures how a suite is turned into metrics and an engine.
+type BuildOption func(*buildConfig)
+
+type buildConfig struct {
+	provider   llm.Provider
+	engineOpts []evaluation.EngineOption
+}
+
+// WithJudgeProvider sets the provider used by LLM judge metrics.
+// The suite's judge model, temperature, and language are applied on top
+// of it.
+func WithJudgeProvider(provider llm.Provider) BuildOption {
+	return func(c *buildConfig) {
+		c.provider = provider
+	}
+}
+
+// WithEngineOptions adds options to the engine built by BuildEngine.
+func WithEngineOptions(opts ...evaluation.EngineOption) BuildOption {
+	return func(c *buildConfig) {
+		c.engineOpts = append(c.engineOpts, opts...)
+	}
+}
+
+// BuildMetrics creates the suite's metrics. If any cannot be created, an
+// *evaluation.ValidationError lists them all.
+func (s *Suite) BuildMetrics(opts ...BuildOption) ([]evaluation.Metric, error) {
+	cfg := &buildConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var judgeOpts []llm.JudgeOption
+	if s.Judge.Model != "" {
+		judgeOpts = append(judgeOpts, llm.WithJudgeModel(s.Judge.Model))
+	}
+	if s.Judge.Temperature != 0 {
+		judgeOpts = append(judgeOpts, llm.WithJudgeTemperature(s.Judge.Temperature))
+	}
+	if s.Judge.Language != "" {
+		judgeOpts = append(judgeOpts, llm.WithJudgeLanguage(s.Judge.Language))
+	}
+	if s.Judge.Rationale {
+		judgeOpts = append(judgeOpts, llm.WithJudgeRationale())
+	}
+
+	metrics := make([]evaluation.Metric, 0, len(s.Metrics))
+	var problems evaluation.Problems
+	for i, mc := range s.Metrics {
+		if _, ok := evaluation.LookupMetric(mc.Name); !ok {
+			problems.Addf("metrics[%d]: unknown metric %q", i, mc.Name)
+			continue
+		}
+		params := evaluation.MetricParams(mc.Params)
+		if cfg.provider != nil {
+			params = llm.WithJudgeParams(params, cfg.provider, judgeOpts...)
+		}
+		metric, err := evaluation.NewMetric(mc.Name, params)
+		if err != nil {
+			problems.Add(fmt.Sprintf("metrics[%d] %s", i, mc.Name), err)
+			continue
+		}
+		metrics = append(metrics, metric)
+	}
+	if err := problems.Err(); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// BuildEngine creates an evaluation engine for the suite's metrics and
+// concurrency. The engine is checked with Engine.Validate, so an
+// *evaluation.ValidationError lists every problem in the suite's metrics.
+func (s *Suite) BuildEngine(opts ...BuildOption) (*evaluation.Engine, error) {
+	metrics, err := s.BuildMetrics(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &buildConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	engineOpts := []evaluation.EngineOption{}
+	if s.Concurrency > 0 {
+		engineOpts = append(engineOpts, evaluation.WithConcurrency(s.Concurrency))
+	}
+	engineOpts = append(engineOpts, cfg.engineOpts...)
+
+	engine := evaluation.NewEngine(metrics, engineOpts...)
+	if err := engine.Validate(); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// InputMapper returns a mapper from dataset items to metric inputs using the suite's mapping.
+func (s *Suite) InputMapper() func(item map[string]any) evaluation.MetricInput {
+	base := evaluation.DefaultInputMapper(s.Mapping.InputKey(), s.Mapping.OutputKey(), s.Mapping.ExpectedKey())
+	contextKey := s.Mapping.ContextKey()
+	return func(item map[string]any) evaluation.MetricInput {
+		input := base(item)
+		switch v := item[contextKey].(type) {
+		case string:
+			input.Context = v
+		case []any:
+			for _, chunk := range v {
+				if s, ok := chunk.(string); ok {
+					input.ContextList = append(input.ContextList, s)
+				}
+			}
+		}
+		return input
+	}
+}
+
+// ThresholdFailure describes a metric whose average score fell below its threshold.
+type ThresholdFailure struct {
+	Metric    string  `json:"metric"`
+	Average   float64 `json:"average"`
+	Threshold float64 `json:"threshold"`
+}
+
+// String returns a human-readable description of the failure.
+func (f ThresholdFailure) String() string {
+	return fmt.Sprintf("%s: average %.3f below threshold %.3f", f.Metric, f.Average, f.Threshold)
+}
+
+// ThresholdError is returned by CheckThresholds when any metric misses its threshold.
+type ThresholdError struct {
+	Failures []ThresholdFailure
+}
+
+// Error implements the error interface.
+func (e *ThresholdError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		parts[i] = f.String()
+	}
+	return "thresholds not met: " + strings.Join(parts, "; ")
+}
+
+// Thresholds returns the threshold of each metric that has one, by the
+// name the built metric reports, falling back to the suite-wide threshold.
+// metrics must be the slice returned by BuildMetrics (or Engine.Metrics).
+func (s *Suite) Thresholds(metrics []evaluation.Metric) map[string]float64 {
+	thresholds := make(map[string]float64)
+	for i, metric := range metrics {
+		if i >= len(s.Metrics) {
+			break
+		}
+		threshold := s.Metrics[i].Threshold
+		if threshold == nil {
+			threshold = s.Threshold
+		}
+		if threshold != nil {
+			thresholds[metric.Name()] = *threshold
+		}
+	}
+	return thresholds
+}
+
+// CheckThresholds compares the average score of each metric against its threshold,
+// falling back to the suite-wide threshold. It returns a *ThresholdError if any
+// metric falls short.
+//
+// Results are matched to metrics by the name each built metric reports, so
+// metrics must be the slice returned by BuildMetrics (or Engine.Metrics).
+func (s *Suite) CheckThresholds(metrics []evaluation.Metric, results evaluation.EvaluationResults) error {
+	var failures []ThresholdFailure
+	for name, threshold := range s.Thresholds(metrics) {
+		avg := results.AverageByMetric(name)
+		if avg < threshold {
+			failures = append(failures, ThresholdFailure{
+				Metric:    name,
+				Average:   avg,
+				Threshold: threshold,
+			})
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Metric < failures[j].Metric })
+	return &ThresholdError{Failures: failures}
+}
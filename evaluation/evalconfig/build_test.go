@@ -0,0 +1,213 @@
+package evalconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/llm"
+)
+
+func TestBuildEngine(t *testing.T) {
+	suite, err := Parse([]byte(testSuiteYAML), FormatYAML)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	engine, err := suite.BuildEngine()
+	if err != nil {
+		t.Fatalf("BuildEngine error: %v", err)
+	}
+
+	metrics := engine.Metrics()
+	if len(metrics) != 2 {
+		t.Fatalf("len(metrics) = %d, want 2", len(metrics))
+	}
+	if metrics[0].Name() != "equals" || metrics[1].Name() != "word_count" {
+		t.Errorf("metric names = %q, %q", metrics[0].Name(), metrics[1].Name())
+	}
+
+	items := []map[string]any{
+		{"question": "q1", "answer": "Paris", "expected": "Paris"},
+		{"question": "q2", "answer": "paris", "expected": "Paris"},
+	}
+	results := evaluation.NewDatasetEvaluator(engine, suite.InputMapper()).Evaluate(context.Background(), items)
+
+	if avg := results.AverageByMetric("equals"); avg != 0.5 {
+		t.Errorf("equals average = %v, want 0.5", avg)
+	}
+	if err := suite.CheckThresholds(metrics, results); err != nil {
+		t.Errorf("CheckThresholds error: %v", err)
+	}
+}
+
+func TestBuildMetricsErrors(t *testing.T) {
+	t.Run("unknown metric", func(t *testing.T) {
+		suite := &Suite{Metrics: []MetricConfig{{Name: "nope"}}}
+		if _, err := suite.BuildMetrics(); err == nil {
+			t.Error("expected error for unknown metric")
+		}
+	})
+
+	t.Run("judge without provider", func(t *testing.T) {
+		suite := &Suite{Metrics: []MetricConfig{{Name: "hallucination"}}}
+		_, err := suite.BuildMetrics()
+		if !errors.Is(err, llm.ErrNoJudgeProvider) {
+			t.Errorf("err = %v, want ErrNoJudgeProvider", err)
+		}
+	})
+
+	t.Run("bad param type", func(t *testing.T) {
+		suite := &Suite{Metrics: []MetricConfig{{Name: "equals", Params: map[string]any{"case_sensitive": "yes"}}}}
+		if _, err := suite.BuildMetrics(); err == nil {
+			t.Error("expected error for bad param type")
+		}
+	})
+
+	t.Run("g_eval requires criteria", func(t *testing.T) {
+		suite := &Suite{Metrics: []MetricConfig{{Name: "g_eval"}}}
+		if _, err := suite.BuildMetrics(WithJudgeProvider(llm.NewMockProvider(nil, "5"))); err == nil {
+			t.Error("expected error for missing criteria")
+		}
+	})
+}
+
+func TestBuildEngineValidation(t *testing.T) {
+	suite := &Suite{Metrics: []MetricConfig{
+		{Name: "nope"},
+		{Name: "word_count", Params: map[string]any{"min": 10, "max": 5}},
+		{Name: "equals"},
+		{Name: "equals"},
+	}}
+	_, err := suite.BuildEngine()
+	var verr *evaluation.ValidationError
+	if !errors.As(err, &verr) || len(verr.Problems) != 1 {
+		t.Fatalf("BuildEngine error = %v, want the unknown metric only", err)
+	}
+
+	suite.Metrics = suite.Metrics[1:]
+	_, err = suite.BuildEngine()
+	if !errors.As(err, &verr) || len(verr.Problems) != 2 {
+		t.Fatalf("BuildEngine error = %v, want 2 problems", err)
+	}
+	want := []string{
+		`metric "word_count": max 5 is below min 10`,
+		`metrics[1] and metrics[2] are both named "equals"`,
+	}
+	for i, w := range want {
+		if got := verr.Problems[i].Error(); got != w {
+			t.Errorf("problem %d = %q, want %q", i, got, w)
+		}
+	}
+}
+
+func TestBuildJudgeMetrics(t *testing.T) {
+	suite := &Suite{
+		Judge: Judge{Model: "judge-model", Language: "de", Rationale: true},
+		Metrics: []MetricConfig{
+			{Name: "hallucination"},
+			{Name: "custom_judge", Params: map[string]any{"name": "tone", "prompt": "Rate {{output}}"}},
+		},
+	}
+
+	metrics, err := suite.BuildMetrics(WithJudgeProvider(llm.NewMockProvider(nil, `{"score": 1}`)))
+	if err != nil {
+		t.Fatalf("BuildMetrics error: %v", err)
+	}
+
+	judge, ok := metrics[0].(*llm.Hallucination)
+	if !ok {
+		t.Fatalf("metrics[0] = %T, want *llm.Hallucination", metrics[0])
+	}
+	if judge.Model() != "judge-model" {
+		t.Errorf("judge model = %q, want judge-model", judge.Model())
+	}
+	if judge.Language() != "de" {
+		t.Errorf("judge language = %q, want de", judge.Language())
+	}
+	if !judge.Rationale() {
+		t.Error("judge rationale not set")
+	}
+	if metrics[1].Name() != "tone" {
+		t.Errorf("custom judge name = %q, want tone", metrics[1].Name())
+	}
+}
+
+func TestCheckThresholds(t *testing.T) {
+	high := 0.9
+	suite := &Suite{
+		Metrics: []MetricConfig{
+			{Name: "equals", Threshold: &high},
+			{Name: "not_empty"},
+		},
+	}
+	metrics, err := suite.BuildMetrics()
+	if err != nil {
+		t.Fatalf("BuildMetrics error: %v", err)
+	}
+
+	results := evaluation.Evaluate(context.Background(), metrics, []evaluation.MetricInput{
+		evaluation.NewMetricInput("q", "a").WithExpected("b"),
+	})
+
+	err = suite.CheckThresholds(metrics, results)
+	var thresholdErr *ThresholdError
+	if !errors.As(err, &thresholdErr) {
+		t.Fatalf("err = %v, want *ThresholdError", err)
+	}
+	if len(thresholdErr.Failures) != 1 || thresholdErr.Failures[0].Metric != "equals" {
+		t.Errorf("failures = %v", thresholdErr.Failures)
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	high, low := 0.9, 0.5
+	suite := &Suite{
+		Threshold: &low,
+		Metrics: []MetricConfig{
+			{Name: "equals", Threshold: &high},
+			{Name: "not_empty"},
+		},
+	}
+	metrics, err := suite.BuildMetrics()
+	if err != nil {
+		t.Fatalf("BuildMetrics error: %v", err)
+	}
+	got := suite.Thresholds(metrics)
+	if len(got) != 2 || got["equals"] != 0.9 || got[metrics[1].Name()] != 0.5 {
+		t.Errorf("Thresholds = %v", got)
+	}
+}
+
+func TestMetricNames(t *testing.T) {
+	names := MetricNames()
+	for _, want := range []string{"equals", "hallucination", "rouge"} {
+		found := false
+		for _, name := range names {
+			found = found || name == want
+		}
+		if !found {
+			t.Errorf("MetricNames() missing %q", want)
+		}
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] > names[i] {
+			t.Fatal("MetricNames() should be sorted")
+		}
+	}
+}
+
+func TestInputMapperContextList(t *testing.T) {
+	suite := &Suite{Mapping: Mapping{Context: "passages"}}
+	mapper := suite.InputMapper()
+
+	input := mapper(map[string]any{"input": "q", "passages": []any{"first", "second"}})
+	if len(input.ContextList) != 2 || input.ContextList[1] != "second" || input.Context != "" {
+		t.Errorf("ContextList = %v, Context = %q", input.ContextList, input.Context)
+	}
+	input = mapper(map[string]any{"input": "q", "passages": "one passage"})
+	if input.Context != "one passage" || input.ContextList != nil {
+		t.Errorf("ContextList = %v, Context = %q", input.ContextList, input.Context)
+	}
+}
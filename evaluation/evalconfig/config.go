@@ -0,0 +1,226 @@
+package evalconfig
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// Format is the encoding of a suite or dataset file.
+type Format string
+
+const (
+	// FormatYAML is the YAML encoding.
+	FormatYAML Format = "yaml"
+	// FormatJSON is the JSON encoding.
+	FormatJSON Format = "json"
+)
+
+// Suite is a declarative evaluation suite.
+type Suite struct {
+	// Name identifies the suite in reports.
+	Name string `yaml:"name" json:"name"`
+
+	// Dataset is the name of an Opik dataset to evaluate.
+	Dataset string `yaml:"dataset,omitempty" json:"dataset,omitempty"`
+
+	// DatasetFile is a local JSON array or JSON Lines file of items to evaluate.
+	// Relative paths are resolved against the suite file's directory.
+	DatasetFile string `yaml:"dataset_file,omitempty" json:"dataset_file,omitempty"`
+
+	// Mapping maps dataset item keys to metric input fields.
+	Mapping Mapping `yaml:"mapping,omitempty" json:"mapping,omitempty"`
+
+	// Concurrency is the number of items evaluated in parallel.
+	Concurrency int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+
+	// Judge configures the LLM used by judge metrics.
+	Judge Judge `yaml:"judge,omitempty" json:"judge,omitempty"`
+
+	// Metrics lists the metrics to run.
+	Metrics []MetricConfig `yaml:"metrics" json:"metrics"`
+
+	// Threshold is the minimum average score every metric must reach,
+	// unless the metric sets its own threshold.
+	Threshold *float64 `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+
+	// baseDir is the directory of the file the suite was loaded from.
+	baseDir string
+}
+
+// Mapping maps dataset item keys to metric input fields.
+type Mapping struct {
+	Input    string `yaml:"input,omitempty" json:"input,omitempty"`
+	Output   string `yaml:"output,omitempty" json:"output,omitempty"`
+	Expected string `yaml:"expected,omitempty" json:"expected,omitempty"`
+	Context  string `yaml:"context,omitempty" json:"context,omitempty"`
+}
+
+// Judge configures the LLM used by judge metrics.
+type Judge struct {
+	// Provider is the provider name (e.g., "openai", "anthropic").
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	// Model is the model to use for judging.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+	// Temperature is the sampling temperature for judging.
+	Temperature float64 `yaml:"temperature,omitempty" json:"temperature,omitempty"`
+	// Language is the BCP 47 tag of the language judges write reasons in.
+	Language string `yaml:"language,omitempty" json:"language,omitempty"`
+	// Rationale asks judges for the steps of their reasoning; see
+	// llm.WithJudgeRationale.
+	Rationale bool `yaml:"rationale,omitempty" json:"rationale,omitempty"`
+}
+
+// MetricConfig configures a single metric.
+type MetricConfig struct {
+	// Name is the metric name (e.g., "equals", "hallucination").
+	Name string `yaml:"name" json:"name"`
+	// Params are metric-specific parameters.
+	Params map[string]any `yaml:"params,omitempty" json:"params,omitempty"`
+	// Threshold is the minimum average score for this metric.
+	Threshold *float64 `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+}
+
+// Load reads a suite from a YAML or JSON file.
+// The format is detected from the file extension, defaulting to YAML.
+func Load(path string) (*Suite, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is provided by the caller
+	if err != nil {
+		return nil, fmt.Errorf("read suite: %w", err)
+	}
+
+	format := FormatYAML
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = FormatJSON
+	}
+
+	suite, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	suite.baseDir = filepath.Dir(path)
+	return suite, nil
+}
+
+// Parse decodes and validates a suite.
+func Parse(data []byte, format Format) (*Suite, error) {
+	var suite Suite
+	var err error
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&suite)
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&suite)
+	default:
+		return nil, fmt.Errorf("unsupported format: %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse suite: %w", err)
+	}
+
+	if err := suite.Validate(); err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
+
+// Validate checks that the suite is well formed. The returned
+// *evaluation.ValidationError lists every problem found.
+func (s *Suite) Validate() error {
+	var p evaluation.Problems
+	if len(s.Metrics) == 0 {
+		p.Addf("suite has no metrics")
+	}
+	if s.Concurrency < 0 {
+		p.Addf("concurrency must not be negative: %d", s.Concurrency)
+	}
+	if s.Dataset != "" && s.DatasetFile != "" {
+		p.Addf("dataset and dataset_file are mutually exclusive")
+	}
+	for i, m := range s.Metrics {
+		if m.Name == "" {
+			p.Addf("metrics[%d]: name is required", i)
+		}
+	}
+	return p.Err()
+}
+
+// InputKey returns the dataset key used for metric input.
+func (m Mapping) InputKey() string { return orDefault(m.Input, "input") }
+
+// OutputKey returns the dataset key used for metric output.
+func (m Mapping) OutputKey() string { return orDefault(m.Output, "output") }
+
+// ExpectedKey returns the dataset key used for the expected output.
+func (m Mapping) ExpectedKey() string { return orDefault(m.Expected, "expected") }
+
+// ContextKey returns the dataset key used for metric context.
+func (m Mapping) ContextKey() string { return orDefault(m.Context, "context") }
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// LoadItems reads the items of the suite's DatasetFile.
+// The file may contain a JSON array of objects or one JSON object per line.
+func (s *Suite) LoadItems() ([]map[string]any, error) {
+	if s.DatasetFile == "" {
+		return nil, errors.New("suite has no dataset_file")
+	}
+
+	path := s.DatasetFile
+	if !filepath.IsAbs(path) && s.baseDir != "" {
+		path = filepath.Join(s.baseDir, path)
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path comes from the suite file
+	if err != nil {
+		return nil, fmt.Errorf("read dataset: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var items []map[string]any
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("parse dataset: %w", err)
+		}
+		return items, nil
+	}
+
+	var items []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var item map[string]any
+		if err := json.Unmarshal(text, &item); err != nil {
+			return nil, fmt.Errorf("parse dataset line %d: %w", line, err)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read dataset: %w", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,165 @@
+package evalconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+const testSuiteYAML = `
+name: qa
+dataset: golden
+mapping:
+  input: question
+  output: answer
+concurrency: 4
+threshold: 0.5
+judge:
+  provider: openai
+  model: gpt-4o-mini
+metrics:
+  - name: equals
+    params:
+      case_sensitive: true
+  - name: word_count
+    params:
+      min: 1
+      max: 10
+    threshold: 0.9
+`
+
+func TestParseYAML(t *testing.T) {
+	suite, err := Parse([]byte(testSuiteYAML), FormatYAML)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	if suite.Name != "qa" || suite.Dataset != "golden" {
+		t.Errorf("Name/Dataset = %q/%q", suite.Name, suite.Dataset)
+	}
+	if suite.Concurrency != 4 {
+		t.Errorf("Concurrency = %d, want 4", suite.Concurrency)
+	}
+	if suite.Judge.Model != "gpt-4o-mini" {
+		t.Errorf("Judge.Model = %q", suite.Judge.Model)
+	}
+	if len(suite.Metrics) != 2 {
+		t.Fatalf("len(Metrics) = %d, want 2", len(suite.Metrics))
+	}
+	if suite.Metrics[1].Threshold == nil || *suite.Metrics[1].Threshold != 0.9 {
+		t.Errorf("Metrics[1].Threshold = %v, want 0.9", suite.Metrics[1].Threshold)
+	}
+	if cs, _ := evaluation.MetricParams(suite.Metrics[0].Params).Bool("case_sensitive", false); !cs {
+		t.Error("case_sensitive param should be true")
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	data := `{"name": "qa", "metrics": [{"name": "not_empty"}], "threshold": 1}`
+	suite, err := Parse([]byte(data), FormatJSON)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if suite.Threshold == nil || *suite.Threshold != 1 {
+		t.Errorf("Threshold = %v, want 1", suite.Threshold)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"no metrics", "name: x\n"},
+		{"unknown field", "name: x\nmetricz: []\n"},
+		{"metric without name", "metrics:\n  - params: {}\n"},
+		{"both datasets", "dataset: a\ndataset_file: b.jsonl\nmetrics:\n  - name: equals\n"},
+		{"negative concurrency", "concurrency: -1\nmetrics:\n  - name: equals\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data), FormatYAML); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if _, err := Parse([]byte("{}"), Format("toml")); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestValidateListsEveryProblem(t *testing.T) {
+	suite := &Suite{Concurrency: -1, Dataset: "a", DatasetFile: "b.jsonl", Metrics: []MetricConfig{{Name: "equals"}, {}}}
+	err := suite.Validate()
+	want := "evaluation: invalid configuration (3 problems):\n" +
+		"  - concurrency must not be negative: -1\n" +
+		"  - dataset and dataset_file are mutually exclusive\n" +
+		"  - metrics[1]: name is required"
+	if err == nil || err.Error() != want {
+		t.Errorf("Validate error = %v, want %q", err, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	suitePath := filepath.Join(dir, "suite.yml")
+	suiteData := "dataset_file: items.jsonl\nmetrics:\n  - name: equals\n"
+	if err := os.WriteFile(suitePath, []byte(suiteData), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	items := "{\"input\": \"a\", \"output\": \"x\"}\n\n{\"input\": \"b\", \"output\": \"y\"}\n"
+	if err := os.WriteFile(filepath.Join(dir, "items.jsonl"), []byte(items), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	suite, err := Load(suitePath)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+
+	got, err := suite.LoadItems()
+	if err != nil {
+		t.Fatalf("LoadItems error: %v", err)
+	}
+	if len(got) != 2 || got[1]["input"] != "b" {
+		t.Errorf("items = %v", got)
+	}
+
+	t.Run("json array dataset", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(dir, "items.json"), []byte(`[{"input": "a"}]`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		suite.DatasetFile = "items.json"
+		got, err := suite.LoadItems()
+		if err != nil {
+			t.Fatalf("LoadItems error: %v", err)
+		}
+		if len(got) != 1 {
+			t.Errorf("len(items) = %d, want 1", len(got))
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestMappingDefaults(t *testing.T) {
+	var m Mapping
+	if m.InputKey() != "input" || m.OutputKey() != "output" ||
+		m.ExpectedKey() != "expected" || m.ContextKey() != "context" {
+		t.Errorf("unexpected defaults: %q %q %q %q", m.InputKey(), m.OutputKey(), m.ExpectedKey(), m.ContextKey())
+	}
+
+	m = Mapping{Input: "q"}
+	if m.InputKey() != "q" {
+		t.Errorf("InputKey = %q, want q", m.InputKey())
+	}
+}
@@ -0,0 +1,56 @@
+// Package evalconfig loads declarative evaluation suites from YAML or JSON files.
+//
+// A suite describes the dataset to evaluate, how dataset items map to metric
+// inputs, the metrics to run with their parameters and thresholds, the judge
+// model for LLM-based metrics, and the evaluation concurrency.
+//
+// # Suite File
+//
+//	name: qa-regression
+//	dataset: qa-golden          # Opik dataset name, or:
+//	# dataset_file: golden.jsonl
+//	mapping:
+//	  input: question
+//	  output: answer
+//	  expected: reference
+//	concurrency: 8
+//	threshold: 0.7              # default threshold for every metric
+//	judge:
+//	  provider: openai
+//	  model: gpt-4o-mini
+//	  language: de              # judges write reasons in German
+//	  rationale: true           # judges return the steps of their reasoning
+//	metrics:
+//	  - name: equals
+//	    params:
+//	      case_sensitive: false
+//	  - name: fuzzy_match
+//	    params:
+//	      threshold: 0.85
+//	    threshold: 0.9
+//	  - name: hallucination
+//
+// # Usage
+//
+//	suite, err := evalconfig.Load("suite.yaml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	engine, err := suite.BuildEngine(evalconfig.WithJudgeProvider(provider))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	evaluator := evaluation.NewDatasetEvaluator(engine, suite.InputMapper())
+//	results := evaluator.Evaluate(ctx, items)
+//
+//	if err := suite.CheckThresholds(engine.Metrics(), results); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// The same suite files can be run from the command line with
+// "opik eval -config suite.yaml", or combined with metric names, as in
+// "opik eval -dataset qa-golden -metrics equals,judges.yaml". MetricNames lists the metric names a suite
+// may reference.
+package evalconfig
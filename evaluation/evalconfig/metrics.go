@@ -0,0 +1,15 @@
+package evalconfig
+
+import (
+	"github.com/plexusone/opik-go/evaluation"
+
+	// Register the built-in heuristic and LLM judge metrics.
+	_ "github.com/plexusone/opik-go/evaluation/heuristic"
+	_ "github.com/plexusone/opik-go/evaluation/llm"
+)
+
+// MetricNames returns the names of the metrics available to suites, sorted.
+// It includes the built-in metrics and any registered with evaluation.Register.
+func MetricNames() []string {
+	return evaluation.RegisteredMetrics()
+}
@@ -0,0 +1,364 @@
+package heuristic
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// codeLanguage is how CodeExecutes runs programs in a language.
+type codeLanguage struct {
+	interpreter string
+	args        []string
+	ext         string
+}
+
+// codeLanguages are the languages CodeExecutes runs, by name.
+var codeLanguages = map[string]codeLanguage{
+	"python":     {interpreter: "python3", ext: ".py"},
+	"javascript": {interpreter: "node", ext: ".js"},
+	"ruby":       {interpreter: "ruby", ext: ".rb"},
+	"bash":       {interpreter: "bash", ext: ".sh"},
+	"sh":         {interpreter: "sh", ext: ".sh"},
+	"go":         {interpreter: "go", args: []string{"run"}, ext: ".go"},
+}
+
+// codeLanguageAliases map other names of languages, such as the languages
+// of fenced code blocks, to their names in codeLanguages.
+var codeLanguageAliases = map[string]string{
+	"py":      "python",
+	"python3": "python",
+	"js":      "javascript",
+	"node":    "javascript",
+	"rb":      "ruby",
+	"shell":   "sh",
+	"golang":  "go",
+}
+
+// DefaultCodeOutputBytes is the default CodeLimits.MaxOutputBytes.
+const DefaultCodeOutputBytes = 1 << 20
+
+// CodeLimits are the resources a program run by CodeExecutes may use.
+// The memory and CPU limits are set with ulimit, so they need a Unix
+// shell at /bin/sh. Zero fields are not limited.
+type CodeLimits struct {
+	// MaxMemoryBytes limits the virtual memory of the program. Some
+	// runtimes, such as Node.js and Go, reserve far more virtual memory
+	// than they use and need a generous limit.
+	MaxMemoryBytes int64
+	// MaxCPUTime limits the CPU time of the program, in whole seconds.
+	MaxCPUTime time.Duration
+	// MaxOutputBytes limits how much of stdout and stderr each is kept;
+	// the rest is discarded. Defaults to DefaultCodeOutputBytes.
+	MaxOutputBytes int
+}
+
+// CodeExecutes runs the code in the output and checks that it works: the
+// program must exit with status 0 before the timeout and, if the input has
+// an expected value, print it to stdout, ignoring leading and trailing
+// white space. The code is the first fenced code block of the output in
+// the metric's language, or else its first fenced code block, or else the
+// whole output. A "stdin" string in the input's metadata is passed to the
+// program as its standard input.
+//
+// Each program runs in a new temporary directory, which is removed
+// afterwards, with only PATH of the environment, HOME and TMPDIR set to
+// the directory, and the limits set with WithCodeLimits. The CodeRunner
+// set with WithCodeRunner starts it: by default a NamespaceRunner on
+// Linux, which cuts the program off from the network and other processes.
+// Other systems have no default, so a runner must be chosen. No built-in
+// runner keeps the code from reading the current user's files; run
+// untrusted code with a CodeRunner that uses a container or VM.
+//
+// A program that fails scores 0 with its exit status and the end of its
+// stderr as the reason. If the interpreter cannot be started, the result
+// is failed rather than scored.
+type CodeExecutes struct {
+	evaluation.BaseMetric
+	fenceNames  []string
+	interpreter string
+	args        []string
+	ext         string
+	allowed     []string
+	timeout     time.Duration
+	limits      CodeLimits
+	runner      CodeRunner
+}
+
+// CodeExecutesOption configures a CodeExecutes metric.
+type CodeExecutesOption func(*CodeExecutes)
+
+// WithInterpreter sets the program, and the arguments before the code
+// file, that runs the code, such as "python3.12" or "deno", "run". The
+// interpreter must be allowed with WithAllowedInterpreters unless it is the
+// language's default.
+func WithInterpreter(interpreter string, args ...string) CodeExecutesOption {
+	return func(m *CodeExecutes) {
+		m.interpreter = interpreter
+		m.args = args
+	}
+}
+
+// WithAllowedInterpreters sets the interpreters the metric may run, by the
+// name or path given to WithInterpreter, or by their resolved path. It
+// replaces the default list, the default interpreter of each language:
+// python3, node, ruby, bash, sh, and go.
+func WithAllowedInterpreters(interpreters ...string) CodeExecutesOption {
+	return func(m *CodeExecutes) {
+		m.allowed = interpreters
+	}
+}
+
+// WithCodeLimits sets the resources the program may use.
+func WithCodeLimits(limits CodeLimits) CodeExecutesOption {
+	return func(m *CodeExecutes) {
+		m.limits = limits
+	}
+}
+
+// WithCodeRunner sets the CodeRunner that runs the programs, such as
+// ProcessRunner to run trusted code without isolation.
+func WithCodeRunner(runner CodeRunner) CodeExecutesOption {
+	return func(m *CodeExecutes) {
+		m.runner = runner
+	}
+}
+
+// NewCodeExecutes creates a new CodeExecutes metric for code in lang:
+// python, javascript, ruby, bash, sh, or go. Each run is stopped after
+// timeout. It returns an error if the language is unknown, the
+// interpreter is not allowed or cannot be found, or there is no default
+// CodeRunner and none is set.
+func NewCodeExecutes(lang string, timeout time.Duration, opts ...CodeExecutesOption) (*CodeExecutes, error) {
+	name := strings.ToLower(lang)
+	if alias, ok := codeLanguageAliases[name]; ok {
+		name = alias
+	}
+	language, ok := codeLanguages[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown code language %q", lang)
+	}
+
+	m := &CodeExecutes{
+		BaseMetric:  evaluation.NewBaseMetric("code_executes"),
+		interpreter: language.interpreter,
+		args:        language.args,
+		ext:         language.ext,
+		timeout:     timeout,
+	}
+	for alias, target := range codeLanguageAliases {
+		if target == name {
+			m.fenceNames = append(m.fenceNames, alias)
+		}
+	}
+	m.fenceNames = append(m.fenceNames, name)
+	for _, l := range codeLanguages {
+		m.allowed = append(m.allowed, l.interpreter)
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	var p evaluation.Problems
+	if m.runner == nil {
+		runner, err := defaultCodeRunner()
+		if err != nil {
+			p.Add("runner", err)
+		}
+		m.runner = runner
+	}
+	if timeout <= 0 {
+		p.Addf("timeout must be positive: %v", timeout)
+	}
+	if path, err := exec.LookPath(m.interpreter); err != nil {
+		p.Add("interpreter", err)
+	} else if !slices.Contains(m.allowed, m.interpreter) && !slices.Contains(m.allowed, path) {
+		p.Addf("interpreter %q is not allowed", m.interpreter)
+	} else {
+		m.interpreter = path
+	}
+	if m.limits.MaxMemoryBytes < 0 || m.limits.MaxCPUTime < 0 || m.limits.MaxOutputBytes < 0 {
+		p.Addf("limits must not be negative: %+v", m.limits)
+	}
+	if (m.limits.MaxMemoryBytes > 0 || m.limits.MaxCPUTime > 0) && runtime.GOOS == "windows" {
+		p.Addf("memory and CPU limits are not supported on Windows")
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if m.limits.MaxOutputBytes == 0 {
+		m.limits.MaxOutputBytes = DefaultCodeOutputBytes
+	}
+	return m, nil
+}
+
+// Score runs the code in the output and scores 1 if it succeeds.
+func (m *CodeExecutes) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+	code := m.extractCode(input.Output)
+	if strings.TrimSpace(code) == "" {
+		return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "no code in output")
+	}
+
+	run, err := m.run(ctx, code, input.GetString("stdin"))
+	if err != nil {
+		return evaluation.NewFailedScoreResult(m.Name(), err)
+	}
+	switch {
+	case run.timedOut:
+		return evaluation.NewScoreResultWithReason(m.Name(), 0.0, fmt.Sprintf("timed out after %v", m.timeout))
+	case !run.exit.Success():
+		reason := run.exit.Description
+		if stderr := strings.TrimSpace(run.stderr); stderr != "" {
+			reason += ": " + lastBytes(stderr, 500)
+		}
+		return evaluation.NewScoreResultWithReason(m.Name(), 0.0, reason)
+	case input.Expected != "" && strings.TrimSpace(run.stdout) != strings.TrimSpace(input.Expected):
+		return evaluation.NewScoreResultWithReason(m.Name(), 0.0,
+			fmt.Sprintf("stdout %q, want %q", lastBytes(strings.TrimSpace(run.stdout), 200), lastBytes(strings.TrimSpace(input.Expected), 200)))
+	case input.Expected != "":
+		return evaluation.NewScoreResultWithReason(m.Name(), 1.0, "exited with status 0 and printed the expected output")
+	default:
+		return evaluation.NewScoreResultWithReason(m.Name(), 1.0, "exited with status 0")
+	}
+}
+
+// extractCode returns the code to run from an output: the first fenced
+// code block in the metric's language, the first fenced code block, or
+// the whole output.
+func (m *CodeExecutes) extractCode(output string) string {
+	var first *string
+	var block []string
+	fence, lang := "", ""
+	for line := range strings.SplitSeq(output, "\n") {
+		if fence == "" {
+			if f := markdownFence.FindStringSubmatch(line); f != nil {
+				fence, lang = f[1], strings.ToLower(f[2])
+				block = block[:0]
+			}
+			continue
+		}
+		if !strings.HasPrefix(strings.TrimSpace(line), fence) {
+			block = append(block, line)
+			continue
+		}
+		code := strings.Join(block, "\n") + "\n"
+		if slices.Contains(m.fenceNames, lang) {
+			return code
+		}
+		if first == nil {
+			first = &code
+		}
+		fence = ""
+	}
+	if first != nil {
+		return *first
+	}
+	return output
+}
+
+// codeRun is the outcome of running a program.
+type codeRun struct {
+	exit     CodeExitStatus
+	stdout   string
+	stderr   string
+	timedOut bool
+}
+
+// run runs code in a new temporary directory and waits for it to exit.
+func (m *CodeExecutes) run(ctx context.Context, code, stdin string) (codeRun, error) {
+	dir, err := os.MkdirTemp("", "opik-code-")
+	if err != nil {
+		return codeRun{}, err
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "main"+m.ext)
+	if err := os.WriteFile(file, []byte(code), 0o600); err != nil {
+		return codeRun{}, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+	name, args := m.command(file)
+	stdout := &cappedBuffer{max: m.limits.MaxOutputBytes}
+	stderr := &cappedBuffer{max: m.limits.MaxOutputBytes}
+	exit, err := m.runner.Run(runCtx, &CodeCommand{
+		Path:   name,
+		Args:   args,
+		Dir:    dir,
+		Env:    []string{"PATH=" + os.Getenv("PATH"), "HOME=" + dir, "TMPDIR=" + dir},
+		Stdin:  strings.NewReader(stdin),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if ctx.Err() != nil {
+		return codeRun{}, ctx.Err()
+	}
+	run := codeRun{exit: exit, stdout: stdout.String(), stderr: stderr.String()}
+	switch {
+	case runCtx.Err() != nil:
+		run.timedOut = true
+	case err != nil:
+		return codeRun{}, err
+	}
+	return run, nil
+}
+
+// command returns the program and arguments that run file, through a
+// shell that sets the memory and CPU limits if there are any.
+func (m *CodeExecutes) command(file string) (string, []string) {
+	args := append(slices.Clip(m.args), file)
+	var ulimits []string
+	if m.limits.MaxMemoryBytes > 0 {
+		ulimits = append(ulimits, "ulimit -v "+strconv.FormatInt(max(m.limits.MaxMemoryBytes>>10, 1), 10))
+	}
+	if m.limits.MaxCPUTime > 0 {
+		ulimits = append(ulimits, "ulimit -t "+strconv.FormatInt(int64(max(m.limits.MaxCPUTime/time.Second, 1)), 10))
+	}
+	if len(ulimits) == 0 {
+		return m.interpreter, args
+	}
+	script := strings.Join(ulimits, " && ") + ` && exec "$0" "$@"`
+	return "/bin/sh", append([]string{"-c", script, m.interpreter}, args...)
+}
+
+// cappedBuffer keeps the first max bytes written to it and discards the
+// rest, so a program's output cannot exhaust memory.
+type cappedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
+
+// lastBytes returns the end of s, at most n bytes, for a reason. It does
+// not start in the middle of a UTF-8 character.
+func lastBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	start := len(s) - n
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return "..." + s[start:]
+}
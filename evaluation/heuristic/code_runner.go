@@ -0,0 +1,107 @@
+package heuristic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// CodeRunner runs the programs scored by CodeExecutes, and decides how
+// they are isolated from the host. Implement it to run programs in a
+// container or VM.
+type CodeRunner interface {
+	// Run runs cmd and waits for it to exit. It returns how the program
+	// ended, or an error if it could not be run. Once ctx is done, Run must
+	// stop the program and every process it started.
+	Run(ctx context.Context, cmd *CodeCommand) (CodeExitStatus, error)
+}
+
+// CodeCommand is a program for a CodeRunner to run.
+type CodeCommand struct {
+	// Path is the interpreter, or a shell that sets the limits of the run
+	// and starts it, and Args are its arguments, ending with the path of
+	// the code file.
+	Path string
+	Args []string
+	// Dir is the run directory. It holds the code file and is the working
+	// directory, HOME, and TMPDIR of the program.
+	Dir string
+	// Env is the program's whole environment.
+	Env []string
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// CodeExitStatus is how a program run by a CodeRunner ended.
+type CodeExitStatus struct {
+	// Code is the exit status, or -1 if the program was killed by a
+	// signal.
+	Code int
+	// Description describes the end for a score reason, such as
+	// "exit status 3" or "signal: killed".
+	Description string
+}
+
+// Success reports whether the program exited with status 0.
+func (s CodeExitStatus) Success() bool {
+	return s.Code == 0
+}
+
+// ProcessRunner runs programs as child processes of the current one,
+// without isolation: they run as the current user, can read their files,
+// and can use the network. Each program runs in its own process group,
+// which is killed when the run stops, so processes it starts in the
+// background do not outlive it. Use it only for trusted code, or where
+// NamespaceRunner is not available.
+type ProcessRunner struct{}
+
+// Run implements CodeRunner.
+func (ProcessRunner) Run(ctx context.Context, cmd *CodeCommand) (CodeExitStatus, error) {
+	return runCodeCommand(ctx, cmd, nil)
+}
+
+// NamespaceRunner runs programs as ProcessRunner does, in new Linux user,
+// PID, network, IPC, and UTS namespaces: a program cannot reach the
+// network, or see or signal processes outside its run, and the kernel
+// kills every process it started when it exits. It still runs as the
+// current user and can read their files. It is the default CodeRunner on
+// Linux, and needs unprivileged user namespaces; on other systems, Run
+// returns an error.
+type NamespaceRunner struct{}
+
+// runCodeCommand runs cmd as a child process in its own process group,
+// with isolate, if not nil, applied to it first.
+func runCodeCommand(ctx context.Context, c *CodeCommand, isolate func(*exec.Cmd)) (CodeExitStatus, error) {
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...) //nolint:gosec // G204: running the output is the point; interpreters are allowlisted
+	cmd.Dir = c.Dir
+	cmd.Env = c.Env
+	cmd.Stdin = c.Stdin
+	cmd.Stdout, cmd.Stderr = c.Stdout, c.Stderr
+	// Stop waiting for output held open by a process the program started.
+	cmd.WaitDelay = time.Second
+	setProcessGroup(cmd)
+	if isolate != nil {
+		isolate(cmd)
+	}
+
+	err := cmd.Run()
+	if cmd.Process != nil {
+		killProcessGroup(cmd)
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil, errors.As(err, &exitErr), errors.Is(err, exec.ErrWaitDelay):
+		return exitStatus(cmd.ProcessState), nil
+	default:
+		return CodeExitStatus{}, err
+	}
+}
+
+func exitStatus(state *os.ProcessState) CodeExitStatus {
+	return CodeExitStatus{Code: state.ExitCode(), Description: state.String()}
+}
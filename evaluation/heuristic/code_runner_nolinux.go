@@ -0,0 +1,20 @@
+//go:build !linux
+
+package heuristic
+
+import (
+	"context"
+	"errors"
+	"runtime"
+)
+
+// Run implements CodeRunner. Namespaces are only supported on Linux.
+func (NamespaceRunner) Run(context.Context, *CodeCommand) (CodeExitStatus, error) {
+	return CodeExitStatus{}, errors.New("namespaces are not supported on " + runtime.GOOS)
+}
+
+// defaultCodeRunner returns the CodeRunner of metrics created without
+// WithCodeRunner. No runner isolates programs here, so one must be chosen.
+func defaultCodeRunner() (CodeRunner, error) {
+	return nil, errors.New("no isolating code runner on " + runtime.GOOS + "; choose one with WithCodeRunner, such as ProcessRunner for trusted code")
+}
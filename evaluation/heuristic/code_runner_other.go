@@ -0,0 +1,11 @@
+//go:build !unix
+
+package heuristic
+
+import "os/exec"
+
+// setProcessGroup does nothing where process groups are not supported;
+// cancelling cmd kills only the program.
+func setProcessGroup(*exec.Cmd) {}
+
+func killProcessGroup(*exec.Cmd) {}
@@ -0,0 +1,107 @@
+package heuristic
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// namespacesWork reports whether NamespaceRunner can run programs here.
+func namespacesWork(t *testing.T) bool {
+	t.Helper()
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		return false
+	}
+	_, err = NamespaceRunner{}.Run(context.Background(), &CodeCommand{Path: sh, Args: []string{"-c", "true"}})
+	if err != nil {
+		t.Logf("NamespaceRunner unavailable: %v", err)
+	}
+	return err == nil
+}
+
+func TestCodeRunnerKillsProcessGroup(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reads /proc")
+	}
+	m := newShellMetric(t, 200*time.Millisecond, WithCodeRunner(ProcessRunner{}))
+
+	// The background sleep holds stdout open, and must die with the run.
+	run, err := m.run(context.Background(), "sleep 30 & echo $!; wait", "")
+	if err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	if !run.timedOut {
+		t.Fatalf("run = %+v, want timed out", run)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(run.stdout))
+	if err != nil {
+		t.Fatalf("stdout %q is not a PID", run.stdout)
+	}
+	for start := time.Now(); processRunning(pid); {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("background process %d still running", pid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// processRunning reports whether the process is alive and not a zombie.
+func processRunning(pid int) bool {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z" && fields[0] != "X"
+}
+
+func TestNamespaceRunner(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		if _, err := NewCodeExecutes("sh", time.Second); err == nil || !strings.Contains(err.Error(), "WithCodeRunner") {
+			t.Errorf("NewCodeExecutes without a runner error = %v, want one naming WithCodeRunner", err)
+		}
+		t.Skip("namespaces need Linux")
+	}
+	if !namespacesWork(t) {
+		t.Skip("user namespaces are not available")
+	}
+	m, err := NewCodeExecutes("sh", 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewCodeExecutes error: %v", err)
+	}
+	if _, ok := m.runner.(NamespaceRunner); !ok {
+		t.Fatalf("default runner = %T, want NamespaceRunner", m.runner)
+	}
+
+	// The program is the first process of its PID namespace, and its
+	// network namespace has only the loopback interface.
+	result := m.Score(context.Background(), evaluation.NewMetricInput("",
+		`[ $$ = 1 ] && [ "$(grep -c : /proc/net/dev)" = 1 ]`))
+	if result.Value != 1 {
+		t.Errorf("Score = %v (%s), want the program isolated", result.Value, result.Reason)
+	}
+}
+
+// failingRunner is a CodeRunner that cannot run programs.
+type failingRunner struct{}
+
+func (failingRunner) Run(context.Context, *CodeCommand) (CodeExitStatus, error) {
+	return CodeExitStatus{}, errors.New("sandbox unavailable")
+}
+
+func TestWithCodeRunner(t *testing.T) {
+	m := newShellMetric(t, time.Second, WithCodeRunner(failingRunner{}))
+	result := m.Score(context.Background(), evaluation.NewMetricInput("", "true"))
+	if result.Status != evaluation.ScoreStatusFailed || !strings.Contains(result.Error.Error(), "sandbox unavailable") {
+		t.Errorf("Score = %+v, want failed with the runner's error", result)
+	}
+}
@@ -0,0 +1,216 @@
+package heuristic
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// newShellMetric returns a CodeExecutes metric for sh, with the default
+// runner where it works and ProcessRunner elsewhere.
+func newShellMetric(t *testing.T, timeout time.Duration, opts ...CodeExecutesOption) *CodeExecutes {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a Unix shell")
+	}
+	if !namespacesWork(t) {
+		opts = append([]CodeExecutesOption{WithCodeRunner(ProcessRunner{})}, opts...)
+	}
+	m, err := NewCodeExecutes("sh", timeout, opts...)
+	if err != nil {
+		t.Fatalf("NewCodeExecutes error: %v", err)
+	}
+	return m
+}
+
+func TestCodeExecutes(t *testing.T) {
+	m := newShellMetric(t, 5*time.Second)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		input  evaluation.MetricInput
+		want   float64
+		reason string
+	}{
+		{"exits 0", evaluation.NewMetricInput("", "true"), 1, "exited with status 0"},
+		{"fails", evaluation.NewMetricInput("", "echo boom >&2; exit 3"), 0, "exit status 3: boom"},
+		{"expected stdout", evaluation.NewMetricInput("", "echo 42").WithExpected("42\n"), 1, "printed the expected output"},
+		{"wrong stdout", evaluation.NewMetricInput("", "echo 41").WithExpected("42"), 0, `stdout "41", want "42"`},
+		{"stdin", evaluation.NewMetricInput("", "read x; echo $((x * 2))").WithExpected("42").WithMetadata("stdin", "21\n"), 1, "expected output"},
+		{"fenced", evaluation.NewMetricInput("", "Here you go:\n\n```sh\nexit 0\n```\n"), 1, "exited with status 0"},
+		{"no code", evaluation.NewMetricInput("", "  \n"), 0, "no code in output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := m.Score(ctx, tt.input)
+			if result.Value != tt.want || !strings.Contains(result.Reason, tt.reason) {
+				t.Errorf("Score = %v (%s), want %v (%s)", result.Value, result.Reason, tt.want, tt.reason)
+			}
+		})
+	}
+}
+
+func TestCodeExecutesTimeout(t *testing.T) {
+	m := newShellMetric(t, 100*time.Millisecond)
+	start := time.Now()
+	result := m.Score(context.Background(), evaluation.NewMetricInput("", "sleep 10"))
+	if result.Value != 0 || result.Reason != "timed out after 100ms" {
+		t.Errorf("Score = %v (%s), want a timeout", result.Value, result.Reason)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Score took %v, want the program stopped at the timeout", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result := m.Score(ctx, evaluation.NewMetricInput("", "true")); result.Status != evaluation.ScoreStatusFailed {
+		t.Errorf("Score with a canceled context = %+v, want failed", result)
+	}
+}
+
+func TestCodeExecutesSandbox(t *testing.T) {
+	m := newShellMetric(t, 5*time.Second)
+	t.Setenv("OPIK_CODE_SECRET", "leaked")
+
+	result := m.Score(context.Background(), evaluation.NewMetricInput("",
+		`[ -z "$OPIK_CODE_SECRET" ] && [ "$HOME" = "$(pwd)" ] && [ "$TMPDIR" = "$HOME" ] && pwd`))
+	if result.Value != 1 {
+		t.Fatalf("Score = %v (%s), want the environment cleared and HOME in the run directory", result.Value, result.Reason)
+	}
+
+	dir := strings.TrimSpace(runStdout(t, m, "pwd"))
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("run directory %s still exists: %v", dir, err)
+	}
+}
+
+// runStdout runs code with m and returns its stdout.
+func runStdout(t *testing.T, m *CodeExecutes, code string) string {
+	t.Helper()
+	run, err := m.run(context.Background(), code, "")
+	if err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	return run.stdout
+}
+
+func TestCodeExecutesLimits(t *testing.T) {
+	m := newShellMetric(t, 10*time.Second, WithCodeLimits(CodeLimits{MaxCPUTime: time.Second, MaxOutputBytes: 10}))
+
+	result := m.Score(context.Background(), evaluation.NewMetricInput("", "while :; do :; done"))
+	if result.Value != 0 || !strings.Contains(result.Reason, "signal") {
+		t.Errorf("Score of a busy loop = %v (%s), want killed by a signal", result.Value, result.Reason)
+	}
+	if got := runStdout(t, m, "echo 0123456789abcdef"); got != "0123456789" {
+		t.Errorf("stdout = %q, want the first 10 bytes", got)
+	}
+
+	m = newShellMetric(t, time.Second, WithCodeLimits(CodeLimits{MaxMemoryBytes: 256 << 20}))
+	name, args := m.command("main.sh")
+	if name != "/bin/sh" || args[1] != `ulimit -v 262144 && exec "$0" "$@"` || args[len(args)-1] != "main.sh" {
+		t.Errorf("command = %s %q, want a shell setting the memory limit", name, args)
+	}
+	if result := m.Score(context.Background(), evaluation.NewMetricInput("", "ulimit -v")).Reason; result != "exited with status 0" {
+		t.Errorf("Score with a memory limit = %s", result)
+	}
+}
+
+func TestLastBytes(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"0123456789", 4, "...6789"},
+		{"héllo wörld", 5, "...örld"},
+		{"héllo wörld", 4, "...rld"},
+		{"日本語", 4, "...語"},
+	}
+	for _, tt := range tests {
+		if got := lastBytes(tt.s, tt.n); got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("lastBytes(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestCodeExecutesExtractCode(t *testing.T) {
+	m := &CodeExecutes{fenceNames: []string{"py", "python"}}
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"print(1)", "print(1)"},
+		{"Try:\n```\nfirst\n```\n", "first\n"},
+		{"```bash\nls\n```\ntext\n```python\nprint(2)\nprint(3)\n```", "print(2)\nprint(3)\n"},
+		{"~~~py\nx = 1\n~~~", "x = 1\n"},
+		{"```python\nunclosed", "```python\nunclosed"},
+	}
+	for _, tt := range tests {
+		if got := m.extractCode(tt.output); got != tt.want {
+			t.Errorf("extractCode(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestCodeExecutesPython(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not installed")
+	}
+	m, err := NewCodeExecutes("Python", 10*time.Second)
+	if err != nil {
+		t.Fatalf("NewCodeExecutes error: %v", err)
+	}
+	output := "```python\ndef add(a, b):\n    return a + b\n\nprint(add(2, 3))\n```"
+	if result := m.Score(context.Background(), evaluation.NewMetricInput("", output).WithExpected("5")); result.Value != 1 {
+		t.Errorf("Score = %v (%s), want 1", result.Value, result.Reason)
+	}
+	result := m.Score(context.Background(), evaluation.NewMetricInput("", "raise ValueError('bad')"))
+	if result.Value != 0 || !strings.Contains(result.Reason, "ValueError: bad") {
+		t.Errorf("Score of a raising program = %v (%s)", result.Value, result.Reason)
+	}
+}
+
+func TestNewCodeExecutesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		opts []CodeExecutesOption
+		want string
+	}{
+		{"unknown language", "cobol", nil, `unknown code language "cobol"`},
+		{"not allowed", "sh", []CodeExecutesOption{WithAllowedInterpreters("python3")}, `interpreter "sh" is not allowed`},
+		{"missing interpreter", "sh", []CodeExecutesOption{
+			WithInterpreter("opik-no-such-interpreter"),
+			WithAllowedInterpreters("opik-no-such-interpreter"),
+		}, "interpreter: "},
+		{"negative limits", "sh", []CodeExecutesOption{WithCodeLimits(CodeLimits{MaxOutputBytes: -1})}, "limits must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCodeExecutes(tt.lang, time.Second, tt.opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewCodeExecutes error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := NewCodeExecutes("sh", 0); err == nil || !strings.Contains(err.Error(), "timeout must be positive") {
+		t.Errorf("NewCodeExecutes with a zero timeout error = %v", err)
+	}
+
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not installed")
+	}
+	if _, err := NewCodeExecutes("sh", time.Second, WithInterpreter(sh), WithAllowedInterpreters(sh)); err != nil {
+		t.Errorf("NewCodeExecutes with an allowed path error = %v", err)
+	}
+}
@@ -17,6 +17,16 @@
 //   - JSONHasKeys, JSONSchemaValid: JSON structure validation
 //   - IsXML: XML validation
 //   - IsNumber, IsBoolean: Type validation
+//   - MarkdownStructure: Required headings, tables, links, and code blocks
+//
+// # Code Metrics
+//
+// Functional correctness of generated code:
+//   - CodeExecutes: Runs the code in a subprocess and checks its exit status
+//     and stdout. Programs are started by a CodeRunner: NamespaceRunner by
+//     default on Linux, ProcessRunner for trusted code, or your own
+//     container or VM runner. It is registered for suite files only by
+//     RegisterCodeExecutes.
 //
 // # Pattern Metrics
 //
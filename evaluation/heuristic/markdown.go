@@ -0,0 +1,192 @@
+package heuristic
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// MarkdownRequirements describes the Markdown layout an output must follow.
+// Zero fields are not checked.
+type MarkdownRequirements struct {
+	// Headings must all appear, matched case-insensitively against the
+	// heading text. Prefix a heading with #s, as in "## Usage", to also
+	// require its level.
+	Headings []string
+	// RequireTable requires at least one table.
+	RequireTable bool
+	// MinLinks and MaxLinks bound the number of links. A MaxLinks of 0
+	// means no upper bound.
+	MinLinks int
+	MaxLinks int
+	// CodeLanguages must each be the language of at least one fenced code
+	// block, matched case-insensitively.
+	CodeLanguages []string
+	// RequireCodeLanguage requires every fenced code block to declare a
+	// language.
+	RequireCodeLanguage bool
+}
+
+// MarkdownStructure checks that the output follows a required Markdown
+// layout: headings, tables, link counts, and fenced code block languages.
+// The score is the fraction of requirements met, and the reason lists the
+// ones that are not.
+type MarkdownStructure struct {
+	evaluation.BaseMetric
+	req MarkdownRequirements
+}
+
+// NewMarkdownStructure creates a new MarkdownStructure metric.
+func NewMarkdownStructure(requirements MarkdownRequirements) *MarkdownStructure {
+	return &MarkdownStructure{
+		BaseMetric: evaluation.NewBaseMetric("markdown_structure"),
+		req:        requirements,
+	}
+}
+
+var (
+	markdownHeading    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	markdownTableDelim = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	markdownLink       = regexp.MustCompile(`(^|[^!])\[[^\]]*\]\([^)\s]+(\s+"[^"]*")?\)|<https?://[^>\s]+>`)
+	markdownFence      = regexp.MustCompile("^ {0,3}(```+|~~~+)\\s*([^`\\s]*)")
+)
+
+// markdownDoc is the structure found in a Markdown document.
+type markdownDoc struct {
+	headings  []markdownHeadingLine
+	tables    int
+	links     int
+	codeLangs []string // one per fenced block; "" if none declared
+}
+
+type markdownHeadingLine struct {
+	level int
+	text  string
+}
+
+// parseMarkdown scans a document line by line. Headings, tables, and links
+// inside fenced code blocks are ignored.
+func parseMarkdown(text string) markdownDoc {
+	var doc markdownDoc
+	lines := strings.Split(text, "\n")
+	fence := ""
+	for i, line := range lines {
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := markdownFence.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			doc.codeLangs = append(doc.codeLangs, strings.ToLower(m[2]))
+			continue
+		}
+		if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			doc.headings = append(doc.headings, markdownHeadingLine{level: len(m[1]), text: strings.TrimSpace(m[2])})
+			continue
+		}
+		if i > 0 && strings.Contains(lines[i-1], "|") && strings.Contains(line, "-") && markdownTableDelim.MatchString(line) {
+			doc.tables++
+		}
+		doc.links += len(markdownLink.FindAllString(line, -1))
+	}
+	return doc
+}
+
+// Score evaluates the output against the Markdown requirements.
+func (m *MarkdownStructure) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+	doc := parseMarkdown(input.Output)
+	checks := 0
+	var failures []string
+
+	for _, want := range m.req.Headings {
+		checks++
+		if !doc.hasHeading(want) {
+			failures = append(failures, "missing heading "+want)
+		}
+	}
+	if m.req.RequireTable {
+		checks++
+		if doc.tables == 0 {
+			failures = append(failures, "missing table")
+		}
+	}
+	if m.req.MinLinks > 0 || m.req.MaxLinks > 0 {
+		checks++
+		if doc.links < m.req.MinLinks {
+			failures = append(failures, fmt.Sprintf("%d links, want at least %d", doc.links, m.req.MinLinks))
+		} else if m.req.MaxLinks > 0 && doc.links > m.req.MaxLinks {
+			failures = append(failures, fmt.Sprintf("%d links, want at most %d", doc.links, m.req.MaxLinks))
+		}
+	}
+	for _, lang := range m.req.CodeLanguages {
+		checks++
+		if !slices.Contains(doc.codeLangs, strings.ToLower(lang)) {
+			failures = append(failures, "missing "+lang+" code block")
+		}
+	}
+	if m.req.RequireCodeLanguage {
+		checks++
+		missing := 0
+		for _, lang := range doc.codeLangs {
+			if lang == "" {
+				missing++
+			}
+		}
+		if missing > 0 {
+			failures = append(failures, fmt.Sprintf("%d code blocks without a language", missing))
+		}
+	}
+
+	if checks == 0 || len(failures) == 0 {
+		return evaluation.NewScoreResultWithReason(m.Name(), 1.0, "meets all Markdown requirements")
+	}
+	score := float64(checks-len(failures)) / float64(checks)
+	return evaluation.NewScoreResultWithReason(m.Name(), score, strings.Join(failures, "; "))
+}
+
+// Validate reports negative link bounds, a MaxLinks below MinLinks, and
+// empty headings or code languages, which would never match.
+func (m *MarkdownStructure) Validate() error {
+	var p evaluation.Problems
+	if m.req.MinLinks < 0 {
+		p.Addf("MinLinks must not be negative: %d", m.req.MinLinks)
+	}
+	if m.req.MaxLinks < 0 {
+		p.Addf("MaxLinks must not be negative: %d", m.req.MaxLinks)
+	} else if m.req.MaxLinks > 0 && m.req.MaxLinks < m.req.MinLinks {
+		p.Addf("MaxLinks %d is below MinLinks %d", m.req.MaxLinks, m.req.MinLinks)
+	}
+	for i, h := range m.req.Headings {
+		if strings.Trim(h, "# ") == "" {
+			p.Addf("Headings[%d] is empty", i)
+		}
+	}
+	for i, lang := range m.req.CodeLanguages {
+		if strings.TrimSpace(lang) == "" {
+			p.Addf("CodeLanguages[%d] is empty", i)
+		}
+	}
+	return p.Err()
+}
+
+// hasHeading reports whether the document has a heading matching want,
+// which may start with #s to require a level.
+func (d markdownDoc) hasHeading(want string) bool {
+	level := 0
+	for level < len(want) && want[level] == '#' {
+		level++
+	}
+	text := strings.TrimSpace(want[level:])
+	for _, h := range d.headings {
+		if (level == 0 || h.level == level) && strings.EqualFold(h.text, text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package heuristic
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+const markdownReport = "# Release Notes\n" +
+	"\n" +
+	"## Summary\n" +
+	"\n" +
+	"See the [changelog](https://example.com/changelog) and <https://example.com/docs>.\n" +
+	"![diagram](diagram.png)\n" +
+	"\n" +
+	"| Version | Date |\n" +
+	"|:--------|-----:|\n" +
+	"| 1.2.0   | May  |\n" +
+	"\n" +
+	"## Usage ##\n" +
+	"\n" +
+	"```go\n" +
+	"// ## Not a heading\n" +
+	"x := [link](https://ignored.example)\n" +
+	"```\n" +
+	"\n" +
+	"~~~\n" +
+	"plain\n" +
+	"~~~\n"
+
+func TestParseMarkdown(t *testing.T) {
+	doc := parseMarkdown(markdownReport)
+
+	var headings []string
+	for _, h := range doc.headings {
+		headings = append(headings, strings.Repeat("#", h.level)+" "+h.text)
+	}
+	if got := strings.Join(headings, ", "); got != "# Release Notes, ## Summary, ## Usage" {
+		t.Errorf("headings = %s", got)
+	}
+	if doc.tables != 1 {
+		t.Errorf("tables = %d, want 1", doc.tables)
+	}
+	if doc.links != 2 {
+		t.Errorf("links = %d, want 2 (images and code are not links)", doc.links)
+	}
+	if strings.Join(doc.codeLangs, ",") != "go," {
+		t.Errorf("code languages = %q, want [go, \"\"]", doc.codeLangs)
+	}
+}
+
+func TestMarkdownStructure(t *testing.T) {
+	ctx := context.Background()
+	input := evaluation.NewMetricInput("", markdownReport)
+
+	tests := []struct {
+		name   string
+		req    MarkdownRequirements
+		want   float64
+		reason string
+	}{
+		{"no requirements", MarkdownRequirements{}, 1, ""},
+		{"headings", MarkdownRequirements{Headings: []string{"summary", "## Usage"}}, 1, ""},
+		{"heading level", MarkdownRequirements{Headings: []string{"# Summary", "Install"}}, 0, "missing heading # Summary; missing heading Install"},
+		{"table and links", MarkdownRequirements{RequireTable: true, MinLinks: 1, MaxLinks: 3}, 1, ""},
+		{"too few links", MarkdownRequirements{MinLinks: 3}, 0, "2 links, want at least 3"},
+		{"too many links", MarkdownRequirements{MaxLinks: 1}, 0, "2 links, want at most 1"},
+		{"code languages", MarkdownRequirements{CodeLanguages: []string{"Go", "python"}}, 0.5, "missing python code block"},
+		{"code language declared", MarkdownRequirements{RequireCodeLanguage: true}, 0, "1 code blocks without a language"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewMarkdownStructure(tt.req).Score(ctx, input)
+			if result.Value != tt.want {
+				t.Errorf("score = %v, want %v (%s)", result.Value, tt.want, result.Reason)
+			}
+			if tt.reason != "" && result.Reason != tt.reason {
+				t.Errorf("reason = %q, want %q", result.Reason, tt.reason)
+			}
+		})
+	}
+
+	plain := NewMarkdownStructure(MarkdownRequirements{RequireTable: true}).Score(ctx, evaluation.NewMetricInput("", "a | b\nnot a table"))
+	if plain.Value != 0 {
+		t.Errorf("pipe without delimiter row scored %v, want 0", plain.Value)
+	}
+}
+
+func TestMarkdownStructureRegistered(t *testing.T) {
+	m, err := evaluation.NewMetric("markdown_structure", evaluation.MetricParams{
+		"headings":      []any{"Summary"},
+		"require_table": true,
+		"min_links":     1,
+	})
+	if err != nil {
+		t.Fatalf("NewMetric error: %v", err)
+	}
+	if got := m.Score(context.Background(), evaluation.NewMetricInput("", markdownReport)).Value; got != 1 {
+		t.Errorf("score = %v, want 1", got)
+	}
+}
+
+func TestMarkdownStructureValidate(t *testing.T) {
+	m := NewMarkdownStructure(MarkdownRequirements{MinLinks: 3, MaxLinks: 1, Headings: []string{"Summary", "## "}, CodeLanguages: []string{""}})
+	err := m.Validate()
+	if err == nil {
+		t.Fatal("Validate accepted invalid requirements")
+	}
+	for _, want := range []string{"MaxLinks 1 is below MinLinks 3", "Headings[1] is empty", "CodeLanguages[0] is empty"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q: %v", want, err)
+		}
+	}
+	if err := NewMarkdownStructure(MarkdownRequirements{MinLinks: 1}).Validate(); err != nil {
+		t.Errorf("Validate error = %v, want nil", err)
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
+	"math"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/plexusone/opik-go/evaluation"
@@ -31,6 +35,11 @@ func (m *IsJSON) Score(ctx context.Context, input evaluation.MetricInput) *evalu
 	return evaluation.NewScoreResultWithReason(m.Name(), 1.0, "valid JSON")
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *IsJSON) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{Name: m.Name(), Kind: "is_json"}
+}
+
 // IsJSONObject checks if the output is a valid JSON object.
 type IsJSONObject struct {
 	evaluation.BaseMetric
@@ -52,6 +61,11 @@ func (m *IsJSONObject) Score(ctx context.Context, input evaluation.MetricInput)
 	return evaluation.NewScoreResultWithReason(m.Name(), 1.0, "valid JSON object")
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *IsJSONObject) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{Name: m.Name(), Kind: "is_json_object"}
+}
+
 // IsJSONArray checks if the output is a valid JSON array.
 type IsJSONArray struct {
 	evaluation.BaseMetric
@@ -73,6 +87,11 @@ func (m *IsJSONArray) Score(ctx context.Context, input evaluation.MetricInput) *
 	return evaluation.NewScoreResultWithReason(m.Name(), 1.0, "valid JSON array")
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *IsJSONArray) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{Name: m.Name(), Kind: "is_json_array"}
+}
+
 // JSONHasKeys checks if the JSON output has the specified keys.
 type JSONHasKeys struct {
 	evaluation.BaseMetric
@@ -111,6 +130,173 @@ func (m *JSONHasKeys) Score(ctx context.Context, input evaluation.MetricInput) *
 		"missing keys: "+strings.Join(missing, ", "))
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *JSONHasKeys) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{
+		Name:   m.Name(),
+		Kind:   "json_has_keys",
+		Params: map[string]any{"keys": m.keys},
+	}
+}
+
+// JSONEquals checks if the output and expected value are semantically equal
+// JSON documents. Object key order and formatting are ignored.
+type JSONEquals struct {
+	evaluation.BaseMetric
+	tolerance   float64
+	ignoreExtra bool
+}
+
+// JSONEqualsOption configures a JSONEquals metric.
+type JSONEqualsOption func(*JSONEquals)
+
+// WithNumericTolerance treats numbers as equal if they differ by at most tolerance.
+func WithNumericTolerance(tolerance float64) JSONEqualsOption {
+	return func(m *JSONEquals) {
+		m.tolerance = math.Abs(tolerance)
+	}
+}
+
+// WithIgnoreExtraFields ignores object fields in the output that are not in
+// the expected value, so the expected value acts as a subset to match.
+func WithIgnoreExtraFields() JSONEqualsOption {
+	return func(m *JSONEquals) {
+		m.ignoreExtra = true
+	}
+}
+
+// NewJSONEquals creates a new JSONEquals metric.
+func NewJSONEquals(opts ...JSONEqualsOption) *JSONEquals {
+	m := &JSONEquals{
+		BaseMetric: evaluation.NewBaseMetric("json_equals"),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Score evaluates if output and expected are equal JSON documents.
+func (m *JSONEquals) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+	output, err := decodeJSONNumbers(input.Output)
+	if err != nil {
+		return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "output is not valid JSON: "+err.Error())
+	}
+	expected, err := decodeJSONNumbers(input.Expected)
+	if err != nil {
+		return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "expected is not valid JSON: "+err.Error())
+	}
+
+	if diff := m.compare("$", output, expected); diff != "" {
+		return evaluation.NewScoreResultWithReason(m.Name(), 0.0, diff)
+	}
+	return evaluation.NewScoreResultWithReason(m.Name(), 1.0, "JSON documents are equal")
+}
+
+// compare returns a description of the first difference between got and
+// want, or "" if they are equal.
+func (m *JSONEquals) compare(path string, got, want any) string {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return fmt.Sprintf("%s: expected object, got %s", path, jsonKind(got))
+		}
+		keys := make([]string, 0, len(w))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			gv, ok := g[k]
+			if !ok {
+				return fmt.Sprintf("%s.%s: missing", path, k)
+			}
+			if diff := m.compare(path+"."+k, gv, w[k]); diff != "" {
+				return diff
+			}
+		}
+		if !m.ignoreExtra {
+			extra := make([]string, 0)
+			for k := range g {
+				if _, ok := w[k]; !ok {
+					extra = append(extra, k)
+				}
+			}
+			if len(extra) > 0 {
+				sort.Strings(extra)
+				return fmt.Sprintf("%s.%s: unexpected field", path, extra[0])
+			}
+		}
+		return ""
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			return fmt.Sprintf("%s: expected array, got %s", path, jsonKind(got))
+		}
+		if len(g) != len(w) {
+			return fmt.Sprintf("%s: expected %d elements, got %d", path, len(w), len(g))
+		}
+		for i := range w {
+			if diff := m.compare(fmt.Sprintf("%s[%d]", path, i), g[i], w[i]); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	case json.Number:
+		g, ok := got.(json.Number)
+		if !ok {
+			return fmt.Sprintf("%s: expected number, got %s", path, jsonKind(got))
+		}
+		if g == w {
+			return ""
+		}
+		gf, gErr := strconv.ParseFloat(string(g), 64)
+		wf, wErr := strconv.ParseFloat(string(w), 64)
+		if gErr != nil || wErr != nil || math.Abs(gf-wf) > m.tolerance {
+			return fmt.Sprintf("%s: expected %s, got %s", path, w, g)
+		}
+		return ""
+	default:
+		if got != want {
+			return fmt.Sprintf("%s: expected %v, got %v", path, want, got)
+		}
+		return ""
+	}
+}
+
+func decodeJSONNumbers(s string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+func jsonKind(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
 // JSONSchemaValid checks if the JSON output matches a simple schema.
 type JSONSchemaValid struct {
 	evaluation.BaseMetric
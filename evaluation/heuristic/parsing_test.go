@@ -155,6 +155,53 @@ func TestJSONHasKeys(t *testing.T) {
 	}
 }
 
+func TestJSONEquals(t *testing.T) {
+	ctx := context.Background()
+
+	if NewJSONEquals().Name() != "json_equals" {
+		t.Errorf("Name() = %q, want %q", NewJSONEquals().Name(), "json_equals")
+	}
+
+	tests := []struct {
+		name     string
+		opts     []JSONEqualsOption
+		output   string
+		expected string
+		want     float64
+	}{
+		{"identical", nil, `{"a": 1}`, `{"a": 1}`, 1.0},
+		{"key order and whitespace", nil, `{"b":[1,2],"a":{"x":true}}`, `{ "a": {"x": true}, "b": [1, 2] }`, 1.0},
+		{"equivalent numbers", nil, `{"n": 1.0}`, `{"n": 1}`, 1.0},
+		{"different value", nil, `{"a": 1}`, `{"a": 2}`, 0.0},
+		{"array order matters", nil, `[1, 2]`, `[2, 1]`, 0.0},
+		{"missing field", nil, `{"a": 1}`, `{"a": 1, "b": 2}`, 0.0},
+		{"extra field", nil, `{"a": 1, "b": 2}`, `{"a": 1}`, 0.0},
+		{"extra field ignored", []JSONEqualsOption{WithIgnoreExtraFields()}, `{"a": 1, "b": {"c": 2, "d": 3}}`, `{"b": {"c": 2}}`, 1.0},
+		{"within tolerance", []JSONEqualsOption{WithNumericTolerance(0.01)}, `{"p": 0.305}`, `{"p": 0.3}`, 1.0},
+		{"outside tolerance", []JSONEqualsOption{WithNumericTolerance(0.001)}, `{"p": 0.305}`, `{"p": 0.3}`, 0.0},
+		{"type mismatch", nil, `{"a": "1"}`, `{"a": 1}`, 0.0},
+		{"null", nil, `null`, `null`, 1.0},
+		{"invalid output", nil, `{a: 1}`, `{"a": 1}`, 0.0},
+		{"invalid expected", nil, `{"a": 1}`, ``, 0.0},
+		{"trailing data", nil, `{"a": 1} {"b": 2}`, `{"a": 1}`, 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metric := NewJSONEquals(tt.opts...)
+			result := metric.Score(ctx, evaluation.NewMetricInput("", tt.output).WithExpected(tt.expected))
+			if result.Value != tt.want {
+				t.Errorf("Score() = %v, want %v (reason: %s)", result.Value, tt.want, result.Reason)
+			}
+		})
+	}
+
+	result := NewJSONEquals().Score(ctx, evaluation.NewMetricInput("", `{"a": {"b": [1, 3]}}`).WithExpected(`{"a": {"b": [1, 2]}}`))
+	if result.Reason != "$.a.b[1]: expected 2, got 3" {
+		t.Errorf("Reason = %q", result.Reason)
+	}
+}
+
 func TestJSONSchemaValid(t *testing.T) {
 	ctx := context.Background()
 
@@ -43,6 +43,15 @@ func (m *RegexMatch) Score(ctx context.Context, input evaluation.MetricInput) *e
 	return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "does not match pattern")
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *RegexMatch) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{
+		Name:   m.Name(),
+		Kind:   "regex_match",
+		Params: map[string]any{"pattern": m.pattern.String()},
+	}
+}
+
 // RegexNotMatch checks if the output does NOT match a regular expression.
 type RegexNotMatch struct {
 	evaluation.BaseMetric
@@ -78,6 +87,15 @@ func (m *RegexNotMatch) Score(ctx context.Context, input evaluation.MetricInput)
 	return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "matches pattern (unexpected)")
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *RegexNotMatch) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{
+		Name:   m.Name(),
+		Kind:   "regex_not_match",
+		Params: map[string]any{"pattern": m.pattern.String()},
+	}
+}
+
 // RegexFindAll counts how many times a pattern matches.
 type RegexFindAll struct {
 	evaluation.BaseMetric
@@ -120,6 +138,19 @@ func (m *RegexFindAll) Score(ctx context.Context, input evaluation.MetricInput)
 	return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "match count out of range")
 }
 
+// Validate reports a negative minimum, or a maximum below the minimum. A
+// maximum of zero or less means no upper bound.
+func (m *RegexFindAll) Validate() error {
+	var p evaluation.Problems
+	if m.minMatches < 0 {
+		p.Addf("min matches must not be negative: %d", m.minMatches)
+	}
+	if m.maxMatches > 0 && m.maxMatches < m.minMatches {
+		p.Addf("max matches %d is below min matches %d", m.maxMatches, m.minMatches)
+	}
+	return p.Err()
+}
+
 // EmailFormat checks if the output contains a valid email format.
 type EmailFormat struct {
 	evaluation.BaseMetric
@@ -0,0 +1,276 @@
+package heuristic
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// The metrics in this package are registered under their metric names so they
+// can be referenced from suite files and the CLI. ROUGE is registered as "rouge".
+func init() {
+	// String metrics
+	evaluation.Register("equals", caseSensitive(func(cs bool) evaluation.Metric { return NewEquals(cs) }))
+	evaluation.Register("contains", caseSensitive(func(cs bool) evaluation.Metric { return NewContains(cs) }))
+	evaluation.Register("starts_with", caseSensitive(func(cs bool) evaluation.Metric { return NewStartsWith(cs) }))
+	evaluation.Register("ends_with", caseSensitive(func(cs bool) evaluation.Metric { return NewEndsWith(cs) }))
+	evaluation.Register("contains_any", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		values, cs, err := valuesAndCase(p)
+		if err != nil {
+			return nil, err
+		}
+		return NewContainsAny(values, cs), nil
+	})
+	evaluation.Register("contains_all", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		values, cs, err := valuesAndCase(p)
+		if err != nil {
+			return nil, err
+		}
+		return NewContainsAll(values, cs), nil
+	})
+	evaluation.Register("not_empty", simple(func() evaluation.Metric { return NewNotEmpty() }))
+	evaluation.Register("length_between", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		minLen, maxLen, err := minMax(p)
+		if err != nil {
+			return nil, err
+		}
+		return NewLengthBetween(minLen, maxLen), nil
+	})
+	evaluation.Register("word_count", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		minWords, maxWords, err := minMax(p)
+		if err != nil {
+			return nil, err
+		}
+		return NewWordCount(minWords, maxWords), nil
+	})
+	evaluation.Register("no_offensive_language", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		patterns, err := p.Strings("patterns")
+		if err != nil {
+			return nil, err
+		}
+		return NewNoOffensiveLanguage(patterns), nil
+	})
+
+	// Parsing metrics
+	evaluation.Register("is_json", simple(func() evaluation.Metric { return NewIsJSON() }))
+	evaluation.Register("is_json_object", simple(func() evaluation.Metric { return NewIsJSONObject() }))
+	evaluation.Register("is_json_array", simple(func() evaluation.Metric { return NewIsJSONArray() }))
+	evaluation.Register("json_has_keys", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		keys, err := p.Strings("keys")
+		if err != nil {
+			return nil, err
+		}
+		return NewJSONHasKeys(keys), nil
+	})
+	evaluation.Register("json_equals", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		tolerance, err := p.Float("tolerance", 0)
+		if err != nil {
+			return nil, err
+		}
+		ignoreExtra, err := p.Bool("ignore_extra_fields", false)
+		if err != nil {
+			return nil, err
+		}
+		opts := []JSONEqualsOption{WithNumericTolerance(tolerance)}
+		if ignoreExtra {
+			opts = append(opts, WithIgnoreExtraFields())
+		}
+		return NewJSONEquals(opts...), nil
+	})
+	evaluation.Register("json_schema_valid", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		required, err := p.StringMap("required")
+		if err != nil {
+			return nil, err
+		}
+		return NewJSONSchemaValid(required), nil
+	})
+	evaluation.Register("markdown_structure", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		var req MarkdownRequirements
+		var err error
+		if req.Headings, err = p.Strings("headings"); err != nil {
+			return nil, err
+		}
+		if req.RequireTable, err = p.Bool("require_table", false); err != nil {
+			return nil, err
+		}
+		if req.MinLinks, err = p.Int("min_links", 0); err != nil {
+			return nil, err
+		}
+		if req.MaxLinks, err = p.Int("max_links", 0); err != nil {
+			return nil, err
+		}
+		if req.CodeLanguages, err = p.Strings("code_languages"); err != nil {
+			return nil, err
+		}
+		if req.RequireCodeLanguage, err = p.Bool("require_code_language", false); err != nil {
+			return nil, err
+		}
+		return NewMarkdownStructure(req), nil
+	})
+	evaluation.Register("is_xml", simple(func() evaluation.Metric { return NewIsXML() }))
+	evaluation.Register("is_number", simple(func() evaluation.Metric { return NewIsNumber() }))
+	evaluation.Register("is_boolean", simple(func() evaluation.Metric { return NewIsBoolean() }))
+
+	// Pattern metrics
+	evaluation.Register("regex_match", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		pattern, err := p.String("pattern", "")
+		if err != nil {
+			return nil, err
+		}
+		return NewRegexMatch(pattern)
+	})
+	evaluation.Register("regex_not_match", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		pattern, err := p.String("pattern", "")
+		if err != nil {
+			return nil, err
+		}
+		return NewRegexNotMatch(pattern)
+	})
+	evaluation.Register("email_format", simple(func() evaluation.Metric { return NewEmailFormat() }))
+	evaluation.Register("url_format", simple(func() evaluation.Metric { return NewURLFormat() }))
+	evaluation.Register("phone_format", simple(func() evaluation.Metric { return NewPhoneFormat() }))
+	evaluation.Register("date_format", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		pattern, err := p.String("pattern", "")
+		if err != nil {
+			return nil, err
+		}
+		if pattern == "" {
+			return NewDateFormat(), nil
+		}
+		return NewDateFormatWithPattern(pattern)
+	})
+	evaluation.Register("uuid_format", simple(func() evaluation.Metric { return NewUUIDFormat() }))
+
+	// Similarity metrics
+	evaluation.Register("levenshtein_similarity", caseSensitive(func(cs bool) evaluation.Metric {
+		return NewLevenshteinSimilarity(cs)
+	}))
+	evaluation.Register("jaccard_similarity", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		cs, err := p.Bool("case_sensitive", false)
+		if err != nil {
+			return nil, err
+		}
+		words, err := p.Bool("use_words", true)
+		if err != nil {
+			return nil, err
+		}
+		return NewJaccardSimilarity(cs, words), nil
+	})
+	evaluation.Register("cosine_similarity", caseSensitive(func(cs bool) evaluation.Metric {
+		return NewCosineSimilarity(cs)
+	}))
+	evaluation.Register("bleu", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		maxN, err := p.Int("max_n", 4)
+		if err != nil {
+			return nil, err
+		}
+		return NewBLEU(maxN), nil
+	})
+	evaluation.Register("rouge", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		beta, err := p.Float("beta", 1.0)
+		if err != nil {
+			return nil, err
+		}
+		return NewROUGE(beta), nil
+	})
+	evaluation.Register("fuzzy_match", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		threshold, err := p.Float("threshold", 0.8)
+		if err != nil {
+			return nil, err
+		}
+		cs, err := p.Bool("case_sensitive", false)
+		if err != nil {
+			return nil, err
+		}
+		return NewFuzzyMatch(threshold, cs), nil
+	})
+}
+
+// RegisterCodeExecutes registers CodeExecutes as "code_executes", so suite
+// files can run the code in outputs. It is not registered with the other
+// metrics, since it runs whatever the model wrote: call it once, before
+// loading suites, in programs that evaluate code. opts apply to
+// every metric it creates, before the params; pass WithCodeRunner to
+// choose how programs are isolated. It panics if called twice.
+//
+// The params are language, timeout_seconds (default 10), interpreters,
+// max_memory_mb, and max_cpu_seconds.
+func RegisterCodeExecutes(opts ...CodeExecutesOption) {
+	evaluation.Register("code_executes", codeExecutesFactory(opts...))
+}
+
+func codeExecutesFactory(base ...CodeExecutesOption) evaluation.MetricFactory {
+	return func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		lang, err := p.String("language", "")
+		if err != nil {
+			return nil, err
+		}
+		timeout, err := p.Float("timeout_seconds", 10)
+		if err != nil {
+			return nil, err
+		}
+		opts := slices.Clip(base)
+		interpreters, err := p.Strings("interpreters")
+		if err != nil {
+			return nil, err
+		}
+		if interpreters != nil {
+			opts = append(opts, WithAllowedInterpreters(interpreters...))
+		}
+		var limits CodeLimits
+		memoryMB, err := p.Int("max_memory_mb", 0)
+		if err != nil {
+			return nil, err
+		}
+		cpuSeconds, err := p.Int("max_cpu_seconds", 0)
+		if err != nil {
+			return nil, err
+		}
+		limits.MaxMemoryBytes = int64(memoryMB) << 20
+		limits.MaxCPUTime = time.Duration(cpuSeconds) * time.Second
+		opts = append(opts, WithCodeLimits(limits))
+		return NewCodeExecutes(lang, time.Duration(timeout*float64(time.Second)), opts...)
+	}
+}
+
+func simple(fn func() evaluation.Metric) evaluation.MetricFactory {
+	return func(evaluation.MetricParams) (evaluation.Metric, error) {
+		return fn(), nil
+	}
+}
+
+func caseSensitive(fn func(caseSensitive bool) evaluation.Metric) evaluation.MetricFactory {
+	return func(p evaluation.MetricParams) (evaluation.Metric, error) {
+		cs, err := p.Bool("case_sensitive", false)
+		if err != nil {
+			return nil, err
+		}
+		return fn(cs), nil
+	}
+}
+
+func valuesAndCase(p evaluation.MetricParams) ([]string, bool, error) {
+	values, err := p.Strings("values")
+	if err != nil {
+		return nil, false, err
+	}
+	cs, err := p.Bool("case_sensitive", false)
+	if err != nil {
+		return nil, false, err
+	}
+	return values, cs, nil
+}
+
+func minMax(p evaluation.MetricParams) (int, int, error) {
+	minVal, err := p.Int("min", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	maxVal, err := p.Int("max", math.MaxInt)
+	if err != nil {
+		return 0, 0, err
+	}
+	return minVal, maxVal, nil
+}
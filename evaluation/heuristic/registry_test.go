@@ -0,0 +1,78 @@
+package heuristic
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+func TestRegisteredMetrics(t *testing.T) {
+	names := []string{
+		"equals", "contains", "not_empty", "length_between", "is_json",
+		"json_has_keys", "regex_match", "date_format", "bleu", "rouge", "fuzzy_match",
+	}
+	for _, name := range names {
+		if _, ok := evaluation.LookupMetric(name); !ok {
+			t.Errorf("metric %q not registered", name)
+		}
+	}
+}
+
+func TestRegisteredMetricParams(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("case sensitive", func(t *testing.T) {
+		m, err := evaluation.NewMetric("equals", evaluation.MetricParams{"case_sensitive": true})
+		if err != nil {
+			t.Fatalf("NewMetric error: %v", err)
+		}
+		input := evaluation.NewMetricInput("", "Paris").WithExpected("paris")
+		if got := m.Score(ctx, input).Value; got != 0 {
+			t.Errorf("case-sensitive equals = %v, want 0", got)
+		}
+	})
+
+	t.Run("length bounds", func(t *testing.T) {
+		m, err := evaluation.NewMetric("length_between", evaluation.MetricParams{"min": 2})
+		if err != nil {
+			t.Fatalf("NewMetric error: %v", err)
+		}
+		if got := m.Score(ctx, evaluation.NewMetricInput("", "a long output")).Value; got != 1 {
+			t.Errorf("length_between without max = %v, want 1", got)
+		}
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		if _, err := evaluation.NewMetric("regex_match", evaluation.MetricParams{"pattern": "("}); err == nil {
+			t.Error("expected error for invalid pattern")
+		}
+	})
+
+	t.Run("code executes", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("needs a Unix shell")
+		}
+		if _, ok := evaluation.LookupMetric("code_executes"); ok {
+			t.Fatal("code_executes registered without RegisterCodeExecutes")
+		}
+		factory := codeExecutesFactory(WithCodeRunner(ProcessRunner{}))
+		m, err := factory(evaluation.MetricParams{"language": "sh", "timeout_seconds": 5})
+		if err != nil {
+			t.Fatalf("factory error: %v", err)
+		}
+		if got := m.Score(ctx, evaluation.NewMetricInput("", "echo ok").WithExpected("ok")).Value; got != 1 {
+			t.Errorf("code_executes = %v, want 1", got)
+		}
+		if _, err := factory(evaluation.MetricParams{"language": "sh", "interpreters": []any{"node"}}); err == nil {
+			t.Error("expected error for an interpreter not in the allowlist")
+		}
+	})
+
+	t.Run("bad param type", func(t *testing.T) {
+		if _, err := evaluation.NewMetric("contains_any", evaluation.MetricParams{"values": "x"}); err == nil {
+			t.Error("expected error for non-list values")
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"unicode"
 
 	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/textnorm"
 )
 
 // LevenshteinSimilarity calculates similarity based on Levenshtein distance.
@@ -27,8 +28,8 @@ func NewLevenshteinSimilarity(caseSensitive bool) *LevenshteinSimilarity {
 func (m *LevenshteinSimilarity) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
 	s1, s2 := input.Output, input.Expected
 	if !m.caseSensitive {
-		s1 = strings.ToLower(s1)
-		s2 = strings.ToLower(s2)
+		s1 = textnorm.FoldCase(s1)
+		s2 = textnorm.FoldCase(s2)
 	}
 
 	distance := levenshteinDistance(s1, s2)
@@ -104,8 +105,8 @@ func NewJaccardSimilarity(caseSensitive, useWords bool) *JaccardSimilarity {
 func (m *JaccardSimilarity) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
 	s1, s2 := input.Output, input.Expected
 	if !m.caseSensitive {
-		s1 = strings.ToLower(s1)
-		s2 = strings.ToLower(s2)
+		s1 = textnorm.FoldCase(s1)
+		s2 = textnorm.FoldCase(s2)
 	}
 
 	var set1, set2 map[string]bool
@@ -133,7 +134,7 @@ func (m *JaccardSimilarity) Score(ctx context.Context, input evaluation.MetricIn
 }
 
 func wordSet(s string) map[string]bool {
-	words := strings.Fields(s)
+	words := textnorm.Words(s)
 	set := make(map[string]bool, len(words))
 	for _, w := range words {
 		set[w] = true
@@ -167,8 +168,8 @@ func NewCosineSimilarity(caseSensitive bool) *CosineSimilarity {
 func (m *CosineSimilarity) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
 	s1, s2 := input.Output, input.Expected
 	if !m.caseSensitive {
-		s1 = strings.ToLower(s1)
-		s2 = strings.ToLower(s2)
+		s1 = textnorm.FoldCase(s1)
+		s2 = textnorm.FoldCase(s2)
 	}
 
 	vec1 := wordFrequency(s1)
@@ -210,7 +211,7 @@ func (m *CosineSimilarity) Score(ctx context.Context, input evaluation.MetricInp
 }
 
 func wordFrequency(s string) map[string]int {
-	words := strings.Fields(s)
+	words := textnorm.Words(s)
 	freq := make(map[string]int, len(words))
 	for _, w := range words {
 		// Remove punctuation
@@ -242,14 +243,18 @@ func NewBLEU(maxN int) *BLEU {
 	}
 }
 
-// Score calculates the BLEU score between output and expected.
+// Score calculates the BLEU score between output and expected. Without an
+// expected output, the input is not scored.
 func (m *BLEU) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
-	candidate := strings.ToLower(input.Output)
-	reference := strings.ToLower(input.Expected)
+	candidate := textnorm.FoldCase(input.Output)
+	reference := textnorm.FoldCase(input.Expected)
 
-	candWords := strings.Fields(candidate)
-	refWords := strings.Fields(reference)
+	candWords := textnorm.Words(candidate)
+	refWords := textnorm.Words(reference)
 
+	if len(refWords) == 0 {
+		return evaluation.NewNotScoredResult(m.Name(), "no expected output")
+	}
 	if len(candWords) == 0 {
 		return evaluation.NewScoreResult(m.Name(), 0.0)
 	}
@@ -333,18 +338,19 @@ func NewROUGE(beta float64) *ROUGE {
 	}
 }
 
-// Score calculates the ROUGE-L score between output and expected.
+// Score calculates the ROUGE-L score between output and expected. Without
+// an expected output, the input is not scored.
 func (m *ROUGE) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
-	candidate := strings.ToLower(input.Output)
-	reference := strings.ToLower(input.Expected)
+	candidate := textnorm.FoldCase(input.Output)
+	reference := textnorm.FoldCase(input.Expected)
 
-	candWords := strings.Fields(candidate)
-	refWords := strings.Fields(reference)
+	candWords := textnorm.Words(candidate)
+	refWords := textnorm.Words(reference)
 
-	if len(candWords) == 0 || len(refWords) == 0 {
-		if len(candWords) == 0 && len(refWords) == 0 {
-			return evaluation.NewScoreResult(m.Name(), 1.0)
-		}
+	if len(refWords) == 0 {
+		return evaluation.NewNotScoredResult(m.Name(), "no expected output")
+	}
+	if len(candWords) == 0 {
 		return evaluation.NewScoreResult(m.Name(), 0.0)
 	}
 
@@ -362,7 +368,9 @@ func (m *ROUGE) Score(ctx context.Context, input evaluation.MetricInput) *evalua
 	betaSq := m.beta * m.beta
 	fScore := ((1 + betaSq) * precision * recall) / (betaSq*precision + recall)
 
-	return evaluation.NewScoreResult(m.Name(), fScore)
+	return evaluation.NewScoreResult(m.Name(), fScore).
+		WithSubScore("precision", precision).
+		WithSubScore("recall", recall)
 }
 
 func lcsLength(a, b []string) int {
@@ -405,8 +413,8 @@ func NewFuzzyMatch(threshold float64, caseSensitive bool) *FuzzyMatch {
 func (m *FuzzyMatch) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
 	s1, s2 := input.Output, input.Expected
 	if !m.caseSensitive {
-		s1 = strings.ToLower(s1)
-		s2 = strings.ToLower(s2)
+		s1 = textnorm.FoldCase(s1)
+		s2 = textnorm.FoldCase(s2)
 	}
 
 	// Combine multiple similarity measures
@@ -421,6 +429,15 @@ func (m *FuzzyMatch) Score(ctx context.Context, input evaluation.MetricInput) *e
 	return evaluation.NewScoreResultWithReason(m.Name(), avgScore, "fuzzy match below threshold")
 }
 
+// Validate reports a threshold outside [0, 1], which no score can cross.
+func (m *FuzzyMatch) Validate() error {
+	var p evaluation.Problems
+	if m.threshold < 0 || m.threshold > 1 {
+		p.Addf("threshold must be between 0 and 1: %v", m.threshold)
+	}
+	return p.Err()
+}
+
 // SemanticSimilarity is a placeholder for embedding-based semantic similarity.
 // In a full implementation, this would use an embedding model.
 type SemanticSimilarity struct {
@@ -107,6 +107,7 @@ func TestCosineSimilarity(t *testing.T) {
 		{"identical", "hello world", "hello world", 1.0},
 		{"no overlap", "aaa bbb", "ccc ddd", 0.0},
 		{"partial overlap", "hello world", "hello there", 0.5},
+		{"chinese characters", "我爱北京", "我爱上海", 0.5},
 	}
 
 	for _, tt := range tests {
@@ -147,6 +148,18 @@ func TestBLEU(t *testing.T) {
 	}
 }
 
+func TestReferenceMetricsNotScoredWithoutExpected(t *testing.T) {
+	ctx := context.Background()
+	for _, metric := range []evaluation.Metric{NewBLEU(4), NewROUGE(1.0)} {
+		for _, output := range []string{"the cat sat", ""} {
+			result := metric.Score(ctx, evaluation.NewMetricInput("", output))
+			if !result.IsNotScored() {
+				t.Errorf("%s(%q) without expected = %+v, want not scored", metric.Name(), output, result)
+			}
+		}
+	}
+}
+
 func TestROUGE(t *testing.T) {
 	ctx := context.Background()
 
@@ -167,6 +180,15 @@ func TestROUGE(t *testing.T) {
 			t.Errorf("ROUGE = %v, want between 0.3 and 0.9", result.Value)
 		}
 	})
+
+	t.Run("ROUGE sub-scores", func(t *testing.T) {
+		metric := NewROUGE(1.0)
+		input := evaluation.NewMetricInput("", "the cat sat").WithExpected("the cat sat on the mat")
+		result := metric.Score(ctx, input)
+		if result.SubScores["precision"] != 1.0 || result.SubScores["recall"] != 0.5 {
+			t.Errorf("SubScores = %v, want precision 1.0 and recall 0.5", result.SubScores)
+		}
+	})
 }
 
 func TestFuzzyMatch(t *testing.T) {
@@ -4,8 +4,10 @@ import (
 	"context"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/textnorm"
 )
 
 // Equals checks if the output exactly matches the expected value.
@@ -28,8 +30,8 @@ func (m *Equals) Score(ctx context.Context, input evaluation.MetricInput) *evalu
 	expected := input.Expected
 
 	if !m.caseSensitive {
-		output = strings.ToLower(output)
-		expected = strings.ToLower(expected)
+		output = textnorm.FoldCase(output)
+		expected = textnorm.FoldCase(expected)
 	}
 
 	if output == expected {
@@ -38,6 +40,16 @@ func (m *Equals) Score(ctx context.Context, input evaluation.MetricInput) *evalu
 	return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "no match")
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *Equals) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{
+		Name:           m.Name(),
+		Kind:           "equals",
+		Params:         map[string]any{"case_sensitive": m.caseSensitive},
+		NeedsReference: true,
+	}
+}
+
 // Contains checks if the output contains the expected value.
 type Contains struct {
 	evaluation.BaseMetric
@@ -58,8 +70,8 @@ func (m *Contains) Score(ctx context.Context, input evaluation.MetricInput) *eva
 	expected := input.Expected
 
 	if !m.caseSensitive {
-		output = strings.ToLower(output)
-		expected = strings.ToLower(expected)
+		output = textnorm.FoldCase(output)
+		expected = textnorm.FoldCase(expected)
 	}
 
 	if strings.Contains(output, expected) {
@@ -68,6 +80,16 @@ func (m *Contains) Score(ctx context.Context, input evaluation.MetricInput) *eva
 	return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "does not contain expected value")
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *Contains) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{
+		Name:           m.Name(),
+		Kind:           "contains",
+		Params:         map[string]any{"case_sensitive": m.caseSensitive},
+		NeedsReference: true,
+	}
+}
+
 // StartsWith checks if the output starts with the expected value.
 type StartsWith struct {
 	evaluation.BaseMetric
@@ -88,8 +110,8 @@ func (m *StartsWith) Score(ctx context.Context, input evaluation.MetricInput) *e
 	expected := input.Expected
 
 	if !m.caseSensitive {
-		output = strings.ToLower(output)
-		expected = strings.ToLower(expected)
+		output = textnorm.FoldCase(output)
+		expected = textnorm.FoldCase(expected)
 	}
 
 	if strings.HasPrefix(output, expected) {
@@ -98,6 +120,16 @@ func (m *StartsWith) Score(ctx context.Context, input evaluation.MetricInput) *e
 	return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "does not start with expected value")
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *StartsWith) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{
+		Name:           m.Name(),
+		Kind:           "starts_with",
+		Params:         map[string]any{"case_sensitive": m.caseSensitive},
+		NeedsReference: true,
+	}
+}
+
 // EndsWith checks if the output ends with the expected value.
 type EndsWith struct {
 	evaluation.BaseMetric
@@ -118,8 +150,8 @@ func (m *EndsWith) Score(ctx context.Context, input evaluation.MetricInput) *eva
 	expected := input.Expected
 
 	if !m.caseSensitive {
-		output = strings.ToLower(output)
-		expected = strings.ToLower(expected)
+		output = textnorm.FoldCase(output)
+		expected = textnorm.FoldCase(expected)
 	}
 
 	if strings.HasSuffix(output, expected) {
@@ -128,6 +160,16 @@ func (m *EndsWith) Score(ctx context.Context, input evaluation.MetricInput) *eva
 	return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "does not end with expected value")
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *EndsWith) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{
+		Name:           m.Name(),
+		Kind:           "ends_with",
+		Params:         map[string]any{"case_sensitive": m.caseSensitive},
+		NeedsReference: true,
+	}
+}
+
 // ContainsAny checks if the output contains any of the specified values.
 type ContainsAny struct {
 	evaluation.BaseMetric
@@ -148,13 +190,13 @@ func NewContainsAny(values []string, caseSensitive bool) *ContainsAny {
 func (m *ContainsAny) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
 	output := input.Output
 	if !m.caseSensitive {
-		output = strings.ToLower(output)
+		output = textnorm.FoldCase(output)
 	}
 
 	for _, v := range m.values {
 		check := v
 		if !m.caseSensitive {
-			check = strings.ToLower(v)
+			check = textnorm.FoldCase(v)
 		}
 		if strings.Contains(output, check) {
 			return evaluation.NewScoreResultWithReason(m.Name(), 1.0, "contains: "+v)
@@ -163,6 +205,15 @@ func (m *ContainsAny) Score(ctx context.Context, input evaluation.MetricInput) *
 	return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "does not contain any expected value")
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *ContainsAny) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{
+		Name:   m.Name(),
+		Kind:   "contains_any",
+		Params: map[string]any{"values": m.values, "case_sensitive": m.caseSensitive},
+	}
+}
+
 // ContainsAll checks if the output contains all of the specified values.
 type ContainsAll struct {
 	evaluation.BaseMetric
@@ -183,14 +234,14 @@ func NewContainsAll(values []string, caseSensitive bool) *ContainsAll {
 func (m *ContainsAll) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
 	output := input.Output
 	if !m.caseSensitive {
-		output = strings.ToLower(output)
+		output = textnorm.FoldCase(output)
 	}
 
 	missing := []string{}
 	for _, v := range m.values {
 		check := v
 		if !m.caseSensitive {
-			check = strings.ToLower(v)
+			check = textnorm.FoldCase(v)
 		}
 		if !strings.Contains(output, check) {
 			missing = append(missing, v)
@@ -208,6 +259,15 @@ func (m *ContainsAll) Score(ctx context.Context, input evaluation.MetricInput) *
 		"missing: "+strings.Join(missing, ", "))
 }
 
+// RuleSpec implements evaluation.RuleSpecer.
+func (m *ContainsAll) RuleSpec() evaluation.RuleSpec {
+	return evaluation.RuleSpec{
+		Name:   m.Name(),
+		Kind:   "contains_all",
+		Params: map[string]any{"values": m.values, "case_sensitive": m.caseSensitive},
+	}
+}
+
 // NotEmpty checks if the output is not empty.
 type NotEmpty struct {
 	evaluation.BaseMetric
@@ -228,7 +288,8 @@ func (m *NotEmpty) Score(ctx context.Context, input evaluation.MetricInput) *eva
 	return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "output is empty")
 }
 
-// LengthBetween checks if the output length is within a range.
+// LengthBetween checks if the output length, in characters rather than
+// bytes, is within a range.
 type LengthBetween struct {
 	evaluation.BaseMetric
 	min int
@@ -246,7 +307,7 @@ func NewLengthBetween(min, max int) *LengthBetween {
 
 // Score evaluates if output length is within range.
 func (m *LengthBetween) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
-	length := len(input.Output)
+	length := utf8.RuneCountInString(input.Output)
 	if length >= m.min && length <= m.max {
 		return evaluation.NewScoreResultWithReason(m.Name(), 1.0, "length within range")
 	}
@@ -254,7 +315,21 @@ func (m *LengthBetween) Score(ctx context.Context, input evaluation.MetricInput)
 		"length out of range: "+strconv.Itoa(length))
 }
 
-// WordCount checks if the output word count is within a range.
+// Validate reports a negative minimum or a maximum below the minimum.
+func (m *LengthBetween) Validate() error {
+	var p evaluation.Problems
+	if m.min < 0 {
+		p.Addf("min must not be negative: %d", m.min)
+	}
+	if m.max < m.min {
+		p.Addf("max %d is below min %d", m.max, m.min)
+	}
+	return p.Err()
+}
+
+// WordCount checks if the output word count is within a range. Words are
+// split with textnorm.Words, so each Chinese or Japanese character counts as
+// a word.
 type WordCount struct {
 	evaluation.BaseMetric
 	min int
@@ -272,7 +347,7 @@ func NewWordCount(min, max int) *WordCount {
 
 // Score evaluates if output word count is within range.
 func (m *WordCount) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
-	words := strings.Fields(input.Output)
+	words := textnorm.Words(input.Output)
 	count := len(words)
 	if count >= m.min && count <= m.max {
 		return evaluation.NewScoreResultWithReason(m.Name(), 1.0, "word count within range")
@@ -280,6 +355,18 @@ func (m *WordCount) Score(ctx context.Context, input evaluation.MetricInput) *ev
 	return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "word count out of range")
 }
 
+// Validate reports a negative minimum or a maximum below the minimum.
+func (m *WordCount) Validate() error {
+	var p evaluation.Problems
+	if m.min < 0 {
+		p.Addf("min must not be negative: %d", m.min)
+	}
+	if m.max < m.min {
+		p.Addf("max %d is below min %d", m.max, m.min)
+	}
+	return p.Err()
+}
+
 // NoOffensiveLanguage checks for offensive language patterns.
 type NoOffensiveLanguage struct {
 	evaluation.BaseMetric
@@ -296,9 +383,9 @@ func NewNoOffensiveLanguage(patterns []string) *NoOffensiveLanguage {
 
 // Score evaluates if output contains offensive language.
 func (m *NoOffensiveLanguage) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
-	lower := strings.ToLower(input.Output)
+	lower := textnorm.FoldCase(input.Output)
 	for _, pattern := range m.patterns {
-		if strings.Contains(lower, strings.ToLower(pattern)) {
+		if strings.Contains(lower, textnorm.FoldCase(pattern)) {
 			return evaluation.NewScoreResultWithReason(m.Name(), 0.0, "contains offensive pattern")
 		}
 	}
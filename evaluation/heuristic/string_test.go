@@ -49,6 +49,8 @@ func TestEqualsIgnoreCase(t *testing.T) {
 		{"exact match", "hello", "hello", 1.0},
 		{"case insensitive match", "Hello", "hello", 1.0},
 		{"mixed case match", "HeLLo WoRLD", "hello world", 1.0},
+		{"case folding", "STRASSE", "straße", 1.0},
+		{"greek final sigma", "ΟΔΟΣ", "οδος", 1.0},
 		{"no match", "hello", "world", 0.0},
 	}
 
@@ -264,6 +266,7 @@ func TestLengthBetween(t *testing.T) {
 		{"at max", "1234567890", 1.0},
 		{"too short", "hi", 0.0},
 		{"too long", "hello world!", 0.0},
+		{"counts characters", "こんにちは", 1.0},
 	}
 
 	for _, tt := range tests {
@@ -292,6 +295,9 @@ func TestWordCount(t *testing.T) {
 		{"too few", "hello", 0.0},
 		{"too many", "one two three four five six", 0.0},
 		{"empty", "", 0.0},
+		{"chinese characters", "我爱北京。", 1.0},
+		{"chinese too many", "我爱北京天安门", 0.0},
+		{"korean words", "안녕하세요 세계", 1.0},
 	}
 
 	for _, tt := range tests {
@@ -355,3 +361,30 @@ func TestMetricName(t *testing.T) {
 		})
 	}
 }
+
+func TestRangeMetricsValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		metric evaluation.Validator
+		want   int
+	}{
+		{"length ok", NewLengthBetween(0, 10), 0},
+		{"length inverted", NewLengthBetween(10, 5), 1},
+		{"words negative and inverted", NewWordCount(-1, -2), 2},
+		{"fuzzy threshold", NewFuzzyMatch(1.5, false), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.metric.Validate()
+			got := 0
+			if verr, ok := err.(*evaluation.ValidationError); ok {
+				got = len(verr.Problems)
+			} else if err != nil {
+				t.Fatalf("Validate error = %v, want *evaluation.ValidationError", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d problems, want %d: %v", got, tt.want, err)
+			}
+		})
+	}
+}
@@ -2,13 +2,18 @@ package llm
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/textnorm"
 )
 
 // BaseJudge provides common functionality for LLM-based evaluation metrics.
@@ -17,6 +22,18 @@ type BaseJudge struct {
 	provider    Provider
 	model       string
 	temperature float64
+	window      *WindowConfig
+	maxPrompt   int
+	prompt      *judgePrompt
+	language    string
+	rationale   bool
+	// check validates a parsed response, which ScoreWithRetry retries if
+	// it fails; it may complete the response, as ReferenceGrader sets the
+	// score of a grade.
+	check func(*ScoreResponse) error
+	// grades, if set, are the grades the judge responds with in place of
+	// a score, for the schema of a StructuredProvider.
+	grades []string
 }
 
 // NewBaseJudge creates a new base judge.
@@ -24,9 +41,11 @@ func NewBaseJudge(name string, provider Provider, opts ...JudgeOption) *BaseJudg
 	j := &BaseJudge{
 		BaseMetric:  evaluation.NewBaseMetric(name),
 		provider:    provider,
-		model:       provider.DefaultModel(),
 		temperature: 0.0, // Deterministic by default for evaluation
 	}
+	if provider != nil {
+		j.model = provider.DefaultModel()
+	}
 
 	for _, opt := range opts {
 		opt(j)
@@ -52,6 +71,43 @@ func WithJudgeTemperature(temp float64) JudgeOption {
 	}
 }
 
+// WithMaxPromptTokens limits the prompt sent to the judge to about
+// maxTokens tokens, estimated as four characters each. A longer
+// conversation is shortened with TruncateMessages, keeping the system
+// messages and the latest user turn, and the score's metadata records the
+// number of messages left out under MetadataOmittedMessages.
+func WithMaxPromptTokens(maxTokens int) JudgeOption {
+	return func(j *BaseJudge) {
+		j.maxPrompt = maxTokens
+	}
+}
+
+// Validate reports a missing provider, a negative temperature or prompt
+// limit, an invalid context window, an incomplete prompt reference, and an
+// invalid language tag.
+func (j *BaseJudge) Validate() error {
+	var p evaluation.Problems
+	if j.provider == nil {
+		p.Addf("judge has no provider")
+	}
+	if j.temperature < 0 {
+		p.Addf("temperature must not be negative: %v", j.temperature)
+	}
+	if j.maxPrompt < 0 {
+		p.Addf("max prompt tokens must not be negative: %d", j.maxPrompt)
+	}
+	if j.window != nil {
+		validateWindow(&p, j.window)
+	}
+	if j.prompt != nil {
+		validatePrompt(&p, j.prompt)
+	}
+	if j.language != "" {
+		validateLanguage(&p, j.language)
+	}
+	return p.Err()
+}
+
 // Provider returns the LLM provider.
 func (j *BaseJudge) Provider() Provider {
 	return j.provider
@@ -62,13 +118,143 @@ func (j *BaseJudge) Model() string {
 	return j.model
 }
 
-// Complete sends a completion request to the provider.
+// Temperature returns the sampling temperature.
+func (j *BaseJudge) Temperature() float64 {
+	return j.temperature
+}
+
+// Language returns the language tag set with WithJudgeLanguage, or "".
+func (j *BaseJudge) Language() string {
+	return j.language
+}
+
+// NewScoreResult creates a score result from a judge response, including its provenance.
+func (j *BaseJudge) NewScoreResult(sr *ScoreResponse) *evaluation.ScoreResult {
+	result := evaluation.NewScoreResultWithReason(j.Name(), sr.Score, sr.Reason)
+	result.Provenance = sr.Provenance
+	result.Confidence = sr.Confidence
+	result.SubScores = sr.SubScores
+	if sr.Provenance != nil && sr.Provenance.OmittedMessages > 0 {
+		result.Metadata = map[string]any{MetadataOmittedMessages: sr.Provenance.OmittedMessages}
+	}
+	if len(sr.Steps) > 0 {
+		if result.Metadata == nil {
+			result.Metadata = make(map[string]any)
+		}
+		result.Metadata[MetadataRationaleSteps] = sr.Steps
+	}
+	return result
+}
+
+// Complete sends a completion request to the provider. If ctx carries an
+// evaluation.Coalescer, identical requests from other metrics share one
+// provider call; see CoalesceKey. If ctx carries an evaluation.Budget, the
+// request is only sent while the budget lasts, and evaluation.ErrBudgetExhausted
+// is returned after. During an evaluation.Engine dry run it records the
+// request and returns evaluation.ErrDryRun instead. A judge created with
+// WithMaxPromptTokens first shortens messages to fit.
 func (j *BaseJudge) Complete(ctx context.Context, messages []Message) (*CompletionResponse, error) {
-	return j.provider.Complete(ctx, CompletionRequest{
+	return j.completeSchema(ctx, messages, nil)
+}
+
+// completeSchema sends messages as Complete does, with CompleteStructured
+// if schema is not nil.
+func (j *BaseJudge) completeSchema(ctx context.Context, messages []Message, schema *ResponseSchema) (*CompletionResponse, error) {
+	messages, omitted := TruncateMessages(messages, j.maxPrompt, func(m Message) int { return estimateTokens(m.Content) })
+	resp, err := j.completeRequest(ctx, messages, schema)
+	if err != nil {
+		return nil, err
+	}
+	resp.OmittedMessages = omitted
+	return resp, nil
+}
+
+// completeRequest sends messages, coalescing identical requests.
+func (j *BaseJudge) completeRequest(ctx context.Context, messages []Message, schema *ResponseSchema) (*CompletionResponse, error) {
+	req := CompletionRequest{
 		Messages:    messages,
 		Model:       j.model,
 		Temperature: j.temperature,
+		Metric:      j.Name(),
+	}
+	if evaluation.RecordPromptPreview(ctx, previewRequest(j.provider, req)) {
+		return nil, evaluation.ErrDryRun
+	}
+	c := evaluation.CoalescerFromContext(ctx)
+	if c == nil {
+		return j.send(ctx, req, schema)
+	}
+
+	key := CoalesceKey(j.provider, req)
+	if schema != nil {
+		key += "\x00" + schemaHash(schema)
+	}
+	v, shared, err := c.Do(ctx, key, func() (any, error) {
+		return j.send(ctx, req, schema)
 	})
+	if err != nil {
+		return nil, err
+	}
+	resp := *v.(*CompletionResponse)
+	resp.Coalesced = shared
+	return &resp, nil
+}
+
+// send sends req to the provider, charging its tokens to the budget of ctx.
+// A request with a schema is sent with CompleteStructured.
+func (j *BaseJudge) send(ctx context.Context, req CompletionRequest, schema *ResponseSchema) (*CompletionResponse, error) {
+	budget := evaluation.BudgetFromContext(ctx)
+	if budget == nil {
+		return j.call(ctx, req, schema)
+	}
+	if budget.Exhausted() {
+		return nil, evaluation.ErrBudgetExhausted
+	}
+	resp, err := j.call(ctx, req, schema)
+	if err == nil {
+		budget.Spend(resp.PromptTokens + resp.OutputTokens)
+	}
+	return resp, err
+}
+
+// call sends req to the provider.
+func (j *BaseJudge) call(ctx context.Context, req CompletionRequest, schema *ResponseSchema) (*CompletionResponse, error) {
+	if sp, ok := j.provider.(StructuredProvider); ok && schema != nil {
+		return sp.CompleteStructured(ctx, req, *schema)
+	}
+	return j.provider.Complete(ctx, req)
+}
+
+// previewRequest converts a request to a dry-run preview.
+func previewRequest(provider Provider, req CompletionRequest) evaluation.PromptPreview {
+	messages := make([]evaluation.PromptMessage, len(req.Messages))
+	for i, msg := range req.Messages {
+		messages[i] = evaluation.PromptMessage{Role: msg.Role, Content: msg.Content}
+	}
+	return evaluation.PromptPreview{
+		Metric:      req.Metric,
+		Provider:    provider.Name(),
+		Model:       req.Model,
+		Temperature: req.Temperature,
+		Messages:    messages,
+	}
+}
+
+// complete sends a request without coalescing, for retries after a
+// response that could not be parsed: a shared response would fail again.
+func (j *BaseJudge) complete(ctx context.Context, messages []Message, attempt int, schema *ResponseSchema) (*CompletionResponse, error) {
+	if attempt > 0 {
+		ctx = evaluation.ContextWithCoalescer(ctx, nil)
+	}
+	return j.completeSchema(ctx, messages, schema)
+}
+
+// CoalesceKey returns the key under which a Coalescer shares a judge
+// request: the provider name, model, temperature, and prompt hash. The
+// metric name is not part of the key, so requests sent through a
+// RouterProvider are routed once for all metrics sharing them.
+func CoalesceKey(provider Provider, req CompletionRequest) string {
+	return fmt.Sprintf("%s\x00%s\x00%g\x00%s", provider.Name(), req.Model, req.Temperature, PromptHash(req.Messages))
 }
 
 // ParseScoreFromResponse extracts a numeric score from an LLM response.
@@ -104,7 +290,7 @@ func ParseScoreFromResponse(response string) (float64, error) {
 		return 0.0, nil
 	}
 
-	return 0, fmt.Errorf("could not parse score from response: %s", truncate(response, 100))
+	return 0, fmt.Errorf("could not parse score from response: %s", textnorm.Truncate(response, 100))
 }
 
 // ParseJSONResponse extracts JSON from an LLM response.
@@ -142,20 +328,25 @@ func ParseReasonFromResponse(response string) string {
 		return strings.TrimSpace(sentences[0])
 	}
 
-	return truncate(response, 200)
-}
-
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
+	return textnorm.Truncate(response, 200)
 }
 
 // ScoreResponse represents a structured scoring response.
 type ScoreResponse struct {
 	Score  float64 `json:"score"`
 	Reason string  `json:"reason,omitempty"`
+	// Confidence and SubScores are optional and copied to the score result.
+	Confidence *float64           `json:"confidence,omitempty"`
+	SubScores  map[string]float64 `json:"sub_scores,omitempty"`
+	// Steps are the steps of the judge's reasoning, requested with
+	// WithJudgeRationale, and are recorded in the score result's metadata.
+	Steps []RationaleStep `json:"steps,omitempty"`
+	// Grade is the named grade of a judge that grades on a scale, such as
+	// ReferenceGrader.
+	Grade string `json:"grade,omitempty"`
+
+	// Provenance is set by ScoreWithRetry and is not part of the judge's response.
+	Provenance *evaluation.Provenance `json:"-"`
 }
 
 // ParseScoreResponse parses a JSON score response.
@@ -185,25 +376,147 @@ func FormatPromptTemplate(template string, vars map[string]string) string {
 	return result
 }
 
-// ScoreWithRetry attempts to score with retries on failure.
+// ScoreWithRetry attempts to score with retries on failure. It stops
+// retrying once ctx is cancelled.
+// The returned response carries the provenance of the score: the judge model,
+// provider, prompt hash, temperature, retries used, token usage, and latency,
+// and the prompt version of a judge created with WithJudgePromptRef. A judge
+// created with WithJudgeRationale is asked for the steps of its reasoning,
+// and one created with WithJudgeLanguage to write its reason in that
+// language. If the judge's provider is a StructuredProvider, the score is
+// requested with a response schema, and responses that do not match it are
+// retried instead of parsed heuristically.
 func ScoreWithRetry(ctx context.Context, j *BaseJudge, messages []Message, maxRetries int) (*ScoreResponse, error) {
 	var lastErr error
+	if j.rationale {
+		messages = appendInstruction(messages, rationaleInstruction)
+	}
+	messages = j.localize(messages)
+	schema := j.responseSchema()
+
+	prov := &evaluation.Provenance{
+		Model:       j.model,
+		Provider:    j.provider.Name(),
+		PromptHash:  PromptHash(messages),
+		Temperature: j.temperature,
+	}
+	start := time.Now()
 
 	for i := 0; i < maxRetries; i++ {
-		resp, err := j.Complete(ctx, messages)
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("scoring canceled: %w", err)
+		}
+		prov.Retries = i
+
+		resp, err := j.complete(ctx, messages, i, schema)
+		if errors.Is(err, evaluation.ErrDryRun) || errors.Is(err, evaluation.ErrBudgetExhausted) {
+			return nil, err
+		}
 		if err != nil {
 			lastErr = err
 			continue
 		}
-
-		sr, err := ParseScoreResponse(resp.Content)
+		if resp.Coalesced {
+			prov.Coalesced = true
+		} else {
+			prov.PromptTokens += resp.PromptTokens
+			prov.OutputTokens += resp.OutputTokens
+		}
+		if resp.Model != "" {
+			prov.Model = resp.Model
+		}
+		prov.Route = resp.Route
+		prov.OmittedMessages = resp.OmittedMessages
+
+		var sr *ScoreResponse
+		if schema != nil {
+			sr, err = parseStructuredResponse(resp.Content, schema)
+		} else {
+			sr, err = ParseScoreResponse(resp.Content)
+		}
+		if err == nil && j.check != nil {
+			err = j.check(sr)
+		}
 		if err != nil {
 			lastErr = err
 			continue
 		}
 
+		prov.Latency = time.Since(start)
+		j.setPromptProvenance(prov)
+		sr.Provenance = prov
 		return sr, nil
 	}
 
 	return nil, fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr)
 }
+
+// completeJSON asks the judge for a JSON response and decodes it into v,
+// retrying on errors, responses that do not decode, and responses check
+// rejects, as ScoreWithRetry does for scores. check may be nil. It returns
+// the provenance of the response.
+func (j *BaseJudge) completeJSON(ctx context.Context, messages []Message, maxRetries int, v any, check func() error) (*evaluation.Provenance, error) {
+	var lastErr error
+
+	prov := &evaluation.Provenance{
+		Model:       j.model,
+		Provider:    j.provider.Name(),
+		PromptHash:  PromptHash(messages),
+		Temperature: j.temperature,
+	}
+	start := time.Now()
+
+	for i := 0; i < maxRetries; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("judge request canceled: %w", err)
+		}
+		prov.Retries = i
+
+		resp, err := j.complete(ctx, messages, i, nil)
+		if errors.Is(err, evaluation.ErrDryRun) || errors.Is(err, evaluation.ErrBudgetExhausted) {
+			return nil, err
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.Coalesced {
+			prov.Coalesced = true
+		} else {
+			prov.PromptTokens += resp.PromptTokens
+			prov.OutputTokens += resp.OutputTokens
+		}
+		if resp.Model != "" {
+			prov.Model = resp.Model
+		}
+		prov.Route = resp.Route
+
+		if err := ParseJSONResponse(resp.Content, v); err != nil {
+			lastErr = fmt.Errorf("could not parse judge response: %w", err)
+			continue
+		}
+		if check != nil {
+			if err := check(); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+
+		prov.Latency = time.Since(start)
+		return prov, nil
+	}
+
+	return nil, fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr)
+}
+
+// PromptHash returns a hex-encoded SHA-256 hash of the prompt messages.
+func PromptHash(messages []Message) string {
+	h := sha256.New()
+	for _, msg := range messages {
+		h.Write([]byte(msg.Role))
+		h.Write([]byte{0})
+		h.Write([]byte(msg.Content))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,280 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/plexusone/opik-go/internal/latency"
+)
+
+// LatencyStats summarizes a set of latency measurements: their minimum,
+// maximum, mean, and nearest-rank percentiles.
+type LatencyStats = latency.Stats
+
+// BenchmarkSample is the measurement for a single benchmark request.
+type BenchmarkSample struct {
+	// Prompt is the prompt that was sent.
+	Prompt string `json:"prompt"`
+	// Latency is the time until the complete response was received.
+	Latency time.Duration `json:"latency"`
+	// TimeToFirstToken is the time until the first streamed delta arrived.
+	// It is zero when the provider does not support streaming.
+	TimeToFirstToken time.Duration `json:"time_to_first_token,omitempty"`
+	// PromptTokens is the number of prompt tokens reported by the provider.
+	PromptTokens int `json:"prompt_tokens,omitempty"`
+	// OutputTokens is the number of output tokens reported by the provider.
+	OutputTokens int `json:"output_tokens,omitempty"`
+	// Error is set if the request failed.
+	Error error `json:"-"`
+}
+
+// BenchmarkReport is the result of benchmarking a provider.
+type BenchmarkReport struct {
+	// Provider is the provider name.
+	Provider string `json:"provider"`
+	// Model is the model that was benchmarked.
+	Model string `json:"model"`
+	// Concurrency is the number of requests that were in flight at once.
+	Concurrency int `json:"concurrency"`
+	// Requests is the total number of requests sent.
+	Requests int `json:"requests"`
+	// Errors is the number of failed requests.
+	Errors int `json:"errors"`
+	// ErrorRate is the fraction of requests that failed.
+	ErrorRate float64 `json:"error_rate"`
+	// Duration is the wall-clock duration of the whole benchmark.
+	Duration time.Duration `json:"duration"`
+	// Latency summarizes the latency of successful requests.
+	Latency LatencyStats `json:"latency"`
+	// TimeToFirstToken summarizes time to first token, or nil if the
+	// provider does not support streaming.
+	TimeToFirstToken *LatencyStats `json:"time_to_first_token,omitempty"`
+	// PromptTokens is the total number of prompt tokens.
+	PromptTokens int `json:"prompt_tokens"`
+	// OutputTokens is the total number of output tokens.
+	OutputTokens int `json:"output_tokens"`
+	// TokensPerSecond is the output token throughput across the benchmark.
+	TokensPerSecond float64 `json:"tokens_per_second"`
+	// RequestsPerSecond is the request throughput across the benchmark.
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	// Samples contains the individual measurements in prompt order.
+	Samples []BenchmarkSample `json:"-"`
+}
+
+// Metadata returns the report as a flat map suitable for experiment metadata.
+func (r *BenchmarkReport) Metadata() map[string]any {
+	m := map[string]any{
+		"benchmark.provider":            r.Provider,
+		"benchmark.model":               r.Model,
+		"benchmark.concurrency":         r.Concurrency,
+		"benchmark.requests":            r.Requests,
+		"benchmark.errors":              r.Errors,
+		"benchmark.error_rate":          r.ErrorRate,
+		"benchmark.duration_ms":         r.Duration.Milliseconds(),
+		"benchmark.latency_p50_ms":      r.Latency.P50.Milliseconds(),
+		"benchmark.latency_p90_ms":      r.Latency.P90.Milliseconds(),
+		"benchmark.latency_p99_ms":      r.Latency.P99.Milliseconds(),
+		"benchmark.prompt_tokens":       r.PromptTokens,
+		"benchmark.output_tokens":       r.OutputTokens,
+		"benchmark.tokens_per_second":   r.TokensPerSecond,
+		"benchmark.requests_per_second": r.RequestsPerSecond,
+	}
+	if r.TimeToFirstToken != nil {
+		m["benchmark.ttft_p50_ms"] = r.TimeToFirstToken.P50.Milliseconds()
+		m["benchmark.ttft_p90_ms"] = r.TimeToFirstToken.P90.Milliseconds()
+	}
+	return m
+}
+
+// String returns a human-readable summary of the report.
+func (r *BenchmarkReport) String() string {
+	s := fmt.Sprintf("%s/%s: %d requests (%.1f%% errors), p50=%s p90=%s p99=%s, %.1f tokens/s",
+		r.Provider, r.Model, r.Requests, r.ErrorRate*100,
+		r.Latency.P50, r.Latency.P90, r.Latency.P99, r.TokensPerSecond)
+	if r.TimeToFirstToken != nil {
+		s += fmt.Sprintf(", ttft p50=%s", r.TimeToFirstToken.P50)
+	}
+	return s
+}
+
+// BenchmarkOption configures a benchmark run.
+type BenchmarkOption func(*benchmarkConfig)
+
+type benchmarkConfig struct {
+	model     string
+	maxTokens int
+	streaming bool
+	reporter  func(ctx context.Context, report *BenchmarkReport) error
+}
+
+// WithBenchmarkModel sets the model to benchmark (defaults to the provider's default model).
+func WithBenchmarkModel(model string) BenchmarkOption {
+	return func(c *benchmarkConfig) {
+		c.model = model
+	}
+}
+
+// WithBenchmarkMaxTokens sets the maximum output tokens per request.
+func WithBenchmarkMaxTokens(max int) BenchmarkOption {
+	return func(c *benchmarkConfig) {
+		c.maxTokens = max
+	}
+}
+
+// WithBenchmarkStreaming enables or disables streaming for providers that support it.
+// Streaming is enabled by default so time to first token can be measured.
+func WithBenchmarkStreaming(enabled bool) BenchmarkOption {
+	return func(c *benchmarkConfig) {
+		c.streaming = enabled
+	}
+}
+
+// WithBenchmarkReporter sets a function that receives the finished report,
+// for example to log it as an Opik experiment:
+//
+//	llm.WithBenchmarkReporter(func(ctx context.Context, r *llm.BenchmarkReport) error {
+//	    _, err := client.CreateExperiment(ctx, "judge-benchmarks",
+//	        opik.WithExperimentName(r.Provider+"/"+r.Model),
+//	        opik.WithExperimentMetadata(r.Metadata()))
+//	    return err
+//	})
+func WithBenchmarkReporter(fn func(ctx context.Context, report *BenchmarkReport) error) BenchmarkOption {
+	return func(c *benchmarkConfig) {
+		c.reporter = fn
+	}
+}
+
+// Benchmark sends each prompt to the provider with the given concurrency and
+// measures latency, time to first token (when the provider implements
+// StreamingProvider), error rate, and token throughput.
+//
+// Failed requests are counted in the report rather than returned as errors.
+// An error is returned only if the context is cancelled or the reporter fails.
+func Benchmark(ctx context.Context, provider Provider, prompts []string, concurrency int, opts ...BenchmarkOption) (*BenchmarkReport, error) {
+	cfg := &benchmarkConfig{
+		model:     provider.DefaultModel(),
+		streaming: true,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	streamer, canStream := provider.(StreamingProvider)
+	canStream = canStream && cfg.streaming
+
+	samples := make([]BenchmarkSample, len(prompts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for i, prompt := range prompts {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(idx int, p string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			req := CompletionRequest{
+				Messages:  []Message{{Role: "user", Content: p}},
+				Model:     cfg.model,
+				MaxTokens: cfg.maxTokens,
+			}
+			sample := BenchmarkSample{Prompt: p}
+
+			reqStart := time.Now()
+			var resp *CompletionResponse
+			var err error
+			if canStream {
+				var once sync.Once
+				resp, err = streamer.CompleteStream(ctx, req, func(delta string) {
+					if delta == "" {
+						return
+					}
+					once.Do(func() {
+						sample.TimeToFirstToken = time.Since(reqStart)
+					})
+				})
+			} else {
+				resp, err = provider.Complete(ctx, req)
+			}
+			sample.Latency = time.Since(reqStart)
+
+			if err != nil {
+				sample.Error = err
+			} else if resp != nil {
+				sample.PromptTokens = resp.PromptTokens
+				sample.OutputTokens = resp.OutputTokens
+			}
+			samples[idx] = sample
+		}(i, prompt)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := buildBenchmarkReport(provider.Name(), cfg.model, concurrency, samples, elapsed, canStream)
+
+	if cfg.reporter != nil {
+		if err := cfg.reporter(ctx, report); err != nil {
+			return report, fmt.Errorf("benchmark reporter: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+func buildBenchmarkReport(providerName, model string, concurrency int, samples []BenchmarkSample, elapsed time.Duration, streamed bool) *BenchmarkReport {
+	report := &BenchmarkReport{
+		Provider:    providerName,
+		Model:       model,
+		Concurrency: concurrency,
+		Requests:    len(samples),
+		Duration:    elapsed,
+		Samples:     samples,
+	}
+
+	latencies := make([]time.Duration, 0, len(samples))
+	ttfts := make([]time.Duration, 0, len(samples))
+	for _, s := range samples {
+		if s.Error != nil {
+			report.Errors++
+			continue
+		}
+		latencies = append(latencies, s.Latency)
+		if s.TimeToFirstToken > 0 {
+			ttfts = append(ttfts, s.TimeToFirstToken)
+		}
+		report.PromptTokens += s.PromptTokens
+		report.OutputTokens += s.OutputTokens
+	}
+
+	if report.Requests > 0 {
+		report.ErrorRate = float64(report.Errors) / float64(report.Requests)
+	}
+	if secs := elapsed.Seconds(); secs > 0 {
+		report.TokensPerSecond = float64(report.OutputTokens) / secs
+		report.RequestsPerSecond = float64(report.Requests) / secs
+	}
+
+	report.Latency = latency.Summarize(latencies)
+	if streamed && len(ttfts) > 0 {
+		stats := latency.Summarize(ttfts)
+		report.TimeToFirstToken = &stats
+	}
+
+	return report
+}
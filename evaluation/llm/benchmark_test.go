@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type streamingMockProvider struct {
+	*SimpleProvider
+	chunks []string
+}
+
+func (p *streamingMockProvider) CompleteStream(ctx context.Context, req CompletionRequest, onChunk func(delta string)) (*CompletionResponse, error) {
+	for _, c := range p.chunks {
+		time.Sleep(time.Millisecond)
+		onChunk(c)
+	}
+	return &CompletionResponse{
+		Content:      strings.Join(p.chunks, ""),
+		PromptTokens: 3,
+		OutputTokens: len(p.chunks),
+	}, nil
+}
+
+func TestBenchmark(t *testing.T) {
+	var inFlight, maxInFlight int32
+	provider := NewSimpleProvider("test", "test-model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		if req.Messages[0].Content == "fail" {
+			return nil, errors.New("boom")
+		}
+		return &CompletionResponse{Content: "ok", PromptTokens: 5, OutputTokens: 10}, nil
+	})
+
+	prompts := []string{"a", "b", "fail", "c"}
+	report, err := Benchmark(context.Background(), provider, prompts, 2)
+	if err != nil {
+		t.Fatalf("Benchmark error: %v", err)
+	}
+
+	if report.Requests != 4 {
+		t.Errorf("Requests = %d, want 4", report.Requests)
+	}
+	if report.Errors != 1 {
+		t.Errorf("Errors = %d, want 1", report.Errors)
+	}
+	if report.ErrorRate != 0.25 {
+		t.Errorf("ErrorRate = %v, want 0.25", report.ErrorRate)
+	}
+	if report.OutputTokens != 30 {
+		t.Errorf("OutputTokens = %d, want 30", report.OutputTokens)
+	}
+	if report.PromptTokens != 15 {
+		t.Errorf("PromptTokens = %d, want 15", report.PromptTokens)
+	}
+	if report.Model != "test-model" {
+		t.Errorf("Model = %q, want %q", report.Model, "test-model")
+	}
+	if report.TimeToFirstToken != nil {
+		t.Error("TimeToFirstToken should be nil for non-streaming provider")
+	}
+	if report.Latency.P50 <= 0 || report.Latency.Max < report.Latency.Min {
+		t.Errorf("unexpected latency stats: %+v", report.Latency)
+	}
+	if report.TokensPerSecond <= 0 {
+		t.Errorf("TokensPerSecond = %v, want > 0", report.TokensPerSecond)
+	}
+	if atomic.LoadInt32(&maxInFlight) > 2 {
+		t.Errorf("max in flight = %d, want <= 2", maxInFlight)
+	}
+	if len(report.Samples) != 4 || report.Samples[2].Error == nil {
+		t.Error("samples should preserve prompt order and record errors")
+	}
+}
+
+func TestBenchmarkStreaming(t *testing.T) {
+	provider := &streamingMockProvider{
+		SimpleProvider: NewSimpleProvider("stream", "stream-model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+			return &CompletionResponse{Content: "hello"}, nil
+		}),
+		chunks: []string{"hel", "lo"},
+	}
+
+	report, err := Benchmark(context.Background(), provider, []string{"x", "y"}, 1)
+	if err != nil {
+		t.Fatalf("Benchmark error: %v", err)
+	}
+
+	if report.TimeToFirstToken == nil {
+		t.Fatal("TimeToFirstToken should be set for streaming provider")
+	}
+	if report.TimeToFirstToken.P50 > report.Latency.P50 {
+		t.Errorf("TTFT p50 %v should not exceed latency p50 %v", report.TimeToFirstToken.P50, report.Latency.P50)
+	}
+
+	t.Run("streaming disabled", func(t *testing.T) {
+		report, err := Benchmark(context.Background(), provider, []string{"x"}, 1, WithBenchmarkStreaming(false))
+		if err != nil {
+			t.Fatalf("Benchmark error: %v", err)
+		}
+		if report.TimeToFirstToken != nil {
+			t.Error("TimeToFirstToken should be nil when streaming is disabled")
+		}
+	})
+}
+
+func TestBenchmarkReporter(t *testing.T) {
+	provider := NewMockProvider(nil, "ok")
+
+	var got *BenchmarkReport
+	report, err := Benchmark(context.Background(), provider, []string{"a"}, 1,
+		WithBenchmarkModel("other-model"),
+		WithBenchmarkReporter(func(ctx context.Context, r *BenchmarkReport) error {
+			got = r
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Benchmark error: %v", err)
+	}
+	if got != report {
+		t.Error("reporter should receive the report")
+	}
+	if report.Model != "other-model" {
+		t.Errorf("Model = %q, want %q", report.Model, "other-model")
+	}
+
+	md := report.Metadata()
+	if md["benchmark.requests"] != 1 {
+		t.Errorf("metadata requests = %v, want 1", md["benchmark.requests"])
+	}
+
+	_, err = Benchmark(context.Background(), provider, []string{"a"}, 1,
+		WithBenchmarkReporter(func(ctx context.Context, r *BenchmarkReport) error {
+			return errors.New("log failed")
+		}),
+	)
+	if err == nil {
+		t.Error("expected reporter error to be returned")
+	}
+}
+
+func TestBenchmarkCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Benchmark(ctx, NewMockProvider(nil, "ok"), []string{"a", "b"}, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
@@ -11,6 +11,10 @@
 //   - MockProvider: For testing
 //   - CachingProvider: Wraps another provider with caching
 //
+// Providers implementing StructuredProvider constrain responses to a JSON
+// schema; judges then validate their scores against it instead of parsing
+// free text.
+//
 // # Available Metrics
 //
 //   - GEval: G-EVAL framework with chain-of-thought evaluation
@@ -22,6 +26,11 @@
 //   - Factuality: Factual accuracy evaluation
 //   - Coherence: Logical coherence assessment
 //   - Helpfulness: How helpful the response is
+//   - ReferenceGrader: Grades the response against the expected answer
+//   - Faithfulness: Fraction of the response's claims supported by the context chunks
+//   - AnswerCorrectness: Factuality and similarity against the expected answer
+//   - ContextRelevancy: Relevance of each retrieved context chunk to the input
+//   - PairwiseComparison: Which of two outputs is better, judged in both orders
 //   - CustomJudge: Create metrics with custom prompts
 //
 // # Usage Example
@@ -51,4 +60,13 @@
 //	Return your response in JSON format:
 //	{"score": <0.0-1.0>, "reason": "<explanation>"}
 //	`, provider)
+//
+// # Benchmarking Providers
+//
+// Benchmark measures latency percentiles, time to first token (for providers
+// implementing StreamingProvider), error rate, and token throughput, which
+// helps when choosing a judge model or gateway:
+//
+//	report, err := llm.Benchmark(ctx, provider, prompts, 8)
+//	fmt.Println(report)
 package llm
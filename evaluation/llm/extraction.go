@@ -0,0 +1,291 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/textnorm"
+)
+
+// FieldType is the type of a field in an extraction schema. It determines
+// how extracted values are compared to expected ones.
+type FieldType string
+
+const (
+	// FieldString compares normalized text, with partial credit for
+	// overlapping words.
+	FieldString FieldType = "string"
+	// FieldNumber compares numeric values, such as amounts or counts.
+	// Currency symbols, thousands separators and percent signs are ignored.
+	FieldNumber FieldType = "number"
+	// FieldDate compares calendar dates, ignoring the time of day.
+	FieldDate FieldType = "date"
+	// FieldList compares sets of values, such as entity names, by F1.
+	FieldList FieldType = "list"
+)
+
+// ExtractionField describes one field the judge extracts from the output.
+type ExtractionField struct {
+	// Name is the JSON key of the field in the extracted and expected values.
+	Name string
+	// Type determines how the field is compared. Defaults to FieldString.
+	Type FieldType
+	// Description tells the judge what to extract.
+	Description string
+	// Tolerance is the allowed relative difference for FieldNumber fields
+	// (e.g. 0.01 for 1%).
+	Tolerance float64
+}
+
+// ExtractionSchema is the set of fields an ExtractionJudge extracts.
+type ExtractionSchema []ExtractionField
+
+// dateLayouts are the formats accepted for FieldDate values.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+// ExtractionJudge asks the judge to extract structured fields from the
+// output and compares them to the expected values field by field. It is
+// useful for information extraction, where exact string match is too strict.
+//
+// Expected values are read from input.Expected as a JSON object keyed by
+// field name. Fields missing from the expected object are not scored.
+// The score is the mean of the per-field scores, which are reported as
+// sub-scores and in the "fields" metadata along with the "extracted" values.
+type ExtractionJudge struct {
+	*BaseJudge
+	schema ExtractionSchema
+}
+
+// NewExtractionJudge creates a schema-guided extraction judge.
+func NewExtractionJudge(provider Provider, schema ExtractionSchema, opts ...JudgeOption) *ExtractionJudge {
+	return &ExtractionJudge{
+		BaseJudge: NewBaseJudge("extraction", provider, opts...),
+		schema:    schema,
+	}
+}
+
+// Schema returns the fields the judge extracts.
+func (m *ExtractionJudge) Schema() ExtractionSchema {
+	return m.schema
+}
+
+// Score extracts the schema fields from the output and compares them to the
+// expected values. If there are no expected values for the schema's fields,
+// the input is not scored and the judge is not called.
+func (m *ExtractionJudge) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+	if strings.TrimSpace(input.Expected) == "" {
+		return evaluation.NewNotScoredResult(m.Name(), "no expected values")
+	}
+	var expected map[string]any
+	if err := json.Unmarshal([]byte(input.Expected), &expected); err != nil {
+		return evaluation.NewFailedScoreResult(m.Name(), fmt.Errorf("expected values must be a JSON object: %w", err))
+	}
+	if !slices.ContainsFunc(m.schema, func(f ExtractionField) bool {
+		_, ok := expected[f.Name]
+		return ok
+	}) {
+		return evaluation.NewNotScoredResult(m.Name(), "no expected values for schema fields")
+	}
+
+	var fieldList strings.Builder
+	for _, f := range m.schema {
+		fmt.Fprintf(&fieldList, "- %s (%s)", f.Name, fieldType(f))
+		if f.Description != "" {
+			fmt.Fprintf(&fieldList, ": %s", f.Description)
+		}
+		fieldList.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`Extract the following fields from the text.
+
+Fields:
+%s
+Text: %s
+
+Use null for fields that are not present. Write dates as YYYY-MM-DD, numbers without units, and lists as JSON arrays.
+
+Return only a JSON object with the field names as keys.`, fieldList.String(), input.Output)
+
+	messages := []Message{
+		{Role: "user", Content: prompt},
+	}
+
+	var extracted map[string]any
+	prov, err := m.completeJSON(ctx, messages, 3, &extracted, nil)
+	if err != nil {
+		return evaluation.NewFailedScoreResult(m.Name(), err)
+	}
+
+	scores := make(map[string]float64)
+	var total float64
+	var mismatched []string
+	for _, f := range m.schema {
+		want, ok := expected[f.Name]
+		if !ok {
+			continue
+		}
+		s := CompareField(f, extracted[f.Name], want)
+		scores[f.Name] = s
+		total += s
+		if s < 1 {
+			mismatched = append(mismatched, f.Name)
+		}
+	}
+
+	reason := fmt.Sprintf("%d/%d fields matched", len(scores)-len(mismatched), len(scores))
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		reason += "; mismatched: " + strings.Join(mismatched, ", ")
+	}
+
+	result := evaluation.NewScoreResultWithReason(m.Name(), total/float64(len(scores)), reason)
+	result.Metadata = map[string]any{
+		"fields":    scores,
+		"extracted": extracted,
+	}
+	result.SubScores = scores
+	result.Provenance = prov
+	return result
+}
+
+// CompareField scores an extracted value against the expected value for a
+// field, from 0.0 to 1.0. A missing (nil) value matches only a nil
+// expected value.
+func CompareField(field ExtractionField, got, want any) float64 {
+	if got == nil || want == nil {
+		if got == nil && want == nil {
+			return 1.0
+		}
+		return 0.0
+	}
+
+	switch fieldType(field) {
+	case FieldNumber:
+		g, ok1 := toNumber(got)
+		w, ok2 := toNumber(want)
+		if !ok1 || !ok2 {
+			return 0.0
+		}
+		if g == w || math.Abs(g-w) <= field.Tolerance*math.Abs(w) {
+			return 1.0
+		}
+		return 0.0
+	case FieldDate:
+		g, ok1 := toDate(got)
+		w, ok2 := toDate(want)
+		if !ok1 || !ok2 {
+			return 0.0
+		}
+		if g.Equal(w) {
+			return 1.0
+		}
+		return 0.0
+	case FieldList:
+		return setF1(toStrings(got), toStrings(want))
+	default:
+		g, w := normalizeValue(fmt.Sprint(got)), normalizeValue(fmt.Sprint(want))
+		if g == w {
+			return 1.0
+		}
+		return setF1(strings.Fields(g), strings.Fields(w))
+	}
+}
+
+func fieldType(f ExtractionField) FieldType {
+	if f.Type == "" {
+		return FieldString
+	}
+	return f.Type
+}
+
+func normalizeValue(s string) string {
+	return textnorm.CollapseWhitespace(strings.ToLower(s))
+}
+
+func toNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case string:
+		s := strings.NewReplacer("$", "", "€", "", "£", "", ",", "", "%", "", " ", "").Replace(n)
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toDate(v any) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			y, mo, d := t.Date()
+			return time.Date(y, mo, d, 0, 0, 0, 0, time.UTC), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func toStrings(v any) []string {
+	items, ok := v.([]any)
+	if !ok {
+		return []string{normalizeValue(fmt.Sprint(v))}
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			out = append(out, normalizeValue(fmt.Sprint(item)))
+		}
+	}
+	return out
+}
+
+// setF1 returns the F1 score of two sets of strings.
+func setF1(got, want []string) float64 {
+	if len(got) == 0 && len(want) == 0 {
+		return 1.0
+	}
+	wantSet := make(map[string]bool, len(want))
+	for _, w := range want {
+		wantSet[w] = true
+	}
+	gotSet := make(map[string]bool, len(got))
+	matched := 0
+	for _, g := range got {
+		if gotSet[g] {
+			continue
+		}
+		gotSet[g] = true
+		if wantSet[g] {
+			matched++
+		}
+	}
+	if matched == 0 {
+		return 0.0
+	}
+	precision := float64(matched) / float64(len(gotSet))
+	recall := float64(matched) / float64(len(wantSet))
+	return 2 * precision * recall / (precision + recall)
+}
@@ -0,0 +1,167 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+var invoiceSchema = ExtractionSchema{
+	{Name: "vendor", Type: FieldString, Description: "the company that issued the invoice"},
+	{Name: "total", Type: FieldNumber, Tolerance: 0.01},
+	{Name: "due", Type: FieldDate},
+	{Name: "items", Type: FieldList},
+}
+
+func TestExtractionJudge(t *testing.T) {
+	provider := NewMockProvider(nil, "```json\n"+`{"vendor": "Acme Corp", "total": "$1,204.50", "due": "2024-03-01", "items": ["Widgets", "gears"]}`+"\n```")
+	judge := NewExtractionJudge(provider, invoiceSchema)
+
+	input := evaluation.NewMetricInput("", "Acme Corp billed $1,204.50 for widgets and gears, due March 1, 2024.").
+		WithExpected(`{"vendor": "acme corp", "total": 1204.5, "due": "March 1, 2024", "items": ["widgets", "gears", "bolts"]}`)
+
+	result := judge.Score(context.Background(), input)
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+
+	fields, ok := result.Metadata["fields"].(map[string]float64)
+	if !ok {
+		t.Fatalf("fields metadata = %T", result.Metadata["fields"])
+	}
+	if fields["vendor"] != 1 || fields["total"] != 1 || fields["due"] != 1 {
+		t.Errorf("fields = %v, want vendor, total and due to match", fields)
+	}
+	// 2 of 2 extracted items are correct, 2 of 3 expected items were found.
+	if got := fields["items"]; got < 0.79 || got > 0.81 {
+		t.Errorf("items score = %v, want 0.8", got)
+	}
+	if result.Value < 0.94 || result.Value > 0.96 {
+		t.Errorf("Value = %v, want 0.95", result.Value)
+	}
+	if !strings.Contains(result.Reason, "3/4 fields matched") || !strings.Contains(result.Reason, "items") {
+		t.Errorf("Reason = %q", result.Reason)
+	}
+	if result.Provenance == nil || result.Provenance.PromptHash == "" {
+		t.Errorf("Provenance = %+v", result.Provenance)
+	}
+}
+
+func TestExtractionJudgeSkipsFieldsWithoutExpectedValues(t *testing.T) {
+	provider := NewMockProvider(nil, `{"vendor": "Globex", "total": 10}`)
+	judge := NewExtractionJudge(provider, invoiceSchema)
+
+	result := judge.Score(context.Background(), evaluation.MetricInput{Expected: `{"total": 12}`})
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	fields := result.Metadata["fields"].(map[string]float64)
+	if len(fields) != 1 || result.Value != 0 {
+		t.Errorf("fields = %v, value = %v", fields, result.Value)
+	}
+}
+
+func TestExtractionJudgeErrors(t *testing.T) {
+	provider := NewMockProvider(nil, "not json")
+	judge := NewExtractionJudge(provider, invoiceSchema)
+
+	if r := judge.Score(context.Background(), evaluation.MetricInput{Expected: "Acme"}); r.Error == nil {
+		t.Error("expected error for non-JSON expected values")
+	}
+	if r := judge.Score(context.Background(), evaluation.MetricInput{Expected: `{"vendor": "Acme"}`}); r.Error == nil {
+		t.Error("expected error for unparseable judge response")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := judge.Score(ctx, evaluation.MetricInput{Expected: `{"vendor": "Acme"}`})
+	if !errors.Is(r.Error, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", r.Error)
+	}
+}
+
+func TestCompareField(t *testing.T) {
+	tests := []struct {
+		name  string
+		field ExtractionField
+		got   any
+		want  any
+		score float64
+	}{
+		{"string case and spacing", ExtractionField{Type: FieldString}, "  New  York ", "new york", 1},
+		{"string partial", ExtractionField{}, "new york city", "new york", 0.8},
+		{"string mismatch", ExtractionField{}, "boston", "new york", 0},
+		{"number exact", ExtractionField{Type: FieldNumber}, 42.0, "42", 1},
+		{"number currency", ExtractionField{Type: FieldNumber}, "$1,000", 1000.0, 1},
+		{"number within tolerance", ExtractionField{Type: FieldNumber, Tolerance: 0.05}, 98.0, 100.0, 1},
+		{"number outside tolerance", ExtractionField{Type: FieldNumber}, 98.0, 100.0, 0},
+		{"number unparseable", ExtractionField{Type: FieldNumber}, "lots", 100.0, 0},
+		{"date formats", ExtractionField{Type: FieldDate}, "2024-03-01", "Mar 1, 2024", 1},
+		{"date with time", ExtractionField{Type: FieldDate}, "2024-03-01T15:04:05Z", "2024-03-01", 1},
+		{"date mismatch", ExtractionField{Type: FieldDate}, "2024-03-02", "2024-03-01", 0},
+		{"list", ExtractionField{Type: FieldList}, []any{"A", "b"}, []any{"a", "b"}, 1},
+		{"list single value", ExtractionField{Type: FieldList}, "a", []any{"a", "b"}, 2.0 / 3},
+		{"missing value", ExtractionField{}, nil, "a", 0},
+		{"both missing", ExtractionField{}, nil, nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareField(tt.field, tt.got, tt.want)
+			if got < tt.score-0.001 || got > tt.score+0.001 {
+				t.Errorf("CompareField(%v, %v) = %v, want %v", tt.got, tt.want, got, tt.score)
+			}
+		})
+	}
+}
+
+func TestRegisteredExtractionJudge(t *testing.T) {
+	provider := NewMockProvider(nil, `{}`)
+
+	m, err := evaluation.NewMetric("extraction", WithJudgeParams(evaluation.MetricParams{
+		"fields":    map[string]any{"total": "number", "vendor": "string"},
+		"tolerance": 0.01,
+	}, provider))
+	if err != nil {
+		t.Fatalf("NewMetric error: %v", err)
+	}
+	judge, ok := m.(*ExtractionJudge)
+	if !ok {
+		t.Fatalf("metric = %T, want *ExtractionJudge", m)
+	}
+	schema := judge.Schema()
+	if len(schema) != 2 || schema[0].Name != "total" || schema[0].Type != FieldNumber || schema[0].Tolerance != 0.01 {
+		t.Errorf("Schema() = %+v", schema)
+	}
+
+	if _, err := evaluation.NewMetric("extraction", WithJudgeParams(nil, provider)); err == nil {
+		t.Error("expected error for missing fields")
+	}
+	if _, err := evaluation.NewMetric("extraction", WithJudgeParams(evaluation.MetricParams{
+		"fields": map[string]any{"total": "money"},
+	}, provider)); err == nil {
+		t.Error("expected error for unknown field type")
+	}
+}
+
+func TestExtractionJudgeNotScoredWithoutExpectedValues(t *testing.T) {
+	var calls atomic.Int32
+	provider := NewSimpleProvider("test", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		calls.Add(1)
+		return &CompletionResponse{Content: `{"vendor": "Acme"}`}, nil
+	})
+	judge := NewExtractionJudge(provider, invoiceSchema)
+
+	for _, expected := range []string{"", `{"customer": "Globex"}`} {
+		result := judge.Score(context.Background(), evaluation.MetricInput{Expected: expected})
+		if !result.IsNotScored() {
+			t.Errorf("expected %q: result = %+v, want not scored", expected, result)
+		}
+	}
+	if calls.Load() != 0 {
+		t.Errorf("provider called %d times, want 0", calls.Load())
+	}
+}
@@ -0,0 +1,49 @@
+package llm
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// WithJudgeLanguage makes the judge write its reasons in a language, given
+// as a BCP 47 tag such as "de" or "pt-BR". ScoreWithRetry adds the
+// instruction to the end of the prompt, after the text being judged, so
+// the built-in English prompts work for teams that read reasons in another
+// language. The JSON keys and the score scale are unchanged.
+func WithJudgeLanguage(tag string) JudgeOption {
+	return func(j *BaseJudge) {
+		j.language = tag
+	}
+}
+
+// validateLanguage reports a language tag that cannot be parsed.
+func validateLanguage(p *evaluation.Problems, tag string) {
+	if _, err := language.Parse(tag); err != nil {
+		p.Addf("judge language %q is not a valid language tag: %v", tag, err)
+	}
+}
+
+// languageInstruction returns the prompt instruction for writing reasons in
+// the language with the given tag.
+func languageInstruction(tag string) string {
+	name := tag
+	if t, err := language.Parse(tag); err == nil {
+		if n := display.English.Tags().Name(t); n != "" {
+			name = n
+		}
+	}
+	return fmt.Sprintf(`Write the "reason" in %s. Keep the JSON keys in English.`, name)
+}
+
+// localize adds the judge's language instruction to the prompt, or returns
+// messages unchanged if the judge has no language.
+func (j *BaseJudge) localize(messages []Message) []Message {
+	if j.language == "" {
+		return messages
+	}
+	return appendInstruction(messages, languageInstruction(j.language))
+}
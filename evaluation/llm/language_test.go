@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+func TestJudgeLanguage(t *testing.T) {
+	provider := &messagesProvider{}
+	m := NewAnswerRelevance(provider, WithJudgeLanguage("de"))
+	if err := m.Validate(); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+
+	input := evaluation.MetricInput{Input: "2+2", Output: "4"}
+	if result := m.Score(context.Background(), input); result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	sent := provider.sent[0]
+	last := sent[len(sent)-1]
+	want := `Write the "reason" in German. Keep the JSON keys in English.`
+	if last.Role != "user" || !strings.HasSuffix(last.Content, want) {
+		t.Errorf("last message = %+v, want it to end with %q", last, want)
+	}
+
+	// The language changes the prompt, so it changes the prompt hash.
+	english := NewAnswerRelevance(provider).Score(context.Background(), input)
+	german := m.Score(context.Background(), input)
+	if english.Provenance.PromptHash == german.Provenance.PromptHash {
+		t.Error("prompt hash should differ between languages")
+	}
+	if strings.Contains(provider.sent[1][len(provider.sent[1])-1].Content, "Write the") {
+		t.Error("judge without a language should not add an instruction")
+	}
+}
+
+func TestJudgeLanguageLocalize(t *testing.T) {
+	j := NewBaseJudge("judge", &messagesProvider{}, WithJudgeLanguage("pt-BR"))
+	if j.Language() != "pt-BR" {
+		t.Errorf("Language() = %q", j.Language())
+	}
+
+	system := []Message{{Role: "system", Content: "Score it."}}
+	got := j.localize(system)
+	if len(got) != 2 || got[1].Role != "user" || !strings.Contains(got[1].Content, "Brazilian Portuguese") {
+		t.Errorf("localize without a user message = %+v", got)
+	}
+	if system[0].Content != "Score it." {
+		t.Error("localize should not modify its input")
+	}
+}
+
+func TestJudgeLanguageValidate(t *testing.T) {
+	j := NewBaseJudge("judge", &messagesProvider{}, WithJudgeLanguage("not a tag!"))
+	if err := j.Validate(); err == nil || !strings.Contains(err.Error(), "not a valid language tag") {
+		t.Errorf("Validate = %v, want an invalid language error", err)
+	}
+}
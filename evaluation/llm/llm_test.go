@@ -3,6 +3,8 @@ package llm
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/plexusone/opik-go/evaluation"
@@ -325,22 +327,6 @@ func TestParseReasonFromResponse(t *testing.T) {
 	}
 }
 
-func TestTruncate(t *testing.T) {
-	short := "short"
-	if truncate(short, 10) != short {
-		t.Error("short string should not be truncated")
-	}
-
-	long := "this is a very long string that should be truncated"
-	result := truncate(long, 20)
-	if len(result) != 20 {
-		t.Errorf("length = %d, want 20", len(result))
-	}
-	if result[len(result)-3:] != "..." {
-		t.Error("truncated string should end with ...")
-	}
-}
-
 func TestFormatPromptTemplate(t *testing.T) {
 	template := "Input: {{input}}\nOutput: {{ output }}"
 	vars := map[string]string{
@@ -622,3 +608,240 @@ func TestScoreWithRetry(t *testing.T) {
 		}
 	})
 }
+
+func TestScoreWithRetryProvenance(t *testing.T) {
+	calls := 0
+	provider := NewSimpleProvider("test-provider", "default-model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		calls++
+		if calls == 1 {
+			return &CompletionResponse{Content: "unparseable", PromptTokens: 10, OutputTokens: 2}, nil
+		}
+		return &CompletionResponse{Content: `{"score": 0.9, "reason": "good"}`, Model: "served-model", PromptTokens: 10, OutputTokens: 5}, nil
+	})
+	j := NewBaseJudge("test", provider, WithJudgeTemperature(0.2))
+	messages := []Message{{Role: "user", Content: "rate this"}}
+
+	sr, err := ScoreWithRetry(context.Background(), j, messages, 3)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	prov := sr.Provenance
+	if prov == nil {
+		t.Fatal("Provenance should be set")
+	}
+	if prov.Model != "served-model" {
+		t.Errorf("Model = %q, want served-model", prov.Model)
+	}
+	if prov.Provider != "test-provider" {
+		t.Errorf("Provider = %q, want test-provider", prov.Provider)
+	}
+	if prov.Temperature != 0.2 {
+		t.Errorf("Temperature = %v, want 0.2", prov.Temperature)
+	}
+	if prov.Retries != 1 {
+		t.Errorf("Retries = %d, want 1", prov.Retries)
+	}
+	if prov.PromptTokens != 20 || prov.OutputTokens != 7 {
+		t.Errorf("tokens = %d/%d, want 20/7", prov.PromptTokens, prov.OutputTokens)
+	}
+	if prov.PromptHash != PromptHash(messages) || len(prov.PromptHash) != 64 {
+		t.Errorf("PromptHash = %q", prov.PromptHash)
+	}
+	if prov.Latency <= 0 {
+		t.Error("Latency should be positive")
+	}
+
+	result := j.NewScoreResult(sr)
+	if result.Provenance != prov || result.Value != 0.9 || result.Reason != "good" {
+		t.Errorf("NewScoreResult = %+v", result)
+	}
+}
+
+func TestPromptHash(t *testing.T) {
+	a := PromptHash([]Message{{Role: "user", Content: "hello"}})
+	b := PromptHash([]Message{{Role: "user", Content: "hello"}})
+	c := PromptHash([]Message{{Role: "system", Content: "hello"}})
+	d := PromptHash([]Message{{Role: "user", Content: "hel"}, {Role: "lo", Content: ""}})
+
+	if a != b {
+		t.Error("identical prompts should hash the same")
+	}
+	if a == c || a == d {
+		t.Error("different prompts should hash differently")
+	}
+}
+
+func TestJudgeMetricProvenance(t *testing.T) {
+	provider := NewMockProvider(nil, `{"score": 1.0, "reason": "relevant"}`)
+	metric := NewAnswerRelevance(provider, WithJudgeModel("judge-model"))
+
+	result := metric.Score(context.Background(), evaluation.NewMetricInput("q", "a"))
+	if result.Provenance == nil {
+		t.Fatal("judge metric results should include provenance")
+	}
+	if result.Provenance.Provider != "mock" {
+		t.Errorf("Provider = %q, want mock", result.Provenance.Provider)
+	}
+}
+
+func TestJudgeConfidenceAndSubScores(t *testing.T) {
+	provider := NewMockProvider(nil, `{"score": 0.6, "reason": "some violence", "confidence": 0.8, "sub_scores": {"violence": 0.6, "hate": 0}}`)
+	m := NewModeration(provider)
+
+	result := m.Score(context.Background(), evaluation.NewMetricInput("", "content"))
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	if result.Confidence == nil || *result.Confidence != 0.8 {
+		t.Errorf("Confidence = %v, want 0.8", result.Confidence)
+	}
+	if len(result.SubScores) != 2 || result.SubScores["violence"] != 0.6 {
+		t.Errorf("SubScores = %v", result.SubScores)
+	}
+}
+
+func TestBaseJudgeCoalescing(t *testing.T) {
+	var calls atomic.Int32
+	provider := NewSimpleProvider("test-provider", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		calls.Add(1)
+		return &CompletionResponse{Content: `{"score": 0.6}`, PromptTokens: 10, OutputTokens: 3}, nil
+	})
+	template := "Rate {{output}} for {{input}}"
+	metrics := []evaluation.Metric{
+		NewCustomJudge("first", template, provider),
+		NewCustomJudge("second", template, provider),
+		NewCustomJudge("hotter", template, provider, WithJudgeTemperature(0.7)),
+	}
+
+	c := evaluation.NewCoalescer()
+	engine := evaluation.NewEngine(metrics, evaluation.WithCoalescer(c), evaluation.WithConcurrency(2))
+	results := engine.EvaluateMany(context.Background(), []evaluation.MetricInput{
+		evaluation.NewMetricInput("q", "a"),
+		evaluation.NewMetricInput("q", "a"),
+	})
+
+	// One request per distinct temperature; the rest are shared.
+	if calls.Load() != 2 {
+		t.Errorf("provider called %d times, want 2", calls.Load())
+	}
+	if stats := c.Stats(); stats.Requests != 6 || stats.Shared != 4 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	coalesced, tokens := 0, 0
+	for _, r := range results {
+		for _, s := range r.Scores {
+			if s.Value != 0.6 || s.Provenance == nil {
+				t.Fatalf("score = %+v", s)
+			}
+			if s.Provenance.Coalesced {
+				coalesced++
+			}
+			tokens += s.Provenance.PromptTokens
+		}
+	}
+	if coalesced != 4 || tokens != 20 {
+		t.Errorf("coalesced = %d, prompt tokens = %d, want 4 and 20", coalesced, tokens)
+	}
+}
+
+func TestScoreWithRetryBypassesCoalescer(t *testing.T) {
+	calls := 0
+	provider := NewSimpleProvider("test-provider", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		calls++
+		if calls == 1 {
+			return &CompletionResponse{Content: "unparseable"}, nil
+		}
+		return &CompletionResponse{Content: `{"score": 0.9}`}, nil
+	})
+	j := NewBaseJudge("test", provider)
+	ctx := evaluation.ContextWithCoalescer(context.Background(), evaluation.NewCoalescer())
+
+	sr, err := ScoreWithRetry(ctx, j, []Message{{Role: "user", Content: "rate this"}}, 3)
+	if err != nil || sr.Score != 0.9 {
+		t.Fatalf("sr = %+v, err = %v", sr, err)
+	}
+	if calls != 2 {
+		t.Errorf("provider called %d times, want 2", calls)
+	}
+}
+
+func TestBaseJudgeDryRun(t *testing.T) {
+	var calls atomic.Int32
+	provider := NewSimpleProvider("test-provider", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		calls.Add(1)
+		return &CompletionResponse{Content: `{"score": 1}`}, nil
+	})
+	engine := evaluation.NewEngine([]evaluation.Metric{
+		NewCustomJudge("tone", "Rate the tone of {{output}} for {{input}}", provider, WithJudgeModel("judge-model")),
+		NewAnswerRelevance(provider),
+	})
+
+	result := engine.DryRun(context.Background(), evaluation.NewMetricInput("Where is Paris?", "In France."))
+	if calls.Load() != 0 {
+		t.Errorf("provider called %d times during a dry run", calls.Load())
+	}
+	if len(result.Prompts) != 2 || len(result.Scores) != 0 {
+		t.Fatalf("prompts = %d, scores = %+v", len(result.Prompts), result.Scores)
+	}
+	tone := result.Prompts[0]
+	if tone.Metric != "tone" || tone.Provider != "test-provider" || tone.Model != "judge-model" {
+		t.Errorf("preview = %+v", tone)
+	}
+	last := tone.Messages[len(tone.Messages)-1].Content
+	if !strings.Contains(last, "In France.") || !strings.Contains(last, "Where is Paris?") || strings.Contains(last, "{{") {
+		t.Errorf("template not substituted: %q", last)
+	}
+	if tone.EstimatedTokens == 0 {
+		t.Error("preview has no token estimate")
+	}
+}
+
+func TestContextMetricsNotScoredWithoutContext(t *testing.T) {
+	var calls atomic.Int32
+	provider := NewSimpleProvider("test", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		calls.Add(1)
+		return &CompletionResponse{Content: `{"score": 1}`}, nil
+	})
+	metrics := []evaluation.Metric{NewHallucination(provider), NewContextRecall(provider), NewContextPrecision(provider)}
+
+	for _, m := range metrics {
+		result := m.Score(context.Background(), evaluation.NewMetricInput("question", "answer"))
+		if !result.IsNotScored() || result.Reason == "" {
+			t.Errorf("%s: result = %+v, want not scored with a reason", m.Name(), result)
+		}
+		result = m.Score(context.Background(), evaluation.NewMetricInput("question", "answer").WithExpected("reference"))
+		if !result.IsSuccess() {
+			t.Errorf("%s with expected output: result = %+v, want scored", m.Name(), result)
+		}
+	}
+	if calls.Load() != 3 {
+		t.Errorf("provider called %d times, want 3", calls.Load())
+	}
+}
+
+func TestBaseJudgeBudget(t *testing.T) {
+	var calls atomic.Int32
+	provider := NewSimpleProvider("test", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		calls.Add(1)
+		return &CompletionResponse{Content: `{"score": 0.9}`, PromptTokens: 40, OutputTokens: 10}, nil
+	})
+	budget := evaluation.NewTokenBudget(120)
+	engine := evaluation.NewEngine([]evaluation.Metric{NewAnswerRelevance(provider)}, evaluation.WithBudget(budget))
+
+	results := engine.EvaluateMany(context.Background(), make([]evaluation.MetricInput, 4))
+	if calls.Load() != 3 {
+		t.Errorf("provider called %d times, want 3", calls.Load())
+	}
+	if budget.Spent() != 150 {
+		t.Errorf("Spent() = %d, want 150", budget.Spent())
+	}
+	last := results[3].Scores[0]
+	if !last.IsNotScored() || last.Error != nil {
+		t.Errorf("last result = %+v, want not scored", last)
+	}
+	if got := results.CountByStatus("answer_relevance")[evaluation.ScoreStatusScored]; got != 3 {
+		t.Errorf("scored = %d, want 3", got)
+	}
+}
@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/textnorm"
+)
+
+// Winners of a PairwiseComparison, recorded in the score result's "winner"
+// metadata.
+const (
+	WinnerOutput   = "output"
+	WinnerBaseline = "baseline"
+	WinnerTie      = "tie"
+)
+
+// Metadata keys of PairwiseComparison.
+const (
+	// MetadataBaseline is the default input metadata key of the output a
+	// PairwiseComparison compares input.Output to.
+	MetadataBaseline = "baseline"
+	// MetadataWinner holds the preferred output: WinnerOutput,
+	// WinnerBaseline, or WinnerTie.
+	MetadataWinner = "winner"
+	// MetadataPositionConsistent is true if the judge preferred the same
+	// output in both orderings.
+	MetadataPositionConsistent = "position_consistent"
+	// MetadataVerdicts holds the []PairwiseVerdict of both orderings.
+	MetadataVerdicts = "verdicts"
+)
+
+// PairwiseVerdict is the judge's preference in one ordering of a pairwise
+// comparison.
+type PairwiseVerdict struct {
+	// OutputFirst is true if input.Output was shown as response A.
+	OutputFirst bool `json:"output_first"`
+	// Winner is WinnerOutput, WinnerBaseline, or WinnerTie.
+	Winner     string  `json:"winner"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason,omitempty"`
+}
+
+// PairwiseComparison asks the judge which of two outputs for the same input
+// is better, for A/B testing prompts or models. It compares input.Output to
+// a baseline output read from the input's "baseline" metadata, which
+// DefaultInputMapper fills from the dataset item's "baseline" field.
+//
+// Judges tend to prefer the response they see first, so the pair is judged
+// twice, once in each order. The score is the preference for input.Output:
+// 1.0 if it wins both orderings, 0.0 if the baseline does, and 0.5 for a
+// tie or when the orderings disagree. The confidence is the judge's mean
+// confidence, reduced by the disagreement between the orderings, so a
+// position-biased verdict has a confidence of 0.
+type PairwiseComparison struct {
+	*BaseJudge
+	criteria    string
+	baselineKey string
+}
+
+// NewPairwiseComparison creates a new pairwise comparison metric.
+func NewPairwiseComparison(provider Provider, opts ...JudgeOption) *PairwiseComparison {
+	return &PairwiseComparison{
+		BaseJudge:   NewBaseJudge("pairwise_comparison", provider, opts...),
+		criteria:    "Which response better answers the input: more helpful, accurate, and complete, without padding.",
+		baselineKey: MetadataBaseline,
+	}
+}
+
+// WithCriteria sets what makes one response better than the other.
+func (m *PairwiseComparison) WithCriteria(criteria string) *PairwiseComparison {
+	m.criteria = criteria
+	return m
+}
+
+// WithBaselineKey sets the input metadata key of the baseline output.
+func (m *PairwiseComparison) WithBaselineKey(key string) *PairwiseComparison {
+	m.baselineKey = key
+	return m
+}
+
+// Validate reports the judge's problems, empty criteria, and an empty
+// baseline key.
+func (m *PairwiseComparison) Validate() error {
+	var p evaluation.Problems
+	p.Add("", m.BaseJudge.Validate())
+	if strings.TrimSpace(m.criteria) == "" {
+		p.Addf("pairwise comparison has no criteria")
+	}
+	if m.baselineKey == "" {
+		p.Addf("pairwise comparison has no baseline key")
+	}
+	return p.Err()
+}
+
+// Compare compares output to baseline for input, as Score does.
+func (m *PairwiseComparison) Compare(ctx context.Context, input, output, baseline string) *evaluation.ScoreResult {
+	return m.Score(ctx, evaluation.NewMetricInput(input, output).WithMetadata(m.baselineKey, baseline))
+}
+
+// Score compares input.Output to the baseline output in both orderings.
+// Inputs without a baseline are not scored.
+func (m *PairwiseComparison) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+	baseline := input.GetString(m.baselineKey)
+	if strings.TrimSpace(baseline) == "" {
+		return evaluation.NewNotScoredResult(m.Name(), fmt.Sprintf("no baseline output in metadata %q", m.baselineKey))
+	}
+
+	var verdicts [2]PairwiseVerdict
+	var provs [2]*evaluation.Provenance
+	for i, outputFirst := range []bool{true, false} {
+		v, prov, err := m.judge(ctx, input.Input, input.Output, baseline, outputFirst)
+		if err != nil {
+			return evaluation.NewFailedScoreResult(m.Name(), err)
+		}
+		verdicts[i], provs[i] = v, prov
+	}
+
+	p1, p2 := preference(verdicts[0].Winner), preference(verdicts[1].Winner)
+	value := (p1 + p2) / 2
+	consistent := verdicts[0].Winner == verdicts[1].Winner
+	confidence := (verdicts[0].Confidence + verdicts[1].Confidence) / 2 * (1 - math.Abs(p1-p2))
+
+	winner := WinnerTie
+	switch {
+	case value > 0.5:
+		winner = WinnerOutput
+	case value < 0.5:
+		winner = WinnerBaseline
+	}
+	reason := verdicts[0].Reason
+	if !consistent {
+		reason = fmt.Sprintf("orderings disagree: preferred %s when the output was first (%s), %s when it was second (%s)",
+			verdicts[0].Winner, verdicts[0].Reason, verdicts[1].Winner, verdicts[1].Reason)
+	}
+
+	result := evaluation.NewScoreResultWithReason(m.Name(), value, reason).WithConfidence(confidence)
+	result.Metadata = map[string]any{
+		MetadataWinner:             winner,
+		MetadataPositionConsistent: consistent,
+		MetadataVerdicts:           verdicts[:],
+	}
+	result.Provenance = sumProvenance(provs[0], provs[1])
+	return result
+}
+
+// judge asks for the judge's preference with the output shown first or
+// second.
+func (m *PairwiseComparison) judge(ctx context.Context, input, output, baseline string, outputFirst bool) (PairwiseVerdict, *evaluation.Provenance, error) {
+	a, b := output, baseline
+	if !outputFirst {
+		a, b = baseline, output
+	}
+	prompt := fmt.Sprintf(`You are comparing two AI responses to the same input.
+
+Input: %s
+
+Response A: %s
+
+Response B: %s
+
+Criteria: %s
+
+Compare the responses on the criteria alone. The order of the responses is random and must not affect your choice, and neither must their length unless the criteria ask for it.
+
+Return your response in JSON format:
+{"winner": "<A|B|tie>", "confidence": <0.0-1.0>, "reason": "<why the winner is better>"}`, input, a, b, m.criteria)
+
+	var resp struct {
+		Winner     string  `json:"winner"`
+		Confidence float64 `json:"confidence"`
+		Reason     string  `json:"reason"`
+	}
+	prov, err := m.completeJSON(ctx, []Message{{Role: "user", Content: prompt}}, 3, &resp, func() error {
+		switch strings.ToUpper(strings.TrimSpace(resp.Winner)) {
+		case "A", "B", "TIE":
+			return nil
+		}
+		return fmt.Errorf("unknown winner %q", textnorm.Truncate(resp.Winner, 20))
+	})
+	if err != nil {
+		return PairwiseVerdict{}, nil, err
+	}
+
+	v := PairwiseVerdict{
+		OutputFirst: outputFirst,
+		Winner:      WinnerTie,
+		Confidence:  min(max(resp.Confidence, 0), 1),
+		Reason:      resp.Reason,
+	}
+	switch strings.ToUpper(strings.TrimSpace(resp.Winner)) {
+	case "A":
+		v.Winner = WinnerBaseline
+		if outputFirst {
+			v.Winner = WinnerOutput
+		}
+	case "B":
+		v.Winner = WinnerOutput
+		if outputFirst {
+			v.Winner = WinnerBaseline
+		}
+	}
+	return v, prov, nil
+}
+
+// preference returns the preference for the output of a winner.
+func preference(winner string) float64 {
+	switch winner {
+	case WinnerOutput:
+		return 1
+	case WinnerBaseline:
+		return 0
+	}
+	return 0.5
+}
@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// preferProvider is a judge that prefers the response containing better,
+// with the given confidence.
+func preferProvider(better string, confidence string) *SimpleProvider {
+	return NewSimpleProvider("test", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		prompt := req.Messages[0].Content
+		a := prompt[strings.Index(prompt, "Response A:"):strings.Index(prompt, "Response B:")]
+		winner := "B"
+		if strings.Contains(a, better) {
+			winner = "A"
+		}
+		return &CompletionResponse{Content: `{"winner": "` + winner + `", "confidence": ` + confidence + `, "reason": "more detail"}`}, nil
+	})
+}
+
+func TestPairwiseComparison(t *testing.T) {
+	tests := []struct {
+		name   string
+		better string
+		want   float64
+		winner string
+	}{
+		{"output preferred", "Paris, on the Seine", 1, WinnerOutput},
+		{"baseline preferred", "It is Paris", 0, WinnerBaseline},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metric := NewPairwiseComparison(preferProvider(tt.better, "0.8"))
+			result := metric.Compare(context.Background(), "What is the capital of France?", "Paris, on the Seine.", "It is Paris.")
+			if result.Error != nil {
+				t.Fatalf("Score error: %v", result.Error)
+			}
+			if result.Value != tt.want {
+				t.Errorf("Value = %v, want %v", result.Value, tt.want)
+			}
+			if result.Confidence == nil || *result.Confidence != 0.8 {
+				t.Errorf("Confidence = %v, want 0.8", result.Confidence)
+			}
+			if result.Metadata[MetadataWinner] != tt.winner || result.Metadata[MetadataPositionConsistent] != true {
+				t.Errorf("Metadata = %v", result.Metadata)
+			}
+			verdicts := result.Metadata[MetadataVerdicts].([]PairwiseVerdict)
+			if len(verdicts) != 2 || !verdicts[0].OutputFirst || verdicts[1].OutputFirst {
+				t.Errorf("verdicts = %+v", verdicts)
+			}
+		})
+	}
+}
+
+func TestPairwiseComparisonPositionBias(t *testing.T) {
+	// The judge always prefers the first response.
+	provider := NewMockProvider(nil, `{"winner": "A", "confidence": 0.9, "reason": "first is better"}`)
+	input := evaluation.NewMetricInput("q", "new answer").WithMetadata(MetadataBaseline, "old answer")
+
+	result := NewPairwiseComparison(provider).Score(context.Background(), input)
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	if result.Value != 0.5 || result.Metadata[MetadataWinner] != WinnerTie {
+		t.Errorf("Value = %v, winner = %v, want a tie", result.Value, result.Metadata[MetadataWinner])
+	}
+	if result.Metadata[MetadataPositionConsistent] != false {
+		t.Error("position bias not detected")
+	}
+	if *result.Confidence != 0 {
+		t.Errorf("Confidence = %v, want 0", *result.Confidence)
+	}
+	if !strings.Contains(result.Reason, "orderings disagree") {
+		t.Errorf("Reason = %q", result.Reason)
+	}
+}
+
+func TestPairwiseComparisonRetriesUnknownWinner(t *testing.T) {
+	var calls atomic.Int32
+	provider := NewSimpleProvider("test", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		if calls.Add(1) == 1 {
+			return &CompletionResponse{Content: `{"winner": "both"}`}, nil
+		}
+		return &CompletionResponse{Content: `{"winner": "tie", "confidence": 0.6}`}, nil
+	})
+
+	result := NewPairwiseComparison(provider).Compare(context.Background(), "q", "a", "b")
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	if result.Value != 0.5 || calls.Load() != 3 {
+		t.Errorf("Value = %v after %d calls, want 0.5 after 3", result.Value, calls.Load())
+	}
+	if *result.Confidence != 0.6 {
+		t.Errorf("Confidence = %v, want 0.6", *result.Confidence)
+	}
+}
+
+func TestPairwiseComparisonBaselineKey(t *testing.T) {
+	var calls atomic.Int32
+	provider := NewSimpleProvider("test", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		calls.Add(1)
+		return &CompletionResponse{Content: `{"winner": "tie", "confidence": 1}`}, nil
+	})
+	metric := NewPairwiseComparison(provider).WithBaselineKey("prompt_v1")
+
+	input := evaluation.NewMetricInput("q", "a").WithMetadata(MetadataBaseline, "b")
+	if result := metric.Score(context.Background(), input); !result.IsNotScored() {
+		t.Errorf("result = %+v, want not scored", result)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("judge called %d times", calls.Load())
+	}
+	if result := metric.Score(context.Background(), input.WithMetadata("prompt_v1", "b")); result.Error != nil || result.IsNotScored() {
+		t.Errorf("result = %+v, want scored", result)
+	}
+}
+
+func TestPairwiseComparisonRegistered(t *testing.T) {
+	params := WithJudgeParams(evaluation.MetricParams{"baseline_key": "control"}, preferProvider("treatment", "1"))
+	metric, err := evaluation.NewMetric("pairwise_comparison", params)
+	if err != nil {
+		t.Fatalf("NewMetric: %v", err)
+	}
+	mapper := evaluation.DefaultInputMapper("input", "output", "expected")
+	result := metric.Score(context.Background(), mapper(map[string]any{
+		"input":   "q",
+		"output":  "treatment answer",
+		"control": "control answer",
+	}))
+	if result.Value != 1 {
+		t.Errorf("Value = %v, want 1", result.Value)
+	}
+}
@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// PromptLibrary loads prompt templates by name and commit from a prompt
+// library. *opik.Client implements it with Opik's prompt management:
+//
+//	judge := llm.NewHallucination(provider,
+//	    llm.WithJudgePromptLibrary(client),
+//	    llm.WithJudgePromptRef("hallucination-judge", "abc123"),
+//	)
+type PromptLibrary interface {
+	// LoadPrompt returns the template of a prompt version and its commit.
+	// An empty commit selects the latest version.
+	LoadPrompt(ctx context.Context, name, commit string) (template, loadedCommit string, err error)
+}
+
+// judgePrompt is a judge prompt template loaded from a prompt library.
+type judgePrompt struct {
+	name    string
+	commit  string
+	library PromptLibrary
+
+	mu       sync.Mutex
+	loaded   bool
+	template string
+	loadedAs string // commit of the loaded version
+}
+
+// WithJudgePromptRef makes the judge load its prompt template from a
+// prompt library, set with WithJudgePromptLibrary, instead of using its
+// built-in prompt. The template is the prompt version with the given name
+// and commit, or the latest version if commit is empty, and is loaded once,
+// on the judge's first score.
+//
+// The template is rendered with FormatPromptTemplate. Every judge provides
+// the variables input, output, expected, and context; some provide more,
+// such as criteria and steps for GEval and categories for Moderation. The
+// template must still ask for the judge's JSON response. The prompt name
+// and commit are recorded in each score's provenance.
+func WithJudgePromptRef(name, commit string) JudgeOption {
+	return func(j *BaseJudge) {
+		if j.prompt == nil {
+			j.prompt = &judgePrompt{}
+		}
+		j.prompt.name, j.prompt.commit = name, commit
+	}
+}
+
+// WithJudgePromptLibrary sets the prompt library WithJudgePromptRef loads
+// from.
+func WithJudgePromptLibrary(library PromptLibrary) JudgeOption {
+	return func(j *BaseJudge) {
+		if j.prompt == nil {
+			j.prompt = &judgePrompt{}
+		}
+		j.prompt.library = library
+	}
+}
+
+// validatePrompt reports a prompt reference without a name or library.
+func validatePrompt(p *evaluation.Problems, prompt *judgePrompt) {
+	if prompt.name == "" {
+		p.Addf("judge prompt reference has no name")
+	}
+	if prompt.library == nil {
+		p.Addf("judge prompt reference %q has no prompt library", prompt.name)
+	}
+}
+
+// load returns the prompt's template and commit, loading them on first use.
+// A failed load is retried on the next call.
+func (p *judgePrompt) load(ctx context.Context) (string, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.loaded {
+		return p.template, p.loadedAs, nil
+	}
+	if p.library == nil {
+		return "", "", fmt.Errorf("judge prompt %q has no prompt library", p.name)
+	}
+	template, commit, err := p.library.LoadPrompt(ctx, p.name, p.commit)
+	if err != nil {
+		return "", "", fmt.Errorf("load judge prompt %q: %w", p.name, err)
+	}
+	if commit == "" {
+		commit = p.commit
+	}
+	p.template, p.loadedAs, p.loaded = template, commit, true
+	return template, commit, nil
+}
+
+// PromptMessages returns the messages a judge sends to score input. For a
+// judge created with WithJudgePromptRef, they hold the loaded template
+// rendered with input's fields and vars; otherwise they hold defaultPrompt.
+// Judges built on BaseJudge call it so their prompt can be versioned in a
+// prompt library.
+func (j *BaseJudge) PromptMessages(ctx context.Context, defaultPrompt string, input evaluation.MetricInput, vars map[string]string) ([]Message, error) {
+	if j.prompt == nil {
+		return []Message{{Role: "user", Content: defaultPrompt}}, nil
+	}
+	template, _, err := j.prompt.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{
+		"input":    input.Input,
+		"output":   input.Output,
+		"expected": input.Expected,
+		"context":  input.Context,
+	}
+	for k, v := range vars {
+		values[k] = v
+	}
+	return []Message{{Role: "user", Content: FormatPromptTemplate(template, values)}}, nil
+}
+
+// setPromptProvenance records the judge's loaded prompt version in prov.
+func (j *BaseJudge) setPromptProvenance(prov *evaluation.Provenance) {
+	if j.prompt == nil {
+		return
+	}
+	j.prompt.mu.Lock()
+	defer j.prompt.mu.Unlock()
+	if j.prompt.loaded {
+		prov.PromptName = j.prompt.name
+		prov.PromptCommit = j.prompt.loadedAs
+	}
+}
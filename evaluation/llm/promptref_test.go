@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// promptLibrary serves one template and counts its loads.
+type promptLibrary struct {
+	template string
+	commit   string
+	err      error
+	loads    int
+}
+
+func (l *promptLibrary) LoadPrompt(_ context.Context, name, commit string) (string, string, error) {
+	l.loads++
+	if l.err != nil {
+		return "", "", l.err
+	}
+	if commit == "" {
+		commit = l.commit
+	}
+	return l.template, commit, nil
+}
+
+func TestJudgePromptRef(t *testing.T) {
+	library := &promptLibrary{template: "Q: {{input}} A: {{ output }} Ref: {{expected}}", commit: "latest1"}
+	provider := &messagesProvider{}
+	m := NewAnswerRelevance(provider,
+		WithJudgePromptLibrary(library),
+		WithJudgePromptRef("relevance-judge", "abc123"),
+	)
+	if err := m.Validate(); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+
+	input := evaluation.MetricInput{Input: "2+2", Output: "4", Expected: "4"}
+	for range 2 {
+		if result := m.Score(context.Background(), input); result.Error != nil {
+			t.Fatalf("Score error: %v", result.Error)
+		}
+	}
+	if want := "Q: 2+2 A: 4 Ref: 4"; provider.sent[0][0].Content != want {
+		t.Errorf("prompt = %q, want %q", provider.sent[0][0].Content, want)
+	}
+	if library.loads != 1 {
+		t.Errorf("template loaded %d times, want once", library.loads)
+	}
+
+	result := m.Score(context.Background(), input)
+	if p := result.Provenance; p.PromptName != "relevance-judge" || p.PromptCommit != "abc123" {
+		t.Errorf("provenance prompt = %q@%q, want relevance-judge@abc123", p.PromptName, p.PromptCommit)
+	}
+
+	// Without a commit, the latest version's commit is recorded.
+	latest := NewCoherence(provider, WithJudgePromptLibrary(library), WithJudgePromptRef("coherence-judge", ""))
+	if p := latest.Score(context.Background(), input).Provenance; p.PromptCommit != "latest1" {
+		t.Errorf("latest commit = %q, want latest1", p.PromptCommit)
+	}
+}
+
+func TestJudgePromptRefVars(t *testing.T) {
+	library := &promptLibrary{template: "{{criteria}} | {{categories}} | {{output}}"}
+	provider := &messagesProvider{}
+	opts := []JudgeOption{WithJudgePromptLibrary(library), WithJudgePromptRef("judge", "c1")}
+	input := evaluation.MetricInput{Output: "text"}
+
+	NewGEval(provider, "be concise", opts...).Score(context.Background(), input)
+	NewModeration(provider, opts...).WithCategories([]string{"spam"}).Score(context.Background(), input)
+	if got := provider.sent[0][0].Content; got != "be concise | {{categories}} | text" {
+		t.Errorf("g_eval prompt = %q", got)
+	}
+	if got := provider.sent[1][0].Content; got != "{{criteria}} | - spam\n | text" {
+		t.Errorf("moderation prompt = %q", got)
+	}
+}
+
+func TestJudgePromptRefErrors(t *testing.T) {
+	provider := &messagesProvider{}
+	if err := NewHelpfulness(provider, WithJudgePromptRef("judge", "c1")).Validate(); err == nil {
+		t.Error("Validate accepted a prompt reference without a library")
+	}
+	if err := NewHelpfulness(provider, WithJudgePromptLibrary(&promptLibrary{})).Validate(); err == nil {
+		t.Error("Validate accepted a prompt library without a reference")
+	}
+
+	library := &promptLibrary{err: errors.New("not found")}
+	m := NewHelpfulness(provider, WithJudgePromptLibrary(library), WithJudgePromptRef("judge", "c1"))
+	if result := m.Score(context.Background(), evaluation.MetricInput{Output: "x"}); result.Error == nil {
+		t.Error("Score succeeded without its prompt")
+	}
+	if len(provider.sent) != 0 {
+		t.Errorf("sent %d requests, want none", len(provider.sent))
+	}
+
+	// A failed load is retried.
+	library.err, library.template = nil, "{{output}}"
+	if result := m.Score(context.Background(), evaluation.MetricInput{Output: "x"}); result.Error != nil {
+		t.Errorf("Score error after the library recovered: %v", result.Error)
+	}
+}
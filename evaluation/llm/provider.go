@@ -16,6 +16,9 @@ type CompletionRequest struct {
 	Model       string    `json:"model,omitempty"`
 	Temperature float64   `json:"temperature,omitempty"`
 	MaxTokens   int       `json:"max_tokens,omitempty"`
+	// Metric is the name of the judge metric making the request, for
+	// routing. It is not sent to the model.
+	Metric string `json:"-"`
 }
 
 // CompletionResponse represents a chat completion response.
@@ -24,6 +27,14 @@ type CompletionResponse struct {
 	Model        string `json:"model,omitempty"`
 	PromptTokens int    `json:"prompt_tokens,omitempty"`
 	OutputTokens int    `json:"output_tokens,omitempty"`
+	// Route is the name of the RouterProvider rule that handled the request.
+	Route string `json:"-"`
+	// Coalesced is true when the response was shared from another metric's
+	// identical request instead of sent to the provider.
+	Coalesced bool `json:"-"`
+	// OmittedMessages is the number of prompt messages a judge created
+	// with WithMaxPromptTokens left out to fit its limit.
+	OmittedMessages int `json:"-"`
 }
 
 // Provider is an interface for LLM providers used in evaluation.
@@ -38,6 +49,38 @@ type Provider interface {
 	DefaultModel() string
 }
 
+// StreamingProvider is an optional interface for providers that can stream completions.
+type StreamingProvider interface {
+	Provider
+
+	// CompleteStream sends a completion request, calling onChunk for each content delta
+	// as it arrives, and returns the complete response when the stream ends.
+	CompleteStream(ctx context.Context, req CompletionRequest, onChunk func(delta string)) (*CompletionResponse, error)
+}
+
+// ResponseSchema describes the JSON object a StructuredProvider responds with.
+type ResponseSchema struct {
+	// Name identifies the schema, such as the function name of a provider
+	// that uses function calling.
+	Name        string
+	Description string
+	// Schema is a JSON Schema of the response object.
+	Schema map[string]any
+}
+
+// StructuredProvider is an optional interface for providers that can
+// constrain a completion to a JSON schema, with a native JSON mode or
+// function calling. Judges whose provider implements it request their
+// scores with CompleteStructured and validate the response against the
+// schema, instead of parsing free text.
+type StructuredProvider interface {
+	Provider
+
+	// CompleteStructured sends a completion request and returns a response
+	// whose Content is a JSON object matching schema.
+	CompleteStructured(ctx context.Context, req CompletionRequest, schema ResponseSchema) (*CompletionResponse, error)
+}
+
 // ProviderOption configures a provider.
 type ProviderOption func(*providerConfig)
 
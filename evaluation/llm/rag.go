@@ -0,0 +1,423 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/heuristic"
+)
+
+// Score result metadata keys of the RAG metrics.
+const (
+	// MetadataClaims holds a Faithfulness score's []ClaimVerdict.
+	MetadataClaims = "claims"
+	// MetadataStatements holds an AnswerCorrectness score's classified
+	// statements, by class.
+	MetadataStatements = "statements"
+	// MetadataChunkScores holds a ContextRelevancy score's per-chunk
+	// scores, in the order of the chunks.
+	MetadataChunkScores = "chunk_scores"
+)
+
+// ClaimVerdict is the judge's verdict on one claim of an output.
+type ClaimVerdict struct {
+	Claim     string `json:"claim"`
+	Supported bool   `json:"supported"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Faithfulness measures how much of the output is supported by the
+// retrieved context, as in RAGAS. The judge breaks the output into
+// standalone claims, then checks each claim against the context chunks.
+// The score is the fraction of claims the context supports, and the
+// verdicts are recorded in the score result's "claims" metadata.
+//
+// The context is read from input.ContextList, or input.Context as a single
+// chunk. Inputs without a context, or whose output makes no claims, are not
+// scored.
+type Faithfulness struct {
+	*BaseJudge
+}
+
+// NewFaithfulness creates a new Faithfulness metric.
+func NewFaithfulness(provider Provider, opts ...JudgeOption) *Faithfulness {
+	return &Faithfulness{
+		BaseJudge: NewBaseJudge("faithfulness", provider, opts...),
+	}
+}
+
+// Score extracts the output's claims and verifies them against the context.
+func (m *Faithfulness) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+	chunks := input.Contexts()
+	if len(chunks) == 0 {
+		return evaluation.NewNotScoredResult(m.Name(), "no context")
+	}
+
+	claims, claimsProv, err := m.extractClaims(ctx, input)
+	if err != nil {
+		return evaluation.NewFailedScoreResult(m.Name(), fmt.Errorf("extracting claims: %w", err))
+	}
+	if len(claims) == 0 {
+		return evaluation.NewNotScoredResult(m.Name(), "output makes no claims")
+	}
+
+	prompt := fmt.Sprintf(`You are checking whether claims are supported by retrieved context.
+
+Context:
+%s
+
+Claims:
+%s
+
+For each claim, decide whether it can be directly inferred from the context. A claim is supported only if the context states or clearly implies it; a claim that is plausible but not in the context is not supported.
+
+Return your response in JSON format, with one verdict per claim, in order:
+{"verdicts": [{"claim": "<claim>", "supported": <true|false>, "reason": "<where the context supports or fails to support it>"}]}`, numberedList(chunks), numberedList(claims))
+
+	var resp struct {
+		Verdicts []ClaimVerdict `json:"verdicts"`
+	}
+	verifyProv, err := m.completeJSON(ctx, []Message{{Role: "user", Content: prompt}}, 3, &resp, func() error {
+		if len(resp.Verdicts) != len(claims) {
+			return fmt.Errorf("got %d verdicts for %d claims", len(resp.Verdicts), len(claims))
+		}
+		return nil
+	})
+	if err != nil {
+		return evaluation.NewFailedScoreResult(m.Name(), fmt.Errorf("verifying claims: %w", err))
+	}
+
+	var unsupported []string
+	for i := range resp.Verdicts {
+		resp.Verdicts[i].Claim = claims[i]
+		if !resp.Verdicts[i].Supported {
+			unsupported = append(unsupported, claims[i])
+		}
+	}
+	supported := len(claims) - len(unsupported)
+	reason := fmt.Sprintf("%d/%d claims supported by the context", supported, len(claims))
+	if len(unsupported) > 0 {
+		reason += "; unsupported: " + strings.Join(unsupported, "; ")
+	}
+
+	result := evaluation.NewScoreResultWithReason(m.Name(), float64(supported)/float64(len(claims)), reason)
+	result.Metadata = map[string]any{MetadataClaims: resp.Verdicts}
+	result.Provenance = sumProvenance(claimsProv, verifyProv)
+	return result
+}
+
+// extractClaims asks the judge for the claims the output makes.
+func (m *Faithfulness) extractClaims(ctx context.Context, input evaluation.MetricInput) ([]string, *evaluation.Provenance, error) {
+	prompt := fmt.Sprintf(`Break the answer below into the factual claims it makes.
+
+Question: %s
+
+Answer: %s
+
+Write each claim as a short, standalone sentence that can be checked on its own: replace pronouns with what they refer to. Leave out opinions, greetings, and questions. If the answer makes no factual claims, return an empty list.
+
+Return your response in JSON format:
+{"claims": ["<claim>", ...]}`, input.Input, input.Output)
+
+	var resp struct {
+		Claims []string `json:"claims"`
+	}
+	prov, err := m.completeJSON(ctx, []Message{{Role: "user", Content: prompt}}, 3, &resp, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	claims := resp.Claims[:0]
+	for _, c := range resp.Claims {
+		if c = strings.TrimSpace(c); c != "" {
+			claims = append(claims, c)
+		}
+	}
+	return claims, prov, nil
+}
+
+// AnswerCorrectness measures how correct the output is against the
+// expected answer, as in RAGAS. It combines two scores:
+//
+//   - factuality: the judge classifies the statements of both answers as
+//     true positives (in the output and supported by the expected answer),
+//     false positives (in the output but not supported), and false
+//     negatives (in the expected answer but missing from the output), and
+//     the score is their F1;
+//   - similarity: a similarity metric between output and expected answer,
+//     word-based cosine similarity unless set with WithSimilarity.
+//
+// The score is their weighted mean, 0.75 factuality and 0.25 similarity
+// unless set with WithWeights. Both are reported as sub-scores, and the
+// classified statements in the "statements" metadata. Inputs without an
+// expected answer are not scored.
+type AnswerCorrectness struct {
+	*BaseJudge
+	similarity       evaluation.Metric
+	factualityWeight float64
+	similarityWeight float64
+}
+
+// NewAnswerCorrectness creates a new AnswerCorrectness metric.
+func NewAnswerCorrectness(provider Provider, opts ...JudgeOption) *AnswerCorrectness {
+	return &AnswerCorrectness{
+		BaseJudge:        NewBaseJudge("answer_correctness", provider, opts...),
+		similarity:       heuristic.NewCosineSimilarity(false),
+		factualityWeight: 0.75,
+		similarityWeight: 0.25,
+	}
+}
+
+// WithSimilarity sets the metric that scores the similarity of output and
+// expected answer, such as an embedding-based metric.
+func (m *AnswerCorrectness) WithSimilarity(metric evaluation.Metric) *AnswerCorrectness {
+	m.similarity = metric
+	return m
+}
+
+// WithWeights sets the weights of the factuality and similarity scores.
+// A zero similarity weight skips the similarity metric.
+func (m *AnswerCorrectness) WithWeights(factuality, similarity float64) *AnswerCorrectness {
+	m.factualityWeight = factuality
+	m.similarityWeight = similarity
+	return m
+}
+
+// Validate reports the judge's problems, negative or all-zero weights, and
+// a missing similarity metric.
+func (m *AnswerCorrectness) Validate() error {
+	var p evaluation.Problems
+	p.Add("", m.BaseJudge.Validate())
+	if m.factualityWeight < 0 || m.similarityWeight < 0 {
+		p.Addf("weights must not be negative: %v, %v", m.factualityWeight, m.similarityWeight)
+	} else if m.factualityWeight+m.similarityWeight == 0 {
+		p.Addf("weights must not both be zero")
+	}
+	if m.similarity == nil && m.similarityWeight > 0 {
+		p.Addf("answer correctness has no similarity metric")
+	}
+	return p.Err()
+}
+
+// statementClasses is the judge's classification of statements for
+// AnswerCorrectness.
+type statementClasses struct {
+	TruePositives  []string `json:"true_positives"`
+	FalsePositives []string `json:"false_positives"`
+	FalseNegatives []string `json:"false_negatives"`
+}
+
+// Score compares the output to input.Expected.
+func (m *AnswerCorrectness) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+	if strings.TrimSpace(input.Expected) == "" {
+		return evaluation.NewNotScoredResult(m.Name(), "no expected output")
+	}
+
+	prompt := fmt.Sprintf(`You are comparing an AI answer to a ground truth answer.
+
+Question: %s
+
+AI answer: %s
+
+Ground truth: %s
+
+Break both answers into short, standalone factual statements, and classify them:
+- true_positives: statements of the AI answer that are supported by the ground truth
+- false_positives: statements of the AI answer that are not supported by the ground truth
+- false_negatives: statements of the ground truth that are missing from the AI answer
+
+Each statement belongs to exactly one class. Ignore differences in wording.
+
+Return your response in JSON format:
+{"true_positives": ["<statement>", ...], "false_positives": [...], "false_negatives": [...]}`, input.Input, input.Output, input.Expected)
+
+	var classes statementClasses
+	prov, err := m.completeJSON(ctx, []Message{{Role: "user", Content: prompt}}, 3, &classes, func() error {
+		if len(classes.TruePositives)+len(classes.FalsePositives)+len(classes.FalseNegatives) == 0 {
+			return errors.New("no statements were classified")
+		}
+		return nil
+	})
+	if err != nil {
+		return evaluation.NewFailedScoreResult(m.Name(), err)
+	}
+	tp := float64(len(classes.TruePositives))
+	factuality := tp / (tp + 0.5*float64(len(classes.FalsePositives)+len(classes.FalseNegatives)))
+
+	subScores := map[string]float64{"factuality": factuality}
+	value := factuality
+	if m.similarityWeight > 0 {
+		sim := m.similarity.Score(ctx, input)
+		if sim.Error != nil {
+			return evaluation.NewFailedScoreResult(m.Name(), fmt.Errorf("similarity: %w", sim.Error))
+		}
+		subScores["similarity"] = sim.Value
+		value = (m.factualityWeight*factuality + m.similarityWeight*sim.Value) / (m.factualityWeight + m.similarityWeight)
+	}
+
+	reason := fmt.Sprintf("%d statements correct, %d unsupported, %d missing",
+		len(classes.TruePositives), len(classes.FalsePositives), len(classes.FalseNegatives))
+	result := evaluation.NewScoreResultWithReason(m.Name(), value, reason)
+	result.SubScores = subScores
+	result.Metadata = map[string]any{
+		MetadataStatements: map[string][]string{
+			"true_positives":  classes.TruePositives,
+			"false_positives": classes.FalsePositives,
+			"false_negatives": classes.FalseNegatives,
+		},
+	}
+	result.Provenance = prov
+	return result
+}
+
+// ContextRelevancy measures how relevant the retrieved context is to the
+// input. Each chunk of input.ContextList is scored on its own, so one
+// irrelevant chunk among relevant ones lowers the score rather than being
+// lost in a long context, and the chunk scores are averaged unless set
+// otherwise with WithAggregation. The chunk scores are recorded in the
+// score result's "chunk_scores" metadata.
+//
+// A single input.Context is scored as one chunk. Inputs without an input or
+// a context are not scored.
+type ContextRelevancy struct {
+	*BaseJudge
+	aggregation WindowAggregation
+}
+
+// NewContextRelevancy creates a new ContextRelevancy metric.
+func NewContextRelevancy(provider Provider, opts ...JudgeOption) *ContextRelevancy {
+	return &ContextRelevancy{
+		BaseJudge:   NewBaseJudge("context_relevancy", provider, opts...),
+		aggregation: WindowMean,
+	}
+}
+
+// WithAggregation sets how chunk scores are combined: WindowMean averages
+// them, WindowMax keeps the best chunk's score, which suits retrieval where
+// one relevant chunk is enough, and WindowMin keeps the worst chunk's.
+func (m *ContextRelevancy) WithAggregation(aggregation WindowAggregation) *ContextRelevancy {
+	m.aggregation = aggregation
+	return m
+}
+
+// Validate reports the judge's problems and an unknown aggregation.
+func (m *ContextRelevancy) Validate() error {
+	var p evaluation.Problems
+	p.Add("", m.BaseJudge.Validate())
+	switch m.aggregation {
+	case WindowMean, WindowMin, WindowMax:
+	default:
+		p.Addf("unknown aggregation %q", m.aggregation)
+	}
+	return p.Err()
+}
+
+// Score scores each context chunk's relevance to the input and combines
+// the scores. The judge's prompt template, if set with WithJudgePromptRef,
+// gets the chunk as {{chunk}}.
+func (m *ContextRelevancy) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+	chunks := input.Contexts()
+	if len(chunks) == 0 {
+		return evaluation.NewNotScoredResult(m.Name(), "no context")
+	}
+	if strings.TrimSpace(input.Input) == "" {
+		return evaluation.NewNotScoredResult(m.Name(), "no input")
+	}
+
+	results := make([]*evaluation.ScoreResult, len(chunks))
+	for i, chunk := range chunks {
+		results[i] = m.scoreChunk(ctx, input, chunk)
+		if results[i].Error != nil {
+			return evaluation.NewFailedScoreResult(m.Name(), fmt.Errorf("context chunk %d of %d: %w", i+1, len(chunks), results[i].Error))
+		}
+	}
+
+	pick := 0
+	var value float64
+	switch m.aggregation {
+	case WindowMin, WindowMax:
+		for i, r := range results {
+			if (m.aggregation == WindowMin && r.Value < results[pick].Value) ||
+				(m.aggregation == WindowMax && r.Value > results[pick].Value) {
+				pick = i
+			}
+		}
+		value = results[pick].Value
+	default:
+		for _, r := range results {
+			value += r.Value
+		}
+		value /= float64(len(results))
+	}
+
+	reason := fmt.Sprintf("%s of %d context chunks", m.aggregation, len(results))
+	if m.aggregation != WindowMean && results[pick].Reason != "" {
+		reason = fmt.Sprintf("chunk %d of %d: %s", pick+1, len(results), results[pick].Reason)
+	}
+	result := evaluation.NewScoreResultWithReason(m.Name(), value, reason)
+	result.Metadata = map[string]any{MetadataChunkScores: windowScores(results)}
+	result.Provenance = combineProvenance(results, pick)
+	return result
+}
+
+func (m *ContextRelevancy) scoreChunk(ctx context.Context, input evaluation.MetricInput, chunk string) *evaluation.ScoreResult {
+	prompt := fmt.Sprintf(`You are evaluating whether a retrieved passage is relevant to a question.
+
+Question: %s
+
+Passage: %s
+
+Evaluate how useful the passage is for answering the question. Judge the passage alone, not whether it fully answers the question.
+
+Return your response in JSON format:
+{"score": <0.0-1.0>, "reason": "<explanation>"}
+
+Where:
+- 1.0: The passage contains information needed to answer the question
+- 0.5: The passage is on topic but only marginally useful for answering
+- 0.0: The passage is unrelated to the question`, input.Input, chunk)
+
+	messages, err := m.PromptMessages(ctx, prompt, input, map[string]string{"chunk": chunk})
+	if err != nil {
+		return evaluation.NewFailedScoreResult(m.Name(), err)
+	}
+
+	sr, err := ScoreWithRetry(ctx, m.BaseJudge, messages, 3)
+	if err != nil {
+		return evaluation.NewFailedScoreResult(m.Name(), err)
+	}
+
+	return m.NewScoreResult(sr)
+}
+
+// numberedList formats items as a numbered list, one per paragraph.
+func numberedList(items []string) string {
+	var b strings.Builder
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "[%d] %s", i+1, item)
+	}
+	return b.String()
+}
+
+// sumProvenance combines the provenance of consecutive judge requests for
+// one score. It keeps the model and prompt hash of the last request, and
+// the sum of the retries, tokens, and latency of all.
+func sumProvenance(provs ...*evaluation.Provenance) *evaluation.Provenance {
+	var sum evaluation.Provenance
+	for _, p := range provs {
+		retries, prompt, output, latency := sum.Retries, sum.PromptTokens, sum.OutputTokens, sum.Latency
+		coalesced := sum.Coalesced
+		sum = *p
+		sum.Retries += retries
+		sum.PromptTokens += prompt
+		sum.OutputTokens += output
+		sum.Latency += latency
+		sum.Coalesced = sum.Coalesced || coalesced
+	}
+	return &sum
+}
@@ -0,0 +1,235 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// promptProvider answers each request with the response of the first key
+// the prompt contains, and counts the requests.
+func promptProvider(calls *atomic.Int32, responses map[string]string) *SimpleProvider {
+	return NewSimpleProvider("test", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		calls.Add(1)
+		prompt := req.Messages[len(req.Messages)-1].Content
+		for key, content := range responses {
+			if strings.Contains(prompt, key) {
+				return &CompletionResponse{Content: content, PromptTokens: 10, OutputTokens: 5}, nil
+			}
+		}
+		return nil, errors.New("unexpected prompt")
+	})
+}
+
+func TestFaithfulness(t *testing.T) {
+	var calls atomic.Int32
+	provider := promptProvider(&calls, map[string]string{
+		"Break the answer": `{"claims": ["Paris is the capital of France.", "Paris has 2 million residents.", " "]}`,
+		"Claims:": `{"verdicts": [
+			{"claim": "Paris is the capital of France.", "supported": true, "reason": "chunk 1"},
+			{"claim": "Paris has 2 million residents.", "supported": false, "reason": "no population in context"}
+		]}`,
+	})
+	input := evaluation.NewMetricInput("Tell me about Paris.", "Paris is the capital of France and has 2 million residents.").
+		WithContextList("Paris is the capital of France.", "The Seine flows through Paris.")
+
+	result := NewFaithfulness(provider).Score(context.Background(), input)
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	if result.Value != 0.5 {
+		t.Errorf("Value = %v, want 0.5", result.Value)
+	}
+	if !strings.Contains(result.Reason, "1/2 claims") || !strings.Contains(result.Reason, "2 million") {
+		t.Errorf("Reason = %q", result.Reason)
+	}
+	verdicts, ok := result.Metadata[MetadataClaims].([]ClaimVerdict)
+	if !ok || len(verdicts) != 2 || verdicts[1].Supported {
+		t.Errorf("claims metadata = %#v", result.Metadata[MetadataClaims])
+	}
+	if calls.Load() != 2 {
+		t.Errorf("judge called %d times, want 2", calls.Load())
+	}
+	if p := result.Provenance; p == nil || p.PromptTokens != 20 || p.OutputTokens != 10 {
+		t.Errorf("Provenance = %+v, want the tokens of both requests", p)
+	}
+}
+
+func TestFaithfulnessRetriesVerdictCountMismatch(t *testing.T) {
+	var verifications atomic.Int32
+	provider := NewSimpleProvider("test", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		if strings.Contains(req.Messages[0].Content, "Break the answer") {
+			return &CompletionResponse{Content: `{"claims": ["a", "b"]}`}, nil
+		}
+		if verifications.Add(1) == 1 {
+			return &CompletionResponse{Content: `{"verdicts": [{"supported": true}]}`}, nil
+		}
+		return &CompletionResponse{Content: `{"verdicts": [{"supported": true}, {"supported": true}]}`}, nil
+	})
+
+	result := NewFaithfulness(provider).Score(context.Background(), evaluation.MetricInput{Output: "a and b", Context: "a, b"})
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	if result.Value != 1 || verifications.Load() != 2 {
+		t.Errorf("Value = %v after %d verifications, want 1 after 2", result.Value, verifications.Load())
+	}
+	if result.Provenance.Retries != 1 {
+		t.Errorf("Retries = %d, want 1", result.Provenance.Retries)
+	}
+}
+
+func TestFaithfulnessNotScored(t *testing.T) {
+	var calls atomic.Int32
+	provider := promptProvider(&calls, map[string]string{"Break the answer": `{"claims": []}`})
+	metric := NewFaithfulness(provider)
+
+	if result := metric.Score(context.Background(), evaluation.MetricInput{Output: "Paris"}); !result.IsNotScored() {
+		t.Errorf("without context: %+v, want not scored", result)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("judge called %d times without context", calls.Load())
+	}
+	result := metric.Score(context.Background(), evaluation.MetricInput{Output: "Hello!", ContextList: []string{"c"}})
+	if !result.IsNotScored() || result.Reason != "output makes no claims" {
+		t.Errorf("without claims: %+v, want not scored", result)
+	}
+}
+
+func TestAnswerCorrectness(t *testing.T) {
+	var calls atomic.Int32
+	provider := promptProvider(&calls, map[string]string{
+		"Ground truth": `{"true_positives": ["The wall fell in 1989."], "false_positives": ["It fell in Munich."], "false_negatives": ["It fell on November 9."]}`,
+	})
+	input := evaluation.NewMetricInput("When did the Berlin Wall fall?", "The wall fell in 1989 in Munich.").
+		WithExpected("The Berlin Wall fell on November 9, 1989.")
+
+	metric := NewAnswerCorrectness(provider).
+		WithSimilarity(evaluation.NewMetricFunc("sim", func(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+			return evaluation.NewScoreResult("sim", 1)
+		}))
+	result := metric.Score(context.Background(), input)
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	// F1 = 1 / (1 + 0.5*2) = 0.5; 0.75*0.5 + 0.25*1 = 0.625.
+	if result.SubScores["factuality"] != 0.5 || result.SubScores["similarity"] != 1 {
+		t.Errorf("SubScores = %v", result.SubScores)
+	}
+	if result.Value != 0.625 {
+		t.Errorf("Value = %v, want 0.625", result.Value)
+	}
+	statements, ok := result.Metadata[MetadataStatements].(map[string][]string)
+	if !ok || len(statements["false_negatives"]) != 1 {
+		t.Errorf("statements metadata = %#v", result.Metadata[MetadataStatements])
+	}
+
+	result = metric.WithWeights(1, 0).Score(context.Background(), input)
+	if result.Value != 0.5 {
+		t.Errorf("factuality only: Value = %v, want 0.5", result.Value)
+	}
+	if _, ok := result.SubScores["similarity"]; ok {
+		t.Error("similarity scored with zero weight")
+	}
+}
+
+func TestAnswerCorrectnessNotScoredWithoutExpected(t *testing.T) {
+	var calls atomic.Int32
+	result := NewAnswerCorrectness(promptProvider(&calls, nil)).Score(context.Background(), evaluation.MetricInput{Output: "1989"})
+	if !result.IsNotScored() || calls.Load() != 0 {
+		t.Errorf("result = %+v after %d calls, want not scored", result, calls.Load())
+	}
+}
+
+func TestAnswerCorrectnessValidate(t *testing.T) {
+	provider := NewMockProvider(nil, "")
+	if err := NewAnswerCorrectness(provider).Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	for _, m := range []*AnswerCorrectness{
+		NewAnswerCorrectness(provider).WithWeights(-1, 1),
+		NewAnswerCorrectness(provider).WithWeights(0, 0),
+		NewAnswerCorrectness(provider).WithSimilarity(nil),
+		NewAnswerCorrectness(nil),
+	} {
+		var verr *evaluation.ValidationError
+		if err := m.Validate(); !errors.As(err, &verr) {
+			t.Errorf("Validate() = %v, want a ValidationError", err)
+		}
+	}
+}
+
+func TestContextRelevancy(t *testing.T) {
+	var calls atomic.Int32
+	provider := promptProvider(&calls, map[string]string{
+		"Passage: Paris is the capital": `{"score": 1.0, "reason": "answers the question"}`,
+		"Passage: The Seine":            `{"score": 0.5, "reason": "on topic"}`,
+		"Passage: Bananas":              `{"score": 0.0, "reason": "unrelated"}`,
+	})
+	input := evaluation.NewMetricInput("What is the capital of France?", "Paris").
+		WithContextList("Paris is the capital of France.", "The Seine flows through Paris.", "Bananas are yellow.")
+
+	result := NewContextRelevancy(provider).Score(context.Background(), input)
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	if result.Value != 0.5 {
+		t.Errorf("Value = %v, want 0.5", result.Value)
+	}
+	scores, ok := result.Metadata[MetadataChunkScores].([]float64)
+	if !ok || len(scores) != 3 || scores[0] != 1 || scores[2] != 0 {
+		t.Errorf("chunk scores = %#v", result.Metadata[MetadataChunkScores])
+	}
+	if result.Provenance == nil || result.Provenance.PromptTokens != 30 {
+		t.Errorf("Provenance = %+v, want the tokens of all chunks", result.Provenance)
+	}
+
+	result = NewContextRelevancy(provider).WithAggregation(WindowMin).Score(context.Background(), input)
+	if result.Value != 0 || !strings.Contains(result.Reason, "chunk 3 of 3: unrelated") {
+		t.Errorf("min: Value = %v, Reason = %q", result.Value, result.Reason)
+	}
+	result = NewContextRelevancy(provider).WithAggregation(WindowMax).Score(context.Background(), input)
+	if result.Value != 1 {
+		t.Errorf("max: Value = %v, want 1", result.Value)
+	}
+}
+
+func TestContextRelevancyNotScored(t *testing.T) {
+	var calls atomic.Int32
+	metric := NewContextRelevancy(promptProvider(&calls, nil))
+	for _, input := range []evaluation.MetricInput{
+		{Input: "q"},
+		{ContextList: []string{"chunk"}},
+	} {
+		if result := metric.Score(context.Background(), input); !result.IsNotScored() {
+			t.Errorf("Score(%+v) = %+v, want not scored", input, result)
+		}
+	}
+	if calls.Load() != 0 {
+		t.Errorf("judge called %d times", calls.Load())
+	}
+	if err := metric.WithAggregation("median").Validate(); err == nil {
+		t.Error("Validate() accepted an unknown aggregation")
+	}
+}
+
+func TestRAGMetricsRegistered(t *testing.T) {
+	for _, name := range []string{"faithfulness", "answer_correctness", "context_relevancy"} {
+		metric, err := evaluation.NewMetric(name, WithJudgeParams(nil, NewMockProvider(nil, "")))
+		if err != nil {
+			t.Errorf("NewMetric(%q): %v", name, err)
+			continue
+		}
+		if metric.Name() != name {
+			t.Errorf("NewMetric(%q).Name() = %q", name, metric.Name())
+		}
+	}
+	params := WithJudgeParams(evaluation.MetricParams{"aggregation": "median"}, NewMockProvider(nil, ""))
+	if _, err := evaluation.NewMetric("context_relevancy", params); err == nil {
+		t.Error("NewMetric accepted an unknown aggregation")
+	}
+}
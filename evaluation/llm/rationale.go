@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"encoding/json"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// MetadataRationaleSteps is the metadata key under which a judge's score
+// records the steps of its reasoning.
+const MetadataRationaleSteps = "rationale_steps"
+
+// RationaleStep is one step of a judge's reasoning, such as one G-EVAL
+// evaluation step or one criterion of a custom judge's rubric.
+type RationaleStep struct {
+	// Step is what the judge checked.
+	Step string `json:"step"`
+	// Reasoning is what the judge found.
+	Reasoning string `json:"reasoning,omitempty"`
+	// Score is the judge's score for the step, if it gave one.
+	Score *float64 `json:"score,omitempty"`
+}
+
+// rationaleInstruction asks the judge for its steps.
+const rationaleInstruction = `Also include a "steps" array in the JSON with one entry per step of your evaluation, in order, such as each evaluation step or criterion: {"step": "<what you checked>", "reasoning": "<what you found>", "score": <0.0-1.0>}`
+
+// WithJudgeRationale asks the judge to return the steps of its reasoning
+// as well as its overall reason. The steps are recorded in the score's
+// metadata under MetadataRationaleSteps, so the scores where judges and
+// human reviewers disagree can be traced to the step where they part.
+//
+// The steps are a chain of thought written into the response. Use the
+// option only with models and providers whose terms allow it; the
+// response, and the cost of the judge, grow with the number of steps.
+func WithJudgeRationale() JudgeOption {
+	return func(j *BaseJudge) {
+		j.rationale = true
+	}
+}
+
+// Rationale reports whether the judge was created with WithJudgeRationale.
+func (j *BaseJudge) Rationale() bool {
+	return j.rationale
+}
+
+// RationaleSteps returns the steps recorded in a score's metadata, or nil
+// if there are none. It reads scores created by a judge and scores decoded
+// from JSON, such as saved evaluation results.
+func RationaleSteps(result *evaluation.ScoreResult) []RationaleStep {
+	if result == nil || result.Metadata[MetadataRationaleSteps] == nil {
+		return nil
+	}
+	if steps, ok := result.Metadata[MetadataRationaleSteps].([]RationaleStep); ok {
+		return steps
+	}
+	data, err := json.Marshal(result.Metadata[MetadataRationaleSteps])
+	if err != nil {
+		return nil
+	}
+	var steps []RationaleStep
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil
+	}
+	return steps
+}
+
+// appendInstruction adds an instruction to the last user message, or adds
+// a user message if there is none. Providers that accept a single system
+// message keep working, since none is added.
+func appendInstruction(messages []Message, instruction string) []Message {
+	out := append([]Message(nil), messages...)
+	for i := len(out) - 1; i >= 0; i-- {
+		if out[i].Role == "user" {
+			out[i].Content += "\n\n" + instruction
+			return out
+		}
+	}
+	return append(out, Message{Role: "user", Content: instruction})
+}
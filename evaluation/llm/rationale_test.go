@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// stepsProvider records prompts and answers with a two-step rationale.
+type stepsProvider struct {
+	messagesProvider
+}
+
+func (p *stepsProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	_, _ = p.messagesProvider.Complete(ctx, req)
+	return &CompletionResponse{Content: `{"score": 0.5, "reason": "partly concise", "steps": [
+		{"step": "Check length", "reasoning": "two sentences", "score": 1},
+		{"step": "Check filler", "reasoning": "opens with a greeting", "score": 0}
+	]}`}, nil
+}
+
+func TestJudgeRationale(t *testing.T) {
+	provider := &stepsProvider{}
+	m := NewGEval(provider, "be concise", WithJudgeRationale()).
+		WithEvaluationSteps([]string{"Check length", "Check filler"})
+
+	result := m.Score(context.Background(), evaluation.MetricInput{Input: "hi", Output: "Hello! It is 4."})
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	prompt := provider.sent[0][len(provider.sent[0])-1].Content
+	if !strings.Contains(prompt, `include a "steps" array`) {
+		t.Errorf("prompt does not ask for steps:\n%s", prompt)
+	}
+
+	steps := RationaleSteps(result)
+	if len(steps) != 2 || steps[0].Step != "Check length" || steps[1].Reasoning != "opens with a greeting" {
+		t.Fatalf("steps = %+v", steps)
+	}
+	if steps[1].Score == nil || *steps[1].Score != 0 {
+		t.Errorf("step score = %v, want 0", steps[1].Score)
+	}
+	if result.Reason != "partly concise" {
+		t.Errorf("reason = %q", result.Reason)
+	}
+
+	// Steps survive a JSON round trip of the result.
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	var decoded evaluation.ScoreResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if got := RationaleSteps(&decoded); len(got) != 2 || got[1].Step != "Check filler" {
+		t.Errorf("decoded steps = %+v", got)
+	}
+}
+
+func TestJudgeRationaleOff(t *testing.T) {
+	provider := &messagesProvider{}
+	result := NewCoherence(provider).Score(context.Background(), evaluation.MetricInput{Output: "text"})
+	if strings.Contains(provider.sent[0][len(provider.sent[0])-1].Content, `"steps"`) {
+		t.Error("judge without WithJudgeRationale should not ask for steps")
+	}
+	if steps := RationaleSteps(result); steps != nil {
+		t.Errorf("steps = %+v, want nil", steps)
+	}
+	if RationaleSteps(nil) != nil {
+		t.Error("RationaleSteps(nil) should be nil")
+	}
+}
+
+func TestJudgeRationaleWithLanguage(t *testing.T) {
+	provider := &messagesProvider{}
+	NewCoherence(provider, WithJudgeRationale(), WithJudgeLanguage("fr")).Score(context.Background(), evaluation.MetricInput{Output: "text"})
+	prompt := provider.sent[0][len(provider.sent[0])-1].Content
+	steps, language := strings.Index(prompt, `"steps"`), strings.Index(prompt, "in French")
+	if steps < 0 || language < steps {
+		t.Errorf("prompt should ask for steps, then the language:\n%s", prompt)
+	}
+}
@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// Grade is a ReferenceGrader's verdict on an output.
+type Grade string
+
+// Grades of a ReferenceGrader.
+const (
+	GradeCorrect          Grade = "correct"
+	GradePartiallyCorrect Grade = "partially_correct"
+	GradeIncorrect        Grade = "incorrect"
+)
+
+// MetadataGrade is the score result metadata key of a ReferenceGrader's
+// grade.
+const MetadataGrade = "grade"
+
+// ReferenceGrader grades the output against the expected reference answer,
+// as correct, partially correct, or incorrect. Differences in phrasing,
+// formatting, and length that do not change the answer are tolerated, so
+// it suits question answering where exact or fuzzy string matching is too
+// strict. This is often called model-graded QA.
+//
+// Correct scores 1.0, partially correct 0.5, and incorrect 0.0; the grade
+// is recorded in the score result's "grade" metadata.
+type ReferenceGrader struct {
+	*BaseJudge
+	rubric  string
+	partial float64
+}
+
+// NewReferenceGrader creates a reference grader.
+func NewReferenceGrader(provider Provider, opts ...JudgeOption) *ReferenceGrader {
+	g := &ReferenceGrader{
+		BaseJudge: NewBaseJudge("reference_grader", provider, opts...),
+		partial:   0.5,
+	}
+	g.check = g.checkGrade
+	g.grades = []string{string(GradeCorrect), string(GradePartiallyCorrect), string(GradeIncorrect)}
+	return g
+}
+
+// WithRubric adds grading instructions for the task, such as which details
+// of the reference an answer must include to be correct.
+func (g *ReferenceGrader) WithRubric(rubric string) *ReferenceGrader {
+	g.rubric = rubric
+	return g
+}
+
+// WithPartialCredit sets the score of a partially correct output; the
+// default is 0.5. Use 0 to grade pass or fail.
+func (g *ReferenceGrader) WithPartialCredit(score float64) *ReferenceGrader {
+	g.partial = score
+	return g
+}
+
+// Score grades the output against input.Expected. If there is no expected
+// output, the input is not scored and the judge is not called. The judge's
+// prompt template, if set with WithJudgePromptRef, gets the rubric as
+// {{rubric}}.
+func (g *ReferenceGrader) Score(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+	if strings.TrimSpace(input.Expected) == "" {
+		return evaluation.NewNotScoredResult(g.Name(), "no expected output")
+	}
+
+	rubric := ""
+	if g.rubric != "" {
+		rubric = fmt.Sprintf("\nAdditional grading instructions:\n%s\n", g.rubric)
+	}
+	prompt := fmt.Sprintf(`You are grading an AI response against a reference answer.
+
+Question: %s
+
+Reference answer: %s
+
+AI response: %s
+
+Grade whether the response gives the same answer as the reference. Judge only correctness against the reference, not style:
+- Ignore differences in wording, phrasing, formatting, capitalization, and length.
+- Extra detail is fine as long as it does not contradict the reference.
+- Treat the reference as true; do not use your own knowledge to overrule it.
+%s
+Return your response in JSON format:
+{"grade": "<correct|partially_correct|incorrect>", "reason": "<what matches or differs from the reference>"}
+
+Where:
+- correct: The response conveys all of the reference's key information and contradicts none of it
+- partially_correct: The response conveys some of the reference's key information, but omits or gets wrong part of it
+- incorrect: The response contradicts the reference, misses its key information, or does not answer`, input.Input, input.Expected, input.Output, rubric)
+
+	messages, err := g.PromptMessages(ctx, prompt, input, map[string]string{"rubric": g.rubric})
+	if err != nil {
+		return evaluation.NewFailedScoreResult(g.Name(), err)
+	}
+
+	sr, err := ScoreWithRetry(ctx, g.BaseJudge, messages, 3)
+	if err != nil {
+		return evaluation.NewFailedScoreResult(g.Name(), err)
+	}
+
+	result := g.NewScoreResult(sr)
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]any)
+	}
+	result.Metadata[MetadataGrade] = sr.Grade
+	return result
+}
+
+// checkGrade sets the score of the response's grade, and fails if the
+// grade is not one of the three.
+func (g *ReferenceGrader) checkGrade(sr *ScoreResponse) error {
+	grade := Grade(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(sr.Grade)), " ", "_"))
+	switch grade {
+	case GradeCorrect:
+		sr.Score = 1
+	case GradePartiallyCorrect:
+		sr.Score = g.partial
+	case GradeIncorrect:
+		sr.Score = 0
+	default:
+		return fmt.Errorf("unknown grade %q", sr.Grade)
+	}
+	sr.Grade = string(grade)
+	return nil
+}
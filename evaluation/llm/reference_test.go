@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+func TestReferenceGrader(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		partial  float64
+		want     float64
+		grade    string
+	}{
+		{"correct", `{"grade": "correct", "reason": "same city"}`, 0.5, 1, "correct"},
+		{"partially correct", `{"grade": "Partially Correct", "reason": "missing the year"}`, 0.5, 0.5, "partially_correct"},
+		{"pass or fail", `{"grade": "partially_correct", "reason": "missing the year"}`, 0, 0, "partially_correct"},
+		{"incorrect", `{"grade": "incorrect", "reason": "wrong city"}`, 0.5, 0, "incorrect"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grader := NewReferenceGrader(NewMockProvider(nil, tt.response)).WithPartialCredit(tt.partial)
+			input := evaluation.NewMetricInput("What is the capital of France?", "It's Paris.").WithExpected("Paris")
+
+			result := grader.Score(context.Background(), input)
+			if result.Error != nil {
+				t.Fatalf("Score error: %v", result.Error)
+			}
+			if result.Name != "reference_grader" {
+				t.Errorf("Name = %q", result.Name)
+			}
+			if result.Value != tt.want {
+				t.Errorf("Value = %v, want %v", result.Value, tt.want)
+			}
+			if result.Metadata[MetadataGrade] != tt.grade {
+				t.Errorf("grade metadata = %v, want %q", result.Metadata[MetadataGrade], tt.grade)
+			}
+		})
+	}
+}
+
+func TestReferenceGraderPrompt(t *testing.T) {
+	var prompt string
+	provider := NewSimpleProvider("test", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		prompt = req.Messages[len(req.Messages)-1].Content
+		return &CompletionResponse{Content: `{"grade": "correct", "reason": "ok"}`}, nil
+	})
+	grader := NewReferenceGrader(provider).WithRubric("The answer must name the year.")
+
+	input := evaluation.NewMetricInput("When did the Berlin Wall fall?", "In 1989.").WithExpected("November 9, 1989")
+	if result := grader.Score(context.Background(), input); result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	for _, want := range []string{"November 9, 1989", "In 1989.", "The answer must name the year.", "phrasing"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt does not contain %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestReferenceGraderRetriesUnknownGrade(t *testing.T) {
+	var calls atomic.Int32
+	provider := NewSimpleProvider("test", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		if calls.Add(1) == 1 {
+			return &CompletionResponse{Content: `{"grade": "mostly right", "reason": "?"}`}, nil
+		}
+		return &CompletionResponse{Content: `{"grade": "correct", "reason": "ok"}`}, nil
+	})
+
+	result := NewReferenceGrader(provider).Score(context.Background(), evaluation.MetricInput{Output: "4", Expected: "four"})
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	if result.Value != 1 || calls.Load() != 2 {
+		t.Errorf("Value = %v after %d calls, want 1 after 2", result.Value, calls.Load())
+	}
+
+	result = NewReferenceGrader(NewMockProvider(nil, `{"grade": "mostly right"}`)).
+		Score(context.Background(), evaluation.MetricInput{Output: "4", Expected: "four"})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "unknown grade") {
+		t.Errorf("Error = %v, want unknown grade", result.Error)
+	}
+}
+
+func TestReferenceGraderNotScoredWithoutExpected(t *testing.T) {
+	var calls atomic.Int32
+	provider := NewSimpleProvider("test", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		calls.Add(1)
+		return &CompletionResponse{Content: `{"grade": "correct"}`}, nil
+	})
+
+	result := NewReferenceGrader(provider).Score(context.Background(), evaluation.MetricInput{Output: "Paris"})
+	if !result.IsNotScored() {
+		t.Errorf("result = %+v, want not scored", result)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("judge called %d times", calls.Load())
+	}
+}
+
+func TestReferenceGraderRegistered(t *testing.T) {
+	params := WithJudgeParams(evaluation.MetricParams{"partial_credit": 0.25}, NewMockProvider(nil, `{"grade": "partially_correct"}`))
+	metric, err := evaluation.NewMetric("reference_grader", params)
+	if err != nil {
+		t.Fatalf("NewMetric: %v", err)
+	}
+	result := metric.Score(context.Background(), evaluation.MetricInput{Output: "Paris", Expected: "Paris, France"})
+	if result.Value != 0.25 {
+		t.Errorf("Value = %v, want 0.25", result.Value)
+	}
+}
@@ -0,0 +1,221 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// ErrNoJudgeProvider is returned when a registered judge metric is created
+// without a provider attached to its parameters.
+var ErrNoJudgeProvider = errors.New("metric requires a judge provider")
+
+// judgeParamKey is the parameter key under which the judge is attached.
+// It cannot be set from suite files, which only contain plain values.
+const judgeParamKey = "__judge"
+
+// judgeParams is the judge attached to metric parameters.
+type judgeParams struct {
+	provider Provider
+	opts     []JudgeOption
+}
+
+// WithJudgeParams returns a copy of params with a judge provider and options
+// attached, for creating registered judge metrics with evaluation.NewMetric.
+func WithJudgeParams(params evaluation.MetricParams, provider Provider, opts ...JudgeOption) evaluation.MetricParams {
+	out := make(evaluation.MetricParams, len(params)+1)
+	for k, v := range params {
+		out[k] = v
+	}
+	out[judgeParamKey] = &judgeParams{provider: provider, opts: opts}
+	return out
+}
+
+// JudgeFromParams returns the judge provider and options attached with
+// WithJudgeParams. Third-party judge metrics use it in their factories:
+//
+//	evaluation.Register("tone", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+//	    provider, opts, err := llm.JudgeFromParams(p)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return llm.NewCustomJudge("tone", tonePrompt, provider, opts...), nil
+//	})
+func JudgeFromParams(params evaluation.MetricParams) (Provider, []JudgeOption, error) {
+	j, ok := params[judgeParamKey].(*judgeParams)
+	if !ok || j.provider == nil {
+		return nil, nil, ErrNoJudgeProvider
+	}
+	return j.provider, j.opts, nil
+}
+
+func init() {
+	evaluation.Register("answer_relevance", judgeMetric(func(p Provider, opts []JudgeOption) evaluation.Metric {
+		return NewAnswerRelevance(p, opts...)
+	}))
+	evaluation.Register("hallucination", judgeMetric(func(p Provider, opts []JudgeOption) evaluation.Metric {
+		return NewHallucination(p, opts...)
+	}))
+	evaluation.Register("context_recall", judgeMetric(func(p Provider, opts []JudgeOption) evaluation.Metric {
+		return NewContextRecall(p, opts...)
+	}))
+	evaluation.Register("context_precision", judgeMetric(func(p Provider, opts []JudgeOption) evaluation.Metric {
+		return NewContextPrecision(p, opts...)
+	}))
+	evaluation.Register("moderation", judgeMetric(func(p Provider, opts []JudgeOption) evaluation.Metric {
+		return NewModeration(p, opts...)
+	}))
+	evaluation.Register("factuality", judgeMetric(func(p Provider, opts []JudgeOption) evaluation.Metric {
+		return NewFactuality(p, opts...)
+	}))
+	evaluation.Register("coherence", judgeMetric(func(p Provider, opts []JudgeOption) evaluation.Metric {
+		return NewCoherence(p, opts...)
+	}))
+	evaluation.Register("helpfulness", judgeMetric(func(p Provider, opts []JudgeOption) evaluation.Metric {
+		return NewHelpfulness(p, opts...)
+	}))
+	evaluation.Register("faithfulness", judgeMetric(func(p Provider, opts []JudgeOption) evaluation.Metric {
+		return NewFaithfulness(p, opts...)
+	}))
+	evaluation.Register("answer_correctness", func(params evaluation.MetricParams) (evaluation.Metric, error) {
+		provider, opts, err := JudgeFromParams(params)
+		if err != nil {
+			return nil, err
+		}
+		factuality, err := params.Float("factuality_weight", 0.75)
+		if err != nil {
+			return nil, err
+		}
+		similarity, err := params.Float("similarity_weight", 0.25)
+		if err != nil {
+			return nil, err
+		}
+		return NewAnswerCorrectness(provider, opts...).WithWeights(factuality, similarity), nil
+	})
+	evaluation.Register("context_relevancy", func(params evaluation.MetricParams) (evaluation.Metric, error) {
+		provider, opts, err := JudgeFromParams(params)
+		if err != nil {
+			return nil, err
+		}
+		aggregation, err := params.String("aggregation", string(WindowMean))
+		if err != nil {
+			return nil, err
+		}
+		switch WindowAggregation(aggregation) {
+		case WindowMean, WindowMin, WindowMax:
+		default:
+			return nil, fmt.Errorf(`param "aggregation" must be mean, min, or max, not %q`, aggregation)
+		}
+		return NewContextRelevancy(provider, opts...).WithAggregation(WindowAggregation(aggregation)), nil
+	})
+	evaluation.Register("pairwise_comparison", func(params evaluation.MetricParams) (evaluation.Metric, error) {
+		provider, opts, err := JudgeFromParams(params)
+		if err != nil {
+			return nil, err
+		}
+		m := NewPairwiseComparison(provider, opts...)
+		criteria, err := params.String("criteria", "")
+		if err != nil {
+			return nil, err
+		}
+		if criteria != "" {
+			m.WithCriteria(criteria)
+		}
+		key, err := params.String("baseline_key", MetadataBaseline)
+		if err != nil {
+			return nil, err
+		}
+		return m.WithBaselineKey(key), nil
+	})
+	evaluation.Register("g_eval", func(params evaluation.MetricParams) (evaluation.Metric, error) {
+		provider, opts, err := JudgeFromParams(params)
+		if err != nil {
+			return nil, err
+		}
+		criteria, err := params.String("criteria", "")
+		if err != nil {
+			return nil, err
+		}
+		if criteria == "" {
+			return nil, errors.New(`param "criteria" is required`)
+		}
+		return NewGEval(provider, criteria, opts...), nil
+	})
+	evaluation.Register("reference_grader", func(params evaluation.MetricParams) (evaluation.Metric, error) {
+		provider, opts, err := JudgeFromParams(params)
+		if err != nil {
+			return nil, err
+		}
+		rubric, err := params.String("rubric", "")
+		if err != nil {
+			return nil, err
+		}
+		partial, err := params.Float("partial_credit", 0.5)
+		if err != nil {
+			return nil, err
+		}
+		return NewReferenceGrader(provider, opts...).WithRubric(rubric).WithPartialCredit(partial), nil
+	})
+	evaluation.Register("custom_judge", func(params evaluation.MetricParams) (evaluation.Metric, error) {
+		provider, opts, err := JudgeFromParams(params)
+		if err != nil {
+			return nil, err
+		}
+		name, err := params.String("name", "custom_judge")
+		if err != nil {
+			return nil, err
+		}
+		template, err := params.String("prompt", "")
+		if err != nil {
+			return nil, err
+		}
+		if template == "" {
+			return nil, errors.New(`param "prompt" is required`)
+		}
+		return NewCustomJudge(name, template, provider, opts...), nil
+	})
+	evaluation.Register("extraction", func(params evaluation.MetricParams) (evaluation.Metric, error) {
+		provider, opts, err := JudgeFromParams(params)
+		if err != nil {
+			return nil, err
+		}
+		fields, err := params.StringMap("fields")
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			return nil, errors.New(`param "fields" is required`)
+		}
+		tolerance, err := params.Float("tolerance", 0)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		schema := make(ExtractionSchema, 0, len(names))
+		for _, name := range names {
+			switch FieldType(fields[name]) {
+			case FieldString, FieldNumber, FieldDate, FieldList:
+			default:
+				return nil, fmt.Errorf("param %q.%s: unknown field type %q", "fields", name, fields[name])
+			}
+			schema = append(schema, ExtractionField{Name: name, Type: FieldType(fields[name]), Tolerance: tolerance})
+		}
+		return NewExtractionJudge(provider, schema, opts...), nil
+	})
+}
+
+func judgeMetric(fn func(provider Provider, opts []JudgeOption) evaluation.Metric) evaluation.MetricFactory {
+	return func(params evaluation.MetricParams) (evaluation.Metric, error) {
+		provider, opts, err := JudgeFromParams(params)
+		if err != nil {
+			return nil, err
+		}
+		return fn(provider, opts), nil
+	}
+}
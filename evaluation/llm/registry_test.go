@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+func TestJudgeParams(t *testing.T) {
+	provider := NewMockProvider(nil, `{"score": 1}`)
+	params := evaluation.MetricParams{"criteria": "accuracy"}
+
+	withJudge := WithJudgeParams(params, provider, WithJudgeModel("judge-model"))
+	if _, ok := params[judgeParamKey]; ok {
+		t.Error("WithJudgeParams should not modify the original params")
+	}
+
+	got, opts, err := JudgeFromParams(withJudge)
+	if err != nil {
+		t.Fatalf("JudgeFromParams error: %v", err)
+	}
+	if got != provider || len(opts) != 1 {
+		t.Errorf("JudgeFromParams = %v, %d opts", got, len(opts))
+	}
+
+	if _, _, err := JudgeFromParams(params); !errors.Is(err, ErrNoJudgeProvider) {
+		t.Errorf("err = %v, want ErrNoJudgeProvider", err)
+	}
+}
+
+func TestRegisteredJudgeMetrics(t *testing.T) {
+	provider := NewMockProvider(nil, `{"score": 1}`)
+
+	m, err := evaluation.NewMetric("hallucination", WithJudgeParams(nil, provider, WithJudgeModel("judge-model")))
+	if err != nil {
+		t.Fatalf("NewMetric error: %v", err)
+	}
+	judge, ok := m.(*Hallucination)
+	if !ok {
+		t.Fatalf("metric = %T, want *Hallucination", m)
+	}
+	if judge.Model() != "judge-model" {
+		t.Errorf("Model() = %q, want judge-model", judge.Model())
+	}
+
+	if _, err := evaluation.NewMetric("hallucination", nil); !errors.Is(err, ErrNoJudgeProvider) {
+		t.Errorf("err = %v, want ErrNoJudgeProvider", err)
+	}
+
+	if _, err := evaluation.NewMetric("g_eval", WithJudgeParams(nil, provider)); err == nil {
+		t.Error("expected error for missing criteria")
+	}
+
+	m, err = evaluation.NewMetric("custom_judge", WithJudgeParams(evaluation.MetricParams{
+		"name":   "tone",
+		"prompt": "Rate {{output}}",
+	}, provider))
+	if err != nil {
+		t.Fatalf("NewMetric error: %v", err)
+	}
+	if m.Name() != "tone" {
+		t.Errorf("Name() = %q, want tone", m.Name())
+	}
+}
@@ -0,0 +1,159 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation/textnorm"
+)
+
+// ErrNoRoute is returned by a RouterProvider when no rule matches a request.
+var ErrNoRoute = errors.New("no route matches the judge request")
+
+// RouteMatcher reports whether a routing rule applies to a request.
+type RouteMatcher func(ctx context.Context, req CompletionRequest) bool
+
+// RouteRule sends matching judge requests to a provider and model.
+type RouteRule struct {
+	// Name identifies the rule in ScoreResult provenance.
+	Name string
+	// Match selects the requests the rule applies to. A nil Match matches
+	// every request, so it can be used for a final default rule.
+	Match RouteMatcher
+	// Provider handles the matching requests.
+	Provider Provider
+	// Model overrides the requested model. Defaults to the provider's
+	// default model.
+	Model string
+}
+
+// RouterProvider routes judge requests to different providers and models
+// by rule, for example sending short answers to a small model and nuanced
+// criteria to a frontier model to cut evaluation costs. Rules are tried in
+// order and the first match wins. The name of the matching rule is recorded
+// in the provenance of the resulting score.
+type RouterProvider struct {
+	rules []RouteRule
+}
+
+// NewRouterProvider creates a provider that routes requests by rule.
+func NewRouterProvider(rules ...RouteRule) *RouterProvider {
+	return &RouterProvider{rules: rules}
+}
+
+// Route returns the rule that applies to a request.
+func (p *RouterProvider) Route(ctx context.Context, req CompletionRequest) (RouteRule, error) {
+	for _, rule := range p.rules {
+		if rule.Match == nil || rule.Match(ctx, req) {
+			return rule, nil
+		}
+	}
+	return RouteRule{}, ErrNoRoute
+}
+
+// Complete sends the request to the provider and model of the first
+// matching rule.
+func (p *RouterProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	rule, err := p.Route(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if rule.Provider == nil {
+		return nil, fmt.Errorf("route %s has no provider", rule.Name)
+	}
+
+	req.Model = rule.Model
+	if req.Model == "" {
+		req.Model = rule.Provider.DefaultModel()
+	}
+	resp, err := rule.Provider.Complete(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("route %s: %w", rule.Name, err)
+	}
+	if resp.Model == "" {
+		resp.Model = req.Model
+	}
+	resp.Route = rule.Name
+	return resp, nil
+}
+
+// Name returns "router".
+func (p *RouterProvider) Name() string {
+	return "router"
+}
+
+// DefaultModel returns an empty string: the model is chosen per request by
+// the matching rule.
+func (p *RouterProvider) DefaultModel() string {
+	return ""
+}
+
+// MatchPromptLength matches requests whose messages total at most maxChars
+// characters, such as short answers that a small model can judge reliably.
+func MatchPromptLength(maxChars int) RouteMatcher {
+	return func(_ context.Context, req CompletionRequest) bool {
+		n := 0
+		for _, msg := range req.Messages {
+			n += len(msg.Content)
+		}
+		return n <= maxChars
+	}
+}
+
+// MatchMetric matches requests made by the named judge metrics.
+func MatchMetric(names ...string) RouteMatcher {
+	return func(_ context.Context, req CompletionRequest) bool {
+		return slices.Contains(names, req.Metric)
+	}
+}
+
+// Classifier assigns a label to a judge request, for example by asking a
+// cheap model how difficult it is to judge.
+type Classifier func(ctx context.Context, req CompletionRequest) (string, error)
+
+// MatchClassifier matches requests that classify assigns one of labels.
+// Requests it fails to classify do not match.
+func MatchClassifier(classify Classifier, labels ...string) RouteMatcher {
+	return func(ctx context.Context, req CompletionRequest) bool {
+		label, err := classify(ctx, req)
+		return err == nil && slices.Contains(labels, label)
+	}
+}
+
+// NewPromptClassifier returns a Classifier that asks provider, typically a
+// small model, to label a judge request with one of labels. The instruction
+// describes what the labels mean, for example "Label the evaluation task
+// simple if the answer is short and factual, otherwise nuanced."
+func NewPromptClassifier(provider Provider, instruction string, labels ...string) Classifier {
+	return func(ctx context.Context, req CompletionRequest) (string, error) {
+		var task strings.Builder
+		for _, msg := range req.Messages {
+			task.WriteString(msg.Content)
+			task.WriteString("\n")
+		}
+		prompt := fmt.Sprintf(`%s
+
+Evaluation task:
+%s
+Answer with exactly one of: %s`, instruction, task.String(), strings.Join(labels, ", "))
+
+		resp, err := provider.Complete(ctx, CompletionRequest{
+			Messages:  []Message{{Role: "user", Content: prompt}},
+			Model:     provider.DefaultModel(),
+			MaxTokens: 10,
+		})
+		if err != nil {
+			return "", err
+		}
+		answer := strings.ToLower(strings.Trim(strings.TrimSpace(resp.Content), ".\"'"))
+		for _, label := range labels {
+			if strings.ToLower(label) == answer {
+				return label, nil
+			}
+		}
+		return "", fmt.Errorf("classifier answered %q, want one of %s", textnorm.Truncate(answer, 50), strings.Join(labels, ", "))
+	}
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// recordingProvider records the models it was asked for.
+type recordingProvider struct {
+	name   string
+	model  string
+	reply  string
+	models []string
+}
+
+func (p *recordingProvider) Complete(_ context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	p.models = append(p.models, req.Model)
+	return &CompletionResponse{Content: p.reply}, nil
+}
+
+func (p *recordingProvider) Name() string         { return p.name }
+func (p *recordingProvider) DefaultModel() string { return p.model }
+
+func TestRouterProvider(t *testing.T) {
+	small := &recordingProvider{name: "small", model: "mini", reply: `{"score": 0.9, "reason": "ok"}`}
+	frontier := &recordingProvider{name: "frontier", model: "large", reply: `{"score": 0.4, "reason": "subtle"}`}
+
+	router := NewRouterProvider(
+		RouteRule{Name: "nuanced-metrics", Match: MatchMetric("g_eval"), Provider: frontier, Model: "large-2"},
+		RouteRule{Name: "short", Match: MatchPromptLength(2000), Provider: small},
+		RouteRule{Name: "default", Provider: frontier},
+	)
+
+	input := evaluation.NewMetricInput("What is 2+2?", "4")
+	result := NewAnswerRelevance(router).Score(context.Background(), input)
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	if result.Value != 0.9 || result.Provenance.Route != "short" || result.Provenance.Model != "mini" {
+		t.Errorf("result = %v, provenance = %+v", result.Value, result.Provenance)
+	}
+
+	geval := NewGEval(router, "Is the answer nuanced?")
+	if r := geval.Score(context.Background(), input); r.Provenance == nil || r.Provenance.Route != "nuanced-metrics" {
+		t.Errorf("g_eval provenance = %+v", r.Provenance)
+	}
+	if len(frontier.models) != 1 || frontier.models[0] != "large-2" {
+		t.Errorf("frontier models = %v, want [large-2]", frontier.models)
+	}
+
+	long := evaluation.NewMetricInput(strings.Repeat("context ", 500), "answer")
+	if r := NewAnswerRelevance(router).Score(context.Background(), long); r.Provenance.Route != "default" {
+		t.Errorf("long input route = %q, want default", r.Provenance.Route)
+	}
+}
+
+func TestRouterProviderNoRoute(t *testing.T) {
+	router := NewRouterProvider(RouteRule{Name: "never", Match: MatchMetric("none"), Provider: NewMockProvider(nil, "")})
+	_, err := router.Complete(context.Background(), CompletionRequest{Metric: "other"})
+	if !errors.Is(err, ErrNoRoute) {
+		t.Errorf("err = %v, want ErrNoRoute", err)
+	}
+}
+
+func TestPromptClassifier(t *testing.T) {
+	classifier := NewPromptClassifier(
+		NewMockProvider(nil, " Nuanced.\n"),
+		"Label the task simple or nuanced.",
+		"simple", "nuanced",
+	)
+	req := CompletionRequest{Messages: []Message{{Role: "user", Content: "judge this"}}}
+
+	label, err := classifier(context.Background(), req)
+	if err != nil || label != "nuanced" {
+		t.Errorf("label = %q, err = %v", label, err)
+	}
+	if !MatchClassifier(classifier, "nuanced")(context.Background(), req) {
+		t.Error("MatchClassifier should match the nuanced label")
+	}
+	if MatchClassifier(classifier, "simple")(context.Background(), req) {
+		t.Error("MatchClassifier should not match the simple label")
+	}
+
+	unsure := NewPromptClassifier(NewMockProvider(nil, "maybe"), "Label it.", "simple", "nuanced")
+	if _, err := unsure(context.Background(), req); err == nil {
+		t.Error("expected error for an unknown label")
+	}
+}
@@ -0,0 +1,211 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation/textnorm"
+)
+
+// responseSchema returns the schema of the judge's score responses, or nil
+// if its provider is not a StructuredProvider. The response has a score,
+// or a grade for a judge created with grades, and a reason; it has steps
+// for a judge created with WithJudgeRationale.
+func (j *BaseJudge) responseSchema() *ResponseSchema {
+	if _, ok := j.provider.(StructuredProvider); !ok {
+		return nil
+	}
+
+	properties := map[string]any{
+		"score":      map[string]any{"type": "number"},
+		"reason":     map[string]any{"type": "string"},
+		"confidence": map[string]any{"type": "number", "minimum": 0, "maximum": 1},
+		"sub_scores": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "number"}},
+	}
+	required := []string{"score", "reason"}
+	if len(j.grades) > 0 {
+		properties["grade"] = map[string]any{"type": "string", "enum": j.grades}
+		required = []string{"grade", "reason"}
+	}
+	if j.rationale {
+		properties["steps"] = map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"step":      map[string]any{"type": "string"},
+					"reasoning": map[string]any{"type": "string"},
+					"score":     map[string]any{"type": "number"},
+				},
+				"required": []string{"step"},
+			},
+		}
+		required = append(required, "steps")
+	}
+
+	return &ResponseSchema{
+		Name:        "score",
+		Description: "The judge's evaluation of the output.",
+		Schema: map[string]any{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		},
+	}
+}
+
+// parseStructuredResponse decodes a structured score response, failing if
+// it does not match schema.
+func parseStructuredResponse(content string, schema *ResponseSchema) (*ScoreResponse, error) {
+	var raw any
+	if err := ParseJSONResponse(content, &raw); err != nil {
+		return nil, fmt.Errorf("could not parse structured response: %s", textnorm.Truncate(content, 100))
+	}
+	if err := validateSchema(schema.Schema, raw, ""); err != nil {
+		return nil, fmt.Errorf("response does not match schema %q: %w", schema.Name, err)
+	}
+	var sr ScoreResponse
+	if err := ParseJSONResponse(content, &sr); err != nil {
+		return nil, fmt.Errorf("response does not match schema %q: %w", schema.Name, err)
+	}
+	return &sr, nil
+}
+
+// schemaHash returns a hash of schema, to tell requests for different
+// schemas apart.
+func schemaHash(schema *ResponseSchema) string {
+	data, _ := json.Marshal(schema)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// validateSchema checks a decoded JSON value against the subset of JSON
+// Schema judges use: type, enum, properties, required,
+// additionalProperties, items, minimum, and maximum. path locates v in
+// error messages.
+func validateSchema(schema map[string]any, v any, path string) error {
+	at := path
+	if at == "" {
+		at = "response"
+	}
+
+	if typ, ok := schema["type"].(string); ok && !hasType(v, typ) {
+		return fmt.Errorf("%s: want %s, got %s", at, typ, jsonType(v))
+	}
+	if enum, ok := schema["enum"]; ok && !slices.ContainsFunc(anySlice(enum), func(e any) bool { return e == v }) {
+		return fmt.Errorf("%s: %v is not one of %v", at, v, enum)
+	}
+	if n, ok := v.(float64); ok {
+		if lo, ok := number(schema["minimum"]); ok && n < lo {
+			return fmt.Errorf("%s: %v is below the minimum %v", at, n, lo)
+		}
+		if hi, ok := number(schema["maximum"]); ok && n > hi {
+			return fmt.Errorf("%s: %v is above the maximum %v", at, n, hi)
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range anySlice(schema["required"]) {
+			if _, ok := v[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing %q", at, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sub, ok := properties[k].(map[string]any)
+			if !ok {
+				switch extra := schema["additionalProperties"].(type) {
+				case bool:
+					if !extra {
+						return fmt.Errorf("%s: unexpected %q", at, k)
+					}
+					continue
+				case map[string]any:
+					sub = extra
+				default:
+					continue
+				}
+			}
+			if err := validateSchema(sub, v[k], strings.TrimPrefix(path+"."+k, ".")); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasType reports whether v has the JSON Schema type typ.
+func hasType(v any, typ string) bool {
+	if typ == "integer" {
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return jsonType(v) == typ
+}
+
+// jsonType returns the JSON Schema type of a decoded JSON value.
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// anySlice converts a schema list, which may be built in Go as a typed
+// slice, to []any.
+func anySlice(v any) []any {
+	switch v := v.(type) {
+	case []any:
+		return v
+	case []string:
+		out := make([]any, len(v))
+		for i, s := range v {
+			out[i] = s
+		}
+		return out
+	}
+	return nil
+}
+
+// number converts a schema number, which may be built in Go as an int, to
+// float64.
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// schemaProvider is a StructuredProvider returning responses in turn, and
+// recording the schemas it was asked for.
+type schemaProvider struct {
+	*SimpleProvider
+	responses []string
+	calls     atomic.Int32
+	schemas   []ResponseSchema
+}
+
+func newSchemaProvider(responses ...string) *schemaProvider {
+	p := &schemaProvider{responses: responses}
+	p.SimpleProvider = NewSimpleProvider("structured", "model", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		return nil, errors.New("Complete called on a structured provider")
+	})
+	return p
+}
+
+func (p *schemaProvider) CompleteStructured(ctx context.Context, req CompletionRequest, schema ResponseSchema) (*CompletionResponse, error) {
+	n := int(p.calls.Add(1)) - 1
+	p.schemas = append(p.schemas, schema)
+	return &CompletionResponse{Content: p.responses[min(n, len(p.responses)-1)]}, nil
+}
+
+func TestScoreWithRetryStructured(t *testing.T) {
+	provider := newSchemaProvider(`{"score": 0.8, "reason": "accurate", "confidence": 0.9}`)
+	judge := NewBaseJudge("judge", provider)
+
+	sr, err := ScoreWithRetry(context.Background(), judge, []Message{{Role: "user", Content: "Rate it."}}, 3)
+	if err != nil {
+		t.Fatalf("ScoreWithRetry error: %v", err)
+	}
+	if sr.Score != 0.8 || sr.Reason != "accurate" || *sr.Confidence != 0.9 {
+		t.Errorf("response = %+v", sr)
+	}
+	if len(provider.schemas) != 1 || provider.schemas[0].Name != "score" {
+		t.Fatalf("schemas = %+v", provider.schemas)
+	}
+	required := provider.schemas[0].Schema["required"]
+	if got, _ := json.Marshal(required); string(got) != `["score","reason"]` {
+		t.Errorf("required = %s", got)
+	}
+}
+
+func TestScoreWithRetryStructuredRetriesSchemaMismatch(t *testing.T) {
+	provider := newSchemaProvider(
+		`The score is 0.8 because it is accurate.`,
+		`{"score": "high", "reason": "accurate"}`,
+		`{"score": 0.8}`,
+		`{"score": 0.7, "reason": "mostly accurate"}`,
+	)
+	judge := NewBaseJudge("judge", provider)
+
+	_, err := ScoreWithRetry(context.Background(), judge, []Message{{Role: "user", Content: "Rate it."}}, 3)
+	if err == nil || !strings.Contains(err.Error(), `missing "reason"`) {
+		t.Fatalf("error = %v, want a schema mismatch", err)
+	}
+
+	sr, err := ScoreWithRetry(context.Background(), judge, []Message{{Role: "user", Content: "Rate it."}}, 3)
+	if err != nil {
+		t.Fatalf("ScoreWithRetry error: %v", err)
+	}
+	if sr.Score != 0.7 || sr.Provenance.Retries != 0 {
+		t.Errorf("response = %+v", sr)
+	}
+}
+
+func TestScoreWithRetryStructuredRationale(t *testing.T) {
+	provider := newSchemaProvider(
+		`{"score": 1, "reason": "ok"}`,
+		`{"score": 1, "reason": "ok", "steps": [{"step": "facts", "score": 1}]}`,
+	)
+	judge := NewBaseJudge("judge", provider, WithJudgeRationale())
+
+	sr, err := ScoreWithRetry(context.Background(), judge, []Message{{Role: "user", Content: "Rate it."}}, 3)
+	if err != nil {
+		t.Fatalf("ScoreWithRetry error: %v", err)
+	}
+	if len(sr.Steps) != 1 || sr.Provenance.Retries != 1 {
+		t.Errorf("response = %+v after %d retries, want steps after 1", sr, sr.Provenance.Retries)
+	}
+}
+
+func TestReferenceGraderStructured(t *testing.T) {
+	provider := newSchemaProvider(
+		`{"grade": "mostly right", "reason": "close"}`,
+		`{"grade": "partially_correct", "reason": "misses the date"}`,
+	)
+	input := evaluation.NewMetricInput("q", "1989").WithExpected("November 9, 1989")
+
+	result := NewReferenceGrader(provider).Score(context.Background(), input)
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	if result.Value != 0.5 || result.Metadata[MetadataGrade] != "partially_correct" {
+		t.Errorf("result = %+v", result)
+	}
+	grade := provider.schemas[0].Schema["properties"].(map[string]any)["grade"].(map[string]any)
+	if enum := grade["enum"].([]string); len(enum) != 3 {
+		t.Errorf("grade enum = %v", enum)
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"score": map[string]any{"type": "number", "minimum": 0, "maximum": 1},
+			"label": map[string]any{"type": "string", "enum": []string{"a", "b"}},
+			"count": map[string]any{"type": "integer"},
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+		"required":             []string{"score"},
+		"additionalProperties": false,
+	}
+	tests := []struct {
+		json string
+		err  string
+	}{
+		{`{"score": 0.5, "label": "a", "count": 2, "tags": ["x"]}`, ""},
+		{`[]`, "response: want object, got array"},
+		{`{}`, `response: missing "score"`},
+		{`{"score": 1.5}`, "score: 1.5 is above the maximum 1"},
+		{`{"score": 0, "label": "c"}`, "label: c is not one of [a b]"},
+		{`{"score": 0, "count": 1.5}`, "count: want integer, got number"},
+		{`{"score": 0, "tags": ["x", 1]}`, "tags[1]: want string, got number"},
+		{`{"score": 0, "extra": true}`, `response: unexpected "extra"`},
+	}
+	for _, tt := range tests {
+		var v any
+		if err := json.Unmarshal([]byte(tt.json), &v); err != nil {
+			t.Fatal(err)
+		}
+		err := validateSchema(schema, v, "")
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("validateSchema(%s) = %v, want %q", tt.json, err, tt.err)
+		}
+	}
+}
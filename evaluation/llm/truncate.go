@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"encoding/json"
+	"maps"
+)
+
+// MetadataOmittedMessages is the metadata key under which truncated chat
+// inputs record the number of messages left out.
+const MetadataOmittedMessages = "omitted_messages"
+
+// TruncateMessages fits a conversation into limit, measured by size, by
+// leaving out whole messages from its middle; see TruncateMessagesFunc.
+func TruncateMessages(messages []Message, limit int, size func(Message) int) ([]Message, int) {
+	return TruncateMessagesFunc(messages, limit, func(m Message) string { return m.Role }, size)
+}
+
+// TruncateMessagesFunc fits a conversation into limit, measured by size,
+// by leaving out whole messages rather than cutting them. The leading
+// system (or developer) messages and the latest user turn, from the last
+// user message on, are always kept; the oldest of the other messages are
+// left out until the rest fits. It returns the kept messages and the
+// number left out.
+//
+// If the kept messages alone exceed limit, they are returned anyway, so
+// the result can still be larger than limit. A limit of zero or less
+// keeps every message.
+func TruncateMessagesFunc[M any](messages []M, limit int, role func(M) string, size func(M) int) ([]M, int) {
+	if limit <= 0 {
+		return messages, 0
+	}
+	sizes := make([]int, len(messages))
+	total := 0
+	for i, m := range messages {
+		sizes[i] = size(m)
+		total += sizes[i]
+	}
+	if total <= limit {
+		return messages, 0
+	}
+
+	head := 0
+	for head < len(messages) && isSystemRole(role(messages[head])) {
+		head++
+	}
+	if head == len(messages) {
+		return messages, 0
+	}
+	start := len(messages) - 1
+	for i := len(messages) - 1; i >= head; i-- {
+		if role(messages[i]) == "user" {
+			start = i
+			break
+		}
+	}
+
+	used := 0
+	for i := range messages[:head] {
+		used += sizes[i]
+	}
+	for i := start; i < len(messages); i++ {
+		used += sizes[i]
+	}
+	for start > head && used+sizes[start-1] <= limit {
+		start--
+		used += sizes[start]
+	}
+	if start == head {
+		return messages, 0
+	}
+
+	kept := make([]M, 0, head+len(messages)-start)
+	kept = append(kept, messages[:head]...)
+	kept = append(kept, messages[start:]...)
+	return kept, start - head
+}
+
+func isSystemRole(role string) bool {
+	return role == "system" || role == "developer"
+}
+
+// TruncateInputMessages fits the "messages" list of a chat request body,
+// such as a traced span input, into maxBytes of JSON with
+// TruncateMessagesFunc. It returns a copy of input with the kept messages
+// and the number left out, or input itself if nothing was left out.
+func TruncateInputMessages(input map[string]any, maxBytes int) (map[string]any, int) {
+	var messages []any
+	switch m := input["messages"].(type) {
+	case []any:
+		messages = m
+	case []map[string]any:
+		messages = make([]any, len(m))
+		for i, msg := range m {
+			messages[i] = msg
+		}
+	default:
+		return input, 0
+	}
+
+	role := func(msg any) string {
+		if m, ok := msg.(map[string]any); ok {
+			r, _ := m["role"].(string)
+			return r
+		}
+		return ""
+	}
+	size := func(msg any) int {
+		data, _ := json.Marshal(msg)
+		return len(data)
+	}
+	kept, omitted := TruncateMessagesFunc(messages, maxBytes, role, size)
+	if omitted == 0 {
+		return input, 0
+	}
+	out := maps.Clone(input)
+	out["messages"] = kept
+	return out, omitted
+}
@@ -0,0 +1,170 @@
+package llm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func conversation(roles ...string) []Message {
+	messages := make([]Message, len(roles))
+	for i, role := range roles {
+		messages[i] = Message{Role: role, Content: role[:1] + strings.Repeat("x", 9)}
+	}
+	return messages
+}
+
+func roles(messages []Message) []string {
+	out := make([]string, len(messages))
+	for i, m := range messages {
+		out[i] = m.Role
+	}
+	return out
+}
+
+func TestTruncateMessages(t *testing.T) {
+	size := func(m Message) int { return len(m.Content) } // 10 per message
+	tests := []struct {
+		name        string
+		roles       []string
+		limit       int
+		wantRoles   []string
+		wantOmitted int
+	}{
+		{"fits", []string{"system", "user", "assistant"}, 30, []string{"system", "user", "assistant"}, 0},
+		{"no limit", []string{"user", "assistant", "user"}, 0, []string{"user", "assistant", "user"}, 0},
+		{
+			"drops oldest turns",
+			[]string{"system", "user", "assistant", "user", "assistant", "user"},
+			40,
+			[]string{"system", "user", "assistant", "user"}, 2,
+		},
+		{
+			"keeps latest user turn over limit",
+			[]string{"system", "user", "assistant", "user", "assistant"},
+			20,
+			[]string{"system", "user", "assistant"}, 2,
+		},
+		{
+			"keeps developer messages",
+			[]string{"developer", "system", "user", "assistant", "user"},
+			30,
+			[]string{"developer", "system", "user"}, 2,
+		},
+		{"only system", []string{"system", "system"}, 10, []string{"system", "system"}, 0},
+		{"no user message", []string{"assistant", "assistant", "assistant"}, 10, []string{"assistant"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, omitted := TruncateMessages(conversation(tt.roles...), tt.limit, size)
+			if !reflect.DeepEqual(roles(kept), tt.wantRoles) || omitted != tt.wantOmitted {
+				t.Errorf("kept %v, omitted %d; want %v, %d", roles(kept), omitted, tt.wantRoles, tt.wantOmitted)
+			}
+		})
+	}
+}
+
+func TestTruncateMessagesKeepsOrder(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "first question"},
+		{Role: "assistant", Content: "first answer"},
+		{Role: "user", Content: "second question"},
+	}
+	kept, omitted := TruncateMessages(messages, 30, func(m Message) int { return len(m.Content) })
+	if omitted != 2 || !reflect.DeepEqual(kept, []Message{messages[0], messages[3]}) {
+		t.Errorf("kept %v, omitted %d", kept, omitted)
+	}
+	if len(messages) != 4 || messages[1].Content != "first question" {
+		t.Errorf("input modified: %v", messages)
+	}
+}
+
+func TestTruncateInputMessages(t *testing.T) {
+	input := map[string]any{
+		"model": "gpt-4o",
+		"messages": []any{
+			map[string]any{"role": "system", "content": "be brief"},
+			map[string]any{"role": "user", "content": strings.Repeat("old ", 50)},
+			map[string]any{"role": "assistant", "content": strings.Repeat("reply ", 50)},
+			map[string]any{"role": "user", "content": "latest"},
+		},
+	}
+	out, omitted := TruncateInputMessages(input, 200)
+	if omitted != 2 {
+		t.Fatalf("omitted = %d, want 2", omitted)
+	}
+	messages := out["messages"].([]any)
+	if len(messages) != 2 || messages[1].(map[string]any)["content"] != "latest" || out["model"] != "gpt-4o" {
+		t.Errorf("output = %v", out)
+	}
+	if len(input["messages"].([]any)) != 4 {
+		t.Error("input modified")
+	}
+
+	// Typed message lists are truncated too, and other inputs are left alone.
+	typed := map[string]any{"messages": []map[string]any{
+		{"role": "user", "content": strings.Repeat("a", 100)},
+		{"role": "user", "content": "b"},
+	}}
+	if _, omitted := TruncateInputMessages(typed, 50); omitted != 1 {
+		t.Errorf("typed omitted = %d, want 1", omitted)
+	}
+	other := map[string]any{"prompt": strings.Repeat("a", 100)}
+	if out, omitted := TruncateInputMessages(other, 10); omitted != 0 || !reflect.DeepEqual(out, other) {
+		t.Errorf("prompt input = %v, omitted %d", out, omitted)
+	}
+}
+
+// messagesProvider records the messages it was sent.
+type messagesProvider struct {
+	sent [][]Message
+}
+
+func (p *messagesProvider) Complete(_ context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	p.sent = append(p.sent, req.Messages)
+	return &CompletionResponse{Content: `{"score": 1, "reason": "ok"}`}, nil
+}
+
+func (p *messagesProvider) Name() string         { return "messages" }
+func (p *messagesProvider) DefaultModel() string { return "model" }
+
+func TestBaseJudgeMaxPromptTokens(t *testing.T) {
+	provider := &messagesProvider{}
+	j := NewBaseJudge("judge", provider, WithMaxPromptTokens(9))
+	messages := []Message{
+		{Role: "system", Content: "judge it"},
+		{Role: "user", Content: strings.Repeat("old turn ", 10)},
+		{Role: "assistant", Content: "noted"},
+		{Role: "user", Content: "score the last answer"},
+	}
+
+	sr, err := ScoreWithRetry(context.Background(), j, messages, 1)
+	if err != nil {
+		t.Fatalf("ScoreWithRetry error: %v", err)
+	}
+	if sent := provider.sent[0]; len(sent) != 2 || sent[1].Content != "score the last answer" {
+		t.Errorf("sent %v, want the system message and the last user turn", sent)
+	}
+	if sr.Provenance.OmittedMessages != 2 {
+		t.Errorf("provenance omitted = %d, want 2", sr.Provenance.OmittedMessages)
+	}
+	if result := j.NewScoreResult(sr); result.Metadata[MetadataOmittedMessages] != 2 {
+		t.Errorf("metadata = %v, want %s 2", result.Metadata, MetadataOmittedMessages)
+	}
+
+	// Prompts within the limit are sent whole.
+	j = NewBaseJudge("judge", provider)
+	sr, err = ScoreWithRetry(context.Background(), j, messages, 1)
+	if err != nil {
+		t.Fatalf("ScoreWithRetry error: %v", err)
+	}
+	if len(provider.sent[1]) != 4 || j.NewScoreResult(sr).Metadata != nil {
+		t.Errorf("unlimited judge sent %d messages, metadata %v", len(provider.sent[1]), j.NewScoreResult(sr).Metadata)
+	}
+
+	if err := NewBaseJudge("judge", provider, WithMaxPromptTokens(-1)).Validate(); err == nil {
+		t.Error("Validate accepted a negative prompt limit")
+	}
+}
@@ -0,0 +1,256 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// WindowAggregation combines the scores of the context windows of one
+// input into a single score.
+type WindowAggregation string
+
+const (
+	// WindowMin keeps the lowest window score.
+	WindowMin WindowAggregation = "min"
+	// WindowMax keeps the highest window score.
+	WindowMax WindowAggregation = "max"
+	// WindowMean averages the window scores, weighted by window size.
+	WindowMean WindowAggregation = "mean"
+)
+
+// WindowConfig configures how a judge splits a context that does not fit
+// in its model's context window.
+type WindowConfig struct {
+	// MaxTokens is the largest context, in tokens, sent in one request.
+	// Leave room for the rest of the prompt and the response.
+	MaxTokens int
+	// Overlap is the number of tokens consecutive windows share, so a
+	// passage cut at a window boundary is whole in one of them.
+	Overlap int
+	// MaxWindows limits the windows scored per input; 0 means no limit.
+	// Context beyond the last window is not evaluated, and the score
+	// metadata says so.
+	MaxWindows int
+	// Aggregation combines the window scores. If empty, each metric uses
+	// the strategy that fits it; see WithContextWindow.
+	Aggregation WindowAggregation
+	// CountTokens counts the tokens of a text. If nil, tokens are
+	// estimated as four characters each.
+	CountTokens func(text string) int
+}
+
+// WithContextWindow makes context metrics (Hallucination, ContextRecall,
+// and ContextPrecision) split a context longer than config.MaxTokens into
+// overlapping windows, score each window with its own request, and combine
+// the window scores. Unless config.Aggregation is set, Hallucination keeps
+// the lowest score, since a claim is grounded if any window supports it,
+// ContextPrecision keeps the highest, and ContextRecall averages.
+//
+// Windowed scores carry the window scores and token counts in their
+// metadata, under "window_scores", "context_tokens", and
+// "evaluated_tokens", and a "truncation_warning" when config.MaxWindows
+// left part of the context unscored.
+func WithContextWindow(config WindowConfig) JudgeOption {
+	return func(j *BaseJudge) {
+		j.window = &config
+	}
+}
+
+// validateWindow reports the problems of a window configuration.
+func validateWindow(p *evaluation.Problems, c *WindowConfig) {
+	if c.MaxTokens <= 0 {
+		p.Addf("context window MaxTokens must be positive: %d", c.MaxTokens)
+	}
+	if c.Overlap < 0 || (c.MaxTokens > 0 && c.Overlap >= c.MaxTokens) {
+		p.Addf("context window Overlap must be at least 0 and below MaxTokens: %d", c.Overlap)
+	}
+	if c.MaxWindows < 0 {
+		p.Addf("context window MaxWindows must not be negative: %d", c.MaxWindows)
+	}
+	switch c.Aggregation {
+	case "", WindowMin, WindowMax, WindowMean:
+	default:
+		p.Addf("unknown context window aggregation %q", c.Aggregation)
+	}
+}
+
+// ScoreContext scores input with score. If the judge was created with
+// WithContextWindow and input.Context is longer than the window, score is
+// called once per window, with input.Context set to the window, and the
+// scores are combined with the configured aggregation, or with aggregation
+// if none is configured. Custom context judges can use it as the
+// built-in ones do.
+func (j *BaseJudge) ScoreContext(ctx context.Context, input evaluation.MetricInput, aggregation WindowAggregation, score func(context.Context, evaluation.MetricInput) *evaluation.ScoreResult) *evaluation.ScoreResult {
+	if j.window == nil {
+		return score(ctx, input)
+	}
+	config := *j.window
+	if config.Aggregation != "" {
+		aggregation = config.Aggregation
+	}
+	if config.CountTokens == nil {
+		config.CountTokens = estimateTokens
+	}
+
+	windows, total := splitWindows(input.Context, config)
+	if len(windows) <= 1 {
+		return score(ctx, input)
+	}
+	evaluated := total
+	if config.MaxWindows > 0 && len(windows) > config.MaxWindows {
+		windows = windows[:config.MaxWindows]
+		evaluated = windows[len(windows)-1].end
+	}
+
+	results := make([]*evaluation.ScoreResult, len(windows))
+	for i, w := range windows {
+		results[i] = score(ctx, input.WithContext(w.text))
+		if results[i].Error != nil {
+			return evaluation.NewFailedScoreResult(j.Name(), fmt.Errorf("context window %d of %d: %w", i+1, len(windows), results[i].Error))
+		}
+	}
+
+	result := combineWindows(j.Name(), windows, results, aggregation)
+	result.Metadata = map[string]any{
+		"window_scores":    windowScores(results),
+		"window_tokens":    config.MaxTokens,
+		"aggregation":      string(aggregation),
+		"context_tokens":   total,
+		"evaluated_tokens": evaluated,
+	}
+	if evaluated < total {
+		result.Metadata["truncation_warning"] = fmt.Sprintf(
+			"only the first %d of %d context tokens were evaluated (MaxWindows %d)", evaluated, total, config.MaxWindows)
+	}
+	return result
+}
+
+// window is a slice of a context, with the token offset it ends at.
+type window struct {
+	text   string
+	tokens int
+	end    int
+}
+
+// segmentPattern splits text into words with their trailing whitespace, so
+// windows keep the original line breaks.
+var segmentPattern = regexp.MustCompile(`\s*\S+\s*`)
+
+// splitWindows splits text into windows of at most config.MaxTokens tokens,
+// breaking between words, and returns them with the total token count.
+// Consecutive windows share about config.Overlap tokens.
+func splitWindows(text string, config WindowConfig) ([]window, int) {
+	segments := segmentPattern.FindAllString(text, -1)
+	tokens := make([]int, len(segments))
+	total := 0
+	for i, s := range segments {
+		tokens[i] = config.CountTokens(s)
+		total += tokens[i]
+	}
+	if total <= config.MaxTokens {
+		return nil, total
+	}
+
+	var windows []window
+	offset := 0 // tokens before segments[start]
+	for start := 0; start < len(segments); {
+		end, size := start, 0
+		// Always take one segment, so a word longer than the window does
+		// not stall the split.
+		for end < len(segments) && (end == start || size+tokens[end] <= config.MaxTokens) {
+			size += tokens[end]
+			end++
+		}
+		windows = append(windows, window{
+			text:   strings.TrimSpace(strings.Join(segments[start:end], "")),
+			tokens: size,
+			end:    offset + size,
+		})
+		if end == len(segments) {
+			break
+		}
+
+		// Step back over up to Overlap tokens for the next window, but
+		// always move forward.
+		next, overlap := end, 0
+		for next-1 > start && overlap+tokens[next-1] <= config.Overlap {
+			next--
+			overlap += tokens[next]
+		}
+		for _, t := range tokens[start:next] {
+			offset += t
+		}
+		start = next
+	}
+	return windows, total
+}
+
+// combineWindows combines window scores. The reason and provenance of the
+// window that decided a min or max score are kept, with the token counts
+// and latency of every window.
+func combineWindows(name string, windows []window, results []*evaluation.ScoreResult, aggregation WindowAggregation) *evaluation.ScoreResult {
+	pick := 0
+	var value float64
+	switch aggregation {
+	case WindowMin, WindowMax:
+		for i, r := range results {
+			if (aggregation == WindowMin && r.Value < results[pick].Value) ||
+				(aggregation == WindowMax && r.Value > results[pick].Value) {
+				pick = i
+			}
+		}
+		value = results[pick].Value
+	default:
+		var sum, weights float64
+		for i, r := range results {
+			w := float64(max(windows[i].tokens, 1))
+			sum += r.Value * w
+			weights += w
+		}
+		value = sum / weights
+	}
+
+	reason := fmt.Sprintf("%s of %d context windows", aggregation, len(results))
+	if aggregation != WindowMean && results[pick].Reason != "" {
+		reason = fmt.Sprintf("window %d of %d: %s", pick+1, len(results), results[pick].Reason)
+	}
+	result := evaluation.NewScoreResultWithReason(name, value, reason)
+	result.Provenance = combineProvenance(results, pick)
+	return result
+}
+
+func combineProvenance(results []*evaluation.ScoreResult, pick int) *evaluation.Provenance {
+	if results[pick].Provenance == nil {
+		return nil
+	}
+	prov := *results[pick].Provenance
+	prov.Retries, prov.PromptTokens, prov.OutputTokens, prov.Latency = 0, 0, 0, 0
+	for _, r := range results {
+		if r.Provenance == nil {
+			continue
+		}
+		prov.Retries += r.Provenance.Retries
+		prov.PromptTokens += r.Provenance.PromptTokens
+		prov.OutputTokens += r.Provenance.OutputTokens
+		prov.Latency += r.Provenance.Latency
+	}
+	return &prov
+}
+
+func windowScores(results []*evaluation.ScoreResult) []float64 {
+	scores := make([]float64, len(results))
+	for i, r := range results {
+		scores[i] = r.Value
+	}
+	return scores
+}
+
+// estimateTokens estimates the tokens of text as four characters each, as
+// evaluation dry runs do.
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
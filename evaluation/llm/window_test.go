@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// wordCount counts one token per word.
+func wordCount(text string) int {
+	return len(strings.Fields(text))
+}
+
+func TestSplitWindows(t *testing.T) {
+	config := WindowConfig{MaxTokens: 4, Overlap: 1, CountTokens: wordCount}
+
+	if windows, total := splitWindows("a b c", config); windows != nil || total != 3 {
+		t.Errorf("short text = %v, %d, want no windows and 3 tokens", windows, total)
+	}
+
+	windows, total := splitWindows("a b c d\ne f g h i", config)
+	var texts []string
+	for _, w := range windows {
+		texts = append(texts, w.text)
+	}
+	want := []string{"a b c d", "d\ne f g", "g h i"}
+	if total != 9 || strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Errorf("windows = %q (%d tokens), want %q", texts, total, want)
+	}
+	if last := windows[len(windows)-1]; last.end != total {
+		t.Errorf("last window ends at %d, want %d", last.end, total)
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	// The judge finds the answer grounded only in the window holding "France".
+	var mu sync.Mutex
+	var prompts []string
+	provider := NewSimpleProvider("mock", "judge", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		content := req.Messages[0].Content
+		mu.Lock()
+		prompts = append(prompts, content)
+		mu.Unlock()
+		if strings.Contains(content, "France") {
+			return &CompletionResponse{Content: `{"score": 0.1, "reason": "grounded"}`, PromptTokens: 10}, nil
+		}
+		return &CompletionResponse{Content: `{"score": 0.9, "reason": "unsupported"}`, PromptTokens: 10}, nil
+	})
+	docs := strings.Repeat("filler ", 20) + "The capital of France is Paris. " + strings.Repeat("more ", 20)
+	input := evaluation.NewMetricInput("capital?", "Paris").WithContext(docs)
+
+	m := NewHallucination(provider, WithContextWindow(WindowConfig{MaxTokens: 15, Overlap: 3, CountTokens: wordCount}))
+	result := m.Score(t.Context(), input)
+	if result.Error != nil {
+		t.Fatalf("Score error: %v", result.Error)
+	}
+	if result.Value != 0.1 || !strings.Contains(result.Reason, "grounded") {
+		t.Errorf("Score = %v (%q), want the lowest window score", result.Value, result.Reason)
+	}
+	scores := result.Metadata["window_scores"].([]float64)
+	if len(scores) != len(prompts) || len(scores) < 3 {
+		t.Errorf("window_scores = %v for %d prompts, want one per window", scores, len(prompts))
+	}
+	if result.Provenance.PromptTokens != 10*len(scores) {
+		t.Errorf("PromptTokens = %d, want the tokens of every window", result.Provenance.PromptTokens)
+	}
+	if _, ok := result.Metadata["truncation_warning"]; ok {
+		t.Error("unexpected truncation warning")
+	}
+
+	// Precision keeps the highest score by default, unless overridden.
+	precision := NewContextPrecision(provider, WithContextWindow(WindowConfig{MaxTokens: 15, CountTokens: wordCount, Aggregation: WindowMin}))
+	if got := precision.Score(t.Context(), input).Value; got != 0.1 {
+		t.Errorf("precision with WindowMin = %v, want 0.1", got)
+	}
+
+	// MaxWindows stops before the window with the answer, and says so.
+	prompts = nil
+	truncated := NewHallucination(provider, WithContextWindow(WindowConfig{MaxTokens: 15, MaxWindows: 1, CountTokens: wordCount}))
+	result = truncated.Score(t.Context(), input)
+	if result.Value != 0.9 || len(prompts) != 1 {
+		t.Errorf("Score = %v after %d prompts, want 0.9 after 1", result.Value, len(prompts))
+	}
+	if warning, _ := result.Metadata["truncation_warning"].(string); !strings.Contains(warning, "15 of 46") {
+		t.Errorf("truncation_warning = %q, want the evaluated and total tokens", warning)
+	}
+
+	// A short context is scored in one request, without window metadata.
+	short := m.Score(t.Context(), evaluation.NewMetricInput("capital?", "Paris").WithContext("Paris is the capital of France."))
+	if short.Value != 0.1 || short.Metadata != nil {
+		t.Errorf("short context = %+v, want a plain score", short)
+	}
+}
+
+func TestContextWindowError(t *testing.T) {
+	provider := NewSimpleProvider("mock", "judge", func(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+		return nil, errors.New("context length exceeded")
+	})
+	m := NewContextRecall(provider, WithContextWindow(WindowConfig{MaxTokens: 2, CountTokens: wordCount}))
+	result := m.Score(t.Context(), evaluation.NewMetricInput("q", "a").WithContext("one two three four"))
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "context window 1 of 2") {
+		t.Errorf("Score error = %v, want the failing window", result.Error)
+	}
+}
+
+func TestContextWindowValidate(t *testing.T) {
+	m := NewHallucination(NewMockProvider(nil, ""), WithContextWindow(WindowConfig{Overlap: -1, Aggregation: "median"}))
+	var verr *evaluation.ValidationError
+	if err := m.Validate(); !errors.As(err, &verr) || len(verr.Problems) != 3 {
+		t.Errorf("Validate = %v, want 3 problems", err)
+	}
+}
@@ -0,0 +1,262 @@
+package evaluation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ManifestSchemaVersion is the version of the RunManifest format.
+const ManifestSchemaVersion = 1
+
+// sdkModule is the module path used to find the SDK version in build info.
+const sdkModule = "github.com/plexusone/opik-go"
+
+// VersionedMetric is a metric that reports a version, recorded in run
+// manifests so a change to a metric's logic shows up when runs are
+// compared.
+type VersionedMetric interface {
+	Metric
+	Version() string
+}
+
+// ManifestConfig describes the run a manifest is generated for. Fields
+// that are left empty are detected where possible or omitted.
+type ManifestConfig struct {
+	// Name is the name of the run, such as the experiment name.
+	Name string
+	// DatasetName and DatasetVersion identify the evaluated dataset.
+	DatasetName    string
+	DatasetVersion string
+	// DatasetHash identifies the dataset's content. If empty, it is
+	// computed from the results with DatasetHash.
+	DatasetHash string
+	// Metrics are the metrics of the run. Their names and versions are
+	// recorded even for metrics that produced no results.
+	Metrics []Metric
+	// TaskVersion identifies the task that produced the outputs; see
+	// TaskVersion.
+	TaskVersion string
+	// SDKVersion is the version of this SDK. If empty, it is read from the
+	// binary's build info.
+	SDKVersion string
+	// GitSHA is the commit the run was made from. If empty, it is read from
+	// the binary's build info, which records it for binaries built with go
+	// build in a git checkout.
+	GitSHA string
+	// StartedAt and FinishedAt are when the run started and finished.
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Extra holds additional entries, such as a ticket or approver.
+	Extra map[string]any
+}
+
+// RunManifest is a machine-readable record of an evaluation run, for ML
+// governance sign-offs: what was evaluated, with which metrics and judge
+// models, from which code, and with what results.
+type RunManifest struct {
+	SchemaVersion int       `json:"schema_version"`
+	Name          string    `json:"name,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	StartedAt     time.Time `json:"started_at,omitzero"`
+	FinishedAt    time.Time `json:"finished_at,omitzero"`
+
+	Dataset     ManifestDataset  `json:"dataset"`
+	Metrics     []ManifestMetric `json:"metrics"`
+	TaskVersion string           `json:"task_version,omitempty"`
+
+	SDKVersion string `json:"sdk_version,omitempty"`
+	GoVersion  string `json:"go_version"`
+	GitSHA     string `json:"git_sha,omitempty"`
+	// GitDirty is true if the binary was built from a checkout with
+	// uncommitted changes.
+	GitDirty bool `json:"git_dirty,omitempty"`
+
+	// Summary is EvaluationResults.Summary of the run.
+	Summary map[string]float64 `json:"summary"`
+	Extra   map[string]any     `json:"extra,omitempty"`
+}
+
+// ManifestDataset identifies the evaluated dataset.
+type ManifestDataset struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	Hash    string `json:"hash"`
+	// Items is the number of evaluated items.
+	Items int `json:"items"`
+}
+
+// ManifestMetric records one metric of a run.
+type ManifestMetric struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// JudgeModels and JudgeProviders are the judge models and providers
+	// that produced the metric's scores, from their provenance.
+	JudgeModels    []string `json:"judge_models,omitempty"`
+	JudgeProviders []string `json:"judge_providers,omitempty"`
+	// Scored, NotScored, and Failed count the items by score status.
+	Scored    int `json:"scored"`
+	NotScored int `json:"not_scored"`
+	Failed    int `json:"failed"`
+}
+
+// Manifest generates the run manifest of results. Attach it to an
+// experiment with Metadata, or write it out with ToJSON.
+func Manifest(results EvaluationResults, config ManifestConfig) *RunManifest {
+	m := &RunManifest{
+		SchemaVersion: ManifestSchemaVersion,
+		Name:          config.Name,
+		CreatedAt:     time.Now().UTC(),
+		StartedAt:     config.StartedAt,
+		FinishedAt:    config.FinishedAt,
+		Dataset: ManifestDataset{
+			Name:    config.DatasetName,
+			Version: config.DatasetVersion,
+			Hash:    config.DatasetHash,
+			Items:   len(results),
+		},
+		TaskVersion: config.TaskVersion,
+		SDKVersion:  config.SDKVersion,
+		GoVersion:   runtime.Version(),
+		GitSHA:      config.GitSHA,
+		Summary:     results.Summary(),
+		Extra:       config.Extra,
+	}
+	if m.Dataset.Hash == "" {
+		m.Dataset.Hash = DatasetHash(results)
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		m.readBuildInfo(info)
+	}
+
+	versions := make(map[string]string)
+	var names []string
+	for _, metric := range config.Metrics {
+		if metric == nil {
+			continue
+		}
+		names = append(names, metric.Name())
+		if v, ok := metric.(VersionedMetric); ok {
+			versions[metric.Name()] = v.Version()
+		}
+	}
+	for _, res := range results {
+		for _, score := range res.Scores {
+			names = append(names, score.Name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range slices.Compact(names) {
+		m.Metrics = append(m.Metrics, manifestMetric(results, name, versions[name]))
+	}
+	return m
+}
+
+// readBuildInfo fills in the SDK version and git commit from info, unless
+// they were given.
+func (m *RunManifest) readBuildInfo(info *debug.BuildInfo) {
+	if m.SDKVersion == "" {
+		if info.Main.Path == sdkModule {
+			m.SDKVersion = info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == sdkModule {
+				m.SDKVersion = dep.Version
+			}
+		}
+	}
+	if m.GitSHA == "" {
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				m.GitSHA = s.Value
+			case "vcs.modified":
+				m.GitDirty = s.Value == "true"
+			}
+		}
+	}
+}
+
+func manifestMetric(results EvaluationResults, name, version string) ManifestMetric {
+	mm := ManifestMetric{Name: name, Version: version}
+	counts := results.CountByStatus(name)
+	mm.Scored = counts[ScoreStatusScored]
+	mm.NotScored = counts[ScoreStatusNotScored]
+	mm.Failed = counts[ScoreStatusFailed]
+
+	models := make(map[string]bool)
+	providers := make(map[string]bool)
+	for _, res := range results {
+		for _, score := range res.Scores.AllByName(name) {
+			if p := score.Provenance; p != nil {
+				if p.Model != "" {
+					models[p.Model] = true
+				}
+				if p.Provider != "" {
+					providers[p.Provider] = true
+				}
+			}
+		}
+	}
+	mm.JudgeModels = sortedKeys(models)
+	mm.JudgeProviders = sortedKeys(providers)
+	return mm
+}
+
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DatasetHash returns a SHA-256 hash of the inputs, expected outputs, and
+// contexts, including context lists, of results. It does not depend on the order of the results or
+// on the outputs being scored, so runs over the same data have the same
+// hash.
+func DatasetHash(results EvaluationResults) string {
+	rows := make([]string, 0, len(results))
+	for _, res := range results {
+		row := []any{res.Input.Input, res.Input.Expected, res.Input.Context}
+		if len(res.Input.ContextList) > 0 {
+			row = append(row, res.Input.ContextList)
+		}
+		data, _ := json.Marshal(row)
+		rows = append(rows, string(data))
+	}
+	sort.Strings(rows)
+	h := sha256.New()
+	for _, row := range rows {
+		h.Write([]byte(row))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ToJSON returns the manifest as indented JSON.
+func (m *RunManifest) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// Metadata returns the manifest as a map, for use as experiment metadata.
+func (m *RunManifest) Metadata() map[string]any {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	return out
+}
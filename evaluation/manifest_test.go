@@ -0,0 +1,144 @@
+package evaluation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime/debug"
+	"testing"
+	"time"
+)
+
+type versionedMetric struct {
+	BaseMetric
+}
+
+func (m versionedMetric) Score(context.Context, MetricInput) *ScoreResult {
+	return NewScoreResult(m.Name(), 1)
+}
+
+func (versionedMetric) Version() string { return "2" }
+
+func TestManifest(t *testing.T) {
+	judged := NewScoreResult("relevance", 0.8)
+	judged.Provenance = &Provenance{Model: "gpt-4o", Provider: "openai"}
+	results := EvaluationResults{
+		{Input: MetricInput{Input: "q1", Expected: "a1"}, Scores: ScoreResults{NewScoreResult("exact", 1), judged}},
+		{Input: MetricInput{Input: "q2"}, Scores: ScoreResults{NewScoreResult("exact", 0), NewNotScoredResult("relevance", "no context")}},
+		{Input: MetricInput{Input: "q3"}, Scores: ScoreResults{NewScoreResult("exact", 1), NewFailedScoreResult("relevance", errors.New("boom"))}},
+	}
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := Manifest(results, ManifestConfig{
+		Name:        "run-1",
+		DatasetName: "qa",
+		Metrics:     []Metric{versionedMetric{NewBaseMetric("exact")}, versionedMetric{NewBaseMetric("unused")}},
+		TaskVersion: "abc",
+		SDKVersion:  "1.2.3",
+		GitSHA:      "deadbeef",
+		StartedAt:   started,
+		FinishedAt:  started.Add(time.Minute),
+		Extra:       map[string]any{"approver": "ml-review"},
+	})
+
+	if m.SchemaVersion != ManifestSchemaVersion || m.Name != "run-1" || m.SDKVersion != "1.2.3" || m.GitSHA != "deadbeef" {
+		t.Errorf("manifest = %+v", m)
+	}
+	if m.Dataset.Name != "qa" || m.Dataset.Items != 3 || m.Dataset.Hash != DatasetHash(results) {
+		t.Errorf("dataset = %+v", m.Dataset)
+	}
+	if len(m.Metrics) != 3 {
+		t.Fatalf("metrics = %+v, want exact, relevance, and unused", m.Metrics)
+	}
+	exact, relevance, unused := m.Metrics[0], m.Metrics[1], m.Metrics[2]
+	if exact.Name != "exact" || exact.Version != "2" || exact.Scored != 3 {
+		t.Errorf("exact = %+v", exact)
+	}
+	if relevance.Scored != 1 || relevance.NotScored != 1 || relevance.Failed != 1 || relevance.Version != "" {
+		t.Errorf("relevance = %+v", relevance)
+	}
+	if len(relevance.JudgeModels) != 1 || relevance.JudgeModels[0] != "gpt-4o" || relevance.JudgeProviders[0] != "openai" {
+		t.Errorf("relevance judges = %v %v", relevance.JudgeModels, relevance.JudgeProviders)
+	}
+	if unused.Name != "unused" || unused.Scored != 0 {
+		t.Errorf("unused = %+v", unused)
+	}
+	if m.Summary["relevance"] != 0.8 {
+		t.Errorf("summary = %v", m.Summary)
+	}
+
+	data, err := m.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON error: %v", err)
+	}
+	var decoded RunManifest
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	if !decoded.StartedAt.Equal(started) || decoded.TaskVersion != "abc" || decoded.Extra["approver"] != "ml-review" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+
+	meta := m.Metadata()
+	if meta["git_sha"] != "deadbeef" || meta["dataset"].(map[string]any)["name"] != "qa" {
+		t.Errorf("Metadata() = %v", meta)
+	}
+}
+
+func TestManifestOmitsUnsetTimes(t *testing.T) {
+	data, err := Manifest(nil, ManifestConfig{}).ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON error: %v", err)
+	}
+	var fields map[string]any
+	_ = json.Unmarshal(data, &fields)
+	if _, ok := fields["started_at"]; ok {
+		t.Errorf("manifest %s has started_at", data)
+	}
+	if _, ok := fields["created_at"]; !ok {
+		t.Errorf("manifest %s has no created_at", data)
+	}
+}
+
+func TestManifestBuildInfo(t *testing.T) {
+	m := &RunManifest{}
+	m.readBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/app", Version: "(devel)"},
+		Deps: []*debug.Module{{Path: sdkModule, Version: "v0.4.0"}},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123abc"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+	if m.SDKVersion != "v0.4.0" || m.GitSHA != "0123abc" || !m.GitDirty {
+		t.Errorf("manifest = %+v", m)
+	}
+
+	given := &RunManifest{SDKVersion: "1.0.0", GitSHA: "given"}
+	given.readBuildInfo(&debug.BuildInfo{Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "0123abc"}}})
+	if given.SDKVersion != "1.0.0" || given.GitSHA != "given" {
+		t.Errorf("given values were replaced: %+v", given)
+	}
+}
+
+func TestDatasetHash(t *testing.T) {
+	a := EvaluationResults{
+		{Input: MetricInput{Input: "q1", Output: "x", Expected: "a1"}},
+		{Input: MetricInput{Input: "q2", Context: "c"}},
+	}
+	b := EvaluationResults{
+		{Input: MetricInput{Input: "q2", Context: "c"}},
+		{Input: MetricInput{Input: "q1", Output: "different output", Expected: "a1"}},
+	}
+	if DatasetHash(a) != DatasetHash(b) {
+		t.Error("hash depends on order or outputs")
+	}
+	b[0].Input.Context = "changed"
+	if DatasetHash(a) == DatasetHash(b) {
+		t.Error("hash ignores the context")
+	}
+	b[0].Input.Context = "c"
+	b[0].Input.ContextList = []string{"chunk"}
+	if DatasetHash(a) == DatasetHash(b) {
+		t.Error("hash ignores the context list")
+	}
+}
@@ -2,6 +2,7 @@ package evaluation
 
 import (
 	"context"
+	"fmt"
 )
 
 // Metric is the interface for all evaluation metrics.
@@ -22,6 +23,9 @@ type MetricInput struct {
 	Expected string
 	// Context is additional context provided to the model.
 	Context string
+	// ContextList holds retrieved context chunks, such as the passages a
+	// RAG pipeline put in the prompt, for metrics that judge each chunk.
+	ContextList []string
 	// Metadata contains additional key-value pairs.
 	Metadata map[string]any
 }
@@ -46,6 +50,24 @@ func (m MetricInput) WithContext(ctx string) MetricInput {
 	return m
 }
 
+// WithContextList returns a copy of the input with the context chunks set.
+func (m MetricInput) WithContextList(chunks ...string) MetricInput {
+	m.ContextList = chunks
+	return m
+}
+
+// Contexts returns the context chunks: ContextList if it is set, otherwise
+// Context as a single chunk, or nil if there is no context.
+func (m MetricInput) Contexts() []string {
+	if len(m.ContextList) > 0 {
+		return m.ContextList
+	}
+	if m.Context != "" {
+		return []string{m.Context}
+	}
+	return nil
+}
+
 // WithMetadata returns a copy of the input with additional metadata.
 func (m MetricInput) WithMetadata(key string, value any) MetricInput {
 	if m.Metadata == nil {
@@ -181,6 +203,23 @@ func (m *CompositeMetric) ScoreAll(ctx context.Context, input MetricInput) Score
 	return scores
 }
 
+// Validate reports a composite with no metrics, nil metrics, and the
+// problems of metrics implementing Validator.
+func (m *CompositeMetric) Validate() error {
+	var p Problems
+	if len(m.metrics) == 0 {
+		p.Addf("composite has no metrics")
+	}
+	for i, metric := range m.metrics {
+		if metric == nil {
+			p.Addf("metrics[%d] is nil", i)
+		} else if v, ok := metric.(Validator); ok {
+			p.Add(fmt.Sprintf("metric %q", metric.Name()), v.Validate())
+		}
+	}
+	return p.Err()
+}
+
 // ConditionalMetric evaluates a metric only if a condition is met.
 type ConditionalMetric struct {
 	BaseMetric
@@ -205,6 +244,21 @@ func (m *ConditionalMetric) Score(ctx context.Context, input MetricInput) *Score
 	return m.metric.Score(ctx, input)
 }
 
+// Validate reports a nil condition or metric, and the problems of a metric
+// implementing Validator.
+func (m *ConditionalMetric) Validate() error {
+	var p Problems
+	if m.condition == nil {
+		p.Addf("condition is nil")
+	}
+	if m.metric == nil {
+		p.Addf("metric is nil")
+	} else if v, ok := m.metric.(Validator); ok {
+		p.Add("", v.Validate())
+	}
+	return p.Err()
+}
+
 // WeightedMetric applies a weight to a metric's score.
 type WeightedMetric struct {
 	metric Metric
@@ -221,9 +275,27 @@ func NewWeightedMetric(metric Metric, weight float64) *WeightedMetric {
 
 // Name returns the name of the underlying metric.
 func (m *WeightedMetric) Name() string {
+	if m.metric == nil {
+		return ""
+	}
 	return m.metric.Name()
 }
 
+// Validate reports a nil metric or a negative weight, and the problems of a
+// metric implementing Validator.
+func (m *WeightedMetric) Validate() error {
+	var p Problems
+	if m.weight < 0 {
+		p.Addf("weight must not be negative: %v", m.weight)
+	}
+	if m.metric == nil {
+		p.Addf("metric is nil")
+	} else if v, ok := m.metric.(Validator); ok {
+		p.Add("", v.Validate())
+	}
+	return p.Err()
+}
+
 // Score evaluates the metric and applies the weight.
 func (m *WeightedMetric) Score(ctx context.Context, input MetricInput) *ScoreResult {
 	result := m.metric.Score(ctx, input)
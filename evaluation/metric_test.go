@@ -39,6 +39,19 @@ func TestMetricInputWithContext(t *testing.T) {
 	}
 }
 
+func TestMetricInputContexts(t *testing.T) {
+	if got := NewMetricInput("", "output").Contexts(); got != nil {
+		t.Errorf("Contexts() = %v, want nil", got)
+	}
+	if got := NewMetricInput("", "output").WithContext("c").Contexts(); len(got) != 1 || got[0] != "c" {
+		t.Errorf("Contexts() = %v, want [c]", got)
+	}
+	input := NewMetricInput("", "output").WithContext("c").WithContextList("a", "b")
+	if got := input.Contexts(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Contexts() = %v, want [a b]", got)
+	}
+}
+
 func TestMetricInputWithMetadata(t *testing.T) {
 	input := NewMetricInput("", "output").
 		WithMetadata("key1", "value1").
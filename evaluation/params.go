@@ -0,0 +1,119 @@
+package evaluation
+
+import (
+	"fmt"
+	"math"
+)
+
+// MetricParams holds the parameters used to construct a metric from the registry,
+// typically decoded from a suite file.
+// Accessors accept the value types produced by both the YAML and JSON decoders.
+type MetricParams map[string]any
+
+// String returns a string parameter, or def if it is not set.
+func (p MetricParams) String(key, def string) (string, error) {
+	v, ok := p[key]
+	if !ok || v == nil {
+		return def, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("param %q: expected string, got %T", key, v)
+	}
+	return s, nil
+}
+
+// Bool returns a boolean parameter, or def if it is not set.
+func (p MetricParams) Bool(key string, def bool) (bool, error) {
+	v, ok := p[key]
+	if !ok || v == nil {
+		return def, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("param %q: expected bool, got %T", key, v)
+	}
+	return b, nil
+}
+
+// Float returns a numeric parameter, or def if it is not set.
+func (p MetricParams) Float(key string, def float64) (float64, error) {
+	v, ok := p[key]
+	if !ok || v == nil {
+		return def, nil
+	}
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	default:
+		return 0, fmt.Errorf("param %q: expected number, got %T", key, v)
+	}
+}
+
+// Int returns an integer parameter, or def if it is not set.
+func (p MetricParams) Int(key string, def int) (int, error) {
+	if _, ok := p[key]; !ok {
+		return def, nil
+	}
+	f, err := p.Float(key, float64(def))
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("param %q: expected integer, got %v", key, f)
+	}
+	return int(f), nil
+}
+
+// Strings returns a string list parameter, or nil if it is not set.
+func (p MetricParams) Strings(key string) ([]string, error) {
+	v, ok := p[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	switch list := v.(type) {
+	case []string:
+		return list, nil
+	case []any:
+		out := make([]string, 0, len(list))
+		for i, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("param %q[%d]: expected string, got %T", key, i, item)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("param %q: expected list of strings, got %T", key, v)
+	}
+}
+
+// StringMap returns a string-to-string map parameter, or nil if it is not set.
+func (p MetricParams) StringMap(key string) (map[string]string, error) {
+	v, ok := p[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("param %q: expected map, got %T", key, v)
+	}
+	out := make(map[string]string, len(m))
+	for k, item := range m {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("param %q.%s: expected string, got %T", key, k, item)
+		}
+		out[k] = s
+	}
+	return out, nil
+}
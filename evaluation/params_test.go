@@ -0,0 +1,58 @@
+package evaluation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetricParams(t *testing.T) {
+	p := MetricParams{
+		"s":     "text",
+		"b":     true,
+		"i":     3,
+		"f":     0.5,
+		"jsonI": float64(7),
+		"list":  []any{"a", "b"},
+		"map":   map[string]any{"k": "v"},
+	}
+
+	if v, err := p.String("s", ""); err != nil || v != "text" {
+		t.Errorf("String = %q, %v", v, err)
+	}
+	if v, err := p.String("missing", "def"); err != nil || v != "def" {
+		t.Errorf("String default = %q, %v", v, err)
+	}
+	if v, err := p.Bool("b", false); err != nil || !v {
+		t.Errorf("Bool = %v, %v", v, err)
+	}
+	if v, err := p.Int("i", 0); err != nil || v != 3 {
+		t.Errorf("Int = %d, %v", v, err)
+	}
+	if v, err := p.Int("jsonI", 0); err != nil || v != 7 {
+		t.Errorf("Int from float64 = %d, %v", v, err)
+	}
+	if v, err := p.Float("i", 0); err != nil || v != 3 {
+		t.Errorf("Float from int = %v, %v", v, err)
+	}
+	if v, err := p.Strings("list"); err != nil || !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Errorf("Strings = %v, %v", v, err)
+	}
+	if v, err := p.StringMap("map"); err != nil || v["k"] != "v" {
+		t.Errorf("StringMap = %v, %v", v, err)
+	}
+
+	t.Run("type errors", func(t *testing.T) {
+		if _, err := p.String("b", ""); err == nil {
+			t.Error("String on bool should fail")
+		}
+		if _, err := p.Bool("s", false); err == nil {
+			t.Error("Bool on string should fail")
+		}
+		if _, err := p.Int("f", 0); err == nil {
+			t.Error("Int on fractional number should fail")
+		}
+		if _, err := p.Strings("s"); err == nil {
+			t.Error("Strings on string should fail")
+		}
+	})
+}
@@ -0,0 +1,261 @@
+package evaluation
+
+import (
+	"context"
+	"sync"
+)
+
+// Priority is the scheduling class of work submitted to a Pool.
+type Priority int
+
+const (
+	// PriorityBulk is for batch runs, such as EvaluateMany over a dataset.
+	PriorityBulk Priority = iota
+	// PriorityInteractive is for single items a user is waiting on, such as
+	// EvaluateOne.
+	PriorityInteractive
+
+	numPriorities = 2
+)
+
+// String returns the name of the priority class.
+func (p Priority) String() string {
+	switch p {
+	case PriorityBulk:
+		return "bulk"
+	case PriorityInteractive:
+		return "interactive"
+	default:
+		return "unknown"
+	}
+}
+
+// Pool limits how many evaluations run at once across engines, for example
+// to stay within a judge provider's connection limits. Engines sharing a pool
+// via WithPool schedule their work through it:
+//
+//   - Interactive work runs ahead of bulk work, but after interactiveWeight
+//     interactive items in a row one bulk item runs, so bulk runs are never
+//     starved entirely.
+//   - Within a priority class, concurrent batch runs take turns, so one large
+//     run does not delay a smaller one until it finishes.
+//
+// A Pool starts no goroutines and needs no cleanup.
+type Pool struct {
+	mu                sync.Mutex
+	workers           int
+	running           int
+	interactiveWeight int
+	streak            int
+	nextGroup         uint64
+	classes           [numPriorities]classQueue
+}
+
+// PoolOption configures a Pool.
+type PoolOption func(*Pool)
+
+// WithInteractiveWeight sets how many interactive items may run in a row
+// while bulk items are waiting (default 4).
+func WithInteractiveWeight(n int) PoolOption {
+	return func(p *Pool) {
+		if n > 0 {
+			p.interactiveWeight = n
+		}
+	}
+}
+
+// NewPool creates a pool that runs up to workers evaluations at once.
+func NewPool(workers int, opts ...PoolOption) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &Pool{
+		workers:           workers,
+		interactiveWeight: 4,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Workers returns the maximum number of concurrent evaluations.
+func (p *Pool) Workers() int {
+	return p.workers
+}
+
+// Running returns the number of evaluations currently running.
+func (p *Pool) Running() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.running
+}
+
+// Queued returns the number of evaluations waiting in a priority class.
+func (p *Pool) Queued(priority Priority) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.class(priority).size
+}
+
+// Run waits for a free worker, then calls fn. It returns ctx.Err() without
+// calling fn if ctx is cancelled first.
+func (p *Pool) Run(ctx context.Context, priority Priority, fn func()) error {
+	return p.run(ctx, priority, p.newGroup(), fn)
+}
+
+// newGroup returns a new group ID. Groups in the same priority class are
+// served in turn.
+func (p *Pool) newGroup() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nextGroup++
+	return p.nextGroup
+}
+
+func (p *Pool) run(ctx context.Context, priority Priority, group uint64, fn func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	if p.running < p.workers && p.queued() == 0 {
+		p.running++
+		p.mu.Unlock()
+	} else {
+		w := &waiter{ready: make(chan struct{})}
+		p.class(priority).push(group, w)
+		p.mu.Unlock()
+
+		select {
+		case <-w.ready:
+		case <-ctx.Done():
+			p.mu.Lock()
+			granted := w.granted
+			if !granted {
+				p.class(priority).remove(group, w)
+			}
+			p.mu.Unlock()
+			if granted {
+				p.release()
+			}
+			return ctx.Err()
+		}
+	}
+
+	defer p.release()
+	fn()
+	return nil
+}
+
+// release frees a worker and hands it to the next waiter, if any.
+func (p *Pool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running--
+	for p.running < p.workers {
+		w := p.next()
+		if w == nil {
+			return
+		}
+		w.granted = true
+		p.running++
+		close(w.ready)
+	}
+}
+
+// next removes and returns the next waiter to run, or nil if none are
+// waiting.
+func (p *Pool) next() *waiter {
+	interactive := p.class(PriorityInteractive)
+	bulk := p.class(PriorityBulk)
+	if interactive.size > 0 && (bulk.size == 0 || p.streak < p.interactiveWeight) {
+		p.streak++
+		return interactive.pop()
+	}
+	p.streak = 0
+	if bulk.size > 0 {
+		return bulk.pop()
+	}
+	return nil
+}
+
+func (p *Pool) queued() int {
+	n := 0
+	for i := range p.classes {
+		n += p.classes[i].size
+	}
+	return n
+}
+
+func (p *Pool) class(priority Priority) *classQueue {
+	if priority < 0 || int(priority) >= numPriorities {
+		priority = PriorityBulk
+	}
+	return &p.classes[priority]
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// classQueue holds the waiters of one priority class, in one FIFO queue per
+// group. Groups are served round-robin.
+type classQueue struct {
+	order  []uint64
+	groups map[uint64][]*waiter
+	size   int
+}
+
+func (q *classQueue) push(group uint64, w *waiter) {
+	if q.groups == nil {
+		q.groups = make(map[uint64][]*waiter)
+	}
+	if len(q.groups[group]) == 0 {
+		q.order = append(q.order, group)
+	}
+	q.groups[group] = append(q.groups[group], w)
+	q.size++
+}
+
+func (q *classQueue) pop() *waiter {
+	if q.size == 0 {
+		return nil
+	}
+	group := q.order[0]
+	q.order = q.order[1:]
+	waiters := q.groups[group]
+	w := waiters[0]
+	if len(waiters) > 1 {
+		q.groups[group] = waiters[1:]
+		q.order = append(q.order, group)
+	} else {
+		delete(q.groups, group)
+	}
+	q.size--
+	return w
+}
+
+func (q *classQueue) remove(group uint64, w *waiter) {
+	waiters := q.groups[group]
+	for i, x := range waiters {
+		if x != w {
+			continue
+		}
+		waiters = append(waiters[:i], waiters[i+1:]...)
+		q.size--
+		if len(waiters) > 0 {
+			q.groups[group] = waiters
+			return
+		}
+		delete(q.groups, group)
+		for j, g := range q.order {
+			if g == group {
+				q.order = append(q.order[:j], q.order[j+1:]...)
+				break
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,226 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockPool occupies all of the pool's workers until the returned function
+// is called.
+func blockPool(t *testing.T, p *Pool) func() {
+	t.Helper()
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	for i := 0; i < p.Workers(); i++ {
+		started.Add(1)
+		go func() {
+			_ = p.Run(context.Background(), PriorityBulk, func() {
+				started.Done()
+				<-release
+			})
+		}()
+	}
+	started.Wait()
+	return func() { close(release) }
+}
+
+// waitQueued waits until n items are queued in the priority class.
+func waitQueued(t *testing.T, p *Pool, priority Priority, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for p.Queued(priority) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Queued(%v) = %d, want %d", priority, p.Queued(priority), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// recorder records the order in which queued items run.
+type recorder struct {
+	mu    sync.Mutex
+	order []string
+	wg    sync.WaitGroup
+}
+
+func (r *recorder) submit(t *testing.T, p *Pool, priority Priority, group uint64, name string) {
+	t.Helper()
+	r.wg.Add(1)
+	queued := p.Queued(priority)
+	go func() {
+		defer r.wg.Done()
+		_ = p.run(context.Background(), priority, group, func() {
+			r.mu.Lock()
+			r.order = append(r.order, name)
+			r.mu.Unlock()
+		})
+	}()
+	waitQueued(t, p, priority, queued+1)
+}
+
+func (r *recorder) wait() []string {
+	r.wg.Wait()
+	return r.order
+}
+
+func TestPoolLimitsConcurrency(t *testing.T) {
+	p := NewPool(3)
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = p.Run(context.Background(), PriorityBulk, func() {
+				n := running.Add(1)
+				for {
+					old := peak.Load()
+					if n <= old || peak.CompareAndSwap(old, n) {
+						break
+					}
+				}
+				time.Sleep(2 * time.Millisecond)
+				running.Add(-1)
+			})
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", peak.Load())
+	}
+	if p.Running() != 0 {
+		t.Errorf("Running() = %d after all work finished", p.Running())
+	}
+}
+
+func TestPoolPriority(t *testing.T) {
+	tests := []struct {
+		weight int
+		want   []string
+	}{
+		{4, []string{"i1", "i2", "b1", "b2", "b3"}},
+		{1, []string{"i1", "b1", "i2", "b2", "b3"}},
+	}
+	for _, tt := range tests {
+		p := NewPool(1, WithInteractiveWeight(tt.weight))
+		unblock := blockPool(t, p)
+
+		var r recorder
+		bulk := p.newGroup()
+		r.submit(t, p, PriorityBulk, bulk, "b1")
+		r.submit(t, p, PriorityBulk, bulk, "b2")
+		r.submit(t, p, PriorityBulk, bulk, "b3")
+		r.submit(t, p, PriorityInteractive, p.newGroup(), "i1")
+		r.submit(t, p, PriorityInteractive, p.newGroup(), "i2")
+		unblock()
+
+		got := r.wait()
+		if len(got) != len(tt.want) {
+			t.Fatalf("weight %d: order = %v, want %v", tt.weight, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("weight %d: order = %v, want %v", tt.weight, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestPoolFairnessBetweenGroups(t *testing.T) {
+	p := NewPool(1)
+	unblock := blockPool(t, p)
+
+	var r recorder
+	large, small := p.newGroup(), p.newGroup()
+	r.submit(t, p, PriorityBulk, large, "a1")
+	r.submit(t, p, PriorityBulk, large, "a2")
+	r.submit(t, p, PriorityBulk, large, "a3")
+	r.submit(t, p, PriorityBulk, small, "b1")
+	r.submit(t, p, PriorityBulk, small, "b2")
+	unblock()
+
+	want := []string{"a1", "b1", "a2", "b2", "a3"}
+	got := r.wait()
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPoolCancelWhileQueued(t *testing.T) {
+	p := NewPool(1)
+	unblock := blockPool(t, p)
+	defer unblock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	called := false
+	go func() {
+		errc <- p.Run(ctx, PriorityInteractive, func() { called = true })
+	}()
+	waitQueued(t, p, PriorityInteractive, 1)
+	cancel()
+
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+	if called {
+		t.Error("fn should not be called after cancellation")
+	}
+	if p.Queued(PriorityInteractive) != 0 {
+		t.Errorf("Queued = %d after cancellation", p.Queued(PriorityInteractive))
+	}
+}
+
+func TestEngineWithPool(t *testing.T) {
+	pool := NewPool(1)
+	var running, peak atomic.Int32
+	metric := NewMetricFunc("slow", func(ctx context.Context, input MetricInput) *ScoreResult {
+		if n := running.Add(1); n > peak.Load() {
+			peak.Store(n)
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return NewScoreResult("slow", 1)
+	})
+
+	bulk := NewEngine([]Metric{metric}, WithConcurrency(4), WithPool(pool))
+	interactive := NewEngine([]Metric{metric}, WithPool(pool))
+
+	inputs := make([]MetricInput, 20)
+	done := make(chan EvaluationResults)
+	go func() {
+		done <- bulk.EvaluateMany(context.Background(), inputs)
+	}()
+	waitQueued(t, pool, PriorityBulk, 3)
+
+	// The interactive item runs next, ahead of the queued bulk items.
+	result := interactive.EvaluateOne(context.Background(), NewMetricInput("q", "a"))
+	if result.Error != nil || result.Scores[0].Value != 1 {
+		t.Errorf("EvaluateOne = %+v", result)
+	}
+	if queued := pool.Queued(PriorityBulk); queued == 0 {
+		t.Error("interactive item should not wait for the bulk run to finish")
+	}
+
+	results := <-done
+	if len(results.Successful()) != 20 {
+		t.Errorf("successful = %d, want 20", len(results.Successful()))
+	}
+	if peak.Load() != 1 {
+		t.Errorf("peak concurrency = %d, want 1", peak.Load())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if r := interactive.EvaluateOne(ctx, NewMetricInput("q", "a")); !errors.Is(r.Error, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", r.Error)
+	}
+}
@@ -0,0 +1,96 @@
+package evaluation
+
+import (
+	"context"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation/textnorm"
+)
+
+// Preprocessor transforms a text value before metrics score it.
+type Preprocessor func(text string) string
+
+// Built-in preprocessors. They use the functions of package textnorm,
+// which applications can call directly.
+var (
+	// TrimWhitespace removes leading and trailing whitespace.
+	TrimWhitespace Preprocessor = strings.TrimSpace
+
+	// CollapseWhitespace replaces each run of whitespace with a single
+	// space and trims the ends.
+	CollapseWhitespace Preprocessor = textnorm.CollapseWhitespace
+
+	// NormalizeUnicode applies NFKC normalization, which also folds
+	// non-breaking spaces and ellipses, and replaces typographic quotes and
+	// dashes with their ASCII equivalents.
+	NormalizeUnicode Preprocessor = textnorm.NormalizeUnicode
+
+	// StripMarkdown removes Markdown formatting, keeping the text content.
+	StripMarkdown Preprocessor = textnorm.StripMarkdown
+
+	// StripHTML removes HTML tags, keeping the text content.
+	StripHTML Preprocessor = textnorm.StripHTML
+
+	// ExtractCodeBlock returns the contents of the first fenced code block,
+	// or the text unchanged if it contains none.
+	ExtractCodeBlock Preprocessor = textnorm.ExtractCodeBlock
+)
+
+// ChainPreprocessors returns a preprocessor that applies each of ps in order.
+func ChainPreprocessors(ps ...Preprocessor) Preprocessor {
+	return func(text string) string {
+		for _, p := range ps {
+			text = p(text)
+		}
+		return text
+	}
+}
+
+// Preprocess returns a copy of the input with ps applied to Output and Expected.
+func (m MetricInput) Preprocess(ps ...Preprocessor) MetricInput {
+	if len(ps) == 0 {
+		return m
+	}
+	p := ChainPreprocessors(ps...)
+	m.Output = p(m.Output)
+	if m.Expected != "" {
+		m.Expected = p(m.Expected)
+	}
+	return m
+}
+
+// WithPreprocessors applies preprocessors to every input before the engine's
+// metrics score it. Results keep the original input.
+func WithPreprocessors(ps ...Preprocessor) EngineOption {
+	return func(e *Engine) {
+		e.preprocessors = append(e.preprocessors, ps...)
+	}
+}
+
+// PreprocessedMetric applies preprocessors to inputs before delegating to
+// another metric. It keeps the wrapped metric's name.
+type PreprocessedMetric struct {
+	metric        Metric
+	preprocessors []Preprocessor
+}
+
+// WithMetricPreprocessors wraps metric so that ps are applied to its inputs
+// only. Engine-level preprocessors run first.
+func WithMetricPreprocessors(metric Metric, ps ...Preprocessor) *PreprocessedMetric {
+	return &PreprocessedMetric{metric: metric, preprocessors: ps}
+}
+
+// Name returns the name of the wrapped metric.
+func (m *PreprocessedMetric) Name() string {
+	return m.metric.Name()
+}
+
+// Score preprocesses the input and scores it with the wrapped metric.
+func (m *PreprocessedMetric) Score(ctx context.Context, input MetricInput) *ScoreResult {
+	return m.metric.Score(ctx, input.Preprocess(m.preprocessors...))
+}
+
+// Unwrap returns the wrapped metric.
+func (m *PreprocessedMetric) Unwrap() Metric {
+	return m.metric
+}
@@ -0,0 +1,82 @@
+package evaluation
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBuiltinPreprocessors(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Preprocessor
+		in   string
+		want string
+	}{
+		{"trim", TrimWhitespace, "  hello \n", "hello"},
+		{"unicode quotes", NormalizeUnicode, "“it’s” — ok…", `"it's" - ok...`},
+		{"unicode width", NormalizeUnicode, "ｆｕｌｌ width", "full width"},
+		{"code block", ExtractCodeBlock, "Here:\n```go\nfmt.Println(1)\n```\nDone", "fmt.Println(1)"},
+		{"code block without language", ExtractCodeBlock, "```\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"no code block", ExtractCodeBlock, "plain text", "plain text"},
+		{"markdown heading", StripMarkdown, "## Title\ntext", "Title\ntext"},
+		{"markdown emphasis", StripMarkdown, "a **bold** and *em* and `code`", "a bold and em and code"},
+		{"markdown link", StripMarkdown, "see [docs](https://x.y) ![img](a.png)", "see docs img"},
+		{"markdown list", StripMarkdown, "- one\n  * two\n1. three", "one\n  two\nthree"},
+		{"markdown quote", StripMarkdown, "> quoted", "quoted"},
+		{"markdown keeps snake_case", StripMarkdown, "use my_var_name", "use my_var_name"},
+		{"collapse whitespace", CollapseWhitespace, " a\n\n b ", "a b"},
+		{"html", StripHTML, "<p>a <b>bold</b> &amp; more</p>", "a bold & more"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p(tt.in); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricInputPreprocess(t *testing.T) {
+	input := MetricInput{Input: "  q  ", Output: "  **Paris**  ", Expected: " Paris "}
+
+	got := input.Preprocess(StripMarkdown, TrimWhitespace)
+
+	if got.Output != "Paris" || got.Expected != "Paris" {
+		t.Errorf("Output/Expected = %q/%q, want Paris/Paris", got.Output, got.Expected)
+	}
+	if got.Input != "  q  " {
+		t.Errorf("Input should not be preprocessed, got %q", got.Input)
+	}
+	if input.Output != "  **Paris**  " {
+		t.Error("Preprocess should not modify the original input")
+	}
+}
+
+func TestEngineWithPreprocessors(t *testing.T) {
+	var seen []string
+	exact := NewMetricFunc("exact", func(_ context.Context, in MetricInput) *ScoreResult {
+		seen = append(seen, in.Output)
+		if in.Output == in.Expected {
+			return NewScoreResult("exact", 1)
+		}
+		return NewScoreResult("exact", 0)
+	})
+	excited := WithMetricPreprocessors(exact, func(s string) string { return s + "!" })
+
+	engine := NewEngine([]Metric{exact, excited}, WithPreprocessors(TrimWhitespace))
+	result := engine.EvaluateOne(context.Background(), NewMetricInput("q", " yes ").WithExpected("yes"))
+
+	if result.Scores[0].Value != 1 || result.Scores[1].Value != 1 {
+		t.Errorf("scores = %v, %v, want 1, 1", result.Scores[0].Value, result.Scores[1].Value)
+	}
+	if seen[0] != "yes" || seen[1] != "yes!" {
+		t.Errorf("metrics saw %q", seen)
+	}
+	if result.Input.Output != " yes " {
+		t.Errorf("result input = %q, want original", result.Input.Output)
+	}
+	if excited.Name() != "exact" || excited.Unwrap() != exact {
+		t.Error("PreprocessedMetric should keep the wrapped metric's name")
+	}
+}
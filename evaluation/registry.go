@@ -0,0 +1,77 @@
+package evaluation
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MetricFactory creates a metric from its parameters.
+type MetricFactory func(params MetricParams) (Metric, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]MetricFactory)
+)
+
+// Register makes a metric available by name to config files, the CLI, and
+// NewMetric. It is typically called from the init function of the package
+// that implements the metric:
+//
+//	func init() {
+//	    evaluation.Register("my_metric", func(p evaluation.MetricParams) (evaluation.Metric, error) {
+//	        threshold, err := p.Float("threshold", 0.5)
+//	        if err != nil {
+//	            return nil, err
+//	        }
+//	        return NewMyMetric(threshold), nil
+//	    })
+//	}
+//
+// Register panics if the factory is nil or a metric is registered twice under the same name.
+func Register(name string, factory MetricFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("evaluation: Register factory is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("evaluation: Register called twice for metric " + name)
+	}
+	registry[name] = factory
+}
+
+// RegisteredMetrics returns a sorted list of the names of the registered metrics.
+func RegisteredMetrics() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupMetric returns the factory registered under name.
+func LookupMetric(name string) (MetricFactory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	factory, ok := registry[name]
+	return factory, ok
+}
+
+// NewMetric creates a registered metric by name.
+func NewMetric(name string, params MetricParams) (Metric, error) {
+	factory, ok := LookupMetric(name)
+	if !ok {
+		return nil, fmt.Errorf("evaluation: unknown metric %q (forgotten import?)", name)
+	}
+	if params == nil {
+		params = MetricParams{}
+	}
+	return factory(params)
+}
@@ -0,0 +1,80 @@
+package evaluation
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	Register("test_registry_metric", func(p MetricParams) (Metric, error) {
+		value, err := p.Float("value", 1)
+		if err != nil {
+			return nil, err
+		}
+		return NewMetricFunc("test_registry_metric", func(ctx context.Context, input MetricInput) *ScoreResult {
+			return NewScoreResult("test_registry_metric", value)
+		}), nil
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		if _, ok := LookupMetric("test_registry_metric"); !ok {
+			t.Error("registered metric should be found")
+		}
+		if _, ok := LookupMetric("missing_metric"); ok {
+			t.Error("unregistered metric should not be found")
+		}
+	})
+
+	t.Run("listed", func(t *testing.T) {
+		found := false
+		for _, name := range RegisteredMetrics() {
+			if name == "test_registry_metric" {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("RegisteredMetrics should include the registered metric")
+		}
+	})
+
+	t.Run("new metric with params", func(t *testing.T) {
+		m, err := NewMetric("test_registry_metric", MetricParams{"value": 0.25})
+		if err != nil {
+			t.Fatalf("NewMetric error: %v", err)
+		}
+		if got := m.Score(context.Background(), MetricInput{}).Value; got != 0.25 {
+			t.Errorf("score = %v, want 0.25", got)
+		}
+
+		if _, err := NewMetric("test_registry_metric", nil); err != nil {
+			t.Errorf("NewMetric with nil params error: %v", err)
+		}
+		if _, err := NewMetric("test_registry_metric", MetricParams{"value": "x"}); err == nil {
+			t.Error("expected param error")
+		}
+	})
+
+	t.Run("unknown metric", func(t *testing.T) {
+		if _, err := NewMetric("missing_metric", nil); err == nil {
+			t.Error("expected error for unknown metric")
+		}
+	})
+
+	t.Run("duplicate panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic on duplicate registration")
+			}
+		}()
+		Register("test_registry_metric", func(MetricParams) (Metric, error) { return nil, nil })
+	})
+
+	t.Run("nil factory panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic on nil factory")
+			}
+		}()
+		Register("test_nil_metric", nil)
+	})
+}
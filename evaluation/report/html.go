@@ -0,0 +1,131 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// HTML writes results as a self-contained HTML page: a table of the
+// metrics, a histogram of the scores of each metric, and a table of the
+// failures if there are any.
+func HTML(w io.Writer, results evaluation.EvaluationResults, opts ...Option) error {
+	c, err := newConfig(opts)
+	if err != nil {
+		return err
+	}
+	s := summarize(results, c)
+
+	page := htmlPage{summary: s}
+	for _, m := range s.Metrics {
+		// Scores are usually from 0 to 1; widen the scale for any outside.
+		lo, hi := math.Min(0, m.Min), math.Max(1, m.Max)
+		page.Histograms = append(page.Histograms, histogram{Metric: m.Name, Lo: lo, Hi: hi, Bins: bins(m.Values, lo, hi, c.bins)})
+	}
+	return htmlTemplate.Execute(w, page)
+}
+
+type htmlPage struct {
+	*summary
+	Histograms []histogram
+}
+
+// histogram is the histogram of a metric's scores, from Lo to Hi.
+type histogram struct {
+	Metric string
+	Lo, Hi float64
+	Bins   []bin
+}
+
+// bin is a histogram bar: the number of scores in a range, and its height
+// as a percentage of the highest bar.
+type bin struct {
+	Label   string
+	Count   int
+	Percent float64
+}
+
+// bins sorts values into n bins of equal width from lo to hi.
+func bins(values []float64, lo, hi float64, n int) []bin {
+	width := (hi - lo) / float64(n)
+	out := make([]bin, n)
+	for i := range out {
+		out[i].Label = fmt.Sprintf("%.2f–%.2f", lo+float64(i)*width, lo+float64(i+1)*width)
+	}
+	for _, v := range values {
+		i := min(int((v-lo)/width), n-1)
+		out[i].Count++
+	}
+
+	highest := 0
+	for _, b := range out {
+		highest = max(highest, b.Count)
+	}
+	if highest > 0 {
+		for i := range out {
+			out[i].Percent = 100 * float64(out[i].Count) / float64(highest)
+		}
+	}
+	return out
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"score": formatScore,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #1f2328; }
+table { border-collapse: collapse; margin-bottom: 1.5rem; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.6rem; text-align: left; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+.pass { color: #1a7f37; }
+.fail { color: #cf222e; font-weight: bold; }
+.histogram { display: flex; align-items: flex-end; gap: 2px; height: 120px; margin-bottom: 0.3rem; }
+.histogram div { flex: 1; background: #0969da; min-height: 1px; }
+.axis { display: flex; justify-content: space-between; font-size: 0.8rem; color: #656d76; width: 100%; }
+.chart { max-width: 480px; margin-bottom: 1.5rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Items}} items, {{.Errored}} failed.</p>
+{{- if .Metrics}}
+<h2>Metrics</h2>
+<table>
+<tr><th>Metric</th><th>Mean</th><th>Min</th><th>Max</th><th>Scored</th><th>Not scored</th><th>Failed</th><th>Threshold</th></tr>
+{{- range .Metrics}}
+<tr><td>{{.Name}}</td><td class="num">{{score .Mean}}</td><td class="num">{{score .Min}}</td><td class="num">{{score .Max}}</td><td class="num">{{.Scored}}</td><td class="num">{{.NotScored}}</td><td class="num">{{.Failed}}</td>
+{{- if .Threshold}}<td class="{{if .Passed}}pass{{else}}fail{{end}}">{{score .Threshold}} {{if .Passed}}pass{{else}}fail{{end}}</td>{{else}}<td></td>{{end}}</tr>
+{{- end}}
+</table>
+<h2>Score Distributions</h2>
+{{- range .Histograms}}
+<div class="chart">
+<h3>{{.Metric}}</h3>
+<div class="histogram">
+{{- range .Bins}}
+<div style="height: {{printf "%.1f" .Percent}}%" title="{{.Label}}: {{.Count}}"></div>
+{{- end}}
+</div>
+<div class="axis"><span>{{score .Lo}}</span><span>{{score .Hi}}</span></div>
+</div>
+{{- end}}
+{{- end}}
+{{- if .Failures}}
+<h2>Failures</h2>
+<table>
+<tr><th>Item</th><th>Metric</th><th>Error</th></tr>
+{{- range .Failures}}
+<tr><td>{{.Item}}</td><td>{{.Metric}}</td><td>{{.Error}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+func TestHTML(t *testing.T) {
+	results := testResults()
+	results[0].Scores[0].Name = "<script>"
+
+	var b strings.Builder
+	if err := HTML(&b, results, WithTitle("qa & regression"), WithThreshold("relevance", 0.7), WithHistogramBins(4)); err != nil {
+		t.Fatalf("HTML error: %v", err)
+	}
+	page := b.String()
+
+	for _, want := range []string{
+		"<title>qa &amp; regression</title>",
+		"<td>&lt;script&gt;</td>",
+		`<td class="fail">0.700 fail</td>`,
+		`title="0.75–1.00: 1"`,
+		"<td>item 2</td><td>relevance</td><td>judge timed out</td>",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page does not contain %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Error("page contains an unescaped metric name")
+	}
+}
+
+func TestBins(t *testing.T) {
+	got := bins([]float64{0, 0.2, 0.5, 0.5, 1, 2}, 0, 2, 4)
+	counts := []int{2, 2, 1, 1}
+	for i, b := range got {
+		if b.Count != counts[i] {
+			t.Errorf("bin %d = %+v, want %d scores", i, b, counts[i])
+		}
+	}
+	if got[0].Percent != 100 || got[2].Percent != 50 || got[0].Label != "0.00–0.50" {
+		t.Errorf("bins = %+v", got)
+	}
+}
+
+func TestHTMLEmpty(t *testing.T) {
+	var b strings.Builder
+	if err := HTML(&b, evaluation.EvaluationResults{}); err != nil {
+		t.Fatalf("HTML error: %v", err)
+	}
+	if !strings.Contains(b.String(), "0 items, 0 failed.") {
+		t.Errorf("page = %s", b.String())
+	}
+}
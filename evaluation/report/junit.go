@@ -0,0 +1,134 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// JUnit writes results as JUnit XML, for CI systems that show test
+// reports. Each metric is a test suite with a test case per item. A test
+// case fails if its score is below the metric's threshold, errors if the
+// metric failed on the item or the evaluation of the item failed, and is
+// skipped if the item was not scored. Each test case's time is the judge
+// latency of its score, when it has one.
+func JUnit(w io.Writer, results evaluation.EvaluationResults, opts ...Option) error {
+	c, err := newConfig(opts)
+	if err != nil {
+		return err
+	}
+	s := summarize(results, c)
+
+	doc := junitTestSuites{Name: s.Title}
+	for _, m := range s.Metrics {
+		suite := junitTestSuite{Name: m.Name}
+		for i, res := range results {
+			score := res.Scores.ByName(m.Name)
+			if score == nil && res.Error == nil {
+				continue
+			}
+			suite.TestCases = append(suite.TestCases, testCase(res, i, m, score))
+		}
+		for _, tc := range suite.TestCases {
+			suite.Tests++
+			suite.Time += tc.Time
+			switch {
+			case tc.Failure != nil:
+				suite.Failures++
+			case tc.Error != nil:
+				suite.Errors++
+			case tc.Skipped != nil:
+				suite.Skipped++
+			}
+		}
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Errors += suite.Errors
+		doc.Skipped += suite.Skipped
+		doc.Time += suite.Time
+		doc.TestSuites = append(doc.TestSuites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// testCase returns the test case of the metric m for the i-th result.
+// score is the metric's score of the item, or nil if it has none.
+func testCase(res *evaluation.EvaluationResult, i int, m metricSummary, score *evaluation.ScoreResult) junitCase {
+	tc := junitCase{ClassName: m.Name, Name: itemName(res, i)}
+	switch {
+	case score == nil:
+		tc.Error = &junitMessage{Message: res.Error.Error()}
+	case score.IsNotScored():
+		tc.Skipped = &junitMessage{Message: score.Reason}
+	case !score.IsSuccess():
+		tc.Error = &junitMessage{Message: scoreError(score)}
+	default:
+		if m.Threshold != nil && score.Value < *m.Threshold {
+			tc.Failure = &junitMessage{
+				Message: fmt.Sprintf("score %s below threshold %s", formatScore(score.Value), formatScore(*m.Threshold)),
+				Text:    score.Reason,
+			}
+		} else if score.Reason != "" {
+			tc.SystemOut = score.Reason
+		}
+	}
+	if score != nil && score.Provenance != nil {
+		tc.Time = seconds(score.Provenance.Latency.Seconds())
+	}
+	return tc
+}
+
+// seconds is a duration in seconds, the unit of JUnit times.
+type seconds float64
+
+func (s seconds) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: fmt.Sprintf("%.3f", float64(s))}, nil
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       seconds          `xml:"time,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      seconds     `xml:"time,attr"`
+	TestCases []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      seconds       `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+	Skipped   *junitMessage `xml:"skipped"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
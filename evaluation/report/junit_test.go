@@ -0,0 +1,45 @@
+package report
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestJUnit(t *testing.T) {
+	var b strings.Builder
+	if err := JUnit(&b, testResults(), WithTitle("qa"), WithThreshold("relevance", 0.7)); err != nil {
+		t.Fatalf("JUnit error: %v", err)
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal([]byte(b.String()), &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, b.String())
+	}
+	if doc.Name != "qa" || doc.Tests != 10 || doc.Failures != 1 || doc.Errors != 4 || doc.Skipped != 1 {
+		t.Errorf("testsuites = %+v", doc)
+	}
+
+	relevance := doc.TestSuites[2]
+	if relevance.Name != "relevance" || len(relevance.TestCases) != 4 || relevance.Time != 1.5 {
+		t.Fatalf("relevance suite = %+v", relevance)
+	}
+	cases := relevance.TestCases
+	if cases[0].Name != "q1" || cases[0].Failure != nil || cases[0].SystemOut != "on topic" || cases[0].Time != 1.5 {
+		t.Errorf("q1 = %+v", cases[0])
+	}
+	if f := cases[1].Failure; f == nil || f.Message != "score 0.400 below threshold 0.700" || f.Text != "off | topic" {
+		t.Errorf("q2 = %+v", cases[1])
+	}
+	if e := cases[2].Error; cases[2].Name != "item 2" || e == nil || e.Message != "judge timed out" {
+		t.Errorf("item 2 = %+v", cases[2])
+	}
+	if e := cases[3].Error; e == nil || e.Message != "task failed" {
+		t.Errorf("q4 = %+v", cases[3])
+	}
+
+	recall := doc.TestSuites[0]
+	if len(recall.TestCases) != 2 || recall.TestCases[0].Skipped == nil || recall.TestCases[0].Skipped.Message != "no context" {
+		t.Errorf("context_recall suite = %+v", recall)
+	}
+}
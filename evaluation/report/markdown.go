@@ -0,0 +1,62 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// Markdown writes results as a Markdown report: a table of the metrics,
+// and a table of the failures if there are any.
+func Markdown(w io.Writer, results evaluation.EvaluationResults, opts ...Option) error {
+	c, err := newConfig(opts)
+	if err != nil {
+		return err
+	}
+	s := summarize(results, c)
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "# %s\n\n", markdownCell(s.Title))
+	fmt.Fprintf(b, "%d items, %d failed.\n", s.Items, s.Errored)
+
+	if len(s.Metrics) > 0 {
+		b.WriteString("\n| Metric | Mean | Min | Max | Scored | Not scored | Failed | Threshold |\n")
+		b.WriteString("|--------|-----:|----:|----:|-------:|-----------:|-------:|-----------|\n")
+		for _, m := range s.Metrics {
+			fmt.Fprintf(b, "| %s | %s | %s | %s | %d | %d | %d | %s |\n",
+				markdownCell(m.Name), formatScore(m.Mean), formatScore(m.Min), formatScore(m.Max),
+				m.Scored, m.NotScored, m.Failed, thresholdStatus(m))
+		}
+	}
+
+	if len(s.Failures) > 0 {
+		b.WriteString("\n## Failures\n\n")
+		b.WriteString("| Item | Metric | Error |\n")
+		b.WriteString("|------|--------|-------|\n")
+		for _, f := range s.Failures {
+			fmt.Fprintf(b, "| %s | %s | %s |\n", markdownCell(f.Item), markdownCell(f.Metric), markdownCell(f.Error))
+		}
+	}
+	return b.Flush()
+}
+
+// thresholdStatus describes whether a metric's mean reaches its threshold.
+func thresholdStatus(m metricSummary) string {
+	if m.Threshold == nil {
+		return ""
+	}
+	if m.Passed() {
+		return formatScore(*m.Threshold) + " pass"
+	}
+	return formatScore(*m.Threshold) + " **fail**"
+}
+
+// markdownCellReplacer escapes text for a Markdown table cell.
+var markdownCellReplacer = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+func markdownCell(s string) string {
+	return markdownCellReplacer.Replace(s)
+}
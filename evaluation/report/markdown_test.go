@@ -0,0 +1,40 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	var b strings.Builder
+	if err := Markdown(&b, testResults(), WithThreshold("relevance", 0.7), WithThreshold("equals", 0.5)); err != nil {
+		t.Fatalf("Markdown error: %v", err)
+	}
+
+	want := `# Evaluation Report
+
+4 items, 1 failed.
+
+| Metric | Mean | Min | Max | Scored | Not scored | Failed | Threshold |
+|--------|-----:|----:|----:|-------:|-----------:|-------:|-----------|
+| context_recall | 0.000 | 0.000 | 0.000 | 0 | 1 | 0 |  |
+| equals | 0.667 | 0.000 | 1.000 | 3 | 0 | 0 | 0.500 pass |
+| relevance | 0.650 | 0.400 | 0.900 | 2 | 0 | 1 | 0.700 **fail** |
+
+## Failures
+
+| Item | Metric | Error |
+|------|--------|-------|
+| item 2 | relevance | judge timed out |
+| q4 |  | task failed |
+`
+	if b.String() != want {
+		t.Errorf("Markdown =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestMarkdownCell(t *testing.T) {
+	if got := markdownCell("a | b\nc"); got != `a \| b<br>c` {
+		t.Errorf("markdownCell = %q", got)
+	}
+}
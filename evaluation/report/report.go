@@ -0,0 +1,203 @@
+// Package report writes evaluation results as reports: Markdown tables for
+// pull requests and wikis, an HTML page with a score histogram per metric,
+// and JUnit XML for CI systems.
+//
+//	results := evaluator.Evaluate(ctx, items)
+//
+//	f, _ := os.Create("eval.xml")
+//	defer f.Close()
+//	err := report.JUnit(f, results,
+//	    report.WithTitle("qa-regression"),
+//	    report.WithThreshold("answer_relevance", 0.7),
+//	)
+//
+// Every report summarizes each metric: the mean, minimum, and maximum of
+// its scores, and how many items it scored, skipped, and failed on. Items
+// the evaluation failed on entirely and failed metric results are listed
+// as failures.
+//
+// A threshold set with WithThreshold applies to a metric's mean in the
+// Markdown and HTML reports, as evalconfig thresholds do, and to each
+// item's score in JUnit reports, so every item below it is a failed test
+// case.
+package report
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// DefaultTitle is the title of reports without WithTitle.
+const DefaultTitle = "Evaluation Report"
+
+// defaultBins is the number of histogram bins of HTML reports.
+const defaultBins = 10
+
+// Option configures a report.
+type Option func(*config)
+
+type config struct {
+	title      string
+	thresholds map[string]float64
+	bins       int
+
+	// problems are invalid option values, returned by the report writers.
+	problems evaluation.Problems
+}
+
+// WithTitle sets the title of the report, which is the name of the test
+// suites of JUnit reports.
+func WithTitle(title string) Option {
+	return func(c *config) {
+		c.title = title
+	}
+}
+
+// WithThreshold sets the minimum score of a metric.
+func WithThreshold(metric string, min float64) Option {
+	return func(c *config) {
+		if c.thresholds == nil {
+			c.thresholds = make(map[string]float64)
+		}
+		c.thresholds[metric] = min
+	}
+}
+
+// WithHistogramBins sets the number of bins of the score histograms of HTML
+// reports. The default is 10.
+func WithHistogramBins(n int) Option {
+	return func(c *config) {
+		if n <= 0 {
+			c.problems.Addf("histogram bins must be positive: %d", n)
+			return
+		}
+		c.bins = n
+	}
+}
+
+func newConfig(opts []Option) (*config, error) {
+	c := &config{title: DefaultTitle, bins: defaultBins}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.problems.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// summary is the summary of an evaluation run that reports are written
+// from.
+type summary struct {
+	Title   string
+	Items   int
+	Errored int
+	Metrics []metricSummary
+	// Failures are the failed items and metric results, in result order.
+	Failures []failure
+}
+
+// metricSummary summarizes the results of one metric.
+type metricSummary struct {
+	Name      string
+	Mean      float64
+	Min       float64
+	Max       float64
+	Scored    int
+	NotScored int
+	Failed    int
+	// Values are the scores, in result order.
+	Values []float64
+	// Threshold is the metric's threshold, if it has one.
+	Threshold *float64
+}
+
+// Passed reports whether the mean reaches the threshold, if there is one.
+func (m metricSummary) Passed() bool {
+	return m.Threshold == nil || m.Scored > 0 && m.Mean >= *m.Threshold
+}
+
+// failure is a failed item, with an empty Metric, or a failed metric
+// result.
+type failure struct {
+	Item   string
+	Metric string
+	Error  string
+}
+
+// summarize summarizes results.
+func summarize(results evaluation.EvaluationResults, c *config) *summary {
+	s := &summary{Title: c.title, Items: len(results)}
+	metrics := make(map[string]*metricSummary)
+	var names []string
+	for i, res := range results {
+		if res.Error != nil {
+			s.Errored++
+			s.Failures = append(s.Failures, failure{Item: itemName(res, i), Error: res.Error.Error()})
+		}
+		for _, score := range res.Scores {
+			m, ok := metrics[score.Name]
+			if !ok {
+				m = &metricSummary{Name: score.Name, Min: math.Inf(1), Max: math.Inf(-1)}
+				if t, ok := c.thresholds[score.Name]; ok {
+					m.Threshold = &t
+				}
+				metrics[score.Name] = m
+				names = append(names, score.Name)
+			}
+			switch {
+			case score.IsNotScored():
+				m.NotScored++
+			case !score.IsSuccess():
+				m.Failed++
+				s.Failures = append(s.Failures, failure{Item: itemName(res, i), Metric: score.Name, Error: scoreError(score)})
+			default:
+				m.Scored++
+				m.Values = append(m.Values, score.Value)
+				m.Min = math.Min(m.Min, score.Value)
+				m.Max = math.Max(m.Max, score.Value)
+			}
+		}
+	}
+
+	slices.Sort(names)
+	for _, name := range names {
+		m := metrics[name]
+		if m.Scored == 0 {
+			m.Min, m.Max = 0, 0
+		} else {
+			var sum float64
+			for _, v := range m.Values {
+				sum += v
+			}
+			m.Mean = sum / float64(m.Scored)
+		}
+		s.Metrics = append(s.Metrics, *m)
+	}
+	return s
+}
+
+// itemName returns the name of the i-th result in reports: its item ID, or
+// its index.
+func itemName(res *evaluation.EvaluationResult, i int) string {
+	if res.ItemID != "" {
+		return res.ItemID
+	}
+	return fmt.Sprintf("item %d", i)
+}
+
+// scoreError returns the error message of a failed score.
+func scoreError(score *evaluation.ScoreResult) string {
+	if score.Error != nil {
+		return score.Error.Error()
+	}
+	return "metric failed"
+}
+
+// formatScore formats a score for tables.
+func formatScore(v float64) string {
+	return fmt.Sprintf("%.3f", v)
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// testResults returns the results of a run over four items: two scored,
+// one the relevance judge failed on, and one the evaluation failed on.
+func testResults() evaluation.EvaluationResults {
+	judged := evaluation.NewScoreResultWithReason("relevance", 0.9, "on topic")
+	judged.Provenance = &evaluation.Provenance{Latency: 1500 * time.Millisecond}
+	return evaluation.EvaluationResults{
+		{ItemID: "q1", Scores: evaluation.ScoreResults{
+			evaluation.NewScoreResult("equals", 1),
+			judged,
+		}},
+		{ItemID: "q2", Scores: evaluation.ScoreResults{
+			evaluation.NewScoreResult("equals", 0),
+			evaluation.NewScoreResultWithReason("relevance", 0.4, "off | topic"),
+		}},
+		{Scores: evaluation.ScoreResults{
+			evaluation.NewScoreResult("equals", 1),
+			evaluation.NewFailedScoreResult("relevance", errors.New("judge timed out")),
+			evaluation.NewNotScoredResult("context_recall", "no context"),
+		}},
+		{ItemID: "q4", Error: errors.New("task failed")},
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	c, err := newConfig([]Option{WithTitle("qa"), WithThreshold("relevance", 0.7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := summarize(testResults(), c)
+
+	if s.Title != "qa" || s.Items != 4 || s.Errored != 1 || len(s.Metrics) != 3 {
+		t.Fatalf("summary = %+v", s)
+	}
+	recall, equals, relevance := s.Metrics[0], s.Metrics[1], s.Metrics[2]
+	if recall.Name != "context_recall" || recall.NotScored != 1 || recall.Min != 0 || !recall.Passed() {
+		t.Errorf("context_recall = %+v", recall)
+	}
+	if equals.Mean != 2.0/3 || equals.Min != 0 || equals.Max != 1 || equals.Scored != 3 {
+		t.Errorf("equals = %+v", equals)
+	}
+	if relevance.Mean != 0.65 || relevance.Failed != 1 || relevance.Passed() {
+		t.Errorf("relevance = %+v", relevance)
+	}
+
+	want := []failure{
+		{Item: "item 2", Metric: "relevance", Error: "judge timed out"},
+		{Item: "q4", Error: "task failed"},
+	}
+	if len(s.Failures) != len(want) || s.Failures[0] != want[0] || s.Failures[1] != want[1] {
+		t.Errorf("failures = %+v", s.Failures)
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	var validationErr *evaluation.ValidationError
+	if _, err := newConfig([]Option{WithHistogramBins(0)}); !errors.As(err, &validationErr) {
+		t.Errorf("error = %v, want a *ValidationError", err)
+	}
+}
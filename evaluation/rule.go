@@ -0,0 +1,24 @@
+package evaluation
+
+// RuleSpec describes a metric's check in a portable form, so the check can
+// run outside the SDK, for example as an online scoring rule on the Opik
+// server.
+type RuleSpec struct {
+	// Name is the name of the scores the check produces.
+	Name string
+	// Kind identifies the check, such as "contains" or "regex_match".
+	Kind string
+	// Params are the check's settings, such as a pattern or a list of
+	// values. Values are strings, bools, numbers, or string slices.
+	Params map[string]any
+	// NeedsReference is true if the check compares the output to
+	// MetricInput.Expected.
+	NeedsReference bool
+}
+
+// RuleSpecer is implemented by metrics that can describe their check as a
+// RuleSpec. Simple heuristic metrics, such as string, regex, and JSON
+// checks, implement it.
+type RuleSpecer interface {
+	RuleSpec() RuleSpec
+}
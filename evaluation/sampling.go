@@ -0,0 +1,165 @@
+package evaluation
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"math"
+	"slices"
+)
+
+// DisagreementFunc measures how much a set of metric scores for one item
+// disagree. Higher values mark items more worth labeling.
+type DisagreementFunc func(scores []float64) float64
+
+// ScoreRange is the difference between the highest and lowest score.
+// It is the default disagreement measure.
+func ScoreRange(scores []float64) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	return slices.Max(scores) - slices.Min(scores)
+}
+
+// ScoreStdDev is the population standard deviation of the scores. It is
+// less sensitive than ScoreRange to a single outlying judge in an ensemble.
+func ScoreStdDev(scores []float64) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	var mean float64
+	for _, s := range scores {
+		mean += s
+	}
+	mean /= float64(len(scores))
+	var variance float64
+	for _, s := range scores {
+		variance += (s - mean) * (s - mean)
+	}
+	return math.Sqrt(variance / float64(len(scores)))
+}
+
+// LabelCandidate is an evaluated item proposed for human labeling.
+type LabelCandidate struct {
+	// ItemID is the identifier of the evaluated item.
+	ItemID string `json:"item_id,omitempty"`
+	// Input is the evaluated input.
+	Input MetricInput `json:"input"`
+	// Scores are the metric scores that were compared, by metric name.
+	Scores map[string]float64 `json:"scores"`
+	// Disagreement is how much the scores disagree.
+	Disagreement float64 `json:"disagreement"`
+}
+
+// Sampler ranks evaluation results by disagreement between metrics, such as
+// a heuristic metric and an LLM judge or the judges of an ensemble, so the
+// most informative items can be labeled first.
+type Sampler struct {
+	metrics      []string
+	disagreement DisagreementFunc
+	isLabeled    func(*EvaluationResult) bool
+}
+
+// SamplerOption configures a Sampler.
+type SamplerOption func(*Sampler)
+
+// WithSamplerMetrics compares only the named metrics. By default every
+// successful score of an item is compared.
+func WithSamplerMetrics(names ...string) SamplerOption {
+	return func(s *Sampler) {
+		s.metrics = names
+	}
+}
+
+// WithDisagreement sets how disagreement between scores is measured.
+func WithDisagreement(fn DisagreementFunc) SamplerOption {
+	return func(s *Sampler) {
+		s.disagreement = fn
+	}
+}
+
+// WithSkipLabeled excludes results for which isLabeled returns true, such
+// as items that already have a human label.
+func WithSkipLabeled(isLabeled func(*EvaluationResult) bool) SamplerOption {
+	return func(s *Sampler) {
+		s.isLabeled = isLabeled
+	}
+}
+
+// NewSampler creates a sampler.
+func NewSampler(opts ...SamplerOption) *Sampler {
+	s := &Sampler{disagreement: ScoreRange}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Rank returns a candidate for every result with at least two comparable
+// scores, most disagreement first. Failed results are skipped, and ties keep
+// the order of results.
+func (s *Sampler) Rank(results EvaluationResults) []LabelCandidate {
+	candidates := make([]LabelCandidate, 0, len(results))
+	for _, res := range results {
+		if !res.IsSuccess() || (s.isLabeled != nil && s.isLabeled(res)) {
+			continue
+		}
+		scores := s.comparableScores(res.Scores)
+		if len(scores) < 2 {
+			continue
+		}
+		values := make([]float64, 0, len(scores))
+		for _, v := range scores {
+			values = append(values, v)
+		}
+		candidates = append(candidates, LabelCandidate{
+			ItemID:       res.ItemID,
+			Input:        res.Input,
+			Scores:       scores,
+			Disagreement: s.disagreement(values),
+		})
+	}
+	slices.SortStableFunc(candidates, func(a, b LabelCandidate) int {
+		return cmp.Compare(b.Disagreement, a.Disagreement)
+	})
+	return candidates
+}
+
+// Select returns the n most informative candidates.
+func (s *Sampler) Select(results EvaluationResults, n int) []LabelCandidate {
+	candidates := s.Rank(results)
+	if n < len(candidates) {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+func (s *Sampler) comparableScores(results ScoreResults) map[string]float64 {
+	scores := make(map[string]float64)
+	if len(s.metrics) == 0 {
+		for _, score := range results {
+			if score.IsSuccess() {
+				scores[score.Name] = score.Value
+			}
+		}
+		return scores
+	}
+	for _, name := range s.metrics {
+		if score := results.ByName(name); score != nil && score.IsSuccess() {
+			scores[name] = score.Value
+		}
+	}
+	return scores
+}
+
+// WriteLabelingQueue writes candidates as JSON Lines, one candidate per line,
+// for import into a labeling tool.
+func WriteLabelingQueue(w io.Writer, candidates []LabelCandidate) error {
+	enc := json.NewEncoder(w)
+	for _, c := range candidates {
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package evaluation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func samplingResults() EvaluationResults {
+	result := func(id string, scores ...*ScoreResult) *EvaluationResult {
+		return &EvaluationResult{ItemID: id, Input: NewMetricInput("q-"+id, "a-"+id), Scores: scores}
+	}
+	return EvaluationResults{
+		result("agree", NewScoreResult("heuristic", 0.9), NewScoreResult("judge", 0.9)),
+		result("split", NewScoreResult("heuristic", 1), NewScoreResult("judge", 0)),
+		result("some", NewScoreResult("heuristic", 0.6), NewScoreResult("judge", 0.2), NewScoreResult("other", 0.6)),
+		result("single", NewScoreResult("heuristic", 0.5)),
+		result("judge-failed", NewScoreResult("heuristic", 1), NewFailedScoreResult("judge", errors.New("timeout"))),
+		{ItemID: "failed", Error: errors.New("boom")},
+	}
+}
+
+func candidateIDs(candidates []LabelCandidate) []string {
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.ItemID
+	}
+	return ids
+}
+
+func TestSamplerRank(t *testing.T) {
+	candidates := NewSampler().Rank(samplingResults())
+
+	ids := candidateIDs(candidates)
+	want := []string{"split", "some", "agree"}
+	if len(ids) != len(want) {
+		t.Fatalf("ranked = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("ranked = %v, want %v", ids, want)
+			break
+		}
+	}
+	if candidates[0].Disagreement != 1 || candidates[0].Scores["judge"] != 0 {
+		t.Errorf("top candidate = %+v", candidates[0])
+	}
+	if candidates[0].Input.Output != "a-split" {
+		t.Errorf("candidate input = %+v", candidates[0].Input)
+	}
+}
+
+func TestSamplerOptions(t *testing.T) {
+	sampler := NewSampler(
+		WithSamplerMetrics("heuristic", "other"),
+		WithDisagreement(ScoreStdDev),
+		WithSkipLabeled(func(r *EvaluationResult) bool { return r.ItemID == "agree" }),
+	)
+
+	candidates := sampler.Select(samplingResults(), 5)
+	if len(candidates) != 1 || candidates[0].ItemID != "some" {
+		t.Fatalf("selected = %v, want [some]", candidateIDs(candidates))
+	}
+	if len(candidates[0].Scores) != 2 || candidates[0].Disagreement != 0 {
+		t.Errorf("candidate = %+v", candidates[0])
+	}
+
+	if got := NewSampler().Select(samplingResults(), 1); len(got) != 1 || got[0].ItemID != "split" {
+		t.Errorf("Select(1) = %v", candidateIDs(got))
+	}
+}
+
+func TestDisagreementFuncs(t *testing.T) {
+	scores := []float64{0, 0.5, 1}
+	if got := ScoreRange(scores); got != 1 {
+		t.Errorf("ScoreRange = %v, want 1", got)
+	}
+	if got := ScoreStdDev(scores); math.Abs(got-math.Sqrt(1.0/6)) > 1e-9 {
+		t.Errorf("ScoreStdDev = %v", got)
+	}
+	if ScoreRange(nil) != 0 || ScoreStdDev(nil) != 0 {
+		t.Error("empty scores should have no disagreement")
+	}
+}
+
+func TestWriteLabelingQueue(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteLabelingQueue(&buf, NewSampler().Rank(samplingResults())); err != nil {
+		t.Fatalf("WriteLabelingQueue error: %v", err)
+	}
+
+	var lines int
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var c LabelCandidate
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			t.Fatalf("line %d: %v", lines, err)
+		}
+		if lines == 0 && (c.ItemID != "split" || c.Input.Input != "q-split") {
+			t.Errorf("first line = %+v", c)
+		}
+		lines++
+	}
+	if lines != 3 {
+		t.Errorf("lines = %d, want 3", lines)
+	}
+}
@@ -3,12 +3,30 @@ package evaluation
 import (
 	"encoding/json"
 	"fmt"
+	"time"
+)
+
+// ScoreStatus is the outcome of a metric evaluation.
+type ScoreStatus string
+
+const (
+	// ScoreStatusScored means the metric produced a score.
+	ScoreStatusScored ScoreStatus = "scored"
+	// ScoreStatusNotScored means the metric was skipped, for example because
+	// the input lacks a field it needs or the engine's budget ran out. The
+	// result's Reason says why. Not-scored results are left out of averages.
+	ScoreStatusNotScored ScoreStatus = "not_scored"
+	// ScoreStatusFailed means the metric failed with an error.
+	ScoreStatusFailed ScoreStatus = "failed"
 )
 
 // ScoreResult represents the result of a metric evaluation.
 type ScoreResult struct {
 	// Name is the name of the metric.
 	Name string `json:"name"`
+	// Status is the outcome of the evaluation. A result with an Error is
+	// failed whatever its Status, unless Status is ScoreStatusNotScored.
+	Status ScoreStatus `json:"status,omitempty"`
 	// Value is the numeric score value (typically 0.0 to 1.0).
 	Value float64 `json:"value"`
 	// Reason is an optional explanation for the score.
@@ -17,16 +35,84 @@ type ScoreResult struct {
 	Metadata map[string]any `json:"metadata,omitempty"`
 	// Error is set if the metric evaluation failed.
 	Error error `json:"error,omitempty"`
+	// Provenance records how the score was produced, for LLM judge metrics.
+	Provenance *Provenance `json:"provenance,omitempty"`
+	// Confidence is how confident the metric is in the score, from 0.0 to
+	// 1.0, or nil if the metric does not report one.
+	Confidence *float64 `json:"confidence,omitempty"`
+	// SubScores are named components of the score, such as the precision
+	// and recall behind an F-score or per-category moderation scores.
+	// EvaluationResults.Summary reports them as "metric.subscore".
+	SubScores map[string]float64 `json:"sub_scores,omitempty"`
+}
+
+// Provenance records how a score was produced so results can be reproduced
+// and audited.
+type Provenance struct {
+	// Model is the judge model that produced the score.
+	Model string `json:"model,omitempty"`
+	// Provider is the name of the judge provider.
+	Provider string `json:"provider,omitempty"`
+	// PromptHash is a SHA-256 hash of the prompt messages sent to the judge.
+	PromptHash string `json:"prompt_hash,omitempty"`
+	// Temperature is the sampling temperature used for the judge.
+	Temperature float64 `json:"temperature"`
+	// Retries is the number of attempts made after the first one.
+	Retries int `json:"retries"`
+	// PromptTokens is the total number of prompt tokens across attempts.
+	PromptTokens int `json:"prompt_tokens,omitempty"`
+	// OutputTokens is the total number of output tokens across attempts.
+	OutputTokens int `json:"output_tokens,omitempty"`
+	// Latency is the total time spent producing the score, including retries.
+	Latency time.Duration `json:"latency"`
+	// Route is the name of the routing rule that chose the judge model, when
+	// the judge uses a router provider.
+	Route string `json:"route,omitempty"`
+	// Coalesced is true when the judge response was shared with another
+	// metric's identical request. Its tokens are then counted only in the
+	// provenance of the metric that sent the request.
+	Coalesced bool `json:"coalesced,omitempty"`
+	// OmittedMessages is the number of prompt messages left out to fit the
+	// judge's prompt limit.
+	OmittedMessages int `json:"omitted_messages,omitempty"`
+	// PromptName and PromptCommit identify the prompt library version the
+	// judge's prompt was rendered from, when it was loaded from one.
+	PromptName   string `json:"prompt_name,omitempty"`
+	PromptCommit string `json:"prompt_commit,omitempty"`
 }
 
-// IsSuccess returns true if the score was computed successfully.
+// IsSuccess returns true if the score was computed successfully. A
+// not-scored result is neither successful nor failed.
 func (s *ScoreResult) IsSuccess() bool {
-	return s.Error == nil
+	return s.status() == ScoreStatusScored
+}
+
+// IsNotScored returns true if the metric was skipped.
+func (s *ScoreResult) IsNotScored() bool {
+	return s.status() == ScoreStatusNotScored
+}
+
+// status returns the result's status. A result with an Error is failed
+// unless it was marked not scored.
+func (s *ScoreResult) status() ScoreStatus {
+	switch {
+	case s.Status == ScoreStatusNotScored:
+		return ScoreStatusNotScored
+	case s.Error != nil || s.Status == ScoreStatusFailed:
+		return ScoreStatusFailed
+	}
+	return ScoreStatusScored
 }
 
 // String returns a human-readable representation of the score.
 func (s *ScoreResult) String() string {
-	if s.Error != nil {
+	switch s.status() {
+	case ScoreStatusNotScored:
+		if s.Reason != "" {
+			return fmt.Sprintf("%s: not scored (%s)", s.Name, s.Reason)
+		}
+		return fmt.Sprintf("%s: not scored", s.Name)
+	case ScoreStatusFailed:
 		return fmt.Sprintf("%s: error - %v", s.Name, s.Error)
 	}
 	if s.Reason != "" {
@@ -35,6 +121,21 @@ func (s *ScoreResult) String() string {
 	return fmt.Sprintf("%s: %.4f", s.Name, s.Value)
 }
 
+// WithConfidence sets the confidence of the score and returns the result.
+func (s *ScoreResult) WithConfidence(confidence float64) *ScoreResult {
+	s.Confidence = &confidence
+	return s
+}
+
+// WithSubScore adds a named sub-score and returns the result.
+func (s *ScoreResult) WithSubScore(name string, value float64) *ScoreResult {
+	if s.SubScores == nil {
+		s.SubScores = make(map[string]float64)
+	}
+	s.SubScores[name] = value
+	return s
+}
+
 // ToJSON returns the score as JSON bytes.
 func (s *ScoreResult) ToJSON() ([]byte, error) {
 	return json.Marshal(s)
@@ -77,9 +178,18 @@ func (r ScoreResults) Successful() ScoreResults {
 
 // Failed returns only the failed score results.
 func (r ScoreResults) Failed() ScoreResults {
+	return r.withStatus(ScoreStatusFailed)
+}
+
+// NotScored returns only the score results of skipped metrics.
+func (r ScoreResults) NotScored() ScoreResults {
+	return r.withStatus(ScoreStatusNotScored)
+}
+
+func (r ScoreResults) withStatus(status ScoreStatus) ScoreResults {
 	results := make(ScoreResults, 0)
 	for _, s := range r {
-		if !s.IsSuccess() {
+		if s.status() == status {
 			results = append(results, s)
 		}
 	}
@@ -115,8 +225,9 @@ func (r ScoreResults) AverageByName(name string) float64 {
 // NewScoreResult creates a new successful score result.
 func NewScoreResult(name string, value float64) *ScoreResult {
 	return &ScoreResult{
-		Name:  name,
-		Value: value,
+		Name:   name,
+		Status: ScoreStatusScored,
+		Value:  value,
 	}
 }
 
@@ -124,6 +235,7 @@ func NewScoreResult(name string, value float64) *ScoreResult {
 func NewScoreResultWithReason(name string, value float64, reason string) *ScoreResult {
 	return &ScoreResult{
 		Name:   name,
+		Status: ScoreStatusScored,
 		Value:  value,
 		Reason: reason,
 	}
@@ -132,8 +244,20 @@ func NewScoreResultWithReason(name string, value float64, reason string) *ScoreR
 // NewFailedScoreResult creates a new failed score result.
 func NewFailedScoreResult(name string, err error) *ScoreResult {
 	return &ScoreResult{
-		Name:  name,
-		Error: err,
+		Name:   name,
+		Status: ScoreStatusFailed,
+		Error:  err,
+	}
+}
+
+// NewNotScoredResult creates a result for a metric that was skipped, with
+// the reason it was skipped. Use it rather than a zero score when an input
+// lacks what the metric needs, so the item does not drag down averages.
+func NewNotScoredResult(name, reason string) *ScoreResult {
+	return &ScoreResult{
+		Name:   name,
+		Status: ScoreStatusNotScored,
+		Reason: reason,
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -323,3 +324,110 @@ func TestScoreResultEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestScoreResultProvenanceJSON(t *testing.T) {
+	s := NewScoreResult("judge", 0.5)
+
+	data, err := s.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON error: %v", err)
+	}
+	if strings.Contains(string(data), "provenance") {
+		t.Error("provenance should be omitted when not set")
+	}
+
+	s.Provenance = &Provenance{Model: "gpt-4o", Provider: "openai", Retries: 1}
+	data, err = s.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON error: %v", err)
+	}
+	for _, want := range []string{`"provenance"`, `"model":"gpt-4o"`, `"provider":"openai"`, `"retries":1`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON %s missing %s", data, want)
+		}
+	}
+}
+
+func TestScoreResultConfidenceAndSubScores(t *testing.T) {
+	s := NewScoreResult("rouge_l", 0.5)
+	data, _ := s.ToJSON()
+	if strings.Contains(string(data), "confidence") || strings.Contains(string(data), "sub_scores") {
+		t.Errorf("JSON %s should omit unset confidence and sub-scores", data)
+	}
+
+	s.WithConfidence(0.9).WithSubScore("precision", 0.4).WithSubScore("recall", 0.6)
+	if s.Confidence == nil || *s.Confidence != 0.9 {
+		t.Errorf("Confidence = %v, want 0.9", s.Confidence)
+	}
+	if s.SubScores["precision"] != 0.4 || s.SubScores["recall"] != 0.6 {
+		t.Errorf("SubScores = %v", s.SubScores)
+	}
+
+	data, err := s.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON error: %v", err)
+	}
+	for _, want := range []string{`"confidence":0.9`, `"sub_scores":{"precision":0.4,"recall":0.6}`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON %s missing %s", data, want)
+		}
+	}
+}
+
+func TestScoreResultStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		result        *ScoreResult
+		success, skip bool
+		str           string
+	}{
+		{"scored", NewScoreResult("m", 0.5), true, false, "m: 0.5000"},
+		{"literal", &ScoreResult{Name: "m", Value: 0.5}, true, false, "m: 0.5000"},
+		{"failed", NewFailedScoreResult("m", errors.New("boom")), false, false, "m: error - boom"},
+		{"error without status", &ScoreResult{Name: "m", Status: ScoreStatusScored, Error: errors.New("boom")}, false, false, "m: error - boom"},
+		{"not scored", NewNotScoredResult("m", "no context"), false, true, "m: not scored (no context)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.IsSuccess(); got != tt.success {
+				t.Errorf("IsSuccess() = %v, want %v", got, tt.success)
+			}
+			if got := tt.result.IsNotScored(); got != tt.skip {
+				t.Errorf("IsNotScored() = %v, want %v", got, tt.skip)
+			}
+			if got := tt.result.String(); got != tt.str {
+				t.Errorf("String() = %q, want %q", got, tt.str)
+			}
+		})
+	}
+}
+
+func TestScoreResultsNotScored(t *testing.T) {
+	results := ScoreResults{
+		NewScoreResult("a", 1.0),
+		NewNotScoredResult("b", "no expected output"),
+		NewFailedScoreResult("c", errors.New("boom")),
+		NewScoreResult("a", 0.5),
+	}
+
+	if got := len(results.NotScored()); got != 1 {
+		t.Errorf("NotScored() = %d results, want 1", got)
+	}
+	if got := len(results.Failed()); got != 1 {
+		t.Errorf("Failed() = %d results, want 1", got)
+	}
+	if got := len(results.Successful()); got != 2 {
+		t.Errorf("Successful() = %d results, want 2", got)
+	}
+	if got := results.Average(); got != 0.75 {
+		t.Errorf("Average() = %v, want 0.75", got)
+	}
+
+	data, err := results[1].ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON error: %v", err)
+	}
+	if !strings.Contains(string(data), `"status":"not_scored"`) {
+		t.Errorf("JSON %s missing not_scored status", data)
+	}
+}
@@ -0,0 +1,77 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// Task produces the output to evaluate for a dataset item, typically by
+// calling the application under test. Its output is merged into the item
+// before the dataset evaluator's mapper turns it into a MetricInput, so a
+// task usually returns the field the mapper reads as the output:
+//
+//	task := func(ctx context.Context, item map[string]any) (map[string]any, error) {
+//		answer, err := app.Answer(ctx, item["question"].(string))
+//		return map[string]any{"output": answer}, err
+//	}
+type Task func(ctx context.Context, item map[string]any) (map[string]any, error)
+
+// EvaluateTask runs task on each dataset item, then evaluates the item with
+// the task's output merged into it. Tasks run with the engine's
+// concurrency; an item whose task fails is reported with the task's error
+// and not scored. Wrap the task with NewCachedTask to skip items whose
+// output is already known.
+func (d *DatasetEvaluator) EvaluateTask(ctx context.Context, items []map[string]any, task Task) EvaluationResults {
+	merged := make([]map[string]any, len(items))
+	errs := make([]error, len(items))
+
+	sem := make(chan struct{}, max(d.engine.concurrency, 1))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		if ctx.Err() != nil {
+			errs[i] = canceledError(ctx)
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			output, err := task(ctx, item)
+			if err != nil {
+				errs[i] = fmt.Errorf("task: %w", err)
+				return
+			}
+			m := maps.Clone(item)
+			if m == nil {
+				m = make(map[string]any, len(output))
+			}
+			maps.Copy(m, output)
+			merged[i] = m
+		}()
+	}
+	wg.Wait()
+
+	var inputs []MetricInput
+	var indexes []int
+	for i, m := range merged {
+		if errs[i] == nil {
+			inputs = append(inputs, d.inputMapper(m))
+			indexes = append(indexes, i)
+		}
+	}
+	scored := d.engine.EvaluateMany(ctx, inputs)
+
+	results := make(EvaluationResults, len(items))
+	for i, err := range errs {
+		if err != nil {
+			results[i] = &EvaluationResult{Input: d.inputMapper(items[i]), Error: err}
+		}
+	}
+	for j, i := range indexes {
+		results[i] = scored[j]
+	}
+	return results
+}
@@ -0,0 +1,51 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateTask(t *testing.T) {
+	contains := NewMetricFunc("mentions_paris", func(ctx context.Context, input MetricInput) *ScoreResult {
+		if strings.Contains(input.Output, "Paris") {
+			return NewScoreResult("mentions_paris", 1)
+		}
+		return NewScoreResult("mentions_paris", 0)
+	})
+	engine := NewEngine([]Metric{contains}, WithConcurrency(2))
+	evaluator := NewDatasetEvaluator(engine, DefaultInputMapper("question", "output", "expected"))
+
+	task := func(ctx context.Context, item map[string]any) (map[string]any, error) {
+		switch item["question"] {
+		case "capital of France?":
+			return map[string]any{"output": "Paris"}, nil
+		case "broken":
+			return nil, errors.New("model unavailable")
+		}
+		return map[string]any{"output": "no idea"}, nil
+	}
+	items := []map[string]any{
+		{"question": "capital of France?"},
+		{"question": "broken"},
+		{"question": "capital of Spain?"},
+	}
+	results := evaluator.EvaluateTask(context.Background(), items, task)
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if results[0].Input.Output != "Paris" || results[0].AverageScore() != 1 {
+		t.Errorf("result 0 = %+v, want Paris scored 1", results[0])
+	}
+	if results[1].Error == nil || !strings.Contains(results[1].Error.Error(), "model unavailable") {
+		t.Errorf("result 1 error = %v, want the task error", results[1].Error)
+	}
+	if results[2].Input.Input != "capital of Spain?" || results[2].AverageScore() != 0 {
+		t.Errorf("result 2 = %+v, want scored 0", results[2])
+	}
+	if _, ok := items[0]["output"]; ok {
+		t.Error("EvaluateTask modified the dataset items")
+	}
+}
@@ -0,0 +1,233 @@
+package evaluation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// TaskCache stores task outputs between runs. Keys come from TaskCacheKey.
+// Implementations must be safe for concurrent use.
+type TaskCache interface {
+	// Get returns the output stored under key, if any.
+	Get(key string) (map[string]any, bool)
+	// Put stores output under key.
+	Put(key string, output map[string]any) error
+}
+
+// TaskVersion hashes whatever determines a task's behavior, such as its
+// prompt template, model, and a code version, into a short version string
+// for NewCachedTask. Changing any part changes the version, so every item
+// is run again. It returns an error if a part cannot be encoded as JSON,
+// such as a func or NaN, since it would not change the version.
+func TaskVersion(parts ...any) (string, error) {
+	data, err := json.Marshal(parts)
+	if err != nil {
+		return "", fmt.Errorf("task version: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// TaskCacheKey returns the cache key of a dataset item for a task version:
+// a hash of the version and the item's content. Items whose content has
+// changed get a new key, so only they are run again. It returns an error if
+// the item cannot be encoded as JSON, since items differing only in such
+// values would share a key.
+func TaskCacheKey(item map[string]any, version string) (string, error) {
+	// encoding/json sorts map keys, so equal items hash equally.
+	data, err := json.Marshal(item)
+	if err != nil {
+		return "", fmt.Errorf("task cache key: %w", err)
+	}
+	h := sha256.New()
+	h.Write([]byte(version))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CachedTask runs a task through a cache, so re-running an experiment on
+// the same dataset with an unchanged task version reuses the stored
+// outputs, and only items that are new or have changed are run. Failed
+// runs are not cached, and neither are items that TaskCacheKey cannot hash,
+// which are run every time. It is safe for concurrent use.
+type CachedTask struct {
+	task    Task
+	cache   TaskCache
+	version string
+
+	mu    sync.Mutex
+	stats TaskCacheStats
+}
+
+// TaskCacheStats counts the runs of a CachedTask.
+type TaskCacheStats struct {
+	// Hits is the number of items answered from the cache.
+	Hits int
+	// Misses is the number of items the task was run for.
+	Misses int
+	// PutErrors is the number of outputs that could not be stored.
+	PutErrors int
+	// Uncached is the number of items run without the cache because
+	// TaskCacheKey could not hash them.
+	Uncached int
+}
+
+// NewCachedTask wraps task with cache for the given task version, as
+// returned by TaskVersion.
+func NewCachedTask(task Task, cache TaskCache, version string) *CachedTask {
+	return &CachedTask{task: task, cache: cache, version: version}
+}
+
+// Run returns the cached output for item, or runs the task and caches its
+// output. A failure to store the output is counted in Stats but does not
+// fail the run.
+func (t *CachedTask) Run(ctx context.Context, item map[string]any) (map[string]any, error) {
+	key, err := TaskCacheKey(item, t.version)
+	if err != nil {
+		t.count(func(s *TaskCacheStats) { s.Uncached++ })
+		return t.task(ctx, item)
+	}
+	if output, ok := t.cache.Get(key); ok {
+		t.count(func(s *TaskCacheStats) { s.Hits++ })
+		return output, nil
+	}
+	t.count(func(s *TaskCacheStats) { s.Misses++ })
+	output, err := t.task(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.cache.Put(key, output); err != nil {
+		t.count(func(s *TaskCacheStats) { s.PutErrors++ })
+	}
+	return output, nil
+}
+
+// Task returns t.Run as a Task.
+func (t *CachedTask) Task() Task {
+	return t.Run
+}
+
+// Stats returns the hits and misses so far.
+func (t *CachedTask) Stats() TaskCacheStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+func (t *CachedTask) count(fn func(*TaskCacheStats)) {
+	t.mu.Lock()
+	fn(&t.stats)
+	t.mu.Unlock()
+}
+
+// MemoryTaskCache is a TaskCache held in memory, for repeated runs within
+// one process.
+type MemoryTaskCache struct {
+	mu      sync.RWMutex
+	outputs map[string]map[string]any
+}
+
+// NewMemoryTaskCache creates an empty in-memory cache.
+func NewMemoryTaskCache() *MemoryTaskCache {
+	return &MemoryTaskCache{outputs: make(map[string]map[string]any)}
+}
+
+// Get implements TaskCache.
+func (c *MemoryTaskCache) Get(key string) (map[string]any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	output, ok := c.outputs[key]
+	return maps.Clone(output), ok
+}
+
+// Put implements TaskCache.
+func (c *MemoryTaskCache) Put(key string, output map[string]any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outputs[key] = maps.Clone(output)
+	return nil
+}
+
+// FileTaskCache is a TaskCache that stores each output as a JSON file in a
+// directory, so outputs survive between runs of a prompt development loop.
+// Outputs are read back as decoded JSON, so numbers become float64.
+type FileTaskCache struct {
+	dir string
+}
+
+// NewFileTaskCache creates a cache in dir, creating the directory if needed.
+func NewFileTaskCache(dir string) (*FileTaskCache, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create task cache: %w", err)
+	}
+	return &FileTaskCache{dir: dir}, nil
+}
+
+// Get implements TaskCache. Unreadable entries are treated as missing.
+func (c *FileTaskCache) Get(key string) (map[string]any, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var output map[string]any
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, false
+	}
+	return output, true
+}
+
+// Put implements TaskCache. The file is written under a temporary name and
+// renamed, so concurrent runs never read a partial entry.
+func (c *FileTaskCache) Put(key string, output map[string]any) error {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("encode task output: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Clear removes every cached output.
+func (c *FileTaskCache) Clear() error {
+	entries, err := os.ReadDir(c.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".json" {
+			if err := os.Remove(filepath.Join(c.dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *FileTaskCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
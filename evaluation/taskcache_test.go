@@ -0,0 +1,133 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCachedTaskReusesOutputs(t *testing.T) {
+	ctx := context.Background()
+	var runs atomic.Int32
+	task := func(ctx context.Context, item map[string]any) (map[string]any, error) {
+		runs.Add(1)
+		if item["q"] == "fail" {
+			return nil, errors.New("boom")
+		}
+		return map[string]any{"output": "answer to " + item["q"].(string)}, nil
+	}
+	cache := NewMemoryTaskCache()
+	version, err := TaskVersion("prompt v1", "gpt-4o")
+	if err != nil {
+		t.Fatalf("TaskVersion error: %v", err)
+	}
+
+	cached := NewCachedTask(task, cache, version)
+	for _, q := range []string{"a", "b", "fail"} {
+		_, _ = cached.Run(ctx, map[string]any{"q": q})
+	}
+
+	// A second run with one changed item only runs that item, and the
+	// failed item, which was not cached.
+	again := NewCachedTask(task, cache, version)
+	runs.Store(0)
+	for _, q := range []string{"a", "b changed", "fail"} {
+		_, _ = again.Run(ctx, map[string]any{"q": q})
+	}
+	if got := runs.Load(); got != 2 {
+		t.Errorf("task ran %d times, want 2", got)
+	}
+	if got := again.Stats(); got != (TaskCacheStats{Hits: 1, Misses: 2}) {
+		t.Errorf("Stats = %+v, want 1 hit and 2 misses", got)
+	}
+	output, err := again.Run(ctx, map[string]any{"q": "a"})
+	if err != nil || output["output"] != "answer to a" {
+		t.Errorf("Run = %v, %v, want the cached output", output, err)
+	}
+
+	// A new task version runs every item again.
+	runs.Store(0)
+	version2, _ := TaskVersion("prompt v2", "gpt-4o")
+	v2 := NewCachedTask(task, cache, version2)
+	_, _ = v2.Run(ctx, map[string]any{"q": "a"})
+	if runs.Load() != 1 {
+		t.Error("new task version reused an output of the old version")
+	}
+
+	// Items that cannot be hashed are run every time rather than sharing a key.
+	runs.Store(0)
+	uncached := NewCachedTask(task, cache, version)
+	for _, extra := range []any{func() {}, make(chan int)} {
+		_, _ = uncached.Run(ctx, map[string]any{"q": "a", "extra": extra})
+	}
+	if got := uncached.Stats(); runs.Load() != 2 || got != (TaskCacheStats{Uncached: 2}) {
+		t.Errorf("task ran %d times with Stats = %+v, want 2 uncached runs", runs.Load(), got)
+	}
+}
+
+func TestTaskCacheKey(t *testing.T) {
+	key := func(item map[string]any, version string) string {
+		t.Helper()
+		k, err := TaskCacheKey(item, version)
+		if err != nil {
+			t.Fatalf("TaskCacheKey error: %v", err)
+		}
+		return k
+	}
+	a := key(map[string]any{"x": 1, "y": "z"}, "v1")
+	if b := key(map[string]any{"y": "z", "x": 1}, "v1"); a != b {
+		t.Error("equal items have different keys")
+	}
+	if b := key(map[string]any{"x": 2, "y": "z"}, "v1"); a == b {
+		t.Error("changed item kept its key")
+	}
+	if b := key(map[string]any{"x": 1, "y": "z"}, "v2"); a == b {
+		t.Error("changed version kept the key")
+	}
+
+	if _, err := TaskCacheKey(map[string]any{"x": math.NaN()}, "v1"); err == nil {
+		t.Error("TaskCacheKey of an item with NaN succeeded")
+	}
+	if _, err := TaskVersion("prompt", func() {}); err == nil {
+		t.Error("TaskVersion with a func succeeded")
+	}
+}
+
+func TestFileTaskCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	cache, err := NewFileTaskCache(dir)
+	if err != nil {
+		t.Fatalf("NewFileTaskCache error: %v", err)
+	}
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("Get found a missing key")
+	}
+	if err := cache.Put("k", map[string]any{"output": "hi", "tokens": 3}); err != nil {
+		t.Fatalf("Put error: %v", err)
+	}
+
+	// A new cache on the same directory sees the output.
+	reopened, _ := NewFileTaskCache(dir)
+	output, ok := reopened.Get("k")
+	if !ok || output["output"] != "hi" || output["tokens"] != float64(3) {
+		t.Errorf("Get = %v, %v, want the stored output", output, ok)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("bad"); ok {
+		t.Error("Get returned a corrupt entry")
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("Clear error: %v", err)
+	}
+	if _, ok := cache.Get("k"); ok {
+		t.Error("Get found an output after Clear")
+	}
+}
@@ -0,0 +1,211 @@
+// Package textnorm normalizes text for comparison and scoring: it strips
+// Markdown and HTML formatting, collapses whitespace, folds Unicode
+// variants and typographic punctuation, splits text into sentences, and
+// truncates it on character boundaries.
+//
+// The evaluation preprocessors and metrics use these functions, and
+// applications can use them to prepare their own outputs the same way:
+//
+//	answer := textnorm.CollapseWhitespace(textnorm.StripMarkdown(output))
+//	for _, sentence := range textnorm.Sentences(answer) {
+//	    ...
+//	}
+package textnorm
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var quoteReplacer = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'",
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`,
+	"–", "-", "—", "-", "−", "-",
+	" ", " ", "…", "...",
+)
+
+// NormalizeQuotes replaces typographic quotes, dashes, ellipses, and
+// non-breaking spaces with their ASCII equivalents.
+func NormalizeQuotes(text string) string {
+	return quoteReplacer.Replace(text)
+}
+
+// NormalizeUnicode applies NFKC normalization, which also folds full-width
+// characters and ligatures, and then NormalizeQuotes.
+func NormalizeUnicode(text string) string {
+	return NormalizeQuotes(norm.NFKC.String(text))
+}
+
+// CollapseWhitespace replaces each run of whitespace, including line
+// breaks, with a single space and trims the ends.
+func CollapseWhitespace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+var codeBlockPattern = regexp.MustCompile("(?s)```[^\\n`]*\\n?(.*?)```")
+
+// ExtractCodeBlock returns the contents of the first fenced code block, or
+// the text unchanged if it contains none.
+func ExtractCodeBlock(text string) string {
+	if m := codeBlockPattern.FindStringSubmatch(text); m != nil {
+		return strings.TrimRight(m[1], "\n")
+	}
+	return text
+}
+
+var markdownRules = []struct {
+	pattern *regexp.Regexp
+	repl    string
+}{
+	{regexp.MustCompile("(?m)^```[^\\n]*\\n?"), ""},                      // code fences
+	{regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`), "$1"},                 // images
+	{regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`), "$1"},                  // links
+	{regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`), ""},                    // headings
+	{regexp.MustCompile(`(?m)^\s{0,3}>\s?`), ""},                         // blockquotes
+	{regexp.MustCompile(`(?m)^(\s*)(?:[-*+]|\d+[.)])\s+`), "$1"},         // list markers
+	{regexp.MustCompile(`(?m)^\s*(?:-{3,}|\*{3,}|_{3,})\s*$`), ""},       // rules
+	{regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`), "$2"},       // bold
+	{regexp.MustCompile(`(^|\W)[*_](\S(?:.*?\S)?)[*_](\W|$)`), "$1$2$3"}, // emphasis
+	{regexp.MustCompile("`([^`]*)`"), "$1"},                              // inline code
+	{regexp.MustCompile(`~~(.*?)~~`), "$1"},                              // strikethrough
+}
+
+// StripMarkdown removes Markdown formatting, keeping the text content and
+// line breaks. Link and image text is kept without the URL.
+func StripMarkdown(text string) string {
+	for _, r := range markdownRules {
+		text = r.pattern.ReplaceAllString(text, r.repl)
+	}
+	return text
+}
+
+var (
+	htmlSkipPattern  = regexp.MustCompile(`(?is)<!--.*?-->|<(script|style)\b[^>]*>.*?</(?:script|style)\s*>`)
+	htmlBlockPattern = regexp.MustCompile(`(?i)</?(?:address|article|aside|blockquote|br|dd|div|dl|dt|figcaption|figure|footer|h[1-6]|header|hr|li|main|nav|ol|p|pre|section|table|td|th|tr|ul)\b[^>]*>`)
+	htmlTagPattern   = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	blankLinePattern = regexp.MustCompile(`[ \t]*\n[\s]*`)
+)
+
+// StripHTML removes HTML tags, comments, scripts, and styles, and decodes
+// character references, keeping the text content. Block elements such as
+// paragraphs, list items, and line breaks become line breaks, so the text
+// keeps its lines.
+func StripHTML(text string) string {
+	text = htmlSkipPattern.ReplaceAllString(text, "")
+	text = htmlBlockPattern.ReplaceAllString(text, "\n")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	return strings.TrimSpace(blankLinePattern.ReplaceAllString(text, "\n"))
+}
+
+// abbreviations are words followed by a period that do not end a sentence.
+var abbreviations = map[string]bool{
+	"dr": true, "e.g": true, "etc": true, "fig": true, "i.e": true, "inc": true,
+	"jr": true, "ltd": true, "mr": true, "mrs": true, "ms": true, "no": true,
+	"prof": true, "sr": true, "st": true, "vs": true,
+}
+
+// Sentences splits text into sentences, trimmed of surrounding whitespace.
+// A sentence ends at a period, question mark, exclamation mark, or
+// ellipsis followed by whitespace, and at a blank line. Periods in
+// numbers, in common abbreviations such as "e.g." and "Dr.", and after
+// single-letter initials do not end a sentence, nor does a terminator
+// followed by a lowercase word.
+func Sentences(text string) []string {
+	var sentences []string
+	add := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case r == '\n' && isBlankLine(text[i+size:]):
+			add(text[start:i])
+			start = i + size
+		case isTerminator(r):
+			end := i + size
+			for end < len(text) {
+				next, n := utf8.DecodeRuneInString(text[end:])
+				if !isTerminator(next) && !isCloser(next) {
+					break
+				}
+				end += n
+			}
+			if endsSentence(text[start:i], r, text[end:]) {
+				add(text[start:end])
+				start = end
+			}
+			i = end
+			continue
+		}
+		i += size
+	}
+	add(text[start:])
+	return sentences
+}
+
+func isTerminator(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+func isCloser(r rune) bool {
+	return r == '"' || r == '\'' || r == ')' || r == ']' || r == '”' || r == '’'
+}
+
+// isBlankLine reports whether rest starts with a line holding only
+// whitespace, so the line break before it ends a paragraph.
+func isBlankLine(rest string) bool {
+	line, _, found := strings.Cut(rest, "\n")
+	return found && strings.TrimSpace(line) == ""
+}
+
+// endsSentence reports whether the terminator r, preceded by before and
+// followed by after, ends a sentence.
+func endsSentence(before string, r rune, after string) bool {
+	next, _ := utf8.DecodeRuneInString(after)
+	if after != "" && !unicode.IsSpace(next) {
+		return false // "3.14", "example.com"
+	}
+	if word := strings.TrimLeft(strings.TrimSpace(after), "\"'([“‘"); word != "" {
+		if first, _ := utf8.DecodeRuneInString(word); unicode.IsLower(first) {
+			return false
+		}
+	}
+	if r != '.' {
+		return true
+	}
+	fields := strings.Fields(before)
+	if len(fields) == 0 {
+		return true
+	}
+	last := strings.TrimLeft(fields[len(fields)-1], "\"'([“‘")
+	if utf8.RuneCountInString(last) == 1 && unicode.IsUpper([]rune(last)[0]) {
+		return false // an initial, as in "J. Smith"
+	}
+	return !abbreviations[strings.ToLower(last)]
+}
+
+// Truncate shortens text to at most maxBytes bytes, ending it with "..."
+// when it is cut. It never cuts a multi-byte character in half.
+func Truncate(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	if maxBytes <= 3 {
+		return "..."[:max(maxBytes, 0)]
+	}
+	cut := maxBytes - 3
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "..."
+}
@@ -0,0 +1,104 @@
+package textnorm
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{"quotes", NormalizeQuotes, "“it’s” — ok…", `"it's" - ok...`},
+		{"quotes keep width", NormalizeQuotes, "ｆｕｌｌ", "ｆｕｌｌ"},
+		{"unicode width", NormalizeUnicode, "ｆｕｌｌ width", "full width"},
+		{"unicode ligature", NormalizeUnicode, "ﬁne “print”", `fine "print"`},
+		{"collapse", CollapseWhitespace, "  a \t b\n\n c  ", "a b c"},
+		{"code block", ExtractCodeBlock, "Here:\n```go\nfmt.Println(1)\n```\nDone", "fmt.Println(1)"},
+		{"no code block", ExtractCodeBlock, "plain text", "plain text"},
+		{"markdown", StripMarkdown, "## Title\n- a **bold** [link](https://x.y)", "Title\na bold link"},
+		{"markdown keeps snake_case", StripMarkdown, "use my_var_name", "use my_var_name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.in); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"inline tags", `<p>Paris is the <b>capital</b> of <a href="/fr">France</a>.</p>`, "Paris is the capital of France."},
+		{"blocks become lines", "<h1>Title</h1><ul><li>one</li><li>two</li></ul>text<br/>more", "Title\none\ntwo\ntext\nmore"},
+		{"entities", "Tom &amp; Jerry &lt;3 &quot;cheese&quot; &#8212; yes", `Tom & Jerry <3 "cheese" — yes`},
+		{"scripts and comments", "<style>p{}</style>a<!-- hidden --><script>alert(1)</script>b", "ab"},
+		{"plain text", "2 < 3 and 5 > 4", "2 < 3 and 5 > 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripHTML(tt.in); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSentences(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"terminators", "It works. Does it? Yes!  Great…", []string{"It works.", "Does it?", "Yes!", "Great…"}},
+		{"numbers and domains", "Pi is 3.14 today. See example.com for more.", []string{"Pi is 3.14 today.", "See example.com for more."}},
+		{"abbreviations", "Dr. Smith met Mr. J. Jones, e.g. at noon. They left.", []string{"Dr. Smith met Mr. J. Jones, e.g. at noon.", "They left."}},
+		{"quotes", `He said "stop." Then he left.`, []string{`He said "stop."`, "Then he left."}},
+		{"repeated terminators", "Really?! Yes.", []string{"Really?!", "Yes."}},
+		{"lowercase continuation", "The value is approx. ten units.", []string{"The value is approx. ten units."}},
+		{"paragraphs", "Title\n\nFirst line\nsame sentence", []string{"Title", "First line\nsame sentence"}},
+		{"no terminator", "just words", []string{"just words"}},
+		{"empty", "  ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sentences(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	short := "short"
+	if Truncate(short, 10) != short {
+		t.Error("short string should not be truncated")
+	}
+
+	long := "this is a very long string that should be truncated"
+	result := Truncate(long, 20)
+	if len(result) != 20 {
+		t.Errorf("length = %d, want 20", len(result))
+	}
+	if result[len(result)-3:] != "..." {
+		t.Error("truncated string should end with ...")
+	}
+
+	// Multi-byte characters are not cut in half.
+	result = Truncate("héllo wörld", 8)
+	if !utf8.ValidString(result) || len(result) > 8 {
+		t.Errorf("Truncate = %q, want valid UTF-8 of at most 8 bytes", result)
+	}
+	if got := Truncate("abcdef", 2); got != ".." {
+		t.Errorf("Truncate to 2 bytes = %q", got)
+	}
+}
@@ -0,0 +1,60 @@
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+)
+
+// Words splits text into words. Words are separated by whitespace, except
+// in Chinese and Japanese text, which is written without spaces: each Han,
+// Hiragana, or Katakana character counts as a word of its own, so word
+// counts and word overlap work for CJK text. Korean is written with spaces
+// and is split on them.
+//
+// Punctuation split off by a CJK character, such as "。", is not a word.
+func Words(text string) []string {
+	var words []string
+	for _, field := range strings.Fields(text) {
+		if !strings.ContainsFunc(field, isCJK) {
+			words = append(words, field)
+			continue
+		}
+		start := 0
+		for i, r := range field {
+			if !isCJK(r) {
+				continue
+			}
+			words = appendWord(words, field[start:i])
+			end := i + len(string(r))
+			words = append(words, field[i:end])
+			start = end
+		}
+		words = appendWord(words, field[start:])
+	}
+	return words
+}
+
+// appendWord appends a part of a field split by CJK characters, unless it
+// holds no letters or digits.
+func appendWord(words []string, part string) []string {
+	if strings.IndexFunc(part, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return words
+	}
+	return append(words, part)
+}
+
+// isCJK reports whether r is a Chinese or Japanese character that forms a
+// word by itself.
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r)
+}
+
+// FoldCase applies Unicode case folding, for comparing text without
+// regard to case. Unlike strings.ToLower it folds characters without a
+// one-to-one lowercase form, so "Straße" and "STRASSE" fold to the same
+// text, as do the final and medial forms of Greek sigma.
+func FoldCase(text string) string {
+	return cases.Fold().String(text)
+}
@@ -0,0 +1,46 @@
+package textnorm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"spaces", "  the quick\tbrown\nfox ", []string{"the", "quick", "brown", "fox"}},
+		{"japanese", "東京は大きい。", []string{"東", "京", "は", "大", "き", "い"}},
+		{"katakana", "コーヒー", []string{"コ", "ー", "ヒ", "ー"}},
+		{"mixed", "Go言語 rocks", []string{"Go", "言", "語", "rocks"}},
+		{"number before han", "3月です", []string{"3", "月", "で", "す"}},
+		{"korean", "안녕하세요 세계", []string{"안녕하세요", "세계"}},
+		{"punctuation outside cjk", "hello, world!", []string{"hello,", "world!"}},
+		{"empty", " ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Words(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoldCase(t *testing.T) {
+	pairs := [][2]string{
+		{"Straße", "STRASSE"},
+		{"ΟΔΟΣ", "οδος"},
+		{"Hello", "hELLO"},
+	}
+	for _, p := range pairs {
+		if FoldCase(p[0]) != FoldCase(p[1]) {
+			t.Errorf("FoldCase(%q) = %q, FoldCase(%q) = %q", p[0], FoldCase(p[0]), p[1], FoldCase(p[1]))
+		}
+	}
+	if FoldCase("Apple") == FoldCase("apples") {
+		t.Error("different words should not fold to the same text")
+	}
+}
@@ -0,0 +1,149 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Validator is implemented by metrics that can check their own
+// configuration, such as a range whose minimum is above its maximum.
+// Engine.Validate calls it for each metric.
+type Validator interface {
+	// Validate returns an error describing every problem found, or nil.
+	Validate() error
+}
+
+// ValidationError lists every problem found in a configuration, so they
+// can all be fixed at once rather than one per attempt. The evaluation
+// engine and its metrics report it, and so does the opik client for its
+// options.
+type ValidationError struct {
+	// Prefix starts the message, such as "evaluation: invalid
+	// configuration". The package name it starts with is dropped from the
+	// problems, so it is not repeated. Empty means "invalid configuration".
+	Prefix string
+	// Problems are the individual errors, in the order they were found.
+	Problems []error
+	// Kind, if not nil, is matched by errors.Is, such as
+	// opik.ErrInvalidInput.
+	Kind error
+}
+
+func (e *ValidationError) Error() string {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = "invalid configuration"
+	}
+	pkg, _, ok := strings.Cut(prefix, ": ")
+	problem := func(err error) string {
+		if ok {
+			return strings.TrimPrefix(err.Error(), pkg+": ")
+		}
+		return err.Error()
+	}
+	if len(e.Problems) == 1 {
+		return prefix + ": " + problem(e.Problems[0])
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%d problems):", prefix, len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem(p))
+	}
+	return b.String()
+}
+
+// Unwrap returns the problems, so errors.Is and errors.As look at each.
+func (e *ValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+// Is reports whether target is the error's Kind.
+func (e *ValidationError) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+// Problems collects validation errors. Metrics implementing Validator can
+// use it to report all of their problems together:
+//
+//	func (m *MyMetric) Validate() error {
+//		var p evaluation.Problems
+//		if m.min > m.max {
+//			p.Addf("min %d is above max %d", m.min, m.max)
+//		}
+//		return p.Err()
+//	}
+type Problems []error
+
+// Addf records a problem.
+func (p *Problems) Addf(format string, args ...any) {
+	*p = append(*p, fmt.Errorf(format, args...))
+}
+
+// Add records err, prefixed with prefix if it is not empty. The problems of
+// a ValidationError are recorded one by one. A nil err is ignored.
+func (p *Problems) Add(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	errs := []error{err}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		errs = verr.Problems
+	}
+	for _, err := range errs {
+		if prefix != "" {
+			err = fmt.Errorf("%s: %w", prefix, err)
+		}
+		*p = append(*p, err)
+	}
+}
+
+// Err returns a ValidationError holding the problems, prefixed with
+// "evaluation: invalid configuration", or nil if there are none.
+func (p Problems) Err() error {
+	return p.ErrWithPrefix("evaluation: invalid configuration", nil)
+}
+
+// ErrWithPrefix returns a ValidationError holding the problems, with the
+// given Prefix and Kind, or nil if there are none.
+func (p Problems) ErrWithPrefix(prefix string, kind error) error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ValidationError{Prefix: prefix, Problems: p, Kind: kind}
+}
+
+// Validate checks the engine's options and metrics, and returns a
+// ValidationError listing every problem found: invalid option values, nil
+// or unnamed metrics, metrics sharing a name, whose scores could not be
+// told apart, and the problems reported by metrics implementing Validator.
+//
+// NewEngine does not fail, so call Validate after building an engine from
+// values that were not checked already, such as user configuration.
+func (e *Engine) Validate() error {
+	p := append(Problems(nil), e.problems...)
+	if len(e.metrics) == 0 {
+		p.Addf("engine has no metrics")
+	}
+	seen := make(map[string]int, len(e.metrics))
+	for i, metric := range e.metrics {
+		if metric == nil {
+			p.Addf("metrics[%d] is nil", i)
+			continue
+		}
+		name := metric.Name()
+		if name == "" {
+			p.Addf("metrics[%d] has no name", i)
+		} else if j, ok := seen[name]; ok {
+			p.Addf("metrics[%d] and metrics[%d] are both named %q", j, i, name)
+		} else {
+			seen[name] = i
+		}
+		if v, ok := metric.(Validator); ok {
+			p.Add(fmt.Sprintf("metric %q", name), v.Validate())
+		}
+	}
+	return p.Err()
+}
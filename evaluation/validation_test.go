@@ -0,0 +1,112 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type rangeMetric struct {
+	BaseMetric
+	min, max int
+}
+
+func (m *rangeMetric) Score(ctx context.Context, input MetricInput) *ScoreResult {
+	return NewScoreResult(m.Name(), 1)
+}
+
+func (m *rangeMetric) Validate() error {
+	var p Problems
+	if m.min < 0 {
+		p.Addf("min must not be negative: %d", m.min)
+	}
+	if m.max < m.min {
+		p.Addf("max %d is below min %d", m.max, m.min)
+	}
+	return p.Err()
+}
+
+func TestEngineValidate(t *testing.T) {
+	noop := func(ctx context.Context, input MetricInput) *ScoreResult { return nil }
+	engine := NewEngine([]Metric{
+		NewMetricFunc("exact", noop),
+		nil,
+		NewMetricFunc("", noop),
+		NewMetricFunc("exact", noop),
+		&rangeMetric{BaseMetric: NewBaseMetric("range"), min: -1, max: -2},
+		NewWeightedMetric(nil, -1),
+	}, WithConcurrency(-2), WithCallback(nil))
+
+	err := engine.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate error = %v, want *ValidationError", err)
+	}
+	want := []string{
+		"concurrency must be positive: -2",
+		"WithCallback: callback is nil",
+		"metrics[1] is nil",
+		"metrics[2] has no name",
+		`metrics[0] and metrics[3] are both named "exact"`,
+		`metric "range": min must not be negative: -1`,
+		`metric "range": max -2 is below min -1`,
+		"metrics[5] has no name",
+		`metric "": weight must not be negative: -1`,
+		`metric "": metric is nil`,
+	}
+	if len(verr.Problems) != len(want) {
+		t.Fatalf("got %d problems, want %d:\n%v", len(verr.Problems), len(want), err)
+	}
+	for i, w := range want {
+		if got := verr.Problems[i].Error(); got != w {
+			t.Errorf("problem %d = %q, want %q", i, got, w)
+		}
+	}
+	if !strings.HasPrefix(err.Error(), "evaluation: invalid configuration (10 problems):\n  - concurrency") {
+		t.Errorf("error = %q", err.Error())
+	}
+}
+
+func TestEngineValidateOK(t *testing.T) {
+	engine := NewEngine([]Metric{
+		NewMetricFunc("a", func(ctx context.Context, input MetricInput) *ScoreResult { return nil }),
+		NewCompositeMetric("b", &rangeMetric{BaseMetric: NewBaseMetric("range"), max: 1}),
+	}, WithConcurrency(2))
+	if err := engine.Validate(); err != nil {
+		t.Errorf("Validate error = %v, want nil", err)
+	}
+}
+
+func TestCompositeMetricValidate(t *testing.T) {
+	err := NewCompositeMetric("c", &rangeMetric{BaseMetric: NewBaseMetric("range"), max: -1}, nil).Validate()
+	if err == nil || err.Error() != "evaluation: invalid configuration (2 problems):\n  - metric \"range\": max -1 is below min 0\n  - metrics[1] is nil" {
+		t.Errorf("Validate error = %v", err)
+	}
+	if err := NewCompositeMetric("empty").Validate(); err == nil {
+		t.Error("empty composite validated")
+	}
+}
+
+func TestProblemsErrWithPrefix(t *testing.T) {
+	errKind := errors.New("invalid input")
+	var p Problems
+	p.Addf("app: timeout must not be negative: %d", -1)
+	err := p.ErrWithPrefix("app: invalid options", errKind)
+	if want := "app: invalid options: timeout must not be negative: -1"; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+	if !errors.Is(err, errKind) {
+		t.Errorf("errors.Is(err, kind) = false, want true")
+	}
+	if errors.Is(p.Err(), errKind) {
+		t.Errorf("errors.Is(Err(), kind) = true, want false")
+	}
+	p.Addf("no name")
+	if want := "invalid configuration (2 problems):\n  - app: timeout must not be negative: -1\n  - no name"; p.ErrWithPrefix("", nil).Error() != want {
+		t.Errorf("error = %q, want %q", p.ErrWithPrefix("", nil).Error(), want)
+	}
+	if err := Problems(nil).ErrWithPrefix("app: invalid options", errKind); err != nil {
+		t.Errorf("ErrWithPrefix with no problems = %v, want nil", err)
+	}
+}
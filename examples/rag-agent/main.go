@@ -0,0 +1,211 @@
+// Command rag-agent is a small retrieval-augmented question answering agent
+// that exercises the SDK end to end against an Opik server: tracing,
+// streaming spans, attachments, dataset creation, experiments, and
+// evaluation. It answers from a built-in document set, so it needs no LLM
+// provider.
+//
+// Run it against a local Opik (see https://www.comet.com/docs/opik/self-host/local_deployment):
+//
+//	go run ./examples/rag-agent
+//
+// The same flow runs as an end-to-end test with:
+//
+//	go test -tags=e2e ./examples/rag-agent
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/heuristic"
+	"github.com/plexusone/opik-go/evaluation/textnorm"
+)
+
+// documents is the agent's knowledge base.
+var documents = []string{
+	"Opik is an open-source platform for tracing, evaluating, and monitoring LLM applications.",
+	"A trace records one request to an LLM application, and spans record the steps within it.",
+	"Datasets hold the inputs and expected outputs that experiments evaluate an application on.",
+	"An experiment runs an application on every item of a dataset and scores the outputs with metrics.",
+	"Streaming spans record each chunk of a streamed response and the time to the first chunk.",
+}
+
+// questions are the dataset items the agent is evaluated on.
+var questions = []map[string]any{
+	{"input": "What does a trace record?", "expected": "one request"},
+	{"input": "What do datasets hold?", "expected": "expected outputs"},
+	{"input": "What does an experiment run?", "expected": "every item of a dataset"},
+}
+
+func main() {
+	project := flag.String("project", "go-sdk-rag-agent", "Opik project to log to")
+	keep := flag.Bool("keep", false, "keep the dataset created for the run")
+	flag.Parse()
+
+	client, err := opik.NewClient(opik.WithProjectName(*project))
+	if err != nil {
+		log.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+	defer func() { _ = client.Close(ctx) }()
+
+	report, err := run(ctx, client, fmt.Sprintf("rag-agent-%d", time.Now().Unix()))
+	if err != nil {
+		log.Fatalf("Run failed: %v", err)
+	}
+	if !*keep {
+		defer func() { _ = report.Dataset.Delete(ctx) }()
+	}
+
+	fmt.Printf("Answer: %s\n", report.Answer)
+	fmt.Printf("Trace: %s\n", report.TraceID)
+	fmt.Printf("Experiment: %s (%d items, %d scores)\n",
+		report.Experiment.Experiment.Name(), len(report.Experiment.Results), report.Experiment.Scores)
+	summary := report.Experiment.Results.Summary()
+	for _, name := range slices.Sorted(maps.Keys(summary)) {
+		fmt.Printf("  %s: %.2f\n", name, summary[name])
+	}
+}
+
+// report is what a run created.
+type report struct {
+	Answer     string
+	TraceID    string
+	Dataset    *opik.Dataset
+	Experiment *opik.ExperimentRunResult
+}
+
+// run answers one question in a trace of its own, then creates a dataset
+// named name and evaluates the agent on it in an experiment. It flushes the
+// client before returning, so everything it created can be read back.
+func run(ctx context.Context, client *opik.Client, name string) (*report, error) {
+	question := "What do spans record?"
+	traceCtx, trace, err := opik.StartTrace(ctx, client, "rag-agent",
+		opik.WithTraceInput(map[string]any{"question": question}),
+		opik.WithTraceTags("example", "rag"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("start trace: %w", err)
+	}
+	answer, err := answerQuestion(traceCtx, question)
+	if err != nil {
+		return nil, err
+	}
+	if err := trace.End(traceCtx, opik.WithTraceOutput(map[string]any{"answer": answer})); err != nil {
+		return nil, fmt.Errorf("end trace: %w", err)
+	}
+
+	dataset, err := client.CreateDataset(ctx, name, opik.WithDatasetDescription("Questions for the rag-agent example"))
+	if err != nil {
+		return nil, fmt.Errorf("create dataset: %w", err)
+	}
+	if err := dataset.InsertItems(ctx, questions); err != nil {
+		return nil, fmt.Errorf("insert dataset items: %w", err)
+	}
+
+	task := func(ctx context.Context, item map[string]any) (map[string]any, error) {
+		question, _ := item["input"].(string)
+		answer, err := answerQuestion(ctx, question)
+		return map[string]any{"output": answer}, err
+	}
+	metrics := []evaluation.Metric{
+		heuristic.NewContains(false),
+		heuristic.NewNotEmpty(),
+	}
+	result, err := opik.RunExperiment(ctx, client, dataset, task, metrics,
+		opik.WithRunExperimentOptions(opik.WithExperimentName(name)),
+		opik.WithRunTraceName("rag-agent"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("run experiment: %w", err)
+	}
+
+	if err := client.Flush(ctx); err != nil {
+		return nil, fmt.Errorf("flush: %w", err)
+	}
+	return &report{Answer: answer, TraceID: trace.ID(), Dataset: dataset, Experiment: result}, nil
+}
+
+// answerQuestion retrieves the documents relevant to question and streams
+// an answer from them, recording each step as a span of the trace in ctx.
+func answerQuestion(ctx context.Context, question string) (string, error) {
+	retrieveCtx, span, err := opik.StartSpan(ctx, "retrieve",
+		opik.WithSpanType(opik.SpanTypeTool),
+		opik.WithSpanInput(map[string]any{"query": question}),
+	)
+	if err != nil {
+		return "", fmt.Errorf("start retrieval span: %w", err)
+	}
+	docs := retrieve(question, 2)
+	attachment := opik.NewTextAttachment("context.txt", strings.Join(docs, "\n"))
+	if err := span.End(retrieveCtx,
+		opik.WithSpanOutput(map[string]any{"documents": docs}),
+		opik.WithSpanMetadata(map[string]any{"context": attachment.ToDataURL()}),
+	); err != nil {
+		return "", fmt.Errorf("end retrieval span: %w", err)
+	}
+
+	generateCtx, stream, err := opik.StartStreamingSpan(ctx, "generate",
+		opik.WithSpanType(opik.SpanTypeLLM),
+		opik.WithSpanModel("extractive"),
+		opik.WithSpanInput(map[string]any{"question": question, "documents": docs}),
+	)
+	if err != nil {
+		return "", fmt.Errorf("start generation span: %w", err)
+	}
+	var answer string
+	if len(docs) > 0 {
+		answer = docs[0]
+	}
+	words := strings.Fields(answer)
+	for i, word := range words {
+		var opts []opik.StreamChunkOption
+		if i == len(words)-1 {
+			opts = append(opts, opik.WithChunkFinishReason("stop"))
+		}
+		stream.AddChunk(word+" ", append(opts, opik.WithChunkTokenCount(1))...)
+	}
+	if err := stream.End(generateCtx); err != nil {
+		return "", fmt.Errorf("end generation span: %w", err)
+	}
+	return strings.TrimSpace(stream.Accumulator().Content()), nil
+}
+
+// retrieve returns up to k documents ranked by the number of words they
+// share with query.
+func retrieve(query string, k int) []string {
+	terms := make(map[string]bool)
+	for _, word := range textnorm.Words(textnorm.FoldCase(query)) {
+		terms[strings.Trim(word, "?.,!")] = true
+	}
+	type ranked struct {
+		doc   string
+		score int
+	}
+	var hits []ranked
+	for _, doc := range documents {
+		score := 0
+		for _, word := range textnorm.Words(textnorm.FoldCase(doc)) {
+			if terms[strings.Trim(word, "?.,!")] && len(word) > 3 {
+				score++
+			}
+		}
+		if score > 0 {
+			hits = append(hits, ranked{doc, score})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b ranked) int { return b.score - a.score })
+	var docs []string
+	for _, hit := range hits[:min(k, len(hits))] {
+		docs = append(docs, hit.doc)
+	}
+	return docs
+}
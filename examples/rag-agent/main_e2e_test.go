@@ -0,0 +1,111 @@
+//go:build e2e
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// TestRAGAgent runs the example against the Opik server configured in the
+// environment (by default a local Opik at http://localhost:5173) and reads
+// back what it logged. Run with:
+//
+//	make e2e
+func TestRAGAgent(t *testing.T) {
+	client, err := opik.NewClient(opik.WithProjectName("go-sdk-e2e"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	defer func() { _ = client.Close(ctx) }()
+
+	if _, err := client.ListProjects(ctx, 1, 1); err != nil {
+		t.Fatalf("Opik is not reachable at %s; start a local Opik first: %v", client.Config().URL, err)
+	}
+
+	name := fmt.Sprintf("go-sdk-e2e-%d", time.Now().UnixNano())
+	report, err := run(ctx, client, name)
+	if err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = report.Experiment.Experiment.Delete(context.Background())
+		_ = report.Dataset.Delete(context.Background())
+	})
+
+	if report.Answer == "" {
+		t.Error("agent returned an empty answer")
+	}
+	if len(report.Experiment.Failed) > 0 {
+		t.Errorf("failed items: %+v", report.Experiment.Failed)
+	}
+	if got, want := report.Experiment.Scores, 2*len(questions); got != want {
+		t.Errorf("logged %d scores, want %d", got, want)
+	}
+	summary := report.Experiment.Results.Summary()
+	if summary["contains"] != 1 || summary["not_empty"] != 1 {
+		t.Errorf("summary = %v, want every item to pass", summary)
+	}
+
+	// The server ingests asynchronously, so poll for what was logged.
+	var spans []*opik.SpanInfo
+	eventually(t, func() error {
+		if _, err := client.GetTrace(ctx, report.TraceID); err != nil {
+			return err
+		}
+		spans, err = client.ListSpans(ctx, report.TraceID, 1, 10)
+		if err != nil {
+			return err
+		}
+		if len(spans) != 2 {
+			return fmt.Errorf("got %d spans, want 2", len(spans))
+		}
+		return nil
+	})
+	for _, span := range spans {
+		if span.Name == "generate" && span.Type != opik.SpanTypeLLM {
+			t.Errorf("generate span type = %q", span.Type)
+		}
+	}
+
+	eventually(t, func() error {
+		dataset, err := client.GetDatasetByName(ctx, name)
+		if err != nil {
+			return err
+		}
+		items, err := dataset.GetItems(ctx, 1, 10)
+		if err != nil {
+			return err
+		}
+		if len(items) != len(questions) {
+			return fmt.Errorf("dataset has %d items, want %d", len(items), len(questions))
+		}
+		return nil
+	})
+	eventually(t, func() error {
+		_, err := client.GetExperiment(ctx, report.Experiment.Experiment.ID())
+		return err
+	})
+}
+
+// eventually retries check until it succeeds or 30 seconds pass.
+func eventually(t *testing.T, check func() error) {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -16,7 +18,10 @@ type Experiment struct {
 	id          string
 	name        string
 	datasetName string
-	metadata    map[string]any
+	// datasetID is the ID of the dataset, if known; Items looks it up by
+	// name otherwise.
+	datasetID string
+	metadata  map[string]any
 }
 
 // ExperimentItem represents an item result in an experiment.
@@ -27,6 +32,11 @@ type ExperimentItem struct {
 	TraceID       string
 	Input         any
 	Output        any
+	// FeedbackScores are the scores of the item's trace. They are set on
+	// items read back by Experiment.Items.
+	FeedbackScores []FeedbackScoreInfo
+	// Duration is the duration of the item's trace, if it is known.
+	Duration time.Duration
 }
 
 // ExperimentStatus represents the status of an experiment.
@@ -137,6 +147,7 @@ func (c *Client) CreateExperiment(ctx context.Context, datasetName string, opts
 	}
 
 	resp, err := c.apiClient.CreateExperiment(ctx, api.NewOptExperimentWrite(req))
+	c.audit(AuditCreate, AuditEntityExperiment, experimentUUID.String(), options.name, err)
 	if err != nil {
 		return nil, err
 	}
@@ -172,10 +183,13 @@ func (c *Client) GetExperiment(ctx context.Context, experimentID string) (*Exper
 	// Handle the response union type
 	switch v := resp.(type) {
 	case *api.ExperimentPublic:
-		var id, name, datasetName string
+		var id, name, datasetName, datasetID string
 		if v.ID.Set {
 			id = v.ID.Value.String()
 		}
+		if v.DatasetID.Set {
+			datasetID = v.DatasetID.Value.String()
+		}
 		if v.Name.Set {
 			name = v.Name.Value
 		}
@@ -186,6 +200,7 @@ func (c *Client) GetExperiment(ctx context.Context, experimentID string) (*Exper
 			id:          id,
 			name:        name,
 			datasetName: datasetName,
+			datasetID:   datasetID,
 		}, nil
 	default:
 		return nil, ErrExperimentNotFound
@@ -228,6 +243,7 @@ func (c *Client) ListExperiments(ctx context.Context, datasetID string, page, si
 				id:          id,
 				name:        name,
 				datasetName: exp.DatasetName,
+				datasetID:   datasetID,
 			})
 		}
 		return experiments, nil
@@ -247,7 +263,9 @@ func (c *Client) DeleteExperiment(ctx context.Context, experimentID string) erro
 		Ids: []uuid.UUID{experimentUUID},
 	}
 
-	return c.apiClient.DeleteExperimentsById(ctx, api.NewOptDeleteIdsHolder(req))
+	err = c.apiClient.DeleteExperimentsById(ctx, api.NewOptDeleteIdsHolder(req))
+	c.audit(AuditDelete, AuditEntityExperiment, experimentID, "", err)
+	return err
 }
 
 // ExperimentItemOption is a functional option for configuring an ExperimentItem.
@@ -320,7 +338,9 @@ func (e *Experiment) LogItem(ctx context.Context, datasetItemID, traceID string,
 		}},
 	}
 
-	return e.client.apiClient.CreateExperimentItems(ctx, api.NewOptExperimentItemsBatch(req))
+	err = e.client.apiClient.CreateExperimentItems(ctx, api.NewOptExperimentItemsBatch(req))
+	e.client.audit(AuditCreate, AuditEntityExperimentItem, itemUUID.String(), "", err)
+	return err
 }
 
 // Complete marks the experiment as completed.
@@ -333,19 +353,45 @@ func (e *Experiment) Cancel(ctx context.Context) error {
 	return e.updateStatus(ctx, ExperimentStatusCancelled)
 }
 
+// UpdateMetadata adds metadata to the experiment, replacing entries with
+// the same keys and keeping the others.
+func (e *Experiment) UpdateMetadata(ctx context.Context, metadata map[string]any) error {
+	return e.update(ctx, "", metadata)
+}
+
 func (e *Experiment) updateStatus(ctx context.Context, status ExperimentStatus) error {
+	return e.update(ctx, status, nil)
+}
+
+// update sets the experiment's status, unless it is empty, and adds
+// metadata, in one request.
+func (e *Experiment) update(ctx context.Context, status ExperimentStatus, metadata map[string]any) error {
 	experimentUUID, err := uuid.Parse(e.id)
 	if err != nil {
 		return err
 	}
 
-	req := api.ExperimentUpdate{
-		Status: api.NewOptExperimentUpdateStatus(api.ExperimentUpdateStatus(status)),
+	var req api.ExperimentUpdate
+	if status != "" {
+		req.Status = api.NewOptExperimentUpdateStatus(api.ExperimentUpdateStatus(status))
+	}
+	var merged map[string]any
+	if len(metadata) > 0 {
+		merged = maps.Clone(e.metadata)
+		if merged == nil {
+			merged = make(map[string]any, len(metadata))
+		}
+		maps.Copy(merged, metadata)
+		req.Metadata = api.NewOptJsonNode(mapToJsonNode(merged))
 	}
 
 	_, err = e.client.apiClient.UpdateExperiment(ctx, api.NewOptExperimentUpdate(req), api.UpdateExperimentParams{
 		ID: experimentUUID,
 	})
+	e.client.audit(AuditUpdate, AuditEntityExperiment, e.id, e.name, err)
+	if err == nil && merged != nil {
+		e.metadata = merged
+	}
 	return err
 }
 
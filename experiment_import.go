@@ -0,0 +1,348 @@
+package opik
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ImportFormat is the file format of offline results read by
+// ImportExperiment.
+type ImportFormat string
+
+const (
+	// ImportFormatCSV is a CSV file with a header row.
+	ImportFormatCSV ImportFormat = "csv"
+	// ImportFormatJSONL is a JSON Lines file with one object per row.
+	ImportFormatJSONL ImportFormat = "jsonl"
+	// ImportFormatParquet is a Parquet file. It is read into memory
+	// whole; integer columns are read as numbers, and nested columns as
+	// maps and slices.
+	ImportFormatParquet ImportFormat = "parquet"
+)
+
+// importPageSize is the page size used to read the dataset's items.
+const importPageSize = 100
+
+// ImportMapping describes which columns of a results file hold the item
+// key, the outputs, and the metric scores.
+type ImportMapping struct {
+	// KeyColumn holds the key that identifies each row's dataset item.
+	KeyColumn string
+	// KeyField is the dataset item field the key is matched against, such
+	// as "question". If empty, the key is the dataset item ID.
+	KeyField string
+	// OutputColumns become the experiment item's output. If empty, every
+	// column that is not the key or a metric is an output.
+	OutputColumns []string
+	// MetricColumns become feedback scores named after the column. Values
+	// must be numeric; empty values are skipped.
+	MetricColumns []string
+}
+
+// ExperimentImportResult reports the outcome of ImportExperiment.
+type ExperimentImportResult struct {
+	Experiment *Experiment
+	// Imported is the number of rows logged as experiment items.
+	Imported int
+	// Scores is the number of feedback scores logged.
+	Scores int
+	// Failed lists the rows that were not imported, by their 0-based
+	// position in the file, excluding the CSV header.
+	Failed []ItemError
+}
+
+// ImportExperiment publishes scores computed offline, for example in a
+// notebook, as an experiment on the named dataset. Each row of r is matched
+// to a dataset item by mapping.KeyColumn and logged as an experiment item
+// with a trace holding its outputs, and each metric column is logged as a
+// feedback score on that trace. The experiment is marked completed once
+// every row has been read.
+//
+// Rows that cannot be imported, such as rows whose key matches no dataset
+// item, are reported in the result's Failed list without stopping the
+// import. An error is returned if the file cannot be parsed, the mapping is
+// invalid, or the experiment cannot be created.
+func (c *Client) ImportExperiment(ctx context.Context, datasetName string, r io.Reader, format ImportFormat, mapping ImportMapping, opts ...ExperimentOption) (*ExperimentImportResult, error) {
+	if mapping.KeyColumn == "" {
+		return nil, fmt.Errorf("%w: import mapping needs a key column", ErrInvalidInput)
+	}
+	rows, columns, err := readImportRows(r, format)
+	if err != nil {
+		return nil, err
+	}
+	for _, col := range slices.Concat([]string{mapping.KeyColumn}, mapping.OutputColumns, mapping.MetricColumns) {
+		if !slices.Contains(columns, col) {
+			return nil, fmt.Errorf("%w: column %q not found in the results file", ErrInvalidInput, col)
+		}
+	}
+	outputs := mapping.OutputColumns
+	if len(outputs) == 0 {
+		for _, col := range columns {
+			if col != mapping.KeyColumn && !slices.Contains(mapping.MetricColumns, col) {
+				outputs = append(outputs, col)
+			}
+		}
+	}
+
+	dataset, err := c.GetDatasetByName(ctx, datasetName)
+	if err != nil {
+		return nil, err
+	}
+	items, err := datasetItemsByKey(ctx, dataset, mapping.KeyField)
+	if err != nil {
+		return nil, err
+	}
+
+	experiment, err := c.CreateExperiment(ctx, datasetName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExperimentImportResult{Experiment: experiment}
+
+	var scores []FeedbackBatchItem
+	scoreRows := make(map[int]int) // index in scores -> row
+	for i, row := range rows {
+		item, ok := items[importString(row[mapping.KeyColumn])]
+		if !ok {
+			result.Failed = append(result.Failed, ItemError{Index: i, Err: fmt.Errorf("no dataset item with %s %q", keyFieldName(mapping.KeyField), importString(row[mapping.KeyColumn]))})
+			continue
+		}
+		values := make(map[string]float64, len(mapping.MetricColumns))
+		if err := importMetrics(row, mapping.MetricColumns, values); err != nil {
+			result.Failed = append(result.Failed, ItemError{Index: i, Err: err})
+			continue
+		}
+		output := make(map[string]any, len(outputs))
+		for _, col := range outputs {
+			output[col] = row[col]
+		}
+
+		trace, err := c.Trace(ctx, "experiment_import",
+			WithTraceInput(item.Data),
+			WithTraceOutput(output),
+			WithTraceMetadata(map[string]any{"experiment": experiment.Name(), "dataset": datasetName}),
+			withSampled(),
+		)
+		if err == nil {
+			err = trace.End(ctx)
+		}
+		if err == nil {
+			err = experiment.LogItem(ctx, item.ID, trace.ID(), WithExperimentItemInput(item.Data), WithExperimentItemOutput(output))
+		}
+		if err != nil {
+			result.Failed = append(result.Failed, ItemError{Index: i, Err: err})
+			continue
+		}
+		result.Imported++
+		for _, col := range mapping.MetricColumns {
+			if v, ok := values[col]; ok {
+				scoreRows[len(scores)] = i
+				scores = append(scores, FeedbackBatchItem{EntityType: "trace", EntityID: trace.ID(), Name: col, Value: v})
+			}
+		}
+	}
+
+	if len(scores) > 0 {
+		batch, err := c.AddFeedbackScores(ctx, scores)
+		if err != nil {
+			return result, err
+		}
+		result.Scores = batch.Succeeded
+		for _, f := range batch.Failed {
+			result.Failed = append(result.Failed, ItemError{Index: scoreRows[f.Index], Err: fmt.Errorf("score %s: %w", scores[f.Index].Name, f.Err)})
+		}
+		slices.SortStableFunc(result.Failed, func(a, b ItemError) int { return a.Index - b.Index })
+	}
+
+	if err := experiment.Complete(ctx); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// readImportRows parses a results file into rows keyed by column name, and
+// returns the column names: in file order for CSV and Parquet, and sorted
+// for JSON Lines.
+func readImportRows(r io.Reader, format ImportFormat) ([]map[string]any, []string, error) {
+	switch format {
+	case ImportFormatCSV:
+		records, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read CSV: %w", err)
+		}
+		if len(records) == 0 {
+			return nil, nil, fmt.Errorf("%w: CSV file has no header row", ErrInvalidInput)
+		}
+		header := records[0]
+		rows := make([]map[string]any, 0, len(records)-1)
+		for _, record := range records[1:] {
+			row := make(map[string]any, len(header))
+			for i, col := range header {
+				row[col] = record[i]
+			}
+			rows = append(rows, row)
+		}
+		return rows, header, nil
+
+	case ImportFormatJSONL:
+		var rows []map[string]any
+		var columns []string
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			var row map[string]any
+			if err := json.Unmarshal([]byte(text), &row); err != nil {
+				return nil, nil, fmt.Errorf("read JSON Lines: line %d: %w", line, err)
+			}
+			for col := range row {
+				if !slices.Contains(columns, col) {
+					columns = append(columns, col)
+				}
+			}
+			rows = append(rows, row)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, nil, fmt.Errorf("read JSON Lines: %w", err)
+		}
+		slices.Sort(columns)
+		return rows, columns, nil
+
+	case ImportFormatParquet:
+		return readParquetRows(r)
+
+	default:
+		return nil, nil, fmt.Errorf("%w: unsupported import format %q", ErrInvalidInput, format)
+	}
+}
+
+// readParquetRows reads the rows of a Parquet file, with integers made
+// float64 so they match keys and metrics as JSON numbers do.
+func readParquetRows(r io.Reader) ([]map[string]any, []string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read Parquet: %w", err)
+	}
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: read Parquet: %v", ErrInvalidInput, err)
+	}
+	var columns []string
+	for _, field := range file.Schema().Fields() {
+		columns = append(columns, field.Name())
+	}
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+	var rows []map[string]any
+	for i := 0; ; i++ {
+		row := make(map[string]any, len(columns))
+		if err := reader.Read(&row); err == io.EOF {
+			return rows, columns, nil
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("read Parquet: row %d: %w", i, err)
+		}
+		for col, v := range row {
+			row[col] = parquetNumber(v)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// parquetNumber returns v as a float64 if it is a number.
+func parquetNumber(v any) any {
+	switch v := v.(type) {
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	return v
+}
+
+// datasetItemsByKey reads every item of the dataset, indexed by the value
+// of field, or by item ID if field is empty.
+func datasetItemsByKey(ctx context.Context, dataset *Dataset, field string) (map[string]DatasetItem, error) {
+	items := make(map[string]DatasetItem)
+	for page := 1; ; page++ {
+		batch, err := dataset.GetItems(ctx, page, importPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range batch {
+			key := item.ID
+			if field != "" {
+				key = importString(item.Data[field])
+			}
+			items[key] = item
+		}
+		if len(batch) < importPageSize {
+			return items, nil
+		}
+	}
+}
+
+// importMetrics parses the metric columns of a row into values, skipping
+// empty cells.
+func importMetrics(row map[string]any, columns []string, values map[string]float64) error {
+	for _, col := range columns {
+		switch v := row[col].(type) {
+		case nil:
+		case float64:
+			values[col] = v
+		case string:
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return fmt.Errorf("metric %s: %q is not a number", col, v)
+			}
+			values[col] = f
+		default:
+			return fmt.Errorf("metric %s: %v is not a number", col, v)
+		}
+	}
+	return nil
+}
+
+// importString returns a key cell or dataset field as a string, so CSV
+// text and JSON numbers match.
+func importString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+func keyFieldName(field string) string {
+	if field == "" {
+		return "ID"
+	}
+	return field
+}
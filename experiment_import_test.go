@@ -0,0 +1,228 @@
+package opik
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+// importServer serves a dataset of three questions and records the
+// experiment items, feedback scores, and experiment updates it receives.
+type importServer struct {
+	*testutil.MockServer
+	itemIDs map[string]string // question -> dataset item ID
+}
+
+func newImportServer(t *testing.T) *importServer {
+	t.Helper()
+	datasetID := uuid.Must(uuid.NewV7()).String()
+	s := &importServer{MockServer: testutil.NewMockServer(), itemIDs: map[string]string{}}
+	var content []map[string]any
+	for _, q := range []string{"capital of France", "2+2", "largest ocean"} {
+		id := uuid.Must(uuid.NewV7()).String()
+		s.itemIDs[q] = id
+		content = append(content, map[string]any{"id": id, "source": "sdk", "data": map[string]any{"question": q}})
+	}
+
+	s.OnPost("/v1/private/datasets/retrieve").RespondJSON(http.StatusOK, map[string]any{"id": datasetID, "name": "qa"})
+	s.OnGet("/v1/private/datasets/"+datasetID+"/items").
+		RespondJSON(http.StatusOK, map[string]any{"page": 1, "size": len(content), "total": len(content), "content": content})
+	s.OnPost("/v1/private/experiments").Respond(http.StatusCreated, nil).
+		WithHeaders(map[string]string{"Location": "/v1/private/experiments/" + uuid.NewString()})
+	s.OnPost("/v1/private/experiments/items").Respond(http.StatusNoContent, nil)
+	s.OnPut("/v1/private/traces/feedback-scores").Respond(http.StatusNoContent, nil)
+	// Experiment updates, and trace writes, go to paths holding IDs the
+	// client makes up.
+	s.OnUnmatched().WithHandler(func(w http.ResponseWriter, r *http.Request) {
+		if !isExperimentUpdate(r.Method, r.URL.Path) && !strings.HasPrefix(r.URL.Path, "/v1/private/traces") {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	t.Cleanup(s.Close)
+	return s
+}
+
+// recorded returns the entries under field of the requests to method and
+// path.
+func (s *importServer) recorded(method, path, field string) []map[string]any {
+	var entries []map[string]any
+	for _, r := range s.RequestsFor(method, path) {
+		var body map[string][]map[string]any
+		_ = r.DecodeJSON(&body)
+		entries = append(entries, body[field]...)
+	}
+	return entries
+}
+
+func (s *importServer) items() []map[string]any {
+	return s.recorded(http.MethodPost, "/v1/private/experiments/items", "experiment_items")
+}
+
+func (s *importServer) scores() []map[string]any {
+	return s.recorded(http.MethodPut, "/v1/private/traces/feedback-scores", "scores")
+}
+
+func (s *importServer) updates() []map[string]any {
+	var updates []map[string]any
+	for _, r := range s.Requests() {
+		if !isExperimentUpdate(r.Method, r.Path) {
+			continue
+		}
+		var body map[string]any
+		_ = r.DecodeJSON(&body)
+		updates = append(updates, body)
+	}
+	return updates
+}
+
+func isExperimentUpdate(method, path string) bool {
+	return method == http.MethodPatch && strings.HasPrefix(path, "/v1/private/experiments/")
+}
+
+func TestImportExperimentCSV(t *testing.T) {
+	s := newImportServer(t)
+	client, err := NewClient(WithURL(s.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	csv := "question,answer,accuracy,fluency\n" +
+		"capital of France,Paris,1,0.9\n" +
+		"2+2,5,0,\n" +
+		"unknown,?,1,1\n" +
+		"largest ocean,Pacific,high,1\n"
+	result, err := client.ImportExperiment(context.Background(), "qa", strings.NewReader(csv), ImportFormatCSV, ImportMapping{
+		KeyColumn:     "question",
+		KeyField:      "question",
+		MetricColumns: []string{"accuracy", "fluency"},
+	}, WithExperimentName("notebook-run"))
+	if err != nil {
+		t.Fatalf("ImportExperiment error: %v", err)
+	}
+
+	if result.Imported != 2 || result.Scores != 3 {
+		t.Errorf("imported %d rows and %d scores, want 2 and 3", result.Imported, result.Scores)
+	}
+	if len(result.Failed) != 2 || result.Failed[0].Index != 2 || result.Failed[1].Index != 3 {
+		t.Fatalf("failed = %v, want rows 2 and 3", result.Failed)
+	}
+	if msg := result.Failed[0].Err.Error(); msg != `no dataset item with question "unknown"` {
+		t.Errorf("row 2 error = %q", msg)
+	}
+	if msg := result.Failed[1].Err.Error(); msg != `metric accuracy: "high" is not a number` {
+		t.Errorf("row 3 error = %q", msg)
+	}
+
+	items := s.items()
+	if len(items) != 2 || items[0]["dataset_item_id"] != s.itemIDs["capital of France"] {
+		t.Fatalf("experiment items = %v", items)
+	}
+	if output := items[0]["output"].(map[string]any); len(output) != 1 || output["answer"] != "Paris" {
+		t.Errorf("output = %v, want only the answer column", output)
+	}
+	var names []string
+	for _, score := range s.scores() {
+		names = append(names, score["name"].(string))
+	}
+	if strings.Join(names, ",") != "accuracy,fluency,accuracy" {
+		t.Errorf("scores = %v", names)
+	}
+	if updates := s.updates(); len(updates) != 1 || updates[0]["status"] != "completed" {
+		t.Errorf("experiment updates = %v, want it completed", updates)
+	}
+}
+
+func TestImportExperimentJSONL(t *testing.T) {
+	s := newImportServer(t)
+	client, err := NewClient(WithURL(s.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	jsonl := `{"item_id": "` + s.itemIDs["2+2"] + `", "answer": "4", "steps": ["add"], "score": 1}` + "\n"
+	result, err := client.ImportExperiment(context.Background(), "qa", strings.NewReader(jsonl), ImportFormatJSONL, ImportMapping{
+		KeyColumn:     "item_id",
+		OutputColumns: []string{"answer"},
+		MetricColumns: []string{"score"},
+	})
+	if err != nil {
+		t.Fatalf("ImportExperiment error: %v", err)
+	}
+	if result.Imported != 1 || result.Scores != 1 || len(result.Failed) != 0 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestImportExperimentParquet(t *testing.T) {
+	s := newImportServer(t)
+	client, err := NewClient(WithURL(s.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	type result struct {
+		Question string   `parquet:"question"`
+		Answer   string   `parquet:"answer"`
+		Accuracy int64    `parquet:"accuracy"`
+		Fluency  *float64 `parquet:"fluency,optional"`
+	}
+	fluency := 0.9
+	var file bytes.Buffer
+	if err := parquet.Write(&file, []result{
+		{"capital of France", "Paris", 1, &fluency},
+		{"2+2", "5", 0, nil},
+	}); err != nil {
+		t.Fatalf("write Parquet: %v", err)
+	}
+
+	imported, err := client.ImportExperiment(context.Background(), "qa", &file, ImportFormatParquet, ImportMapping{
+		KeyColumn:     "question",
+		KeyField:      "question",
+		MetricColumns: []string{"accuracy", "fluency"},
+	})
+	if err != nil {
+		t.Fatalf("ImportExperiment error: %v", err)
+	}
+	if imported.Imported != 2 || imported.Scores != 3 || len(imported.Failed) != 0 {
+		t.Errorf("result = %+v, want 2 rows and 3 scores", imported)
+	}
+	items := s.items()
+	if len(items) != 2 || items[1]["dataset_item_id"] != s.itemIDs["2+2"] {
+		t.Fatalf("experiment items = %v", items)
+	}
+	if output := items[0]["output"].(map[string]any); len(output) != 1 || output["answer"] != "Paris" {
+		t.Errorf("output = %v, want only the answer column", output)
+	}
+}
+
+func TestImportExperimentInvalid(t *testing.T) {
+	client, err := NewClient(WithURL("http://localhost"), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	for name, tc := range map[string]struct {
+		format  ImportFormat
+		mapping ImportMapping
+	}{
+		"no key":         {ImportFormatCSV, ImportMapping{}},
+		"missing column": {ImportFormatCSV, ImportMapping{KeyColumn: "id", MetricColumns: []string{"bleu"}}},
+		"unknown format": {"xlsx", ImportMapping{KeyColumn: "id"}},
+		"not parquet":    {ImportFormatParquet, ImportMapping{KeyColumn: "id"}},
+	} {
+		_, err := client.ImportExperiment(ctx, "qa", strings.NewReader("id,score\n1,1\n"), tc.format, tc.mapping)
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: error = %v, want ErrInvalidInput", name, err)
+		}
+	}
+}
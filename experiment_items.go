@@ -0,0 +1,167 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// Items returns an iterator over the experiment's items, with the feedback
+// scores of their traces. Items are read with their dataset items, a page
+// at a time as the iterator advances, in the order of the dataset. If the
+// experiment's dataset cannot be found or a request fails, the iterator
+// yields the error and stops.
+//
+//	for item, err := range experiment.Items(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		// use item.Output and item.FeedbackScores
+//	}
+func (e *Experiment) Items(ctx context.Context) iter.Seq2[ExperimentItem, error] {
+	return func(yield func(ExperimentItem, error) bool) {
+		experimentUUID, err := uuid.Parse(e.id)
+		if err != nil {
+			yield(ExperimentItem{}, fmt.Errorf("%w: experiment ID %q: %v", ErrInvalidInput, e.id, err))
+			return
+		}
+		datasetUUID, err := e.datasetUUID(ctx)
+		if err != nil {
+			yield(ExperimentItem{}, err)
+			return
+		}
+		ids, err := json.Marshal([]string{e.id})
+		if err != nil {
+			yield(ExperimentItem{}, err)
+			return
+		}
+
+		params := api.FindDatasetItemsWithExperimentItemsParams{
+			ID:            datasetUUID,
+			Size:          api.NewOptInt32(itemsPageSize),
+			ExperimentIds: string(ids),
+		}
+		for page := int32(1); ; page++ {
+			params.Page = api.NewOptInt32(page)
+			resp, err := e.client.apiClient.FindDatasetItemsWithExperimentItems(ctx, params)
+			if err != nil {
+				yield(ExperimentItem{}, err)
+				return
+			}
+			for _, datasetItem := range resp.Content {
+				for i := range datasetItem.ExperimentItems {
+					item := &datasetItem.ExperimentItems[i]
+					if item.ExperimentID != experimentUUID {
+						continue
+					}
+					if !yield(experimentItemFromAPI(item), nil) {
+						return
+					}
+				}
+			}
+			if len(resp.Content) < itemsPageSize {
+				return
+			}
+		}
+	}
+}
+
+// ExperimentScores are the feedback scores of an experiment's items,
+// aggregated by score name.
+type ExperimentScores struct {
+	// Items is the number of items read, scored or not.
+	Items int
+	// Metrics holds the statistics of each score, by name.
+	Metrics map[string]FeedbackStats
+}
+
+// Names returns the names of the scores, sorted.
+func (s *ExperimentScores) Names() []string {
+	names := make([]string, 0, len(s.Metrics))
+	for name := range s.Metrics {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Scores reads the experiment's items and aggregates their feedback scores
+// by name, so experiments can be ranked or checked for regressions without
+// exporting them:
+//
+//	scores, err := experiment.Scores(ctx)
+//	for _, name := range scores.Names() {
+//	    s := scores.Metrics[name]
+//	    fmt.Printf("%s  n=%d  mean=%.2f  p50=%.2f\n", name, s.Count, s.Mean, s.P50)
+//	}
+//
+// The statistics are computed in the client from Items.
+func (e *Experiment) Scores(ctx context.Context) (*ExperimentScores, error) {
+	scores := &ExperimentScores{Metrics: make(map[string]FeedbackStats)}
+	values := make(map[string][]float64)
+	for item, err := range e.Items(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		scores.Items++
+		for _, s := range item.FeedbackScores {
+			values[s.Name] = append(values[s.Name], s.Value)
+		}
+	}
+	for name, v := range values {
+		scores.Metrics[name] = feedbackStats(v)
+	}
+	return scores, nil
+}
+
+// datasetUUID returns the ID of the experiment's dataset, looking it up by
+// name the first time if the experiment was created without it.
+func (e *Experiment) datasetUUID(ctx context.Context) (uuid.UUID, error) {
+	if e.datasetID == "" {
+		dataset, err := e.client.GetDatasetByName(ctx, e.datasetName)
+		if err != nil {
+			return uuid.UUID{}, err
+		}
+		e.datasetID = dataset.ID()
+	}
+	id, err := uuid.Parse(e.datasetID)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("%w: dataset ID %q: %v", ErrInvalidInput, e.datasetID, err)
+	}
+	return id, nil
+}
+
+// experimentItemFromAPI converts an experiment item read with its dataset
+// item. The server reports durations in milliseconds.
+func experimentItemFromAPI(item *api.ExperimentItemCompare) ExperimentItem {
+	out := ExperimentItem{
+		ExperimentID:  item.ExperimentID.String(),
+		DatasetItemID: item.DatasetItemID.String(),
+		TraceID:       item.TraceID.String(),
+		Input:         decodeJSONValue(api.JsonListStringPublic(item.Input)),
+		Output:        decodeJSONValue(api.JsonListStringPublic(item.Output)),
+	}
+	if item.ID.Set {
+		out.ID = item.ID.Value.String()
+	}
+	if item.Duration.Set {
+		out.Duration = time.Duration(item.Duration.Value * float64(time.Millisecond))
+	}
+	for _, s := range item.FeedbackScores {
+		out.FeedbackScores = append(out.FeedbackScores, FeedbackScoreInfo{
+			Name:         s.Name,
+			Value:        s.Value,
+			Reason:       s.Reason.Or(""),
+			CategoryName: s.CategoryName.Or(""),
+			Source:       string(s.Source),
+		})
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+const datasetLookupPath = "/v1/private/datasets/retrieve"
+
+// newExperimentItemsServer serves a dataset of three items, two run in the
+// experiment experimentID and one also in another experiment. Dataset
+// lookups are counted by the route of datasetLookupPath.
+func newExperimentItemsServer(t *testing.T, experimentID string) *testutil.MockServer {
+	t.Helper()
+	datasetID := uuid.Must(uuid.NewV7()).String()
+	otherID := uuid.Must(uuid.NewV7()).String()
+	item := func(experiment, output string, duration float64, scores ...map[string]any) map[string]any {
+		return map[string]any{
+			"id":              uuid.NewString(),
+			"experiment_id":   experiment,
+			"dataset_item_id": uuid.NewString(),
+			"trace_id":        uuid.NewString(),
+			"input":           map[string]any{"question": "q"},
+			"output":          map[string]any{"answer": output},
+			"duration":        duration,
+			"feedback_scores": scores,
+		}
+	}
+	score := func(name string, value float64) map[string]any {
+		return map[string]any{"name": name, "value": value, "source": "sdk"}
+	}
+	content := []map[string]any{
+		{"id": uuid.NewString(), "source": "sdk", "data": map[string]any{}, "experiment_items": []map[string]any{
+			item(experimentID, "Paris", 1500, score("accuracy", 1), score("fluency", 0.5)),
+			item(otherID, "Lyon", 900, score("accuracy", 0)),
+		}},
+		{"id": uuid.NewString(), "source": "sdk", "data": map[string]any{}, "experiment_items": []map[string]any{
+			item(experimentID, "5", 200, score("accuracy", 0)),
+		}},
+		{"id": uuid.NewString(), "source": "sdk", "data": map[string]any{}, "experiment_items": []map[string]any{}},
+	}
+
+	ms := testutil.NewMockServer()
+	ms.OnPost(datasetLookupPath).RespondJSON(http.StatusOK, map[string]any{"id": datasetID, "name": "qa"})
+	ms.OnGet("/v1/private/experiments/"+experimentID).
+		RespondJSON(http.StatusOK, map[string]any{"id": experimentID, "name": "run", "dataset_name": "qa", "dataset_id": datasetID})
+	ms.OnGet("/v1/private/datasets/"+datasetID+"/items/experiments/items").
+		RespondJSON(http.StatusOK, map[string]any{"page": 1, "size": len(content), "total": len(content), "content": content})
+	t.Cleanup(ms.Close)
+	return ms
+}
+
+func TestExperimentItems(t *testing.T) {
+	experimentID := uuid.Must(uuid.NewV7()).String()
+	ts := newExperimentItemsServer(t, experimentID)
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	e := &Experiment{client: client, id: experimentID, name: "run", datasetName: "qa"}
+
+	var items []ExperimentItem
+	for item, err := range e.Items(context.Background()) {
+		if err != nil {
+			t.Fatalf("Items error: %v", err)
+		}
+		items = append(items, item)
+	}
+	if len(items) != 2 {
+		t.Fatalf("items = %d, want the 2 items of the experiment", len(items))
+	}
+	if got, want := ts.LastRequest().Query.Get("experiment_ids"), `["`+experimentID+`"]`; got != want {
+		t.Errorf("experiment_ids = %q, want %q", got, want)
+	}
+	first := items[0]
+	if first.ExperimentID != experimentID || first.ID == "" || first.DatasetItemID == "" || first.TraceID == "" {
+		t.Errorf("item IDs = %+v, want them all set", first)
+	}
+	if got := first.Output.(map[string]any)["answer"]; got != "Paris" {
+		t.Errorf("Output answer = %v, want Paris", got)
+	}
+	if first.Duration != 1500*time.Millisecond {
+		t.Errorf("Duration = %v, want 1.5s", first.Duration)
+	}
+	if len(first.FeedbackScores) != 2 || first.FeedbackScores[0].Name != "accuracy" || first.FeedbackScores[0].Source != "sdk" {
+		t.Errorf("FeedbackScores = %+v, want accuracy and fluency", first.FeedbackScores)
+	}
+
+	for _, err := range e.Items(context.Background()) {
+		if err != nil {
+			t.Fatalf("Items error: %v", err)
+		}
+	}
+	if lookups := ts.RouteCallCount(http.MethodPost, datasetLookupPath); lookups != 1 {
+		t.Errorf("dataset lookups = %d, want the dataset ID kept after the first", lookups)
+	}
+}
+
+func TestExperimentItemsInvalidID(t *testing.T) {
+	client, err := NewClient(WithURL("http://localhost:1"), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	e := &Experiment{client: client, id: "not-a-uuid", datasetName: "qa"}
+	for _, err := range e.Items(context.Background()) {
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Items error = %v, want ErrInvalidInput", err)
+		}
+	}
+}
+
+func TestExperimentScores(t *testing.T) {
+	experimentID := uuid.Must(uuid.NewV7()).String()
+	ts := newExperimentItemsServer(t, experimentID)
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	e, err := client.GetExperiment(context.Background(), experimentID)
+	if err != nil {
+		t.Fatalf("GetExperiment error: %v", err)
+	}
+
+	scores, err := e.Scores(context.Background())
+	if err != nil {
+		t.Fatalf("Scores error: %v", err)
+	}
+	if lookups := ts.RouteCallCount(http.MethodPost, datasetLookupPath); lookups != 0 {
+		t.Errorf("dataset lookups = %d, want the dataset ID of the experiment used", lookups)
+	}
+	if scores.Items != 2 {
+		t.Errorf("Items = %d, want 2", scores.Items)
+	}
+	if got := scores.Names(); len(got) != 2 || got[0] != "accuracy" || got[1] != "fluency" {
+		t.Errorf("Names() = %v, want [accuracy fluency]", got)
+	}
+	if got := scores.Metrics["accuracy"]; got.Count != 2 || got.Mean != 0.5 {
+		t.Errorf("accuracy = %+v, want 2 scores with mean 0.5", got)
+	}
+	if got := scores.Metrics["fluency"]; got.Count != 1 || got.Mean != 0.5 {
+		t.Errorf("fluency = %+v, want 1 score of 0.5", got)
+	}
+}
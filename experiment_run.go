@@ -0,0 +1,320 @@
+package opik
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// ExperimentRunOption configures RunExperiment.
+type ExperimentRunOption func(*experimentRunOptions)
+
+type experimentRunOptions struct {
+	experimentOpts []ExperimentOption
+	engineOpts     []evaluation.EngineOption
+	itemOpts       []DatasetItemsOption
+	mapper         func(item map[string]any) evaluation.MetricInput
+	concurrency    int
+	traceName      string
+	manifest       evaluation.ManifestConfig
+	problems       evaluation.Problems
+}
+
+// WithRunExperimentOptions configures the experiment RunExperiment
+// creates, for example its name and metadata.
+func WithRunExperimentOptions(opts ...ExperimentOption) ExperimentRunOption {
+	return func(o *experimentRunOptions) {
+		o.experimentOpts = append(o.experimentOpts, opts...)
+	}
+}
+
+// WithRunEngineOptions configures the evaluation engine that scores the
+// task outputs, for example with a worker pool or a token budget.
+func WithRunEngineOptions(opts ...evaluation.EngineOption) ExperimentRunOption {
+	return func(o *experimentRunOptions) {
+		o.engineOpts = append(o.engineOpts, opts...)
+	}
+}
+
+// WithRunItems selects the dataset items to run, for example a version or
+// a filter; see Dataset.Items.
+func WithRunItems(opts ...DatasetItemsOption) ExperimentRunOption {
+	return func(o *experimentRunOptions) {
+		o.itemOpts = append(o.itemOpts, opts...)
+	}
+}
+
+// WithRunInputMapper sets how an item's data, with the task output merged
+// in, becomes the metric input. The default reads the "input", "output",
+// and "expected" keys, as evaluation.DefaultInputMapper does.
+func WithRunInputMapper(mapper func(item map[string]any) evaluation.MetricInput) ExperimentRunOption {
+	return func(o *experimentRunOptions) {
+		if mapper == nil {
+			o.problems.Addf("input mapper is nil")
+			return
+		}
+		o.mapper = mapper
+	}
+}
+
+// WithRunConcurrency sets how many items run their task, and are scored,
+// at once. The default is 1.
+func WithRunConcurrency(n int) ExperimentRunOption {
+	return func(o *experimentRunOptions) {
+		if n <= 0 {
+			o.problems.Addf("concurrency must be positive: %d", n)
+			return
+		}
+		o.concurrency = n
+	}
+}
+
+// WithRunTraceName sets the name of the trace recorded for each task call.
+// The default is "evaluation_task".
+func WithRunTraceName(name string) ExperimentRunOption {
+	return func(o *experimentRunOptions) {
+		o.traceName = name
+	}
+}
+
+// WithRunManifest sets the run manifest's configuration, for example its
+// task version, git commit, or extra entries. The name, dataset, metrics,
+// SDK version, and timestamps are filled in by RunExperiment unless set.
+func WithRunManifest(config evaluation.ManifestConfig) ExperimentRunOption {
+	return func(o *experimentRunOptions) {
+		o.manifest = config
+	}
+}
+
+// ExperimentRunResult reports the outcome of RunExperiment.
+type ExperimentRunResult struct {
+	Experiment *Experiment
+	// Results holds the evaluation of each dataset item, in dataset order,
+	// with ItemID set to the dataset item ID. Items whose task failed have
+	// the task's error and no scores.
+	Results evaluation.EvaluationResults
+	// Manifest is the run manifest, also stored in the experiment's
+	// metadata under "manifest".
+	Manifest *evaluation.RunManifest
+	// Scores is the number of feedback scores logged.
+	Scores int
+	// Failed lists the items whose task failed or whose results could not
+	// be logged, by their position in Results.
+	Failed []ItemError
+}
+
+// experimentRun is the task outcome of one dataset item.
+type experimentRun struct {
+	traceID string
+	output  map[string]any
+	err     error
+}
+
+// RunExperiment evaluates task on a dataset end to end, as the Python SDK's
+// evaluate function does. For each dataset item it calls task inside a new
+// trace, so spans the task starts from its context are nested under it,
+// then scores the item's data, with the task output merged in, with
+// metrics. Each item is logged as an experiment item linked to its trace,
+// and each score as a feedback score on that trace; not-scored and failed
+// metrics are not logged. A run manifest (see evaluation.Manifest) is
+// added to the experiment's metadata, and the experiment is marked
+// completed at the end,
+// or cancelled if ctx is done first; items not started by then are
+// reported as failed, and the results of items already run are still
+// logged.
+//
+//	result, err := opik.RunExperiment(ctx, client, dataset,
+//		func(ctx context.Context, item map[string]any) (map[string]any, error) {
+//			answer, err := app.Answer(ctx, item["input"].(string))
+//			return map[string]any{"output": answer}, err
+//		},
+//		[]evaluation.Metric{heuristic.NewEquals(false)},
+//		opik.WithRunConcurrency(8),
+//	)
+//
+// Items whose task fails, or whose results cannot be logged, are reported
+// in the result's Failed list without stopping the run. An error is
+// returned if an argument or option is invalid, the dataset items cannot be
+// read, or the experiment cannot be created. If tracing is disabled, items
+// are scored but not logged.
+func RunExperiment(ctx context.Context, client *Client, dataset *Dataset, task evaluation.Task, metrics []evaluation.Metric, opts ...ExperimentRunOption) (*ExperimentRunResult, error) {
+	options := &experimentRunOptions{
+		mapper:      evaluation.DefaultInputMapper("input", "output", "expected"),
+		concurrency: 1,
+		traceName:   "evaluation_task",
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if client == nil {
+		options.problems.Addf("client is nil")
+	}
+	if dataset == nil {
+		options.problems.Addf("dataset is nil")
+	}
+	if task == nil {
+		options.problems.Addf("task is nil")
+	}
+	if len(metrics) == 0 {
+		options.problems.Addf("no metrics")
+	}
+	if err := invalidOptions(options.problems); err != nil {
+		return nil, err
+	}
+	startedAt := time.Now().UTC()
+	engine := evaluation.NewEngine(metrics, slices.Concat(
+		[]evaluation.EngineOption{evaluation.WithConcurrency(options.concurrency)},
+		options.engineOpts,
+	)...)
+	if err := engine.Validate(); err != nil {
+		return nil, err
+	}
+
+	var items []DatasetItem
+	for item, err := range dataset.Items(ctx, options.itemOpts...) {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	experiment, err := client.CreateExperiment(ctx, dataset.Name(), options.experimentOpts...)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExperimentRunResult{Experiment: experiment, Results: make(evaluation.EvaluationResults, len(items))}
+
+	runs := make([]experimentRun, len(items))
+	sem := make(chan struct{}, options.concurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			runs[i].err = fmt.Errorf("task canceled: %w", ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			runs[i] = runExperimentTask(ctx, client, options.traceName, experiment, dataset.Name(), item, task)
+		}()
+	}
+	wg.Wait()
+
+	var inputs []evaluation.MetricInput
+	var indexes []int
+	for i, run := range runs {
+		data := maps.Clone(items[i].Data)
+		if data == nil {
+			data = make(map[string]any, len(run.output))
+		}
+		maps.Copy(data, run.output)
+		if run.err != nil {
+			result.Results[i] = &evaluation.EvaluationResult{ItemID: items[i].ID, Input: options.mapper(data), Error: run.err}
+			result.Failed = append(result.Failed, ItemError{Index: i, Err: run.err})
+			continue
+		}
+		inputs = append(inputs, options.mapper(data))
+		indexes = append(indexes, i)
+	}
+	for j, res := range engine.EvaluateMany(ctx, inputs) {
+		i := indexes[j]
+		res.ItemID = items[i].ID
+		result.Results[i] = res
+	}
+
+	// Results of the tasks that ran are logged even if ctx is done.
+	logCtx := context.WithoutCancel(ctx)
+	var scores []FeedbackBatchItem
+	scoreItems := make(map[int]int) // index in scores -> item
+	for i, run := range runs {
+		if run.traceID == "" {
+			continue
+		}
+		err := experiment.LogItem(logCtx, items[i].ID, run.traceID, WithExperimentItemInput(items[i].Data), WithExperimentItemOutput(run.output))
+		if err != nil {
+			result.Failed = append(result.Failed, ItemError{Index: i, Err: fmt.Errorf("log experiment item: %w", err)})
+			continue
+		}
+		for _, score := range result.Results[i].Scores {
+			if score.IsSuccess() {
+				scoreItems[len(scores)] = i
+				scores = append(scores, FeedbackBatchItem{EntityType: "trace", EntityID: run.traceID, Name: score.Name, Value: score.Value, Reason: score.Reason})
+			}
+		}
+	}
+
+	if len(scores) > 0 {
+		batch, err := client.AddFeedbackScores(logCtx, scores)
+		if err != nil {
+			return result, err
+		}
+		result.Scores = batch.Succeeded
+		for _, f := range batch.Failed {
+			result.Failed = append(result.Failed, ItemError{Index: scoreItems[f.Index], Err: fmt.Errorf("score %s: %w", scores[f.Index].Name, f.Err)})
+		}
+	}
+	slices.SortStableFunc(result.Failed, func(a, b ItemError) int { return a.Index - b.Index })
+
+	config := options.manifest
+	if config.Name == "" {
+		config.Name = experiment.Name()
+	}
+	if config.DatasetName == "" {
+		config.DatasetName = dataset.Name()
+	}
+	if config.Metrics == nil {
+		config.Metrics = metrics
+	}
+	if config.SDKVersion == "" {
+		config.SDKVersion = Version
+	}
+	if config.StartedAt.IsZero() {
+		config.StartedAt = startedAt
+	}
+	if config.FinishedAt.IsZero() {
+		config.FinishedAt = time.Now().UTC()
+	}
+	result.Manifest = evaluation.Manifest(result.Results, config)
+	manifest := map[string]any{"manifest": result.Manifest.Metadata()}
+
+	if ctx.Err() != nil {
+		_ = experiment.update(logCtx, ExperimentStatusCancelled, manifest)
+		return result, ctx.Err()
+	}
+	if err := experiment.update(logCtx, ExperimentStatusCompleted, manifest); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// runExperimentTask calls task for item inside a new trace.
+func runExperimentTask(ctx context.Context, client *Client, name string, experiment *Experiment, datasetName string, item DatasetItem, task evaluation.Task) experimentRun {
+	metadata := map[string]any{"experiment": experiment.Name(), "dataset": datasetName, "dataset_item_id": item.ID}
+	taskCtx, trace, err := StartTrace(ctx, client, name, WithTraceInput(item.Data), WithTraceMetadata(metadata), withSampled())
+	if err != nil {
+		return experimentRun{err: fmt.Errorf("start trace: %w", err)}
+	}
+	run := experimentRun{traceID: trace.ID()}
+
+	run.output, run.err = task(taskCtx, item.Data)
+	endOpts := []TraceOption{}
+	if run.output != nil {
+		endOpts = append(endOpts, WithTraceOutput(run.output))
+	}
+	if run.err != nil {
+		run.err = fmt.Errorf("task: %w", run.err)
+		endOpts = append(endOpts, WithTraceMetadata(map[string]any{"error": run.err.Error()}), WithTraceError(run.err))
+	}
+	if err := trace.End(context.WithoutCancel(ctx), endOpts...); err != nil && run.err == nil {
+		run.err = fmt.Errorf("end trace: %w", err)
+	}
+	return run
+}
@@ -0,0 +1,180 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/heuristic"
+)
+
+var answers = map[string]string{"capital of France": "Paris", "2+2": "4"}
+
+func answerTask(traceIDs *sync.Map) evaluation.Task {
+	return func(ctx context.Context, item map[string]any) (map[string]any, error) {
+		q := item["question"].(string)
+		traceIDs.Store(q, CurrentTraceID(ctx))
+		answer, ok := answers[q]
+		if !ok {
+			return nil, errors.New("no answer")
+		}
+		return map[string]any{"output": answer}, nil
+	}
+}
+
+func TestRunExperiment(t *testing.T) {
+	s := newImportServer(t)
+	client, err := NewClient(WithURL(s.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	dataset, err := client.GetDatasetByName(context.Background(), "qa")
+	if err != nil {
+		t.Fatalf("GetDatasetByName error: %v", err)
+	}
+
+	var traceIDs sync.Map
+	answered := evaluation.NewMetricFunc("answered", func(ctx context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+		return evaluation.BooleanScore("answered", input.Output != "")
+	})
+	result, err := RunExperiment(context.Background(), client, dataset, answerTask(&traceIDs),
+		[]evaluation.Metric{answered, heuristic.NewBLEU(4)},
+		WithRunConcurrency(2),
+		WithRunExperimentOptions(WithExperimentName("run-1")),
+	)
+	if err != nil {
+		t.Fatalf("RunExperiment error: %v", err)
+	}
+
+	if len(result.Results) != 3 {
+		t.Fatalf("results = %d, want 3", len(result.Results))
+	}
+	for i, q := range []string{"capital of France", "2+2", "largest ocean"} {
+		if result.Results[i].ItemID != s.itemIDs[q] {
+			t.Errorf("result %d ItemID = %q, want the ID of %q", i, result.Results[i].ItemID, q)
+		}
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Index != 2 || !strings.Contains(result.Failed[0].Err.Error(), "no answer") {
+		t.Errorf("failed = %v, want the task error of item 2", result.Failed)
+	}
+	if result.Results[2].Error == nil || len(result.Results[2].Scores) != 0 {
+		t.Errorf("result 2 = %+v, want the task error and no scores", result.Results[2])
+	}
+	if got := result.Results.AverageByMetric("answered"); got != 1 {
+		t.Errorf("AverageByMetric(answered) = %v, want 1", got)
+	}
+
+	// Every item is linked to the trace its task ran in, including the
+	// item whose task failed.
+	if len(s.items()) != 3 {
+		t.Fatalf("experiment items = %d, want 3", len(s.items()))
+	}
+	for _, item := range s.items() {
+		var q string
+		for question, id := range s.itemIDs {
+			if id == item["dataset_item_id"] {
+				q = question
+			}
+		}
+		if traceID, _ := traceIDs.Load(q); traceID == "" || item["trace_id"] != traceID {
+			t.Errorf("item %q trace_id = %v, want the task's trace %v", q, item["trace_id"], traceID)
+		}
+	}
+
+	// BLEU is not scored without an expected output, so only the
+	// "answered" scores of the two answered items are logged.
+	if result.Scores != 2 || len(s.scores()) != 2 {
+		t.Errorf("logged %d scores, server got %d, want 2", result.Scores, len(s.scores()))
+	}
+	for _, score := range s.scores() {
+		if score["name"] != "answered" || score["value"] != 1.0 {
+			t.Errorf("score = %v", score)
+		}
+	}
+	if len(s.updates()) != 1 || s.updates()[0]["status"] != "completed" {
+		t.Fatalf("experiment updates = %v, want it completed", s.updates())
+	}
+	manifest, _ := s.updates()[0]["metadata"].(map[string]any)["manifest"].(map[string]any)
+	if manifest["name"] != "run-1" || manifest["sdk_version"] != Version || manifest["dataset"].(map[string]any)["name"] != "qa" {
+		t.Errorf("manifest = %v", manifest)
+	}
+	if result.Manifest == nil || result.Manifest.Summary["answered"] != 1 || result.Manifest.StartedAt.IsZero() {
+		t.Errorf("result manifest = %+v", result.Manifest)
+	}
+}
+
+func TestRunExperimentCancelled(t *testing.T) {
+	s := newImportServer(t)
+	client, err := NewClient(WithURL(s.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	dataset, err := client.GetDatasetByName(context.Background(), "qa")
+	if err != nil {
+		t.Fatalf("GetDatasetByName error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	task := func(ctx context.Context, item map[string]any) (map[string]any, error) {
+		calls++
+		cancel()
+		return map[string]any{"output": "x"}, nil
+	}
+	result, err := RunExperiment(ctx, client, dataset, task, []evaluation.Metric{heuristic.NewEquals(false)})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunExperiment error = %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Errorf("task called %d times, want 1", calls)
+	}
+	if len(result.Failed) != 2 {
+		t.Errorf("failed = %v, want the two items not run", result.Failed)
+	}
+	statuses := make([]any, len(s.updates()))
+	for i, u := range s.updates() {
+		statuses[i] = u["status"]
+	}
+	if !slices.Equal(statuses, []any{"cancelled"}) {
+		t.Errorf("experiment statuses = %v, want cancelled", statuses)
+	}
+}
+
+func TestRunExperimentInvalid(t *testing.T) {
+	s := newImportServer(t)
+	client, err := NewClient(WithURL(s.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	dataset := &Dataset{client: client, id: "not-a-uuid", name: "qa"}
+	task := func(context.Context, map[string]any) (map[string]any, error) { return nil, nil }
+	metrics := []evaluation.Metric{heuristic.NewEquals(false)}
+
+	tests := []struct {
+		name    string
+		task    evaluation.Task
+		metrics []evaluation.Metric
+		opts    []ExperimentRunOption
+	}{
+		{"nil task", nil, metrics, nil},
+		{"no metrics", task, nil, nil},
+		{"bad concurrency", task, metrics, []ExperimentRunOption{WithRunConcurrency(0)}},
+		{"nil mapper", task, metrics, []ExperimentRunOption{WithRunInputMapper(nil)}},
+		{"bad items filter", task, metrics, []ExperimentRunOption{WithRunItems(WithItemsFilter("data.x", "like", "y"))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := RunExperiment(context.Background(), client, dataset, tt.task, tt.metrics, tt.opts...); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+	if len(s.items()) != 0 || len(s.updates()) != 0 {
+		t.Errorf("server got %d items and %d updates, want none", len(s.items()), len(s.updates()))
+	}
+}
@@ -1,6 +1,7 @@
 package opik
 
 import (
+	"context"
 	"testing"
 )
 
@@ -192,3 +193,33 @@ func TestExperimentOptionsChaining(t *testing.T) {
 		t.Errorf("status = %q, want %q", opts.status, ExperimentStatusRunning)
 	}
 }
+
+func TestExperimentUpdateMetadata(t *testing.T) {
+	s := newImportServer(t)
+	client, err := NewClient(WithURL(s.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	e, err := client.CreateExperiment(ctx, "qa", WithExperimentMetadata(map[string]any{"model": "gpt-4o", "prompt": "v1"}))
+	if err != nil {
+		t.Fatalf("CreateExperiment error: %v", err)
+	}
+
+	if err := e.UpdateMetadata(ctx, map[string]any{"prompt": "v2", "reviewed": true}); err != nil {
+		t.Fatalf("UpdateMetadata error: %v", err)
+	}
+	if len(s.updates()) != 1 {
+		t.Fatalf("updates = %v, want 1", s.updates())
+	}
+	sent := s.updates()[0]["metadata"].(map[string]any)
+	if sent["model"] != "gpt-4o" || sent["prompt"] != "v2" || sent["reviewed"] != true {
+		t.Errorf("sent metadata = %v, want the old and new entries merged", sent)
+	}
+	if _, ok := s.updates()[0]["status"]; ok {
+		t.Errorf("update %v should not change the status", s.updates()[0])
+	}
+	if e.Metadata()["prompt"] != "v2" {
+		t.Errorf("Metadata() = %v, want the update kept", e.Metadata())
+	}
+}
@@ -0,0 +1,119 @@
+package opik
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// exportPageSize is the default page size used to export traces.
+const exportPageSize = 100
+
+// ExportOption configures ExportTraces.
+type ExportOption func(*exportOptions)
+
+type exportOptions struct {
+	pageSize int
+	from     time.Time
+	to       time.Time
+	spans    bool
+}
+
+// WithExportPageSize sets how many traces are fetched per request. The
+// default is 100.
+func WithExportPageSize(size int) ExportOption {
+	return func(o *exportOptions) {
+		if size > 0 {
+			o.pageSize = size
+		}
+	}
+}
+
+// WithExportTimeRange limits the export to traces started between from and
+// to. A zero from is unbounded, and a zero to is the time the export starts.
+func WithExportTimeRange(from, to time.Time) ExportOption {
+	return func(o *exportOptions) {
+		o.from = from
+		o.to = to
+	}
+}
+
+// WithExportSpans sets whether each trace's spans are fetched. It is on by
+// default; turn it off to export trace-level data only, with one request
+// per page instead of one more per trace.
+func WithExportSpans(include bool) ExportOption {
+	return func(o *exportOptions) {
+		o.spans = include
+	}
+}
+
+// ExportTraces returns an iterator over every trace in a project, with its
+// spans and feedback scores, newest first. Pages of traces are fetched as
+// the iterator advances, so a large project is never held in memory at
+// once. An empty projectName exports the client's default project.
+//
+// Traces started after the export begins are not included, so new traffic
+// does not shift the pages being read. If a request fails, the iterator
+// yields the error and stops.
+//
+//	for tree, err := range client.ExportTraces(ctx, "my-project") {
+//		if err != nil {
+//			return err
+//		}
+//		// write tree.Trace, tree.Spans, ...
+//	}
+func (c *Client) ExportTraces(ctx context.Context, projectName string, opts ...ExportOption) iter.Seq2[*TraceTree, error] {
+	options := &exportOptions{pageSize: exportPageSize, spans: true}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if projectName == "" {
+		projectName = c.projectName
+	}
+
+	return func(yield func(*TraceTree, error) bool) {
+		params := api.GetTracesByProjectParams{
+			ProjectName: api.NewOptString(projectName),
+			Size:        api.NewOptInt32(int32(options.pageSize)), //nolint:gosec // G115: size values are bounded by API limits
+		}
+		if !options.from.IsZero() {
+			params.FromTime = api.NewOptDateTime(options.from)
+		}
+		to := options.to
+		if to.IsZero() {
+			to = time.Now()
+		}
+		params.ToTime = api.NewOptDateTime(to)
+
+		read := int64(0)
+		for page := int32(1); ; page++ {
+			params.Page = api.NewOptInt32(page)
+			resp, err := c.apiClient.GetTracesByProject(ctx, params)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, t := range resp.Content {
+				tree := &TraceTree{Trace: traceInfoFromAPI(&t)}
+				if options.spans && t.ID.Set {
+					spans, err := c.traceSpans(ctx, t.ID.Value, t.ProjectID, projectName)
+					if err != nil {
+						yield(nil, err)
+						return
+					}
+					tree.Spans = spans
+					tree.Roots = BuildSpanTree(spans)
+				}
+				if !yield(tree, nil) {
+					return
+				}
+			}
+			read += int64(len(resp.Content))
+			if len(resp.Content) < options.pageSize || (resp.Total.Set && read >= resp.Total.Value) {
+				return
+			}
+		}
+	}
+}
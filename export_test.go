@@ -0,0 +1,129 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestExportTraces(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var traces []map[string]any
+	for i := range 5 {
+		traces = append(traces, map[string]any{
+			"id": uuid.Must(uuid.NewV7()).String(), "name": "trace-" + strconv.Itoa(i), "start_time": base,
+			"tags": []string{"prod"},
+			"feedback_scores": []map[string]any{
+				{"name": "accuracy", "value": float64(i) / 4, "reason": "checked", "source": "sdk"},
+			},
+		})
+	}
+
+	var tracePages, spanRequests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/v1/private/traces":
+			if q.Get("project_name") != "export-me" || q.Get("to_time") == "" {
+				http.Error(w, "bad query "+r.URL.RawQuery, http.StatusBadRequest)
+				return
+			}
+			tracePages.Add(1)
+			page, _ := strconv.Atoi(q.Get("page"))
+			size, _ := strconv.Atoi(q.Get("size"))
+			start := min((page-1)*size, len(traces))
+			end := min(start+size, len(traces))
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"page": page, "size": end - start, "total": len(traces), "content": traces[start:end],
+			})
+		case "/v1/private/spans":
+			spanRequests.Add(1)
+			traceID := q.Get("trace_id")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"page": 1, "size": 2, "total": 2, "content": []map[string]any{
+					{"id": uuid.Must(uuid.NewV7()).String(), "trace_id": traceID, "name": "llm", "type": "llm", "start_time": base,
+						"feedback_scores": []map[string]any{{"name": "relevance", "value": 1, "source": "ui"}}},
+					{"id": uuid.Must(uuid.NewV7()).String(), "trace_id": traceID, "name": "tool", "type": "tool", "start_time": base.Add(time.Second)},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"), WithProjectName("export-me"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	var names []string
+	for tree, err := range client.ExportTraces(ctx, "", WithExportPageSize(2)) {
+		if err != nil {
+			t.Fatalf("ExportTraces error: %v", err)
+		}
+		names = append(names, tree.Trace.Name)
+		if len(tree.Spans) != 2 || len(tree.Roots) != 2 {
+			t.Fatalf("%s: spans = %d, roots = %d, want 2", tree.Trace.Name, len(tree.Spans), len(tree.Roots))
+		}
+		if scores := tree.Spans[0].FeedbackScores; len(scores) != 1 || scores[0].Source != "ui" {
+			t.Errorf("span feedback scores = %+v", scores)
+		}
+	}
+	if strings.Join(names, ",") != "trace-0,trace-1,trace-2,trace-3,trace-4" {
+		t.Errorf("exported %v", names)
+	}
+	if tracePages.Load() != 3 || spanRequests.Load() != 5 {
+		t.Errorf("trace pages = %d, span requests = %d; want 3 and 5", tracePages.Load(), spanRequests.Load())
+	}
+
+	// Stopping early fetches no further pages, and traces alone need no
+	// span requests.
+	tracePages.Store(0)
+	spanRequests.Store(0)
+	for tree, err := range client.ExportTraces(ctx, "export-me", WithExportPageSize(2), WithExportSpans(false)) {
+		if err != nil {
+			t.Fatalf("ExportTraces error: %v", err)
+		}
+		score := tree.Trace.FeedbackScores[0]
+		if score.Name != "accuracy" || score.Reason != "checked" || score.Value != 0 || tree.Trace.Tags[0] != "prod" {
+			t.Errorf("trace = %+v", tree.Trace)
+		}
+		break
+	}
+	if tracePages.Load() != 1 || spanRequests.Load() != 0 {
+		t.Errorf("trace pages = %d, span requests = %d; want 1 and 0", tracePages.Load(), spanRequests.Load())
+	}
+}
+
+func TestExportTracesError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	n := 0
+	for tree, err := range client.ExportTraces(context.Background(), "p") {
+		n++
+		if err == nil || tree != nil {
+			t.Errorf("got %v, %v; want an error", tree, err)
+		}
+	}
+	if n != 1 {
+		t.Errorf("yielded %d times, want 1", n)
+	}
+}
@@ -0,0 +1,223 @@
+package opik
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FeedbackGroupBy splits a feedback summary into groups of traces.
+type FeedbackGroupBy string
+
+// Groupings for FeedbackQuery. Time buckets are in UTC and keyed by their
+// start: "2006-01-02T15" for hours, "2006-01-02" for days, and the date of
+// the Monday for weeks. A trace with several tags is counted in the group
+// of each, and one without tags in the group with an empty key.
+const (
+	GroupByNone FeedbackGroupBy = ""
+	GroupByHour FeedbackGroupBy = "hour"
+	GroupByDay  FeedbackGroupBy = "day"
+	GroupByWeek FeedbackGroupBy = "week"
+	GroupByName FeedbackGroupBy = "name"
+	GroupByTag  FeedbackGroupBy = "tag"
+)
+
+// groupByMetadataPrefix prefixes the key of a GroupByMetadata grouping.
+const groupByMetadataPrefix = "metadata."
+
+// GroupByMetadata groups traces by the value of a top-level metadata key,
+// such as a model or prompt version. Traces without the key are in the
+// group with an empty key.
+func GroupByMetadata(key string) FeedbackGroupBy {
+	return FeedbackGroupBy(groupByMetadataPrefix + key)
+}
+
+// TimeRange is a span of time. A zero From is unbounded, and a zero To is
+// the present.
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// LastDays returns the time range of the n days before now.
+func LastDays(n int) TimeRange {
+	return TimeRange{From: time.Now().AddDate(0, 0, -n)}
+}
+
+// FeedbackQuery selects the feedback scores that FeedbackSummary
+// aggregates.
+type FeedbackQuery struct {
+	// Project is the project whose traces are read; empty is the client's
+	// default project.
+	Project string
+	// ScoreName is the name of the feedback score. It is required.
+	ScoreName string
+	// TimeRange limits the query to traces started within it.
+	TimeRange TimeRange
+	// GroupBy splits the summary into groups, in addition to the overall
+	// statistics.
+	GroupBy FeedbackGroupBy
+}
+
+// FeedbackStats are statistics of a set of feedback score values.
+// Percentiles are nearest-rank. All are zero if Count is.
+type FeedbackStats struct {
+	Count int
+	Mean  float64
+	P50   float64
+	P90   float64
+}
+
+// FeedbackGroup is the statistics of one group of a feedback summary.
+type FeedbackGroup struct {
+	Key string
+	FeedbackStats
+}
+
+// FeedbackSummary is the result of FeedbackSummary: statistics of a
+// feedback score across all matching traces, and per group.
+type FeedbackSummary struct {
+	ScoreName string
+	FeedbackStats
+	// Groups holds the statistics of each group, in order of key, if the
+	// query has a GroupBy. Groups without scores are omitted.
+	Groups []FeedbackGroup
+	// Traces is the number of traces read, scored or not.
+	Traces int
+}
+
+// FeedbackSummary aggregates a feedback score across the traces of a
+// project, so that trends such as user satisfaction per day or per model
+// can be read without exporting traces:
+//
+//	summary, err := client.FeedbackSummary(ctx, opik.FeedbackQuery{
+//	    ScoreName: "user_satisfaction",
+//	    TimeRange: opik.LastDays(30),
+//	    GroupBy:   opik.GroupByDay,
+//	})
+//	for _, day := range summary.Groups {
+//	    fmt.Printf("%s  n=%d  mean=%.2f  p90=%.2f\n", day.Key, day.Count, day.Mean, day.P90)
+//	}
+//
+// The traces are read in pages, as by ExportTraces, and the statistics are
+// computed in the client, so summarizing a busy project over a long range
+// takes as long as exporting it. Span scores are not included.
+func (c *Client) FeedbackSummary(ctx context.Context, query FeedbackQuery) (*FeedbackSummary, error) {
+	if query.ScoreName == "" {
+		return nil, fmt.Errorf("%w: feedback query has no score name", ErrInvalidInput)
+	}
+	groupKeys, err := query.GroupBy.keys()
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &FeedbackSummary{ScoreName: query.ScoreName}
+	var all []float64
+	groups := make(map[string][]float64)
+	traces := c.ExportTraces(ctx, query.Project,
+		WithExportSpans(false),
+		WithExportTimeRange(query.TimeRange.From, query.TimeRange.To),
+	)
+	for tree, err := range traces {
+		if err != nil {
+			return nil, err
+		}
+		summary.Traces++
+		i := slices.IndexFunc(tree.Trace.FeedbackScores, func(s FeedbackScoreInfo) bool {
+			return s.Name == query.ScoreName
+		})
+		if i < 0 {
+			continue
+		}
+		value := tree.Trace.FeedbackScores[i].Value
+		all = append(all, value)
+		if groupKeys != nil {
+			for _, key := range groupKeys(tree.Trace) {
+				groups[key] = append(groups[key], value)
+			}
+		}
+	}
+
+	summary.FeedbackStats = feedbackStats(all)
+	for key, values := range groups {
+		summary.Groups = append(summary.Groups, FeedbackGroup{Key: key, FeedbackStats: feedbackStats(values)})
+	}
+	sort.Slice(summary.Groups, func(i, j int) bool {
+		return summary.Groups[i].Key < summary.Groups[j].Key
+	})
+	return summary, nil
+}
+
+// keys returns the function that gives the group keys of a trace, or nil if
+// g does not group.
+func (g FeedbackGroupBy) keys() (func(*TraceInfo) []string, error) {
+	switch g {
+	case GroupByNone:
+		return nil, nil
+	case GroupByHour:
+		return timeKey(func(t time.Time) time.Time { return t }, "2006-01-02T15"), nil
+	case GroupByDay:
+		return timeKey(func(t time.Time) time.Time { return t }, time.DateOnly), nil
+	case GroupByWeek:
+		return timeKey(func(t time.Time) time.Time {
+			return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+		}, time.DateOnly), nil
+	case GroupByName:
+		return func(t *TraceInfo) []string { return []string{t.Name} }, nil
+	case GroupByTag:
+		return func(t *TraceInfo) []string {
+			if len(t.Tags) == 0 {
+				return []string{""}
+			}
+			return slices.Compact(slices.Sorted(slices.Values(t.Tags)))
+		}, nil
+	}
+	if key, ok := strings.CutPrefix(string(g), groupByMetadataPrefix); ok && key != "" {
+		return func(t *TraceInfo) []string {
+			metadata, _ := t.Metadata.(map[string]any)
+			value, ok := metadata[key]
+			if !ok || value == nil {
+				return []string{""}
+			}
+			return []string{fmt.Sprint(value)}
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown feedback grouping %q", ErrInvalidInput, string(g))
+}
+
+// timeKey returns a group key function that formats the start of the
+// trace's UTC time bucket, as found by start.
+func timeKey(start func(time.Time) time.Time, layout string) func(*TraceInfo) []string {
+	return func(t *TraceInfo) []string {
+		return []string{start(t.StartTime.UTC()).Format(layout)}
+	}
+}
+
+// feedbackStats returns the statistics of values.
+func feedbackStats(values []float64) FeedbackStats {
+	if len(values) == 0 {
+		return FeedbackStats{}
+	}
+	sorted := slices.Sorted(slices.Values(values))
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	return FeedbackStats{
+		Count: len(sorted),
+		Mean:  sum / float64(len(sorted)),
+		P50:   scorePercentile(sorted, 50),
+		P90:   scorePercentile(sorted, 90),
+	}
+}
+
+// scorePercentile returns the nearest-rank percentile of sorted values.
+func scorePercentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = min(max(rank, 1), len(sorted))
+	return sorted[rank-1]
+}
@@ -0,0 +1,135 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFeedbackSummary(t *testing.T) {
+	// Monday 2024-01-01 and the following Monday.
+	mon, nextMon := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)
+	trace := func(start time.Time, model string, tags []string, scores map[string]float64) map[string]any {
+		fs := []map[string]any{}
+		for name, value := range scores {
+			fs = append(fs, map[string]any{"name": name, "value": value, "source": "ui"})
+		}
+		if tags == nil {
+			tags = []string{}
+		}
+		return map[string]any{
+			"id": uuid.Must(uuid.NewV7()).String(), "name": "chat", "start_time": start,
+			"metadata": map[string]any{"model": model}, "tags": tags, "feedback_scores": fs,
+		}
+	}
+	traces := []map[string]any{
+		trace(mon, "gpt-4o", []string{"web"}, map[string]float64{"csat": 1}),
+		trace(mon.Add(time.Hour), "gpt-4o", []string{"web", "beta"}, map[string]float64{"csat": 0.5, "other": 0}),
+		trace(mon.AddDate(0, 0, 2), "gpt-4o-mini", nil, map[string]float64{"csat": 0}),
+		trace(nextMon, "gpt-4o-mini", []string{"web"}, map[string]float64{"csat": 0.25}),
+		trace(nextMon, "gpt-4o", nil, nil),
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v1/private/traces" || q.Get("project_name") != "support" || q.Get("from_time") == "" {
+			http.Error(w, "bad request "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		page, _ := strconv.Atoi(q.Get("page"))
+		size, _ := strconv.Atoi(q.Get("size"))
+		start := min((page-1)*size, len(traces))
+		end := min(start+size, len(traces))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"page": page, "size": end - start, "total": len(traces), "content": traces[start:end]})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	query := func(groupBy FeedbackGroupBy) *FeedbackSummary {
+		t.Helper()
+		summary, err := client.FeedbackSummary(ctx, FeedbackQuery{
+			Project: "support", ScoreName: "csat", TimeRange: TimeRange{From: mon.AddDate(0, 0, -1)}, GroupBy: groupBy,
+		})
+		if err != nil {
+			t.Fatalf("FeedbackSummary(%q) error: %v", groupBy, err)
+		}
+		return summary
+	}
+
+	summary := query(GroupByNone)
+	want := FeedbackStats{Count: 4, Mean: 0.4375, P50: 0.25, P90: 1}
+	if summary.FeedbackStats != want || summary.Traces != 5 || summary.Groups != nil {
+		t.Errorf("summary = %+v, want %+v over 5 traces", summary, want)
+	}
+
+	tests := []struct {
+		groupBy FeedbackGroupBy
+		want    map[string]int
+	}{
+		{GroupByDay, map[string]int{"2024-01-01": 2, "2024-01-03": 1, "2024-01-08": 1}},
+		{GroupByHour, map[string]int{"2024-01-01T09": 1, "2024-01-01T10": 1, "2024-01-03T09": 1, "2024-01-08T09": 1}},
+		{GroupByWeek, map[string]int{"2024-01-01": 3, "2024-01-08": 1}},
+		{GroupByTag, map[string]int{"web": 3, "beta": 1, "": 1}},
+		{GroupByMetadata("model"), map[string]int{"gpt-4o": 2, "gpt-4o-mini": 2}},
+		{GroupByName, map[string]int{"chat": 4}},
+	}
+	for _, tt := range tests {
+		groups := query(tt.groupBy).Groups
+		got := make(map[string]int)
+		for i, g := range groups {
+			got[g.Key] = g.Count
+			if i > 0 && groups[i-1].Key >= g.Key {
+				t.Errorf("%s: groups out of order: %q before %q", tt.groupBy, groups[i-1].Key, g.Key)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: groups = %v, want %v", tt.groupBy, got, tt.want)
+			continue
+		}
+		for key, n := range tt.want {
+			if got[key] != n {
+				t.Errorf("%s: groups = %v, want %v", tt.groupBy, got, tt.want)
+				break
+			}
+		}
+	}
+
+	mean := query(GroupByMetadata("model")).Groups[0]
+	if mean.Key != "gpt-4o" || mean.Mean != 0.75 || mean.P50 != 0.5 {
+		t.Errorf("gpt-4o group = %+v", mean)
+	}
+}
+
+func TestFeedbackSummaryInvalidQuery(t *testing.T) {
+	client, err := NewClient(WithURL("http://localhost"), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	for _, query := range []FeedbackQuery{{}, {ScoreName: "csat", GroupBy: "month"}, {ScoreName: "csat", GroupBy: GroupByMetadata("")}} {
+		if _, err := client.FeedbackSummary(context.Background(), query); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("FeedbackSummary(%+v) error = %v, want ErrInvalidInput", query, err)
+		}
+	}
+}
+
+func TestFeedbackStats(t *testing.T) {
+	values := []float64{9, 1, 2, 3, 4, 5, 6, 7, 8, 10}
+	if got := feedbackStats(values); got != (FeedbackStats{Count: 10, Mean: 5.5, P50: 5, P90: 9}) {
+		t.Errorf("feedbackStats = %+v", got)
+	}
+	if got := feedbackStats(nil); got != (FeedbackStats{}) {
+		t.Errorf("feedbackStats(nil) = %+v, want zero", got)
+	}
+}
@@ -0,0 +1,33 @@
+// Package guardrails validates text before an application uses it, and
+// records each decision in the trace.
+//
+// A Guardrail checks text and returns a Result: whether it passed, a score,
+// and a reason. Check runs a list of guardrails and fails if any of them
+// does, running each in a span of type opik.SpanTypeGuardrail under the
+// span or trace in the context.
+//
+// # Built-in Guardrails
+//
+//   - PII: emails, phone numbers, credit card numbers, SSNs, and IP
+//     addresses, found with regular expressions
+//   - Topic: allowed and denied topics, judged by an LLM
+//   - MaxTokens: a limit on the length of the text
+//
+// FromMetric turns any evaluation metric into a guardrail, so the same
+// llm.Moderation metric that scores an experiment can block a response in
+// production.
+//
+// # Usage Example
+//
+//	inputGuards := []guardrails.Guardrail{
+//	    guardrails.NewPII(),
+//	    guardrails.NewMaxTokens(4000),
+//	    guardrails.NewTopic(provider, []string{"billing", "shipping"}),
+//	}
+//
+//	ctx, span, _ := opik.StartSpan(ctx, "handle-message")
+//	defer span.End(ctx)
+//	if report, err := guardrails.Check(ctx, message, inputGuards...); err != nil {
+//	    return refuse(report.Failed())
+//	}
+package guardrails
@@ -0,0 +1,180 @@
+package guardrails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// ErrFailed is returned by Check when a guardrail fails.
+var ErrFailed = errors.New("guardrails: validation failed")
+
+// Guardrail validates text, such as a user's input before it reaches a
+// model or a model's output before it reaches the user.
+type Guardrail interface {
+	// Name returns the name of the guardrail.
+	Name() string
+	// Validate checks text. It returns an error only if the check could
+	// not be made, not if the text fails it.
+	Validate(ctx context.Context, text string) (*Result, error)
+}
+
+// Result is the outcome of one guardrail.
+type Result struct {
+	// Guardrail is the name of the guardrail.
+	Guardrail string `json:"guardrail"`
+	// Passed reports whether the text passed the guardrail.
+	Passed bool `json:"passed"`
+	// Score is the guardrail's score for the text. For the built-in
+	// guardrails it runs from 0.0, a clear violation, to 1.0, no
+	// violation; for a metric wrapped with FromMetric it is the metric's
+	// score.
+	Score float64 `json:"score"`
+	// Reason explains the result.
+	Reason string `json:"reason,omitempty"`
+	// Details holds guardrail-specific findings, such as the kinds of PII
+	// found. It never holds the matched text.
+	Details map[string]any `json:"details,omitempty"`
+	// Error is set if the guardrail could not check the text.
+	Error error `json:"-"`
+}
+
+// Report is the outcome of Check.
+type Report struct {
+	// Passed reports whether every guardrail passed.
+	Passed bool
+	// Results holds the result of each guardrail, in order.
+	Results []*Result
+}
+
+// Failed returns the results of the guardrails that failed or could not
+// check the text.
+func (r *Report) Failed() []*Result {
+	var failed []*Result
+	for _, result := range r.Results {
+		if !result.Passed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Check runs guardrails on text, in order, and reports whether all of them
+// passed. If one did not, the report is returned with an error wrapping
+// ErrFailed that names the guardrails that failed:
+//
+//	report, err := guardrails.Check(ctx, userInput, guardrails.NewPII(), guardrails.NewMaxTokens(2000))
+//	if errors.Is(err, guardrails.ErrFailed) {
+//	    return refuse(report.Failed())
+//	}
+//
+// Each guardrail runs in a span of type opik.SpanTypeGuardrail, named after
+// the guardrail, under the span or trace in ctx. The span's input is the
+// text and its output is the result, so guardrail decisions appear in the
+// trace next to the steps they protect. Without a trace in ctx, guardrails
+// run untraced.
+//
+// A guardrail that returns an error counts as failed, so Check fails
+// closed; the error is in the guardrail's Result.
+func Check(ctx context.Context, text string, guardrails ...Guardrail) (*Report, error) {
+	report := &Report{Passed: true, Results: make([]*Result, 0, len(guardrails))}
+	var failed []string
+	for _, g := range guardrails {
+		result := run(ctx, g, text)
+		report.Results = append(report.Results, result)
+		if !result.Passed {
+			report.Passed = false
+			failed = append(failed, result.Guardrail)
+		}
+	}
+	if !report.Passed {
+		return report, fmt.Errorf("%w: %s", ErrFailed, strings.Join(failed, ", "))
+	}
+	return report, nil
+}
+
+// run runs one guardrail in a guardrail span, if ctx has a trace.
+func run(ctx context.Context, g Guardrail, text string) *Result {
+	spanCtx, span, err := opik.StartSpan(ctx, g.Name(),
+		opik.WithSpanType(opik.SpanTypeGuardrail),
+		opik.WithSpanInput(map[string]any{"text": text}),
+	)
+	if err != nil {
+		span, spanCtx = nil, ctx
+	}
+
+	result, err := g.Validate(spanCtx, text)
+	if err != nil {
+		result = &Result{Reason: err.Error(), Error: err}
+	}
+	if result == nil {
+		result = &Result{}
+	}
+	result.Guardrail = g.Name()
+
+	if span != nil {
+		output := map[string]any{"passed": result.Passed, "score": result.Score}
+		if result.Reason != "" {
+			output["reason"] = result.Reason
+		}
+		var opts []opik.SpanOption
+		if len(result.Details) > 0 {
+			opts = append(opts, opik.WithSpanMetadata(result.Details))
+		}
+		_ = span.End(spanCtx, append(opts, opik.WithSpanOutput(output))...)
+	}
+	return result
+}
+
+// FromMetric turns an evaluation metric into a guardrail, so a metric such
+// as llm.Moderation can block text as well as score it. The text is the
+// metric input's Output, and the guardrail passes when pass accepts the
+// metric's score:
+//
+//	moderation := guardrails.FromMetric(llm.NewModeration(provider), guardrails.AtMost(0.3))
+//
+// A metric that fails makes the guardrail return its error; a metric that
+// does not score the text passes it.
+func FromMetric(metric evaluation.Metric, pass func(score float64) bool) Guardrail {
+	return &metricGuardrail{metric: metric, pass: pass}
+}
+
+// AtLeast accepts scores of at least min, for metrics where higher is
+// better.
+func AtLeast(min float64) func(float64) bool {
+	return func(score float64) bool { return score >= min }
+}
+
+// AtMost accepts scores of at most max, for metrics where higher is worse,
+// such as llm.Moderation.
+func AtMost(max float64) func(float64) bool {
+	return func(score float64) bool { return score <= max }
+}
+
+type metricGuardrail struct {
+	metric evaluation.Metric
+	pass   func(float64) bool
+}
+
+func (g *metricGuardrail) Name() string {
+	return g.metric.Name()
+}
+
+func (g *metricGuardrail) Validate(ctx context.Context, text string) (*Result, error) {
+	score := g.metric.Score(ctx, evaluation.MetricInput{Output: text})
+	if score.IsNotScored() {
+		return &Result{Passed: true, Reason: score.Reason}, nil
+	}
+	if score.Error != nil {
+		return nil, score.Error
+	}
+	var details map[string]any
+	if len(score.SubScores) > 0 {
+		details = map[string]any{"sub_scores": score.SubScores}
+	}
+	return &Result{Passed: g.pass(score.Value), Score: score.Value, Reason: score.Reason, Details: details}, nil
+}
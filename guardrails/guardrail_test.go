@@ -0,0 +1,143 @@
+package guardrails
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// staticGuardrail returns a fixed result or error.
+type staticGuardrail struct {
+	name   string
+	result *Result
+	err    error
+}
+
+func (g *staticGuardrail) Name() string { return g.name }
+
+func (g *staticGuardrail) Validate(context.Context, string) (*Result, error) {
+	return g.result, g.err
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	pass := &staticGuardrail{name: "pass", result: &Result{Passed: true, Score: 1}}
+	fail := &staticGuardrail{name: "fail", result: &Result{Reason: "blocked"}}
+	broken := &staticGuardrail{name: "broken", err: errors.New("judge unavailable")}
+
+	report, err := Check(ctx, "text", pass)
+	if err != nil || !report.Passed || len(report.Failed()) != 0 {
+		t.Errorf("passing check = %+v, %v", report, err)
+	}
+
+	report, err = Check(ctx, "text", pass, fail, broken)
+	if !errors.Is(err, ErrFailed) || err.Error() != "guardrails: validation failed: fail, broken" {
+		t.Errorf("error = %v", err)
+	}
+	if report.Passed || len(report.Results) != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+	failed := report.Failed()
+	if len(failed) != 2 || failed[0].Guardrail != "fail" || failed[1].Guardrail != "broken" {
+		t.Errorf("failed = %+v", failed)
+	}
+	if failed[1].Error == nil || failed[1].Reason != "judge unavailable" {
+		t.Errorf("broken result = %+v, want its error", failed[1])
+	}
+}
+
+func TestCheckSpans(t *testing.T) {
+	type span struct {
+		Name   string         `json:"name"`
+		Type   string         `json:"type"`
+		Input  map[string]any `json:"input"`
+		Output map[string]any `json:"output"`
+	}
+	var mu sync.Mutex
+	var created []span
+	var ended []span
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Spans  []span `json:"spans"`
+			Update span   `json:"update"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/v1/private/spans/batch" && r.Method == http.MethodPatch:
+			ended = append(ended, body.Update)
+		case r.URL.Path == "/v1/private/spans/batch":
+			created = append(created, body.Spans...)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	client, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx, _, err := opik.StartTrace(context.Background(), client, "chat")
+	if err != nil {
+		t.Fatalf("StartTrace error: %v", err)
+	}
+
+	if _, err := Check(ctx, "mail me at a@example.com", NewPII()); !errors.Is(err, ErrFailed) {
+		t.Fatalf("Check error = %v, want ErrFailed", err)
+	}
+	if err := client.Flush(ctx); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(created) != 1 || created[0].Name != "pii" || created[0].Type != opik.SpanTypeGuardrail {
+		t.Fatalf("created spans = %+v", created)
+	}
+	if created[0].Input["text"] != "mail me at a@example.com" {
+		t.Errorf("span input = %v", created[0].Input)
+	}
+	if len(ended) != 1 || ended[0].Output["passed"] != false || ended[0].Output["reason"] != "found email (1)" {
+		t.Errorf("span output = %+v", ended)
+	}
+}
+
+func TestFromMetric(t *testing.T) {
+	ctx := context.Background()
+	score := 0.4
+	metric := evaluation.NewMetricFunc("toxicity", func(_ context.Context, input evaluation.MetricInput) *evaluation.ScoreResult {
+		if input.Output == "" {
+			return evaluation.NewNotScoredResult("toxicity", "no text")
+		}
+		if input.Output == "error" {
+			return evaluation.NewFailedScoreResult("toxicity", errors.New("judge failed"))
+		}
+		return evaluation.NewScoreResultWithReason("toxicity", score, "mild")
+	})
+
+	g := FromMetric(metric, AtMost(0.3))
+	if g.Name() != "toxicity" {
+		t.Errorf("Name = %q", g.Name())
+	}
+	result, err := g.Validate(ctx, "some text")
+	if err != nil || result.Passed || result.Score != 0.4 || result.Reason != "mild" {
+		t.Errorf("result = %+v, %v", result, err)
+	}
+	if result, _ := FromMetric(metric, AtLeast(0.4)).Validate(ctx, "some text"); !result.Passed {
+		t.Error("AtLeast(0.4) should accept 0.4")
+	}
+	if result, err := g.Validate(ctx, ""); err != nil || !result.Passed {
+		t.Errorf("not scored = %+v, %v, want passed", result, err)
+	}
+	if _, err := g.Validate(ctx, "error"); err == nil {
+		t.Error("failed metric should return an error")
+	}
+}
@@ -0,0 +1,58 @@
+package guardrails
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxTokens fails text longer than a token limit, such as a prompt that
+// would not fit the model's context window or an input large enough to be
+// an abuse of the application.
+type MaxTokens struct {
+	limit int
+	count func(string) int
+}
+
+// NewMaxTokens creates a guardrail that fails text of more than limit
+// tokens. Tokens are estimated at four bytes each unless a tokenizer is
+// set with WithTokenCounter.
+func NewMaxTokens(limit int) *MaxTokens {
+	return &MaxTokens{limit: limit, count: estimateTokens}
+}
+
+// WithTokenCounter sets the function that counts the tokens in text, such
+// as the model's own tokenizer.
+func (g *MaxTokens) WithTokenCounter(count func(text string) int) *MaxTokens {
+	g.count = count
+	return g
+}
+
+// Name returns "max_tokens".
+func (g *MaxTokens) Name() string {
+	return "max_tokens"
+}
+
+// Validate fails text of more than the limit of tokens. The score falls
+// from 1.0 at the limit in proportion to the excess, so text twice the
+// limit scores 0.5.
+func (g *MaxTokens) Validate(ctx context.Context, text string) (*Result, error) {
+	if g.limit <= 0 {
+		return nil, fmt.Errorf("max_tokens: limit must be positive, got %d", g.limit)
+	}
+	tokens := g.count(text)
+	details := map[string]any{"tokens": tokens, "limit": g.limit}
+	if tokens <= g.limit {
+		return &Result{Passed: true, Score: 1, Reason: fmt.Sprintf("%d tokens, within the limit of %d", tokens, g.limit), Details: details}, nil
+	}
+	return &Result{
+		Score:   float64(g.limit) / float64(tokens),
+		Reason:  fmt.Sprintf("%d tokens, over the limit of %d", tokens, g.limit),
+		Details: details,
+	}, nil
+}
+
+// estimateTokens estimates the number of tokens in text at four bytes per
+// token, the estimate the LLM judges use for context windows.
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
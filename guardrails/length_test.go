@@ -0,0 +1,33 @@
+package guardrails
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMaxTokens(t *testing.T) {
+	ctx := context.Background()
+	g := NewMaxTokens(10)
+
+	result, err := g.Validate(ctx, strings.Repeat("a", 40))
+	if err != nil || !result.Passed || result.Score != 1 {
+		t.Errorf("at the limit = %+v, %v", result, err)
+	}
+	result, err = g.Validate(ctx, strings.Repeat("a", 80))
+	if err != nil || result.Passed || result.Score != 0.5 {
+		t.Errorf("twice the limit = %+v, %v", result, err)
+	}
+	if result.Details["tokens"] != 20 || result.Details["limit"] != 10 {
+		t.Errorf("details = %v", result.Details)
+	}
+
+	words := NewMaxTokens(2).WithTokenCounter(func(text string) int { return len(strings.Fields(text)) })
+	if result, _ := words.Validate(ctx, "three short words"); result.Passed {
+		t.Error("custom counter should count three tokens")
+	}
+
+	if _, err := NewMaxTokens(0).Validate(ctx, "text"); err == nil {
+		t.Error("zero limit should be an error")
+	}
+}
@@ -0,0 +1,101 @@
+package guardrails
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/plexusone/opik-go/internal/pii"
+)
+
+// PIIEntity is a kind of personally identifiable information.
+type PIIEntity string
+
+// PII entities detected by the PII guardrail.
+const (
+	PIIEmail      PIIEntity = "email"
+	PIIPhone      PIIEntity = "phone"
+	PIICreditCard PIIEntity = "credit_card"
+	PIISSN        PIIEntity = "ssn"
+	PIIIPAddress  PIIEntity = "ip_address"
+)
+
+var piiPatterns = map[PIIEntity]*regexp.Regexp{
+	PIIEmail:      pii.Email,
+	PIIPhone:      pii.Phone,
+	PIICreditCard: pii.CreditCard,
+	PIISSN:        pii.SSN,
+	PIIIPAddress:  pii.IPAddress,
+}
+
+// piiOrder is the order in which built-in entities are checked and reported.
+var piiOrder = []PIIEntity{PIIEmail, PIIPhone, PIICreditCard, PIISSN, PIIIPAddress}
+
+// PII fails text that contains personally identifiable information, found
+// with regular expressions. Credit card numbers must also pass the Luhn
+// check, so order and tracking numbers of the same length are not reported.
+type PII struct {
+	entities []PIIEntity
+	patterns map[PIIEntity]*regexp.Regexp
+}
+
+// NewPII creates a PII guardrail that detects the given entities, or all
+// built-in entities if none are given.
+func NewPII(entities ...PIIEntity) *PII {
+	if len(entities) == 0 {
+		entities = piiOrder
+	}
+	g := &PII{patterns: make(map[PIIEntity]*regexp.Regexp)}
+	for _, entity := range entities {
+		if pattern, ok := piiPatterns[entity]; ok {
+			g.entities = append(g.entities, entity)
+			g.patterns[entity] = pattern
+		}
+	}
+	return g
+}
+
+// WithPattern adds a custom entity, such as an employee ID, detected with
+// pattern.
+func (g *PII) WithPattern(entity PIIEntity, pattern *regexp.Regexp) *PII {
+	if _, ok := g.patterns[entity]; !ok {
+		g.entities = append(g.entities, entity)
+	}
+	g.patterns[entity] = pattern
+	return g
+}
+
+// Name returns "pii".
+func (g *PII) Name() string {
+	return "pii"
+}
+
+// Validate fails text that contains any of the guardrail's entities. The
+// result's Details count the matches of each entity under "entities"; the
+// matched text is not included.
+func (g *PII) Validate(ctx context.Context, text string) (*Result, error) {
+	found := make(map[string]int)
+	var summary []string
+	for _, entity := range g.entities {
+		n := 0
+		for _, match := range g.patterns[entity].FindAllString(text, -1) {
+			if entity == PIICreditCard && !pii.Luhn(match) {
+				continue
+			}
+			n++
+		}
+		if n > 0 {
+			found[string(entity)] = n
+			summary = append(summary, fmt.Sprintf("%s (%d)", entity, n))
+		}
+	}
+	if len(found) == 0 {
+		return &Result{Passed: true, Score: 1, Reason: "no PII found"}, nil
+	}
+	return &Result{
+		Score:   0,
+		Reason:  "found " + strings.Join(summary, ", "),
+		Details: map[string]any{"entities": found},
+	}, nil
+}
@@ -0,0 +1,63 @@
+package guardrails
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestPII(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want map[string]int
+	}{
+		{"clean", "Order 12345 shipped on 2024-05-01.", nil},
+		{"email", "Write to jane.doe@example.com or ops@example.org", map[string]int{"email": 2}},
+		{"phone", "Call +1 (555) 123-4567 or 555-123-4567.", map[string]int{"phone": 2}},
+		{"credit card", "Card: 4111 1111 1111 1111", map[string]int{"credit_card": 1}},
+		{"not a card number", "Tracking 4111 1111 1111 1112", nil},
+		{"ssn", "SSN 123-45-6789", map[string]int{"ssn": 1}},
+		{"ip address", "from 192.168.1.10, not 999.1.1.1", map[string]int{"ip_address": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewPII().Validate(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("Validate error: %v", err)
+			}
+			if result.Passed != (tt.want == nil) {
+				t.Fatalf("Passed = %v, reason %q", result.Passed, result.Reason)
+			}
+			if tt.want == nil {
+				if result.Score != 1 {
+					t.Errorf("Score = %v, want 1", result.Score)
+				}
+				return
+			}
+			if got := result.Details["entities"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entities = %v, want %v", got, tt.want)
+			}
+			if result.Score != 0 {
+				t.Errorf("Score = %v, want 0", result.Score)
+			}
+		})
+	}
+}
+
+func TestPIIEntities(t *testing.T) {
+	ctx := context.Background()
+	text := "Call 555-123-4567, employee E-12345"
+
+	result, _ := NewPII(PIIEmail).Validate(ctx, text)
+	if !result.Passed {
+		t.Errorf("email-only guardrail failed: %s", result.Reason)
+	}
+
+	g := NewPII(PIIEmail).WithPattern("employee_id", regexp.MustCompile(`\bE-\d{5}\b`))
+	result, _ = g.Validate(ctx, text)
+	if result.Passed || result.Reason != "found employee_id (1)" {
+		t.Errorf("custom pattern result = %+v", result)
+	}
+}
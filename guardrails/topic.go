@@ -0,0 +1,107 @@
+package guardrails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/evaluation/llm"
+)
+
+// Topic fails text that strays from the allowed topics or touches a denied
+// one, as judged by an LLM. It keeps an assistant on its subject, such as a
+// support bot that should not give legal advice.
+type Topic struct {
+	judge     *llm.BaseJudge
+	allowed   []string
+	denied    []string
+	threshold float64
+}
+
+// NewTopic creates a topic guardrail that allows the given topics. Judge
+// options such as llm.WithJudgeModel configure the judge, and
+// llm.WithJudgePromptRef replaces its prompt; the prompt template gets the
+// topic lists as {{allowed_topics}} and {{denied_topics}}.
+func NewTopic(provider llm.Provider, allowed []string, opts ...llm.JudgeOption) *Topic {
+	return &Topic{
+		judge:     llm.NewBaseJudge("topic", provider, opts...),
+		allowed:   allowed,
+		threshold: 0.5,
+	}
+}
+
+// WithDeniedTopics sets topics the text must not touch, even if it is
+// otherwise on an allowed topic. A guardrail with only denied topics
+// allows any other topic.
+func (g *Topic) WithDeniedTopics(topics ...string) *Topic {
+	g.denied = topics
+	return g
+}
+
+// WithThreshold sets the lowest judge score that passes; the default is 0.5.
+func (g *Topic) WithThreshold(threshold float64) *Topic {
+	g.threshold = threshold
+	return g
+}
+
+// Name returns "topic".
+func (g *Topic) Name() string {
+	return "topic"
+}
+
+// Validate asks the judge whether text is on topic. It returns an error if
+// the guardrail has no topics, the judge is misconfigured, or the judge
+// cannot be reached.
+func (g *Topic) Validate(ctx context.Context, text string) (*Result, error) {
+	if len(g.allowed) == 0 && len(g.denied) == 0 {
+		return nil, errors.New("topic: no allowed or denied topics")
+	}
+	if err := g.judge.Validate(); err != nil {
+		return nil, err
+	}
+
+	allowed, denied := topicList(g.allowed, "any topic"), topicList(g.denied, "none")
+	prompt := fmt.Sprintf(`You are a topic guardrail for an AI application. Decide whether the text stays within the allowed topics and avoids the denied topics.
+
+Allowed topics:
+%s
+Denied topics:
+%s
+Text: %s
+
+Return your response in JSON format:
+{"score": <0.0-1.0>, "reason": "<the text's topic and why it is or is not allowed>"}
+
+Where:
+- 1.0: The text is about an allowed topic and touches no denied topic
+- 0.5: The text drifts from the allowed topics
+- 0.0: The text is off topic or touches a denied topic`, allowed, denied, text)
+
+	input := evaluation.MetricInput{Output: text}
+	messages, err := g.judge.PromptMessages(ctx, prompt, input, map[string]string{
+		"allowed_topics": allowed,
+		"denied_topics":  denied,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sr, err := llm.ScoreWithRetry(ctx, g.judge, messages, 3)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Passed: sr.Score >= g.threshold, Score: sr.Score, Reason: sr.Reason}, nil
+}
+
+// topicList formats topics as a bulleted list, or none if there are none.
+func topicList(topics []string, none string) string {
+	if len(topics) == 0 {
+		return "- " + none + "\n"
+	}
+	var b strings.Builder
+	for _, topic := range topics {
+		fmt.Fprintf(&b, "- %s\n", topic)
+	}
+	return b.String()
+}
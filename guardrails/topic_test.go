@@ -0,0 +1,53 @@
+package guardrails
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/opik-go/evaluation/llm"
+)
+
+func TestTopic(t *testing.T) {
+	var prompt string
+	provider := llm.NewSimpleProvider("test", "model", func(_ context.Context, req llm.CompletionRequest) (*llm.CompletionResponse, error) {
+		prompt = req.Messages[len(req.Messages)-1].Content
+		return &llm.CompletionResponse{Content: `{"score": 0.2, "reason": "asks for legal advice"}`}, nil
+	})
+	g := NewTopic(provider, []string{"billing", "shipping"}).WithDeniedTopics("legal advice")
+
+	result, err := g.Validate(context.Background(), "Can I sue my landlord?")
+	if err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	if result.Passed || result.Score != 0.2 || result.Reason != "asks for legal advice" {
+		t.Errorf("result = %+v", result)
+	}
+	for _, want := range []string{"- billing\n- shipping", "- legal advice", "Can I sue my landlord?"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt does not contain %q:\n%s", want, prompt)
+		}
+	}
+
+	if result, _ := g.WithThreshold(0.1).Validate(context.Background(), "text"); !result.Passed {
+		t.Error("score above the threshold should pass")
+	}
+}
+
+func TestTopicErrors(t *testing.T) {
+	ctx := context.Background()
+	provider := llm.NewSimpleProvider("test", "model", func(context.Context, llm.CompletionRequest) (*llm.CompletionResponse, error) {
+		return nil, errors.New("unavailable")
+	})
+
+	if _, err := NewTopic(provider, nil).Validate(ctx, "text"); err == nil {
+		t.Error("guardrail without topics should return an error")
+	}
+	if _, err := NewTopic(provider, []string{"billing"}).Validate(ctx, "text"); err == nil {
+		t.Error("provider error should be returned")
+	}
+	if _, err := NewTopic(nil, []string{"billing"}).Validate(ctx, "text"); err == nil {
+		t.Error("judge without a provider should return an error")
+	}
+}
@@ -0,0 +1,89 @@
+package opik
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// indexedTagSeparator separates the metadata key from its value in index tags.
+const indexedTagSeparator = ":"
+
+// WithIndexedMetadata marks metadata keys as indexed.
+//
+// Tags are the filterable field the Opik API indexes for traces and spans, so
+// for each indexed key present in the metadata of a new trace or span, a
+// "key:value" tag is added alongside the metadata entry. This keeps lookups by
+// high-cardinality business keys (customer IDs, tenant IDs, order numbers)
+// fast as trace volume grows:
+//
+//	client, _ := opik.NewClient(opik.WithIndexedMetadata("customer_id"))
+//	trace, _ := client.Trace(ctx, "checkout",
+//	    opik.WithTraceMetadata(map[string]any{"customer_id": "c-123"}))
+//	// trace is tagged "customer_id:c-123"
+//
+// Only scalar values (strings, numbers, and booleans) are indexed. Keys are
+// indexed from the metadata passed when the trace or span is created.
+func WithIndexedMetadata(keys ...string) Option {
+	return func(o *clientOptions) {
+		o.indexedMetadata = append(o.indexedMetadata, keys...)
+	}
+}
+
+// IndexedMetadataKeys returns a copy of the metadata keys marked as indexed.
+func (c *Client) IndexedMetadataKeys() []string {
+	return slices.Clone(c.indexedMetadata)
+}
+
+// indexedTags returns tags with a "key:value" tag appended for each indexed
+// key found in metadata. Tags that are already present are not duplicated.
+func indexedTags(keys []string, metadata map[string]any, tags []string) []string {
+	if len(keys) == 0 || len(metadata) == 0 {
+		return tags
+	}
+
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		seen[t] = true
+	}
+
+	var extra []string
+	for _, key := range keys {
+		value, ok := metadata[key]
+		if !ok {
+			continue
+		}
+		s, ok := indexValue(value)
+		if !ok {
+			continue
+		}
+		tag := key + indexedTagSeparator + s
+		if !seen[tag] {
+			seen[tag] = true
+			extra = append(extra, tag)
+		}
+	}
+	if len(extra) == 0 {
+		return tags
+	}
+	sort.Strings(extra)
+
+	result := make([]string, 0, len(tags)+len(extra))
+	result = append(result, tags...)
+	return append(result, extra...)
+}
+
+// indexValue formats a scalar metadata value for use in an index tag.
+func indexValue(v any) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return val, val != ""
+	case bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(val), true
+	case fmt.Stringer:
+		return val.String(), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,93 @@
+package opik
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestIndexedTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		metadata map[string]any
+		tags     []string
+		want     []string
+	}{
+		{
+			name:     "no indexed keys",
+			metadata: map[string]any{"customer_id": "c-1"},
+			tags:     []string{"prod"},
+			want:     []string{"prod"},
+		},
+		{
+			name:     "string and number values",
+			keys:     []string{"customer_id", "order"},
+			metadata: map[string]any{"customer_id": "c-1", "order": 42, "other": "x"},
+			tags:     []string{"prod"},
+			want:     []string{"prod", "customer_id:c-1", "order:42"},
+		},
+		{
+			name:     "missing and non-scalar values skipped",
+			keys:     []string{"customer_id", "items", "empty"},
+			metadata: map[string]any{"items": []string{"a"}, "empty": ""},
+			want:     nil,
+		},
+		{
+			name:     "existing tag not duplicated",
+			keys:     []string{"tenant"},
+			metadata: map[string]any{"tenant": "acme"},
+			tags:     []string{"tenant:acme"},
+			want:     []string{"tenant:acme"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := indexedTags(tt.keys, tt.metadata, tt.tags)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("indexedTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithIndexedMetadata(t *testing.T) {
+	ts, created := newCreateServer()
+	defer ts.Close()
+
+	client, err := NewClient(
+		WithURL(ts.URL()),
+		WithAPIKey("test-key"),
+		WithIndexedMetadata("customer_id"),
+	)
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	if keys := client.IndexedMetadataKeys(); len(keys) != 1 || keys[0] != "customer_id" {
+		t.Errorf("IndexedMetadataKeys() = %v, want [customer_id]", keys)
+	} else {
+		keys[0] = "changed"
+		if got := client.IndexedMetadataKeys(); got[0] != "customer_id" {
+			t.Errorf("changing the returned keys changed the client's: %v", got)
+		}
+	}
+
+	trace, err := client.Trace(context.Background(), "checkout",
+		WithTraceMetadata(map[string]any{"customer_id": "c-123"}),
+		WithTraceTags("prod"),
+	)
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+
+	want := []string{"prod", "customer_id:c-123"}
+	if !reflect.DeepEqual(trace.tags, want) {
+		t.Errorf("tags = %v, want %v", trace.tags, want)
+	}
+
+	if tags := created("POST /v1/private/traces/batch").Tags; !reflect.DeepEqual(tags, want) {
+		t.Errorf("request tags = %v, want %v", tags, want)
+	}
+}
@@ -0,0 +1,510 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// nullJSONWrite is the JSON null written for empty fields. It is shared by
+// every write, so it must not be modified.
+var nullJSONWrite = api.JsonListStringWrite("null")
+
+// WithBatching queues the creates and updates of traces and spans in the
+// client and sends them in batches from a background goroutine, so
+// high-throughput services do not pay an HTTP round trip per span. Batches
+// are sent every config.FlushInterval, or sooner once config.MaxBatchSize
+// writes are queued; config.MaxRetries and config.RetryDelay apply to each
+// batch. Updates to a trace or span that has not been sent yet are merged
+// into its create, so a span that starts and ends between flushes costs no
+// request of its own.
+//
+// When config.MaxQueueDepth writes are waiting, Trace, Span, End, and Update
+// block until a flush makes room or their context is done. Errors from
+// background flushes are returned by the next Flush. Call Close, or at least
+// Flush, before the program exits, or queued writes are lost.
+func WithBatching(config BatcherConfig) Option {
+	return func(o *clientOptions) {
+		if config.MaxBatchSize <= 0 {
+			o.problems.Addf("batching MaxBatchSize must be positive: %d", config.MaxBatchSize)
+		}
+		if config.FlushInterval <= 0 {
+			o.problems.Addf("batching FlushInterval must be positive: %v", config.FlushInterval)
+		}
+		if config.MaxQueueDepth < 0 {
+			o.problems.Addf("batching MaxQueueDepth must not be negative: %d", config.MaxQueueDepth)
+		}
+		if config.MaxQueueDepth == 0 {
+			config.MaxQueueDepth = 10 * config.MaxBatchSize
+		}
+		o.batching = &config
+	}
+}
+
+// Flush sends every queued trace and span write and waits until they are
+// sent or ctx is done. It returns the errors of this flush and of any
+// background flush since the last call. Without WithBatching, writes are
+// sent as they are made and Flush returns nil.
+func (c *Client) Flush(ctx context.Context) error {
+	if c.ingest == nil {
+		return nil
+	}
+	return c.ingest.flushAndReport(ctx, false)
+}
+
+// writeTrace creates a trace, or queues its create when batching.
+func (c *Client) writeTrace(ctx context.Context, write api.TraceWrite) error {
+	if c.ingest.running() {
+		if err := c.ingest.addTrace(ctx, write); !errors.Is(err, errIngestClosed) {
+			return err
+		}
+	}
+	err := c.apiClient.CreateTraces(ctx, api.NewOptTraceBatchWrite(api.TraceBatchWrite{Traces: []api.TraceWrite{write}}))
+	if c.auditLog != nil { // formatting the ID allocates
+		c.audit(AuditCreate, AuditEntityTrace, write.ID.Value.String(), "", err)
+	}
+	return err
+}
+
+// writeSpan creates a span, or queues its create when batching.
+func (c *Client) writeSpan(ctx context.Context, write api.SpanWrite) error {
+	if c.ingest.running() {
+		if err := c.ingest.addSpan(ctx, write); !errors.Is(err, errIngestClosed) {
+			return err
+		}
+	}
+	err := c.apiClient.CreateSpans(ctx, api.NewOptSpanBatchWrite(api.SpanBatchWrite{Spans: []api.SpanWrite{write}}))
+	if c.auditLog != nil { // formatting the ID allocates
+		c.audit(AuditCreate, AuditEntitySpan, write.ID.Value.String(), "", err)
+	}
+	return err
+}
+
+// updateTrace updates a trace, or queues the update when batching.
+func (c *Client) updateTrace(ctx context.Context, id uuid.UUID, update api.TraceUpdate) error {
+	if c.ingest.running() {
+		if err := c.ingest.updateTrace(ctx, id, update); !errors.Is(err, errIngestClosed) {
+			return err
+		}
+	}
+	_, err := c.apiClient.BatchUpdateTraces(ctx, api.NewOptTraceBatchUpdate(api.TraceBatchUpdate{Ids: []uuid.UUID{id}, Update: update}))
+	c.audit(AuditUpdate, AuditEntityTrace, id.String(), "", err)
+	return err
+}
+
+// updateSpan updates a span, or queues the update when batching.
+func (c *Client) updateSpan(ctx context.Context, id uuid.UUID, update api.SpanUpdate) error {
+	if c.ingest.running() {
+		if err := c.ingest.updateSpan(ctx, id, update); !errors.Is(err, errIngestClosed) {
+			return err
+		}
+	}
+	_, err := c.apiClient.BatchUpdateSpans(ctx, api.NewOptSpanBatchUpdate(api.SpanBatchUpdate{Ids: []uuid.UUID{id}, Update: update}))
+	c.audit(AuditUpdate, AuditEntitySpan, id.String(), "", err)
+	return err
+}
+
+// ingestQueue holds the trace and span writes of a client created with
+// WithBatching. Flushes are serialized, so the update of an entity whose
+// create is being sent goes out in the next flush, after the create.
+type ingestQueue struct {
+	client *Client
+	config BatcherConfig
+	batch  batchConfig
+
+	mu           sync.Mutex
+	traces       []api.TraceWrite
+	spans        []api.SpanWrite
+	traceIndex   map[uuid.UUID]int
+	spanIndex    map[uuid.UUID]int
+	traceUpdates []entityUpdate[api.TraceUpdate]
+	spanUpdates  []entityUpdate[api.SpanUpdate]
+	// room is closed, and replaced, whenever a flush takes the queue.
+	room chan struct{}
+	// errs are the errors of background flushes not yet reported by Flush.
+	errs []error
+
+	// closed is set when Close takes the queue for its final flush; later
+	// writes are sent synchronously, once drained is closed.
+	closed bool
+
+	sending   sync.Mutex
+	kick      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	stopOnce  sync.Once
+	drained   chan struct{}
+	drainOnce sync.Once
+}
+
+type entityUpdate[T any] struct {
+	id     uuid.UUID
+	update T
+}
+
+func newIngestQueue(client *Client, config BatcherConfig) *ingestQueue {
+	batch := client.batchConfig
+	batch.maxItems = config.MaxBatchSize
+	batch.maxRetries = config.MaxRetries
+	batch.retryDelay = config.RetryDelay
+	q := &ingestQueue{
+		client:     client,
+		config:     config,
+		batch:      batch,
+		traceIndex: make(map[uuid.UUID]int),
+		spanIndex:  make(map[uuid.UUID]int),
+		room:       make(chan struct{}),
+		kick:       make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		drained:    make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// errIngestClosed is returned by the queue's writes once Close has taken
+// the queue, after its final flush is done, so the write is sent
+// synchronously instead.
+var errIngestClosed = errors.New("opik: batching queue is closed")
+
+// running reports whether writes should be queued. A nil queue, or one
+// drained by Close, sends writes synchronously.
+func (q *ingestQueue) running() bool {
+	if q == nil {
+		return false
+	}
+	select {
+	case <-q.drained:
+		return false
+	default:
+		return true
+	}
+}
+
+// close stops the background flushes, waits for the one in progress, if
+// any, and sends everything queued. Writes keep going through the queue
+// until this final flush takes it, and those made later are sent
+// synchronously once it is done, so an update never overtakes the create
+// it applies to. It is safe to call more than once.
+func (q *ingestQueue) close(ctx context.Context) error {
+	q.stopOnce.Do(func() {
+		close(q.stop)
+		<-q.done
+	})
+	return q.flushAndReport(ctx, true)
+}
+
+// closedLocked reports whether Close has taken the queue. q.mu must be
+// held, and is released if it has, after the final flush is done.
+func (q *ingestQueue) closedLocked() bool {
+	if !q.closed {
+		return false
+	}
+	q.mu.Unlock()
+	<-q.drained
+	return true
+}
+
+// run flushes the queue every flush interval, and whenever it is kicked
+// because a full batch is waiting.
+func (q *ingestQueue) run() {
+	defer close(q.done)
+	ticker := time.NewTicker(q.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-ticker.C:
+		case <-q.kick:
+		}
+		if err := q.flush(context.Background(), false); err != nil {
+			q.mu.Lock()
+			q.errs = append(q.errs, err)
+			q.mu.Unlock()
+		}
+	}
+}
+
+// depth returns the number of queued writes. q.mu must be held.
+func (q *ingestQueue) depth() int {
+	return len(q.traces) + len(q.spans) + len(q.traceUpdates) + len(q.spanUpdates)
+}
+
+// reserve waits until the queue has room for one more write, and returns
+// with q.mu held unless it fails. It returns errIngestClosed once Close has
+// taken the queue.
+func (q *ingestQueue) reserve(ctx context.Context) error {
+	q.mu.Lock()
+	for {
+		if q.closedLocked() {
+			return errIngestClosed
+		}
+		if q.depth() < q.config.MaxQueueDepth {
+			return nil
+		}
+		room := q.room
+		q.mu.Unlock()
+		q.wake()
+		select {
+		case <-room:
+		case <-ctx.Done():
+			return fmt.Errorf("opik: batching queue is full: %w", ctx.Err())
+		}
+		q.mu.Lock()
+	}
+}
+
+// added wakes the flusher once a full batch is waiting. q.mu must be held,
+// and is released.
+func (q *ingestQueue) added() {
+	full := len(q.traces)+len(q.spans) >= q.config.MaxBatchSize
+	q.mu.Unlock()
+	if full {
+		q.wake()
+	}
+}
+
+func (q *ingestQueue) wake() {
+	select {
+	case q.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (q *ingestQueue) addTrace(ctx context.Context, write api.TraceWrite) error {
+	if err := q.reserve(ctx); err != nil {
+		return err
+	}
+	q.traceIndex[write.ID.Value] = len(q.traces)
+	q.traces = append(q.traces, write)
+	q.added()
+	return nil
+}
+
+func (q *ingestQueue) addSpan(ctx context.Context, write api.SpanWrite) error {
+	if err := q.reserve(ctx); err != nil {
+		return err
+	}
+	q.spanIndex[write.ID.Value] = len(q.spans)
+	q.spans = append(q.spans, write)
+	q.added()
+	return nil
+}
+
+func (q *ingestQueue) updateTrace(ctx context.Context, id uuid.UUID, update api.TraceUpdate) error {
+	q.mu.Lock()
+	if q.closedLocked() {
+		return errIngestClosed
+	}
+	if i, ok := q.traceIndex[id]; ok {
+		mergeTraceUpdate(&q.traces[i], update)
+		q.mu.Unlock()
+		return nil
+	}
+	q.mu.Unlock()
+	if err := q.reserve(ctx); err != nil {
+		return err
+	}
+	q.traceUpdates = append(q.traceUpdates, entityUpdate[api.TraceUpdate]{id, update})
+	q.added()
+	return nil
+}
+
+func (q *ingestQueue) updateSpan(ctx context.Context, id uuid.UUID, update api.SpanUpdate) error {
+	q.mu.Lock()
+	if q.closedLocked() {
+		return errIngestClosed
+	}
+	if i, ok := q.spanIndex[id]; ok {
+		mergeSpanUpdate(&q.spans[i], update)
+		q.mu.Unlock()
+		return nil
+	}
+	q.mu.Unlock()
+	if err := q.reserve(ctx); err != nil {
+		return err
+	}
+	q.spanUpdates = append(q.spanUpdates, entityUpdate[api.SpanUpdate]{id, update})
+	q.added()
+	return nil
+}
+
+// flushAndReport flushes the queue and returns its errors together with
+// those of earlier background flushes. If last is set, the queue is closed.
+func (q *ingestQueue) flushAndReport(ctx context.Context, last bool) error {
+	err := q.flush(ctx, last)
+	q.mu.Lock()
+	errs := append(q.errs, err)
+	q.errs = nil
+	q.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// flush sends everything queued: trace creates, then span creates, so spans
+// never arrive before their trace, then updates. If last is set, the queue
+// is closed as it is taken, and drained once it is sent.
+func (q *ingestQueue) flush(ctx context.Context, last bool) error {
+	q.sending.Lock()
+	defer q.sending.Unlock()
+	if last {
+		defer q.drainOnce.Do(func() { close(q.drained) })
+	}
+
+	q.mu.Lock()
+	if last {
+		q.closed = true
+	}
+	traces, spans := q.traces, q.spans
+	traceUpdates, spanUpdates := q.traceUpdates, q.spanUpdates
+	q.traces, q.spans, q.traceUpdates, q.spanUpdates = nil, nil, nil, nil
+	clear(q.traceIndex)
+	clear(q.spanIndex)
+	close(q.room)
+	q.room = make(chan struct{})
+	q.mu.Unlock()
+
+	c := q.client
+	var errs []error
+	if len(traces) > 0 {
+		result := sendInBatches(ctx, q.batch, indexesOf(traces), func(i int) int {
+			return len(traces[i].Input) + len(traces[i].Output) + len(traces[i].Metadata) + 256
+		}, func(ctx context.Context, indexes []int) error {
+			req := api.TraceBatchWrite{Traces: make([]api.TraceWrite, len(indexes))}
+			ids := make([]uuid.UUID, len(indexes))
+			for i, j := range indexes {
+				req.Traces[i] = traces[j]
+				ids[i] = traces[j].ID.Value
+			}
+			err := c.apiClient.CreateTraces(ctx, api.NewOptTraceBatchWrite(req))
+			c.auditBatch(AuditCreate, AuditEntityTrace, ids, err)
+			return err
+		})
+		errs = append(errs, wrapBatchErr("create traces", result))
+	}
+	if len(spans) > 0 {
+		result := sendInBatches(ctx, q.batch, indexesOf(spans), func(i int) int {
+			return len(spans[i].Input) + len(spans[i].Output) + len(spans[i].Metadata) + 256
+		}, func(ctx context.Context, indexes []int) error {
+			req := api.SpanBatchWrite{Spans: make([]api.SpanWrite, len(indexes))}
+			ids := make([]uuid.UUID, len(indexes))
+			for i, j := range indexes {
+				req.Spans[i] = spans[j]
+				ids[i] = spans[j].ID.Value
+			}
+			err := c.apiClient.CreateSpans(ctx, api.NewOptSpanBatchWrite(req))
+			c.auditBatch(AuditCreate, AuditEntitySpan, ids, err)
+			return err
+		})
+		errs = append(errs, wrapBatchErr("create spans", result))
+	}
+
+	// The API applies one update to a list of IDs, so updates are sent one
+	// at a time.
+	single := q.batch
+	single.maxItems = 1
+	if len(traceUpdates) > 0 {
+		result := sendInBatches(ctx, single, indexesOf(traceUpdates), func(int) int { return 0 }, func(ctx context.Context, indexes []int) error {
+			u := traceUpdates[indexes[0]]
+			_, err := c.apiClient.BatchUpdateTraces(ctx, api.NewOptTraceBatchUpdate(api.TraceBatchUpdate{Ids: []uuid.UUID{u.id}, Update: u.update}))
+			c.audit(AuditUpdate, AuditEntityTrace, u.id.String(), "", err)
+			return err
+		})
+		errs = append(errs, wrapBatchErr("update traces", result))
+	}
+	if len(spanUpdates) > 0 {
+		result := sendInBatches(ctx, single, indexesOf(spanUpdates), func(int) int { return 0 }, func(ctx context.Context, indexes []int) error {
+			u := spanUpdates[indexes[0]]
+			_, err := c.apiClient.BatchUpdateSpans(ctx, api.NewOptSpanBatchUpdate(api.SpanBatchUpdate{Ids: []uuid.UUID{u.id}, Update: u.update}))
+			c.audit(AuditUpdate, AuditEntitySpan, u.id.String(), "", err)
+			return err
+		})
+		errs = append(errs, wrapBatchErr("update spans", result))
+	}
+	return errors.Join(errs...)
+}
+
+func indexesOf[T any](items []T) []int {
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+func wrapBatchErr(op string, result *BatchResult) error {
+	if err := result.Err(); err != nil {
+		return fmt.Errorf("opik: %s: %w", op, err)
+	}
+	return nil
+}
+
+// mergeTraceUpdate applies an update to a trace create that has not been
+// sent. JSON fields left as null keep the value of the create.
+func mergeTraceUpdate(w *api.TraceWrite, u api.TraceUpdate) {
+	if u.Name.Set {
+		w.Name = u.Name
+	}
+	if u.EndTime.Set {
+		w.EndTime = u.EndTime
+	}
+	mergeJSON(&w.Input, u.Input)
+	mergeJSON(&w.Output, u.Output)
+	mergeJSON(&w.Metadata, u.Metadata)
+	if u.Tags != nil {
+		w.Tags = u.Tags
+	}
+	if u.ErrorInfo.Set {
+		w.ErrorInfo = api.NewOptErrorInfoWrite(api.ErrorInfoWrite(u.ErrorInfo.Value))
+	}
+	if u.ThreadID.Set {
+		w.ThreadID = u.ThreadID
+	}
+}
+
+// mergeSpanUpdate applies an update to a span create that has not been sent.
+func mergeSpanUpdate(w *api.SpanWrite, u api.SpanUpdate) {
+	if u.Name.Set {
+		w.Name = u.Name
+	}
+	if u.Type.Set {
+		w.Type = api.NewOptSpanWriteType(api.SpanWriteType(u.Type.Value))
+	}
+	if u.EndTime.Set {
+		w.EndTime = u.EndTime
+	}
+	mergeJSON(&w.Input, u.Input)
+	mergeJSON(&w.Output, u.Output)
+	mergeJSON(&w.Metadata, u.Metadata)
+	if u.Model.Set {
+		w.Model = u.Model
+	}
+	if u.Provider.Set {
+		w.Provider = u.Provider
+	}
+	if u.Tags != nil {
+		w.Tags = u.Tags
+	}
+	if u.Usage.Set {
+		w.Usage = api.NewOptSpanWriteUsage(api.SpanWriteUsage(u.Usage.Value))
+	}
+	if u.TotalEstimatedCost.Set {
+		w.TotalEstimatedCost = u.TotalEstimatedCost
+	}
+	if u.ErrorInfo.Set {
+		w.ErrorInfo = api.NewOptErrorInfoWrite(api.ErrorInfoWrite(u.ErrorInfo.Value))
+	}
+}
+
+func mergeJSON(dst *api.JsonListStringWrite, src api.JsonListString) {
+	if len(src) > 0 && string(src) != "null" {
+		*dst = api.JsonListStringWrite(src)
+	}
+}
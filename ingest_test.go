@@ -0,0 +1,233 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+// ingestRequest is a request received by newIngestServer.
+type ingestRequest struct {
+	key    string
+	traces []createdEntity
+	spans  []createdEntity
+}
+
+// newIngestServer records every request, and holds each one until release
+// is closed, if it is not nil.
+func newIngestServer(release chan struct{}) (*testutil.MockServer, func() []ingestRequest) {
+	ms := testutil.NewMockServer()
+	route := ms.OnUnmatched().Respond(http.StatusNoContent, nil)
+	if release != nil {
+		route.WithHandler(func(w http.ResponseWriter, _ *http.Request) {
+			<-release
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+	return ms, func() []ingestRequest {
+		var requests []ingestRequest
+		for _, r := range ms.Requests() {
+			var body struct {
+				Traces []createdEntity `json:"traces"`
+				Spans  []createdEntity `json:"spans"`
+			}
+			_ = r.DecodeJSON(&body)
+			requests = append(requests, ingestRequest{r.Method + " " + r.Path, body.Traces, body.Spans})
+		}
+		return requests
+	}
+}
+
+func batchingConfig() BatcherConfig {
+	config := DefaultBatcherConfig()
+	config.FlushInterval = time.Hour
+	config.RetryDelay = time.Millisecond
+	return config
+}
+
+func TestBatchingMergesEndIntoCreate(t *testing.T) {
+	ts, requests := newIngestServer(nil)
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithBatching(batchingConfig()))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	trace, err := client.Trace(ctx, "request", WithTraceInput("question"))
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	for _, name := range []string{"retrieve", "generate"} {
+		span, err := trace.Span(ctx, name)
+		if err != nil {
+			t.Fatalf("Span error: %v", err)
+		}
+		if err := span.End(ctx, WithSpanOutput("done")); err != nil {
+			t.Fatalf("span End error: %v", err)
+		}
+	}
+	if err := trace.End(ctx, WithTraceOutput("answer")); err != nil {
+		t.Fatalf("trace End error: %v", err)
+	}
+	if got := requests(); len(got) != 0 {
+		t.Fatalf("sent %d requests before Flush, want 0", len(got))
+	}
+
+	if err := client.Flush(ctx); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+	got := requests()
+	if len(got) != 2 || got[0].key != "POST /v1/private/traces/batch" || got[1].key != "POST /v1/private/spans/batch" {
+		t.Fatalf("requests = %+v, want one trace batch then one span batch", got)
+	}
+	if tr := got[0].traces; len(tr) != 1 || tr[0].EndTime == nil || tr[0].Output != "answer" || tr[0].Input != "question" {
+		t.Errorf("trace = %+v, want ended with its output", tr)
+	}
+	if spans := got[1].spans; len(spans) != 2 || spans[0].EndTime == nil || spans[1].Output != "done" {
+		t.Errorf("spans = %+v, want 2 ended spans", spans)
+	}
+
+	// Once sent, later changes are updates.
+	if err := trace.Update(ctx, WithTraceTags("reviewed")); err != nil {
+		t.Fatalf("Update error: %v", err)
+	}
+	if err := client.Close(ctx); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if got := requests(); len(got) != 3 || got[2].key != "PATCH /v1/private/traces/batch" {
+		t.Errorf("requests after Close = %+v, want a trace update", got)
+	}
+
+	// After Close, writes are sent synchronously.
+	if _, err := client.Trace(ctx, "late"); err != nil {
+		t.Fatalf("Trace after Close error: %v", err)
+	}
+	if got := requests(); len(got) != 4 {
+		t.Errorf("got %d requests, want the trace sent at once after Close", len(got))
+	}
+}
+
+func TestBatchingUpdateRacingClose(t *testing.T) {
+	release := make(chan struct{})
+	ts, requests := newIngestServer(release)
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithBatching(batchingConfig()))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "request")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+
+	// Close's final flush sends the create, which the server holds, and the
+	// update made meanwhile has to wait for it.
+	closed := make(chan error, 1)
+	go func() { closed <- client.Close(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(requests()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	updated := make(chan error, 1)
+	go func() { updated <- trace.Update(ctx, WithTraceTags("reviewed")) }()
+	time.Sleep(50 * time.Millisecond)
+	if got := requests(); len(got) != 1 {
+		t.Errorf("requests while the create is sent = %+v, want only the create", got)
+	}
+	close(release)
+
+	if err := <-closed; err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if err := <-updated; err != nil {
+		t.Fatalf("Update error: %v", err)
+	}
+	got := requests()
+	if len(got) != 2 || got[0].key != "POST /v1/private/traces/batch" || got[1].key != "PATCH /v1/private/traces/batch" {
+		t.Errorf("requests = %+v, want the create, then the update", got)
+	}
+}
+
+func TestBatchingFlushesFullBatch(t *testing.T) {
+	ts, requests := newIngestServer(nil)
+	defer ts.Close()
+
+	config := batchingConfig()
+	config.MaxBatchSize = 3
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithBatching(config))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	defer client.Close(context.Background())
+
+	for range 3 {
+		if _, err := client.Trace(context.Background(), "t"); err != nil {
+			t.Fatalf("Trace error: %v", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(requests()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := requests(); len(got) != 1 || len(got[0].traces) != 3 {
+		t.Errorf("requests = %+v, want one batch of 3 traces", got)
+	}
+}
+
+func TestBatchingQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	ts, _ := newIngestServer(release)
+	defer ts.Close()
+	defer close(release)
+
+	config := batchingConfig()
+	config.MaxBatchSize = 1
+	config.MaxQueueDepth = 1
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithBatching(config))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	// The first trace is taken by a flush that the server holds, the second
+	// fills the queue, and the third has to wait.
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	var lastErr error
+	for range 3 {
+		if _, lastErr = client.Trace(ctx, "t"); lastErr != nil {
+			break
+		}
+	}
+	if !errors.Is(lastErr, context.DeadlineExceeded) {
+		t.Errorf("Trace error = %v, want the queue to block until the deadline", lastErr)
+	}
+}
+
+func TestWithBatchingValidation(t *testing.T) {
+	_, err := NewClient(WithURL("http://localhost"), WithBatching(BatcherConfig{MaxQueueDepth: -1}))
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Problems) != 3 {
+		t.Errorf("NewClient error = %v, want 3 problems", err)
+	}
+}
+
+func TestFlushWithoutBatching(t *testing.T) {
+	client, err := NewClient(WithURL("http://localhost"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if err := client.Flush(context.Background()); err != nil {
+		t.Errorf("Flush error = %v, want nil", err)
+	}
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("Close error = %v, want nil", err)
+	}
+}
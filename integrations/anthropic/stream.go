@@ -0,0 +1,283 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// isEventStream reports whether resp is a server-sent event stream, as
+// returned for requests with "stream": true.
+func isEventStream(resp *http.Response) bool {
+	if resp == nil || resp.Body == nil {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return mediaType == "text/event-stream"
+}
+
+// streamRecorder passes a streamed response through unchanged while it
+// assembles the events into the message the same request would have
+// returned without streaming. The span ends when the stream is read to its
+// end, fails, or is closed.
+type streamRecorder struct {
+	body      io.ReadCloser
+	ctx       context.Context
+	transport *TracingTransport
+	span      *opik.Span
+	resp      *http.Response
+	start     time.Time
+
+	mu         sync.Mutex
+	line       []byte
+	events     int
+	firstEvent time.Time
+	done       bool
+	stream     messageStream
+	endOnce    sync.Once
+}
+
+func newStreamRecorder(ctx context.Context, t *TracingTransport, span *opik.Span, resp *http.Response, start time.Time) *streamRecorder {
+	return &streamRecorder{
+		body:      resp.Body,
+		ctx:       context.WithoutCancel(ctx),
+		transport: t,
+		span:      span,
+		resp:      resp,
+		start:     start,
+	}
+}
+
+// Read implements io.Reader.
+func (r *streamRecorder) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.mu.Lock()
+	r.feed(p[:n])
+	if errors.Is(err, io.EOF) {
+		// Some gateways omit the final [DONE] event.
+		r.done = true
+	}
+	r.mu.Unlock()
+	switch {
+	case errors.Is(err, io.EOF):
+		r.end(nil)
+	case err != nil:
+		r.end(err)
+	}
+	return n, err
+}
+
+// Close implements io.Closer. Closing the stream before it finished
+// records the span as cancelled, with the output received so far.
+func (r *streamRecorder) Close() error {
+	err := r.body.Close()
+	r.end(nil)
+	return err
+}
+
+// feed splits data into lines and records each complete data line.
+func (r *streamRecorder) feed(data []byte) {
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			r.line = append(r.line, data...)
+			return
+		}
+		line := data[:i]
+		if len(r.line) > 0 {
+			r.line = append(r.line, line...)
+			line = r.line
+		}
+		r.record(bytes.TrimRight(line, "\r"))
+		r.line = r.line[:0]
+		data = data[i+1:]
+	}
+}
+
+// record records one line of the stream.
+func (r *streamRecorder) record(line []byte) {
+	payload, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return
+	}
+	payload = bytes.TrimSpace(payload)
+	var event map[string]any
+	if json.Unmarshal(payload, &event) != nil || event["type"] == "ping" {
+		return
+	}
+	if r.events == 0 {
+		r.firstEvent = time.Now()
+	}
+	r.events++
+	if r.stream.add(event) {
+		r.done = true
+	}
+}
+
+// end ends the span once. err is the error that ended the stream, if any.
+func (r *streamRecorder) end(err error) {
+	r.endOnce.Do(func() {
+		r.mu.Lock()
+		if len(r.line) > 0 {
+			r.record(bytes.TrimRight(r.line, "\r"))
+			r.line = nil
+		}
+		output := r.stream.output()
+		metadata := map[string]any{
+			"streaming":   true,
+			"chunk_count": r.events,
+			"duration_ms": time.Since(r.start).Milliseconds(),
+		}
+		if r.events > 0 {
+			metadata["time_to_first_chunk"] = r.firstEvent.Sub(r.start).Milliseconds()
+		}
+		switch {
+		case err != nil:
+			metadata["error"] = err.Error()
+		case r.stream.err != nil:
+			metadata["error"] = r.stream.err
+		case !r.done:
+			metadata[opik.MetadataStreamStatus] = opik.StreamStatusCancelled
+			metadata[opik.MetadataAbortReason] = "stream closed before it finished"
+		}
+		r.mu.Unlock()
+
+		r.transport.endSpan(r.ctx, r.span, r.resp, output, metadata)
+	})
+}
+
+// messageStream assembles the events of a Messages stream.
+type messageStream struct {
+	message map[string]any
+	blocks  []*streamBlock
+	// err is the payload of an error event.
+	err any
+}
+
+// streamBlock is a content block of a Messages stream. Text, thinking,
+// and tool input arrive in deltas.
+type streamBlock struct {
+	block     map[string]any
+	text      strings.Builder
+	thinking  strings.Builder
+	signature strings.Builder
+	input     strings.Builder
+}
+
+// add records one event and reports whether it completes the stream.
+func (s *messageStream) add(event map[string]any) bool {
+	switch event["type"] {
+	case "message_start":
+		if message, ok := event["message"].(map[string]any); ok {
+			s.message = message
+		}
+	case "content_block_start":
+		block, _ := event["content_block"].(map[string]any)
+		if block == nil {
+			block = map[string]any{}
+		}
+		s.blocks = append(s.blocks, &streamBlock{block: block})
+	case "content_block_delta":
+		if len(s.blocks) == 0 {
+			return false
+		}
+		b := s.blocks[len(s.blocks)-1]
+		delta, _ := event["delta"].(map[string]any)
+		switch delta["type"] {
+		case "text_delta":
+			b.text.WriteString(stringValue(delta["text"]))
+		case "thinking_delta":
+			b.thinking.WriteString(stringValue(delta["thinking"]))
+		case "signature_delta":
+			b.signature.WriteString(stringValue(delta["signature"]))
+		case "input_json_delta":
+			b.input.WriteString(stringValue(delta["partial_json"]))
+		}
+	case "message_delta":
+		if s.message == nil {
+			s.message = map[string]any{}
+		}
+		if delta, ok := event["delta"].(map[string]any); ok {
+			for k, v := range delta {
+				s.message[k] = v
+			}
+		}
+		// The usage of a message delta is cumulative.
+		if usage, ok := event["usage"].(map[string]any); ok {
+			merged, _ := s.message["usage"].(map[string]any)
+			if merged == nil {
+				merged = map[string]any{}
+			}
+			for k, v := range usage {
+				merged[k] = v
+			}
+			s.message["usage"] = merged
+		}
+	case "message_stop":
+		return true
+	case "error":
+		s.err = event["error"]
+		return true
+	}
+	return false
+}
+
+// output returns the assembled message, in the shape of the response to
+// the same request without streaming, or nil if no event was recorded.
+func (s *messageStream) output() map[string]any {
+	if s.message == nil && len(s.blocks) == 0 {
+		return nil
+	}
+	out := map[string]any{}
+	for k, v := range s.message {
+		out[k] = v
+	}
+	content := make([]any, 0, len(s.blocks))
+	for _, b := range s.blocks {
+		content = append(content, b.content())
+	}
+	out["content"] = content
+	return out
+}
+
+// content returns the block with its deltas applied.
+func (b *streamBlock) content() map[string]any {
+	block := make(map[string]any, len(b.block))
+	for k, v := range b.block {
+		block[k] = v
+	}
+	switch block["type"] {
+	case "text":
+		block["text"] = stringValue(block["text"]) + b.text.String()
+	case "thinking":
+		block["thinking"] = stringValue(block["thinking"]) + b.thinking.String()
+		if b.signature.Len() > 0 {
+			block["signature"] = b.signature.String()
+		}
+	case "tool_use", "server_tool_use":
+		if b.input.Len() > 0 {
+			var input any
+			if json.Unmarshal([]byte(b.input.String()), &input) == nil {
+				block["input"] = input
+			} else {
+				// The stream ended inside the input.
+				block["input"] = b.input.String()
+			}
+		}
+	}
+	return block
+}
+
+func stringValue(v any) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,199 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// roundTripFunc adapts a function to an http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// spanUpdates records the span updates sent to a test Opik server.
+type spanUpdates struct {
+	mu      sync.Mutex
+	updates []map[string]any
+}
+
+func (s *spanUpdates) last(t *testing.T) map[string]any {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.updates) == 0 {
+		t.Fatal("no span update was sent")
+	}
+	return s.updates[len(s.updates)-1]
+}
+
+// tracedRequest sends a Messages request through a tracing transport whose
+// upstream answers with body, one byte per read, and returns the response
+// and the span updates sent to Opik.
+func tracedRequest(t *testing.T, contentType, body string) (*http.Response, *spanUpdates) {
+	t.Helper()
+	updates := &spanUpdates{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && r.URL.Path == "/v1/private/spans/batch" {
+			var req struct {
+				Update map[string]any `json:"update"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			updates.mu.Lock()
+			updates.updates = append(updates.updates, req.Update)
+			updates.mu.Unlock()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(ts.Close)
+
+	client, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "chat")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+
+	upstream := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {contentType}},
+			Body:       io.NopCloser(iotest.OneByteReader(strings.NewReader(body))),
+			Request:    req,
+		}, nil
+	})
+	req, _ := http.NewRequestWithContext(opik.ContextWithTrace(ctx, trace), http.MethodPost,
+		"https://api.anthropic.com/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5","stream":true}`))
+	resp, err := NewTracingTransport(upstream, client).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip error: %v", err)
+	}
+	return resp, updates
+}
+
+func sse(events ...string) string {
+	var b strings.Builder
+	for _, e := range events {
+		var typed struct {
+			Type string `json:"type"`
+		}
+		_ = json.Unmarshal([]byte(e), &typed)
+		b.WriteString("event: " + typed.Type + "\ndata: " + e + "\n\n")
+	}
+	return b.String()
+}
+
+func TestTracingTransportMessageStream(t *testing.T) {
+	body := sse(
+		`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[],"stop_reason":null,"usage":{"input_tokens":25,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Need the weather."}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"ping"}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Let me "}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"check."}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+		`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"{\"city\": "}}`,
+		`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}`,
+		`{"type":"content_block_stop","index":2}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use","stop_sequence":null},"usage":{"output_tokens":42}}`,
+		`{"type":"message_stop"}`,
+	)
+	resp, updates := tracedRequest(t, "text/event-stream", body)
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading stream: %v", err)
+	}
+	resp.Body.Close()
+	if string(got) != body {
+		t.Error("stream was not passed through unchanged")
+	}
+
+	update := updates.last(t)
+	output, _ := update["output"].(map[string]any)
+	if output["id"] != "msg_1" || output["stop_reason"] != "tool_use" {
+		t.Errorf("output = %v, want message msg_1 stopped for tool use", output)
+	}
+	content, _ := output["content"].([]any)
+	if len(content) != 3 {
+		t.Fatalf("content = %v, want 3 blocks", content)
+	}
+	if thinking := content[0].(map[string]any); thinking["thinking"] != nil {
+		t.Errorf("thinking block = %v, want its text recorded only as reasoning", thinking)
+	}
+	if text := content[1].(map[string]any); text["text"] != "Let me check." {
+		t.Errorf("text block = %v", text)
+	}
+	tool := content[2].(map[string]any)
+	if input, _ := tool["input"].(map[string]any); tool["name"] != "get_weather" || input["city"] != "Paris" {
+		t.Errorf("tool_use block = %v, want get_weather for Paris", tool)
+	}
+
+	if usage, _ := update["usage"].(map[string]any); usage["prompt_tokens"] != float64(25) || usage["completion_tokens"] != float64(42) {
+		t.Errorf("usage = %v, want 25 prompt and 42 completion tokens", usage)
+	}
+	metadata, _ := update["metadata"].(map[string]any)
+	if calls, _ := metadata["tool_calls"].([]any); len(calls) != 1 || calls[0] != "get_weather" {
+		t.Errorf("tool_calls = %v, want [get_weather]", metadata["tool_calls"])
+	}
+	if metadata["streaming"] != true || metadata["chunk_count"] != float64(14) || metadata[opik.MetadataStreamStatus] != nil {
+		t.Errorf("metadata = %v, want a finished stream of 14 events", metadata)
+	}
+}
+
+func TestTracingTransportMessageStreamClosedEarly(t *testing.T) {
+	start := sse(
+		`{"type":"message_start","message":{"id":"msg_2","type":"message","role":"assistant","content":[],"usage":{"input_tokens":5}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_2","name":"search","input":{}}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"q\": \"go"}}`,
+	)
+	resp, updates := tracedRequest(t, "text/event-stream", start+sse(`{"type":"message_stop"}`))
+	if _, err := io.ReadFull(resp.Body, make([]byte, len(start))); err != nil {
+		t.Fatalf("reading stream: %v", err)
+	}
+	resp.Body.Close()
+
+	update := updates.last(t)
+	metadata, _ := update["metadata"].(map[string]any)
+	if metadata[opik.MetadataStreamStatus] != opik.StreamStatusCancelled {
+		t.Errorf("metadata = %v, want a cancelled stream", metadata)
+	}
+	output, _ := update["output"].(map[string]any)
+	tool := output["content"].([]any)[0].(map[string]any)
+	if tool["input"] != `{"q": "go` {
+		t.Errorf("partial tool input = %v, want the raw JSON received", tool["input"])
+	}
+}
+
+func TestTracingTransportToolUse(t *testing.T) {
+	resp, updates := tracedRequest(t, "application/json", `{
+		"id": "msg_3", "type": "message", "role": "assistant", "stop_reason": "tool_use",
+		"content": [
+			{"type": "text", "text": "Looking up both."},
+			{"type": "tool_use", "id": "toolu_3", "name": "get_weather", "input": {"city": "Paris"}},
+			{"type": "tool_use", "id": "toolu_4", "name": "get_time", "input": {"city": "Paris"}}
+		],
+		"usage": {"input_tokens": 10, "output_tokens": 20}
+	}`)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	metadata, _ := updates.last(t)["metadata"].(map[string]any)
+	calls, _ := metadata["tool_calls"].([]any)
+	if len(calls) != 2 || calls[0] != "get_weather" || calls[1] != "get_time" || metadata["stop_reason"] != "tool_use" {
+		t.Errorf("metadata = %v, want tool calls get_weather and get_time", metadata)
+	}
+}
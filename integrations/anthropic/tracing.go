@@ -2,12 +2,15 @@ package anthropic
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/evaluation/llm"
 )
 
 // TracingTransport wraps an http.RoundTripper to automatically trace Anthropic API calls.
@@ -15,6 +18,8 @@ type TracingTransport struct {
 	inner       http.RoundTripper
 	opikClient  *opik.Client
 	spanOptions []opik.SpanOption
+	usage       opik.UsageExtractor
+	maxInput    int
 }
 
 // NewTracingTransport creates a new tracing transport.
@@ -29,6 +34,25 @@ func NewTracingTransport(inner http.RoundTripper, opikClient *opik.Client, opts
 	}
 }
 
+// WithUsageExtractor reads token usage from each response with e, for
+// gateways that report usage in headers or trailers rather than the body.
+// Usage in the body takes precedence; see opik.MergeUsage.
+func (t *TracingTransport) WithUsageExtractor(e opik.UsageExtractor) *TracingTransport {
+	t.usage = e
+	return t
+}
+
+// WithMaxInputBytes limits the messages recorded as a span's input to
+// about maxBytes of JSON. Longer conversations are recorded without their
+// oldest messages, keeping the system messages and the latest user turn
+// (see llm.TruncateMessagesFunc), and the span's metadata records the
+// number left out under llm.MetadataOmittedMessages. The request sent to
+// the API is not changed.
+func (t *TracingTransport) WithMaxInputBytes(maxBytes int) *TracingTransport {
+	t.maxInput = maxBytes
+	return t
+}
+
 // RoundTrip implements http.RoundTripper with tracing.
 func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// Only trace Anthropic API calls
@@ -54,11 +78,16 @@ func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	operation := getOperationName(req.URL.Path)
 
 	// Start span
-	opts := append([]opik.SpanOption{
+	input, omitted := llm.TruncateInputMessages(reqData, t.maxInput)
+	opts := []opik.SpanOption{
 		opik.WithSpanType(opik.SpanTypeLLM),
 		opik.WithSpanProvider("anthropic"),
-		opik.WithSpanInput(reqData),
-	}, t.spanOptions...)
+		opik.WithSpanInput(input),
+	}
+	if omitted > 0 {
+		opts = append(opts, opik.WithSpanMetadata(map[string]any{llm.MetadataOmittedMessages: omitted}))
+	}
+	opts = append(opts, t.spanOptions...)
 
 	if model != "" {
 		opts = append(opts, opik.WithSpanModel(model))
@@ -81,43 +110,129 @@ func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 
 	// End span with results
 	if span != nil && err == nil {
-		endOpts := []opik.SpanOption{}
+		metadata := map[string]any{
+			"duration_ms": duration.Milliseconds(),
+		}
 
+		// A streamed response is recorded as it is read, so the caller
+		// receives each event as soon as it arrives.
+		if respErr == nil && isEventStream(resp) {
+			resp.Body = newStreamRecorder(ctx, t, span, resp, startTime)
+			return resp, nil
+		}
+
+		var respData map[string]any
 		if resp != nil && resp.Body != nil {
 			// Read response body for output
 			body, _ := io.ReadAll(resp.Body)
 			resp.Body = io.NopCloser(bytes.NewReader(body))
+			_ = json.Unmarshal(body, &respData)
+		}
+		var endOpts []opik.SpanOption
+		if respErr != nil {
+			metadata["error"] = respErr.Error()
+			endOpts = append(endOpts, opik.WithSpanError(respErr))
+		}
+		t.endSpan(ctx, span, resp, respData, metadata, endOpts...)
+	}
+
+	return resp, respErr
+}
+
+// endSpan ends span with the decoded response body respData, which is nil
+// if the body was not JSON. Token usage and the tools the model called are
+// added to metadata, which is recorded if the response reported usage or
+// tool calls, or the call failed. opts are applied after the options
+// endSpan sets.
+func (t *TracingTransport) endSpan(ctx context.Context, span *opik.Span, resp *http.Response, respData map[string]any, metadata map[string]any, opts ...opik.SpanOption) {
+	endOpts := []opik.SpanOption{}
+	var usage map[string]int
 
-			var respData map[string]any
-			if json.Unmarshal(body, &respData) == nil {
-				endOpts = append(endOpts, opik.WithSpanOutput(respData))
-
-				// Extract usage info
-				if usage, ok := respData["usage"].(map[string]any); ok {
-					metadata := map[string]any{
-						"duration_ms": duration.Milliseconds(),
-					}
-					if it, ok := usage["input_tokens"].(float64); ok {
-						metadata["input_tokens"] = int(it)
-					}
-					if ot, ok := usage["output_tokens"].(float64); ok {
-						metadata["output_tokens"] = int(ot)
-					}
-					endOpts = append(endOpts, opik.WithSpanMetadata(metadata))
-				}
+	if respData != nil {
+		if summary, ok := extractThinking(respData); ok {
+			span.SetReasoning(summary)
+		}
+		endOpts = append(endOpts, opik.WithSpanOutput(respData))
+
+		if tools := toolUses(respData); len(tools) > 0 {
+			metadata["tool_calls"] = tools
+			metadata["stop_reason"] = respData["stop_reason"]
+		}
+
+		// Extract usage info
+		if raw, ok := respData["usage"].(map[string]any); ok {
+			if it, ok := raw["input_tokens"].(float64); ok {
+				metadata["input_tokens"] = int(it)
+			}
+			if ot, ok := raw["output_tokens"].(float64); ok {
+				metadata["output_tokens"] = int(ot)
 			}
+			usage = opik.NormalizeUsage(raw)
 		}
+	}
 
-		if respErr != nil {
-			endOpts = append(endOpts, opik.WithSpanMetadata(map[string]any{
-				"error": respErr.Error(),
-			}))
+	// Fill in usage the body omits, such as usage a gateway
+	// reports only in headers.
+	if t.usage != nil && resp != nil {
+		usage = opik.MergeUsage(usage, t.usage.ExtractUsage(resp))
+	}
+	if len(usage) > 0 {
+		for _, key := range []string{opik.UsageCacheReadTokens, opik.UsageCacheWriteTokens} {
+			if n, ok := usage[key]; ok {
+				metadata[key] = n
+			}
 		}
+		span.SetUsage(usage)
+	}
+	if len(usage) > 0 || metadata["tool_calls"] != nil || metadata["error"] != nil || metadata["streaming"] != nil {
+		endOpts = append(endOpts, opik.WithSpanMetadata(metadata))
+	}
+
+	_ = span.End(ctx, append(endOpts, opts...)...)
+}
 
-		_ = span.End(ctx, endOpts...)
+// toolUses returns the names of the tools a response calls, in order, so
+// tool use can be filtered on without reading the output.
+func toolUses(respData map[string]any) []string {
+	content, _ := respData["content"].([]any)
+	var names []string
+	for _, block := range content {
+		b, ok := block.(map[string]any)
+		if !ok || b["type"] != "tool_use" {
+			continue
+		}
+		if name, ok := b["name"].(string); ok {
+			names = append(names, name)
+		}
 	}
+	return names
+}
 
-	return resp, respErr
+// extractThinking removes the text of extended thinking blocks from a
+// response and returns it, so it is recorded only through Span.SetReasoning,
+// which redacts it unless reasoning capture is enabled. The blocks are kept,
+// without their text, so the output still shows that the model reasoned.
+func extractThinking(respData map[string]any) (string, bool) {
+	content, ok := respData["content"].([]any)
+	if !ok {
+		return "", false
+	}
+	var parts []string
+	for _, block := range content {
+		b, ok := block.(map[string]any)
+		if !ok || b["type"] != "thinking" {
+			continue
+		}
+		if text, ok := b["thinking"].(string); ok && text != "" {
+			parts = append(parts, text)
+		}
+		delete(b, "thinking")
+		delete(b, "signature")
+	}
+	if len(parts) == 0 {
+		return "", false
+	}
+	return strings.Join(parts, "\n\n"), true
 }
 
 func isAnthropicRequest(req *http.Request) bool {
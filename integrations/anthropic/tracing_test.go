@@ -168,3 +168,25 @@ func TestTracingTransportNonAnthropicRequest(t *testing.T) {
 
 	_ = tr
 }
+
+func TestExtractThinking(t *testing.T) {
+	resp := map[string]any{
+		"content": []any{
+			map[string]any{"type": "thinking", "thinking": "Let me check the dates.", "signature": "sig"},
+			map[string]any{"type": "redacted_thinking", "data": "..."},
+			map[string]any{"type": "text", "text": "It is Tuesday."},
+		},
+	}
+	summary, ok := extractThinking(resp)
+	if !ok || summary != "Let me check the dates." {
+		t.Errorf("extractThinking = %q, %v", summary, ok)
+	}
+	block := resp["content"].([]any)[0].(map[string]any)
+	if len(block) != 1 || block["type"] != "thinking" {
+		t.Errorf("thinking block = %v, want only its type", block)
+	}
+
+	if _, ok := extractThinking(map[string]any{"content": []any{}}); ok {
+		t.Error("expected no thinking")
+	}
+}
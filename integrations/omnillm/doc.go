@@ -8,6 +8,11 @@
 //  2. Evaluation provider: Use omnillm as an LLM provider for evaluation metrics
 //     that require LLM-as-judge capabilities.
 //
+// This package is a separate module, so users of the core opik-go module
+// don't depend on omnillm and its provider SDKs. Add it with:
+//
+//	go get github.com/plexusone/opik-go/integrations/omnillm
+//
 // # Automatic Tracing
 //
 // Wrap your omnillm.ChatClient to automatically trace all LLM calls:
@@ -1,9 +1,23 @@
 package omnillm
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/plexusone/omnillm"
 	"github.com/plexusone/omnillm/provider"
+	omnillmtesting "github.com/plexusone/omnillm/testing"
+
+	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/evaluation/llm"
 )
 
 func TestNewProvider(t *testing.T) {
@@ -226,3 +240,592 @@ func TestTracingStreamInit(t *testing.T) {
 		t.Error("usage should be nil initially")
 	}
 }
+
+type fakeStream struct {
+	recvs  int
+	closed bool
+}
+
+func (s *fakeStream) Recv() (*provider.ChatCompletionChunk, error) {
+	s.recvs++
+	return &provider.ChatCompletionChunk{}, nil
+}
+
+func (s *fakeStream) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestTracingStreamCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	inner := &fakeStream{}
+	ts := &tracingStream{stream: inner, ctx: ctx, startTime: time.Now()}
+
+	if _, err := ts.Recv(); err != nil {
+		t.Fatalf("Recv error: %v", err)
+	}
+	cancel()
+	if _, err := ts.Recv(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Recv after cancel = %v, want context.Canceled", err)
+	}
+	if inner.recvs != 1 {
+		t.Errorf("inner Recv called %d times, want 1", inner.recvs)
+	}
+	if !ts.closed {
+		t.Error("span should be ended after cancellation")
+	}
+	if err := ts.Close(); err != nil || !inner.closed {
+		t.Errorf("Close = %v, inner closed = %v", err, inner.closed)
+	}
+}
+
+type fakeProvider struct {
+	requests []*provider.ChatCompletionRequest
+}
+
+func (p *fakeProvider) CreateChatCompletion(_ context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	p.requests = append(p.requests, req)
+	return &provider.ChatCompletionResponse{
+		Model: req.Model,
+		Choices: []provider.ChatCompletionChoice{
+			{Message: provider.Message{Role: provider.RoleAssistant, Content: fmt.Sprintf("reply %d", len(p.requests))}},
+		},
+	}, nil
+}
+
+func (p *fakeProvider) CreateChatCompletionStream(context.Context, *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *fakeProvider) Close() error { return nil }
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func TestProviderCompleteStructured(t *testing.T) {
+	fake := &fakeProvider{}
+	chatClient, err := omnillm.NewClient(omnillm.ClientConfig{
+		Providers: []omnillm.ProviderConfig{{CustomProvider: fake}},
+	})
+	if err != nil {
+		t.Fatalf("omnillm.NewClient error: %v", err)
+	}
+	p := NewProvider(chatClient, WithModel("gpt-4o"))
+
+	schema := llm.ResponseSchema{
+		Name:        "score",
+		Description: "The score.",
+		Schema:      map[string]any{"type": "object", "required": []string{"score"}},
+	}
+	req := llm.CompletionRequest{Messages: []llm.Message{{Role: "user", Content: "Rate it."}}}
+	if _, err := p.CompleteStructured(context.Background(), req, schema); err != nil {
+		t.Fatalf("CompleteStructured error: %v", err)
+	}
+
+	sent := fake.requests[0]
+	if sent.ResponseFormat == nil || sent.ResponseFormat.Type != "json_object" {
+		t.Errorf("ResponseFormat = %+v, want json_object", sent.ResponseFormat)
+	}
+	if len(sent.Messages) != 2 || sent.Messages[1].Role != provider.RoleSystem ||
+		!strings.Contains(sent.Messages[1].Content, `{"required":["score"],"type":"object"}`) {
+		t.Errorf("messages = %+v, want the schema appended", sent.Messages)
+	}
+
+	if _, err := p.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete error: %v", err)
+	}
+	if fake.requests[1].ResponseFormat != nil || len(fake.requests[1].Messages) != 1 {
+		t.Errorf("Complete request = %+v, want no JSON mode", fake.requests[1])
+	}
+}
+
+func TestTracingClientMemoryCapture(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, r.Method+" "+r.URL.Path+" "+string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	opikClient, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	chatClient, err := omnillm.NewClient(omnillm.ClientConfig{
+		Providers: []omnillm.ProviderConfig{{CustomProvider: &fakeProvider{}}},
+		Memory:    omnillmtesting.NewMockKVS(),
+	})
+	if err != nil {
+		t.Fatalf("omnillm.NewClient error: %v", err)
+	}
+
+	tc := NewTracingClient(chatClient, opikClient).WithMemoryCapture(0)
+	if tc.maxTranscriptChars != DefaultMaxTranscriptChars {
+		t.Errorf("maxTranscriptChars = %d, want default", tc.maxTranscriptChars)
+	}
+
+	ctx := context.Background()
+	for _, content := range []string{"hello", "how are you?"} {
+		req := &provider.ChatCompletionRequest{
+			Model:    "gpt-4o",
+			Messages: []provider.Message{{Role: provider.RoleUser, Content: content}},
+		}
+		if _, err := tc.CreateChatCompletionWithMemory(ctx, "session-1", req); err != nil {
+			t.Fatalf("CreateChatCompletionWithMemory error: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var traces, spans []string
+	for _, b := range bodies {
+		switch {
+		case strings.HasPrefix(b, "POST /v1/private/traces"):
+			traces = append(traces, b)
+		case strings.HasPrefix(b, "POST /v1/private/spans"):
+			spans = append(spans, b)
+		}
+	}
+	if len(traces) != 2 || len(spans) != 2 {
+		t.Fatalf("traces = %d, spans = %d, want 2 each", len(traces), len(spans))
+	}
+	for _, tr := range traces {
+		if !strings.Contains(tr, `"thread_id":"session-1"`) {
+			t.Errorf("trace not linked to thread: %s", tr)
+		}
+	}
+	// The second call sees the first turn in memory.
+	second := spans[1]
+	for _, want := range []string{
+		`"memory_message_count":2`,
+		`"conversation_message_count":3`,
+		`"memory_transcript":"user: hello\nassistant: reply 1\nuser: how are you?"`,
+		`"memory_tokens":`,
+	} {
+		if !strings.Contains(second, want) {
+			t.Errorf("second span missing %s: %s", want, second)
+		}
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	messages := []provider.Message{
+		{Role: provider.RoleSystem, Content: "Be brief."},
+		{Role: provider.RoleUser, Content: "héllo"},
+	}
+	if got := transcript(messages, 0); got != "system: Be brief.\nuser: héllo" {
+		t.Errorf("transcript = %q", got)
+	}
+	// Keeps the most recent characters without splitting "é".
+	if got := transcript(messages, 4); got != "...llo" {
+		t.Errorf("truncated transcript = %q", got)
+	}
+}
+
+func TestTracingStreamAbort(t *testing.T) {
+	var mu sync.Mutex
+	var update string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && r.URL.Path == "/v1/private/spans/batch" {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			update = string(body)
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	opikClient, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	trace, err := opikClient.Trace(ctx, "chat")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	span, err := trace.Span(ctx, "omnillm.chat.stream")
+	if err != nil {
+		t.Fatalf("Span error: %v", err)
+	}
+
+	inner := &fakeStream{}
+	var stream AbortableStream = &tracingStream{stream: inner, span: span, ctx: ctx, startTime: time.Now()}
+	stream.(*tracingStream).responseBuffer.WriteString("partial")
+
+	if err := stream.Abort("user navigated away"); err != nil {
+		t.Fatalf("Abort error: %v", err)
+	}
+	if !inner.closed || span.EndTime() == nil {
+		t.Errorf("inner closed = %v, span ended = %v", inner.closed, span.EndTime() != nil)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, want := range []string{
+		`"status":"cancelled"`,
+		`"abort_reason":"user navigated away"`,
+		`"partial_content_length":7`,
+		`"elapsed_ms":`,
+	} {
+		if !strings.Contains(update, want) {
+			t.Errorf("span update missing %s: %s", want, update)
+		}
+	}
+}
+
+// scriptedStream returns chunks with the given contents, then a chunk
+// with the finish reason, if set, then err.
+type scriptedStream struct {
+	contents []string
+	finish   string
+	err      error
+}
+
+func (s *scriptedStream) Recv() (*provider.ChatCompletionChunk, error) {
+	if len(s.contents) == 0 && s.finish != "" {
+		finish := s.finish
+		s.finish = ""
+		return &provider.ChatCompletionChunk{
+			Choices: []provider.ChatCompletionChoice{{Delta: &provider.Message{}, FinishReason: &finish}},
+		}, nil
+	}
+	if len(s.contents) == 0 {
+		return nil, s.err
+	}
+	content := s.contents[0]
+	s.contents = s.contents[1:]
+	return &provider.ChatCompletionChunk{
+		Model:   "gpt-4o",
+		Choices: []provider.ChatCompletionChoice{{Delta: &provider.Message{Role: provider.RoleAssistant, Content: content}}},
+	}, nil
+}
+
+func (s *scriptedStream) Close() error { return nil }
+
+// streamingProvider returns its streams in turn.
+type streamingProvider struct {
+	fakeProvider
+	streams []*scriptedStream
+}
+
+func (p *streamingProvider) CreateChatCompletionStream(_ context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	p.requests = append(p.requests, req)
+	stream := p.streams[0]
+	p.streams = p.streams[1:]
+	return stream, nil
+}
+
+func TestTracingStreamReconnect(t *testing.T) {
+	var mu sync.Mutex
+	var updates []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodPatch {
+			mu.Lock()
+			updates = append(updates, string(body))
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	opikClient, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	fake := &streamingProvider{streams: []*scriptedStream{
+		{contents: []string{"Hel", "lo"}, err: io.ErrUnexpectedEOF},
+		{contents: []string{" world"}, finish: "stop", err: io.EOF},
+	}}
+	chatClient, err := omnillm.NewClient(omnillm.ClientConfig{
+		Providers: []omnillm.ProviderConfig{{CustomProvider: fake}},
+	})
+	if err != nil {
+		t.Fatalf("omnillm.NewClient error: %v", err)
+	}
+	tc := NewTracingClient(chatClient, opikClient).
+		WithStreamReconnect(2).
+		WithStreamPrefill(true).
+		WithStreamReconnectBackoff(time.Millisecond, time.Millisecond)
+
+	ctx := context.Background()
+	trace, err := opikClient.Trace(ctx, "chat")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	req := &provider.ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Say hello world."}},
+	}
+	stream, err := tc.CreateChatCompletionStream(opik.ContextWithTrace(ctx, trace), req)
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream error: %v", err)
+	}
+	var content strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv error: %v", err)
+		}
+		content.WriteString(chunk.Choices[0].Delta.Content)
+	}
+	if content.String() != "Hello world" {
+		t.Errorf("content = %q, want %q", content.String(), "Hello world")
+	}
+
+	if len(fake.requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(fake.requests))
+	}
+	resumed := fake.requests[1].Messages
+	if len(resumed) != 2 || resumed[1].Role != provider.RoleAssistant || resumed[1].Content != "Hello" {
+		t.Errorf("resumed messages = %+v, want the prefix appended", resumed)
+	}
+	if len(req.Messages) != 1 {
+		t.Errorf("original request changed: %+v", req.Messages)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(updates) != 1 {
+		t.Fatalf("span updates = %d, want 1", len(updates))
+	}
+	for _, want := range []string{`"stream_reconnects":1`, `"stream_reconnect_errors":["unexpected EOF"]`, `"content":"Hello world"`} {
+		if !strings.Contains(updates[0], want) {
+			t.Errorf("span update missing %s: %s", want, updates[0])
+		}
+	}
+	if strings.Contains(updates[0], "error_info") {
+		t.Errorf("reconnected stream recorded as failed: %s", updates[0])
+	}
+}
+
+func TestTracingStreamReconnectGivesUp(t *testing.T) {
+	fake := &streamingProvider{streams: []*scriptedStream{
+		{contents: []string{"a"}, err: io.ErrUnexpectedEOF},
+		{err: io.ErrUnexpectedEOF},
+	}}
+	chatClient, err := omnillm.NewClient(omnillm.ClientConfig{
+		Providers: []omnillm.ProviderConfig{{CustomProvider: fake}},
+	})
+	if err != nil {
+		t.Fatalf("omnillm.NewClient error: %v", err)
+	}
+	tc := NewTracingClient(chatClient, nil).
+		WithStreamReconnect(1).
+		WithStreamPrefill(true).
+		WithStreamReconnectBackoff(time.Millisecond, time.Millisecond)
+
+	stream, err := tc.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream error: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv error: %v", err)
+	}
+	_, err = stream.Recv()
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !strings.Contains(err.Error(), "after 1 reconnect attempts") {
+		t.Errorf("Recv error = %v, want the drop after 1 reconnect", err)
+	}
+	if len(fake.requests) != 2 {
+		t.Errorf("requests = %d, want 2", len(fake.requests))
+	}
+}
+
+func TestTracingStreamReconnectPolicy(t *testing.T) {
+	badRequest := &omnillm.APIError{StatusCode: http.StatusBadRequest, Message: "bad request"}
+	tests := []struct {
+		name     string
+		prefill  bool
+		streams  []*scriptedStream
+		want     string
+		wantErr  error
+		requests int
+	}{
+		{"eof before finish reason", true, []*scriptedStream{
+			{contents: []string{"Hel"}, err: io.EOF},
+			{contents: []string{"lo"}, finish: "stop", err: io.EOF},
+		}, "Hello", io.EOF, 2},
+		{"no prefill after content", false, []*scriptedStream{
+			{contents: []string{"Hel"}, err: io.ErrUnexpectedEOF},
+		}, "Hel", io.ErrUnexpectedEOF, 1},
+		{"no prefill before content", false, []*scriptedStream{
+			{err: io.ErrUnexpectedEOF},
+			{contents: []string{"Hello"}, finish: "stop", err: io.EOF},
+		}, "Hello", io.EOF, 2},
+		{"client error", true, []*scriptedStream{
+			{contents: []string{"Hel"}, err: badRequest},
+		}, "Hel", badRequest, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &streamingProvider{streams: tt.streams}
+			chatClient, err := omnillm.NewClient(omnillm.ClientConfig{
+				Providers: []omnillm.ProviderConfig{{CustomProvider: fake}},
+			})
+			if err != nil {
+				t.Fatalf("omnillm.NewClient error: %v", err)
+			}
+			tc := NewTracingClient(chatClient, nil).
+				WithStreamReconnect(3).
+				WithStreamPrefill(tt.prefill).
+				WithStreamReconnectBackoff(time.Millisecond, time.Millisecond)
+
+			stream, err := tc.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{Model: "gpt-4o"})
+			if err != nil {
+				t.Fatalf("CreateChatCompletionStream error: %v", err)
+			}
+			var content strings.Builder
+			for {
+				chunk, err := stream.Recv()
+				if err != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Errorf("Recv error = %v, want %v", err, tt.wantErr)
+					}
+					break
+				}
+				content.WriteString(chunk.Choices[0].Delta.Content)
+			}
+			if content.String() != tt.want || len(fake.requests) != tt.requests {
+				t.Errorf("content = %q after %d requests, want %q after %d", content.String(), len(fake.requests), tt.want, tt.requests)
+			}
+		})
+	}
+
+	// The wait between attempts stops with the context.
+	fake := &streamingProvider{streams: []*scriptedStream{{err: io.ErrUnexpectedEOF}}}
+	chatClient, err := omnillm.NewClient(omnillm.ClientConfig{
+		Providers: []omnillm.ProviderConfig{{CustomProvider: fake}},
+	})
+	if err != nil {
+		t.Fatalf("omnillm.NewClient error: %v", err)
+	}
+	tc := NewTracingClient(chatClient, nil).WithStreamReconnect(1).WithStreamReconnectBackoff(time.Hour, time.Hour)
+	if tc.supportsPrefill() {
+		t.Error("supportsPrefill = true for a provider without assistant prefill")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	stream, err := tc.CreateChatCompletionStream(ctx, &provider.ChatCompletionRequest{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream error: %v", err)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, context.DeadlineExceeded) || len(fake.requests) != 1 {
+		t.Errorf("Recv during backoff error = %v after %d requests, want the context's error after 1", err, len(fake.requests))
+	}
+}
+
+func TestTracingClientMaxInputBytes(t *testing.T) {
+	var mu sync.Mutex
+	var spans []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/private/spans") {
+			mu.Lock()
+			spans = append(spans, string(body))
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	opikClient, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	fake := &fakeProvider{}
+	chatClient, err := omnillm.NewClient(omnillm.ClientConfig{
+		Providers: []omnillm.ProviderConfig{{CustomProvider: fake}},
+	})
+	if err != nil {
+		t.Fatalf("omnillm.NewClient error: %v", err)
+	}
+	tc := NewTracingClient(chatClient, opikClient).WithMaxInputBytes(120)
+
+	ctx := context.Background()
+	trace, err := opikClient.Trace(ctx, "chat")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	req := &provider.ChatCompletionRequest{
+		Model: "gpt-4o",
+		Messages: []provider.Message{
+			{Role: provider.RoleSystem, Content: "Be brief."},
+			{Role: provider.RoleUser, Content: strings.Repeat("old ", 30)},
+			{Role: provider.RoleAssistant, Content: strings.Repeat("reply ", 30)},
+			{Role: provider.RoleUser, Content: "latest question"},
+		},
+	}
+	if _, err := tc.CreateChatCompletion(opik.ContextWithTrace(ctx, trace), req); err != nil {
+		t.Fatalf("CreateChatCompletion error: %v", err)
+	}
+
+	if got := len(fake.requests[0].Messages); got != 4 {
+		t.Errorf("model got %d messages, want all 4", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	for _, want := range []string{`"omitted_messages":2`, `Be brief.`, `latest question`} {
+		if !strings.Contains(spans[0], want) {
+			t.Errorf("span missing %s: %s", want, spans[0])
+		}
+	}
+}
+
+func TestTracingClientContextProject(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodPost {
+			mu.Lock()
+			bodies = append(bodies, string(body))
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	opikClient, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"), opik.WithProjectName("main"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	chatClient, err := omnillm.NewClient(omnillm.ClientConfig{
+		Providers: []omnillm.ProviderConfig{{CustomProvider: &fakeProvider{}}},
+		Memory:    omnillmtesting.NewMockKVS(),
+	})
+	if err != nil {
+		t.Fatalf("omnillm.NewClient error: %v", err)
+	}
+	tc := NewTracingClient(chatClient, opikClient).WithMemoryCapture(0)
+
+	ctx := opik.ContextWithProject(context.Background(), "tier-gold")
+	req := &provider.ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "hello"}},
+	}
+	if _, err := tc.CreateChatCompletionWithMemory(ctx, "session-1", req); err != nil {
+		t.Fatalf("CreateChatCompletionWithMemory error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 2 {
+		t.Fatalf("bodies = %d, want a trace and a span", len(bodies))
+	}
+	for _, b := range bodies {
+		if !strings.Contains(b, `"project_name":"tier-gold"`) {
+			t.Errorf("not logged to the context project: %s", b)
+		}
+	}
+}
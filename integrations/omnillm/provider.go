@@ -2,6 +2,8 @@ package omnillm
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/plexusone/omnillm"
 	"github.com/plexusone/omnillm/provider"
@@ -56,6 +58,29 @@ func WithMaxTokens(max int) Option {
 
 // Complete sends a chat completion request using omnillm.
 func (p *Provider) Complete(ctx context.Context, req llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	return p.send(ctx, p.request(req))
+}
+
+// CompleteStructured sends a chat completion request in JSON mode, with a
+// system message giving the JSON Schema of the response. Providers without
+// a JSON mode ignore it and follow the instruction alone; judges validate
+// the response against the schema either way.
+func (p *Provider) CompleteStructured(ctx context.Context, req llm.CompletionRequest, schema llm.ResponseSchema) (*llm.CompletionResponse, error) {
+	data, err := json.Marshal(schema.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("encoding response schema: %w", err)
+	}
+	omnillmReq := p.request(req)
+	omnillmReq.ResponseFormat = &provider.ResponseFormat{Type: "json_object"}
+	omnillmReq.Messages = append(omnillmReq.Messages, provider.Message{
+		Role:    provider.RoleSystem,
+		Content: fmt.Sprintf("Respond with only a JSON object (%s: %s) matching this JSON Schema:\n%s", schema.Name, schema.Description, data),
+	})
+	return p.send(ctx, omnillmReq)
+}
+
+// request converts req to an omnillm request.
+func (p *Provider) request(req llm.CompletionRequest) *provider.ChatCompletionRequest {
 	// Convert llm.Message to omnillm provider.Message
 	messages := make([]provider.Message, len(req.Messages))
 	for i, m := range req.Messages {
@@ -91,8 +116,11 @@ func (p *Provider) Complete(ctx context.Context, req llm.CompletionRequest) (*ll
 	if maxTokens != 0 {
 		omnillmReq.MaxTokens = &maxTokens
 	}
+	return omnillmReq
+}
 
-	// Make the call
+// send makes the call and converts the response.
+func (p *Provider) send(ctx context.Context, omnillmReq *provider.ChatCompletionRequest) (*llm.CompletionResponse, error) {
 	resp, err := p.client.CreateChatCompletion(ctx, omnillmReq)
 	if err != nil {
 		return nil, err
@@ -122,5 +150,5 @@ func (p *Provider) DefaultModel() string {
 	return p.model
 }
 
-// Ensure Provider implements llm.Provider.
-var _ llm.Provider = (*Provider)(nil)
+// Ensure Provider implements llm.StructuredProvider.
+var _ llm.StructuredProvider = (*Provider)(nil)
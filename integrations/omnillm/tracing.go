@@ -2,14 +2,20 @@ package omnillm
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/plexusone/omnillm"
 	"github.com/plexusone/omnillm/provider"
 
 	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/evaluation/llm"
+	"github.com/plexusone/opik-go/internal/backoff"
 )
 
 // TracingClient wraps an omnillm.ChatClient with automatic Opik tracing.
@@ -17,6 +23,16 @@ type TracingClient struct {
 	client      *omnillm.ChatClient
 	opikClient  *opik.Client
 	spanOptions []opik.SpanOption
+
+	captureMemory      bool
+	maxTranscriptChars int
+	maxInputBytes      int
+	maxReconnects      int
+	reconnectBackoff   time.Duration
+	maxReconnectWait   time.Duration
+	// prefill overrides whether the provider continues from a trailing
+	// assistant message; nil uses providerSupportsPrefill.
+	prefill *bool
 }
 
 // NewTracingClient creates a new tracing client wrapper.
@@ -28,14 +44,156 @@ func NewTracingClient(client *omnillm.ChatClient, opikClient *opik.Client, opts
 	}
 }
 
+// DefaultMaxTranscriptChars is the default length of the conversation
+// transcript recorded by WithMemoryCapture.
+const DefaultMaxTranscriptChars = 4000
+
+// WithMemoryCapture records the conversation history resolved from memory on
+// CreateChatCompletionWithMemory spans: the number of stored messages, a
+// transcript of the conversation truncated to its last maxTranscriptChars
+// characters (DefaultMaxTranscriptChars if zero or less), and the estimated
+// token usage of the history.
+//
+// Calls made outside a trace are recorded in a new trace whose thread ID is
+// the session ID, so the turns of a session are grouped into one thread.
+func (t *TracingClient) WithMemoryCapture(maxTranscriptChars int) *TracingClient {
+	if maxTranscriptChars <= 0 {
+		maxTranscriptChars = DefaultMaxTranscriptChars
+	}
+	t.captureMemory = true
+	t.maxTranscriptChars = maxTranscriptChars
+	return t
+}
+
+// WithMaxInputBytes limits the messages recorded as a span's input to
+// about maxBytes of JSON. Longer conversations are recorded without their
+// oldest messages, keeping the system messages and the latest user turn
+// (see llm.TruncateMessagesFunc), and the span's metadata records the
+// number left out under llm.MetadataOmittedMessages. The request sent to
+// the model is not changed.
+func (t *TracingClient) WithMaxInputBytes(maxBytes int) *TracingClient {
+	t.maxInputBytes = maxBytes
+	return t
+}
+
+// Metadata keys recorded on the spans of streams that reconnected.
+const (
+	// MetadataStreamReconnects is the number of times the stream was
+	// re-issued after it dropped.
+	MetadataStreamReconnects = "stream_reconnects"
+	// MetadataStreamReconnectErrors holds the errors that dropped the
+	// stream, in order.
+	MetadataStreamReconnectErrors = "stream_reconnect_errors"
+)
+
+// Defaults for the waits between stream reconnects, unless set with
+// WithStreamReconnectBackoff.
+const (
+	DefaultStreamReconnectBackoff = 500 * time.Millisecond
+	DefaultMaxStreamReconnectWait = 10 * time.Second
+)
+
+// ErrStreamTruncated is the error a stream created with WithStreamReconnect
+// drops with when it ends before the provider sent a finish reason. It
+// wraps io.ErrUnexpectedEOF.
+var ErrStreamTruncated = fmt.Errorf("omnillm stream ended before a finish reason: %w", io.ErrUnexpectedEOF)
+
+// WithStreamReconnect makes streams from CreateChatCompletionStream
+// reconnect up to maxAttempts times when they drop mid-response with a
+// transient error: a network error, a rate limit or a server error, or an
+// end of stream before the provider sent a finish reason, which Recv
+// reports as ErrStreamTruncated. Other errors, such as a 4xx response, and
+// the context's errors never reconnect. Attempts wait with exponential
+// backoff, as set with WithStreamReconnectBackoff.
+//
+// If the provider supports assistant prefill (see WithStreamPrefill), the
+// request is re-issued with the content received so far as a trailing
+// assistant message, and the chunks of the new stream are returned from
+// Recv as if the stream had not dropped. Otherwise a stream is only
+// re-issued while no content has been returned, since the provider would
+// start the response over. The reconnects and the errors that caused them
+// are recorded on the span under MetadataStreamReconnects and
+// MetadataStreamReconnectErrors. If the stream still fails, Recv returns an
+// error wrapping the last one.
+func (t *TracingClient) WithStreamReconnect(maxAttempts int) *TracingClient {
+	t.maxReconnects = maxAttempts
+	return t
+}
+
+// WithStreamReconnectBackoff sets the wait before the first stream
+// reconnect, which doubles for each further attempt up to maxWait. It
+// defaults to DefaultStreamReconnectBackoff and DefaultMaxStreamReconnectWait.
+func (t *TracingClient) WithStreamReconnectBackoff(initial, maxWait time.Duration) *TracingClient {
+	t.reconnectBackoff = initial
+	t.maxReconnectWait = maxWait
+	return t
+}
+
+// WithStreamPrefill sets whether the provider continues a response from a
+// trailing assistant message, which lets WithStreamReconnect resume a
+// stream that dropped after returning content. By default this is true
+// for Anthropic and false for other providers, which treat the message as
+// a finished turn and would repeat or restart the response.
+func (t *TracingClient) WithStreamPrefill(supported bool) *TracingClient {
+	t.prefill = &supported
+	return t
+}
+
+// prefillProviders are the providers that continue a response from a
+// trailing assistant message.
+var prefillProviders = map[string]bool{
+	string(omnillm.ProviderNameAnthropic): true,
+}
+
+// supportsPrefill reports whether streams can resume from the content
+// received so far.
+func (t *TracingClient) supportsPrefill() bool {
+	if t.prefill != nil {
+		return *t.prefill
+	}
+	p := t.client.Provider()
+	return p != nil && prefillProviders[p.Name()]
+}
+
+// reconnectWaits returns the initial and longest waits between stream
+// reconnects.
+func (t *TracingClient) reconnectWaits() (time.Duration, time.Duration) {
+	initial, maxWait := t.reconnectBackoff, t.maxReconnectWait
+	if initial <= 0 {
+		initial = DefaultStreamReconnectBackoff
+	}
+	if maxWait <= 0 {
+		maxWait = DefaultMaxStreamReconnectWait
+	}
+	return initial, max(initial, maxWait)
+}
+
+// inputOptions returns the span options that record req as the input,
+// within the client's input limit, and metadata, if not nil, with the
+// number of messages left out.
+func (t *TracingClient) inputOptions(req *provider.ChatCompletionRequest, metadata map[string]any) []opik.SpanOption {
+	input, omitted := llm.TruncateInputMessages(requestToMap(req), t.maxInputBytes)
+	if omitted > 0 {
+		if metadata == nil {
+			metadata = make(map[string]any, 1)
+		}
+		metadata[llm.MetadataOmittedMessages] = omitted
+	}
+	opts := []opik.SpanOption{opik.WithSpanInput(input)}
+	if metadata != nil {
+		opts = append(opts, opik.WithSpanMetadata(metadata))
+	}
+	return opts
+}
+
 // CreateChatCompletion creates a chat completion with automatic tracing.
 func (t *TracingClient) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
 	// Prepare span options
 	opts := append([]opik.SpanOption{
 		opik.WithSpanType(opik.SpanTypeLLM),
 		opik.WithSpanProvider("omnillm"),
-		opik.WithSpanInput(requestToMap(req)),
-	}, t.spanOptions...)
+	}, t.inputOptions(req, nil)...)
+	opts = append(opts, t.spanOptions...)
 
 	if req.Model != "" {
 		opts = append(opts, opik.WithSpanModel(req.Model))
@@ -73,13 +231,14 @@ func (t *TracingClient) CreateChatCompletion(ctx context.Context, req *provider.
 			if resp.Model != "" {
 				metadata["model"] = resp.Model
 			}
+			setSpanUsage(span, resp.Usage, resp.ProviderMetadata, metadata)
 			endOpts = append(endOpts, opik.WithSpanMetadata(metadata))
 		}
 
 		if respErr != nil {
 			endOpts = append(endOpts, opik.WithSpanMetadata(map[string]any{
 				"error": respErr.Error(),
-			}))
+			}), opik.WithSpanError(respErr))
 		}
 
 		_ = span.End(ctx, endOpts...)
@@ -94,8 +253,8 @@ func (t *TracingClient) CreateChatCompletionStream(ctx context.Context, req *pro
 	opts := append([]opik.SpanOption{
 		opik.WithSpanType(opik.SpanTypeLLM),
 		opik.WithSpanProvider("omnillm"),
-		opik.WithSpanInput(requestToMap(req)),
-	}, t.spanOptions...)
+	}, t.inputOptions(req, nil)...)
+	opts = append(opts, t.spanOptions...)
 
 	if req.Model != "" {
 		opts = append(opts, opik.WithSpanModel(req.Model))
@@ -118,29 +277,46 @@ func (t *TracingClient) CreateChatCompletionStream(ctx context.Context, req *pro
 		if span != nil && err == nil {
 			_ = span.End(ctx, opik.WithSpanMetadata(map[string]any{
 				"error": streamErr.Error(),
-			}))
+			}), opik.WithSpanError(streamErr))
 		}
 		return nil, streamErr
 	}
 
-	// Wrap stream to capture output when complete
-	return &tracingStream{
-		stream:    stream,
-		span:      span,
-		ctx:       ctx,
-		startTime: time.Now(),
-	}, nil
+	// Wrap stream to capture output when complete. The stream implements
+	// AbortableStream.
+	s := &tracingStream{
+		stream:        stream,
+		span:          span,
+		ctx:           ctx,
+		startTime:     time.Now(),
+		client:        t.client,
+		req:           req,
+		maxReconnects: t.maxReconnects,
+	}
+	if t.maxReconnects > 0 {
+		s.prefill = t.supportsPrefill()
+		s.reconnectBackoff, s.maxReconnectWait = t.reconnectWaits()
+	}
+	return s, nil
 }
 
 // CreateChatCompletionWithMemory creates a chat completion using conversation memory with tracing.
+// The span's metadata includes the session ID as "thread_id".
 func (t *TracingClient) CreateChatCompletionWithMemory(ctx context.Context, sessionID string, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	startMetadata := map[string]any{
+		"session_id": sessionID,
+		"thread_id":  sessionID,
+	}
+	if t.captureMemory {
+		t.addMemoryMetadata(ctx, sessionID, req, startMetadata)
+	}
+
 	// Prepare span options
 	opts := append([]opik.SpanOption{
 		opik.WithSpanType(opik.SpanTypeLLM),
 		opik.WithSpanProvider("omnillm"),
-		opik.WithSpanInput(requestToMap(req)),
-		opik.WithSpanMetadata(map[string]any{"session_id": sessionID}),
-	}, t.spanOptions...)
+	}, t.inputOptions(req, startMetadata)...)
+	opts = append(opts, t.spanOptions...)
 
 	if req.Model != "" {
 		opts = append(opts, opik.WithSpanModel(req.Model))
@@ -149,11 +325,19 @@ func (t *TracingClient) CreateChatCompletionWithMemory(ctx context.Context, sess
 	// Try to create span from context
 	var span *opik.Span
 	var err error
+	var threadTrace *opik.Trace
 
 	if parentSpan := opik.SpanFromContext(ctx); parentSpan != nil {
 		span, err = parentSpan.Span(ctx, "omnillm.chat.memory", opts...)
 	} else if trace := opik.TraceFromContext(ctx); trace != nil {
 		span, err = trace.Span(ctx, "omnillm.chat.memory", opts...)
+	} else if t.captureMemory && t.opikClient != nil {
+		threadTrace, err = t.opikClient.Trace(ctx, "omnillm.chat.memory",
+			opik.WithTraceInput(requestToMap(req)),
+			opik.WithTraceThreadID(sessionID))
+		if err == nil {
+			span, err = threadTrace.Span(ctx, "omnillm.chat.memory", opts...)
+		}
 	}
 
 	// Execute request
@@ -174,21 +358,81 @@ func (t *TracingClient) CreateChatCompletionWithMemory(ctx context.Context, sess
 				"completion_tokens": resp.Usage.CompletionTokens,
 				"total_tokens":      resp.Usage.TotalTokens,
 			}
+			setSpanUsage(span, resp.Usage, resp.ProviderMetadata, metadata)
 			endOpts = append(endOpts, opik.WithSpanMetadata(metadata))
 		}
 
 		if respErr != nil {
 			endOpts = append(endOpts, opik.WithSpanMetadata(map[string]any{
 				"error": respErr.Error(),
-			}))
+			}), opik.WithSpanError(respErr))
 		}
 
 		_ = span.End(ctx, endOpts...)
 	}
 
+	if threadTrace != nil {
+		var traceOpts []opik.TraceOption
+		if resp != nil {
+			traceOpts = append(traceOpts, opik.WithTraceOutput(responseToMap(resp)))
+		}
+		_ = threadTrace.End(ctx, traceOpts...)
+	}
+
 	return resp, respErr
 }
 
+// addMemoryMetadata records the conversation history sent to the model:
+// the messages stored for the session followed by the request's messages.
+func (t *TracingClient) addMemoryMetadata(ctx context.Context, sessionID string, req *provider.ChatCompletionRequest, metadata map[string]any) {
+	if t.client == nil || !t.client.HasMemory() {
+		return
+	}
+	stored, err := t.client.GetConversationMessages(ctx, sessionID)
+	if err != nil {
+		metadata["memory_error"] = err.Error()
+		return
+	}
+
+	history := make([]provider.Message, 0, len(stored)+len(req.Messages))
+	history = append(history, stored...)
+	history = append(history, req.Messages...)
+
+	metadata["memory_message_count"] = len(stored)
+	metadata["conversation_message_count"] = len(history)
+	metadata["memory_transcript"] = transcript(history, t.maxTranscriptChars)
+
+	estimator := t.client.TokenEstimator()
+	if estimator == nil {
+		estimator = omnillm.NewTokenEstimator(omnillm.DefaultTokenEstimatorConfig())
+	}
+	if tokens, err := estimator.EstimateTokens(req.Model, stored); err == nil {
+		metadata["memory_tokens"] = tokens
+	}
+}
+
+// transcript formats messages as "role: content" lines, keeping the last
+// maxChars characters so the most recent turns are recorded.
+func transcript(messages []provider.Message, maxChars int) string {
+	var b strings.Builder
+	for _, msg := range messages {
+		b.WriteString(string(msg.Role))
+		b.WriteString(": ")
+		b.WriteString(msg.Content)
+		b.WriteString("\n")
+	}
+	text := strings.TrimSuffix(b.String(), "\n")
+	if maxChars <= 0 || len(text) <= maxChars {
+		return text
+	}
+	cut := len(text) - maxChars
+	// Don't split a multi-byte character.
+	for cut < len(text) && !utf8.RuneStart(text[cut]) {
+		cut++
+	}
+	return "..." + text[cut:]
+}
+
 // Close closes the underlying client.
 func (t *TracingClient) Close() error {
 	return t.client.Close()
@@ -199,6 +443,25 @@ func (t *TracingClient) Client() *omnillm.ChatClient {
 	return t.client
 }
 
+// AbortableStream is a stream that can be abandoned before it finishes.
+// Streams returned by TracingClient.CreateChatCompletionStream implement it:
+//
+//	stream, _ := tracingClient.CreateChatCompletionStream(ctx, req)
+//	if s, ok := stream.(opikomnillm.AbortableStream); ok {
+//	    defer s.Abort("handler returned early")
+//	}
+type AbortableStream interface {
+	provider.ChatCompletionStream
+
+	// Abort ends the span with a "cancelled" status, the reason, the length
+	// of the content received so far and the elapsed time, then closes the
+	// stream. It does nothing if the span has already ended, except close
+	// the stream.
+	Abort(reason string) error
+}
+
+var _ AbortableStream = (*tracingStream)(nil)
+
 // tracingStream wraps a ChatCompletionStream to capture the complete response.
 type tracingStream struct {
 	stream    provider.ChatCompletionStream
@@ -211,23 +474,63 @@ type tracingStream struct {
 	model          string
 	usage          *provider.Usage
 	closed         bool
+
+	// finished is set once a chunk carries a finish reason.
+	finished bool
+
+	// client and req re-issue the request when the stream drops, up to
+	// maxReconnects times, waiting with backoff between attempts. Unless
+	// prefill is set, the request is only re-issued before any content.
+	client           *omnillm.ChatClient
+	req              *provider.ChatCompletionRequest
+	maxReconnects    int
+	prefill          bool
+	reconnectBackoff time.Duration
+	maxReconnectWait time.Duration
+	reconnectErrors  []string
 }
 
-// Recv receives the next chunk from the stream.
+// Recv receives the next chunk from the stream. Once the stream's context
+// is cancelled, Recv aborts the span and returns an error wrapping the
+// context's error without reading from the underlying stream. A stream
+// created with WithStreamReconnect that drops is re-issued first.
 func (s *tracingStream) Recv() (*provider.ChatCompletionChunk, error) {
-	chunk, err := s.stream.Recv()
-	if err != nil {
-		if err == io.EOF && !s.closed {
-			s.endSpan(nil)
-			s.closed = true
-		} else if err.Error() == "EOF" && !s.closed {
-			s.endSpan(nil)
+	if err := s.ctx.Err(); err != nil {
+		err = fmt.Errorf("omnillm stream canceled: %w", err)
+		if !s.closed {
+			s.endSpan(err, err.Error())
 			s.closed = true
 		}
+		return nil, err
+	}
+
+	chunk, err := s.receive()
+	for err != nil && !s.closed && s.canReconnect(err) {
+		chunk, err = s.reconnect(err)
+	}
+	if err != nil && !s.closed && len(s.reconnectErrors) > 0 && s.dropped(err) {
+		err = fmt.Errorf("omnillm stream dropped after %d reconnect attempts: %w", len(s.reconnectErrors), err)
+	}
+	if err != nil {
+		if s.closed {
+			return chunk, err
+		}
+		s.closed = true
+		switch {
+		case err == io.EOF || err.Error() == "EOF":
+			s.endSpan(nil, "")
+		case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+			s.endSpan(err, err.Error())
+		default:
+			s.endSpan(err, "")
+		}
 		return chunk, err
 	}
 
 	// Buffer the response content
+	if len(chunk.Choices) > 0 && chunk.Choices[0].FinishReason != nil && *chunk.Choices[0].FinishReason != "" {
+		s.finished = true
+	}
 	if len(chunk.Choices) > 0 && chunk.Choices[0].Delta != nil {
 		s.responseBuffer.WriteString(chunk.Choices[0].Delta.Content)
 	}
@@ -245,17 +548,96 @@ func (s *tracingStream) Recv() (*provider.ChatCompletionChunk, error) {
 	return chunk, nil
 }
 
+// receive receives the next chunk from the underlying stream. A stream
+// that reconnects and ends before a finish reason fails with
+// ErrStreamTruncated.
+func (s *tracingStream) receive() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.stream.Recv()
+	if s.maxReconnects > 0 && !s.finished && err != nil && (err == io.EOF || err.Error() == "EOF") {
+		return chunk, ErrStreamTruncated
+	}
+	return chunk, err
+}
+
+// dropped reports whether err ended the stream before it finished, other
+// than by the end of the response or the context.
+func (s *tracingStream) dropped(err error) bool {
+	if err == io.EOF || err.Error() == "EOF" || s.ctx.Err() != nil {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// canReconnect reports whether the stream may be re-issued after err: the
+// error is transient, attempts remain, and either the provider continues
+// from the content received so far or there is none.
+func (s *tracingStream) canReconnect(err error) bool {
+	if len(s.reconnectErrors) >= s.maxReconnects || !s.dropped(err) {
+		return false
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) && omnillm.ClassifyError(err) != omnillm.ErrorCategoryRetryable {
+		return false
+	}
+	return s.prefill || s.responseBuffer.Len() == 0
+}
+
+// reconnect records the error that dropped the stream, waits out the
+// backoff, and re-issues the request, continuing from the content received
+// so far, then receives the first chunk of the new stream.
+func (s *tracingStream) reconnect(cause error) (*provider.ChatCompletionChunk, error) {
+	s.reconnectErrors = append(s.reconnectErrors, cause.Error())
+	_ = s.stream.Close()
+
+	wait := backoff.Exponential(s.reconnectBackoff, s.maxReconnectWait, 0.2, len(s.reconnectErrors)-1)
+	if err := backoff.Sleep(s.ctx, wait); err != nil {
+		err = fmt.Errorf("omnillm stream canceled: %w", err)
+		s.stream = errStream{err}
+		return nil, err
+	}
+
+	req := *s.req
+	if s.prefill && s.responseBuffer.Len() > 0 {
+		req.Messages = append(slices.Clip(s.req.Messages), provider.Message{
+			Role:    provider.RoleAssistant,
+			Content: s.responseBuffer.String(),
+		})
+	}
+	stream, err := s.client.CreateChatCompletionStream(s.ctx, &req)
+	if err != nil {
+		s.stream = errStream{err}
+		return nil, err
+	}
+	s.stream = stream
+	return s.receive()
+}
+
+// errStream is a stream that failed to reconnect.
+type errStream struct{ err error }
+
+func (s errStream) Recv() (*provider.ChatCompletionChunk, error) { return nil, s.err }
+func (s errStream) Close() error                                 { return nil }
+
 // Close closes the stream and ends the span.
 func (s *tracingStream) Close() error {
 	if !s.closed {
-		s.endSpan(nil)
+		s.endSpan(nil, "")
 		s.closed = true
 	}
 	return s.stream.Close()
 }
 
-// endSpan ends the span with the collected response data.
-func (s *tracingStream) endSpan(err error) {
+// Abort ends the span as cancelled and closes the stream.
+func (s *tracingStream) Abort(reason string) error {
+	if !s.closed {
+		s.endSpan(nil, reason)
+		s.closed = true
+	}
+	return s.stream.Close()
+}
+
+// endSpan ends the span with the collected response data. A non-empty
+// abortReason marks the stream as cancelled before it finished.
+func (s *tracingStream) endSpan(err error, abortReason string) {
 	if s.span == nil {
 		return
 	}
@@ -283,16 +665,56 @@ func (s *tracingStream) endSpan(err error) {
 		metadata["prompt_tokens"] = s.usage.PromptTokens
 		metadata["completion_tokens"] = s.usage.CompletionTokens
 		metadata["total_tokens"] = s.usage.TotalTokens
+		setSpanUsage(s.span, *s.usage, nil, metadata)
+	}
+	if err != nil {
+		metadata["error"] = err.Error()
+	}
+	if len(s.reconnectErrors) > 0 {
+		metadata[MetadataStreamReconnects] = len(s.reconnectErrors)
+		metadata[MetadataStreamReconnectErrors] = s.reconnectErrors
+	}
+	if abortReason != "" {
+		metadata[opik.MetadataStreamStatus] = opik.StreamStatusCancelled
+		metadata[opik.MetadataAbortReason] = abortReason
+		metadata[opik.MetadataPartialContentLength] = s.responseBuffer.Len()
+		metadata[opik.MetadataElapsedMs] = duration.Milliseconds()
 	}
 	endOpts = append(endOpts, opik.WithSpanMetadata(metadata))
-
 	if err != nil {
-		endOpts = append(endOpts, opik.WithSpanMetadata(map[string]any{
-			"error": err.Error(),
-		}))
+		endOpts = append(endOpts, opik.WithSpanError(err))
+	}
+
+	// The span is recorded even if the stream was cancelled.
+	_ = s.span.End(context.WithoutCancel(s.ctx), endOpts...)
+}
+
+// setSpanUsage records usage on the span, including prompt cache and
+// reasoning tokens reported in provider metadata. Responses served from
+// omnillm's response cache cost nothing and are flagged in metadata.
+func setSpanUsage(span *opik.Span, usage provider.Usage, providerMetadata map[string]any, metadata map[string]any) {
+	raw := map[string]any{
+		opik.UsagePromptTokens:     usage.PromptTokens,
+		opik.UsageCompletionTokens: usage.CompletionTokens,
+		opik.UsageTotalTokens:      usage.TotalTokens,
+	}
+	for _, key := range []string{"cache_read_input_tokens", "cache_creation_input_tokens", "prompt_tokens_details", "completion_tokens_details"} {
+		if v, ok := providerMetadata[key]; ok {
+			raw[key] = v
+		}
 	}
+	normalized := opik.NormalizeUsage(raw)
+	for _, key := range []string{opik.UsageCacheReadTokens, opik.UsageCacheWriteTokens, opik.UsageReasoningTokens} {
+		if n, ok := normalized[key]; ok {
+			metadata[key] = n
+		}
+	}
+	span.SetUsage(normalized)
 
-	_ = s.span.End(s.ctx, endOpts...)
+	if hit, _ := providerMetadata["cache_hit"].(bool); hit {
+		metadata["cache_hit"] = true
+		span.SetCost(0)
+	}
 }
 
 // requestToMap converts a ChatCompletionRequest to a map for span input.
@@ -0,0 +1,364 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// isEventStream reports whether resp is a server-sent event stream, as
+// returned for requests with "stream": true.
+func isEventStream(resp *http.Response) bool {
+	if resp == nil || resp.Body == nil {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return mediaType == "text/event-stream"
+}
+
+// streamRecorder passes a streamed response through unchanged while it
+// assembles the events into the response the same request would have
+// returned without streaming. The span ends when the stream is read to its
+// end, fails, or is closed.
+type streamRecorder struct {
+	body      io.ReadCloser
+	ctx       context.Context
+	transport *TracingTransport
+	span      *opik.Span
+	resp      *http.Response
+	start     time.Time
+
+	mu         sync.Mutex
+	line       []byte
+	events     int
+	firstEvent time.Time
+	done       bool
+	stream     streamState
+	endOnce    sync.Once
+}
+
+func newStreamRecorder(ctx context.Context, t *TracingTransport, span *opik.Span, resp *http.Response, start time.Time) *streamRecorder {
+	return &streamRecorder{
+		body:      resp.Body,
+		ctx:       context.WithoutCancel(ctx),
+		transport: t,
+		span:      span,
+		resp:      resp,
+		start:     start,
+	}
+}
+
+// Read implements io.Reader.
+func (r *streamRecorder) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.mu.Lock()
+	r.feed(p[:n])
+	if errors.Is(err, io.EOF) {
+		// Some gateways omit the final [DONE] event.
+		r.done = true
+	}
+	r.mu.Unlock()
+	switch {
+	case errors.Is(err, io.EOF):
+		r.end(nil)
+	case err != nil:
+		r.end(err)
+	}
+	return n, err
+}
+
+// Close implements io.Closer. Closing the stream before it finished
+// records the span as cancelled, with the output received so far.
+func (r *streamRecorder) Close() error {
+	err := r.body.Close()
+	r.end(nil)
+	return err
+}
+
+// feed splits data into lines and records each complete data line.
+func (r *streamRecorder) feed(data []byte) {
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			r.line = append(r.line, data...)
+			return
+		}
+		line := data[:i]
+		if len(r.line) > 0 {
+			r.line = append(r.line, line...)
+			line = r.line
+		}
+		r.record(bytes.TrimRight(line, "\r"))
+		r.line = r.line[:0]
+		data = data[i+1:]
+	}
+}
+
+// record records one line of the stream.
+func (r *streamRecorder) record(line []byte) {
+	payload, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return
+	}
+	payload = bytes.TrimSpace(payload)
+	if string(payload) == "[DONE]" {
+		r.done = true
+		return
+	}
+	var event map[string]any
+	if json.Unmarshal(payload, &event) != nil {
+		return
+	}
+	if r.events == 0 {
+		r.firstEvent = time.Now()
+	}
+	r.events++
+	if r.stream.add(event) {
+		r.done = true
+	}
+}
+
+// end ends the span once. err is the error that ended the stream, if any.
+func (r *streamRecorder) end(err error) {
+	r.endOnce.Do(func() {
+		r.mu.Lock()
+		if len(r.line) > 0 {
+			r.record(bytes.TrimRight(r.line, "\r"))
+			r.line = nil
+		}
+		output := r.stream.output()
+		metadata := map[string]any{
+			"streaming":   true,
+			"chunk_count": r.events,
+			"duration_ms": time.Since(r.start).Milliseconds(),
+		}
+		if r.events > 0 {
+			metadata["time_to_first_chunk"] = r.firstEvent.Sub(r.start).Milliseconds()
+		}
+		switch {
+		case err != nil:
+			metadata["error"] = err.Error()
+		case !r.done:
+			metadata[opik.MetadataStreamStatus] = opik.StreamStatusCancelled
+			metadata[opik.MetadataAbortReason] = "stream closed before it finished"
+		}
+		r.mu.Unlock()
+
+		r.transport.endSpan(r.ctx, r.span, r.resp, output, metadata)
+	})
+}
+
+// streamState assembles the events of a Chat Completions, Completions, or
+// Responses stream.
+type streamState struct {
+	// response is the final response of a Responses stream.
+	response map[string]any
+	// text is the output text of a Responses stream that did not complete.
+	text strings.Builder
+
+	id, model, object string
+	choices           map[int]*streamChoice
+	usage             map[string]any
+}
+
+// streamChoice is one choice of a Chat Completions or Completions stream.
+type streamChoice struct {
+	content      strings.Builder
+	reasoning    strings.Builder
+	text         strings.Builder
+	role         string
+	finishReason any
+	toolCalls    map[int]*streamToolCall
+}
+
+type streamToolCall struct {
+	id, kind, name string
+	arguments      strings.Builder
+}
+
+// add records one event and reports whether it completes the stream.
+func (s *streamState) add(event map[string]any) bool {
+	if kind, ok := event["type"].(string); ok && strings.HasPrefix(kind, "response.") {
+		return s.addResponseEvent(kind, event)
+	}
+
+	if id, ok := event["id"].(string); ok {
+		s.id = id
+	}
+	if model, ok := event["model"].(string); ok {
+		s.model = model
+	}
+	if object, ok := event["object"].(string); ok {
+		s.object = strings.TrimSuffix(object, ".chunk")
+	}
+	if usage, ok := event["usage"].(map[string]any); ok {
+		s.usage = usage
+	}
+	choices, _ := event["choices"].([]any)
+	for _, c := range choices {
+		choice, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		index, _ := choice["index"].(float64)
+		sc := s.choice(int(index))
+		if reason, ok := choice["finish_reason"]; ok && reason != nil {
+			sc.finishReason = reason
+		}
+		if text, ok := choice["text"].(string); ok {
+			sc.text.WriteString(text)
+		}
+		delta, _ := choice["delta"].(map[string]any)
+		if role, ok := delta["role"].(string); ok {
+			sc.role = role
+		}
+		if content, ok := delta["content"].(string); ok {
+			sc.content.WriteString(content)
+		}
+		if reasoning, ok := delta["reasoning_content"].(string); ok {
+			sc.reasoning.WriteString(reasoning)
+		}
+		calls, _ := delta["tool_calls"].([]any)
+		for _, tc := range calls {
+			sc.addToolCall(tc)
+		}
+	}
+	return false
+}
+
+// addResponseEvent records an event of a Responses stream. The final
+// event carries the whole response.
+func (s *streamState) addResponseEvent(kind string, event map[string]any) bool {
+	switch kind {
+	case "response.output_text.delta":
+		if delta, ok := event["delta"].(string); ok {
+			s.text.WriteString(delta)
+		}
+	case "response.completed", "response.incomplete", "response.failed":
+		if response, ok := event["response"].(map[string]any); ok {
+			s.response = response
+		}
+		return true
+	}
+	return false
+}
+
+func (s *streamState) choice(index int) *streamChoice {
+	if s.choices == nil {
+		s.choices = make(map[int]*streamChoice)
+	}
+	c, ok := s.choices[index]
+	if !ok {
+		c = &streamChoice{}
+		s.choices[index] = c
+	}
+	return c
+}
+
+// addToolCall merges a tool call delta. The first delta of a call carries
+// its ID and function name; later ones append to its arguments.
+func (c *streamChoice) addToolCall(delta any) {
+	d, ok := delta.(map[string]any)
+	if !ok {
+		return
+	}
+	index, _ := d["index"].(float64)
+	if c.toolCalls == nil {
+		c.toolCalls = make(map[int]*streamToolCall)
+	}
+	call, ok := c.toolCalls[int(index)]
+	if !ok {
+		call = &streamToolCall{}
+		c.toolCalls[int(index)] = call
+	}
+	if id, ok := d["id"].(string); ok {
+		call.id = id
+	}
+	if kind, ok := d["type"].(string); ok {
+		call.kind = kind
+	}
+	fn, _ := d["function"].(map[string]any)
+	if name, ok := fn["name"].(string); ok {
+		call.name = name
+	}
+	if args, ok := fn["arguments"].(string); ok {
+		call.arguments.WriteString(args)
+	}
+}
+
+// output returns the assembled response, in the shape of the response to
+// the same request without streaming, or nil if no event was recorded.
+func (s *streamState) output() map[string]any {
+	if s.response != nil {
+		return s.response
+	}
+	if s.text.Len() > 0 {
+		return map[string]any{"object": "response", "output_text": s.text.String()}
+	}
+	if s.choices == nil && s.usage == nil {
+		return nil
+	}
+
+	choices := make([]any, 0, len(s.choices))
+	for _, i := range slices.Sorted(maps.Keys(s.choices)) {
+		c := s.choices[i]
+		choice := map[string]any{"index": i, "finish_reason": c.finishReason}
+		if s.object == "text_completion" {
+			choice["text"] = c.text.String()
+		} else {
+			choice["message"] = c.message()
+		}
+		choices = append(choices, choice)
+	}
+
+	out := map[string]any{"object": s.object, "choices": choices}
+	if s.id != "" {
+		out["id"] = s.id
+	}
+	if s.model != "" {
+		out["model"] = s.model
+	}
+	if s.usage != nil {
+		out["usage"] = s.usage
+	}
+	return out
+}
+
+// message returns the assembled message of a chat completion choice.
+func (c *streamChoice) message() map[string]any {
+	role := c.role
+	if role == "" {
+		role = "assistant"
+	}
+	msg := map[string]any{"role": role, "content": c.content.String()}
+	if c.reasoning.Len() > 0 {
+		msg["reasoning_content"] = c.reasoning.String()
+	}
+	if len(c.toolCalls) > 0 {
+		calls := make([]any, 0, len(c.toolCalls))
+		for _, i := range slices.Sorted(maps.Keys(c.toolCalls)) {
+			tc := c.toolCalls[i]
+			calls = append(calls, map[string]any{
+				"id":   tc.id,
+				"type": tc.kind,
+				"function": map[string]any{
+					"name":      tc.name,
+					"arguments": tc.arguments.String(),
+				},
+			})
+		}
+		msg["tool_calls"] = calls
+	}
+	return msg
+}
@@ -0,0 +1,219 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// spanUpdates records the span updates sent to a test Opik server.
+type spanUpdates struct {
+	mu      sync.Mutex
+	updates []map[string]any
+}
+
+func (s *spanUpdates) last(t *testing.T) map[string]any {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.updates) == 0 {
+		t.Fatal("no span update was sent")
+	}
+	return s.updates[len(s.updates)-1]
+}
+
+// streamTrace returns a context carrying a trace of an Opik client whose
+// span updates are recorded.
+func streamTrace(t *testing.T) (context.Context, *opik.Client, *spanUpdates) {
+	t.Helper()
+	updates := &spanUpdates{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && r.URL.Path == "/v1/private/spans/batch" {
+			var req struct {
+				Update map[string]any `json:"update"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			updates.mu.Lock()
+			updates.updates = append(updates.updates, req.Update)
+			updates.mu.Unlock()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(ts.Close)
+
+	client, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "chat")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	return opik.ContextWithTrace(ctx, trace), client, updates
+}
+
+// streamingTransport returns a tracing transport whose upstream answers
+// every request with body as an event stream, one byte per read.
+func streamingTransport(client *opik.Client, body string) *TracingTransport {
+	upstream := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"text/event-stream; charset=utf-8"}},
+			Body:       io.NopCloser(iotest.OneByteReader(strings.NewReader(body))),
+			Request:    req,
+		}, nil
+	})
+	return NewTracingTransport(upstream, client)
+}
+
+func sse(events ...string) string {
+	var b strings.Builder
+	for _, e := range events {
+		b.WriteString("data: " + e + "\n\n")
+	}
+	return b.String()
+}
+
+func TestTracingTransportChatStream(t *testing.T) {
+	ctx, client, updates := streamTrace(t)
+	body := sse(
+		`{"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-4o","choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}`,
+		`{"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-4o","choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		`{"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-4o","choices":[{"index":0,"delta":{"content":", world"}}]}`,
+		`{"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-4o","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{\"q\":"}}]}}]}`,
+		`{"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-4o","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"go\"}"}}]},"finish_reason":"tool_calls"}]}`,
+		`{"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-4o","choices":[],"usage":{"prompt_tokens":9,"completion_tokens":4,"total_tokens":13}}`,
+		`[DONE]`,
+	)
+	transport := streamingTransport(client, body)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/chat/completions",
+		strings.NewReader(`{"model":"gpt-4o","stream":true,"stream_options":{"include_usage":true}}`))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip error: %v", err)
+	}
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading stream: %v", err)
+	}
+	resp.Body.Close()
+	if string(got) != body {
+		t.Error("stream was not passed through unchanged")
+	}
+
+	update := updates.last(t)
+	output, _ := update["output"].(map[string]any)
+	choices, _ := output["choices"].([]any)
+	if len(choices) != 1 {
+		t.Fatalf("output = %v, want one choice", output)
+	}
+	choice := choices[0].(map[string]any)
+	msg := choice["message"].(map[string]any)
+	if msg["content"] != "Hello, world" || choice["finish_reason"] != "tool_calls" {
+		t.Errorf("choice = %v, want content %q and finish reason tool_calls", choice, "Hello, world")
+	}
+	call := msg["tool_calls"].([]any)[0].(map[string]any)
+	if fn := call["function"].(map[string]any); call["id"] != "call_1" || fn["name"] != "lookup" || fn["arguments"] != `{"q":"go"}` {
+		t.Errorf("tool call = %v", call)
+	}
+	if usage, _ := update["usage"].(map[string]any); usage["prompt_tokens"] != float64(9) || usage["completion_tokens"] != float64(4) {
+		t.Errorf("usage = %v, want 9 prompt and 4 completion tokens", usage)
+	}
+	metadata, _ := update["metadata"].(map[string]any)
+	if metadata["streaming"] != true || metadata["chunk_count"] != float64(6) || metadata[opik.MetadataStreamStatus] != nil {
+		t.Errorf("metadata = %v, want a finished stream of 6 chunks", metadata)
+	}
+}
+
+func TestTracingTransportResponsesStream(t *testing.T) {
+	ctx, client, updates := streamTrace(t)
+	transport := streamingTransport(client, "event: response.output_text.delta\n"+sse(
+		`{"type":"response.output_text.delta","delta":"Hi"}`,
+	)+"event: response.completed\n"+sse(
+		`{"type":"response.completed","response":{"id":"resp_1","object":"response","status":"completed","output":[{"type":"message","content":[{"type":"output_text","text":"Hi"}]}],"usage":{"input_tokens":5,"output_tokens":1,"total_tokens":6}}}`,
+	))
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/responses",
+		strings.NewReader(`{"model":"gpt-4o","input":"Hi","stream":true}`))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip error: %v", err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	update := updates.last(t)
+	if output, _ := update["output"].(map[string]any); output["id"] != "resp_1" || output["status"] != "completed" {
+		t.Errorf("output = %v, want the completed response", output)
+	}
+	if usage, _ := update["usage"].(map[string]any); usage["prompt_tokens"] != float64(5) || usage["completion_tokens"] != float64(1) {
+		t.Errorf("usage = %v, want 5 prompt and 1 completion tokens", usage)
+	}
+}
+
+func TestTracingTransportStreamClosedEarly(t *testing.T) {
+	ctx, client, updates := streamTrace(t)
+	transport := streamingTransport(client, sse(
+		`{"object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Once upon"}}]}`,
+		`{"object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":" a time"}}]}`,
+		`[DONE]`,
+	))
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/chat/completions",
+		strings.NewReader(`{"model":"gpt-4o","stream":true}`))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip error: %v", err)
+	}
+	first := sse(`{"object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Once upon"}}]}`)
+	if _, err := io.ReadFull(resp.Body, make([]byte, len(first))); err != nil {
+		t.Fatalf("reading stream: %v", err)
+	}
+	resp.Body.Close()
+	resp.Body.Close()
+
+	update := updates.last(t)
+	metadata, _ := update["metadata"].(map[string]any)
+	if metadata[opik.MetadataStreamStatus] != opik.StreamStatusCancelled {
+		t.Errorf("metadata = %v, want a cancelled stream", metadata)
+	}
+	output, _ := update["output"].(map[string]any)
+	msg := output["choices"].([]any)[0].(map[string]any)["message"].(map[string]any)
+	if msg["content"] != "Once upon" {
+		t.Errorf("partial content = %q, want %q", msg["content"], "Once upon")
+	}
+}
+
+func TestSummarizeEmbeddings(t *testing.T) {
+	resp := map[string]any{
+		"object": "list",
+		"data": []any{
+			map[string]any{"index": float64(0), "embedding": []any{0.1, 0.2, 0.3}},
+			map[string]any{"index": float64(1), "embedding": "AAAA"},
+		},
+		"usage": map[string]any{"prompt_tokens": float64(4), "total_tokens": float64(4)},
+	}
+	summarizeEmbeddings(resp)
+
+	data := resp["data"].([]any)
+	first, second := data[0].(map[string]any), data[1].(map[string]any)
+	if first["dimensions"] != 3 || first["embedding"] != nil {
+		t.Errorf("data[0] = %v, want dimensions 3 and no vector", first)
+	}
+	if _, ok := second["embedding"]; ok {
+		t.Errorf("data[1] = %v, want no base64 vector", second)
+	}
+	if resp["usage"] == nil {
+		t.Error("usage should be kept")
+	}
+}
@@ -2,12 +2,15 @@ package openai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/evaluation/llm"
 )
 
 // TracingTransport wraps an http.RoundTripper to automatically trace OpenAI API calls.
@@ -15,6 +18,8 @@ type TracingTransport struct {
 	inner       http.RoundTripper
 	opikClient  *opik.Client
 	spanOptions []opik.SpanOption
+	usage       opik.UsageExtractor
+	maxInput    int
 }
 
 // NewTracingTransport creates a new tracing transport.
@@ -29,6 +34,25 @@ func NewTracingTransport(inner http.RoundTripper, opikClient *opik.Client, opts
 	}
 }
 
+// WithUsageExtractor reads token usage from each response with e, for
+// gateways that report usage in headers or trailers rather than the body.
+// Usage in the body takes precedence; see opik.MergeUsage.
+func (t *TracingTransport) WithUsageExtractor(e opik.UsageExtractor) *TracingTransport {
+	t.usage = e
+	return t
+}
+
+// WithMaxInputBytes limits the messages recorded as a span's input to
+// about maxBytes of JSON. Longer conversations are recorded without their
+// oldest messages, keeping the system messages and the latest user turn
+// (see llm.TruncateMessagesFunc), and the span's metadata records the
+// number left out under llm.MetadataOmittedMessages. The request sent to
+// the API is not changed.
+func (t *TracingTransport) WithMaxInputBytes(maxBytes int) *TracingTransport {
+	t.maxInput = maxBytes
+	return t
+}
+
 // RoundTrip implements http.RoundTripper with tracing.
 func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// Only trace OpenAI API calls
@@ -54,11 +78,16 @@ func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	operation := getOperationName(req.URL.Path)
 
 	// Start span
-	opts := append([]opik.SpanOption{
+	input, omitted := llm.TruncateInputMessages(reqData, t.maxInput)
+	opts := []opik.SpanOption{
 		opik.WithSpanType(opik.SpanTypeLLM),
 		opik.WithSpanProvider("openai"),
-		opik.WithSpanInput(reqData),
-	}, t.spanOptions...)
+		opik.WithSpanInput(input),
+	}
+	if omitted > 0 {
+		opts = append(opts, opik.WithSpanMetadata(map[string]any{llm.MetadataOmittedMessages: omitted}))
+	}
+	opts = append(opts, t.spanOptions...)
 
 	if model != "" {
 		opts = append(opts, opik.WithSpanModel(model))
@@ -81,51 +110,151 @@ func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 
 	// End span with results
 	if span != nil && err == nil {
-		endOpts := []opik.SpanOption{}
+		metadata := map[string]any{
+			"duration_ms": duration.Milliseconds(),
+		}
 
+		// A streamed response is recorded as it is read, so the caller
+		// receives each event as soon as it arrives.
+		if respErr == nil && isEventStream(resp) {
+			resp.Body = newStreamRecorder(ctx, t, span, resp, startTime)
+			return resp, nil
+		}
+
+		var respData map[string]any
 		if resp != nil && resp.Body != nil {
 			// Read response body for output
 			body, _ := io.ReadAll(resp.Body)
 			resp.Body = io.NopCloser(bytes.NewReader(body))
+			if json.Unmarshal(body, &respData) == nil && operation == "openai.embeddings" {
+				summarizeEmbeddings(respData)
+			}
+		}
+		var endOpts []opik.SpanOption
+		if respErr != nil {
+			metadata["error"] = respErr.Error()
+			endOpts = append(endOpts, opik.WithSpanError(respErr))
+		}
+		t.endSpan(ctx, span, resp, respData, metadata, endOpts...)
+	}
 
-			var respData map[string]any
-			if json.Unmarshal(body, &respData) == nil {
-				endOpts = append(endOpts, opik.WithSpanOutput(respData))
+	return resp, respErr
+}
 
-				// Extract usage info
-				if usage, ok := respData["usage"].(map[string]any); ok {
-					metadata := map[string]any{
-						"duration_ms": duration.Milliseconds(),
-					}
-					if pt, ok := usage["prompt_tokens"].(float64); ok {
-						metadata["prompt_tokens"] = int(pt)
-					}
-					if ct, ok := usage["completion_tokens"].(float64); ok {
-						metadata["completion_tokens"] = int(ct)
-					}
-					if tt, ok := usage["total_tokens"].(float64); ok {
-						metadata["total_tokens"] = int(tt)
+// endSpan ends span with the decoded response body respData, which is nil
+// if the body was not JSON. Token usage is added to metadata, which is
+// recorded if the response reported usage or the call failed. opts are
+// applied after the options endSpan sets.
+func (t *TracingTransport) endSpan(ctx context.Context, span *opik.Span, resp *http.Response, respData map[string]any, metadata map[string]any, opts ...opik.SpanOption) {
+	endOpts := []opik.SpanOption{}
+	var usage map[string]int
+
+	if respData != nil {
+		if summary, ok := extractReasoning(respData); ok {
+			span.SetReasoning(summary)
+		}
+		endOpts = append(endOpts, opik.WithSpanOutput(respData))
+
+		// Extract usage info
+		if raw, ok := respData["usage"].(map[string]any); ok {
+			if pt, ok := raw["prompt_tokens"].(float64); ok {
+				metadata["prompt_tokens"] = int(pt)
+			}
+			if ct, ok := raw["completion_tokens"].(float64); ok {
+				metadata["completion_tokens"] = int(ct)
+			}
+			if tt, ok := raw["total_tokens"].(float64); ok {
+				metadata["total_tokens"] = int(tt)
+			}
+			usage = opik.NormalizeUsage(raw)
+		}
+	}
+
+	// Fill in usage the body omits, such as usage a gateway
+	// reports only in headers.
+	if t.usage != nil && resp != nil {
+		usage = opik.MergeUsage(usage, t.usage.ExtractUsage(resp))
+	}
+	if len(usage) > 0 {
+		for _, key := range []string{opik.UsageCacheReadTokens, opik.UsageReasoningTokens} {
+			if n, ok := usage[key]; ok {
+				metadata[key] = n
+			}
+		}
+		span.SetUsage(usage)
+	}
+	if len(usage) > 0 || metadata["error"] != nil || metadata["streaming"] != nil {
+		endOpts = append(endOpts, opik.WithSpanMetadata(metadata))
+	}
+
+	_ = span.End(ctx, append(endOpts, opts...)...)
+}
+
+// summarizeEmbeddings replaces the vectors of an embeddings response with
+// their dimensions, so spans do not store thousands of floats per input.
+func summarizeEmbeddings(respData map[string]any) {
+	data, _ := respData["data"].([]any)
+	for _, d := range data {
+		item, ok := d.(map[string]any)
+		if !ok {
+			continue
+		}
+		if vector, ok := item["embedding"].([]any); ok {
+			item["dimensions"] = len(vector)
+		}
+		delete(item, "embedding")
+	}
+}
+
+// extractReasoning removes reasoning summaries from a response and returns
+// them, so they are recorded only through Span.SetReasoning, which redacts
+// them unless reasoning capture is enabled. It handles reasoning output items
+// from the Responses API and the reasoning_content field that some
+// OpenAI-compatible providers add to chat completion messages.
+func extractReasoning(respData map[string]any) (string, bool) {
+	var parts []string
+
+	if output, ok := respData["output"].([]any); ok {
+		for _, item := range output {
+			m, ok := item.(map[string]any)
+			if !ok || m["type"] != "reasoning" {
+				continue
+			}
+			if summary, ok := m["summary"].([]any); ok {
+				for _, s := range summary {
+					if sm, ok := s.(map[string]any); ok {
+						if text, ok := sm["text"].(string); ok && text != "" {
+							parts = append(parts, text)
+						}
 					}
-					endOpts = append(endOpts, opik.WithSpanMetadata(metadata))
 				}
 			}
+			delete(m, "summary")
 		}
+	}
 
-		if respErr != nil {
-			endOpts = append(endOpts, opik.WithSpanMetadata(map[string]any{
-				"error": respErr.Error(),
-			}))
+	if choices, ok := respData["choices"].([]any); ok {
+		for _, c := range choices {
+			choice, _ := c.(map[string]any)
+			msg, _ := choice["message"].(map[string]any)
+			if text, ok := msg["reasoning_content"].(string); ok {
+				if text != "" {
+					parts = append(parts, text)
+				}
+				delete(msg, "reasoning_content")
+			}
 		}
-
-		_ = span.End(ctx, endOpts...)
 	}
 
-	return resp, respErr
+	if len(parts) == 0 {
+		return "", false
+	}
+	return strings.Join(parts, "\n\n"), true
 }
 
 func isOpenAIRequest(req *http.Request) bool {
-	host := req.URL.Host
-	return host == "api.openai.com" || host == "openai.azure.com"
+	host := req.URL.Hostname()
+	return host == "api.openai.com" || host == "openai.azure.com" || strings.HasSuffix(host, ".openai.azure.com")
 }
 
 func getOperationName(path string) string {
@@ -134,6 +263,8 @@ func getOperationName(path string) string {
 		return "openai.chat.completion"
 	case contains(path, "/completions"):
 		return "openai.completion"
+	case contains(path, "/responses"):
+		return "openai.responses"
 	case contains(path, "/embeddings"):
 		return "openai.embeddings"
 	case contains(path, "/images"):
@@ -1,9 +1,17 @@
 package openai
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
+
+	opik "github.com/plexusone/opik-go"
 )
 
 func TestIsOpenAIRequest(t *testing.T) {
@@ -14,6 +22,7 @@ func TestIsOpenAIRequest(t *testing.T) {
 	}{
 		{"OpenAI", "api.openai.com", true},
 		{"Azure OpenAI", "openai.azure.com", true},
+		{"Azure OpenAI resource", "my-resource.openai.azure.com", true},
 		{"Other API", "api.anthropic.com", false},
 		{"Local", "localhost:8080", false},
 		{"Empty", "", false},
@@ -43,6 +52,7 @@ func TestGetOperationName(t *testing.T) {
 		{"/v1/images/generations", "openai.images"},
 		{"/v1/audio/transcriptions", "openai.audio"},
 		{"/v1/moderations", "openai.moderations"},
+		{"/v1/responses", "openai.responses"},
 		{"/v1/models", "openai.api"},
 		{"/unknown", "openai.api"},
 	}
@@ -198,3 +208,171 @@ func TestTracingTransportNonOpenAIRequest(t *testing.T) {
 	// but we verify the detection logic
 	_ = tr
 }
+
+func TestExtractReasoning(t *testing.T) {
+	responses := map[string]any{
+		"output": []any{
+			map[string]any{
+				"type":    "reasoning",
+				"summary": []any{map[string]any{"type": "summary_text", "text": "Compare the options."}},
+			},
+			map[string]any{"type": "message", "content": []any{}},
+		},
+	}
+	summary, ok := extractReasoning(responses)
+	if !ok || summary != "Compare the options." {
+		t.Errorf("extractReasoning = %q, %v", summary, ok)
+	}
+	item := responses["output"].([]any)[0].(map[string]any)
+	if _, ok := item["summary"]; ok {
+		t.Error("summary should be removed from the output")
+	}
+
+	chat := map[string]any{
+		"choices": []any{map[string]any{
+			"message": map[string]any{"content": "4", "reasoning_content": "2+2=4"},
+		}},
+	}
+	if summary, ok := extractReasoning(chat); !ok || summary != "2+2=4" {
+		t.Errorf("extractReasoning = %q, %v", summary, ok)
+	}
+
+	if _, ok := extractReasoning(map[string]any{"choices": []any{}}); ok {
+		t.Error("expected no reasoning")
+	}
+}
+
+// roundTripFunc adapts a function to an http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestTracingTransportUsageExtractor(t *testing.T) {
+	var mu sync.Mutex
+	var usage map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && r.URL.Path == "/v1/private/spans/batch" {
+			var req struct {
+				Update struct {
+					Usage map[string]any `json:"usage"`
+				} `json:"update"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			usage = req.Update.Usage
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	opikClient, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	// The gateway reports usage only in headers.
+	gateway := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Content-Type":              {"application/json"},
+				"X-Usage-Prompt-Tokens":     {"12"},
+				"X-Usage-Completion-Tokens": {"5"},
+			},
+			Body:    io.NopCloser(strings.NewReader(`{"choices": [{"message": {"content": "hi"}}]}`)),
+			Request: req,
+		}, nil
+	})
+	transport := NewTracingTransport(gateway, opikClient).WithUsageExtractor(opik.HeaderUsageExtractor(map[string]string{
+		"X-Usage-Prompt-Tokens":     opik.UsagePromptTokens,
+		"X-Usage-Completion-Tokens": opik.UsageCompletionTokens,
+	}))
+
+	ctx := context.Background()
+	trace, err := opikClient.Trace(ctx, "chat")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	req, _ := http.NewRequestWithContext(opik.ContextWithTrace(ctx, trace), http.MethodPost,
+		"https://api.openai.com/v1/chat/completions", strings.NewReader(`{"model": "gpt-4o"}`))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip error: %v", err)
+	}
+	resp.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if usage["prompt_tokens"] != float64(12) || usage["completion_tokens"] != float64(5) || usage["total_tokens"] != float64(17) {
+		t.Errorf("span usage = %v, want usage from headers", usage)
+	}
+}
+
+func TestTracingTransportMaxInputBytes(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, r.Method+" "+r.URL.Path+" "+string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	opikClient, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	var sent string
+	api := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		sent = string(body)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"choices": [{"message": {"content": "hi"}}]}`)),
+			Request:    req,
+		}, nil
+	})
+	transport := NewTracingTransport(api, opikClient).WithMaxInputBytes(150)
+
+	ctx := context.Background()
+	trace, err := opikClient.Trace(ctx, "chat")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	body := `{"model": "gpt-4o", "messages": [` +
+		`{"role": "system", "content": "be brief"},` +
+		`{"role": "user", "content": "` + strings.Repeat("old ", 30) + `"},` +
+		`{"role": "assistant", "content": "` + strings.Repeat("reply ", 30) + `"},` +
+		`{"role": "user", "content": "latest question"}]}`
+	req, _ := http.NewRequestWithContext(opik.ContextWithTrace(ctx, trace), http.MethodPost,
+		"https://api.openai.com/v1/chat/completions", strings.NewReader(body))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip error: %v", err)
+	}
+	resp.Body.Close()
+
+	if sent != body {
+		t.Errorf("request body changed: %s", sent)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	var span string
+	for _, b := range bodies {
+		if strings.HasPrefix(b, "POST /v1/private/spans") {
+			span = b
+		}
+	}
+	for _, want := range []string{`"omitted_messages":2`, `latest question`, `be brief`} {
+		if !strings.Contains(span, want) {
+			t.Errorf("span missing %s: %s", want, span)
+		}
+	}
+	if strings.Contains(span, "old old") {
+		t.Errorf("span kept the omitted messages: %s", span)
+	}
+}
@@ -0,0 +1,339 @@
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// genAISystemKey is the provider attribute used before gen_ai.provider.name.
+const genAISystemKey = attribute.Key("gen_ai.system")
+
+// Exporter is an sdktrace.SpanExporter that writes OpenTelemetry spans to
+// Opik. Register it with a TracerProvider, for example with
+// sdktrace.WithBatcher, to see LLM calls instrumented with OpenTelemetry in
+// Opik.
+//
+// Each OpenTelemetry trace becomes an Opik trace, created from its root
+// span, and every span becomes an Opik span under its parent. Opik
+// IDs are derived from the OpenTelemetry IDs, so spans of one trace
+// exported by several services, or in several batches, land in the same
+// Opik trace. Spans whose type cannot be told from opik.span.type or the
+// gen_ai.* attributes are general spans, and attributes with no Opik field
+// are kept as metadata.
+type Exporter struct {
+	client      *opik.Client
+	projectName string
+}
+
+// NewExporter creates an exporter that writes to client's project, or to
+// the project set with WithProjectName.
+func NewExporter(client *opik.Client, opts ...Option) *Exporter {
+	o := newOptions(opts)
+	return &Exporter{client: client, projectName: o.projectName}
+}
+
+// ExportSpans writes spans to Opik: their traces first, then the spans,
+// then any feedback scores carried in opik.feedback_score.* attributes.
+func (e *Exporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	projectName := e.projectName
+	if projectName == "" {
+		projectName = e.client.ProjectName()
+	}
+
+	var traces []api.TraceWrite
+	var writes []api.SpanWrite
+	var scores []opik.FeedbackBatchItem
+	for _, span := range spans {
+		s := newOpikSpan(span)
+		s.redact(e.client)
+		s.withhold(e.client.CaptureMode())
+		traceID := TraceUUID(span.SpanContext().TraceID())
+		spanID := SpanUUID(span.SpanContext().TraceID(), span.SpanContext().SpanID())
+
+		if !span.Parent().IsValid() {
+			traces = append(traces, api.TraceWrite{
+				ID:          api.NewOptUUID(traceID),
+				ProjectName: api.NewOptString(projectName),
+				Name:        api.NewOptString(span.Name()),
+				StartTime:   span.StartTime(),
+				EndTime:     api.NewOptDateTime(span.EndTime()),
+				Input:       s.input,
+				Output:      s.output,
+				Metadata:    s.metadata,
+				Tags:        s.tags,
+				ErrorInfo:   s.errorInfo,
+			})
+			for _, score := range s.scores {
+				score.EntityType, score.EntityID = "trace", traceID.String()
+				scores = append(scores, score)
+			}
+		} else {
+			for _, score := range s.scores {
+				score.EntityType, score.EntityID = "span", spanID.String()
+				scores = append(scores, score)
+			}
+		}
+
+		write := api.SpanWrite{
+			ID:          api.NewOptUUID(spanID),
+			ProjectName: api.NewOptString(projectName),
+			TraceID:     api.NewOptUUID(traceID),
+			Name:        api.NewOptString(span.Name()),
+			Type:        api.NewOptSpanWriteType(api.SpanWriteType(s.spanType)),
+			StartTime:   span.StartTime(),
+			EndTime:     api.NewOptDateTime(span.EndTime()),
+			Input:       s.input,
+			Output:      s.output,
+			Metadata:    s.metadata,
+			Tags:        s.tags,
+			ErrorInfo:   s.errorInfo,
+		}
+		if span.Parent().IsValid() {
+			write.ParentSpanID = api.NewOptUUID(SpanUUID(span.Parent().TraceID(), span.Parent().SpanID()))
+		}
+		if s.model != "" {
+			write.Model = api.NewOptString(s.model)
+		}
+		if s.provider != "" {
+			write.Provider = api.NewOptString(s.provider)
+		}
+		if len(s.usage) > 0 {
+			write.Usage = api.NewOptSpanWriteUsage(s.usage)
+		}
+		writes = append(writes, write)
+	}
+
+	apiClient := e.client.API()
+	if len(traces) > 0 {
+		if err := apiClient.CreateTraces(ctx, api.NewOptTraceBatchWrite(api.TraceBatchWrite{Traces: traces})); err != nil {
+			return fmt.Errorf("export traces to opik: %w", err)
+		}
+	}
+	if err := apiClient.CreateSpans(ctx, api.NewOptSpanBatchWrite(api.SpanBatchWrite{Spans: writes})); err != nil {
+		return fmt.Errorf("export spans to opik: %w", err)
+	}
+	if len(scores) > 0 {
+		result, err := e.client.AddFeedbackScores(ctx, scores)
+		if err != nil {
+			return err
+		}
+		if err := result.Err(); err != nil {
+			return fmt.Errorf("export feedback scores to opik: %w", err)
+		}
+	}
+	return nil
+}
+
+// Shutdown implements sdktrace.SpanExporter. The exporter holds no
+// resources of its own, so there is nothing to release.
+func (e *Exporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// TraceUUID returns the Opik trace ID for an OpenTelemetry trace ID: its
+// bytes, marked as a UUID v7. An OpenTelemetry trace converted from Opik
+// with Spans maps back to its original ID.
+func TraceUUID(traceID trace.TraceID) uuid.UUID {
+	return asUUIDv7(uuid.UUID(traceID))
+}
+
+// SpanUUID returns the Opik span ID for an OpenTelemetry span: the first
+// eight bytes of the trace ID followed by the span ID, marked as a UUID v7.
+func SpanUUID(traceID trace.TraceID, spanID trace.SpanID) uuid.UUID {
+	var u uuid.UUID
+	copy(u[:8], traceID[:8])
+	copy(u[8:], spanID[:])
+	return asUUIDv7(u)
+}
+
+// asUUIDv7 sets the version and variant bits the Opik API requires.
+func asUUIDv7(u uuid.UUID) uuid.UUID {
+	u[6] = u[6]&0x0f | 0x70
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+// opikSpan holds the Opik fields read from an OpenTelemetry span.
+type opikSpan struct {
+	spanType  string
+	model     string
+	provider  string
+	usage     api.SpanWriteUsage
+	input     api.JsonListStringWrite
+	output    api.JsonListStringWrite
+	metadata  api.JsonListStringWrite
+	tags      []string
+	scores    []opik.FeedbackBatchItem
+	errorInfo api.OptErrorInfoWrite
+}
+
+func newOpikSpan(span sdktrace.ReadOnlySpan) *opikSpan {
+	s := &opikSpan{spanType: opik.SpanTypeGeneral}
+	nullJSON := api.JsonListStringWrite("null")
+	s.input, s.output, s.metadata = nullJSON, nullJSON, nullJSON
+
+	var requestModel, responseModel, system, operation string
+	var input, output string
+	metadata := map[string]any{}
+	for _, kv := range span.Attributes() {
+		switch kv.Key {
+		case AttrSpanType:
+			s.spanType = kv.Value.AsString()
+		case semconv.GenAIOperationNameKey:
+			operation = kv.Value.AsString()
+		case semconv.GenAIRequestModelKey:
+			requestModel = kv.Value.AsString()
+		case semconv.GenAIResponseModelKey:
+			responseModel = kv.Value.AsString()
+		case semconv.GenAIProviderNameKey:
+			s.provider = kv.Value.AsString()
+		case genAISystemKey:
+			system = kv.Value.AsString()
+		case semconv.GenAIUsageInputTokensKey:
+			s.addUsage("prompt_tokens", kv.Value.AsInt64())
+		case semconv.GenAIUsageOutputTokensKey:
+			s.addUsage("completion_tokens", kv.Value.AsInt64())
+		case AttrInput:
+			input = kv.Value.AsString()
+		case AttrOutput:
+			output = kv.Value.AsString()
+		case semconv.GenAIInputMessagesKey:
+			if input == "" {
+				input = kv.Value.AsString()
+			}
+		case semconv.GenAIOutputMessagesKey:
+			if output == "" {
+				output = kv.Value.AsString()
+			}
+		case AttrMetadata:
+			_ = json.Unmarshal([]byte(kv.Value.AsString()), &metadata)
+		case AttrTags:
+			s.tags = kv.Value.AsStringSlice()
+		default:
+			if name, ok := hasPrefix(kv.Key, AttrUsagePrefix); ok {
+				s.addUsage(name, kv.Value.AsInt64())
+			} else if name, ok := hasPrefix(kv.Key, AttrFeedbackScorePrefix); ok {
+				s.scores = append(s.scores, opik.FeedbackBatchItem{Name: name, Value: kv.Value.AsFloat64()})
+			} else {
+				metadata[string(kv.Key)] = kv.Value.AsInterface()
+			}
+		}
+	}
+
+	s.model = responseModel
+	if s.model == "" {
+		s.model = requestModel
+	}
+	if s.provider == "" {
+		s.provider = system
+	}
+	if s.spanType == opik.SpanTypeGeneral {
+		switch {
+		case operation == "execute_tool":
+			s.spanType = opik.SpanTypeTool
+		case operation != "" || s.model != "":
+			s.spanType = opik.SpanTypeLLM
+		}
+	}
+	if _, ok := s.usage["total_tokens"]; !ok && (s.usage["prompt_tokens"] > 0 || s.usage["completion_tokens"] > 0) {
+		s.usage["total_tokens"] = s.usage["prompt_tokens"] + s.usage["completion_tokens"]
+	}
+
+	if input != "" {
+		s.input = jsonValue(input)
+	}
+	if output != "" {
+		s.output = jsonValue(output)
+	}
+	if res := span.Resource(); res != nil {
+		if name, ok := res.Set().Value(semconv.ServiceNameKey); ok {
+			metadata[string(semconv.ServiceNameKey)] = name.AsString()
+		}
+	}
+	if len(metadata) > 0 {
+		data, _ := json.Marshal(metadata)
+		s.metadata = api.JsonListStringWrite(data)
+	}
+
+	if span.Status().Code == codes.Error {
+		info := api.ErrorInfoWrite{ExceptionType: "error"}
+		if desc := span.Status().Description; desc != "" {
+			info.Message = api.NewOptString(desc)
+		}
+		for _, event := range span.Events() {
+			if event.Name != semconv.ExceptionEventName {
+				continue
+			}
+			for _, kv := range event.Attributes {
+				switch kv.Key {
+				case semconv.ExceptionTypeKey:
+					info.ExceptionType = kv.Value.AsString()
+				case semconv.ExceptionMessageKey:
+					info.Message = api.NewOptString(kv.Value.AsString())
+				case semconv.ExceptionStacktraceKey:
+					info.Traceback = kv.Value.AsString()
+				}
+			}
+		}
+		s.errorInfo = api.NewOptErrorInfoWrite(info)
+	}
+	return s
+}
+
+func (s *opikSpan) addUsage(key string, n int64) {
+	if s.usage == nil {
+		s.usage = api.SpanWriteUsage{}
+	}
+	s.usage[key] = int32(n) //nolint:gosec // G115: token counts fit in int32
+}
+
+// redact applies the client's redaction pipeline to the input, output,
+// metadata, and error info, as the client does for its own traces and spans.
+func (s *opikSpan) redact(client *opik.Client) {
+	s.input = client.RedactJSON(s.input)
+	s.output = client.RedactJSON(s.output)
+	s.metadata = client.RedactJSON(s.metadata)
+	if info, ok := s.errorInfo.Get(); ok {
+		if message, ok := info.Message.Get(); ok {
+			info.Message = api.NewOptString(client.RedactString(message))
+		}
+		info.Traceback = client.RedactString(info.Traceback)
+		s.errorInfo = api.NewOptErrorInfoWrite(info)
+	}
+}
+
+// withhold hashes the input and output that mode does not send, as the
+// client does for its own traces and spans.
+func (s *opikSpan) withhold(mode opik.CaptureMode) {
+	if string(s.input) != "null" {
+		s.input = mode.Apply(s.input, false)
+	}
+	if string(s.output) != "null" {
+		s.output = mode.Apply(s.output, true)
+	}
+}
+
+// jsonValue returns v as raw JSON if it is valid JSON, and as a JSON string
+// otherwise, since GenAI message attributes are not always JSON.
+func jsonValue(v string) api.JsonListStringWrite {
+	if json.Valid([]byte(v)) && strings.TrimSpace(v) != "" {
+		return api.JsonListStringWrite(v)
+	}
+	data, _ := json.Marshal(v)
+	return api.JsonListStringWrite(data)
+}
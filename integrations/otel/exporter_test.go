@@ -0,0 +1,190 @@
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+type written struct {
+	ID           string          `json:"id"`
+	ProjectName  string          `json:"project_name"`
+	TraceID      string          `json:"trace_id"`
+	ParentSpanID string          `json:"parent_span_id"`
+	Name         string          `json:"name"`
+	Type         string          `json:"type"`
+	Model        string          `json:"model"`
+	Provider     string          `json:"provider"`
+	Usage        map[string]int  `json:"usage"`
+	Input        json.RawMessage `json:"input"`
+	Metadata     map[string]any  `json:"metadata"`
+	ErrorInfo    *struct {
+		ExceptionType string `json:"exception_type"`
+		Message       string `json:"message"`
+	} `json:"error_info"`
+}
+
+func TestExporter(t *testing.T) {
+	var mu sync.Mutex
+	var traces, spans []written
+	var scores []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Traces []written        `json:"traces"`
+			Spans  []written        `json:"spans"`
+			Scores []map[string]any `json:"scores"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		traces = append(traces, body.Traces...)
+		spans = append(spans, body.Spans...)
+		scores = append(scores, body.Scores...)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	client, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(NewExporter(client, WithProjectName("otel-services"))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("checkout"))),
+	)
+	tracer := provider.Tracer("test")
+	ctx := context.Background()
+
+	ctx, root := tracer.Start(ctx, "handle request", trace.WithAttributes(attribute.Float64("opik.feedback_score.csat", 1)))
+	_, llm := tracer.Start(ctx, "chat gpt-4o", trace.WithAttributes(
+		semconv.GenAIOperationNameChat,
+		semconv.GenAIRequestModel("gpt-4o"),
+		semconv.GenAIResponseModel("gpt-4o-2024-08-06"),
+		attribute.String("gen_ai.system", "openai"),
+		semconv.GenAIUsageInputTokens(10),
+		semconv.GenAIUsageOutputTokens(5),
+		semconv.GenAIInputMessagesKey.String(`[{"role":"user","content":"hi"}]`),
+		attribute.String("http.route", "/chat"),
+	))
+	llm.RecordError(errors.New("rate limited"))
+	llm.SetStatus(codes.Error, "upstream failed")
+	llm.End()
+	_, tool := tracer.Start(ctx, "lookup", trace.WithAttributes(semconv.GenAIOperationNameExecuteTool))
+	tool.End()
+	root.End()
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown error: %v", err)
+	}
+
+	if len(traces) != 1 || len(spans) != 3 {
+		t.Fatalf("got %d traces and %d spans, want 1 and 3", len(traces), len(spans))
+	}
+	tr := traces[0]
+	if tr.Name != "handle request" || tr.ProjectName != "otel-services" || tr.Metadata["service.name"] != "checkout" {
+		t.Errorf("trace = %+v", tr)
+	}
+
+	byName := map[string]written{}
+	for _, s := range spans {
+		byName[s.Name] = s
+		if s.TraceID != tr.ID {
+			t.Errorf("span %s trace ID = %s, want %s", s.Name, s.TraceID, tr.ID)
+		}
+	}
+	rootSpan, chat, lookup := byName["handle request"], byName["chat gpt-4o"], byName["lookup"]
+	if rootSpan.ParentSpanID != "" || chat.ParentSpanID != rootSpan.ID || lookup.ParentSpanID != rootSpan.ID {
+		t.Errorf("parents: root %q, chat %q, lookup %q; root ID %s", rootSpan.ParentSpanID, chat.ParentSpanID, lookup.ParentSpanID, rootSpan.ID)
+	}
+	if chat.Type != "llm" || chat.Model != "gpt-4o-2024-08-06" || chat.Provider != "openai" {
+		t.Errorf("chat span = %+v", chat)
+	}
+	if chat.Usage["prompt_tokens"] != 10 || chat.Usage["completion_tokens"] != 5 || chat.Usage["total_tokens"] != 15 {
+		t.Errorf("usage = %v", chat.Usage)
+	}
+	if string(chat.Input) != `[{"role":"user","content":"hi"}]` || chat.Metadata["http.route"] != "/chat" {
+		t.Errorf("input = %s, metadata = %v", chat.Input, chat.Metadata)
+	}
+	if chat.ErrorInfo == nil || chat.ErrorInfo.ExceptionType != "*errors.errorString" || chat.ErrorInfo.Message != "rate limited" {
+		t.Errorf("error info = %+v", chat.ErrorInfo)
+	}
+	if lookup.Type != "tool" || rootSpan.Type != "general" {
+		t.Errorf("types: lookup %q, root %q", lookup.Type, rootSpan.Type)
+	}
+	if len(scores) != 1 || scores[0]["name"] != "csat" || scores[0]["id"] != tr.ID {
+		t.Errorf("scores = %v, want csat on the trace", scores)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tree := testTree()
+	spans := Spans(tree)
+	s := newOpikSpan(spans[2]) // the LLM span
+	if s.spanType != "llm" || s.model != "gpt-4o" || s.provider != "openai" {
+		t.Errorf("span = %+v", s)
+	}
+	if s.usage["prompt_tokens"] != 12 || s.usage["total_tokens"] != 42 || string(s.input) != `{"prompt":"hi"}` {
+		t.Errorf("usage = %v, input = %s", s.usage, s.input)
+	}
+	if len(s.scores) != 1 || s.scores[0].Name != "relevance" {
+		t.Errorf("scores = %v", s.scores)
+	}
+	if got := TraceUUID(spans[0].SpanContext().TraceID()).String(); got != tree.Trace.ID {
+		t.Errorf("trace ID = %s, want %s", got, tree.Trace.ID)
+	}
+}
+
+func TestWithhold(t *testing.T) {
+	spans := Spans(testTree())
+	s := newOpikSpan(spans[2])
+	s.withhold(opik.CaptureOutputOnly)
+	if !strings.Contains(string(s.input), `"withheld":"output_only"`) {
+		t.Errorf("input = %s, want it withheld", s.input)
+	}
+	if strings.Contains(string(s.output), "withheld") {
+		t.Errorf("output = %s, want it sent", s.output)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	client, err := opik.NewClient(opik.WithURL("http://localhost"), opik.WithAPIKey("test-key"),
+		opik.WithRedactor(opik.RedactEmails))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	s := newOpikSpan(Spans(testTree())[2])
+	s.input = jsonValue(`[{"role":"user","content":"mail ana@example.com"}]`)
+	s.metadata = jsonValue(`{"user":"ana@example.com"}`)
+	s.errorInfo = api.NewOptErrorInfoWrite(api.ErrorInfoWrite{
+		ExceptionType: "ValueError",
+		Message:       api.NewOptString("no user ana@example.com"),
+		Traceback:     "lookup(ana@example.com)",
+	})
+	s.redact(client)
+	if info := s.errorInfo.Value; info.Message.Value != "no user [REDACTED_EMAIL]" || info.Traceback != "lookup([REDACTED_EMAIL])" {
+		t.Errorf("error info = %+v, want the message and traceback redacted", info)
+	}
+	if string(s.input) != `[{"content":"mail [REDACTED_EMAIL]","role":"user"}]` {
+		t.Errorf("input = %s", s.input)
+	}
+	if string(s.metadata) != `{"user":"[REDACTED_EMAIL]"}` {
+		t.Errorf("metadata = %s", s.metadata)
+	}
+	if string(s.output) != "null" {
+		t.Errorf("output = %s, want null", s.output)
+	}
+}
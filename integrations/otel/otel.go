@@ -0,0 +1,256 @@
+// Package otel bridges Opik and OpenTelemetry, so teams running an
+// OpenTelemetry collector can see Opik LLM spans alongside their service
+// traces, and spans recorded with OpenTelemetry can be sent to Opik.
+//
+// Spans converts an Opik trace, as read by opik.Client.GetTraceTree or
+// opik.Client.ExportTraces, into OpenTelemetry spans that any
+// sdktrace.SpanExporter can send. Exporter goes the other way: it is an
+// sdktrace.SpanExporter that writes OpenTelemetry spans to Opik.
+//
+// Models, providers, and token usage are mapped to the OpenTelemetry
+// semantic conventions for generative AI (gen_ai.*). Opik fields with no
+// convention, such as inputs, outputs, and feedback scores, use opik.*
+// attributes, so a span converted one way and back keeps them.
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// ScopeName is the instrumentation scope of spans converted from Opik.
+const ScopeName = "github.com/plexusone/opik-go/integrations/otel"
+
+// Attributes for Opik fields that have no GenAI semantic convention.
+// Inputs, outputs, and metadata are JSON-encoded.
+const (
+	AttrSpanType = attribute.Key("opik.span.type")
+	AttrInput    = attribute.Key("opik.input")
+	AttrOutput   = attribute.Key("opik.output")
+	AttrMetadata = attribute.Key("opik.metadata")
+	AttrTags     = attribute.Key("opik.tags")
+	// AttrFeedbackScorePrefix is followed by the score name, as in
+	// "opik.feedback_score.relevance".
+	AttrFeedbackScorePrefix = "opik.feedback_score."
+	// AttrUsagePrefix is followed by a usage key with no GenAI convention,
+	// as in "opik.usage.total_tokens".
+	AttrUsagePrefix = "opik.usage."
+)
+
+// Option configures the conversion in either direction.
+type Option func(*options)
+
+type options struct {
+	resource    *resource.Resource
+	projectName string
+}
+
+// WithResource sets the resource of the spans returned by Spans, which
+// identifies where they came from in the collector. The default resource
+// has service.name "opik".
+func WithResource(res *resource.Resource) Option {
+	return func(o *options) {
+		o.resource = res
+	}
+}
+
+// WithProjectName sets the Opik project that Exporter writes traces to.
+// The default is the client's project.
+func WithProjectName(name string) Option {
+	return func(o *options) {
+		o.projectName = name
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.resource == nil {
+		o.resource = resource.NewSchemaless(semconv.ServiceName("opik"))
+	}
+	return o
+}
+
+// ExportTrace converts tree with Spans and sends the spans to exporter.
+func ExportTrace(ctx context.Context, exporter sdktrace.SpanExporter, tree *opik.TraceTree, opts ...Option) error {
+	return exporter.ExportSpans(ctx, Spans(tree, opts...))
+}
+
+// Spans converts an Opik trace and its spans into OpenTelemetry spans. The
+// trace itself becomes the root span, and the Opik spans keep their
+// parents beneath it; spans whose parent is not in the tree are attached to
+// the root.
+//
+// The OpenTelemetry trace ID is the Opik trace ID, and each span ID is the
+// last eight bytes of the Opik ID, so the same Opik trace always converts
+// to the same IDs.
+func Spans(tree *opik.TraceTree, opts ...Option) []sdktrace.ReadOnlySpan {
+	o := newOptions(opts)
+	scope := instrumentation.Scope{Name: ScopeName, Version: opik.Version}
+
+	traceID := trace.TraceID(parseUUID(tree.Trace.ID))
+	rootID := spanIDFromUUID(tree.Trace.ID)
+	spanContext := func(spanID trace.SpanID) trace.SpanContext {
+		return trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		})
+	}
+
+	children := make(map[string]int, len(tree.Spans))
+	known := make(map[string]bool, len(tree.Spans))
+	for _, s := range tree.Spans {
+		known[s.ID] = true
+	}
+	for _, s := range tree.Spans {
+		if known[s.ParentSpanID] {
+			children[s.ParentSpanID]++
+		} else {
+			children[""]++
+		}
+	}
+
+	t := tree.Trace
+	root := tracetest.SpanStub{
+		Name:                 t.Name,
+		SpanContext:          spanContext(rootID),
+		SpanKind:             trace.SpanKindServer,
+		StartTime:            t.StartTime,
+		EndTime:              endTime(t.StartTime, t.EndTime),
+		Attributes:           commonAttributes(t.Input, t.Output, t.Metadata, t.Tags, t.FeedbackScores),
+		ChildSpanCount:       children[""],
+		Resource:             o.resource,
+		InstrumentationScope: scope,
+	}
+	spans := []sdktrace.ReadOnlySpan{root.Snapshot()}
+
+	for _, s := range tree.Spans {
+		parent := rootID
+		if known[s.ParentSpanID] {
+			parent = spanIDFromUUID(s.ParentSpanID)
+		}
+		stub := tracetest.SpanStub{
+			Name:                 s.Name,
+			SpanContext:          spanContext(spanIDFromUUID(s.ID)),
+			Parent:               spanContext(parent),
+			SpanKind:             trace.SpanKindInternal,
+			StartTime:            s.StartTime,
+			EndTime:              endTime(s.StartTime, s.EndTime),
+			Attributes:           spanAttributes(s),
+			ChildSpanCount:       children[s.ID],
+			Resource:             o.resource,
+			InstrumentationScope: scope,
+		}
+		if s.Type == opik.SpanTypeLLM {
+			stub.SpanKind = trace.SpanKindClient
+		}
+		spans = append(spans, stub.Snapshot())
+	}
+	return spans
+}
+
+// spanAttributes maps an Opik span to GenAI and opik.* attributes.
+func spanAttributes(s *opik.SpanInfo) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if s.Type != "" {
+		attrs = append(attrs, AttrSpanType.String(s.Type))
+	}
+	switch s.Type {
+	case opik.SpanTypeLLM:
+		attrs = append(attrs, semconv.GenAIOperationNameChat)
+	case opik.SpanTypeTool:
+		attrs = append(attrs, semconv.GenAIOperationNameExecuteTool, semconv.GenAIToolName(s.Name))
+	}
+	if s.Model != "" {
+		attrs = append(attrs, semconv.GenAIRequestModel(s.Model))
+	}
+	if s.Provider != "" {
+		attrs = append(attrs, semconv.GenAIProviderNameKey.String(s.Provider))
+	}
+
+	keys := make([]string, 0, len(s.Usage))
+	for k := range s.Usage {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch k {
+		case "prompt_tokens":
+			attrs = append(attrs, semconv.GenAIUsageInputTokens(s.Usage[k]))
+		case "completion_tokens":
+			attrs = append(attrs, semconv.GenAIUsageOutputTokens(s.Usage[k]))
+		default:
+			attrs = append(attrs, attribute.Int(AttrUsagePrefix+k, s.Usage[k]))
+		}
+	}
+	return append(attrs, commonAttributes(s.Input, s.Output, s.Metadata, s.Tags, s.FeedbackScores)...)
+}
+
+// commonAttributes maps the fields traces and spans share.
+func commonAttributes(input, output, metadata any, tags []string, scores []opik.FeedbackScoreInfo) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, field := range []struct {
+		key   attribute.Key
+		value any
+	}{{AttrInput, input}, {AttrOutput, output}, {AttrMetadata, metadata}} {
+		if field.value == nil {
+			continue
+		}
+		if data, err := json.Marshal(field.value); err == nil {
+			attrs = append(attrs, field.key.String(string(data)))
+		}
+	}
+	if len(tags) > 0 {
+		attrs = append(attrs, AttrTags.StringSlice(tags))
+	}
+	for _, score := range scores {
+		attrs = append(attrs, attribute.Float64(AttrFeedbackScorePrefix+score.Name, score.Value))
+	}
+	return attrs
+}
+
+// parseUUID parses an Opik ID, returning the zero UUID if it is invalid.
+func parseUUID(id string) uuid.UUID {
+	u, _ := uuid.Parse(id)
+	return u
+}
+
+// spanIDFromUUID returns the last eight bytes of an Opik ID, the random
+// part of a UUID v7.
+func spanIDFromUUID(id string) trace.SpanID {
+	u := parseUUID(id)
+	var spanID trace.SpanID
+	copy(spanID[:], u[8:])
+	return spanID
+}
+
+// endTime returns end, or start for spans that were never ended.
+func endTime(start, end time.Time) time.Time {
+	if end.IsZero() {
+		return start
+	}
+	return end
+}
+
+// hasPrefix reports whether key starts with prefix and has a name after it.
+func hasPrefix(key attribute.Key, prefix string) (string, bool) {
+	name, ok := strings.CutPrefix(string(key), prefix)
+	return name, ok && name != ""
+}
@@ -0,0 +1,119 @@
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+func testTree() *opik.TraceTree {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	traceID := uuid.Must(uuid.NewV7()).String()
+	agentID := uuid.Must(uuid.NewV7()).String()
+	spans := []*opik.SpanInfo{
+		{ID: agentID, TraceID: traceID, Name: "agent", Type: opik.SpanTypeGeneral, StartTime: base, EndTime: base.Add(3 * time.Second)},
+		{
+			ID: uuid.Must(uuid.NewV7()).String(), TraceID: traceID, ParentSpanID: agentID, Name: "chat", Type: opik.SpanTypeLLM,
+			StartTime: base.Add(time.Second), EndTime: base.Add(2 * time.Second),
+			Model: "gpt-4o", Provider: "openai",
+			Usage:          map[string]int{"prompt_tokens": 12, "completion_tokens": 30, "total_tokens": 42},
+			Input:          map[string]any{"prompt": "hi"},
+			FeedbackScores: []opik.FeedbackScoreInfo{{Name: "relevance", Value: 0.5}},
+		},
+		{ID: uuid.Must(uuid.NewV7()).String(), TraceID: traceID, ParentSpanID: uuid.Must(uuid.NewV7()).String(), Name: "search", Type: opik.SpanTypeTool, StartTime: base},
+	}
+	return &opik.TraceTree{
+		Trace: &opik.TraceInfo{
+			ID: traceID, Name: "request", StartTime: base, EndTime: base.Add(4 * time.Second),
+			Output: map[string]any{"answer": "hello"}, Tags: []string{"prod"},
+		},
+		Spans: spans,
+		Roots: opik.BuildSpanTree(spans),
+	}
+}
+
+func attrs(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestSpans(t *testing.T) {
+	tree := testTree()
+	exporter := tracetest.NewInMemoryExporter()
+	if err := ExportTrace(context.Background(), exporter, tree); err != nil {
+		t.Fatalf("ExportTrace error: %v", err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 4 {
+		t.Fatalf("got %d spans, want 4", len(spans))
+	}
+	root, agent, chat, search := spans[0], spans[1], spans[2], spans[3]
+
+	if got := root.SpanContext.TraceID(); uuid.UUID(got).String() != tree.Trace.ID {
+		t.Errorf("trace ID = %s, want the Opik trace ID", got)
+	}
+	if root.Parent.IsValid() || root.SpanKind != trace.SpanKindServer || root.ChildSpanCount != 2 {
+		t.Errorf("root = %+v", root)
+	}
+	if a := attrs(root.Attributes); a[AttrOutput].AsString() != `{"answer":"hello"}` || a[AttrTags].AsStringSlice()[0] != "prod" {
+		t.Errorf("root attributes = %v", root.Attributes)
+	}
+	if agent.Parent.SpanID() != root.SpanContext.SpanID() || search.Parent.SpanID() != root.SpanContext.SpanID() {
+		t.Error("top-level spans and orphans should be children of the trace span")
+	}
+	if chat.Parent.SpanID() != agent.SpanContext.SpanID() || chat.SpanKind != trace.SpanKindClient {
+		t.Errorf("chat span = %+v", chat)
+	}
+	if !search.EndTime.Equal(search.StartTime) {
+		t.Errorf("unended span ends at %v, want its start", search.EndTime)
+	}
+
+	a := attrs(chat.Attributes)
+	for key, want := range map[attribute.Key]any{
+		"gen_ai.operation.name":         "chat",
+		"gen_ai.request.model":          "gpt-4o",
+		"gen_ai.provider.name":          "openai",
+		"gen_ai.usage.input_tokens":     int64(12),
+		"gen_ai.usage.output_tokens":    int64(30),
+		"opik.usage.total_tokens":       int64(42),
+		"opik.input":                    `{"prompt":"hi"}`,
+		"opik.feedback_score.relevance": 0.5,
+	} {
+		if got := a[key].AsInterface(); got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+	if name := attrs(search.Attributes)["gen_ai.tool.name"].AsString(); name != "search" {
+		t.Errorf("gen_ai.tool.name = %q", name)
+	}
+	if name, _ := chat.Resource.Set().Value("service.name"); name.AsString() != "opik" {
+		t.Errorf("service.name = %q", name.AsString())
+	}
+}
+
+func TestUUIDMapping(t *testing.T) {
+	opikID := uuid.Must(uuid.NewV7())
+	if got := TraceUUID(trace.TraceID(opikID)); got != opikID {
+		t.Errorf("TraceUUID = %s, want %s", got, opikID)
+	}
+
+	traceID := trace.TraceID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	spanID := trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+	u := SpanUUID(traceID, spanID)
+	if u.Version() != 7 || u.Variant() != uuid.RFC4122 {
+		t.Errorf("SpanUUID %s is not a UUID v7", u)
+	}
+	if u != SpanUUID(traceID, spanID) || u == SpanUUID(traceID, trace.SpanID{8}) {
+		t.Error("SpanUUID is not a stable, distinct mapping")
+	}
+}
@@ -0,0 +1,40 @@
+// Package backoff computes exponential waits between retries and waits
+// for them, shared by the client's HTTP retries, the evaluation engine's
+// metric retries, and the omnillm integration's stream reconnects.
+package backoff
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Exponential returns the wait before retry number attempt, counting from
+// 0: initial, doubled for each further retry, up to limit. The wait is
+// shortened by a random fraction of up to jitter, between 0 and 1, so
+// clients failing together do not all retry together.
+func Exponential(initial, limit time.Duration, jitter float64, attempt int) time.Duration {
+	d := limit
+	if attempt < 32 && initial<<attempt < limit && initial<<attempt > 0 {
+		d = initial << attempt
+	}
+	if jitter > 0 {
+		d -= time.Duration(rand.Float64() * jitter * float64(d))
+	}
+	return d
+}
+
+// Sleep waits for d, or returns the context's error if it is done first.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 3 * time.Second},
+		{40, 3 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := Exponential(time.Second, 3*time.Second, 0, tt.attempt); got != tt.want {
+			t.Errorf("Exponential(attempt %d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+	for range 100 {
+		if got := Exponential(time.Second, time.Minute, 0.5, 0); got < 500*time.Millisecond || got > time.Second {
+			t.Fatalf("Exponential with jitter = %v, want between 500ms and 1s", got)
+		}
+	}
+}
+
+func TestSleep(t *testing.T) {
+	if err := Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Sleep error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := Sleep(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("Sleep with a canceled context error = %v, want context.Canceled", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Sleep with a canceled context waited")
+	}
+	if err := Sleep(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("Sleep(0) with a canceled context error = %v, want context.Canceled", err)
+	}
+}
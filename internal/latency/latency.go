@@ -0,0 +1,54 @@
+// Package latency summarizes latency measurements, shared by the load
+// generator's trace latencies and the LLM benchmark's request latencies.
+package latency
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Stats summarizes a set of latency measurements. Percentiles are
+// nearest-rank.
+type Stats struct {
+	Min  time.Duration `json:"min"`
+	Max  time.Duration `json:"max"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P95  time.Duration `json:"p95"`
+	P99  time.Duration `json:"p99"`
+}
+
+// Summarize returns the stats of values, or zero stats if there are none.
+// values is not modified.
+func Summarize(values []time.Duration) Stats {
+	if len(values) == 0 {
+		return Stats{}
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	var sum time.Duration
+	for _, v := range sorted {
+		sum += v
+	}
+
+	return Stats{
+		Min:  sorted[0],
+		Max:  sorted[len(sorted)-1],
+		Mean: sum / time.Duration(len(sorted)),
+		P50:  percentile(sorted, 50),
+		P90:  percentile(sorted, 90),
+		P95:  percentile(sorted, 95),
+		P99:  percentile(sorted, 99),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = min(max(rank, 1), len(sorted))
+	return sorted[rank-1]
+}
@@ -0,0 +1,25 @@
+package latency
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	values := []time.Duration{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	stats := Summarize(values)
+
+	want := Stats{Min: 1, Max: 10, Mean: 5, P50: 5, P90: 9, P95: 10, P99: 10}
+	if stats != want {
+		t.Errorf("Summarize = %+v, want %+v", stats, want)
+	}
+	if values[0] != 10 {
+		t.Error("Summarize sorted its input")
+	}
+	if (Summarize(nil) != Stats{}) {
+		t.Error("empty input should give zero stats")
+	}
+	if got := Summarize([]time.Duration{time.Second}); got.P50 != time.Second || got.P99 != time.Second {
+		t.Errorf("single value stats = %+v", got)
+	}
+}
@@ -0,0 +1,44 @@
+// Package pii holds the patterns that find personal data and secrets in
+// text, shared by the client's built-in redactors and the PII guardrail.
+package pii
+
+import "regexp"
+
+// Patterns for kinds of personal data and secrets. CreditCard matches any
+// run of 13 to 19 digits, so check matches with Luhn.
+var (
+	Email      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	Phone      = regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)\s?|\b\d{3}[\s.-])\d{3}[\s.-]\d{4}\b`)
+	CreditCard = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	SSN        = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	IPAddress  = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`)
+
+	// APIKey matches the key formats of common LLM and cloud providers:
+	// OpenAI and Anthropic (sk-), AWS access key IDs, GitHub tokens, Slack
+	// tokens, and Google API keys.
+	APIKey = regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}` +
+		`|\bAKIA[0-9A-Z]{16}\b` +
+		`|\bgh[pousr]_[A-Za-z0-9]{36,}\b|\bgithub_pat_[A-Za-z0-9_]{22,}` +
+		`|\bxox[abprs]-[A-Za-z0-9-]{10,}` +
+		`|\bAIza[0-9A-Za-z_-]{35}`)
+)
+
+// Luhn reports whether the digits in number pass the Luhn checksum.
+func Luhn(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
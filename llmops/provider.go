@@ -96,10 +96,10 @@ func (p *Provider) Name() string {
 	return ProviderName
 }
 
-// Close closes the provider.
+// Close sends any queued traces and spans and releases the client's
+// resources.
 func (p *Provider) Close() error {
-	// Opik client doesn't have a Close method currently
-	return nil
+	return p.client.Close(context.Background())
 }
 
 // StartTrace starts a new trace.
@@ -142,7 +142,7 @@ func (p *Provider) StartSpan(ctx context.Context, name string, opts ...llmops.Sp
 		return ctx, nil, err
 	}
 
-	return newCtx, &spanAdapter{span: span}, nil
+	return newCtx, newSpanAdapter(span, cfg), nil
 }
 
 // TraceFromContext gets the current trace from context.
@@ -477,7 +477,7 @@ func mapSpanOptions(cfg *llmops.SpanOptions) []opik.SpanOption {
 	if cfg.Provider != "" {
 		opikOpts = append(opikOpts, opik.WithSpanProvider(cfg.Provider))
 	}
-	// Note: Usage is set via span.SetUsage() after creation in Opik
+	// Usage is set on the span after creation by newSpanAdapter
 
 	return opikOpts
 }
@@ -34,7 +34,7 @@ func (t *traceAdapter) StartSpan(ctx context.Context, name string, opts ...llmop
 	}
 
 	newCtx := opik.ContextWithSpan(ctx, span)
-	return newCtx, &spanAdapter{span: span}, nil
+	return newCtx, newSpanAdapter(span, cfg), nil
 }
 
 // SetInput sets the trace input.
@@ -107,6 +107,15 @@ type spanAdapter struct {
 	span *opik.Span
 }
 
+// newSpanAdapter wraps span, setting the usage given in its options.
+func newSpanAdapter(span *opik.Span, cfg *llmops.SpanOptions) *spanAdapter {
+	s := &spanAdapter{span: span}
+	if cfg.Usage != nil {
+		_ = s.SetUsage(*cfg.Usage)
+	}
+	return s
+}
+
 // ID returns the span ID.
 func (s *spanAdapter) ID() string {
 	return s.span.ID()
@@ -143,7 +152,7 @@ func (s *spanAdapter) StartSpan(ctx context.Context, name string, opts ...llmops
 	}
 
 	newCtx := opik.ContextWithSpan(ctx, span)
-	return newCtx, &spanAdapter{span: span}, nil
+	return newCtx, newSpanAdapter(span, cfg), nil
 }
 
 // SetInput sets the span input.
@@ -171,7 +180,8 @@ func (s *spanAdapter) SetProvider(provider string) error {
 	return s.span.Update(context.Background(), opik.WithSpanProvider(provider))
 }
 
-// SetUsage sets token usage.
+// SetUsage sets token usage. A total cost in the usage is recorded as the
+// span's cost; without one, Opik estimates the cost from the span's model.
 func (s *spanAdapter) SetUsage(usage llmops.TokenUsage) error {
 	usageMap := map[string]int{
 		"prompt_tokens":     usage.PromptTokens,
@@ -179,6 +189,9 @@ func (s *spanAdapter) SetUsage(usage llmops.TokenUsage) error {
 		"total_tokens":      usage.TotalTokens,
 	}
 	s.span.SetUsage(usageMap)
+	if usage.TotalCost > 0 {
+		s.span.SetCost(usage.TotalCost)
+	}
 	return nil
 }
 
@@ -0,0 +1,399 @@
+// Package loadgen generates synthetic traces against an Opik server to
+// measure ingest throughput and error rates, for example when capacity
+// testing a self-hosted deployment.
+//
+// Each generated trace has a tree of nested spans whose leaves are LLM spans
+// with token usage, optionally written as streaming spans. Inputs and outputs
+// are random text of a configurable size.
+//
+//	client, _ := opik.NewClient(opik.WithURL("http://opik.internal:5173/api"))
+//	report, err := loadgen.Run(ctx, client,
+//	    loadgen.WithRate(50),
+//	    loadgen.WithDuration(time.Minute),
+//	    loadgen.WithShape(loadgen.Shape{SpanDepth: 3, SpansPerLevel: 2, PayloadBytes: 4096}),
+//	)
+//	fmt.Println(report)
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/internal/latency"
+)
+
+// Shape describes the traces to generate.
+type Shape struct {
+	// SpanDepth is the number of levels of nested spans below each trace.
+	SpanDepth int
+	// SpansPerLevel is the number of child spans of the trace and of each
+	// span above the deepest level.
+	SpansPerLevel int
+	// PayloadBytes is the approximate size of each input and output.
+	PayloadBytes int
+	// Streaming writes the leaf LLM spans as streaming spans.
+	Streaming bool
+}
+
+// SpansPerTrace returns the number of spans in each generated trace.
+func (s Shape) SpansPerTrace() int {
+	n, level := 0, 1
+	for range s.SpanDepth {
+		level *= s.SpansPerLevel
+		n += level
+	}
+	return n
+}
+
+// DefaultShape is used when no shape is configured.
+var DefaultShape = Shape{SpanDepth: 2, SpansPerLevel: 2, PayloadBytes: 512}
+
+// Option configures a load generation run.
+type Option func(*config)
+
+type config struct {
+	rate        float64
+	duration    time.Duration
+	traces      int
+	concurrency int
+	shape       Shape
+	project     string
+	seed        uint64
+}
+
+// WithRate sets the target number of traces started per second. By default,
+// traces are generated as fast as the workers allow.
+func WithRate(tracesPerSecond float64) Option {
+	return func(c *config) {
+		c.rate = tracesPerSecond
+	}
+}
+
+// WithDuration stops the run after d. When combined with WithTraceCount the
+// run stops at whichever limit is reached first.
+func WithDuration(d time.Duration) Option {
+	return func(c *config) {
+		c.duration = d
+	}
+}
+
+// WithTraceCount stops the run after n traces. It defaults to 100 when no
+// duration is set.
+func WithTraceCount(n int) Option {
+	return func(c *config) {
+		c.traces = n
+	}
+}
+
+// WithConcurrency sets the number of traces written at once (default 4).
+func WithConcurrency(n int) Option {
+	return func(c *config) {
+		c.concurrency = n
+	}
+}
+
+// WithShape sets the shape of the generated traces.
+func WithShape(shape Shape) Option {
+	return func(c *config) {
+		c.shape = shape
+	}
+}
+
+// WithProject writes traces to the named project instead of the client's.
+func WithProject(name string) Option {
+	return func(c *config) {
+		c.project = name
+	}
+}
+
+// WithSeed sets the seed for generated payloads.
+func WithSeed(seed uint64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// LatencyStats summarizes a set of latency measurements: their minimum,
+// maximum, mean, and nearest-rank percentiles.
+type LatencyStats = latency.Stats
+
+// Report is the result of a load generation run.
+type Report struct {
+	// Traces is the number of traces started.
+	Traces int `json:"traces"`
+	// Spans is the number of spans created.
+	Spans int `json:"spans"`
+	// Requests is the number of API requests sent.
+	Requests int `json:"requests"`
+	// Errors is the number of failed requests.
+	Errors int `json:"errors"`
+	// ErrorRate is the fraction of requests that failed.
+	ErrorRate float64 `json:"error_rate"`
+	// ErrorSamples holds up to ten distinct error messages.
+	ErrorSamples []string `json:"error_samples,omitempty"`
+	// PayloadBytes is the total size of generated inputs and outputs.
+	PayloadBytes int64 `json:"payload_bytes"`
+	// Duration is the wall-clock duration of the run.
+	Duration time.Duration `json:"duration"`
+	// TracesPerSecond is the trace throughput.
+	TracesPerSecond float64 `json:"traces_per_second"`
+	// SpansPerSecond is the span throughput.
+	SpansPerSecond float64 `json:"spans_per_second"`
+	// RequestsPerSecond is the request throughput.
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	// TraceLatency summarizes the time to write each complete trace.
+	TraceLatency LatencyStats `json:"trace_latency"`
+}
+
+// String returns a one-line summary of the report.
+func (r *Report) String() string {
+	return fmt.Sprintf("%d traces, %d spans, %d requests (%.1f%% errors) in %s: %.1f traces/s, %.1f spans/s, trace p50=%s p99=%s",
+		r.Traces, r.Spans, r.Requests, r.ErrorRate*100, r.Duration.Round(time.Millisecond),
+		r.TracesPerSecond, r.SpansPerSecond, r.TraceLatency.P50, r.TraceLatency.P99)
+}
+
+// maxErrorSamples is the number of distinct error messages kept in a report.
+const maxErrorSamples = 10
+
+// recorder collects measurements from concurrent workers.
+type recorder struct {
+	mu        sync.Mutex
+	report    Report
+	latencies []time.Duration
+	seen      map[string]bool
+}
+
+func (r *recorder) request(err error) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Requests++
+	if err == nil {
+		return true
+	}
+	r.report.Errors++
+	if msg := err.Error(); !r.seen[msg] && len(r.report.ErrorSamples) < maxErrorSamples {
+		r.seen[msg] = true
+		r.report.ErrorSamples = append(r.report.ErrorSamples, msg)
+	}
+	return false
+}
+
+func (r *recorder) latency(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies = append(r.latencies, d)
+}
+
+func (r *recorder) add(fn func(*Report)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.report)
+}
+
+// Run generates traces with client until the trace count or duration is
+// reached and reports the throughput and errors observed.
+//
+// Failed requests are counted in the report rather than returned. If ctx is
+// cancelled, Run stops starting traces, waits for those in flight, and
+// returns the partial report together with the context's error.
+func Run(ctx context.Context, client *opik.Client, opts ...Option) (*Report, error) {
+	cfg := &config{concurrency: 4, shape: DefaultShape}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.traces <= 0 && cfg.duration <= 0 {
+		cfg.traces = 100
+	}
+	if cfg.concurrency <= 0 {
+		cfg.concurrency = 1
+	}
+
+	runCtx := ctx
+	if cfg.duration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, cfg.duration)
+		defer cancel()
+	}
+
+	rec := &recorder{seen: make(map[string]bool)}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := range cfg.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g := &generator{
+				client: client,
+				cfg:    cfg,
+				rec:    rec,
+				rng:    rand.New(rand.NewPCG(cfg.seed, uint64(w))), //nolint:gosec // G404, G115: synthetic payloads, non-negative worker index
+			}
+			for n := range jobs {
+				g.trace(ctx, n)
+			}
+		}()
+	}
+
+	start := time.Now()
+	produce(runCtx, jobs, cfg)
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	report := rec.report
+	report.Duration = elapsed
+	if report.Requests > 0 {
+		report.ErrorRate = float64(report.Errors) / float64(report.Requests)
+	}
+	if secs := elapsed.Seconds(); secs > 0 {
+		report.TracesPerSecond = float64(report.Traces) / secs
+		report.SpansPerSecond = float64(report.Spans) / secs
+		report.RequestsPerSecond = float64(report.Requests) / secs
+	}
+	report.TraceLatency = latency.Summarize(rec.latencies)
+
+	return &report, ctx.Err()
+}
+
+// produce sends trace numbers to jobs at the configured rate until the trace
+// count is reached or ctx is done.
+func produce(ctx context.Context, jobs chan<- int, cfg *config) {
+	var tick <-chan time.Time
+	if cfg.rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for n := 0; cfg.traces <= 0 || n < cfg.traces; n++ {
+		if ctx.Err() != nil {
+			return
+		}
+		if tick != nil && n > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case jobs <- n:
+		}
+	}
+}
+
+// generator writes traces for one worker.
+type generator struct {
+	client *opik.Client
+	cfg    *config
+	rec    *recorder
+	rng    *rand.Rand
+}
+
+func (g *generator) trace(ctx context.Context, n int) {
+	start := time.Now()
+	input, output := g.payload(), g.payload()
+
+	opts := []opik.TraceOption{
+		opik.WithTraceInput(map[string]any{"prompt": input}),
+		opik.WithTraceMetadata(map[string]any{"loadgen": true, "sequence": n}),
+		opik.WithTraceTags("loadgen"),
+	}
+	if g.cfg.project != "" {
+		opts = append(opts, opik.WithTraceProject(g.cfg.project))
+	}
+	trace, err := g.client.Trace(ctx, fmt.Sprintf("loadgen-%d", n), opts...)
+	g.rec.add(func(r *Report) {
+		r.Traces++
+		r.PayloadBytes += int64(len(input))
+	})
+	if !g.rec.request(err) {
+		return
+	}
+
+	for i := range g.cfg.shape.SpansPerLevel {
+		g.span(ctx, trace.Span, 1, i)
+	}
+
+	err = trace.End(ctx, opik.WithTraceOutput(map[string]any{"response": output}))
+	g.rec.add(func(r *Report) { r.PayloadBytes += int64(len(output)) })
+	if g.rec.request(err) {
+		g.rec.latency(time.Since(start))
+	}
+}
+
+type spanFunc func(ctx context.Context, name string, opts ...opik.SpanOption) (*opik.Span, error)
+
+func (g *generator) span(ctx context.Context, create spanFunc, depth, index int) {
+	shape := g.cfg.shape
+	leaf := depth >= shape.SpanDepth
+	input, output := g.payload(), g.payload()
+
+	opts := []opik.SpanOption{opik.WithSpanInput(map[string]any{"input": input})}
+	name := fmt.Sprintf("step-%d.%d", depth, index)
+	if leaf {
+		name = fmt.Sprintf("llm-%d", index)
+		opts = append(opts,
+			opik.WithSpanType(opik.SpanTypeLLM),
+			opik.WithSpanModel("loadgen-model"),
+			opik.WithSpanProvider("loadgen"),
+		)
+	}
+
+	span, err := create(ctx, name, opts...)
+	g.rec.add(func(r *Report) {
+		r.Spans++
+		r.PayloadBytes += int64(len(input) + len(output))
+	})
+	if !g.rec.request(err) {
+		return
+	}
+
+	if !leaf {
+		for i := range shape.SpansPerLevel {
+			g.span(ctx, span.Span, depth+1, i)
+		}
+		g.rec.request(span.End(ctx, opik.WithSpanOutput(map[string]any{"output": output})))
+		return
+	}
+
+	span.SetUsage(map[string]int{
+		opik.UsagePromptTokens:     len(input) / 4,
+		opik.UsageCompletionTokens: len(output) / 4,
+		opik.UsageTotalTokens:      (len(input) + len(output)) / 4,
+	})
+	if !shape.Streaming {
+		g.rec.request(span.End(ctx, opik.WithSpanOutput(map[string]any{"output": output})))
+		return
+	}
+	stream := opik.NewStreamingSpan(span)
+	for chunk := range strings.SplitAfterSeq(output, " ") {
+		stream.AddChunk(chunk, opik.WithChunkTokenCount(1))
+	}
+	g.rec.request(stream.End(ctx))
+}
+
+var words = strings.Fields(`the a model user answer question context document retrieval
+summary response token latency request trace span evaluation prompt system assistant
+result data value error cache tool call function search query score label`)
+
+// payload returns random text of about the configured payload size.
+func (g *generator) payload() string {
+	size := g.cfg.shape.PayloadBytes
+	var b strings.Builder
+	b.Grow(size + 16)
+	for b.Len() < size {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(words[g.rng.IntN(len(words))])
+	}
+	return b.String()
+}
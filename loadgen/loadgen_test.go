@@ -0,0 +1,137 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *opik.Client {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	client, err := opik.NewClient(opik.WithURL(ts.URL), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	return client
+}
+
+func TestShapeSpansPerTrace(t *testing.T) {
+	tests := []struct {
+		shape Shape
+		want  int
+	}{
+		{Shape{SpanDepth: 0, SpansPerLevel: 3}, 0},
+		{Shape{SpanDepth: 1, SpansPerLevel: 3}, 3},
+		{Shape{SpanDepth: 2, SpansPerLevel: 2}, 6},
+		{Shape{SpanDepth: 3, SpansPerLevel: 2}, 14},
+	}
+	for _, tt := range tests {
+		if got := tt.shape.SpansPerTrace(); got != tt.want {
+			t.Errorf("%+v.SpansPerTrace() = %d, want %d", tt.shape, got, tt.want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	var requests, streamed atomic.Int64
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Method == http.MethodPatch && r.URL.Path == "/v1/private/spans/batch" {
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), `"streaming":true`) {
+				streamed.Add(1)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	shape := Shape{SpanDepth: 2, SpansPerLevel: 2, PayloadBytes: 64, Streaming: true}
+	report, err := Run(context.Background(), client, WithTraceCount(5), WithConcurrency(2), WithShape(shape))
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	if report.Traces != 5 || report.Spans != 30 {
+		t.Errorf("traces = %d, spans = %d, want 5, 30", report.Traces, report.Spans)
+	}
+	// Each trace and span is created and ended.
+	if want := 2 * (5 + 30); report.Requests != want || requests.Load() != int64(want) {
+		t.Errorf("requests = %d (server saw %d), want %d", report.Requests, requests.Load(), want)
+	}
+	if report.Errors != 0 || report.ErrorRate != 0 {
+		t.Errorf("errors = %d, samples = %v", report.Errors, report.ErrorSamples)
+	}
+	if streamed.Load() != 20 {
+		t.Errorf("streamed leaf spans = %d, want 20", streamed.Load())
+	}
+	if report.PayloadBytes < 64*2*35 {
+		t.Errorf("payload bytes = %d", report.PayloadBytes)
+	}
+	if report.TraceLatency.P50 <= 0 || report.TracesPerSecond <= 0 {
+		t.Errorf("latency = %+v, traces/s = %v", report.TraceLatency, report.TracesPerSecond)
+	}
+	if !strings.Contains(report.String(), "5 traces, 30 spans") {
+		t.Errorf("String() = %q", report.String())
+	}
+}
+
+func TestRunCountsErrors(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/private/spans/batch" && r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	report, err := Run(context.Background(), client, WithTraceCount(3), WithShape(Shape{SpanDepth: 1, SpansPerLevel: 2}))
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	// 3 trace creates and ends succeed, 6 span creates fail and are not ended.
+	if report.Requests != 12 || report.Errors != 6 {
+		t.Errorf("requests = %d, errors = %d, want 12, 6", report.Requests, report.Errors)
+	}
+	if report.ErrorRate != 0.5 {
+		t.Errorf("error rate = %v, want 0.5", report.ErrorRate)
+	}
+	if len(report.ErrorSamples) != 1 {
+		t.Errorf("error samples = %v, want one distinct error", report.ErrorSamples)
+	}
+}
+
+func TestRunRateAndDuration(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	report, err := Run(context.Background(), client,
+		WithRate(50), WithDuration(100*time.Millisecond), WithShape(Shape{}))
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	// About 5 traces at 50/s over 100ms; allow for scheduling jitter.
+	if report.Traces < 2 || report.Traces > 8 {
+		t.Errorf("traces = %d, want about 5", report.Traces)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err = Run(ctx, client, WithTraceCount(10))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if report == nil || report.Traces != 0 {
+		t.Errorf("report = %+v, want partial report", report)
+	}
+}
@@ -0,0 +1,268 @@
+package opik
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// mergePageSize is the number of spans fetched per request when merging.
+const mergePageSize = 500
+
+// MergeTraces merges the source traces into the target trace using the client
+// attached to ctx. See Client.MergeTraces.
+func MergeTraces(ctx context.Context, targetTraceID string, sourceTraceIDs ...string) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return ErrNoClient
+	}
+	return client.MergeTraces(ctx, targetTraceID, sourceTraceIDs...)
+}
+
+// MergeTraces moves the spans of the source traces into the target trace, so
+// that a request handled by several processes shows as a single trace.
+//
+// Each source trace becomes a span of the target trace carrying the source
+// trace's name, timing, input, output and feedback scores, with the source's
+// spans and their feedback scores nested beneath it. Spans are copied under
+// new IDs, into the target trace's project, because the server does not
+// allow changing a span's trace; the source traces are deleted once all
+// copies are written. Repeated source IDs are merged once.
+//
+// To avoid merging altogether, propagate the trace context between processes
+// with InjectDistributedTraceHeaders and ContinueTrace before spans are sent.
+func (c *Client) MergeTraces(ctx context.Context, targetTraceID string, sourceTraceIDs ...string) error {
+	targetUUID, err := uuid.Parse(targetTraceID)
+	if err != nil {
+		return fmt.Errorf("%w: target trace ID: %v", ErrInvalidInput, err)
+	}
+
+	// Every ID is checked before anything is written or deleted.
+	sources := make([]uuid.UUID, 0, len(sourceTraceIDs))
+	for _, id := range sourceTraceIDs {
+		sourceUUID, err := uuid.Parse(id)
+		if err != nil {
+			return fmt.Errorf("%w: source trace ID: %v", ErrInvalidInput, err)
+		}
+		if sourceUUID == targetUUID {
+			return fmt.Errorf("%w: cannot merge trace %s into itself", ErrInvalidInput, id)
+		}
+		if !slices.Contains(sources, sourceUUID) {
+			sources = append(sources, sourceUUID)
+		}
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	projectName, err := c.traceProjectName(ctx, targetUUID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		spans  []api.SpanWrite
+		scores []api.FeedbackScoreBatchItem
+	)
+	for _, sourceUUID := range sources {
+		copied, copiedScores, err := c.copyTraceSpans(ctx, sourceUUID, targetUUID, projectName)
+		if err != nil {
+			return err
+		}
+		spans = append(spans, copied...)
+		scores = append(scores, copiedScores...)
+	}
+
+	for start := 0; start < len(spans); start += c.batchConfig.maxItems {
+		end := min(start+c.batchConfig.maxItems, len(spans))
+		req := api.SpanBatchWrite{Spans: spans[start:end]}
+		err := c.apiClient.CreateSpans(ctx, api.NewOptSpanBatchWrite(req))
+		c.auditBatch(AuditCreate, AuditEntitySpan, spanIDs(req.Spans), err)
+		if err != nil {
+			return fmt.Errorf("opik: writing merged spans: %w", err)
+		}
+	}
+
+	for start := 0; start < len(scores); start += c.batchConfig.maxItems {
+		end := min(start+c.batchConfig.maxItems, len(scores))
+		req := api.FeedbackScoreBatch{Scores: scores[start:end]}
+		err := c.apiClient.ScoreBatchOfSpans(ctx, api.NewOptFeedbackScoreBatch(req))
+		ids := make([]uuid.UUID, len(req.Scores))
+		for i, score := range req.Scores {
+			ids[i] = score.ID
+		}
+		c.auditBatch(AuditCreate, AuditEntityFeedbackScore, ids, err)
+		if err != nil {
+			return fmt.Errorf("opik: writing merged feedback scores: %w", err)
+		}
+	}
+
+	err = c.apiClient.DeleteTraces(ctx, api.NewOptBatchDelete(api.BatchDelete{Ids: sources}))
+	c.auditBatch(AuditDelete, AuditEntityTrace, sources, err)
+	if err != nil {
+		return fmt.Errorf("opik: deleting merged traces: %w", err)
+	}
+	return nil
+}
+
+// traceProjectName returns the name of the project of a trace, or the
+// client's project if the server does not report it.
+func (c *Client) traceProjectName(ctx context.Context, traceUUID uuid.UUID) (string, error) {
+	trace, err := c.apiClient.GetTraceById(ctx, api.GetTraceByIdParams{ID: traceUUID})
+	if err != nil {
+		return "", fmt.Errorf("opik: getting trace %s: %w", traceUUID, err)
+	}
+	if !trace.ProjectID.Set {
+		return c.projectName, nil
+	}
+	project, err := c.apiClient.GetProjectById(ctx, api.GetProjectByIdParams{ID: trace.ProjectID.Value})
+	if err != nil {
+		return "", fmt.Errorf("opik: getting project of trace %s: %w", traceUUID, err)
+	}
+	return project.Name, nil
+}
+
+// copyTraceSpans returns copies of the source trace and its spans, rewritten
+// as spans of the target trace in project under new IDs, and their feedback
+// scores, rewritten for the copies.
+func (c *Client) copyTraceSpans(ctx context.Context, sourceUUID, targetUUID uuid.UUID, project string) ([]api.SpanWrite, []api.FeedbackScoreBatchItem, error) {
+	trace, err := c.apiClient.GetTraceById(ctx, api.GetTraceByIdParams{ID: sourceUUID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("opik: getting trace %s: %w", sourceUUID, err)
+	}
+
+	var source []api.SpanPublic
+	for page := 1; ; page++ {
+		resp, err := c.apiClient.GetSpansByProject(ctx, api.GetSpansByProjectParams{
+			ProjectID: trace.ProjectID,
+			TraceID:   api.NewOptUUID(sourceUUID),
+			Page:      api.NewOptInt32(int32(page)), //nolint:gosec // G115: page values are bounded by span count
+			Size:      api.NewOptInt32(mergePageSize),
+			Truncate:  api.NewOptBool(false),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("opik: listing spans of trace %s: %w", sourceUUID, err)
+		}
+		source = append(source, resp.Content...)
+		if len(resp.Content) < mergePageSize {
+			break
+		}
+	}
+
+	rootUUID, err := uuid.NewV7()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate span UUID: %w", err)
+	}
+
+	// New IDs are assigned up front so children can refer to their parent's
+	// copy regardless of order.
+	ids := make(map[uuid.UUID]uuid.UUID, len(source))
+	for _, s := range source {
+		if !s.ID.Set {
+			continue
+		}
+		newUUID, err := uuid.NewV7()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate span UUID: %w", err)
+		}
+		ids[s.ID.Value] = newUUID
+	}
+
+	root := api.SpanWrite{
+		ID:          api.NewOptUUID(rootUUID),
+		ProjectName: api.NewOptString(project),
+		TraceID:     api.NewOptUUID(targetUUID),
+		Name:        trace.Name,
+		Type:        api.NewOptSpanWriteType(api.SpanWriteTypeGeneral),
+		StartTime:   trace.StartTime,
+		EndTime:     trace.EndTime,
+		Input:       writeJSON(trace.Input),
+		Output:      writeJSON(trace.Output),
+		Metadata:    writeJSON(trace.Metadata),
+		Tags:        trace.Tags,
+	}
+	if trace.ErrorInfo.Set {
+		root.ErrorInfo = api.NewOptErrorInfoWrite(api.ErrorInfoWrite(trace.ErrorInfo.Value))
+	}
+
+	spans := make([]api.SpanWrite, 0, len(source)+1)
+	spans = append(spans, root)
+	scores := copyFeedbackScores(nil, trace.FeedbackScores, rootUUID, project)
+	for _, s := range source {
+		span := api.SpanWrite{
+			ProjectName:        api.NewOptString(project),
+			TraceID:            api.NewOptUUID(targetUUID),
+			ParentSpanID:       api.NewOptUUID(rootUUID),
+			Name:               s.Name,
+			StartTime:          s.StartTime,
+			EndTime:            s.EndTime,
+			Input:              writeJSON(s.Input),
+			Output:             writeJSON(s.Output),
+			Metadata:           writeJSON(s.Metadata),
+			Model:              s.Model,
+			Provider:           s.Provider,
+			Tags:               s.Tags,
+			TotalEstimatedCost: s.TotalEstimatedCost,
+		}
+		if newUUID, ok := ids[s.ID.Value]; ok && s.ID.Set {
+			span.ID = api.NewOptUUID(newUUID)
+			scores = copyFeedbackScores(scores, s.FeedbackScores, newUUID, project)
+		}
+		if s.ParentSpanID.Set {
+			if parent, ok := ids[s.ParentSpanID.Value]; ok {
+				span.ParentSpanID = api.NewOptUUID(parent)
+			}
+		}
+		if s.Type.Set {
+			span.Type = api.NewOptSpanWriteType(api.SpanWriteType(s.Type.Value))
+		}
+		if s.Usage.Set {
+			span.Usage = api.NewOptSpanWriteUsage(api.SpanWriteUsage(s.Usage.Value))
+		}
+		if s.ErrorInfo.Set {
+			span.ErrorInfo = api.NewOptErrorInfoWrite(api.ErrorInfoWrite(s.ErrorInfo.Value))
+		}
+		spans = append(spans, span)
+	}
+	return spans, scores, nil
+}
+
+// copyFeedbackScores appends copies of scores, for the span spanUUID in
+// project, to dst.
+func copyFeedbackScores(dst []api.FeedbackScoreBatchItem, scores []api.FeedbackScorePublic, spanUUID uuid.UUID, project string) []api.FeedbackScoreBatchItem {
+	for _, score := range scores {
+		dst = append(dst, api.FeedbackScoreBatchItem{
+			ID:           spanUUID,
+			ProjectName:  api.NewOptString(project),
+			Name:         score.Name,
+			CategoryName: score.CategoryName,
+			Value:        score.Value,
+			Reason:       score.Reason,
+			Source:       api.FeedbackScoreBatchItemSource(score.Source),
+			Author:       score.CreatedBy,
+		})
+	}
+	return dst
+}
+
+// writeJSON converts a JSON payload read from the API into a write payload.
+// Note: JsonListStringWrite is raw JSON bytes - use null for empty values.
+func writeJSON(raw api.JsonListStringPublic) api.JsonListStringWrite {
+	if len(raw) == 0 {
+		return api.JsonListStringWrite([]byte("null"))
+	}
+	return api.JsonListStringWrite(raw)
+}
+
+// spanIDs returns the IDs of spans being written.
+func spanIDs(spans []api.SpanWrite) []uuid.UUID {
+	ids := make([]uuid.UUID, len(spans))
+	for i, s := range spans {
+		ids[i] = s.ID.Value
+	}
+	return ids
+}
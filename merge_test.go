@@ -0,0 +1,190 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// mergeServer serves a target trace in project "target-proj" and one source
+// trace with two nested spans, and records the spans and feedback scores
+// written and traces deleted.
+type mergeServer struct {
+	targetID, sourceID, rootSpanID, childSpanID string
+
+	mu      sync.Mutex
+	written []map[string]any
+	scores  []map[string]any
+	deleted []string
+}
+
+const mergeProjectID = "0190b5a4-5b8c-7c1e-9a2f-3d4e5f6a7b8c"
+
+func (m *mergeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/private/traces/"+m.targetID:
+		fmt.Fprintf(w, `{"id": %q, "project_id": %q, "name": "request", "start_time": "2026-01-01T00:00:00Z"}`,
+			m.targetID, mergeProjectID)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/private/projects/"+mergeProjectID:
+		fmt.Fprintf(w, `{"id": %q, "name": "target-proj"}`, mergeProjectID)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/private/traces/"+m.sourceID:
+		fmt.Fprintf(w, `{"id": %q, "name": "worker", "start_time": "2026-01-01T00:00:00Z",
+			"end_time": "2026-01-01T00:00:02Z", "input": {"job": 1}, "tags": ["worker"],
+			"feedback_scores": [{"name": "quality", "value": 0.5, "reason": "ok", "source": "ui"}]}`, m.sourceID)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/private/spans":
+		fmt.Fprintf(w, `{"page": 1, "size": 2, "total": 2, "content": [
+			{"id": %q, "parent_span_id": %q, "trace_id": %q, "name": "llm-call", "type": "llm",
+			 "start_time": "2026-01-01T00:00:01Z", "usage": {"total_tokens": 10},
+			 "feedback_scores": [{"name": "relevance", "value": 1, "source": "sdk"}]},
+			{"id": %q, "trace_id": %q, "name": "process", "type": "general",
+			 "start_time": "2026-01-01T00:00:00Z", "output": {"ok": true}}]}`,
+			m.childSpanID, m.rootSpanID, m.sourceID, m.rootSpanID, m.sourceID)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/private/spans/batch":
+		var body struct {
+			Spans []map[string]any `json:"spans"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		m.mu.Lock()
+		m.written = append(m.written, body.Spans...)
+		m.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.URL.Path == "/v1/private/spans/feedback-scores":
+		var body struct {
+			Scores []map[string]any `json:"scores"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		m.mu.Lock()
+		m.scores = append(m.scores, body.Scores...)
+		m.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/private/traces/delete":
+		var body struct {
+			IDs []string `json:"ids"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		m.mu.Lock()
+		m.deleted = append(m.deleted, body.IDs...)
+		m.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestMergeTraces(t *testing.T) {
+	m := &mergeServer{
+		targetID:    uuid.NewString(),
+		sourceID:    uuid.NewString(),
+		rootSpanID:  uuid.NewString(),
+		childSpanID: uuid.NewString(),
+	}
+	ts := httptest.NewServer(m)
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"), WithProjectName("proj"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	targetID := m.targetID
+	ctx := ContextWithClient(context.Background(), client)
+	// A repeated source is merged once.
+	if err := MergeTraces(ctx, targetID, m.sourceID, m.sourceID); err != nil {
+		t.Fatalf("MergeTraces error: %v", err)
+	}
+
+	if len(m.written) != 3 {
+		t.Fatalf("len(written) = %d, want 3", len(m.written))
+	}
+	byName := make(map[string]map[string]any)
+	for _, s := range m.written {
+		if s["trace_id"] != targetID {
+			t.Errorf("span %v trace_id = %v, want %s", s["name"], s["trace_id"], targetID)
+		}
+		if s["project_name"] != "target-proj" {
+			t.Errorf("span %v project_name = %v", s["name"], s["project_name"])
+		}
+		byName[s["name"].(string)] = s
+	}
+
+	worker, process, call := byName["worker"], byName["process"], byName["llm-call"]
+	if worker == nil || process == nil || call == nil {
+		t.Fatalf("written spans = %v", byName)
+	}
+	if _, ok := worker["parent_span_id"]; ok {
+		t.Errorf("source trace span should be a root span, parent = %v", worker["parent_span_id"])
+	}
+	if process["parent_span_id"] != worker["id"] {
+		t.Errorf("process parent = %v, want %v", process["parent_span_id"], worker["id"])
+	}
+	if call["parent_span_id"] != process["id"] {
+		t.Errorf("llm-call parent = %v, want %v", call["parent_span_id"], process["id"])
+	}
+	if process["id"] == m.rootSpanID || call["id"] == m.childSpanID {
+		t.Error("merged spans should get new IDs")
+	}
+	if !strings.Contains(fmt.Sprint(worker["input"]), "job") {
+		t.Errorf("worker input = %v", worker["input"])
+	}
+
+	// Feedback scores move to the copies of the trace and span they scored.
+	scores := make(map[string]map[string]any)
+	for _, s := range m.scores {
+		scores[s["name"].(string)] = s
+	}
+	if len(m.scores) != 2 || scores["quality"]["id"] != worker["id"] || scores["relevance"]["id"] != call["id"] {
+		t.Errorf("scores = %v, want quality on %v and relevance on %v", m.scores, worker["id"], call["id"])
+	}
+	if q := scores["quality"]; q["value"] != 0.5 || q["reason"] != "ok" || q["source"] != "ui" || q["project_name"] != "target-proj" {
+		t.Errorf("quality score = %v", q)
+	}
+
+	if len(m.deleted) != 1 || m.deleted[0] != m.sourceID {
+		t.Errorf("deleted = %v, want [%s]", m.deleted, m.sourceID)
+	}
+}
+
+func TestMergeTracesErrors(t *testing.T) {
+	if err := MergeTraces(context.Background(), uuid.NewString()); !errors.Is(err, ErrNoClient) {
+		t.Errorf("err = %v, want ErrNoClient", err)
+	}
+
+	ts, calls := newRecordingServer()
+	defer ts.Close()
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	id := uuid.NewString()
+	for _, tt := range []struct {
+		name    string
+		target  string
+		sources []string
+	}{
+		{"bad target", "nope", []string{id}},
+		{"bad source", id, []string{"nope"}},
+		{"self merge", id, []string{id}},
+		{"target among sources", id, []string{uuid.NewString(), id}},
+	} {
+		if err := client.MergeTraces(context.Background(), tt.target, tt.sources...); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: err = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+
+	if err := client.MergeTraces(context.Background(), id); err != nil {
+		t.Errorf("merge without sources: err = %v", err)
+	}
+	if n := len(calls()); n != 0 {
+		t.Errorf("calls = %d, want 0", n)
+	}
+}
@@ -0,0 +1,237 @@
+// Package metadata provides typed access to the metadata of traces, spans,
+// and experiments, with dot paths into nested objects.
+//
+// Metadata is a map[string]any, so it can be passed wherever the SDK takes
+// metadata maps, such as opik.WithSpanMetadata:
+//
+//	md := metadata.New().
+//	    SetString("llm.system", "openai").
+//	    SetInt("retry.count", 2)
+//	span, _ := trace.Span(ctx, "call", opik.WithSpanMetadata(md))
+//
+//	md.GetString("llm.system") // "openai"
+//	md.GetInt("retry.count")   // 2
+//	md["llm"]                  // map[string]any{"system": "openai"}
+//
+// A path is split at dots into the keys of nested objects. Keys that contain
+// dots themselves can only be reached through the map.
+//
+// Merge combines metadata the way the SDK does wherever metadata is added
+// to existing metadata: nested objects are merged key by key, and any other
+// value replaces the previous one.
+package metadata
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+)
+
+// Metadata is metadata with nested objects as map[string]any values.
+type Metadata map[string]any
+
+// New returns empty metadata.
+func New() Metadata {
+	return make(Metadata)
+}
+
+// Set sets the value at path, creating the objects on the way, and
+// replacing values on the way that are not objects. It returns md for
+// chaining.
+func (md Metadata) Set(path string, v any) Metadata {
+	keys := strings.Split(path, ".")
+	m := map[string]any(md)
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := asMap(m[key])
+		if !ok {
+			next = make(map[string]any)
+			m[key] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = v
+	return md
+}
+
+// SetString sets the string at path.
+func (md Metadata) SetString(path, v string) Metadata {
+	return md.Set(path, v)
+}
+
+// SetInt sets the integer at path.
+func (md Metadata) SetInt(path string, v int) Metadata {
+	return md.Set(path, v)
+}
+
+// SetFloat sets the number at path.
+func (md Metadata) SetFloat(path string, v float64) Metadata {
+	return md.Set(path, v)
+}
+
+// SetBool sets the boolean at path.
+func (md Metadata) SetBool(path string, v bool) Metadata {
+	return md.Set(path, v)
+}
+
+// Get returns the value at path, and whether there is one.
+func (md Metadata) Get(path string) (any, bool) {
+	m := map[string]any(md)
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := asMap(m[key])
+		if !ok {
+			return nil, false
+		}
+		m = next
+	}
+	v, ok := m[keys[len(keys)-1]]
+	return v, ok
+}
+
+// Has reports whether there is a value at path.
+func (md Metadata) Has(path string) bool {
+	_, ok := md.Get(path)
+	return ok
+}
+
+// GetString returns the string at path, or "" if the value is missing or
+// not a string.
+func (md Metadata) GetString(path string) string {
+	v, _ := md.Get(path)
+	s, _ := v.(string)
+	return s
+}
+
+// GetInt returns the integer at path, or 0 if the value is missing or not
+// an integer. Whole numbers decoded from JSON as float64 or json.Number
+// are integers.
+func (md Metadata) GetInt(path string) int {
+	v, _ := md.Get(path)
+	switch n := v.(type) {
+	case int:
+		return n
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case float64:
+		if n == math.Trunc(n) {
+			return int(n)
+		}
+	case json.Number:
+		i, _ := n.Int64()
+		return int(i)
+	}
+	return 0
+}
+
+// GetFloat returns the number at path, or 0 if the value is missing or not
+// a number.
+func (md Metadata) GetFloat(path string) float64 {
+	v, _ := md.Get(path)
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case json.Number:
+		f, _ := n.Float64()
+		return f
+	}
+	return 0
+}
+
+// GetBool returns the boolean at path, or false if the value is missing or
+// not a boolean.
+func (md Metadata) GetBool(path string) bool {
+	v, _ := md.Get(path)
+	b, _ := v.(bool)
+	return b
+}
+
+// GetMap returns the object at path, or nil if the value is missing or not
+// an object. Changes to it change md.
+func (md Metadata) GetMap(path string) Metadata {
+	v, _ := md.Get(path)
+	m, _ := asMap(v)
+	return m
+}
+
+// Delete removes the value at path, if there is one. It returns md for
+// chaining.
+func (md Metadata) Delete(path string) Metadata {
+	keys := strings.Split(path, ".")
+	m := map[string]any(md)
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := asMap(m[key])
+		if !ok {
+			return md
+		}
+		m = next
+	}
+	delete(m, keys[len(keys)-1])
+	return md
+}
+
+// Merge merges src into md, as the package function Merge does, and
+// returns md. The objects of src are copied, so later changes to md do not
+// change src.
+func (md Metadata) Merge(src map[string]any) Metadata {
+	for k, v := range src {
+		if sv, ok := asMap(v); ok {
+			dv, _ := asMap(md[k])
+			md[k] = map[string]any(Metadata(clone(dv)).Merge(sv))
+			continue
+		}
+		md[k] = v
+	}
+	return md
+}
+
+// Clone returns a copy of md with its nested objects copied.
+func (md Metadata) Clone() Metadata {
+	if md == nil {
+		return nil
+	}
+	return clone(md)
+}
+
+// Merge returns new metadata with the maps merged in order: nested objects
+// are merged key by key, and any other value replaces the value of an
+// earlier map. The maps are not modified.
+func Merge(maps ...map[string]any) Metadata {
+	md := New()
+	for _, m := range maps {
+		md.Merge(m)
+	}
+	return md
+}
+
+// clone copies m and its nested objects.
+func clone(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if nested, ok := asMap(v); ok {
+			v = clone(nested)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// asMap returns v as an object, if it is one.
+func asMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case map[string]any:
+		return m, true
+	case Metadata:
+		return m, true
+	}
+	return nil, false
+}
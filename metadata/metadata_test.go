@@ -0,0 +1,133 @@
+package metadata
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSetGet(t *testing.T) {
+	md := New().
+		SetString("llm.system", "openai").
+		SetInt("retry.count", 2).
+		SetFloat("retry.backoff_s", 1.5).
+		SetBool("cached", true)
+
+	want := Metadata{
+		"llm":    map[string]any{"system": "openai"},
+		"retry":  map[string]any{"count": 2, "backoff_s": 1.5},
+		"cached": true,
+	}
+	if !reflect.DeepEqual(md, want) {
+		t.Errorf("md = %v, want %v", md, want)
+	}
+	if got := md.GetString("llm.system"); got != "openai" {
+		t.Errorf("GetString = %q", got)
+	}
+	if got := md.GetInt("retry.count"); got != 2 {
+		t.Errorf("GetInt = %d", got)
+	}
+	if got := md.GetFloat("retry.backoff_s"); got != 1.5 {
+		t.Errorf("GetFloat = %v", got)
+	}
+	if !md.GetBool("cached") || !md.Has("retry") || md.Has("retry.max") || md.Has("cached.x") {
+		t.Error("GetBool or Has wrong")
+	}
+	if got := md.GetMap("retry"); len(got) != 2 {
+		t.Errorf("GetMap = %v", got)
+	}
+}
+
+func TestGetWrongType(t *testing.T) {
+	md := New().SetString("a.b", "x").SetFloat("n", 1.5)
+	if md.GetInt("a.b") != 0 || md.GetString("n") != "" || md.GetBool("a") || md.GetMap("n") != nil {
+		t.Error("getters returned values of the wrong type")
+	}
+	if md.GetInt("n") != 0 {
+		t.Errorf("GetInt(1.5) = %d, want 0", md.GetInt("n"))
+	}
+	if md.GetString("a.b.c") != "" || md.GetString("missing.key") != "" {
+		t.Error("getters returned values below a leaf or a missing key")
+	}
+}
+
+func TestGetDecodedJSON(t *testing.T) {
+	var md Metadata
+	if err := json.Unmarshal([]byte(`{"retry": {"count": 3, "ratio": 0.25}}`), &md); err != nil {
+		t.Fatal(err)
+	}
+	if md.GetInt("retry.count") != 3 || md.GetFloat("retry.count") != 3 || md.GetFloat("retry.ratio") != 0.25 {
+		t.Errorf("md = %v", md)
+	}
+}
+
+func TestSetReplacesLeaf(t *testing.T) {
+	md := New().SetString("llm", "openai").SetString("llm.system", "openai")
+	if md.GetString("llm.system") != "openai" {
+		t.Errorf("md = %v", md)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	md := New().SetInt("retry.count", 1).SetInt("retry.max", 3)
+	md.Delete("retry.count").Delete("missing.key").Delete("retry.max.x")
+	if md.Has("retry.count") || !md.Has("retry.max") {
+		t.Errorf("md = %v", md)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	defaults := map[string]any{
+		"service": "api",
+		"llm":     map[string]any{"system": "openai", "model": "gpt-4o"},
+		"tags":    []string{"a"},
+	}
+	call := map[string]any{
+		"llm":  map[string]any{"model": "gpt-4o-mini"},
+		"tags": []string{"b"},
+		"step": 1,
+	}
+
+	merged := Merge(defaults, call)
+	want := Metadata{
+		"service": "api",
+		"llm":     map[string]any{"system": "openai", "model": "gpt-4o-mini"},
+		"tags":    []string{"b"},
+		"step":    1,
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge = %v, want %v", merged, want)
+	}
+
+	merged.SetString("llm.system", "anthropic")
+	if defaults["llm"].(map[string]any)["system"] != "openai" {
+		t.Error("changing the merged metadata changed an input")
+	}
+	if len(call["llm"].(map[string]any)) != 1 {
+		t.Error("Merge changed an input")
+	}
+}
+
+func TestMergeInto(t *testing.T) {
+	src := map[string]any{"llm": map[string]any{"model": "gpt-4o"}}
+	md := New().SetString("llm.system", "openai").Merge(src)
+	if md.GetString("llm.system") != "openai" || md.GetString("llm.model") != "gpt-4o" {
+		t.Errorf("md = %v", md)
+	}
+	md.SetString("llm.model", "o3")
+	if src["llm"].(map[string]any)["model"] != "gpt-4o" {
+		t.Error("changing md changed the merged map")
+	}
+}
+
+func TestClone(t *testing.T) {
+	md := New().SetString("llm.system", "openai")
+	c := md.Clone()
+	c.SetString("llm.system", "anthropic")
+	if md.GetString("llm.system") != "openai" {
+		t.Error("changing the clone changed the original")
+	}
+	if Metadata(nil).Clone() != nil {
+		t.Error("Clone of nil is not nil")
+	}
+}
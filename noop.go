@@ -0,0 +1,49 @@
+package opik
+
+import "context"
+
+// Tracer starts traces. *Client implements it, and so does the tracer
+// returned by NoopTracer, so instrumented code can take a Tracer and run
+// unchanged whether tracing is enabled or not.
+type Tracer interface {
+	Trace(ctx context.Context, name string, opts ...TraceOption) (*Trace, error)
+}
+
+// noopTrace and noopSpan are the inert handles returned by the no-op tracer.
+// They are shared, so their methods must not modify them.
+var (
+	noopTrace = &Trace{noop: true}
+	noopSpan  = &Span{noop: true}
+)
+
+type noopTracer struct{}
+
+// NoopTracer returns a Tracer whose traces are inert: creating traces and
+// spans always succeeds without allocating or contacting Opik, and ending,
+// updating, or scoring them does nothing. Their IDs are empty.
+func NoopTracer() Tracer {
+	return noopTracer{}
+}
+
+func (noopTracer) Trace(context.Context, string, ...TraceOption) (*Trace, error) {
+	return noopTrace, nil
+}
+
+// Tracer returns the client, or NoopTracer if tracing is disabled. Both
+// return inert traces when tracing is disabled.
+func (c *Client) Tracer() Tracer {
+	if c.config.TracingDisabled {
+		return NoopTracer()
+	}
+	return c
+}
+
+// IsNoop reports whether the trace is an inert trace from NoopTracer.
+func (t *Trace) IsNoop() bool {
+	return t.noop
+}
+
+// IsNoop reports whether the span belongs to an inert trace from NoopTracer.
+func (s *Span) IsNoop() bool {
+	return s.noop
+}
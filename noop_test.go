@@ -0,0 +1,100 @@
+package opik
+
+import (
+	"context"
+	"testing"
+)
+
+// instrumented is code written against Tracer, which must behave the same
+// whether tracing is enabled or not.
+func instrumented(ctx context.Context, tracer Tracer) error {
+	trace, err := tracer.Trace(ctx, "request", WithTraceInput("question"))
+	if err != nil {
+		return err
+	}
+	span, err := trace.Span(ctx, "llm", WithSpanType(SpanTypeLLM))
+	if err != nil {
+		return err
+	}
+	child, err := span.Span(ctx, "tool")
+	if err != nil {
+		return err
+	}
+	span.SetUsage(map[string]int{"prompt_tokens": 10})
+	span.SetCost(0.01)
+	span.SetReasoning("thought about it")
+	if err := child.End(ctx); err != nil {
+		return err
+	}
+	if err := span.Update(ctx, WithSpanMetadata(map[string]any{"k": "v"})); err != nil {
+		return err
+	}
+	if err := span.AddFeedbackScore(ctx, "quality", 1, ""); err != nil {
+		return err
+	}
+	if err := span.End(ctx, WithSpanOutput("answer")); err != nil {
+		return err
+	}
+	if err := trace.AddFeedbackScore(ctx, "quality", 1, ""); err != nil {
+		return err
+	}
+	return trace.End(ctx, WithTraceOutput("answer"))
+}
+
+func TestNoopTracer(t *testing.T) {
+	ctx := context.Background()
+	if err := instrumented(ctx, NoopTracer()); err != nil {
+		t.Fatalf("instrumented code failed with the no-op tracer: %v", err)
+	}
+
+	trace, _ := NoopTracer().Trace(ctx, "request")
+	span, _ := trace.Span(ctx, "llm")
+	if !trace.IsNoop() || !span.IsNoop() || trace.ID() != "" || span.ID() != "" {
+		t.Errorf("trace = %+v, span = %+v", trace, span)
+	}
+	if span.Usage() != nil {
+		t.Error("the shared no-op span must not record usage")
+	}
+}
+
+func TestNoopTracerAllocations(t *testing.T) {
+	ctx := context.Background()
+	tracer := NoopTracer()
+	allocs := testing.AllocsPerRun(100, func() {
+		trace, _ := tracer.Trace(ctx, "request")
+		span, _ := trace.Span(ctx, "llm")
+		span.SetCost(0.01)
+		_ = span.End(ctx)
+		_ = trace.End(ctx)
+	})
+	if allocs != 0 {
+		t.Errorf("no-op tracing allocated %.0f times per run, want 0", allocs)
+	}
+}
+
+func TestClientTracer(t *testing.T) {
+	enabled, err := NewClient(WithURL("http://localhost:5173/api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enabled.Tracer() != Tracer(enabled) {
+		t.Error("Tracer() should return the client when tracing is enabled")
+	}
+
+	disabled, err := NewClient(WithURL("http://localhost:5173/api"), WithTracingDisabled(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := instrumented(context.Background(), disabled.Tracer()); err != nil {
+		t.Errorf("instrumented code failed with tracing disabled: %v", err)
+	}
+
+	ctx, trace, err := StartTrace(context.Background(), disabled, "request")
+	if err != nil || !trace.IsNoop() {
+		t.Fatalf("StartTrace = %+v, %v; want an inert trace", trace, err)
+	}
+	_, span, err := StartSpan(ctx, "step")
+	if err != nil || !span.IsNoop() {
+		t.Errorf("StartSpan = %+v, %v; want an inert span", span, err)
+	}
+}
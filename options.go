@@ -3,6 +3,10 @@ package opik
 import (
 	"net/http"
 	"time"
+
+	"github.com/plexusone/opik-go/cost"
+	"github.com/plexusone/opik-go/evaluation"
+	"github.com/plexusone/opik-go/metadata"
 )
 
 // Option is a functional option for configuring the Client.
@@ -13,12 +17,31 @@ type clientOptions struct {
 	config     *Config
 	httpClient *http.Client
 	timeout    time.Duration
+
+	indexedMetadata   []string
+	capabilityCheck   bool
+	captureReasoning  bool
+	auditLog          *auditLog
+	rateLimitCallback RateLimitCallback
+	projectRoutes     []ProjectRoute
+	projectRoutesSet  int
+	canaryProject     string
+	batching          *BatcherConfig
+	retryPolicy       RetryPolicy
+	redactors         []RedactFunc
+	sampler           Sampler
+	promptCacheTTL    time.Duration
+	costTable         cost.Table
+
+	// problems are invalid option values, reported together by NewClient.
+	problems evaluation.Problems
 }
 
 func defaultClientOptions() *clientOptions {
 	return &clientOptions{
-		config:  LoadConfig(),
-		timeout: 60 * time.Second,
+		config:          LoadConfig(),
+		timeout:         60 * time.Second,
+		capabilityCheck: true,
 	}
 }
 
@@ -53,6 +76,10 @@ func WithProjectName(projectName string) Option {
 // WithConfig sets the entire configuration.
 func WithConfig(config *Config) Option {
 	return func(o *clientOptions) {
+		if config == nil {
+			o.problems.Addf("WithConfig: config is nil")
+			return
+		}
 		o.config = config
 	}
 }
@@ -64,7 +91,7 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
-// WithTimeout sets the request timeout.
+// WithTimeout sets the request timeout. Zero means no timeout.
 func WithTimeout(timeout time.Duration) Option {
 	return func(o *clientOptions) {
 		o.timeout = timeout
@@ -78,6 +105,24 @@ func WithTracingDisabled(disabled bool) Option {
 	}
 }
 
+// WithCapabilityCheck enables or disables checking the server version before
+// using features that older servers do not support. It is enabled by default.
+func WithCapabilityCheck(enabled bool) Option {
+	return func(o *clientOptions) {
+		o.capabilityCheck = enabled
+	}
+}
+
+// WithReasoningCapture enables recording reasoning summaries set with
+// Span.SetReasoning. Summaries can contain sensitive details of the model's
+// reasoning, so they are redacted by default; reasoning token counts are
+// always recorded.
+func WithReasoningCapture(enabled bool) Option {
+	return func(o *clientOptions) {
+		o.captureReasoning = enabled
+	}
+}
+
 // TraceOption is a functional option for configuring a Trace.
 type TraceOption func(*traceOptions)
 
@@ -88,12 +133,17 @@ type traceOptions struct {
 	metadata    map[string]any
 	tags        []string
 	threadID    string
+	sla         time.Duration
+	redact      RedactFunc
+	err         error
+	sampled     bool
 }
 
+// defaultTraceOptions leaves metadata nil until an option sets it, so traces
+// without metadata don't allocate a map.
 func defaultTraceOptions() *traceOptions {
 	return &traceOptions{
-		metadata: make(map[string]any),
-		tags:     []string{},
+		tags: []string{},
 	}
 }
 
@@ -118,10 +168,18 @@ func WithTraceOutput(output any) TraceOption {
 	}
 }
 
-// WithTraceMetadata sets the metadata for the trace.
-func WithTraceMetadata(metadata map[string]any) TraceOption {
+// WithTraceMetadata adds metadata to the trace. Metadata from several
+// options is merged as metadata.Merge does, so nested objects are combined
+// key by key.
+func WithTraceMetadata(md map[string]any) TraceOption {
 	return func(o *traceOptions) {
-		o.metadata = metadata
+		switch {
+		case len(md) == 0:
+		case o.metadata == nil:
+			o.metadata = md
+		default:
+			o.metadata = metadata.Merge(o.metadata, md)
+		}
 	}
 }
 
@@ -139,6 +197,24 @@ func WithTraceThreadID(threadID string) TraceOption {
 	}
 }
 
+// WithTraceRedactor sets a function applied to the trace's input and output
+// before they are sent to Opik. It is typically set once for all traces with
+// Client.SetDefaultTraceOptions.
+func WithTraceRedactor(fn RedactFunc) TraceOption {
+	return func(o *traceOptions) {
+		o.redact = fn
+	}
+}
+
+// WithTraceError records that the trace failed with err. The error's type
+// and message are shown on the trace in Opik, and a trace held by a
+// SamplingKeepOnError decision is sent. A nil err records nothing.
+func WithTraceError(err error) TraceOption {
+	return func(o *traceOptions) {
+		o.err = err
+	}
+}
+
 // SpanOption is a functional option for configuring a Span.
 type SpanOption func(*spanOptions)
 
@@ -150,12 +226,15 @@ type spanOptions struct {
 	tags     []string
 	model    string
 	provider string
+	budget   time.Duration
+	redact   RedactFunc
+	err      error
 }
 
+// defaultSpanOptions leaves metadata nil, as defaultTraceOptions does.
 func defaultSpanOptions() *spanOptions {
 	return &spanOptions{
 		spanType: "general",
-		metadata: make(map[string]any),
 		tags:     []string{},
 	}
 }
@@ -181,10 +260,18 @@ func WithSpanOutput(output any) SpanOption {
 	}
 }
 
-// WithSpanMetadata sets the metadata for the span.
-func WithSpanMetadata(metadata map[string]any) SpanOption {
+// WithSpanMetadata adds metadata to the span. Metadata from several
+// options is merged as metadata.Merge does, so nested objects are combined
+// key by key.
+func WithSpanMetadata(md map[string]any) SpanOption {
 	return func(o *spanOptions) {
-		o.metadata = metadata
+		switch {
+		case len(md) == 0:
+		case o.metadata == nil:
+			o.metadata = md
+		default:
+			o.metadata = metadata.Merge(o.metadata, md)
+		}
 	}
 }
 
@@ -209,6 +296,23 @@ func WithSpanProvider(provider string) SpanOption {
 	}
 }
 
+// WithSpanRedactor sets a function applied to the span's input and output
+// before they are sent to Opik. It is typically set once for all spans with
+// Client.SetDefaultSpanOptions.
+func WithSpanRedactor(fn RedactFunc) SpanOption {
+	return func(o *spanOptions) {
+		o.redact = fn
+	}
+}
+
+// WithSpanError records that the span failed with err, as WithTraceError
+// does for a trace.
+func WithSpanError(err error) SpanOption {
+	return func(o *spanOptions) {
+		o.err = err
+	}
+}
+
 // SpanTypeLLM is the span type for LLM calls.
 const SpanTypeLLM = "llm"
 
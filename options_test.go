@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"testing"
 	"time"
+
+	"github.com/plexusone/opik-go/metadata"
 )
 
 func TestDefaultClientOptions(t *testing.T) {
@@ -137,8 +139,8 @@ func TestMultipleClientOptions(t *testing.T) {
 func TestDefaultTraceOptions(t *testing.T) {
 	opts := defaultTraceOptions()
 
-	if opts.metadata == nil {
-		t.Error("metadata should not be nil")
+	if opts.metadata != nil {
+		t.Error("metadata should be nil until an option sets it")
 	}
 	if opts.tags == nil {
 		t.Error("tags should not be nil")
@@ -219,8 +221,8 @@ func TestDefaultSpanOptions(t *testing.T) {
 	if opts.spanType != "general" {
 		t.Errorf("spanType = %q, want %q", opts.spanType, "general")
 	}
-	if opts.metadata == nil {
-		t.Error("metadata should not be nil")
+	if opts.metadata != nil {
+		t.Error("metadata should be nil until an option sets it")
 	}
 	if opts.tags == nil {
 		t.Error("tags should not be nil")
@@ -279,6 +281,21 @@ func TestWithSpanMetadata(t *testing.T) {
 	}
 }
 
+func TestWithSpanMetadataMerges(t *testing.T) {
+	opts := defaultSpanOptions()
+	first := metadata.New().SetString("llm.system", "openai").SetInt("retry.count", 1)
+	WithSpanMetadata(first)(opts)
+	WithSpanMetadata(map[string]any{"llm": map[string]any{"model": "gpt-4o"}, "retry": 2})(opts)
+
+	md := metadata.Metadata(opts.metadata)
+	if md.GetString("llm.system") != "openai" || md.GetString("llm.model") != "gpt-4o" || md.GetInt("retry") != 2 {
+		t.Errorf("metadata = %v", opts.metadata)
+	}
+	if first.GetInt("retry.count") != 1 || first.Has("llm.model") {
+		t.Errorf("first option's map changed: %v", first)
+	}
+}
+
 func TestWithSpanTags(t *testing.T) {
 	opts := defaultSpanOptions()
 	WithSpanTags("production", "v1")(opts)
@@ -0,0 +1,126 @@
+package opik
+
+import (
+	"math/rand/v2"
+	"slices"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// ProjectRoute is one destination for traces routed by WithProjectRoutes.
+type ProjectRoute struct {
+	// Project receives the routed traces. Empty means the client's default
+	// project.
+	Project string
+	// Weight is the route's share of traces relative to the other routes.
+	Weight float64
+	// TraceOptions are applied to routed traces before the options passed
+	// to Trace, for example a different redactor for a canary project.
+	TraceOptions []TraceOption
+	// SpanOptions are applied to every span of a routed trace before the
+	// options passed to Span.
+	SpanOptions []SpanOption
+}
+
+// WithProjectRoutes sends each new trace to one of routes, picked at random
+// in proportion to the route weights. Use it to try instrumentation changes
+// or redaction policies on a share of production traffic before rolling
+// them out. Traces given an explicit WithTraceProject are not routed, and
+// spans always go to the project of their trace.
+//
+// NewClient returns ErrInvalidInput if a weight is negative or all weights
+// are zero.
+func WithProjectRoutes(routes ...ProjectRoute) Option {
+	return func(o *clientOptions) {
+		o.projectRoutes = routes
+		o.projectRoutesSet++
+		o.canaryProject = ""
+	}
+}
+
+// WithCanaryProject sends fraction of new traces, between 0 and 1, to the
+// canary project and the rest to the client's default project. It is
+// shorthand for WithProjectRoutes with two routes; use WithProjectRoutes to
+// also apply options, such as a redactor, to the canary traces only.
+//
+// NewClient returns ErrInvalidInput if project is empty or the default
+// project, or fraction is outside [0, 1].
+func WithCanaryProject(project string, fraction float64) Option {
+	return func(o *clientOptions) {
+		if project == "" {
+			o.problems.Addf("canary project name must not be empty")
+		}
+		if fraction < 0 || fraction > 1 {
+			o.problems.Addf("canary fraction must be between 0 and 1: %v", fraction)
+			fraction = min(max(fraction, 0), 1)
+		}
+		WithProjectRoutes(
+			ProjectRoute{Weight: 1 - fraction},
+			ProjectRoute{Project: project, Weight: fraction},
+		)(o)
+		o.canaryProject = project
+	}
+}
+
+// projectRouter picks the route of each new trace. A nil projectRouter
+// routes every trace to the default project.
+type projectRouter struct {
+	routes []ProjectRoute
+	total  float64
+	rand   func() float64
+}
+
+// newProjectRouter returns a router for routes, which must have passed
+// projectRouteProblems, or nil if there are none.
+func newProjectRouter(routes []ProjectRoute) *projectRouter {
+	if len(routes) == 0 {
+		return nil
+	}
+	total := 0.0
+	for _, route := range routes {
+		total += route.Weight
+	}
+	return &projectRouter{routes: slices.Clone(routes), total: total, rand: rand.Float64}
+}
+
+// projectRouteProblems returns an error for each negative weight, and one if
+// the routes have no weight at all.
+func projectRouteProblems(routes []ProjectRoute) []error {
+	if len(routes) == 0 {
+		return nil
+	}
+	var p evaluation.Problems
+	total := 0.0
+	for _, route := range routes {
+		if route.Weight < 0 {
+			p.Addf("project route %q has negative weight %v", route.Project, route.Weight)
+			continue
+		}
+		total += route.Weight
+	}
+	if total == 0 && len(p) == 0 {
+		p.Addf("project routes have no weight")
+	}
+	return p
+}
+
+// pick returns a route chosen in proportion to the route weights.
+func (r *projectRouter) pick() *ProjectRoute {
+	if r == nil {
+		return nil
+	}
+	n := r.rand() * r.total
+	for i := range r.routes {
+		if n < r.routes[i].Weight {
+			return &r.routes[i]
+		}
+		n -= r.routes[i].Weight
+	}
+	// Rounding can leave n just past the last route with a weight.
+	for i := len(r.routes) - 1; i >= 0; i-- {
+		if r.routes[i].Weight > 0 {
+			return &r.routes[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestProjectRouting(t *testing.T) {
+	ts, created := newCreateServer()
+	defer ts.Close()
+
+	mask := func(any) any { return "[masked]" }
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithProjectName("main"), WithProjectRoutes(
+		ProjectRoute{Weight: 3},
+		ProjectRoute{
+			Project:      "canary",
+			Weight:       1,
+			TraceOptions: []TraceOption{WithTraceRedactor(mask), WithTraceTags("canary")},
+			SpanOptions:  []SpanOption{WithSpanRedactor(mask)},
+		},
+	))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	trace := func(draw float64, opts ...TraceOption) (trace createdEntity, span createdEntity) {
+		t.Helper()
+		client.projectRouter.rand = func() float64 { return draw }
+		tr, err := client.Trace(ctx, "t", append(opts, WithTraceInput("secret"))...)
+		if err != nil {
+			t.Fatalf("Trace error: %v", err)
+		}
+		parent, err := tr.Span(ctx, "parent")
+		if err != nil {
+			t.Fatalf("Span error: %v", err)
+		}
+		if _, err := parent.Span(ctx, "child", WithSpanInput("secret")); err != nil {
+			t.Fatalf("Span error: %v", err)
+		}
+		return created("POST /v1/private/traces/batch"), created("POST /v1/private/spans/batch")
+	}
+
+	tr, span := trace(0.5)
+	if tr.ProjectName != "main" || tr.Input != "secret" || len(tr.Tags) != 0 {
+		t.Errorf("default route trace = %+v", tr)
+	}
+	if span.ProjectName != "main" || span.Input != "secret" {
+		t.Errorf("default route span = %+v", span)
+	}
+
+	tr, span = trace(0.8)
+	if tr.ProjectName != "canary" || tr.Input != "[masked]" || len(tr.Tags) != 1 || tr.Tags[0] != "canary" {
+		t.Errorf("canary trace = %+v", tr)
+	}
+	if span.ProjectName != "canary" || span.Input != "[masked]" {
+		t.Errorf("canary child span = %+v", span)
+	}
+
+	tr, span = trace(0.8, WithTraceProject("pinned"))
+	if tr.ProjectName != "pinned" || tr.Input != "secret" {
+		t.Errorf("explicit project trace = %+v", tr)
+	}
+	if span.ProjectName != "pinned" {
+		t.Errorf("explicit project span = %+v", span)
+	}
+}
+
+func TestProjectRouterPick(t *testing.T) {
+	router := newProjectRouter([]ProjectRoute{{Project: "a", Weight: 1}, {Project: "skip"}, {Project: "b", Weight: 1}})
+	for draw, want := range map[float64]string{0: "a", 0.49: "a", 0.5: "b", 0.99: "b", 1: "b"} {
+		router.rand = func() float64 { return draw }
+		if got := router.pick().Project; got != want {
+			t.Errorf("pick at %v = %q, want %q", draw, got, want)
+		}
+	}
+
+	var none *projectRouter
+	if none.pick() != nil {
+		t.Error("nil router picked a route")
+	}
+}
+
+func TestCanaryProjectFraction(t *testing.T) {
+	options := &clientOptions{}
+	WithCanaryProject("canary", 0.1)(options)
+	router := newProjectRouter(options.projectRoutes)
+	canary := 0
+	for range 10000 {
+		if router.pick().Project == "canary" {
+			canary++
+		}
+	}
+	if canary < 800 || canary > 1200 {
+		t.Errorf("canary got %d of 10000 traces, want about 1000", canary)
+	}
+}
+
+func TestProjectRoutesInvalid(t *testing.T) {
+	for name, routes := range map[string][]ProjectRoute{
+		"negative": {{Project: "a", Weight: 1}, {Project: "b", Weight: -1}},
+		"zero":     {{Project: "a"}, {Project: "b"}},
+	} {
+		_, err := NewClient(WithURL("http://localhost"), WithAPIKey("k"), WithProjectRoutes(routes...))
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: NewClient error = %v, want ErrInvalidInput", name, err)
+		}
+	}
+}
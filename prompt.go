@@ -3,8 +3,6 @@ package opik
 import (
 	"context"
 	"fmt"
-	"regexp"
-	"strings"
 
 	"github.com/google/uuid"
 
@@ -30,6 +28,10 @@ type PromptVersion struct {
 	template          string
 	changeDescription string
 	tags              []string
+	promptType        PromptType
+	templateStructure PromptTemplateStructure
+	// messages are the parsed messages of a chat template.
+	messages []Message
 }
 
 // PromptTemplateStructure represents the type of prompt template.
@@ -104,6 +106,15 @@ func (v *PromptVersion) Tags() []string {
 	return v.tags
 }
 
+// Type returns the version's prompt type, which selects how its template
+// is rendered.
+func (v *PromptVersion) Type() PromptType {
+	if v.promptType == "" {
+		return PromptTypeMustache
+	}
+	return v.promptType
+}
+
 // PromptOption is a functional option for configuring a Prompt.
 type PromptOption func(*promptOptions)
 
@@ -186,6 +197,7 @@ func (c *Client) CreatePrompt(ctx context.Context, name string, opts ...PromptOp
 	}
 
 	resp, err := c.apiClient.CreatePrompt(ctx, api.NewOptPromptWrite(req))
+	c.audit(AuditCreate, AuditEntityPrompt, promptUUID.String(), name, err)
 	if err != nil {
 		return nil, err
 	}
@@ -266,7 +278,7 @@ func (c *Client) GetPromptByName(ctx context.Context, name string, commit string
 			changeDescription = v.ChangeDescription.Value
 		}
 
-		return &PromptVersion{
+		version := &PromptVersion{
 			client:            c,
 			id:                id,
 			promptID:          promptID,
@@ -274,12 +286,31 @@ func (c *Client) GetPromptByName(ctx context.Context, name string, commit string
 			template:          v.Template,
 			changeDescription: changeDescription,
 			tags:              v.Tags,
-		}, nil
+		}
+		if v.Type.Set {
+			version.promptType = PromptType(v.Type.Value)
+		}
+		if v.TemplateStructure.Set {
+			version.setTemplateStructure(PromptTemplateStructure(v.TemplateStructure.Value))
+		}
+		return version, nil
 	default:
 		return nil, ErrPromptNotFound
 	}
 }
 
+// LoadPrompt returns the template and commit of a prompt version, selected
+// by name and commit as with GetPromptByName. It makes the client an
+// llm.PromptLibrary, so LLM judges can load versioned prompts with
+// llm.WithJudgePromptLibrary and llm.WithJudgePromptRef.
+func (c *Client) LoadPrompt(ctx context.Context, name, commit string) (template, loadedCommit string, err error) {
+	version, err := c.GetPromptByName(ctx, name, commit)
+	if err != nil {
+		return "", "", err
+	}
+	return version.Template(), version.Commit(), nil
+}
+
 // ListPrompts lists all prompts.
 //
 //nolint:dupl // Similar structure to ListDatasets is intentional for consistency
@@ -327,7 +358,9 @@ func (c *Client) DeletePrompt(ctx context.Context, promptID string) error {
 		return err
 	}
 
-	return c.apiClient.DeletePrompt(ctx, api.DeletePromptParams{ID: promptUUID})
+	err = c.apiClient.DeletePrompt(ctx, api.DeletePromptParams{ID: promptUUID})
+	c.audit(AuditDelete, AuditEntityPrompt, promptID, "", err)
+	return err
 }
 
 // Delete deletes this prompt.
@@ -373,7 +406,7 @@ func (p *Prompt) GetVersions(ctx context.Context, page, size int) ([]*PromptVers
 			changeDescription = v.ChangeDescription.Value
 		}
 
-		versions = append(versions, &PromptVersion{
+		version := &PromptVersion{
 			client:            p.client,
 			id:                id,
 			promptID:          promptID,
@@ -381,7 +414,14 @@ func (p *Prompt) GetVersions(ctx context.Context, page, size int) ([]*PromptVers
 			template:          v.Template,
 			changeDescription: changeDescription,
 			tags:              v.Tags,
-		})
+		}
+		if v.Type.Set {
+			version.promptType = PromptType(v.Type.Value)
+		}
+		if v.TemplateStructure.Set {
+			version.setTemplateStructure(PromptTemplateStructure(v.TemplateStructure.Value))
+		}
+		versions = append(versions, version)
 	}
 
 	return versions, nil
@@ -394,6 +434,8 @@ type promptVersionOptions struct {
 	changeDescription string
 	promptType        PromptType
 	tags              []string
+	// templateStructure is set by CreateChatVersion.
+	templateStructure PromptTemplateStructure
 }
 
 // WithVersionChangeDescription sets the change description for the version.
@@ -448,11 +490,17 @@ func (p *Prompt) CreateVersion(ctx context.Context, template string, opts ...Pro
 			Tags:              options.tags,
 		},
 	}
+	if options.templateStructure != "" {
+		req.TemplateStructure = api.NewOptCreatePromptVersionDetailTemplateStructure(
+			api.CreatePromptVersionDetailTemplateStructure(options.templateStructure))
+	}
 
 	resp, err := p.client.apiClient.CreatePromptVersion(ctx, api.NewOptCreatePromptVersionDetail(req))
+	p.client.audit(AuditCreate, AuditEntityPromptVersion, versionUUID.String(), p.name, err)
 	if err != nil {
 		return nil, err
 	}
+	p.client.promptCache.forgetLatest(p.name)
 
 	switch v := resp.(type) {
 	case *api.PromptVersionDetail:
@@ -470,7 +518,7 @@ func (p *Prompt) CreateVersion(ctx context.Context, template string, opts ...Pro
 			changeDescription = v.ChangeDescription.Value
 		}
 
-		return &PromptVersion{
+		version := &PromptVersion{
 			client:            p.client,
 			id:                id,
 			promptID:          promptID,
@@ -478,64 +526,79 @@ func (p *Prompt) CreateVersion(ctx context.Context, template string, opts ...Pro
 			template:          v.Template,
 			changeDescription: changeDescription,
 			tags:              v.Tags,
-		}, nil
+			promptType:        options.promptType,
+		}
+		if v.Type.Set {
+			version.promptType = PromptType(v.Type.Value)
+		}
+		structure := options.templateStructure
+		if v.TemplateStructure.Set {
+			structure = PromptTemplateStructure(v.TemplateStructure.Value)
+		}
+		version.setTemplateStructure(structure)
+		return version, nil
 	default:
-		return &PromptVersion{
+		version := &PromptVersion{
 			client:            p.client,
 			id:                versionUUID.String(),
 			promptID:          p.id,
 			template:          template,
 			changeDescription: options.changeDescription,
 			tags:              options.tags,
-		}, nil
+			promptType:        options.promptType,
+		}
+		version.setTemplateStructure(options.templateStructure)
+		return version, nil
 	}
 }
 
-// Render renders the template with the given variables.
-// Supports mustache-style {{variable}} placeholders.
+// Render renders the template with the given variables, with the engine
+// of the version's prompt type: {{variable}} placeholders for mustache,
+// which may have spaces inside the braces as in {{ name }}, {variable}
+// placeholders for fstring, and a subset of Jinja2 with conditionals and
+// loops for jinja2. Templates are parsed as RenderBatch parses them, and
+// values are inserted as they are, without substituting placeholders they
+// contain.
+//
+// Render is lenient: placeholders of missing variables are kept, except in
+// jinja2 templates, where missing variables render as empty as they do in
+// Jinja2, and a template that does not parse is returned unchanged. Use
+// RenderStrict to get errors instead.
 func (v *PromptVersion) Render(variables map[string]string) string {
-	result := v.template
-	for key, value := range variables {
-		// Replace mustache-style placeholders {{key}}
-		placeholder := "{{" + key + "}}"
-		result = strings.ReplaceAll(result, placeholder, value)
+	return v.renderText(v.template, variables)
+}
+
+// renderText renders template, the version's template or the content of
+// one of its messages, leniently with the engine of its prompt type.
+func (v *PromptVersion) renderText(template string, variables map[string]string) string {
+	return v.renderLenient(template, stringVars(variables, nil))
+}
+
+// renderLenient renders template with vars, keeping the placeholders of
+// missing variables, or returns it unchanged if it does not parse.
+func (v *PromptVersion) renderLenient(template string, vars templateVars) string {
+	tmpl, err := parseTemplate(v.Type(), template)
+	if err != nil {
+		return template
 	}
-	return result
+	text, _ := tmpl.execute(vars, false)
+	return text
 }
 
 // RenderWithDefault renders the template with the given variables,
 // using default values for missing variables.
 func (v *PromptVersion) RenderWithDefault(variables map[string]string, defaultValue string) string {
-	result := v.template
-
-	// First replace known variables
-	for key, value := range variables {
-		placeholder := "{{" + key + "}}"
-		result = strings.ReplaceAll(result, placeholder, value)
-	}
-
-	// Then replace any remaining placeholders with the default value
-	re := regexp.MustCompile(`\{\{[^}]+\}\}`)
-	result = re.ReplaceAllString(result, defaultValue)
-
-	return result
+	return v.renderLenient(v.template, stringVars(variables, &renderOptions{defaultSet: true, defaultVal: defaultValue}))
 }
 
-// ExtractVariables returns a list of variable names in the template.
+// ExtractVariables returns a list of variable names in the template. For
+// fstring and jinja2 templates, these are the names of the variables the
+// template reads, without the attributes it reads from them; it returns
+// nil if the template does not parse.
 func (v *PromptVersion) ExtractVariables() []string {
-	re := regexp.MustCompile(`\{\{([^}]+)\}\}`)
-	matches := re.FindAllStringSubmatch(v.template, -1)
-
-	seen := make(map[string]bool)
-	var variables []string
-	for _, match := range matches {
-		if len(match) > 1 {
-			varName := strings.TrimSpace(match[1])
-			if !seen[varName] {
-				seen[varName] = true
-				variables = append(variables, varName)
-			}
-		}
+	tmpl, err := parseTemplate(v.Type(), v.template)
+	if err != nil {
+		return nil
 	}
-	return variables
+	return tmpl.variables()
 }
@@ -0,0 +1,179 @@
+package opik
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultPromptCacheTTL is how long GetPromptByNameCached serves the latest
+// version of a prompt before refreshing it, unless set with
+// WithPromptCacheTTL.
+const DefaultPromptCacheTTL = time.Minute
+
+// promptRefreshTimeout limits each background refresh of a cached prompt
+// version, so a hanging request does not keep the version from being
+// refreshed again.
+const promptRefreshTimeout = 30 * time.Second
+
+// WithPromptCacheTTL sets how long GetPromptByNameCached serves the latest
+// version of a prompt before refreshing it in the background. A shorter
+// TTL picks up new versions sooner at the cost of more requests. The TTL
+// must be positive; it defaults to DefaultPromptCacheTTL.
+func WithPromptCacheTTL(ttl time.Duration) Option {
+	return func(o *clientOptions) {
+		if ttl <= 0 {
+			o.problems.Addf("prompt cache TTL must be positive: %v", ttl)
+		}
+		o.promptCacheTTL = ttl
+	}
+}
+
+// promptCache holds the prompt versions fetched by GetPromptByNameCached.
+type promptCache struct {
+	ttl            time.Duration
+	refreshTimeout time.Duration
+
+	mu      sync.Mutex
+	entries map[promptCacheKey]*promptCacheEntry
+
+	// ctx is cancelled by Client.Close to stop background refreshes, and
+	// closed is set so no more are started.
+	ctx    context.Context
+	cancel context.CancelFunc
+	closed bool
+
+	// refreshes tracks background refreshes, so Close can wait for them.
+	refreshes sync.WaitGroup
+}
+
+type promptCacheKey struct {
+	name   string
+	commit string
+}
+
+type promptCacheEntry struct {
+	version *PromptVersion
+	// expires is when the version is next refreshed.
+	expires    time.Time
+	refreshing bool
+}
+
+// GetPromptByNameCached returns a prompt version as GetPromptByName does,
+// from an in-process cache, so hot request paths do not make a request to
+// Opik for every call. Only the first call for a name and commit waits for
+// the server.
+//
+// A version pinned by commit never changes and is cached for the lifetime
+// of the client. The latest version, with an empty commit, is served for
+// the TTL set with WithPromptCacheTTL; a call after that still returns the
+// cached version at once and refreshes it in the background, so a new
+// version is picked up on a later call. If the refresh fails, the cached
+// version is kept and the refresh is retried after another TTL, unless the
+// prompt no longer exists. Prompt.CreateVersion clears the cached latest
+// version of its prompt on this client.
+//
+// Background refreshes outlive the call that started them, within a
+// timeout, and are cancelled by Client.Close. After Close, an expired
+// version is refreshed before the call returns.
+//
+// The returned version is shared between callers and must not be modified.
+func (c *Client) GetPromptByNameCached(ctx context.Context, name, commit string) (*PromptVersion, error) {
+	key := promptCacheKey{name: name, commit: commit}
+	cache := &c.promptCache
+
+	cache.mu.Lock()
+	if entry, ok := cache.entries[key]; ok {
+		version := entry.version
+		if commit != "" || entry.refreshing || time.Now().Before(entry.expires) {
+			cache.mu.Unlock()
+			return version, nil
+		}
+		entry.refreshing = true
+		if cache.closed {
+			cache.mu.Unlock()
+			return c.refreshPrompt(ctx, key, entry)
+		}
+		cache.startRefresh(ctx, func(ctx context.Context) {
+			_, _ = c.refreshPrompt(ctx, key, entry)
+		})
+		cache.mu.Unlock()
+		return version, nil
+	}
+	cache.mu.Unlock()
+
+	version, err := c.GetPromptByName(ctx, name, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.entries == nil {
+		cache.entries = make(map[promptCacheKey]*promptCacheEntry)
+	}
+	cache.entries[key] = &promptCacheEntry{version: version, expires: time.Now().Add(cache.ttl)}
+	return version, nil
+}
+
+// startRefresh runs refresh in the background with the values of ctx,
+// until the refresh timeout or Close. It must be called with p.mu held.
+func (p *promptCache) startRefresh(ctx context.Context, refresh func(context.Context)) {
+	if p.ctx == nil {
+		p.ctx, p.cancel = context.WithCancel(context.Background())
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cmp.Or(p.refreshTimeout, promptRefreshTimeout))
+	stop := context.AfterFunc(p.ctx, cancel)
+	p.refreshes.Add(1)
+	go func() {
+		defer p.refreshes.Done()
+		defer cancel()
+		defer stop()
+		refresh(ctx)
+	}()
+}
+
+// refreshPrompt fetches the latest version of a cached prompt and replaces
+// the version of entry with it. It returns the version now cached, which
+// is the previous one if the fetch failed, or an error wrapping
+// ErrPromptNotFound if the prompt no longer exists.
+func (c *Client) refreshPrompt(ctx context.Context, key promptCacheKey, entry *promptCacheEntry) (*PromptVersion, error) {
+	cache := &c.promptCache
+	version, err := c.GetPromptByName(ctx, key.name, key.commit)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry.refreshing = false
+	entry.expires = time.Now().Add(cache.ttl)
+	switch {
+	case errors.Is(err, ErrPromptNotFound):
+		if cache.entries[key] == entry {
+			delete(cache.entries, key)
+		}
+		return nil, err
+	case err == nil:
+		entry.version = version
+	}
+	return entry.version, nil
+}
+
+// close cancels the background refreshes and waits for them to return.
+// Expired versions are then refreshed by the calls that find them.
+func (p *promptCache) close() {
+	p.mu.Lock()
+	p.closed = true
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.mu.Unlock()
+	p.refreshes.Wait()
+}
+
+// forgetLatest removes the cached latest version of the named prompt.
+func (p *promptCache) forgetLatest(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.entries, promptCacheKey{name: name})
+}
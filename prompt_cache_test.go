@@ -0,0 +1,264 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+// promptCacheServer serves the latest version of prompts, with a new
+// commit for each request. While stalled, it holds requests until the
+// client gives up on them.
+type promptCacheServer struct {
+	*testutil.MockServer
+
+	mu      sync.Mutex
+	served  map[string]int
+	status  int
+	stalled bool
+}
+
+// promptRequest is the body of a request for a prompt version.
+type promptRequest struct {
+	Name   string `json:"name"`
+	Commit string `json:"commit"`
+}
+
+const promptRetrievePath = "/v1/private/prompts/versions/retrieve"
+
+func newPromptCacheServer(t *testing.T) *promptCacheServer {
+	s := &promptCacheServer{MockServer: testutil.NewMockServer(), served: make(map[string]int)}
+	s.OnPost(promptRetrievePath).WithHandler(func(w http.ResponseWriter, r *http.Request) {
+		var req promptRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.mu.Lock()
+		status := s.status
+		s.served[req.Name+"@"+req.Commit]++
+		n := s.served[req.Name+"@"+req.Commit]
+		stalled := s.stalled
+		s.mu.Unlock()
+		if stalled {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if req.Name == "missing" {
+			status = http.StatusNotFound
+		}
+		if status != 0 {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"errors": ["unavailable"]}`))
+			return
+		}
+		commit := req.Commit
+		if commit == "" {
+			commit = fmt.Sprintf("commit%02d", n)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id": uuid.NewString(), "commit": commit, "template": "Hi {{name}}",
+		})
+	})
+	s.OnPost("/v1/private/prompts/versions").WithHandler(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(req["version"])
+	})
+	t.Cleanup(s.Close)
+	return s
+}
+
+// count returns the number of requests for key, a prompt name and commit
+// joined by "@".
+func (s *promptCacheServer) count(key string) int {
+	n := 0
+	for _, r := range s.RequestsFor(http.MethodPost, promptRetrievePath) {
+		var req promptRequest
+		if r.DecodeJSON(&req) == nil && req.Name+"@"+req.Commit == key {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *promptCacheServer) setStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *promptCacheServer) setStalled(stalled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stalled = stalled
+}
+
+func TestGetPromptByNameCached(t *testing.T) {
+	server := newPromptCacheServer(t)
+	client, err := NewClient(WithURL(server.URL()), WithAPIKey("test-key"), WithPromptCacheTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	for range 3 {
+		v, err := client.GetPromptByNameCached(ctx, "greeting", "")
+		if err != nil || v.Commit() != "commit01" {
+			t.Fatalf("GetPromptByNameCached = %v, %v, want commit01", v, err)
+		}
+		v, err = client.GetPromptByNameCached(ctx, "greeting", "pinned01")
+		if err != nil || v.Commit() != "pinned01" {
+			t.Fatalf("GetPromptByNameCached pinned = %v, %v, want pinned01", v, err)
+		}
+	}
+	client.promptCache.refreshes.Wait()
+	if n := server.count("greeting@"); n != 1 {
+		t.Errorf("latest fetched %d times, want 1", n)
+	}
+	if n := server.count("greeting@pinned01"); n != 1 {
+		t.Errorf("pinned fetched %d times, want 1", n)
+	}
+
+	if _, err := client.GetPromptByNameCached(ctx, "missing", ""); !errors.Is(err, ErrPromptNotFound) {
+		t.Errorf("GetPromptByNameCached of a missing prompt error = %v, want ErrPromptNotFound", err)
+	}
+}
+
+func TestGetPromptByNameCachedRefresh(t *testing.T) {
+	server := newPromptCacheServer(t)
+	client, err := NewClient(WithURL(server.URL()), WithAPIKey("test-key"), WithPromptCacheTTL(time.Nanosecond))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	get := func() string {
+		t.Helper()
+		v, err := client.GetPromptByNameCached(ctx, "greeting", "")
+		if err != nil {
+			t.Fatalf("GetPromptByNameCached error: %v", err)
+		}
+		client.promptCache.refreshes.Wait()
+		return v.Commit()
+	}
+
+	// An expired version is returned at once and refreshed for the next call.
+	if got := get(); got != "commit01" {
+		t.Errorf("first call = %q, want commit01", got)
+	}
+	if got := get(); got != "commit01" {
+		t.Errorf("expired call = %q, want commit01 while refreshing", got)
+	}
+	if got := get(); got != "commit02" {
+		t.Errorf("call after refresh = %q, want commit02", got)
+	}
+
+	// A failed refresh keeps the cached version.
+	server.setStatus(http.StatusInternalServerError)
+	if got := get(); got != "commit03" {
+		t.Errorf("call with a failing server = %q, want commit03", got)
+	}
+	if got := get(); got != "commit03" {
+		t.Errorf("call after a failed refresh = %q, want commit03", got)
+	}
+
+	// A prompt that no longer exists is dropped.
+	server.setStatus(http.StatusNotFound)
+	get()
+	if _, err := client.GetPromptByNameCached(ctx, "greeting", ""); !errors.Is(err, ErrPromptNotFound) {
+		t.Errorf("GetPromptByNameCached after deletion error = %v, want ErrPromptNotFound", err)
+	}
+}
+
+func TestPromptCacheRefreshLifecycle(t *testing.T) {
+	server := newPromptCacheServer(t)
+	client, err := NewClient(WithURL(server.URL()), WithAPIKey("test-key"), WithPromptCacheTTL(time.Nanosecond))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	get := func() string {
+		t.Helper()
+		v, err := client.GetPromptByNameCached(ctx, "greeting", "")
+		if err != nil {
+			t.Fatalf("GetPromptByNameCached error: %v", err)
+		}
+		return v.Commit()
+	}
+	if got := get(); got != "commit01" {
+		t.Fatalf("first call = %q, want commit01", got)
+	}
+	server.setStalled(true)
+
+	// A refresh that hangs gives up at its timeout and keeps the version.
+	client.promptCache.refreshTimeout = 50 * time.Millisecond
+	get()
+	client.promptCache.refreshes.Wait()
+
+	// Close cancels the refresh in flight and waits for it.
+	client.promptCache.refreshTimeout = time.Hour
+	if got := get(); got != "commit01" {
+		t.Errorf("call after a timed-out refresh = %q, want commit01", got)
+	}
+	for server.count("greeting@") < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	start := time.Now()
+	if err := client.Close(ctx); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Close took %v, want the refresh cancelled", elapsed)
+	}
+
+	// After Close, an expired version is refreshed by the call.
+	server.setStalled(false)
+	if got := get(); got != "commit04" {
+		t.Errorf("call after Close = %q, want commit04", got)
+	}
+	if n := server.count("greeting@"); n != 4 {
+		t.Errorf("latest fetched %d times, want 4", n)
+	}
+}
+
+func TestCreateVersionClearsPromptCache(t *testing.T) {
+	server := newPromptCacheServer(t)
+	client, err := NewClient(WithURL(server.URL()), WithAPIKey("test-key"), WithPromptCacheTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.GetPromptByNameCached(ctx, "greeting", ""); err != nil {
+		t.Fatalf("GetPromptByNameCached error: %v", err)
+	}
+	prompt := &Prompt{client: client, id: uuid.NewString(), name: "greeting"}
+	if _, err := prompt.CreateVersion(ctx, "Hello {{name}}"); err != nil {
+		t.Fatalf("CreateVersion error: %v", err)
+	}
+	v, err := client.GetPromptByNameCached(ctx, "greeting", "")
+	if err != nil || v.Commit() != "commit02" {
+		t.Errorf("GetPromptByNameCached after CreateVersion = %v, %v, want commit02", v, err)
+	}
+}
+
+func TestWithPromptCacheTTLValidation(t *testing.T) {
+	if _, err := NewClient(WithURL("http://localhost"), WithPromptCacheTTL(0)); err == nil {
+		t.Error("NewClient with a zero prompt cache TTL succeeded")
+	}
+	client, err := NewClient(WithURL("http://localhost"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if client.promptCache.ttl != DefaultPromptCacheTTL {
+		t.Errorf("prompt cache TTL = %v, want %v", client.promptCache.ttl, DefaultPromptCacheTTL)
+	}
+}
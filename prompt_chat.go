@@ -0,0 +1,108 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// Message is a message of a chat prompt template, with {{variable}}
+// placeholders in its content.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// WithPromptMessages sets the template of the prompt to a chat template of
+// messages, and its template structure to chat.
+func WithPromptMessages(messages ...Message) PromptOption {
+	return func(o *promptOptions) {
+		o.template = chatTemplate(messages)
+		o.templateStructure = PromptTemplateStructureChat
+	}
+}
+
+// CreateChatVersion creates a new version of this chat prompt with the
+// given messages as its template.
+func (p *Prompt) CreateChatVersion(ctx context.Context, messages []Message, opts ...PromptVersionOption) (*PromptVersion, error) {
+	opts = append(opts, func(o *promptVersionOptions) {
+		o.templateStructure = PromptTemplateStructureChat
+	})
+	return p.CreateVersion(ctx, chatTemplate(messages), opts...)
+}
+
+// TemplateStructure returns whether the version's template is a text or a
+// chat template.
+func (v *PromptVersion) TemplateStructure() PromptTemplateStructure {
+	if v.templateStructure == "" {
+		return PromptTemplateStructureText
+	}
+	return v.templateStructure
+}
+
+// Messages returns the messages of a chat template, or nil for a text
+// template.
+func (v *PromptVersion) Messages() []Message {
+	if v.messages == nil {
+		return nil
+	}
+	messages := make([]Message, len(v.messages))
+	copy(messages, v.messages)
+	return messages
+}
+
+// RenderMessages renders the template with the given variables as chat
+// messages, ready for a provider request. Each message of a chat template
+// is rendered as Render renders a text template. A text template is
+// rendered as a single user message.
+func (v *PromptVersion) RenderMessages(variables map[string]string) []Message {
+	if v.messages == nil {
+		return []Message{{Role: "user", Content: v.Render(variables)}}
+	}
+	messages := make([]Message, len(v.messages))
+	for i, m := range v.messages {
+		messages[i] = Message{Role: m.Role, Content: v.renderText(m.Content, variables)}
+	}
+	return messages
+}
+
+// setTemplateStructure records the version's template structure, parsing
+// the messages of a chat template. The template of a chat version is a
+// JSON array of messages; a message whose content is not a string, such as
+// a list of multimodal parts, keeps its content as JSON text.
+func (v *PromptVersion) setTemplateStructure(structure PromptTemplateStructure) {
+	v.templateStructure = structure
+	v.messages = nil
+	if structure != PromptTemplateStructureChat {
+		return
+	}
+
+	var raw []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal([]byte(v.template), &raw); err != nil {
+		// Not a chat template after all; render it as text.
+		return
+	}
+	v.messages = make([]Message, len(raw))
+	for i, m := range raw {
+		v.messages[i].Role = m.Role
+		if err := json.Unmarshal(m.Content, &v.messages[i].Content); err != nil {
+			v.messages[i].Content = string(m.Content)
+		}
+	}
+}
+
+// chatTemplate returns the template of a chat prompt with messages.
+func chatTemplate(messages []Message) string {
+	if messages == nil {
+		messages = []Message{}
+	}
+	// Keep <, >, and & readable in the stored template.
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(messages)
+	return strings.TrimSuffix(b.String(), "\n")
+}
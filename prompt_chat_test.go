@@ -0,0 +1,125 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var chatMessages = []Message{
+	{Role: "system", Content: "You answer questions about {{topic}} & nothing else."},
+	{Role: "user", Content: "{{question}}"},
+}
+
+func TestRenderMessages(t *testing.T) {
+	v := &PromptVersion{template: chatTemplate(chatMessages)}
+	v.setTemplateStructure(PromptTemplateStructureChat)
+
+	if v.TemplateStructure() != PromptTemplateStructureChat || !reflect.DeepEqual(v.Messages(), chatMessages) {
+		t.Fatalf("structure %q, messages %+v", v.TemplateStructure(), v.Messages())
+	}
+	got := v.RenderMessages(map[string]string{"topic": "Go", "question": "What is a goroutine?"})
+	want := []Message{
+		{Role: "system", Content: "You answer questions about Go & nothing else."},
+		{Role: "user", Content: "What is a goroutine?"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RenderMessages = %+v, want %+v", got, want)
+	}
+	if v.Messages()[1].Content != "{{question}}" {
+		t.Error("RenderMessages changed the template")
+	}
+}
+
+func TestRenderMessagesText(t *testing.T) {
+	v := &PromptVersion{template: "Hello, {{name}}!"}
+	if v.TemplateStructure() != PromptTemplateStructureText || v.Messages() != nil {
+		t.Fatalf("structure %q, messages %+v", v.TemplateStructure(), v.Messages())
+	}
+	got := v.RenderMessages(map[string]string{"name": "Alice"})
+	if len(got) != 1 || got[0] != (Message{Role: "user", Content: "Hello, Alice!"}) {
+		t.Errorf("RenderMessages = %+v", got)
+	}
+}
+
+func TestChatTemplateParts(t *testing.T) {
+	v := &PromptVersion{template: `[{"role":"user","content":[{"type":"text","text":"Describe {{thing}}"}]}]`}
+	v.setTemplateStructure(PromptTemplateStructureChat)
+	got := v.RenderMessages(map[string]string{"thing": "this"})
+	if len(got) != 1 || got[0].Content != `[{"type":"text","text":"Describe this"}]` {
+		t.Errorf("RenderMessages = %+v", got)
+	}
+
+	v = &PromptVersion{template: "not JSON {{x}}"}
+	v.setTemplateStructure(PromptTemplateStructureChat)
+	if got := v.RenderMessages(map[string]string{"x": "1"}); len(got) != 1 || got[0].Content != "not JSON 1" {
+		t.Errorf("RenderMessages of an invalid chat template = %+v", got)
+	}
+}
+
+func TestChatPromptRoundTrip(t *testing.T) {
+	var created, versioned map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/private/prompts":
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			w.Header().Set("Location", "/v1/private/prompts/"+created["id"].(string))
+			w.WriteHeader(http.StatusCreated)
+		case "POST /v1/private/prompts/versions":
+			_ = json.NewDecoder(r.Body).Decode(&versioned)
+			version := versioned["version"].(map[string]any)
+			version["commit"] = "abc12345"
+			version["template_structure"] = versioned["template_structure"]
+			_ = json.NewEncoder(w).Encode(version)
+		case "POST /v1/private/prompts/versions/retrieve":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":                 uuid.NewString(),
+				"commit":             "abc12345",
+				"template":           created["template"],
+				"template_structure": "chat",
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(WithURL(server.URL), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	prompt, err := client.CreatePrompt(ctx, "qa", WithPromptMessages(chatMessages...))
+	if err != nil {
+		t.Fatalf("CreatePrompt error: %v", err)
+	}
+	if created["template_structure"] != "chat" || created["template"] != chatTemplate(chatMessages) {
+		t.Errorf("create request = %v", created)
+	}
+
+	version, err := prompt.CreateChatVersion(ctx, chatMessages[1:])
+	if err != nil {
+		t.Fatalf("CreateChatVersion error: %v", err)
+	}
+	if versioned["template_structure"] != "chat" || version.TemplateStructure() != PromptTemplateStructureChat {
+		t.Errorf("version request = %v, structure %q", versioned, version.TemplateStructure())
+	}
+	if !reflect.DeepEqual(version.Messages(), chatMessages[1:]) {
+		t.Errorf("version messages = %+v", version.Messages())
+	}
+
+	loaded, err := client.GetPromptByName(ctx, "qa", "")
+	if err != nil {
+		t.Fatalf("GetPromptByName error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Messages(), chatMessages) {
+		t.Errorf("loaded messages = %+v", loaded.Messages())
+	}
+}
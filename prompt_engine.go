@@ -0,0 +1,182 @@
+package opik
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// textTemplate is a template parsed by the engine of its prompt type.
+type textTemplate interface {
+	// execute renders the template with vars. If strict, a variable
+	// missing from vars is an error wrapping ErrInvalidInput; otherwise it
+	// is rendered the way the engine renders missing variables.
+	execute(vars templateVars, strict bool) (string, error)
+	// variables returns the names of the variables the template reads, in
+	// order of first use.
+	variables() []string
+}
+
+// templateVars looks up the value of a template variable.
+type templateVars func(name string) (any, bool)
+
+// stringVars returns the lookup of variables, filling missing variables
+// with the default of options if it has one. options may be nil.
+func stringVars(variables map[string]string, options *renderOptions) templateVars {
+	return func(name string) (any, bool) {
+		if value, ok := variables[name]; ok {
+			return value, true
+		}
+		if options != nil && options.defaultSet {
+			return options.defaultVal, true
+		}
+		return nil, false
+	}
+}
+
+// anyVars returns the lookup of variables.
+func anyVars(variables map[string]any) templateVars {
+	return func(name string) (any, bool) {
+		value, ok := variables[name]
+		return value, ok
+	}
+}
+
+// parseTemplate parses template with the engine of promptType. Templates
+// of unknown types are parsed as mustache templates.
+func parseTemplate(promptType PromptType, template string) (textTemplate, error) {
+	var (
+		tmpl textTemplate
+		err  error
+	)
+	switch promptType {
+	case PromptTypeFString:
+		tmpl, err = parseFString(template)
+	case PromptTypeJinja2:
+		tmpl, err = parseJinja(template)
+	default:
+		return compileTemplate(template), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s template: %v", ErrInvalidInput, promptType, err)
+	}
+	return tmpl, nil
+}
+
+// RenderStrict renders the template with the given variables like Render,
+// but returns an error wrapping ErrInvalidInput if the template does not
+// parse or reads a variable that is missing from variables. All missing
+// variables are listed in the error.
+//
+// Variables may be any values. Jinja2 templates can loop over slices and
+// maps and read map keys and slice indexes as attributes, as in
+// {{ user.name }} or {{ items.0 }}; fstring templates can read attributes
+// the same way, as in {user.name}. Values are inserted as fmt.Sprint
+// formats them, and nil as empty.
+func (v *PromptVersion) RenderStrict(variables map[string]any) (string, error) {
+	tmpl, err := parseTemplate(v.Type(), v.template)
+	if err != nil {
+		return "", err
+	}
+	return tmpl.execute(anyVars(variables), true)
+}
+
+// missingVariables collects the names of the variables missing in strict
+// rendering, without duplicates.
+type missingVariables []string
+
+func (m *missingVariables) add(name string) {
+	if !slices.Contains(*m, name) {
+		*m = append(*m, name)
+	}
+}
+
+func (m missingVariables) err() error {
+	if len(m) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: missing prompt variables %s", ErrInvalidInput, strings.Join(m, ", "))
+}
+
+// formatPromptValue formats a variable's value for a rendered prompt.
+func formatPromptValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// templateAttribute returns the attribute name of value: the entry of a
+// map with string keys, or the element of a slice at an index.
+func templateAttribute(value any, name string) (any, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		e := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+		if !e.IsValid() {
+			return nil, false
+		}
+		return e.Interface(), true
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(name)
+		if err != nil || i < 0 || i >= rv.Len() {
+			return nil, false
+		}
+		return rv.Index(i).Interface(), true
+	default:
+		return nil, false
+	}
+}
+
+// resolveTemplatePath returns the value of a variable followed by
+// attributes, such as user.name. If it is not found, it returns the path up
+// to the first missing part.
+func resolveTemplatePath(lookup templateVars, path []string) (value any, missing string) {
+	value, ok := lookup(path[0])
+	if !ok {
+		return nil, path[0]
+	}
+	for i, name := range path[1:] {
+		if value, ok = templateAttribute(value, name); !ok {
+			return nil, strings.Join(path[:i+2], ".")
+		}
+	}
+	return value, ""
+}
+
+// isTemplatePath reports whether path is a variable name followed by
+// attributes, which are names or indexes.
+func isTemplatePath(path []string) bool {
+	if !isTemplateName(path[0]) {
+		return false
+	}
+	for _, name := range path[1:] {
+		if _, err := strconv.Atoi(name); err != nil && !isTemplateName(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// isTemplateName reports whether s is an identifier.
+func isTemplateName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderByPromptType(t *testing.T) {
+	vars := map[string]string{"name": "Ada"}
+	tests := []struct {
+		promptType PromptType
+		template   string
+		want       string
+	}{
+		{"", "Hi {{name}} {{other}}", "Hi Ada {{other}}"},
+		{PromptTypeMustache, "Hi {{name}} {name}", "Hi Ada {name}"},
+		{PromptTypeFString, "Hi {name} {other}", "Hi Ada {other}"},
+		{PromptTypeFString, "Hi {name", "Hi {name"},
+		{PromptTypeJinja2, "Hi {{ name }}{% if other %}!{% endif %}{{ other }}", "Hi Ada"},
+		{PromptTypeJinja2, "Hi {% if %}", "Hi {% if %}"},
+	}
+	for _, tt := range tests {
+		v := &PromptVersion{template: tt.template, promptType: tt.promptType}
+		if got := v.Render(vars); got != tt.want {
+			t.Errorf("%s Render(%q) = %q, want %q", tt.promptType, tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestRenderWithDefaultByPromptType(t *testing.T) {
+	v := &PromptVersion{template: "{greeting}, {name}!", promptType: PromptTypeFString}
+	if got := v.RenderWithDefault(map[string]string{"name": "Ada"}, "?"); got != "?, Ada!" {
+		t.Errorf("fstring RenderWithDefault = %q", got)
+	}
+	v = &PromptVersion{template: "{% if greeting %}{{ greeting }}{% endif %}, {{ name }}!", promptType: PromptTypeJinja2}
+	if got := v.RenderWithDefault(map[string]string{"name": "Ada"}, "?"); got != "?, Ada!" {
+		t.Errorf("jinja2 RenderWithDefault = %q", got)
+	}
+}
+
+func TestExtractVariablesByPromptType(t *testing.T) {
+	tests := []struct {
+		promptType PromptType
+		template   string
+		want       []string
+	}{
+		{PromptTypeMustache, "{{a}} {{ b }} {{a}}", []string{"a", "b"}},
+		{PromptTypeFString, "{a} {b.c} {{d}}", []string{"a", "b"}},
+		{PromptTypeJinja2, "{% for x in xs %}{{ x }}{{ sep }}{% endfor %}", []string{"xs", "sep"}},
+		{PromptTypeJinja2, "{% if %}", nil},
+	}
+	for _, tt := range tests {
+		v := &PromptVersion{template: tt.template, promptType: tt.promptType}
+		if got := v.ExtractVariables(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s ExtractVariables(%q) = %v, want %v", tt.promptType, tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestRenderStrict(t *testing.T) {
+	v := &PromptVersion{
+		template:   "{% for doc in docs %}[{{ loop.index }}] {{ doc.title }}\n{% endfor %}Q: {{ question }}",
+		promptType: PromptTypeJinja2,
+	}
+	got, err := v.RenderStrict(map[string]any{
+		"docs":     []map[string]any{{"title": "Go"}, {"title": "Opik"}},
+		"question": "what?",
+	})
+	if err != nil || got != "[1] Go\n[2] Opik\nQ: what?" {
+		t.Errorf("RenderStrict = %q, %v", got, err)
+	}
+
+	_, err = v.RenderStrict(map[string]any{"docs": nil})
+	if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "missing prompt variables question") {
+		t.Errorf("RenderStrict error = %v, want question missing", err)
+	}
+
+	v = &PromptVersion{template: "{{a}} and {{b}}"}
+	if _, err := v.RenderStrict(map[string]any{"a": 1}); err == nil || !strings.Contains(err.Error(), "missing prompt variables b") {
+		t.Errorf("mustache RenderStrict error = %v, want b missing", err)
+	}
+	if got, err := v.RenderStrict(map[string]any{"a": 1, "b": true}); err != nil || got != "1 and true" {
+		t.Errorf("mustache RenderStrict = %q, %v", got, err)
+	}
+
+	v = &PromptVersion{template: "{unclosed", promptType: PromptTypeFString}
+	if _, err := v.RenderStrict(nil); !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "fstring template: unclosed {") {
+		t.Errorf("RenderStrict of an invalid template error = %v", err)
+	}
+}
+
+func TestRenderBatchByPromptType(t *testing.T) {
+	v := &PromptVersion{template: "{{ name | upper }}{% if vip %}*{% endif %}", promptType: PromptTypeJinja2}
+	prompts, err := v.RenderBatch([]map[string]string{
+		{"name": "ada", "vip": "yes"},
+		{"name": "bob"},
+	})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Failed) != 1 || batchErr.Failed[0].Index != 1 {
+		t.Fatalf("RenderBatch error = %v, want row 1 failed", err)
+	}
+	if prompts[0] != "ADA*" || prompts[1] != "" {
+		t.Errorf("prompts = %q", prompts)
+	}
+
+	prompts, err = v.RenderBatch([]map[string]string{{"name": "bob"}}, WithRenderDefault(""))
+	if err != nil || prompts[0] != "BOB" {
+		t.Errorf("RenderBatch with default = %q, %v", prompts, err)
+	}
+
+	v = &PromptVersion{template: "{% for %}", promptType: PromptTypeJinja2}
+	if prompts, err := v.RenderBatch([]map[string]string{{}}); !errors.Is(err, ErrInvalidInput) || prompts != nil {
+		t.Errorf("RenderBatch of an invalid template = %q, %v", prompts, err)
+	}
+}
+
+func TestRenderMessagesByPromptType(t *testing.T) {
+	v := &PromptVersion{promptType: PromptTypeFString}
+	v.template = chatTemplate([]Message{{Role: "system", Content: "You help {user}."}, {Role: "user", Content: "{question}"}})
+	v.setTemplateStructure(PromptTemplateStructureChat)
+	got := v.RenderMessages(map[string]string{"user": "Ada", "question": "Why?"})
+	want := []Message{{Role: "system", Content: "You help Ada."}, {Role: "user", Content: "Why?"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RenderMessages = %+v, want %+v", got, want)
+	}
+}
+
+func TestPromptVersionType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"commit": "abc12345", "template": "Hi {{ name | upper }}", "type": "jinja2"}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(WithURL(server.URL), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	v, err := client.GetPromptByName(context.Background(), "greeting", "")
+	if err != nil {
+		t.Fatalf("GetPromptByName error: %v", err)
+	}
+	if v.Type() != PromptTypeJinja2 || v.Render(map[string]string{"name": "Ada"}) != "Hi ADA" {
+		t.Errorf("Type() = %q, Render = %q", v.Type(), v.Render(map[string]string{"name": "Ada"}))
+	}
+	if (&PromptVersion{}).Type() != PromptTypeMustache {
+		t.Errorf("Type() of a version without a type = %q, want mustache", (&PromptVersion{}).Type())
+	}
+}
@@ -0,0 +1,88 @@
+package opik
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// fstringTemplate is a template with Python format string placeholders,
+// {variable} or {variable.attribute}, and {{ and }} for literal braces.
+type fstringTemplate struct {
+	// literals has one more entry than fields: the text before each
+	// placeholder, and the text after the last.
+	literals []string
+	// fields are the paths of the placeholders, split at dots.
+	fields [][]string
+}
+
+// parseFString parses the placeholders of template. Format specs and
+// conversions, as in {price:.2f} or {name!r}, are not supported.
+func parseFString(template string) (*fstringTemplate, error) {
+	t := &fstringTemplate{}
+	var literal strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case (c == '{' || c == '}') && i+1 < len(template) && template[i+1] == c:
+			literal.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexByte(template[i+1:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed { at offset %d", i)
+			}
+			field := template[i+1 : i+1+end]
+			path := strings.Split(field, ".")
+			if !isTemplatePath(path) {
+				return nil, fmt.Errorf("invalid placeholder {%s} at offset %d", field, i)
+			}
+			t.literals = append(t.literals, literal.String())
+			t.fields = append(t.fields, path)
+			literal.Reset()
+			i += end + 1
+		case c == '}':
+			return nil, fmt.Errorf("single } at offset %d", i)
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	t.literals = append(t.literals, literal.String())
+	return t, nil
+}
+
+// execute renders the template with vars. Missing variables keep their
+// placeholders unless strict.
+func (t *fstringTemplate) execute(vars templateVars, strict bool) (string, error) {
+	var (
+		b       strings.Builder
+		missing missingVariables
+	)
+	for i, path := range t.fields {
+		b.WriteString(t.literals[i])
+		value, name := resolveTemplatePath(vars, path)
+		switch {
+		case name == "":
+			b.WriteString(formatPromptValue(value))
+		case strict:
+			missing.add(name)
+		default:
+			b.WriteString("{" + strings.Join(path, ".") + "}")
+		}
+	}
+	b.WriteString(t.literals[len(t.fields)])
+	if err := missing.err(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (t *fstringTemplate) variables() []string {
+	var names []string
+	for _, path := range t.fields {
+		if !slices.Contains(names, path[0]) {
+			names = append(names, path[0])
+		}
+	}
+	return names
+}
@@ -0,0 +1,84 @@
+package opik
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFStringRender(t *testing.T) {
+	vars := anyVars(map[string]any{
+		"name":  "Ada",
+		"n":     3,
+		"user":  map[string]any{"city": "London"},
+		"items": []string{"tea", "cake"},
+	})
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"Hello, {name}!", "Hello, Ada!"},
+		{"{n} items: {items.0}, {items.1}", "3 items: tea, cake"},
+		{"{user.city}", "London"},
+		{"literal {{braces}} and {name}", "literal {braces} and Ada"},
+		{"no placeholders", "no placeholders"},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseFString(tt.template)
+		if err != nil {
+			t.Errorf("parseFString(%q) error: %v", tt.template, err)
+			continue
+		}
+		got, err := tmpl.execute(vars, true)
+		if err != nil || got != tt.want {
+			t.Errorf("execute(%q) = %q, %v, want %q", tt.template, got, err, tt.want)
+		}
+	}
+}
+
+func TestFStringMissingVariables(t *testing.T) {
+	tmpl, err := parseFString("{a} {user.name} {a} {b}")
+	if err != nil {
+		t.Fatalf("parseFString error: %v", err)
+	}
+	vars := anyVars(map[string]any{"user": map[string]any{}, "b": "B"})
+
+	got, err := tmpl.execute(vars, false)
+	if err != nil || got != "{a} {user.name} {a} B" {
+		t.Errorf("lenient execute = %q, %v, want missing placeholders kept", got, err)
+	}
+	_, err = tmpl.execute(vars, true)
+	if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "missing prompt variables a, user.name") {
+		t.Errorf("strict execute error = %v, want a and user.name missing", err)
+	}
+}
+
+func TestFStringParseErrors(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"Hello {name", "unclosed { at offset 6"},
+		{"a } b", "single } at offset 2"},
+		{"{price:.2f}", "invalid placeholder {price:.2f}"},
+		{"{}", "invalid placeholder {}"},
+		{`{"a": 1}`, `invalid placeholder {"a": 1}`},
+	}
+	for _, tt := range tests {
+		_, err := parseFString(tt.template)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseFString(%q) error = %v, want %q", tt.template, err, tt.want)
+		}
+	}
+}
+
+func TestFStringVariables(t *testing.T) {
+	tmpl, err := parseFString("{b} {a.x} {b} {{c}}")
+	if err != nil {
+		t.Fatalf("parseFString error: %v", err)
+	}
+	if got, want := tmpl.variables(), []string{"b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("variables() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,846 @@
+package opik
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// jinjaTemplate is a template in the subset of Jinja2 that prompts use:
+//
+//   - {{ expression }} outputs, with the filters upper, lower, trim,
+//     length, join, and default;
+//   - {% if %}, {% elif %}, and {% else %} conditionals;
+//   - {% for item in items %} loops, with {% else %} for empty lists and
+//     loop.index, loop.index0, loop.first, loop.last, and loop.length;
+//   - {# comments #}, and "-" to trim whitespace before or after a tag.
+//
+// Expressions are variables with attributes, as in user.name or items.0,
+// string and number literals, true, false, and none, the operators ==,
+// !=, and, or, and not, and the tests "is defined" and "is not defined".
+type jinjaTemplate struct {
+	nodes []jinjaNode
+}
+
+type (
+	jinjaNode any
+	jinjaExpr any
+)
+
+type jinjaText string
+
+type jinjaOutput struct {
+	expr jinjaExpr
+}
+
+type jinjaIf struct {
+	conds  []jinjaExpr
+	bodies [][]jinjaNode
+	orElse []jinjaNode
+}
+
+type jinjaFor struct {
+	name   string
+	items  jinjaExpr
+	body   []jinjaNode
+	orElse []jinjaNode
+}
+
+type jinjaLiteral struct {
+	value any
+}
+
+// jinjaPath is a variable followed by attributes.
+type jinjaPath []string
+
+type jinjaNot struct {
+	x jinjaExpr
+}
+
+// jinjaBinary is a comparison with == or !=, or a boolean and or or.
+type jinjaBinary struct {
+	op   string
+	x, y jinjaExpr
+}
+
+type jinjaDefined struct {
+	x      jinjaExpr
+	negate bool
+}
+
+type jinjaFilter struct {
+	x    jinjaExpr
+	name string
+	args []jinjaExpr
+}
+
+// jinjaFilterArgs are the filters, with their maximum number of arguments.
+var jinjaFilterArgs = map[string]int{
+	"upper":   0,
+	"lower":   0,
+	"trim":    0,
+	"length":  0,
+	"join":    1,
+	"default": 1,
+}
+
+// jinjaToken is a piece of template text, or the inside of an output or
+// block tag.
+type jinjaToken struct {
+	kind byte // 't' for text, 'o' for {{ }}, 'b' for {% %}
+	text string
+}
+
+const jinjaSpace = " \t\r\n"
+
+// parseJinja parses template.
+func parseJinja(template string) (*jinjaTemplate, error) {
+	tokens, err := lexJinja(template)
+	if err != nil {
+		return nil, err
+	}
+	p := &jinjaParser{tokens: tokens}
+	nodes, _, _, err := p.parseNodes()
+	if err != nil {
+		return nil, err
+	}
+	return &jinjaTemplate{nodes: nodes}, nil
+}
+
+// lexJinja splits template into text and tags, dropping comments and
+// trimming whitespace around tags marked with "-".
+func lexJinja(template string) ([]jinjaToken, error) {
+	var tokens []jinjaToken
+	trimNext := false
+	rest := template
+	for {
+		start := -1
+		for i := 0; i+1 < len(rest); i++ {
+			if rest[i] == '{' && (rest[i+1] == '{' || rest[i+1] == '%' || rest[i+1] == '#') {
+				start = i
+				break
+			}
+		}
+		text := rest
+		if start >= 0 {
+			text = rest[:start]
+		}
+		if trimNext {
+			text = strings.TrimLeft(text, jinjaSpace)
+			trimNext = false
+		}
+		if start < 0 {
+			if text != "" {
+				tokens = append(tokens, jinjaToken{kind: 't', text: text})
+			}
+			return tokens, nil
+		}
+
+		open := rest[start+1]
+		closing := map[byte]string{'{': "}}", '%': "%}", '#': "#}"}[open]
+		end := strings.Index(rest[start+2:], closing)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed %s", rest[start:start+2])
+		}
+		inner := rest[start+2 : start+2+end]
+		rest = rest[start+2+end+2:]
+		if strings.HasPrefix(inner, "-") {
+			text = strings.TrimRight(text, jinjaSpace)
+			inner = inner[1:]
+		}
+		if strings.HasSuffix(inner, "-") {
+			trimNext = true
+			inner = inner[:len(inner)-1]
+		}
+		if text != "" {
+			tokens = append(tokens, jinjaToken{kind: 't', text: text})
+		}
+		switch open {
+		case '{':
+			tokens = append(tokens, jinjaToken{kind: 'o', text: strings.TrimSpace(inner)})
+		case '%':
+			tokens = append(tokens, jinjaToken{kind: 'b', text: strings.TrimSpace(inner)})
+		}
+	}
+}
+
+type jinjaParser struct {
+	tokens []jinjaToken
+	pos    int
+}
+
+// parseNodes parses nodes up to a block tag whose keyword is one of stop,
+// and returns the keyword and the rest of the tag. Without stop keywords,
+// it parses to the end of the template.
+func (p *jinjaParser) parseNodes(stop ...string) (nodes []jinjaNode, keyword, args string, err error) {
+	for p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		p.pos++
+		switch tok.kind {
+		case 't':
+			nodes = append(nodes, jinjaText(tok.text))
+		case 'o':
+			expr, err := parseJinjaExpr(tok.text)
+			if err != nil {
+				return nil, "", "", fmt.Errorf("{{ %s }}: %w", tok.text, err)
+			}
+			nodes = append(nodes, jinjaOutput{expr: expr})
+		case 'b':
+			keyword, args, _ = strings.Cut(tok.text, " ")
+			args = strings.TrimSpace(args)
+			if slices.Contains(stop, keyword) {
+				return nodes, keyword, args, nil
+			}
+			var node jinjaNode
+			switch keyword {
+			case "if":
+				node, err = p.parseIf(args)
+			case "for":
+				node, err = p.parseFor(args)
+			default:
+				err = fmt.Errorf("unexpected {%% %s %%}", keyword)
+			}
+			if err != nil {
+				return nil, "", "", err
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	if len(stop) > 0 {
+		return nil, "", "", fmt.Errorf("missing {%% %s %%}", stop[len(stop)-1])
+	}
+	return nodes, "", "", nil
+}
+
+func (p *jinjaParser) parseIf(args string) (jinjaNode, error) {
+	node := &jinjaIf{}
+	for {
+		cond, err := parseJinjaExpr(args)
+		if err != nil {
+			return nil, fmt.Errorf("{%% if %s %%}: %w", args, err)
+		}
+		body, keyword, rest, err := p.parseNodes("elif", "else", "endif")
+		if err != nil {
+			return nil, err
+		}
+		node.conds = append(node.conds, cond)
+		node.bodies = append(node.bodies, body)
+		switch keyword {
+		case "elif":
+			args = rest
+			continue
+		case "else":
+			if node.orElse, _, _, err = p.parseNodes("endif"); err != nil {
+				return nil, err
+			}
+		}
+		return node, nil
+	}
+}
+
+func (p *jinjaParser) parseFor(args string) (jinjaNode, error) {
+	name, items, ok := strings.Cut(args, " in ")
+	name = strings.TrimSpace(name)
+	if !ok || !isTemplateName(name) {
+		return nil, fmt.Errorf("{%% for %s %%}: want {%% for name in items %%}", args)
+	}
+	expr, err := parseJinjaExpr(items)
+	if err != nil {
+		return nil, fmt.Errorf("{%% for %s %%}: %w", args, err)
+	}
+	node := &jinjaFor{name: name, items: expr}
+	body, keyword, _, err := p.parseNodes("else", "endfor")
+	if err != nil {
+		return nil, err
+	}
+	node.body = body
+	if keyword == "else" {
+		if node.orElse, _, _, err = p.parseNodes("endfor"); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// parseJinjaExpr parses an expression.
+func parseJinjaExpr(src string) (jinjaExpr, error) {
+	tokens, err := lexJinjaExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+	p := &jinjaExprParser{tokens: tokens}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// lexJinjaExpr splits an expression into names, literals, and operators.
+// String literals keep their quotes.
+func lexJinjaExpr(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case strings.IndexByte(jinjaSpace, c) >= 0:
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, src[i:j+1])
+			i = j + 1
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			if j+1 < len(src) && src[j] == '.' && src[j+1] >= '0' && src[j+1] <= '9' && (len(tokens) == 0 || tokens[len(tokens)-1] != ".") {
+				j++
+				for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+					j++
+				}
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)) || c >= 0x80:
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] >= 0x80 || unicode.IsLetter(rune(src[j])) || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		case strings.HasPrefix(src[i:], "==") || strings.HasPrefix(src[i:], "!="):
+			tokens = append(tokens, src[i:i+2])
+			i += 2
+		case strings.IndexByte(".|(),", c) >= 0:
+			tokens = append(tokens, src[i:i+1])
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+type jinjaExprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *jinjaExprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *jinjaExprParser) accept(tok string) bool {
+	if p.peek() == tok {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *jinjaExprParser) expect(tok string) error {
+	if !p.accept(tok) {
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("missing %q", tok)
+		}
+		return fmt.Errorf("unexpected %q, want %q", p.peek(), tok)
+	}
+	return nil
+}
+
+func (p *jinjaExprParser) or() (jinjaExpr, error) {
+	x, err := p.and()
+	for err == nil && p.accept("or") {
+		var y jinjaExpr
+		y, err = p.and()
+		x = jinjaBinary{op: "or", x: x, y: y}
+	}
+	return x, err
+}
+
+func (p *jinjaExprParser) and() (jinjaExpr, error) {
+	x, err := p.not()
+	for err == nil && p.accept("and") {
+		var y jinjaExpr
+		y, err = p.not()
+		x = jinjaBinary{op: "and", x: x, y: y}
+	}
+	return x, err
+}
+
+func (p *jinjaExprParser) not() (jinjaExpr, error) {
+	if p.accept("not") {
+		x, err := p.not()
+		return jinjaNot{x: x}, err
+	}
+	return p.comparison()
+}
+
+func (p *jinjaExprParser) comparison() (jinjaExpr, error) {
+	x, err := p.filtered()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.accept("=="), p.accept("!="):
+		op := p.tokens[p.pos-1]
+		y, err := p.filtered()
+		return jinjaBinary{op: op, x: x, y: y}, err
+	case p.accept("is"):
+		negate := p.accept("not")
+		if err := p.expect("defined"); err != nil {
+			return nil, err
+		}
+		return jinjaDefined{x: x, negate: negate}, nil
+	}
+	return x, nil
+}
+
+func (p *jinjaExprParser) filtered() (jinjaExpr, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("|") {
+		f := jinjaFilter{x: x, name: p.peek()}
+		maxArgs, ok := jinjaFilterArgs[f.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %q", f.name)
+		}
+		p.pos++
+		if p.accept("(") && !p.accept(")") {
+			for {
+				arg, err := p.or()
+				if err != nil {
+					return nil, err
+				}
+				f.args = append(f.args, arg)
+				if p.accept(")") {
+					break
+				}
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(f.args) > maxArgs {
+			return nil, fmt.Errorf("filter %s takes at most %d arguments", f.name, maxArgs)
+		}
+		x = f
+	}
+	return x, nil
+}
+
+func (p *jinjaExprParser) primary() (jinjaExpr, error) {
+	tok := p.peek()
+	if tok == "" {
+		return nil, errors.New("unexpected end of expression")
+	}
+	p.pos++
+	switch {
+	case tok == "(":
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	case tok[0] == '"' || tok[0] == '\'':
+		return jinjaLiteral{value: unquoteJinja(tok)}, nil
+	case tok[0] >= '0' && tok[0] <= '9':
+		if n, err := strconv.Atoi(tok); err == nil {
+			return jinjaLiteral{value: n}, nil
+		}
+		f, err := strconv.ParseFloat(tok, 64)
+		return jinjaLiteral{value: f}, err
+	}
+	switch tok {
+	case "true", "True":
+		return jinjaLiteral{value: true}, nil
+	case "false", "False":
+		return jinjaLiteral{value: false}, nil
+	case "none", "None":
+		return jinjaLiteral{value: nil}, nil
+	case "and", "or", "not", "is":
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+	if !isTemplateName(tok) {
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+	path := jinjaPath{tok}
+	for p.accept(".") {
+		attr := p.peek()
+		if _, err := strconv.Atoi(attr); err != nil && !isTemplateName(attr) {
+			return nil, fmt.Errorf("invalid attribute %q", attr)
+		}
+		p.pos++
+		path = append(path, attr)
+	}
+	return path, nil
+}
+
+// unquoteJinja returns the value of a quoted string literal.
+func unquoteJinja(tok string) string {
+	s := tok[1 : len(tok)-1]
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// jinjaValue is the value of an expression. undefined is the name of the
+// missing variable or attribute when the value is undefined.
+type jinjaValue struct {
+	value     any
+	undefined string
+}
+
+type jinjaState struct {
+	vars    templateVars
+	strict  bool
+	locals  []jinjaLocal
+	missing missingVariables
+	b       strings.Builder
+}
+
+// jinjaLocal is a variable set by a loop.
+type jinjaLocal struct {
+	name  string
+	value any
+}
+
+// execute renders the template with vars. Missing variables are empty and
+// false unless strict.
+func (t *jinjaTemplate) execute(vars templateVars, strict bool) (string, error) {
+	s := &jinjaState{vars: vars, strict: strict}
+	if err := s.exec(t.nodes); err != nil {
+		return "", err
+	}
+	if err := s.missing.err(); err != nil {
+		return "", err
+	}
+	return s.b.String(), nil
+}
+
+func (s *jinjaState) exec(nodes []jinjaNode) error {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case jinjaText:
+			s.b.WriteString(string(n))
+		case jinjaOutput:
+			s.b.WriteString(formatPromptValue(s.use(s.eval(n.expr))))
+		case *jinjaIf:
+			body := n.orElse
+			for i, cond := range n.conds {
+				if jinjaTruthy(s.use(s.eval(cond))) {
+					body = n.bodies[i]
+					break
+				}
+			}
+			if err := s.exec(body); err != nil {
+				return err
+			}
+		case *jinjaFor:
+			if err := s.execFor(n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jinjaState) execFor(n *jinjaFor) error {
+	value := s.use(s.eval(n.items))
+	items, ok := jinjaItems(value)
+	if !ok && s.strict {
+		return fmt.Errorf("%w: cannot loop over %T", ErrInvalidInput, value)
+	}
+	if len(items) == 0 {
+		return s.exec(n.orElse)
+	}
+	for i, item := range items {
+		loop := map[string]any{
+			"index":  i + 1,
+			"index0": i,
+			"first":  i == 0,
+			"last":   i == len(items)-1,
+			"length": len(items),
+		}
+		s.locals = append(s.locals, jinjaLocal{name: n.name, value: item}, jinjaLocal{name: "loop", value: loop})
+		err := s.exec(n.body)
+		s.locals = s.locals[:len(s.locals)-2]
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the value of a loop variable or a template variable.
+func (s *jinjaState) lookup(name string) (any, bool) {
+	for i := len(s.locals) - 1; i >= 0; i-- {
+		if s.locals[i].name == name {
+			return s.locals[i].value, true
+		}
+	}
+	return s.vars(name)
+}
+
+// use returns the value of v, recording it as missing if it is undefined.
+func (s *jinjaState) use(v jinjaValue) any {
+	if v.undefined != "" {
+		if s.strict {
+			s.missing.add(v.undefined)
+		}
+		return nil
+	}
+	return v.value
+}
+
+func (s *jinjaState) eval(e jinjaExpr) jinjaValue {
+	switch e := e.(type) {
+	case jinjaLiteral:
+		return jinjaValue{value: e.value}
+	case jinjaPath:
+		value, missing := resolveTemplatePath(s.lookup, e)
+		return jinjaValue{value: value, undefined: missing}
+	case jinjaNot:
+		return jinjaValue{value: !jinjaTruthy(s.use(s.eval(e.x)))}
+	case jinjaDefined:
+		return jinjaValue{value: (s.eval(e.x).undefined == "") != e.negate}
+	case jinjaBinary:
+		x := s.use(s.eval(e.x))
+		switch e.op {
+		case "and":
+			if !jinjaTruthy(x) {
+				return jinjaValue{value: x}
+			}
+			return jinjaValue{value: s.use(s.eval(e.y))}
+		case "or":
+			if jinjaTruthy(x) {
+				return jinjaValue{value: x}
+			}
+			return jinjaValue{value: s.use(s.eval(e.y))}
+		}
+		equal := jinjaEqual(x, s.use(s.eval(e.y)))
+		return jinjaValue{value: equal == (e.op == "==")}
+	case jinjaFilter:
+		return s.filter(e)
+	}
+	return jinjaValue{}
+}
+
+func (s *jinjaState) filter(f jinjaFilter) jinjaValue {
+	x := s.eval(f.x)
+	if f.name == "default" {
+		if x.undefined == "" {
+			return x
+		}
+		if len(f.args) == 0 {
+			return jinjaValue{value: ""}
+		}
+		return s.eval(f.args[0])
+	}
+
+	value := s.use(x)
+	switch f.name {
+	case "upper":
+		return jinjaValue{value: strings.ToUpper(formatPromptValue(value))}
+	case "lower":
+		return jinjaValue{value: strings.ToLower(formatPromptValue(value))}
+	case "trim":
+		return jinjaValue{value: strings.TrimSpace(formatPromptValue(value))}
+	case "length":
+		return jinjaValue{value: jinjaLength(value)}
+	case "join":
+		var sep string
+		if len(f.args) > 0 {
+			sep = formatPromptValue(s.use(s.eval(f.args[0])))
+		}
+		items, _ := jinjaItems(value)
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = formatPromptValue(item)
+		}
+		return jinjaValue{value: strings.Join(parts, sep)}
+	}
+	return jinjaValue{}
+}
+
+// variables returns the names of the template variables the template
+// reads, leaving out loop variables.
+func (t *jinjaTemplate) variables() []string {
+	var names []string
+	var walkExpr func(e jinjaExpr, bound []string)
+	walkExpr = func(e jinjaExpr, bound []string) {
+		switch e := e.(type) {
+		case jinjaPath:
+			if !slices.Contains(bound, e[0]) && !slices.Contains(names, e[0]) {
+				names = append(names, e[0])
+			}
+		case jinjaNot:
+			walkExpr(e.x, bound)
+		case jinjaDefined:
+			walkExpr(e.x, bound)
+		case jinjaBinary:
+			walkExpr(e.x, bound)
+			walkExpr(e.y, bound)
+		case jinjaFilter:
+			walkExpr(e.x, bound)
+			for _, arg := range e.args {
+				walkExpr(arg, bound)
+			}
+		}
+	}
+	var walk func(nodes []jinjaNode, bound []string)
+	walk = func(nodes []jinjaNode, bound []string) {
+		for _, node := range nodes {
+			switch n := node.(type) {
+			case jinjaOutput:
+				walkExpr(n.expr, bound)
+			case *jinjaIf:
+				for i, cond := range n.conds {
+					walkExpr(cond, bound)
+					walk(n.bodies[i], bound)
+				}
+				walk(n.orElse, bound)
+			case *jinjaFor:
+				walkExpr(n.items, bound)
+				walk(n.body, append(slices.Clip(bound), n.name, "loop"))
+				walk(n.orElse, bound)
+			}
+		}
+	}
+	walk(t.nodes, nil)
+	return names
+}
+
+// jinjaTruthy reports whether value is true in a condition: false, nil,
+// zero numbers, and empty strings, slices, and maps are false.
+func jinjaTruthy(value any) bool {
+	if value == nil {
+		return false
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len() > 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		f, _ := jinjaNumber(value)
+		return f != 0
+	default:
+		return true
+	}
+}
+
+// jinjaNumber returns value as a float64 if it is a number.
+func jinjaNumber(value any) (float64, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// jinjaEqual reports whether x and y are equal, comparing numbers by value
+// whatever their types.
+func jinjaEqual(x, y any) bool {
+	if fx, ok := jinjaNumber(x); ok {
+		fy, ok := jinjaNumber(y)
+		return ok && fx == fy
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// jinjaItems returns the items a loop over value iterates: the elements
+// of a slice, or the sorted keys of a map. It reports false for other
+// values; nil has no items.
+func jinjaItems(value any) ([]any, bool) {
+	if value == nil {
+		return nil, true
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return items, true
+	case reflect.Map:
+		keys := rv.MapKeys()
+		items := make([]any, len(keys))
+		for i, k := range keys {
+			items[i] = k.Interface()
+		}
+		slices.SortFunc(items, func(a, b any) int {
+			return strings.Compare(formatPromptValue(a), formatPromptValue(b))
+		})
+		return items, true
+	default:
+		return nil, false
+	}
+}
+
+// jinjaLength returns the length of a string, in characters, or of a slice
+// or map.
+func jinjaLength(value any) int {
+	if s, ok := value.(string); ok {
+		return len([]rune(s))
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len()
+	default:
+		return 0
+	}
+}
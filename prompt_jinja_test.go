@@ -0,0 +1,121 @@
+package opik
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJinjaRender(t *testing.T) {
+	vars := map[string]any{
+		"name":    "Ada",
+		"premium": true,
+		"count":   0,
+		"tier":    "gold",
+		"user":    map[string]any{"name": "Grace", "langs": []string{"go", "python"}},
+		"items":   []any{"tea", "cake"},
+		"empty":   []string{},
+		"scores":  map[string]int{"b": 2, "a": 1},
+	}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"Hello, {{ name }}!", "Hello, Ada!"},
+		{"{{name}} {{ user.name }} {{ items.1 }}", "Ada Grace cake"},
+		{"{{ name | upper }} {{ ' x ' | trim }} {{ 'ABC' | lower }}", "ADA x abc"},
+		{"{{ items | length }} {{ user.langs | join(', ') }}", "2 go, python"},
+		{"{{ missing | default('n/a') }} {{ name | default('n/a') }}", "n/a Ada"},
+		{"{% if premium %}VIP{% else %}basic{% endif %}", "VIP"},
+		{"{% if count %}some{% elif tier == 'gold' %}gold{% else %}none{% endif %}", "gold"},
+		{"{% if tier != 'gold' or not premium %}x{% else %}y{% endif %}", "y"},
+		{"{% if premium and count == 0 %}ok{% endif %}", "ok"},
+		{"{% if missing is defined %}set{% elif missing is not defined %}unset{% endif %}", "unset"},
+		{"{% for item in items %}{{ loop.index }}.{{ item }}{% if not loop.last %}, {% endif %}{% endfor %}", "1.tea, 2.cake"},
+		{"{% for item in empty %}{{ item }}{% else %}nothing{% endfor %}", "nothing"},
+		{"{% for k in scores %}{{ k }}={{ loop.length }};{% endfor %}", "a=2;b=2;"},
+		{"{% for lang in user.langs %}{% for c in items %}{{ lang }}/{{ c }} {% endfor %}{% endfor %}", "go/tea go/cake python/tea python/cake "},
+		{"a {# note #}b", "a b"},
+		{"<ul>\n  {%- for item in items %}\n  <li>{{ item }}</li>\n  {%- endfor %}\n</ul>", "<ul>\n  <li>tea</li>\n  <li>cake</li>\n</ul>"},
+		{"{{ \"say \\\"hi\\\"\" }} {{ 1.5 }} {{ none }}|", `say "hi" 1.5 |`},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseJinja(tt.template)
+		if err != nil {
+			t.Errorf("parseJinja(%q) error: %v", tt.template, err)
+			continue
+		}
+		got, err := tmpl.execute(anyVars(vars), true)
+		if err != nil || got != tt.want {
+			t.Errorf("execute(%q) = %q, %v, want %q", tt.template, got, err, tt.want)
+		}
+	}
+}
+
+func TestJinjaMissingVariables(t *testing.T) {
+	tmpl, err := parseJinja("{{ a }}{% if b %}{{ c.d }}{% endif %}{% for x in e %}{{ x }}{% endfor %}{{ a }}")
+	if err != nil {
+		t.Fatalf("parseJinja error: %v", err)
+	}
+	vars := anyVars(map[string]any{"c": map[string]any{}})
+
+	got, err := tmpl.execute(vars, false)
+	if err != nil || got != "" {
+		t.Errorf("lenient execute = %q, %v, want missing variables empty", got, err)
+	}
+	_, err = tmpl.execute(vars, true)
+	if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "missing prompt variables a, b, e") {
+		t.Errorf("strict execute error = %v, want a, b, and e missing", err)
+	}
+}
+
+func TestJinjaLoopOverNonList(t *testing.T) {
+	tmpl, err := parseJinja("{% for x in n %}{{ x }}{% endfor %}")
+	if err != nil {
+		t.Fatalf("parseJinja error: %v", err)
+	}
+	if _, err := tmpl.execute(anyVars(map[string]any{"n": 3}), true); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("strict execute error = %v, want ErrInvalidInput", err)
+	}
+	if got, err := tmpl.execute(anyVars(map[string]any{"n": 3}), false); err != nil || got != "" {
+		t.Errorf("lenient execute = %q, %v, want an empty loop", got, err)
+	}
+}
+
+func TestJinjaParseErrors(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{{ name", "unclosed {{"},
+		{"{% if a %}yes", "missing {% endif %}"},
+		{"{% for x in xs %}", "missing {% endfor %}"},
+		{"{% endif %}", "unexpected {% endif %}"},
+		{"{% include 'x' %}", "unexpected {% include %}"},
+		{"{{ name | shout }}", `unknown filter "shout"`},
+		{"{{ }}", "empty expression"},
+		{"{{ a == }}", "unexpected end of expression"},
+		{"{{ 'open }}", "unterminated string"},
+		{"{% for in xs %}{% endfor %}", "want {% for name in items %}"},
+		{"{{ a b }}", `unexpected "b"`},
+		{"{{ join | join(',', 'x') }}", "at most 1 arguments"},
+	}
+	for _, tt := range tests {
+		_, err := parseJinja(tt.template)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseJinja(%q) error = %v, want %q", tt.template, err, tt.want)
+		}
+	}
+}
+
+func TestJinjaVariables(t *testing.T) {
+	tmpl, err := parseJinja("{{ greeting }} {% for item in items %}{{ item.name }} {{ loop.index }} {{ sep }}{% endfor %}{% if user.admin %}{{ greeting }}{% endif %}{{ x | default(fallback) }}")
+	if err != nil {
+		t.Fatalf("parseJinja error: %v", err)
+	}
+	want := []string{"greeting", "items", "sep", "user", "x", "fallback"}
+	if got := tmpl.variables(); !reflect.DeepEqual(got, want) {
+		t.Errorf("variables() = %v, want %v", got, want)
+	}
+}
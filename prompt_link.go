@@ -0,0 +1,163 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/internal/api"
+	"github.com/plexusone/opik-go/metadata"
+)
+
+// promptsMetadataKey is the metadata key listing the prompt versions a
+// trace or span used, as the Opik UI and Python SDK read it.
+const promptsMetadataKey = "opik_prompts"
+
+// promptTracesPageSize is how many traces or spans GetTracesByPrompt
+// fetches per request.
+const promptTracesPageSize = 100
+
+// WithTracePromptVersion records that the trace used a prompt version, so
+// the trace is found by GetTracesByPrompt and linked to the prompt in
+// Opik. The prompt and version IDs and the commit are added to the trace's
+// metadata under "opik_prompts"; the option can be given once for each
+// prompt used. A nil version records nothing.
+//
+//	version, _ := client.GetPromptByName(ctx, "summarize", "")
+//	trace, _ := client.Trace(ctx, "summarize", opik.WithTracePromptVersion(version))
+func WithTracePromptVersion(version *PromptVersion) TraceOption {
+	return func(o *traceOptions) {
+		o.metadata = linkPrompt(o.metadata, version)
+	}
+}
+
+// WithSpanPromptVersion records that the span, typically an LLM call, used
+// a prompt version, as WithTracePromptVersion does for a trace.
+// GetTracesByPrompt finds the span's trace.
+func WithSpanPromptVersion(version *PromptVersion) SpanOption {
+	return func(o *spanOptions) {
+		o.metadata = linkPrompt(o.metadata, version)
+	}
+}
+
+// linkPrompt returns md with version added to its list of prompts. md is
+// not modified.
+func linkPrompt(md map[string]any, version *PromptVersion) map[string]any {
+	if version == nil {
+		return md
+	}
+	links, _ := md[promptsMetadataKey].([]any)
+	links = append(slices.Clip(links), map[string]any{
+		"id": version.promptID,
+		"version": map[string]any{
+			"id":     version.id,
+			"commit": version.commit,
+		},
+	})
+	return metadata.Merge(md, map[string]any{promptsMetadataKey: links})
+}
+
+// linksPrompt reports whether metadata read back from Opik lists the
+// prompt or prompt version with the given ID.
+func linksPrompt(md any, id string) bool {
+	m, _ := md.(map[string]any)
+	links, _ := m[promptsMetadataKey].([]any)
+	for _, link := range links {
+		l, _ := link.(map[string]any)
+		version, _ := l["version"].(map[string]any)
+		if l["id"] == id || version["id"] == id {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTracesByPrompt returns the traces of the client's project that used
+// a prompt, newest first. promptID is either a prompt's ID, to find the
+// traces of all its versions, or a PromptVersion's ID, to find those of
+// one version. A trace is included if it or one of its spans was created
+// with WithTracePromptVersion or WithSpanPromptVersion.
+//
+//	traces, err := client.GetTracesByPrompt(ctx, version.ID())
+func (c *Client) GetTracesByPrompt(ctx context.Context, promptID string) ([]*TraceInfo, error) {
+	if _, err := uuid.Parse(promptID); err != nil {
+		return nil, fmt.Errorf("%w: prompt ID %q: %v", ErrInvalidInput, promptID, err)
+	}
+	filters, err := json.Marshal([]itemFilter{{
+		Field:    "metadata",
+		Key:      promptsMetadataKey,
+		Operator: "contains",
+		Value:    promptID,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	var traces []*TraceInfo
+	seen := make(map[string]bool)
+	traceParams := api.GetTracesByProjectParams{
+		ProjectName: api.NewOptString(c.projectName),
+		Filters:     api.NewOptString(string(filters)),
+		Size:        api.NewOptInt32(promptTracesPageSize),
+	}
+	for page := int32(1); ; page++ {
+		traceParams.Page = api.NewOptInt32(page)
+		resp, err := c.apiClient.GetTracesByProject(ctx, traceParams)
+		if err != nil {
+			return nil, err
+		}
+		for i := range resp.Content {
+			trace := traceInfoFromAPI(&resp.Content[i])
+			// Servers that ignore the filter return every trace.
+			if !seen[trace.ID] && linksPrompt(trace.Metadata, promptID) {
+				seen[trace.ID] = true
+				traces = append(traces, trace)
+			}
+		}
+		if len(resp.Content) < promptTracesPageSize {
+			break
+		}
+	}
+
+	spanParams := api.GetSpansByProjectParams{
+		ProjectName: api.NewOptString(c.projectName),
+		Filters:     api.NewOptString(string(filters)),
+		Size:        api.NewOptInt32(promptTracesPageSize),
+	}
+	for page := int32(1); ; page++ {
+		spanParams.Page = api.NewOptInt32(page)
+		resp, err := c.apiClient.GetSpansByProject(ctx, spanParams)
+		if err != nil {
+			return nil, err
+		}
+		for i := range resp.Content {
+			span := spanInfoFromAPI(&resp.Content[i])
+			if seen[span.TraceID] || !linksPrompt(span.Metadata, promptID) {
+				continue
+			}
+			seen[span.TraceID] = true
+			traceUUID, err := uuid.Parse(span.TraceID)
+			if err != nil {
+				return nil, err
+			}
+			trace, err := c.apiClient.GetTraceById(ctx, api.GetTraceByIdParams{ID: traceUUID})
+			if err != nil {
+				return nil, err
+			}
+			if trace != nil {
+				traces = append(traces, traceInfoFromAPI(trace))
+			}
+		}
+		if len(resp.Content) < promptTracesPageSize {
+			break
+		}
+	}
+
+	slices.SortStableFunc(traces, func(a, b *TraceInfo) int {
+		return b.StartTime.Compare(a.StartTime)
+	})
+	return traces, nil
+}
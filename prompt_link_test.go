@@ -0,0 +1,118 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestWithPromptVersion(t *testing.T) {
+	v1 := &PromptVersion{id: "version-1", promptID: "prompt-1", commit: "abc12345"}
+	v2 := &PromptVersion{id: "version-2", promptID: "prompt-2", commit: "def67890"}
+
+	md := map[string]any{"team": "search"}
+	traceOpts := defaultTraceOptions()
+	for _, opt := range []TraceOption{
+		WithTraceMetadata(md),
+		WithTracePromptVersion(v1),
+		WithTracePromptVersion(nil),
+		WithTracePromptVersion(v2),
+	} {
+		opt(traceOpts)
+	}
+	if _, ok := md[promptsMetadataKey]; ok {
+		t.Error("WithTracePromptVersion modified the metadata passed to WithTraceMetadata")
+	}
+
+	// Metadata is linked as it reads back from Opik.
+	data, err := json.Marshal(traceOpts.metadata)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	want := `{"opik_prompts":[{"id":"prompt-1","version":{"commit":"abc12345","id":"version-1"}},{"id":"prompt-2","version":{"commit":"def67890","id":"version-2"}}],"team":"search"}`
+	if string(data) != want {
+		t.Errorf("trace metadata = %s, want %s", data, want)
+	}
+	var decoded any
+	_ = json.Unmarshal(data, &decoded)
+	for _, id := range []string{"prompt-1", "version-2"} {
+		if !linksPrompt(decoded, id) {
+			t.Errorf("linksPrompt(%q) = false, want true", id)
+		}
+	}
+	if linksPrompt(decoded, "version-3") || linksPrompt(nil, "version-1") {
+		t.Error("linksPrompt matched an unlinked ID")
+	}
+
+	spanOpts := defaultSpanOptions()
+	WithSpanPromptVersion(v1)(spanOpts)
+	if links, _ := spanOpts.metadata[promptsMetadataKey].([]any); len(links) != 1 {
+		t.Errorf("span metadata = %v, want one prompt", spanOpts.metadata)
+	}
+}
+
+func TestGetTracesByPrompt(t *testing.T) {
+	versionID := uuid.Must(uuid.NewV7()).String()
+	linked := map[string]any{promptsMetadataKey: []any{map[string]any{
+		"id": "prompt-1", "version": map[string]any{"id": versionID, "commit": "abc12345"},
+	}}}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trace := func(id string, at time.Duration, md any) map[string]any {
+		return map[string]any{"id": id, "name": id, "start_time": base.Add(at), "metadata": md}
+	}
+	traceLinked := uuid.Must(uuid.NewV7()).String()
+	traceUnlinked := uuid.Must(uuid.NewV7()).String()
+	traceViaSpan := uuid.Must(uuid.NewV7()).String()
+
+	var filters []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/private/traces":
+			filters = append(filters, r.URL.Query().Get("filters"))
+			// Like a server that ignores filters, return every trace.
+			_ = json.NewEncoder(w).Encode(map[string]any{"content": []any{
+				trace(traceLinked, time.Minute, linked),
+				trace(traceUnlinked, 2*time.Minute, map[string]any{}),
+			}})
+		case "GET /v1/private/spans":
+			filters = append(filters, r.URL.Query().Get("filters"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"content": []any{
+				map[string]any{"id": uuid.Must(uuid.NewV7()).String(), "trace_id": traceLinked, "name": "llm", "start_time": base, "metadata": linked},
+				map[string]any{"id": uuid.Must(uuid.NewV7()).String(), "trace_id": traceViaSpan, "name": "llm", "start_time": base, "metadata": linked},
+			}})
+		case "GET /v1/private/traces/" + traceViaSpan:
+			_ = json.NewEncoder(w).Encode(trace(traceViaSpan, 3*time.Minute, nil))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	traces, err := client.GetTracesByPrompt(context.Background(), versionID)
+	if err != nil {
+		t.Fatalf("GetTracesByPrompt error: %v", err)
+	}
+	if len(traces) != 2 || traces[0].ID != traceViaSpan || traces[1].ID != traceLinked {
+		t.Errorf("traces = %+v, want the span's trace, then the linked trace", traces)
+	}
+	wantFilter := `[{"field":"metadata","operator":"contains","key":"opik_prompts","value":"` + versionID + `"}]`
+	if len(filters) != 2 || filters[0] != wantFilter || filters[1] != wantFilter {
+		t.Errorf("filters = %q, want %s for traces and spans", filters, wantFilter)
+	}
+
+	if _, err := client.GetTracesByPrompt(context.Background(), "summarize"); !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "summarize") {
+		t.Errorf("GetTracesByPrompt with a name error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,177 @@
+package opik
+
+import (
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RenderOption configures PromptVersion.RenderBatch.
+type RenderOption func(*renderOptions)
+
+type renderOptions struct {
+	concurrency int
+	defaultSet  bool
+	defaultVal  string
+}
+
+// WithRenderConcurrency sets how many goroutines render a batch. The
+// default is GOMAXPROCS.
+func WithRenderConcurrency(n int) RenderOption {
+	return func(o *renderOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithRenderDefault fills variables missing from a row with value instead
+// of failing the row.
+func WithRenderDefault(value string) RenderOption {
+	return func(o *renderOptions) {
+		o.defaultSet = true
+		o.defaultVal = value
+	}
+}
+
+// RenderBatch renders the template once for each set of variables, for
+// batch generation jobs. The template is parsed once for the batch, with
+// the engine of the version's prompt type, and the rows are rendered in
+// parallel. Values are inserted as they are, without substituting
+// placeholders they contain. If the template does not parse, RenderBatch
+// returns an error wrapping ErrInvalidInput and no prompts.
+//
+// A row missing a variable of the template fails unless
+// WithRenderDefault is given: its prompt is "", and the returned error is a
+// *BatchError listing the failed rows, each wrapping ErrInvalidInput. The
+// prompts of the other rows are rendered either way.
+func (v *PromptVersion) RenderBatch(varsList []map[string]string, opts ...RenderOption) ([]string, error) {
+	options := &renderOptions{concurrency: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.concurrency < 1 {
+		options.concurrency = 1
+	}
+
+	tmpl, err := parseTemplate(v.Type(), v.template)
+	if err != nil {
+		return nil, err
+	}
+	prompts := make([]string, len(varsList))
+	errs := make([]error, len(varsList))
+
+	workers := min(options.concurrency, len(varsList))
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := w; i < len(varsList); i += workers {
+				prompts[i], errs[i] = tmpl.execute(stringVars(varsList[i], options), true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	result := &BatchResult{}
+	for i, err := range errs {
+		if err != nil {
+			result.Failed = append(result.Failed, ItemError{Index: i, Err: err})
+		} else {
+			result.Succeeded++
+		}
+	}
+	return prompts, result.Err()
+}
+
+// compiledTemplate is a mustache template split into literal text and
+// placeholders.
+type compiledTemplate struct {
+	// literals has one more entry than names: the text before each
+	// placeholder, and the text after the last.
+	literals []string
+	names    []string
+	// placeholders are the placeholders as written, kept for missing
+	// variables when rendering leniently.
+	placeholders []string
+	size         int
+}
+
+// compileTemplate parses the {{variable}} placeholders of template.
+func compileTemplate(template string) *compiledTemplate {
+	t := &compiledTemplate{}
+	var literal strings.Builder
+	rest := template
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start+2:], "}}")
+		if end < 0 {
+			break
+		}
+		literal.WriteString(rest[:start])
+		name := rest[start+2 : start+2+end]
+		if strings.Contains(name, "}") || strings.TrimSpace(name) == "" {
+			// Not a placeholder ExtractVariables would find; keep it as text.
+			literal.WriteString("{{")
+			rest = rest[start+2:]
+			continue
+		}
+		t.literals = append(t.literals, literal.String())
+		t.names = append(t.names, strings.TrimSpace(name))
+		t.placeholders = append(t.placeholders, rest[start:start+2+end+2])
+		literal.Reset()
+		rest = rest[start+2+end+2:]
+	}
+	literal.WriteString(rest)
+	t.literals = append(t.literals, literal.String())
+	for _, l := range t.literals {
+		t.size += len(l)
+	}
+	return t
+}
+
+// execute renders the template with vars. Missing variables keep their
+// placeholders unless strict.
+func (t *compiledTemplate) execute(vars templateVars, strict bool) (string, error) {
+	var missing missingVariables
+	values := make([]string, len(t.names))
+	size := t.size
+	for i, name := range t.names {
+		value, ok := vars(name)
+		switch {
+		case ok:
+			values[i] = formatPromptValue(value)
+		case strict:
+			missing.add(name)
+			continue
+		default:
+			values[i] = t.placeholders[i]
+		}
+		size += len(values[i])
+	}
+	if err := missing.err(); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.Grow(size)
+	for i, value := range values {
+		b.WriteString(t.literals[i])
+		b.WriteString(value)
+	}
+	b.WriteString(t.literals[len(t.names)])
+	return b.String(), nil
+}
+
+func (t *compiledTemplate) variables() []string {
+	var names []string
+	for _, name := range t.names {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
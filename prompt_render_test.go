@@ -0,0 +1,111 @@
+package opik
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRenderBatch(t *testing.T) {
+	v := &PromptVersion{template: "Hello, {{ name }}! Welcome to {{place}}, {{name}}."}
+	prompts, err := v.RenderBatch([]map[string]string{
+		{"name": "Alice", "place": "Wonderland"},
+		{"name": "{{place}}", "place": "Oz"},
+	})
+	if err != nil {
+		t.Fatalf("RenderBatch error: %v", err)
+	}
+	want := []string{
+		"Hello, Alice! Welcome to Wonderland, Alice.",
+		"Hello, {{place}}! Welcome to Oz, {{place}}.",
+	}
+	for i := range want {
+		if prompts[i] != want[i] {
+			t.Errorf("prompts[%d] = %q, want %q", i, prompts[i], want[i])
+		}
+	}
+}
+
+func TestRenderBatchMissingVariables(t *testing.T) {
+	v := &PromptVersion{template: "{{greeting}}, {{name}}! Bye, {{name}}."}
+	prompts, err := v.RenderBatch([]map[string]string{
+		{"greeting": "Hi", "name": "Alice"},
+		{"greeting": "Hi"},
+		{},
+	})
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("error = %v, want a *BatchError", err)
+	}
+	if batchErr.Succeeded != 1 || len(batchErr.Failed) != 2 {
+		t.Fatalf("error = %v, want 2 failed rows", err)
+	}
+	if f := batchErr.Failed[1]; f.Index != 2 || !errors.Is(f.Err, ErrInvalidInput) ||
+		!strings.Contains(f.Err.Error(), "missing prompt variables greeting, name") {
+		t.Errorf("failed row = %d: %v", f.Index, f.Err)
+	}
+	if prompts[0] != "Hi, Alice! Bye, Alice." || prompts[1] != "" || prompts[2] != "" {
+		t.Errorf("prompts = %q", prompts)
+	}
+}
+
+func TestRenderBatchDefault(t *testing.T) {
+	v := &PromptVersion{template: "{{greeting}}, {{name}}!"}
+	prompts, err := v.RenderBatch([]map[string]string{{"greeting": "Hi"}}, WithRenderDefault("friend"))
+	if err != nil {
+		t.Fatalf("RenderBatch error: %v", err)
+	}
+	if prompts[0] != "Hi, friend!" {
+		t.Errorf("prompt = %q", prompts[0])
+	}
+}
+
+func TestRenderBatchConcurrency(t *testing.T) {
+	v := &PromptVersion{template: "Item {{n}}"}
+	varsList := make([]map[string]string, 1000)
+	for i := range varsList {
+		varsList[i] = map[string]string{"n": fmt.Sprint(i)}
+	}
+
+	for _, n := range []int{0, 1, 7, 5000} {
+		prompts, err := v.RenderBatch(varsList, WithRenderConcurrency(n))
+		if err != nil {
+			t.Fatalf("concurrency %d: %v", n, err)
+		}
+		for i, p := range prompts {
+			if p != fmt.Sprintf("Item %d", i) {
+				t.Fatalf("concurrency %d: prompts[%d] = %q", n, i, p)
+			}
+		}
+	}
+}
+
+func TestRenderBatchEmpty(t *testing.T) {
+	v := &PromptVersion{template: "Hello, {{name}}!"}
+	prompts, err := v.RenderBatch(nil)
+	if err != nil || len(prompts) != 0 {
+		t.Errorf("RenderBatch(nil) = %q, %v", prompts, err)
+	}
+}
+
+func TestCompileTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"no variables", "no variables"},
+		{"{{a}}{{b}}", "AB"},
+		{"unterminated {{a", "unterminated {{a"},
+		{"{{a}b}} and {{a}}", "{{a}b}} and A"},
+		{"blank {{ }} and {{a}}", "blank {{ }} and A"},
+	}
+	vars := map[string]string{"a": "A", "b": "B"}
+	for _, tt := range tests {
+		got, err := compileTemplate(tt.template).execute(stringVars(vars, nil), true)
+		if err != nil || got != tt.want {
+			t.Errorf("execute(%q) = %q, %v, want %q", tt.template, got, err, tt.want)
+		}
+	}
+}
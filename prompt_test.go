@@ -1,7 +1,13 @@
 package opik
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/plexusone/opik-go/evaluation/llm"
 )
 
 func TestPromptGetters(t *testing.T) {
@@ -212,6 +218,24 @@ func TestPromptVersionRender(t *testing.T) {
 			variables: nil,
 			want:      "Hello {{name}}",
 		},
+		{
+			name:      "spaces inside braces",
+			template:  "Hello, {{ name }}! Bye, {{name }}.",
+			variables: map[string]string{"name": "World"},
+			want:      "Hello, World! Bye, World.",
+		},
+		{
+			name:      "missing variable with spaces (kept as is)",
+			template:  "Hello, {{ name }}!",
+			variables: map[string]string{},
+			want:      "Hello, {{ name }}!",
+		},
+		{
+			name:      "values not substituted again",
+			template:  "{{a}} {{b}}",
+			variables: map[string]string{"a": "{{b}}", "b": "{{a}}"},
+			want:      "{{b}} {{a}}",
+		},
 	}
 
 	for _, tt := range tests {
@@ -221,6 +245,10 @@ func TestPromptVersionRender(t *testing.T) {
 			if got != tt.want {
 				t.Errorf("Render() = %q, want %q", got, tt.want)
 			}
+			// Rows with every variable render as they do in a batch.
+			if batch, err := v.RenderBatch([]map[string]string{tt.variables}); err == nil && batch[0] != got {
+				t.Errorf("RenderBatch() = %q, want %q as Render", batch[0], got)
+			}
 		})
 	}
 }
@@ -261,6 +289,13 @@ func TestPromptVersionRenderWithDefault(t *testing.T) {
 			defaultValue: "",
 			want:         "Hello, !",
 		},
+		{
+			name:         "spaces inside braces",
+			template:     "{{ greeting }}, {{ name }}!",
+			variables:    map[string]string{"greeting": "Hi"},
+			defaultValue: "???",
+			want:         "Hi, ???!",
+		},
 	}
 
 	for _, tt := range tests {
@@ -409,3 +444,34 @@ func TestPromptOptionsChaining(t *testing.T) {
 		t.Errorf("tags length = %d, want 2", len(opts.tags))
 	}
 }
+
+var _ llm.PromptLibrary = (*Client)(nil)
+
+func TestClientLoadPrompt(t *testing.T) {
+	var requested map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method+" "+r.URL.Path != "POST /v1/private/prompts/versions/retrieve" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&requested)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"commit": "abc12345", "template": "Judge {{output}}"})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(WithURL(server.URL), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	template, commit, err := client.LoadPrompt(context.Background(), "hallucination-judge", "abc12345")
+	if err != nil {
+		t.Fatalf("LoadPrompt error: %v", err)
+	}
+	if template != "Judge {{output}}" || commit != "abc12345" {
+		t.Errorf("LoadPrompt = %q, %q", template, commit)
+	}
+	if requested["name"] != "hallucination-judge" || requested["commit"] != "abc12345" {
+		t.Errorf("request = %v", requested)
+	}
+}
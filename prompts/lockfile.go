@@ -0,0 +1,193 @@
+package prompts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// LockfileVersion is the format version of lockfiles written by Lock.
+const LockfileVersion = 1
+
+// DefaultLockfile is the conventional name of a prompt lockfile.
+const DefaultLockfile = "prompts.lock.json"
+
+// ErrDrift is returned by LoadLocked and Lockfile.Load when the prompts in
+// Opik no longer match the lockfile.
+var ErrDrift = errors.New("prompts: lockfile drift")
+
+// Lockfile pins prompts to the commits a deployment was tested with, as a
+// dependency lockfile pins module versions. Create one with Lock, commit it
+// with the application, and load the pinned versions at startup with
+// LoadLocked, so a prompt edited in the Opik UI does not change a running
+// deployment until the lockfile is updated.
+type Lockfile struct {
+	// Version is the lockfile format version.
+	Version int `json:"version"`
+	// Prompts maps prompt names to their pinned versions.
+	Prompts map[string]LockedPrompt `json:"prompts"`
+}
+
+// LockedPrompt is the pinned version of one prompt.
+type LockedPrompt struct {
+	// Commit is the prompt version's commit.
+	Commit string `json:"commit"`
+	// VersionID is the prompt version's ID.
+	VersionID string `json:"version_id,omitempty"`
+	// TemplateSHA256 is the hex SHA-256 of the version's template, used to
+	// detect a version whose template has changed.
+	TemplateSHA256 string `json:"template_sha256"`
+}
+
+// Lock pins the latest version of each named prompt, or of every prompt in
+// the workspace if no names are given.
+func Lock(ctx context.Context, client *opik.Client, names ...string) (*Lockfile, error) {
+	if len(names) == 0 {
+		var err error
+		if names, err = promptNames(ctx, client); err != nil {
+			return nil, err
+		}
+	}
+	lock := &Lockfile{Version: LockfileVersion, Prompts: make(map[string]LockedPrompt, len(names))}
+	for _, name := range names {
+		version, err := client.GetPromptByName(ctx, name, "")
+		if err != nil {
+			return nil, fmt.Errorf("prompts: lock %s: %w", name, err)
+		}
+		lock.Prompts[name] = LockedPrompt{
+			Commit:         version.Commit(),
+			VersionID:      version.ID(),
+			TemplateSHA256: templateHash(version.Template()),
+		}
+	}
+	return lock, nil
+}
+
+// promptNames returns the names of all prompts in the workspace.
+func promptNames(ctx context.Context, client *opik.Client) ([]string, error) {
+	const pageSize = 100
+	var names []string
+	for page := 1; ; page++ {
+		list, err := client.ListPrompts(ctx, page, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("prompts: list prompts: %w", err)
+		}
+		for _, p := range list {
+			names = append(names, p.Name())
+		}
+		if len(list) < pageSize {
+			return names, nil
+		}
+	}
+}
+
+// ReadLockfile reads a lockfile written by Lockfile.WriteFile.
+func ReadLockfile(path string) (*Lockfile, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is chosen by the application
+	if err != nil {
+		return nil, err
+	}
+	var lock Lockfile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("prompts: parse lockfile %s: %w", path, err)
+	}
+	if lock.Version != LockfileVersion {
+		return nil, fmt.Errorf("prompts: lockfile %s has version %d, want %d", path, lock.Version, LockfileVersion)
+	}
+	return &lock, nil
+}
+
+// WriteFile writes the lockfile to path as indented JSON, with prompts in
+// name order so that changes diff cleanly in version control.
+func (l *Lockfile) WriteFile(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644) //nolint:gosec // G306: lockfiles are committed with the application
+}
+
+// LoadLocked reads the lockfile at lockPath and fetches exactly the pinned
+// prompt versions. It is meant to run at startup:
+//
+//	locked, err := prompts.LoadLocked(ctx, client, "prompts.lock.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	text := locked["support-agent"].Render(vars)
+//
+// If a pinned commit no longer exists or its template no longer matches
+// the lockfile, LoadLocked returns an error wrapping ErrDrift that lists
+// every drifted prompt, so a deployment fails fast rather than serving
+// prompts it was not tested with.
+func LoadLocked(ctx context.Context, client *opik.Client, lockPath string) (LockedPrompts, error) {
+	lock, err := ReadLockfile(lockPath)
+	if err != nil {
+		return nil, err
+	}
+	return lock.Load(ctx, client)
+}
+
+// Load fetches the pinned prompt versions, as described for LoadLocked.
+func (l *Lockfile) Load(ctx context.Context, client *opik.Client) (LockedPrompts, error) {
+	names := make([]string, 0, len(l.Prompts))
+	for name := range l.Prompts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	locked := make(LockedPrompts, len(names))
+	var drifted []string
+	for _, name := range names {
+		pin := l.Prompts[name]
+		version, err := client.GetPromptByName(ctx, name, pin.Commit)
+		switch {
+		case opik.IsNotFound(err):
+			drifted = append(drifted, fmt.Sprintf("%s@%s not found", name, pin.Commit))
+		case err != nil:
+			return nil, fmt.Errorf("prompts: load %s@%s: %w", name, pin.Commit, err)
+		case version.Commit() != pin.Commit:
+			drifted = append(drifted, fmt.Sprintf("%s@%s resolved to commit %s", name, pin.Commit, version.Commit()))
+		case pin.TemplateSHA256 != "" && templateHash(version.Template()) != pin.TemplateSHA256:
+			drifted = append(drifted, fmt.Sprintf("%s@%s template changed", name, pin.Commit))
+		default:
+			locked[name] = version
+		}
+	}
+	if len(drifted) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDrift, strings.Join(drifted, "; "))
+	}
+	return locked, nil
+}
+
+// LockedPrompts holds the prompt versions pinned by a lockfile, by name.
+type LockedPrompts map[string]*opik.PromptVersion
+
+// LoadPrompt returns the template and commit of a locked prompt. It makes
+// LockedPrompts an llm.PromptLibrary, so LLM judges load the pinned
+// versions with llm.WithJudgePromptLibrary. A commit other than the pinned
+// one, or a prompt that is not locked, is an error.
+func (p LockedPrompts) LoadPrompt(ctx context.Context, name, commit string) (template, loadedCommit string, err error) {
+	version, ok := p[name]
+	if !ok {
+		return "", "", fmt.Errorf("prompts: %s is not in the lockfile: %w", name, opik.ErrPromptNotFound)
+	}
+	if commit != "" && commit != version.Commit() {
+		return "", "", fmt.Errorf("%w: %s requested at commit %s, locked at %s", ErrDrift, name, commit, version.Commit())
+	}
+	return version.Template(), version.Commit(), nil
+}
+
+// templateHash returns the hex SHA-256 of a prompt template.
+func templateHash(template string) string {
+	sum := sha256.Sum256([]byte(template))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,165 @@
+package prompts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/testutil"
+)
+
+// promptServer serves prompt versions by name and commit. The last commit
+// listed for a prompt is its latest version.
+type promptServer struct {
+	*testutil.MockServer
+	mu       sync.Mutex
+	versions map[string][]promptVersion
+}
+
+type promptVersion struct {
+	Commit   string `json:"commit"`
+	Template string `json:"template"`
+}
+
+func newPromptServer(t *testing.T, versions map[string][]promptVersion) *promptServer {
+	t.Helper()
+	s := &promptServer{MockServer: testutil.NewMockServer(), versions: versions}
+	s.OnGet("/v1/private/prompts").WithHandler(func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var content []map[string]any
+		for name := range s.versions {
+			content = append(content, map[string]any{"name": name})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"content": content})
+	})
+	s.OnPost("/v1/private/prompts/versions/retrieve").WithHandler(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Name, Commit string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		versions := s.versions[req.Name]
+		for i := len(versions) - 1; i >= 0; i-- {
+			if req.Commit == "" || versions[i].Commit == req.Commit {
+				_ = json.NewEncoder(w).Encode(versions[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors": ["not found"]}`))
+	})
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *promptServer) set(name string, versions ...promptVersion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions[name] = versions
+}
+
+func TestLockfile(t *testing.T) {
+	s := newPromptServer(t, map[string][]promptVersion{
+		"support-agent": {{"aaaa1111", "v1 {{question}}"}, {"bbbb2222", "v2 {{question}}"}},
+		"summarizer":    {{"cccc3333", "Summarize {{text}}"}},
+	})
+	client, err := opik.NewClient(opik.WithURL(s.URL()), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	lock, err := Lock(ctx, client)
+	if err != nil {
+		t.Fatalf("Lock error: %v", err)
+	}
+	if len(lock.Prompts) != 2 || lock.Prompts["support-agent"].Commit != "bbbb2222" || lock.Prompts["summarizer"].Commit != "cccc3333" {
+		t.Fatalf("lock = %+v", lock)
+	}
+	if lock.Prompts["summarizer"].TemplateSHA256 != templateHash("Summarize {{text}}") {
+		t.Errorf("template hash = %s", lock.Prompts["summarizer"].TemplateSHA256)
+	}
+
+	path := filepath.Join(t.TempDir(), DefaultLockfile)
+	if err := lock.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	// A newer version does not change the locked deployment.
+	s.set("support-agent", promptVersion{"aaaa1111", "v1 {{question}}"}, promptVersion{"bbbb2222", "v2 {{question}}"},
+		promptVersion{"dddd4444", "v3 {{question}}"})
+	locked, err := LoadLocked(ctx, client, path)
+	if err != nil {
+		t.Fatalf("LoadLocked error: %v", err)
+	}
+	if got := locked["support-agent"]; got.Commit() != "bbbb2222" || got.Template() != "v2 {{question}}" {
+		t.Errorf("support-agent = %s %q", got.Commit(), got.Template())
+	}
+	template, commit, err := locked.LoadPrompt(ctx, "summarizer", "")
+	if err != nil || template != "Summarize {{text}}" || commit != "cccc3333" {
+		t.Errorf("LoadPrompt = %q, %q, %v", template, commit, err)
+	}
+	if _, _, err := locked.LoadPrompt(ctx, "summarizer", "eeee5555"); !errors.Is(err, ErrDrift) {
+		t.Errorf("LoadPrompt at another commit error = %v, want ErrDrift", err)
+	}
+	if _, _, err := locked.LoadPrompt(ctx, "unknown", ""); !errors.Is(err, opik.ErrPromptNotFound) {
+		t.Errorf("LoadPrompt unknown error = %v, want ErrPromptNotFound", err)
+	}
+
+	// Deleted and edited versions are drift, and every one is reported.
+	s.set("support-agent", promptVersion{"dddd4444", "v3 {{question}}"})
+	s.set("summarizer", promptVersion{"cccc3333", "Summarize briefly {{text}}"})
+	_, err = LoadLocked(ctx, client, path)
+	if !errors.Is(err, ErrDrift) {
+		t.Fatalf("LoadLocked error = %v, want ErrDrift", err)
+	}
+	for _, want := range []string{"support-agent@bbbb2222 not found", "summarizer@cccc3333 template changed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLockNamed(t *testing.T) {
+	s := newPromptServer(t, map[string][]promptVersion{
+		"support-agent": {{"aaaa1111", "v1"}},
+		"summarizer":    {{"cccc3333", "Summarize"}},
+	})
+	client, err := opik.NewClient(opik.WithURL(s.URL()), opik.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	lock, err := Lock(context.Background(), client, "summarizer")
+	if err != nil {
+		t.Fatalf("Lock error: %v", err)
+	}
+	if len(lock.Prompts) != 1 || lock.Prompts["summarizer"].Commit != "cccc3333" {
+		t.Errorf("lock = %+v", lock)
+	}
+	if _, err := Lock(context.Background(), client, "missing"); !opik.IsNotFound(err) {
+		t.Errorf("Lock missing error = %v, want not found", err)
+	}
+}
+
+func TestReadLockfile(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ReadLockfile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("ReadLockfile of a missing file should fail")
+	}
+	path := filepath.Join(dir, DefaultLockfile)
+	lock := &Lockfile{Version: 2, Prompts: map[string]LockedPrompt{}}
+	if err := lock.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	if _, err := ReadLockfile(path); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Errorf("ReadLockfile error = %v, want unsupported version", err)
+	}
+}
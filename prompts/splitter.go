@@ -0,0 +1,223 @@
+// Package prompts provides helpers for serving Opik prompts in production,
+// such as pinning the prompt versions of a deployment with a lockfile and
+// splitting traffic between two prompt versions for an online A/B
+// experiment.
+package prompts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"sync"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// Variant identifies one side of a prompt split.
+type Variant string
+
+// Prompt split variants.
+const (
+	VariantA Variant = "A"
+	VariantB Variant = "B"
+)
+
+// Metadata keys recorded on traces by a Splitter.
+const (
+	MetadataExperiment = "prompt_experiment"
+	MetadataVariant    = "prompt_variant"
+	MetadataVersionID  = "prompt_version_id"
+	MetadataCommit     = "prompt_commit"
+)
+
+// KeyFunc returns the key a request is assigned by, such as a user or
+// session ID. Requests with the same key always get the same variant.
+type KeyFunc func(ctx context.Context) string
+
+// Option configures a Splitter.
+type Option func(*Splitter)
+
+// WithName names the experiment. The name is recorded on traces and salts
+// the assignment hash, so experiments with different names split the same
+// users independently. Defaults to "prompt-split".
+func WithName(name string) Option {
+	return func(s *Splitter) {
+		s.name = name
+	}
+}
+
+// Splitter serves one of two prompt versions per user or session, for
+// online prompt experiments. Assignment is sticky: it hashes the key
+// returned by the KeyFunc, so a user sees the same variant across requests
+// and processes without any shared state. The splitter records the
+// assignment on the trace and aggregates feedback scores per variant.
+//
+// A Splitter is safe for concurrent use.
+type Splitter struct {
+	a, b  *opik.PromptVersion
+	ratio float64
+	key   KeyFunc
+	name  string
+
+	mu      sync.Mutex
+	results map[Variant]*VariantResult
+}
+
+// Assignment is the variant served for a request.
+type Assignment struct {
+	// Experiment is the splitter's name.
+	Experiment string
+	// Variant is A or B.
+	Variant Variant
+	// Prompt is the prompt version to render.
+	Prompt *opik.PromptVersion
+	// Key is the key the request was assigned by.
+	Key string
+}
+
+// Metadata returns the assignment as trace metadata, for traces created
+// after the assignment is made.
+func (a Assignment) Metadata() map[string]any {
+	return map[string]any{
+		MetadataExperiment: a.Experiment,
+		MetadataVariant:    string(a.Variant),
+		MetadataVersionID:  a.Prompt.ID(),
+		MetadataCommit:     a.Prompt.Commit(),
+	}
+}
+
+// VariantResult aggregates the requests and feedback of one variant.
+type VariantResult struct {
+	Variant   Variant
+	VersionID string
+	Commit    string
+	// Assignments is the number of requests served the variant.
+	Assignments int
+	// Feedback summarizes the recorded feedback scores by name.
+	Feedback map[string]FeedbackSummary
+}
+
+// FeedbackSummary summarizes the feedback scores with one name.
+type FeedbackSummary struct {
+	Count int
+	Sum   float64
+}
+
+// Mean returns the average score, or 0 if there are none.
+func (f FeedbackSummary) Mean() float64 {
+	if f.Count == 0 {
+		return 0
+	}
+	return f.Sum / float64(f.Count)
+}
+
+// NewSplitter creates a splitter that serves promptB to the fraction ratio
+// of keys and promptA to the rest. hashKeyFn returns the key to assign by;
+// requests with an empty key are served promptA.
+func NewSplitter(promptA, promptB *opik.PromptVersion, ratio float64, hashKeyFn KeyFunc, opts ...Option) (*Splitter, error) {
+	if promptA == nil || promptB == nil {
+		return nil, fmt.Errorf("%w: both prompt versions are required", opik.ErrInvalidInput)
+	}
+	if ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("%w: split ratio %g is outside [0, 1]", opik.ErrInvalidInput, ratio)
+	}
+	if hashKeyFn == nil {
+		return nil, fmt.Errorf("%w: key function is required", opik.ErrInvalidInput)
+	}
+
+	s := &Splitter{
+		a:     promptA,
+		b:     promptB,
+		ratio: ratio,
+		key:   hashKeyFn,
+		name:  "prompt-split",
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.results = map[Variant]*VariantResult{
+		VariantA: {Variant: VariantA, VersionID: promptA.ID(), Commit: promptA.Commit(), Feedback: map[string]FeedbackSummary{}},
+		VariantB: {Variant: VariantB, VersionID: promptB.ID(), Commit: promptB.Commit(), Feedback: map[string]FeedbackSummary{}},
+	}
+	return s, nil
+}
+
+// Name returns the experiment name.
+func (s *Splitter) Name() string {
+	return s.name
+}
+
+// Variant returns the variant for a key without recording anything.
+func (s *Splitter) Variant(key string) Variant {
+	if key == "" {
+		return VariantA
+	}
+	sum := sha256.Sum256([]byte(s.name + "\x00" + key))
+	// The top 53 bits give a uniform float64 in [0, 1).
+	if float64(binary.BigEndian.Uint64(sum[:8])>>11)/(1<<53) < s.ratio {
+		return VariantB
+	}
+	return VariantA
+}
+
+// Assign picks the variant for the request and counts it. If ctx carries a
+// trace (see opik.StartTrace), the assignment is recorded in the trace's
+// metadata; the returned error is from that update, and the assignment is
+// valid even if it fails.
+func (s *Splitter) Assign(ctx context.Context) (Assignment, error) {
+	key := s.key(ctx)
+	a := Assignment{Experiment: s.name, Variant: s.Variant(key), Prompt: s.a, Key: key}
+	if a.Variant == VariantB {
+		a.Prompt = s.b
+	}
+
+	s.mu.Lock()
+	s.results[a.Variant].Assignments++
+	s.mu.Unlock()
+
+	if trace := opik.TraceFromContext(ctx); trace != nil {
+		if err := trace.Update(ctx, opik.WithTraceMetadata(a.Metadata())); err != nil {
+			return a, fmt.Errorf("recording prompt assignment: %w", err)
+		}
+	}
+	return a, nil
+}
+
+// RecordFeedback adds a feedback score to the variant's results.
+func (s *Splitter) RecordFeedback(variant Variant, name string, value float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, ok := s.results[variant]
+	if !ok {
+		return
+	}
+	f := result.Feedback[name]
+	f.Count++
+	f.Sum += value
+	result.Feedback[name] = f
+}
+
+// AddFeedbackScore adds a feedback score to the trace of a request and to
+// its variant's results. trace may be nil to only aggregate the score.
+func (s *Splitter) AddFeedbackScore(ctx context.Context, trace *opik.Trace, a Assignment, name string, value float64, reason string) error {
+	s.RecordFeedback(a.Variant, name, value)
+	if trace == nil {
+		return nil
+	}
+	return trace.AddFeedbackScore(ctx, name, value, reason)
+}
+
+// Results returns a snapshot of the results of variants A and B.
+func (s *Splitter) Results() []VariantResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results := make([]VariantResult, 0, 2)
+	for _, v := range []Variant{VariantA, VariantB} {
+		r := *s.results[v]
+		r.Feedback = maps.Clone(r.Feedback)
+		results = append(results, r)
+	}
+	return results
+}
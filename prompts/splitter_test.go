@@ -0,0 +1,200 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	opik "github.com/plexusone/opik-go"
+	"github.com/plexusone/opik-go/testutil"
+)
+
+type userKey struct{}
+
+func userFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
+
+// newTestVersions returns two prompt versions served by ms.
+func newTestVersions(t *testing.T, ms *testutil.MockServer) (*opik.Client, *opik.PromptVersion, *opik.PromptVersion) {
+	t.Helper()
+	commits := []string{"aaaa1111", "bbbb2222"}
+	served := 0
+	ms.OnPost("/v1/private/prompts/versions/retrieve").WithHandler(func(w http.ResponseWriter, _ *http.Request) {
+		// The mock server has consumed the body; serve the commits in order.
+		commit := commits[served%len(commits)]
+		served++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id": %q, "prompt_id": %q, "commit": %q, "template": "Answer {{question}} (%s)"}`,
+			uuid.NewString(), uuid.NewString(), commit, commit)
+	})
+
+	client, err := opik.NewClient(opik.WithURL(ms.URL()), opik.WithAPIKey("test-key"), opik.WithProjectName("proj"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	a, err := client.GetPromptByName(context.Background(), "support-reply", "aaaa1111")
+	if err != nil {
+		t.Fatalf("GetPromptByName error: %v", err)
+	}
+	b, err := client.GetPromptByName(context.Background(), "support-reply", "bbbb2222")
+	if err != nil {
+		t.Fatalf("GetPromptByName error: %v", err)
+	}
+	return client, a, b
+}
+
+func TestSplitterAssignment(t *testing.T) {
+	ms := testutil.NewMockServer()
+	defer ms.Close()
+	_, a, b := newTestVersions(t, ms)
+
+	s, err := NewSplitter(a, b, 0.3, userFromContext, WithName("reply-tone"))
+	if err != nil {
+		t.Fatalf("NewSplitter error: %v", err)
+	}
+
+	counts := map[Variant]int{}
+	for i := range 2000 {
+		ctx := context.WithValue(context.Background(), userKey{}, fmt.Sprintf("user-%d", i))
+		first, err := s.Assign(ctx)
+		if err != nil {
+			t.Fatalf("Assign error: %v", err)
+		}
+		again, _ := s.Assign(ctx)
+		if first.Variant != again.Variant {
+			t.Fatalf("user-%d assigned %s then %s", i, first.Variant, again.Variant)
+		}
+		want := a
+		if first.Variant == VariantB {
+			want = b
+		}
+		if first.Prompt != want {
+			t.Fatalf("variant %s served the wrong prompt", first.Variant)
+		}
+		counts[first.Variant]++
+	}
+	if share := float64(counts[VariantB]) / 2000; math.Abs(share-0.3) > 0.05 {
+		t.Errorf("variant B share = %.3f, want about 0.3", share)
+	}
+
+	if v, _ := s.Assign(context.Background()); v.Variant != VariantA {
+		t.Errorf("empty key variant = %s, want A", v.Variant)
+	}
+
+	other, _ := NewSplitter(a, b, 0.3, userFromContext, WithName("reply-length"))
+	differ := 0
+	for i := range 200 {
+		key := fmt.Sprintf("user-%d", i)
+		if s.Variant(key) != other.Variant(key) {
+			differ++
+		}
+	}
+	if differ == 0 {
+		t.Error("experiments with different names should split independently")
+	}
+
+	results := s.Results()
+	if results[0].Assignments+results[1].Assignments != 4001 {
+		t.Errorf("assignments = %d + %d, want 4001", results[0].Assignments, results[1].Assignments)
+	}
+	if results[1].Commit != "bbbb2222" || results[1].VersionID != b.ID() {
+		t.Errorf("variant B result = %+v", results[1])
+	}
+}
+
+func TestSplitterRecordsAssignmentOnTrace(t *testing.T) {
+	ms := testutil.NewMockServer()
+	defer ms.Close()
+	ms.OnPost("/v1/private/traces/batch").Respond(http.StatusNoContent, nil)
+	ms.OnPatch("/v1/private/traces/batch").Respond(http.StatusNoContent, nil)
+	client, a, b := newTestVersions(t, ms)
+
+	s, err := NewSplitter(a, b, 1, userFromContext)
+	if err != nil {
+		t.Fatalf("NewSplitter error: %v", err)
+	}
+	ctx := context.WithValue(context.Background(), userKey{}, "user-1")
+	ctx, _, err = opik.StartTrace(ctx, client, "reply")
+	if err != nil {
+		t.Fatalf("StartTrace error: %v", err)
+	}
+	assignment, err := s.Assign(ctx)
+	if err != nil {
+		t.Fatalf("Assign error: %v", err)
+	}
+	if assignment.Variant != VariantB || assignment.Key != "user-1" {
+		t.Errorf("assignment = %+v, want variant B for user-1", assignment)
+	}
+
+	updates := ms.RequestsForPath("/v1/private/traces/batch")
+	last := updates[len(updates)-1]
+	if last.Method != http.MethodPatch {
+		t.Fatalf("last trace request = %s, want PATCH", last.Method)
+	}
+	for _, want := range []string{`"prompt_variant":"B"`, `"prompt_experiment":"prompt-split"`, `"prompt_commit":"bbbb2222"`} {
+		if !strings.Contains(string(last.Body), want) {
+			t.Errorf("trace update %s does not contain %s", last.Body, want)
+		}
+	}
+}
+
+func TestSplitterFeedback(t *testing.T) {
+	ms := testutil.NewMockServer()
+	defer ms.Close()
+	_, a, b := newTestVersions(t, ms)
+
+	s, err := NewSplitter(a, b, 0.5, userFromContext)
+	if err != nil {
+		t.Fatalf("NewSplitter error: %v", err)
+	}
+	ctx := context.Background()
+	if err := s.AddFeedbackScore(ctx, nil, Assignment{Variant: VariantA}, "thumbs_up", 1, ""); err != nil {
+		t.Fatalf("AddFeedbackScore error: %v", err)
+	}
+	s.RecordFeedback(VariantA, "thumbs_up", 0)
+	s.RecordFeedback(VariantB, "thumbs_up", 1)
+	s.RecordFeedback("C", "thumbs_up", 1)
+
+	results := s.Results()
+	if got := results[0].Feedback["thumbs_up"]; got.Count != 2 || got.Mean() != 0.5 {
+		t.Errorf("variant A feedback = %+v", got)
+	}
+	if got := results[1].Feedback["thumbs_up"]; got.Count != 1 || got.Mean() != 1 {
+		t.Errorf("variant B feedback = %+v", got)
+	}
+
+	results[0].Feedback["thumbs_up"] = FeedbackSummary{}
+	if s.Results()[0].Feedback["thumbs_up"].Count != 2 {
+		t.Error("Results should return a copy")
+	}
+}
+
+func TestNewSplitterValidation(t *testing.T) {
+	ms := testutil.NewMockServer()
+	defer ms.Close()
+	_, a, b := newTestVersions(t, ms)
+
+	tests := []struct {
+		name  string
+		a, b  *opik.PromptVersion
+		ratio float64
+		key   KeyFunc
+	}{
+		{"missing prompt", a, nil, 0.5, userFromContext},
+		{"negative ratio", a, b, -0.1, userFromContext},
+		{"ratio above one", a, b, 1.5, userFromContext},
+		{"missing key function", a, b, 0.5, nil},
+	}
+	for _, tt := range tests {
+		if _, err := NewSplitter(tt.a, tt.b, tt.ratio, tt.key); !errors.Is(err, opik.ErrInvalidInput) {
+			t.Errorf("%s: err = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package opik
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitStatus is the client's view of the server's rate limit, taken
+// from the responses it has received.
+type RateLimitStatus struct {
+	// Limit is the request quota of the current window, and Remaining what
+	// is left of it. Both are -1 if the server has not reported them.
+	Limit     int
+	Remaining int
+	// Reset is when the current window ends, or zero if unknown.
+	Reset time.Time
+	// Throttled is the number of 429 Too Many Requests responses received.
+	Throttled int
+	// LastThrottled is when the last 429 response was received.
+	LastThrottled time.Time
+	// RetryAfter is the wait the server asked for in its last 429 response.
+	RetryAfter time.Duration
+	// UpdatedAt is when the status last changed, or zero if no response has
+	// carried rate limit information.
+	UpdatedAt time.Time
+}
+
+// Wait returns how long to hold off before the next request at now: until
+// the Retry-After of the last 429 response has passed, or until the window
+// resets if the remaining quota is exhausted. It is 0 if requests may be
+// sent.
+func (s RateLimitStatus) Wait(now time.Time) time.Duration {
+	var wait time.Duration
+	if !s.LastThrottled.IsZero() {
+		wait = s.LastThrottled.Add(s.RetryAfter).Sub(now)
+	}
+	if s.Remaining == 0 && !s.Reset.IsZero() {
+		wait = max(wait, s.Reset.Sub(now))
+	}
+	return max(wait, 0)
+}
+
+// Limited reports whether requests are currently being throttled.
+func (s RateLimitStatus) Limited() bool {
+	return s.Wait(time.Now()) > 0
+}
+
+// RateLimitEvent describes a request rejected with 429 Too Many Requests.
+type RateLimitEvent struct {
+	Time   time.Time
+	Method string
+	Path   string
+	// Status is the rate limit status after the response.
+	Status RateLimitStatus
+}
+
+// RateLimitCallback is called for every request the server throttles.
+type RateLimitCallback func(RateLimitEvent)
+
+// WithRateLimitCallback calls fn for every request the server rejects with
+// 429 Too Many Requests, so throttling can be logged or exported as a
+// metric instead of surfacing only as slower writes. fn is called
+// synchronously from the request's goroutine and must not block.
+func WithRateLimitCallback(fn RateLimitCallback) Option {
+	return func(o *clientOptions) {
+		o.rateLimitCallback = fn
+	}
+}
+
+// RateLimit returns the client's current view of the server's rate limit.
+func (c *Client) RateLimit() RateLimitStatus {
+	return c.rateLimits.status()
+}
+
+// Rate limit headers. The RateLimit-* names are from the IETF draft and the
+// X-RateLimit-* names are the common legacy form.
+var (
+	rateLimitLimitHeaders     = []string{"RateLimit-Limit", "X-RateLimit-Limit"}
+	rateLimitRemainingHeaders = []string{"RateLimit-Remaining", "X-RateLimit-Remaining"}
+	rateLimitResetHeaders     = []string{"RateLimit-Reset", "X-RateLimit-Reset"}
+)
+
+// rateLimiter tracks the rate limit status reported by the server. A nil
+// rateLimiter tracks nothing.
+type rateLimiter struct {
+	mu       sync.Mutex
+	st       RateLimitStatus
+	callback RateLimitCallback
+}
+
+func newRateLimiter(callback RateLimitCallback) *rateLimiter {
+	return &rateLimiter{
+		st:       RateLimitStatus{Limit: -1, Remaining: -1},
+		callback: callback,
+	}
+}
+
+func (l *rateLimiter) status() RateLimitStatus {
+	if l == nil {
+		return RateLimitStatus{Limit: -1, Remaining: -1}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.st
+}
+
+// wait returns how long to hold off before the next request.
+func (l *rateLimiter) wait() time.Duration {
+	return l.status().Wait(time.Now())
+}
+
+// throttled returns the number of 429 responses received so far.
+func (l *rateLimiter) throttled() int {
+	return l.status().Throttled
+}
+
+// observe updates the status from a response.
+func (l *rateLimiter) observe(req *http.Request, resp *http.Response) {
+	if l == nil || resp == nil {
+		return
+	}
+	limit, hasLimit := headerInt(resp.Header, rateLimitLimitHeaders)
+	remaining, hasRemaining := headerInt(resp.Header, rateLimitRemainingHeaders)
+	reset, hasReset := headerInt(resp.Header, rateLimitResetHeaders)
+	throttled := resp.StatusCode == http.StatusTooManyRequests
+	if !hasLimit && !hasRemaining && !hasReset && !throttled {
+		return
+	}
+
+	now := time.Now()
+	l.mu.Lock()
+	if hasLimit {
+		l.st.Limit = limit
+	}
+	if hasRemaining {
+		l.st.Remaining = remaining
+	}
+	if hasReset {
+		l.st.Reset = resetTime(now, reset)
+	}
+	if throttled {
+		l.st.Throttled++
+		l.st.LastThrottled = now
+		l.st.RetryAfter = retryAfter(now, resp.Header.Get("Retry-After"))
+		if l.st.RetryAfter == 0 && hasReset {
+			l.st.RetryAfter = max(l.st.Reset.Sub(now), 0)
+		}
+	}
+	l.st.UpdatedAt = now
+	status := l.st
+	l.mu.Unlock()
+
+	if throttled && l.callback != nil {
+		l.callback(RateLimitEvent{Time: now, Method: req.Method, Path: req.URL.Path, Status: status})
+	}
+}
+
+// headerInt returns the first of the named headers that holds an integer.
+func headerInt(h http.Header, names []string) (int, bool) {
+	for _, name := range names {
+		if v := h.Get(name); v != "" {
+			if n, err := strconv.Atoi(v); err == nil {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// resetTime interprets a reset header, which servers send either as seconds
+// until the reset or as a Unix timestamp.
+func resetTime(now time.Time, v int) time.Time {
+	if v > 1_000_000_000 {
+		return time.Unix(int64(v), 0)
+	}
+	return now.Add(time.Duration(v) * time.Second)
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date.
+func retryAfter(now time.Time, v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
@@ -0,0 +1,164 @@
+package opik
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestClientRateLimit(t *testing.T) {
+	var throttle atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("RateLimit-Limit", "100")
+		if throttle.Load() {
+			w.Header().Set("RateLimit-Remaining", "0")
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("RateLimit-Remaining", "42")
+		w.Header().Set("RateLimit-Reset", "60")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	var events []RateLimitEvent
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"), WithRateLimitCallback(func(e RateLimitEvent) {
+		events = append(events, e)
+	}))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	if status := client.RateLimit(); status.Limit != -1 || status.Remaining != -1 || !status.UpdatedAt.IsZero() {
+		t.Errorf("initial status = %+v, want unknown", status)
+	}
+
+	if _, err := client.Trace(ctx, "ok"); err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	status := client.RateLimit()
+	if status.Limit != 100 || status.Remaining != 42 || status.Throttled != 0 || status.Limited() {
+		t.Errorf("status = %+v", status)
+	}
+	if until := time.Until(status.Reset); until < 50*time.Second || until > 60*time.Second {
+		t.Errorf("reset in %v, want about 60s", until)
+	}
+
+	throttle.Store(true)
+	if _, err := client.Trace(ctx, "throttled"); err == nil {
+		t.Fatal("expected Trace to fail when throttled")
+	}
+	status = client.RateLimit()
+	if status.Throttled != 1 || status.RetryAfter != 30*time.Second || status.Remaining != 0 || !status.Limited() {
+		t.Errorf("status = %+v", status)
+	}
+	if len(events) != 1 || events[0].Method != http.MethodPost || events[0].Path != "/v1/private/traces/batch" || events[0].Status.Throttled != 1 {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestRateLimitStatusWait(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		status RateLimitStatus
+		want   time.Duration
+	}{
+		{"unknown", RateLimitStatus{Limit: -1, Remaining: -1}, 0},
+		{"retry after", RateLimitStatus{Remaining: -1, LastThrottled: now.Add(-time.Second), RetryAfter: 3 * time.Second}, 2 * time.Second},
+		{"retry after passed", RateLimitStatus{Remaining: -1, LastThrottled: now.Add(-time.Minute), RetryAfter: time.Second}, 0},
+		{"quota exhausted", RateLimitStatus{Remaining: 0, Reset: now.Add(5 * time.Second)}, 5 * time.Second},
+		{"quota left", RateLimitStatus{Remaining: 3, Reset: now.Add(5 * time.Second)}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.status.Wait(now); got != tt.want {
+			t.Errorf("%s: Wait = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitHeaderParsing(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := retryAfter(now, "7"); got != 7*time.Second {
+		t.Errorf("retryAfter(7) = %v", got)
+	}
+	if got := retryAfter(now, now.Add(90*time.Second).Format(http.TimeFormat)); got != 90*time.Second {
+		t.Errorf("retryAfter(date) = %v", got)
+	}
+	if got := retryAfter(now, "soon"); got != 0 {
+		t.Errorf("retryAfter(soon) = %v", got)
+	}
+	if got := resetTime(now, 10); !got.Equal(now.Add(10 * time.Second)) {
+		t.Errorf("resetTime(10) = %v", got)
+	}
+	if got := resetTime(now, int(now.Unix())+20); !got.Equal(now.Add(20 * time.Second)) {
+		t.Errorf("resetTime(unix) = %v", got)
+	}
+
+	h := http.Header{}
+	h.Set("X-RateLimit-Remaining", "9")
+	if n, ok := headerInt(h, rateLimitRemainingHeaders); !ok || n != 9 {
+		t.Errorf("headerInt = %d, %v; want the legacy header", n, ok)
+	}
+}
+
+func TestBatcherAdaptsFlushInterval(t *testing.T) {
+	var throttle atomic.Bool
+	throttle.Store(true)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if throttle.Load() {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	client.batchConfig = testBatchConfig()
+
+	config := DefaultBatcherConfig()
+	config.FlushInterval = time.Hour
+	config.MaxFlushInterval = 3 * time.Hour
+	config.MaxRetries = 1
+	batcher := NewBatcher(client, config)
+	defer batcher.Close(time.Second)
+
+	flush := func() {
+		t.Helper()
+		batcher.Add(FeedbackBatchItem{EntityType: "trace", EntityID: uuid.NewString(), Name: "score", Value: 1})
+		if err := batcher.Flush(5 * time.Second); err != nil {
+			t.Fatalf("Flush error: %v", err)
+		}
+	}
+
+	flush()
+	if got := batcher.FlushInterval(); got != 2*time.Hour {
+		t.Errorf("interval after throttled flush = %v, want 2h", got)
+	}
+	flush()
+	if got := batcher.FlushInterval(); got != 3*time.Hour {
+		t.Errorf("interval = %v, want capped at 3h", got)
+	}
+
+	throttle.Store(false)
+	flush()
+	if got := batcher.FlushInterval(); got != 90*time.Minute {
+		t.Errorf("interval after a clean flush = %v, want 90m", got)
+	}
+	flush()
+	if got := batcher.FlushInterval(); got != time.Hour {
+		t.Errorf("interval = %v, want back at 1h", got)
+	}
+}
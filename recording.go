@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/metadata"
 )
 
 // RecordedTrace represents a trace captured during local recording.
@@ -37,6 +39,7 @@ type RecordedSpan struct {
 	Tags         []string
 	Model        string
 	Provider     string
+	Usage        map[string]int
 	Children     []*RecordedSpan
 	Feedback     []*RecordedFeedback
 }
@@ -316,7 +319,8 @@ func (s *RecordingSpan) Name() string {
 	return s.span.Name
 }
 
-// End ends the span.
+// End ends the span. Metadata given at the end is added to the span's,
+// and a model or provider replaces the span's.
 func (s *RecordingSpan) End(ctx context.Context, opts ...SpanOption) error {
 	options := &spanOptions{}
 	for _, opt := range opts {
@@ -327,10 +331,25 @@ func (s *RecordingSpan) End(ctx context.Context, opts ...SpanOption) error {
 	if options.output != nil {
 		s.span.Output = options.output
 	}
+	if len(options.metadata) > 0 && s.span.Metadata == nil {
+		s.span.Metadata = make(map[string]any, len(options.metadata))
+	}
+	metadata.Metadata(s.span.Metadata).Merge(options.metadata)
+	if options.model != "" {
+		s.span.Model = options.model
+	}
+	if options.provider != "" {
+		s.span.Provider = options.provider
+	}
 
 	return nil
 }
 
+// SetUsage sets LLM usage metrics for this span, as Span.SetUsage does.
+func (s *RecordingSpan) SetUsage(usage map[string]int) {
+	s.span.Usage = usage
+}
+
 // Span creates a child span.
 func (s *RecordingSpan) Span(ctx context.Context, name string, opts ...SpanOption) (*RecordingSpan, error) {
 	options := &spanOptions{
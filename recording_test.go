@@ -332,6 +332,27 @@ func TestRecordingSpanEnd(t *testing.T) {
 	}
 }
 
+func TestRecordingSpanEndModelAndUsage(t *testing.T) {
+	ctx := context.Background()
+	client := NewRecordingClient("test-project")
+
+	trace, _ := client.Trace(ctx, "my-trace")
+	span, _ := trace.Span(ctx, "llm", WithSpanMetadata(map[string]any{"attempt": 1}))
+	span.SetUsage(map[string]int{UsageTotalTokens: 42})
+	_ = span.End(ctx, WithSpanModel("gpt-4o"), WithSpanProvider("openai"), WithSpanMetadata(map[string]any{"cached": false}))
+
+	recorded := client.Recording().GetSpan(span.ID())
+	if recorded.Model != "gpt-4o" || recorded.Provider != "openai" {
+		t.Errorf("Model, Provider = %q, %q; want gpt-4o, openai", recorded.Model, recorded.Provider)
+	}
+	if recorded.Usage[UsageTotalTokens] != 42 {
+		t.Errorf("Usage = %v", recorded.Usage)
+	}
+	if recorded.Metadata["attempt"] != 1 || recorded.Metadata["cached"] != false {
+		t.Errorf("Metadata = %v, want both keys", recorded.Metadata)
+	}
+}
+
 func TestRecordingSpanChildSpan(t *testing.T) {
 	ctx := context.Background()
 	client := NewRecordingClient("test-project")
@@ -0,0 +1,208 @@
+package opik
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/plexusone/opik-go/internal/pii"
+)
+
+// Replacements written by the built-in redactors.
+const (
+	RedactedEmail      = "[REDACTED_EMAIL]"
+	RedactedPhone      = "[REDACTED_PHONE]"
+	RedactedCreditCard = "[REDACTED_CREDIT_CARD]"
+	RedactedAPIKey     = "[REDACTED_API_KEY]"
+)
+
+// WithRedactor adds redactors to the client's redaction pipeline, which is
+// applied to the input, output, metadata, and error message of every trace
+// and span before they are sent to Opik. Redactors run in the order they are added,
+// after any redactor set on the trace or span itself:
+//
+//	client, err := opik.NewClient(
+//	    opik.WithRedactor(opik.RedactCreditCards, opik.RedactPhoneNumbers),
+//	    opik.WithRedactor(opik.RedactEmails, opik.RedactAPIKeys),
+//	)
+//
+// Unlike WithTraceRedactor and WithSpanRedactor, the pipeline cannot be
+// overridden at a call site, so a compliance policy set where the client is
+// created holds for all instrumentation, including integrations.
+func WithRedactor(fns ...RedactFunc) Option {
+	return func(o *clientOptions) {
+		for _, fn := range fns {
+			if fn == nil {
+				o.problems.Addf("redactor must not be nil")
+				continue
+			}
+			o.redactors = append(o.redactors, fn)
+		}
+	}
+}
+
+// Redact returns value after the client's redaction pipeline. It returns
+// value unchanged if the client has no redactors.
+func (c *Client) Redact(value any) any {
+	if c == nil {
+		return value
+	}
+	for _, fn := range c.redactors {
+		value = redact(fn, value)
+	}
+	return value
+}
+
+// RedactString returns s after the client's redaction pipeline, as applied
+// to error messages and tracebacks. Integrations that build error info
+// themselves use it to honor the client's redactors.
+func (c *Client) RedactString(s string) string {
+	return c.redactString(nil, s)
+}
+
+// redactString applies fn, then the client's redaction pipeline, to s. A
+// redactor that turns the string into another value, which a custom one
+// may, leaves its text form.
+func (c *Client) redactString(fn RedactFunc, s string) string {
+	switch v := c.Redact(redact(fn, s)).(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// RedactJSON returns the JSON payload data after the client's redaction
+// pipeline. Integrations that build API writes themselves use it to honor
+// the client's redactors. It returns data unchanged if the client has no
+// redactors or data is not valid JSON.
+func (c *Client) RedactJSON(data []byte) []byte {
+	if c == nil || len(c.redactors) == 0 {
+		return data
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return data
+	}
+	redacted, err := json.Marshal(c.Redact(value))
+	if err != nil {
+		return data
+	}
+	return redacted
+}
+
+// marshalMetadata returns the JSON of trace or span metadata after the
+// client's redaction pipeline.
+func (c *Client) marshalMetadata(metadata map[string]any) []byte {
+	data, _ := json.Marshal(c.Redact(metadata))
+	return data
+}
+
+// RedactStrings returns a redactor that applies replace to every string in
+// a value, including map keys and values and slice elements. Keys that are
+// the same after replace are merged, keeping one of their values. Values of other types,
+// such as structs, are redacted in their JSON form, so the result may be a
+// map rather than the original type. Use it to build custom redactors:
+//
+//	maskIDs := opik.RedactStrings(func(s string) string {
+//	    return employeeID.ReplaceAllString(s, "[EMPLOYEE_ID]")
+//	})
+func RedactStrings(replace func(string) string) RedactFunc {
+	return func(value any) any {
+		return redactStrings(value, replace)
+	}
+}
+
+// redactStrings applies replace to the strings in value, including map
+// keys, copying maps and slices rather than modifying them.
+func redactStrings(value any, replace func(string) string) any {
+	switch v := value.(type) {
+	case nil, bool, int, int64, float64, json.Number:
+		return v
+	case string:
+		return replace(v)
+	case []string:
+		out := make([]string, len(v))
+		for i, s := range v {
+			out[i] = replace(s)
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, s := range v {
+			out[replace(k)] = replace(s)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = redactStrings(e, replace)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[replace(k)] = redactStrings(e, replace)
+		}
+		return out
+	}
+
+	// Decoded JSON holds only the types above, so this recurses once.
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return value
+	}
+	return redactStrings(generic, replace)
+}
+
+// redactPattern returns a redactor that replaces the matches of pattern
+// for which keep returns false, or all matches if keep is nil.
+func redactPattern(pattern *regexp.Regexp, replacement string, keep func(string) bool) RedactFunc {
+	return RedactStrings(func(s string) string {
+		return pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if keep != nil && keep(match) {
+				return match
+			}
+			return replacement
+		})
+	})
+}
+
+var (
+	emailRedactor      = redactPattern(pii.Email, RedactedEmail, nil)
+	phoneRedactor      = redactPattern(pii.Phone, RedactedPhone, nil)
+	creditCardRedactor = redactPattern(pii.CreditCard, RedactedCreditCard, func(match string) bool { return !pii.Luhn(match) })
+	apiKeyRedactor     = redactPattern(pii.APIKey, RedactedAPIKey, nil)
+)
+
+// RedactEmails is a redactor that replaces email addresses with
+// RedactedEmail.
+func RedactEmails(value any) any {
+	return emailRedactor(value)
+}
+
+// RedactPhoneNumbers is a redactor that replaces phone numbers, in North
+// American formats with an optional country code, with RedactedPhone.
+func RedactPhoneNumbers(value any) any {
+	return phoneRedactor(value)
+}
+
+// RedactCreditCards is a redactor that replaces credit card numbers with
+// RedactedCreditCard. Only numbers that pass the Luhn check are replaced,
+// so order and tracking numbers of the same length are kept.
+func RedactCreditCards(value any) any {
+	return creditCardRedactor(value)
+}
+
+// RedactAPIKeys is a redactor that replaces API keys and tokens in the
+// formats of common providers, such as OpenAI, Anthropic, AWS, GitHub,
+// Slack, and Google, with RedactedAPIKey.
+func RedactAPIKeys(value any) any {
+	return apiKeyRedactor(value)
+}
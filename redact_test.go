@@ -0,0 +1,145 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinRedactors(t *testing.T) {
+	tests := []struct {
+		name   string
+		redact RedactFunc
+		in     string
+		want   string
+	}{
+		{"email", RedactEmails, "write to ana.silva@example.com today", "write to [REDACTED_EMAIL] today"},
+		{"phone", RedactPhoneNumbers, "call +1 (555) 123-4567 or 555.987.6543", "call [REDACTED_PHONE] or [REDACTED_PHONE]"},
+		{"credit card", RedactCreditCards, "card 4111 1111 1111 1111, order 1234567890123", "card [REDACTED_CREDIT_CARD], order 1234567890123"},
+		{"api key", RedactAPIKeys, "OPENAI_API_KEY=sk-proj-abcdefghijklmnopqrstuvwxyz", "OPENAI_API_KEY=[REDACTED_API_KEY]"},
+		{"no match", RedactEmails, "nothing to see", "nothing to see"},
+	}
+	for _, tt := range tests {
+		if got := tt.redact(tt.in); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRedactStrings(t *testing.T) {
+	upper := RedactStrings(strings.ToUpper)
+
+	in := map[string]any{
+		"text":   "hi",
+		"count":  3,
+		"nested": []any{"a", map[string]any{"b": "c"}, true},
+		"tags":   []string{"x"},
+		"labels": map[string]string{"k": "v"},
+	}
+	// Keys are redacted too, since they may hold the data being redacted.
+	want := map[string]any{
+		"TEXT":   "HI",
+		"COUNT":  3,
+		"NESTED": []any{"A", map[string]any{"B": "C"}, true},
+		"TAGS":   []string{"X"},
+		"LABELS": map[string]string{"K": "V"},
+	}
+	if got := upper(in); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if in["text"] != "hi" || in["nested"].([]any)[0] != "a" {
+		t.Errorf("input modified: %v", in)
+	}
+
+	// Structs are redacted in their JSON form.
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	got := upper([]message{{Role: "user", Content: "hello"}})
+	wantMessages := []any{map[string]any{"ROLE": "USER", "CONTENT": "HELLO"}}
+	if !reflect.DeepEqual(got, wantMessages) {
+		t.Errorf("got %v, want %v", got, wantMessages)
+	}
+}
+
+func TestWithRedactor(t *testing.T) {
+	ts, created := newCreateServer()
+	defer ts.Close()
+
+	var order []string
+	record := func(name string) RedactFunc {
+		return func(value any) any {
+			order = append(order, name)
+			return value
+		}
+	}
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"),
+		WithRedactor(RedactEmails, RedactAPIKeys), WithRedactor(record("client")))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	trace, err := client.Trace(ctx, "request",
+		WithTraceInput(map[string]any{"question": "my email is ana@example.com"}),
+		WithTraceMetadata(map[string]any{"api_key": "sk-abcdefghijklmnopqrstuvwxyz"}),
+		WithTraceRedactor(record("trace")),
+	)
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	got := created("POST /v1/private/traces/batch")
+	if got.Input.(map[string]any)["question"] != "my email is [REDACTED_EMAIL]" {
+		t.Errorf("trace input = %v", got.Input)
+	}
+	if got.Metadata["api_key"] != RedactedAPIKey {
+		t.Errorf("trace metadata = %v", got.Metadata)
+	}
+	// The trace's own redactor runs first, on the input only.
+	if want := []string{"trace", "client", "client"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("redactor order = %v, want %v", order, want)
+	}
+
+	span, err := trace.Span(ctx, "generate")
+	if err != nil {
+		t.Fatalf("Span error: %v", err)
+	}
+	if err := span.End(ctx, WithSpanOutput("reach me at bo@example.org"),
+		WithSpanError(errors.New("invalid key sk-abcdefghijklmnopqrstuvwxyz for bo@example.org"))); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+	ended := created("PATCH /v1/private/spans/batch")
+	if ended.Output != "reach me at "+RedactedEmail {
+		t.Errorf("span output = %v", ended.Output)
+	}
+	if ended.ErrorInfo == nil || ended.ErrorInfo.Message != "invalid key "+RedactedAPIKey+" for "+RedactedEmail {
+		t.Errorf("span error info = %+v, want the message redacted", ended.ErrorInfo)
+	}
+	if err := trace.End(ctx, WithTraceMetadata(map[string]any{"ana@example.com": true})); err != nil {
+		t.Fatalf("trace End error: %v", err)
+	}
+	if md := created("PATCH /v1/private/traces/batch").Metadata; md[RedactedEmail] != true || md["ana@example.com"] != nil {
+		t.Errorf("trace metadata = %v, want the key redacted", md)
+	}
+	if got := client.RedactString("ana@example.com"); got != RedactedEmail {
+		t.Errorf("RedactString = %q", got)
+	}
+
+	if got := string(client.RedactJSON([]byte(`{"to":"ana@example.com"}`))); got != `{"to":"[REDACTED_EMAIL]"}` {
+		t.Errorf("RedactJSON = %s", got)
+	}
+	if got := string(client.RedactJSON([]byte(`not json`))); got != "not json" {
+		t.Errorf("RedactJSON = %s, want data unchanged", got)
+	}
+}
+
+func TestWithRedactorNil(t *testing.T) {
+	_, err := NewClient(WithURL("http://localhost"), WithAPIKey("test-key"), WithRedactor(nil))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("NewClient error = %v, want ValidationError", err)
+	}
+}
@@ -0,0 +1,147 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/plexusone/opik-go/internal/backoff"
+)
+
+// RetryPolicy configures how the client retries a request that fails with
+// 429 Too Many Requests, a 500, 502, 503, or 504 status, or a connection
+// error. Waits grow exponentially from InitialBackoff, and a Retry-After
+// header from the server is honored.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. 0
+	// disables retries.
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry. It doubles for
+	// each further retry, up to MaxBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponential backoff.
+	MaxBackoff time.Duration
+	// Jitter shortens each backoff by a random fraction of up to Jitter, so
+	// clients throttled together do not all retry together. It is between
+	// 0 and 1.
+	Jitter float64
+	// MaxRetryAfter is the longest Retry-After the client waits for. If the
+	// server asks for a longer wait, the response is returned as is. 0
+	// means no limit other than the request's context.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy returns a policy of 4 retries with backoff from 500ms to
+// 30s, honoring a Retry-After of up to a minute.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Jitter:         0.2,
+		MaxRetryAfter:  time.Minute,
+	}
+}
+
+// WithRetryPolicy makes the client retry requests that the server throttles
+// or fails with a transient error, so a long-running job survives a brief
+// rate limit or outage. Retries stop when the request's context is done.
+// Without this option, requests are not retried by the HTTP layer, and
+// batch operations retry their failed batches themselves; with it, batches
+// are not retried again for the failures it retries.
+//
+// A request is only retried if its body can be sent again, which is the
+// case for every request the client makes.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *clientOptions) {
+		if policy.MaxRetries < 0 {
+			o.problems.Addf("retry MaxRetries must not be negative: %d", policy.MaxRetries)
+		}
+		if policy.MaxRetries > 0 && policy.InitialBackoff <= 0 {
+			o.problems.Addf("retry InitialBackoff must be positive: %v", policy.InitialBackoff)
+		}
+		if policy.MaxBackoff < policy.InitialBackoff {
+			o.problems.Addf("retry MaxBackoff %v is below InitialBackoff %v", policy.MaxBackoff, policy.InitialBackoff)
+		}
+		if policy.Jitter < 0 || policy.Jitter > 1 {
+			o.problems.Addf("retry Jitter must be between 0 and 1: %v", policy.Jitter)
+		}
+		if policy.MaxRetryAfter < 0 {
+			o.problems.Addf("retry MaxRetryAfter must not be negative: %v", policy.MaxRetryAfter)
+		}
+		o.retryPolicy = policy
+	}
+}
+
+// backoff returns the wait before retry number attempt, counting from 0.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	return backoff.Exponential(p.InitialBackoff, p.MaxBackoff, p.Jitter, attempt)
+}
+
+// retryableStatus reports whether a response with status code may succeed
+// if the request is sent again.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// send sends req, retrying it as the retry policy allows.
+func (c *authHTTPClient) send(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.client.Do(req) //nolint:gosec // G704: URL is configured by SDK user
+		c.rateLimits.observe(req, resp)
+
+		wait, retry := c.retryWait(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req.Body = body
+		}
+		if resp != nil {
+			// Drain the body so the connection can be reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+		}
+
+		if err := backoff.Sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryWait returns how long to wait before sending req again, and whether
+// it should be sent again at all.
+func (c *authHTTPClient) retryWait(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	policy := c.retry
+	if attempt >= policy.MaxRetries || req.Context().Err() != nil {
+		return 0, false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return 0, false
+	}
+	if err != nil {
+		return policy.backoff(attempt), !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	if !retryableStatus(resp.StatusCode) {
+		return 0, false
+	}
+	wait := policy.backoff(attempt)
+	if after := retryAfter(time.Now(), resp.Header.Get("Retry-After")); after > 0 {
+		if policy.MaxRetryAfter > 0 && after > policy.MaxRetryAfter {
+			return 0, false
+		}
+		wait = max(wait, after)
+	}
+	return wait, true
+}
@@ -0,0 +1,143 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+// newFlakyServer fails the first failures requests with status.
+func newFlakyServer(failures, status int) *testutil.MockServer {
+	ms := testutil.NewMockServer()
+	ms.OnUnmatched().Respond(http.StatusNoContent, nil)
+	ms.InjectFaults(testutil.Burst(0, failures, testutil.Fault{Status: status}))
+	return ms
+}
+
+func fastRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+func TestRetryPolicyRetriesTransientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusBadGateway} {
+		ts := newFlakyServer(2, status)
+		client, err := NewClient(WithURL(ts.URL()), WithRetryPolicy(fastRetryPolicy()))
+		if err != nil {
+			t.Fatalf("NewClient error: %v", err)
+		}
+		if _, err := client.Trace(context.Background(), "t"); err != nil {
+			t.Errorf("status %d: Trace error = %v, want success after retries", status, err)
+		}
+		if got := ts.RequestCount(); got != 3 {
+			t.Errorf("status %d: got %d requests, want 3", status, got)
+		}
+		ts.Close()
+	}
+}
+
+func TestRetryPolicyGivesUp(t *testing.T) {
+	ts := newFlakyServer(10, http.StatusServiceUnavailable)
+	defer ts.Close()
+	client, _ := NewClient(WithURL(ts.URL()), WithRetryPolicy(fastRetryPolicy()))
+	if _, err := client.Trace(context.Background(), "t"); err == nil {
+		t.Error("Trace succeeded, want the last error")
+	}
+	if got := ts.RequestCount(); got != 4 {
+		t.Errorf("got %d requests, want 1 + 3 retries", got)
+	}
+
+	// Client errors are not retried, and neither is anything without a policy.
+	for _, tc := range []struct {
+		status int
+		opts   []Option
+	}{
+		{http.StatusBadRequest, []Option{WithRetryPolicy(fastRetryPolicy())}},
+		{http.StatusServiceUnavailable, nil},
+	} {
+		ts := newFlakyServer(10, tc.status)
+		client, _ := NewClient(append([]Option{WithURL(ts.URL())}, tc.opts...)...)
+		_, _ = client.Trace(context.Background(), "t")
+		if got := ts.RequestCount(); got != 1 {
+			t.Errorf("status %d: got %d requests, want 1", tc.status, got)
+		}
+		ts.Close()
+	}
+}
+
+func TestRetryPolicyUnderFaults(t *testing.T) {
+	ms := testutil.NewMockServer()
+	defer ms.Close()
+	ms.OnPost("/v1/private/traces/batch").Respond(http.StatusNoContent, nil)
+	// A burst of throttling, then a dropped connection.
+	transport := testutil.NewFaultyTransport(nil,
+		testutil.Burst(0, 2, testutil.Fault{Status: http.StatusTooManyRequests}),
+		testutil.Burst(2, 1, testutil.Fault{Reset: true, Latency: time.Millisecond}),
+	)
+
+	client, err := NewClient(WithURL(ms.URL()), WithRetryPolicy(fastRetryPolicy()),
+		WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if _, err := client.Trace(context.Background(), "t"); err != nil {
+		t.Errorf("Trace error = %v, want success after retries", err)
+	}
+	if got := transport.InjectedFaults(); got != 3 {
+		t.Errorf("InjectedFaults = %d, want 3", got)
+	}
+	if got := ms.RequestCount(); got != 1 {
+		t.Errorf("server got %d requests, want 1", got)
+	}
+}
+
+func TestRetryWait(t *testing.T) {
+	c := &authHTTPClient{retry: RetryPolicy{MaxRetries: 2, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second, MaxRetryAfter: time.Minute}}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	respond := func(status int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+
+	for _, tc := range []struct {
+		name     string
+		resp     *http.Response
+		err      error
+		attempt  int
+		want     time.Duration
+		wantNext bool
+	}{
+		{"backoff doubles", respond(503, ""), nil, 1, 2 * time.Second, true},
+		{"retries exhausted", respond(503, ""), nil, 2, 0, false},
+		{"retry-after honored", respond(429, "10"), nil, 0, 10 * time.Second, true},
+		{"retry-after too long", respond(429, "600"), nil, 0, 0, false},
+		{"not retryable", respond(404, ""), nil, 0, 0, false},
+		{"connection error", nil, errors.New("connection reset"), 0, time.Second, true},
+		{"canceled", nil, context.Canceled, 0, 0, false},
+	} {
+		wait, next := c.retryWait(req, tc.resp, tc.err, tc.attempt)
+		if next != tc.wantNext || (next && wait != tc.want) {
+			t.Errorf("%s: retryWait = %v, %v, want %v, %v", tc.name, wait, next, tc.want, tc.wantNext)
+		}
+	}
+
+	c.retry.MaxRetries = 10
+	if wait, _ := c.retryWait(req, respond(503, ""), nil, 8); wait != 3*time.Second {
+		t.Errorf("backoff = %v, want MaxBackoff", wait)
+	}
+}
+
+func TestWithRetryPolicyValidation(t *testing.T) {
+	_, err := NewClient(WithURL("http://localhost"), WithRetryPolicy(RetryPolicy{MaxRetries: 1, MaxBackoff: -1, Jitter: 2}))
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Problems) != 3 {
+		t.Errorf("NewClient error = %v, want 3 problems", err)
+	}
+}
@@ -0,0 +1,358 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// SamplingDecision is a Sampler's decision for a new trace.
+type SamplingDecision int
+
+const (
+	// SamplingDrop drops the trace: it and its spans are inert, as those of
+	// NoopTracer, and nothing is sent.
+	SamplingDrop SamplingDecision = iota
+	// SamplingKeep sends the trace and its spans.
+	SamplingKeep
+	// SamplingKeepOnError holds the trace and its spans in memory until one
+	// of them records an error with WithTraceError or WithSpanError, when
+	// they are sent, or the trace ends without one, when they are dropped.
+	SamplingKeepOnError
+)
+
+// String returns the decision's name.
+func (d SamplingDecision) String() string {
+	switch d {
+	case SamplingDrop:
+		return "drop"
+	case SamplingKeep:
+		return "keep"
+	case SamplingKeepOnError:
+		return "keep_on_error"
+	}
+	return "unknown"
+}
+
+// SamplingParams describes a trace being created, for a Sampler.
+type SamplingParams struct {
+	// Name is the trace name.
+	Name string
+	// ProjectName is the project the trace would be sent to.
+	ProjectName string
+	// ThreadID is the trace's thread ID, if any.
+	ThreadID string
+	// Metadata and Tags are those the trace is created with.
+	Metadata map[string]any
+	Tags     []string
+}
+
+// Sampler decides which traces are sent to Opik. Samplers are called for
+// every trace the client creates, so they must be fast and safe for
+// concurrent use.
+type Sampler interface {
+	Sample(ctx context.Context, params SamplingParams) SamplingDecision
+}
+
+// SamplerFunc adapts a function to the Sampler interface.
+type SamplerFunc func(ctx context.Context, params SamplingParams) SamplingDecision
+
+// Sample calls f.
+func (f SamplerFunc) Sample(ctx context.Context, params SamplingParams) SamplingDecision {
+	return f(ctx, params)
+}
+
+// WithTraceSampler sets the sampler that decides which traces are sent, for
+// services with too many requests to trace each one:
+//
+//	client, err := opik.NewClient(
+//	    opik.WithTraceSampler(opik.RuleSampler(
+//	        opik.KeepErrors(opik.ProbabilitySampler(0.05)),
+//	        opik.SamplingRule{Match: opik.MatchProjects("healthchecks"), Sampler: opik.NeverSample()},
+//	    )),
+//	)
+//
+// The decision is made when a trace is created and applies to all of its
+// spans. Spans that continue a trace from another service through
+// ExtractTraceContext are always sent, so sample at the service that
+// starts the trace. Without a sampler, every trace is sent.
+func WithTraceSampler(sampler Sampler) Option {
+	return func(o *clientOptions) {
+		if sampler == nil {
+			o.problems.Addf("trace sampler must not be nil")
+		}
+		o.sampler = sampler
+	}
+}
+
+// AlwaysSample returns a sampler that keeps every trace.
+func AlwaysSample() Sampler {
+	return constantSampler(SamplingKeep)
+}
+
+// NeverSample returns a sampler that drops every trace.
+func NeverSample() Sampler {
+	return constantSampler(SamplingDrop)
+}
+
+type constantSampler SamplingDecision
+
+func (s constantSampler) Sample(context.Context, SamplingParams) SamplingDecision {
+	return SamplingDecision(s)
+}
+
+// ProbabilitySampler returns a sampler that keeps each trace with
+// probability rate, such as 0.01 for one trace in a hundred. A rate of 0
+// or less drops every trace, and a rate of 1 or more keeps every trace.
+func ProbabilitySampler(rate float64) Sampler {
+	return SamplerFunc(func(context.Context, SamplingParams) SamplingDecision {
+		if rand.Float64() < rate { //nolint:gosec // G404: sampling needs no cryptographic randomness
+			return SamplingKeep
+		}
+		return SamplingDrop
+	})
+}
+
+// RateLimitSampler returns a sampler that keeps at most perSecond traces
+// per second, averaged over time, and drops the rest. Bursts of up to
+// perSecond traces, and at least one, are kept at once.
+func RateLimitSampler(perSecond float64) Sampler {
+	burst := math.Max(perSecond, 1)
+	return &rateLimitSampler{rate: perSecond, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// rateLimitSampler is a token bucket refilled at rate tokens per second.
+type rateLimitSampler struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (s *rateLimitSampler) Sample(context.Context, SamplingParams) SamplingDecision {
+	if s.rate <= 0 {
+		return SamplingDrop
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.tokens = math.Min(s.burst, s.tokens+now.Sub(s.last).Seconds()*s.rate)
+	s.last = now
+	if s.tokens < 1 {
+		return SamplingDrop
+	}
+	s.tokens--
+	return SamplingKeep
+}
+
+// KeepErrors returns a sampler that holds the traces sampler drops instead
+// of dropping them, so traces that record an error are always sent. Held
+// traces are kept in memory until they end.
+func KeepErrors(sampler Sampler) Sampler {
+	return SamplerFunc(func(ctx context.Context, params SamplingParams) SamplingDecision {
+		if d := sampler.Sample(ctx, params); d != SamplingDrop {
+			return d
+		}
+		return SamplingKeepOnError
+	})
+}
+
+// SamplingRule applies Sampler to the traces Match accepts.
+type SamplingRule struct {
+	Match   func(params SamplingParams) bool
+	Sampler Sampler
+}
+
+// RuleSampler returns a sampler that applies the sampler of the first rule
+// matching a trace, or fallback if none does.
+func RuleSampler(fallback Sampler, rules ...SamplingRule) Sampler {
+	return SamplerFunc(func(ctx context.Context, params SamplingParams) SamplingDecision {
+		for _, rule := range rules {
+			if rule.Match(params) {
+				return rule.Sampler.Sample(ctx, params)
+			}
+		}
+		return fallback.Sample(ctx, params)
+	})
+}
+
+// MatchProjects returns a rule match for traces sent to any of the
+// projects.
+func MatchProjects(projects ...string) func(SamplingParams) bool {
+	return func(p SamplingParams) bool {
+		return slices.Contains(projects, p.ProjectName)
+	}
+}
+
+// MatchNames returns a rule match for traces with any of the names.
+func MatchNames(names ...string) func(SamplingParams) bool {
+	return func(p SamplingParams) bool {
+		return slices.Contains(names, p.Name)
+	}
+}
+
+// MatchTags returns a rule match for traces with any of the tags.
+func MatchTags(tags ...string) func(SamplingParams) bool {
+	return func(p SamplingParams) bool {
+		for _, tag := range p.Tags {
+			if slices.Contains(tags, tag) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// withSampled keeps the trace whatever the client's sampler decides, for
+// traces that other records link to, such as those of experiment items.
+func withSampled() TraceOption {
+	return func(o *traceOptions) {
+		o.sampled = true
+	}
+}
+
+// errorInfo returns the error info recorded for err: its type, as the
+// exception type, and its message after fn and the client's redaction
+// pipeline.
+func (c *Client) errorInfo(fn RedactFunc, err error) api.ErrorInfo {
+	return api.ErrorInfo{ExceptionType: fmt.Sprintf("%T", err), Message: api.NewOptString(c.redactString(fn, err.Error()))}
+}
+
+// Hold states of a heldTrace.
+const (
+	holding = iota
+	released
+	discarded
+)
+
+// heldTrace holds the writes of a trace sampled with SamplingKeepOnError.
+// They are released, sent in order, when the trace or one of its spans
+// records an error; after that, writes are sent as they are made. They are
+// discarded if the trace ends first, along with any later writes. The
+// methods of a nil heldTrace send writes directly.
+type heldTrace struct {
+	mu    sync.Mutex
+	state int
+	trace api.TraceWrite
+	spans []api.SpanWrite
+	index map[uuid.UUID]int
+}
+
+// sends reports whether writes to the trace are sent, rather than held or
+// discarded.
+func (h *heldTrace) sends() bool {
+	if h == nil {
+		return true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.state == released
+}
+
+func (h *heldTrace) writeTrace(ctx context.Context, c *Client, write api.TraceWrite) error {
+	if h == nil {
+		return c.writeTrace(ctx, write)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch h.state {
+	case holding:
+		h.trace = write
+		if write.ErrorInfo.Set {
+			return h.release(ctx, c)
+		}
+		return nil
+	case released:
+		return c.writeTrace(ctx, write)
+	}
+	return nil
+}
+
+func (h *heldTrace) writeSpan(ctx context.Context, c *Client, write api.SpanWrite) error {
+	if h == nil {
+		return c.writeSpan(ctx, write)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch h.state {
+	case holding:
+		if h.index == nil {
+			h.index = make(map[uuid.UUID]int)
+		}
+		h.index[write.ID.Value] = len(h.spans)
+		h.spans = append(h.spans, write)
+		if write.ErrorInfo.Set {
+			return h.release(ctx, c)
+		}
+		return nil
+	case released:
+		return c.writeSpan(ctx, write)
+	}
+	return nil
+}
+
+// updateTrace updates the trace. end reports whether the update ends it.
+func (h *heldTrace) updateTrace(ctx context.Context, c *Client, id uuid.UUID, update api.TraceUpdate, end bool) error {
+	if h == nil {
+		return c.updateTrace(ctx, id, update)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch h.state {
+	case holding:
+		mergeTraceUpdate(&h.trace, update)
+		switch {
+		case update.ErrorInfo.Set:
+			return h.release(ctx, c)
+		case end:
+			h.state, h.spans, h.index = discarded, nil, nil
+		}
+		return nil
+	case released:
+		return c.updateTrace(ctx, id, update)
+	}
+	return nil
+}
+
+func (h *heldTrace) updateSpan(ctx context.Context, c *Client, id uuid.UUID, update api.SpanUpdate) error {
+	if h == nil {
+		return c.updateSpan(ctx, id, update)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch h.state {
+	case holding:
+		if i, ok := h.index[id]; ok {
+			mergeSpanUpdate(&h.spans[i], update)
+		}
+		if update.ErrorInfo.Set {
+			return h.release(ctx, c)
+		}
+		return nil
+	case released:
+		return c.updateSpan(ctx, id, update)
+	}
+	return nil
+}
+
+// release sends the held writes, the trace first. h.mu must be held, so
+// writes made meanwhile wait and are sent after the held ones.
+func (h *heldTrace) release(ctx context.Context, c *Client) error {
+	h.state = released
+	errs := []error{c.writeTrace(ctx, h.trace)}
+	for _, span := range h.spans {
+		errs = append(errs, c.writeSpan(ctx, span))
+	}
+	h.spans, h.index = nil, nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,177 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestProbabilitySampler(t *testing.T) {
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		if d := ProbabilitySampler(0).Sample(ctx, SamplingParams{}); d != SamplingDrop {
+			t.Fatalf("ProbabilitySampler(0) = %v, want drop", d)
+		}
+		if d := ProbabilitySampler(1).Sample(ctx, SamplingParams{}); d != SamplingKeep {
+			t.Fatalf("ProbabilitySampler(1) = %v, want keep", d)
+		}
+	}
+}
+
+func TestRateLimitSampler(t *testing.T) {
+	ctx := context.Background()
+	sampler := RateLimitSampler(3)
+	kept := 0
+	for i := 0; i < 10; i++ {
+		if sampler.Sample(ctx, SamplingParams{}) == SamplingKeep {
+			kept++
+		}
+	}
+	if kept != 3 {
+		t.Errorf("kept %d of a burst of 10, want 3", kept)
+	}
+	if d := RateLimitSampler(0).Sample(ctx, SamplingParams{}); d != SamplingDrop {
+		t.Errorf("RateLimitSampler(0) = %v, want drop", d)
+	}
+}
+
+func TestRuleSampler(t *testing.T) {
+	ctx := context.Background()
+	sampler := RuleSampler(KeepErrors(NeverSample()),
+		SamplingRule{Match: MatchProjects("healthchecks"), Sampler: NeverSample()},
+		SamplingRule{Match: MatchTags("debug"), Sampler: AlwaysSample()},
+	)
+	tests := []struct {
+		params SamplingParams
+		want   SamplingDecision
+	}{
+		{SamplingParams{ProjectName: "healthchecks", Tags: []string{"debug"}}, SamplingDrop},
+		{SamplingParams{ProjectName: "api", Tags: []string{"debug"}}, SamplingKeep},
+		{SamplingParams{ProjectName: "api"}, SamplingKeepOnError},
+	}
+	for _, tt := range tests {
+		if got := sampler.Sample(ctx, tt.params); got != tt.want {
+			t.Errorf("Sample(%+v) = %v, want %v", tt.params, got, tt.want)
+		}
+	}
+	if !MatchNames("chat")(SamplingParams{Name: "chat"}) {
+		t.Error("MatchNames did not match")
+	}
+}
+
+func TestTraceSamplerDrop(t *testing.T) {
+	ts, requests := newIngestServer(nil)
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithProjectName("api"),
+		WithTraceSampler(RuleSampler(AlwaysSample(), SamplingRule{Match: MatchProjects("api"), Sampler: NeverSample()})))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	trace, err := client.Trace(ctx, "request")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	if !trace.IsNoop() {
+		t.Error("dropped trace is not a noop")
+	}
+	span, _ := trace.Span(ctx, "step")
+	_ = span.End(ctx)
+	_ = trace.End(ctx)
+	if got := requests(); len(got) != 0 {
+		t.Errorf("sent %d requests for a dropped trace, want 0", len(got))
+	}
+
+	// Traces that other records link to are kept.
+	if _, err := client.Trace(ctx, "item", withSampled()); err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	if got := requests(); len(got) != 1 {
+		t.Errorf("sent %d requests for a sampled trace, want 1", len(got))
+	}
+}
+
+func TestTraceSamplerKeepErrors(t *testing.T) {
+	ts, requests := newIngestServer(nil)
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithTraceSampler(KeepErrors(NeverSample())))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	// A trace that ends without an error is dropped.
+	trace, err := client.Trace(ctx, "ok")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	if trace.IsNoop() {
+		t.Fatal("held trace is a noop")
+	}
+	span, _ := trace.Span(ctx, "step")
+	_ = span.End(ctx)
+	if err := span.AddFeedbackScore(ctx, "quality", 1, ""); err != nil {
+		t.Fatalf("AddFeedbackScore error: %v", err)
+	}
+	_ = trace.End(ctx)
+	if got := requests(); len(got) != 0 {
+		t.Fatalf("sent %d requests for a trace without errors, want 0", len(got))
+	}
+
+	// A span error sends the trace and its spans, then later writes.
+	trace, _ = client.Trace(ctx, "failed", WithTraceInput("question"))
+	retrieve, _ := trace.Span(ctx, "retrieve")
+	_ = retrieve.End(ctx, WithSpanOutput("docs"))
+	generate, _ := trace.Span(ctx, "generate")
+	if err := generate.End(ctx, WithSpanError(errors.New("rate limited"))); err != nil {
+		t.Fatalf("span End error: %v", err)
+	}
+	got := requests()
+	if len(got) != 3 {
+		t.Fatalf("sent %d requests after the error, want 3", len(got))
+	}
+	if got[0].key != "POST /v1/private/traces/batch" || got[0].traces[0].Input != "question" {
+		t.Errorf("first request = %+v, want the trace", got[0])
+	}
+	if got[1].spans[0].Output != "docs" || got[1].spans[0].EndTime == nil {
+		t.Errorf("retrieve span = %+v, want it ended with its output", got[1].spans[0])
+	}
+	info := got[2].spans[0].ErrorInfo
+	if info == nil || info.Message != "rate limited" || info.ExceptionType != "*errors.errorString" {
+		t.Errorf("generate span error_info = %+v", info)
+	}
+
+	_ = trace.End(ctx, WithTraceOutput("sorry"))
+	if got := requests(); len(got) != 4 || got[3].key != "PATCH /v1/private/traces/batch" {
+		t.Errorf("trace end was not sent after release: %+v", got)
+	}
+}
+
+func TestWithTraceError(t *testing.T) {
+	ts, created := newCreateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	trace, _ := client.Trace(ctx, "request")
+	_ = trace.End(ctx, WithTraceError(errors.New("timeout")))
+	info := created("PATCH /v1/private/traces/batch").ErrorInfo
+	if info == nil || info.Message != "timeout" {
+		t.Errorf("trace error_info = %+v, want timeout", info)
+	}
+}
+
+func TestWithTraceSamplerNil(t *testing.T) {
+	_, err := NewClient(WithURL("http://localhost"), WithAPIKey("test-key"), WithTraceSampler(nil))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("NewClient error = %v, want ValidationError", err)
+	}
+}
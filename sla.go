@@ -0,0 +1,76 @@
+package opik
+
+import "time"
+
+// Metadata keys written when a trace has an SLA or a span has a latency
+// budget. Traces and spans use the same keys so both can be filtered the same
+// way, e.g. metadata.over_budget = true.
+const (
+	MetadataLatencyBudgetMs = "latency_budget_ms"
+	MetadataLatencyMs       = "latency_ms"
+	MetadataOverBudget      = "over_budget"
+	MetadataOverageMs       = "overage_ms"
+)
+
+// WithTraceSLA sets the latency budget for the trace. When the trace ends,
+// its latency, whether it exceeded the budget and by how much are recorded
+// in its metadata.
+func WithTraceSLA(sla time.Duration) TraceOption {
+	return func(o *traceOptions) {
+		o.sla = sla
+	}
+}
+
+// WithSpanBudget sets the latency budget for the span. When the span ends,
+// its latency, whether it exceeded the budget and by how much are recorded
+// in its metadata. To give a child span whatever is left of the trace's SLA,
+// pass trace.RemainingSLA().
+func WithSpanBudget(budget time.Duration) SpanOption {
+	return func(o *spanOptions) {
+		o.budget = budget
+	}
+}
+
+// SLA returns the trace's latency budget, or 0 if none was set.
+func (t *Trace) SLA() time.Duration {
+	return t.sla
+}
+
+// RemainingSLA returns how much of the trace's SLA is left. It is negative
+// once the SLA is exceeded, and 0 if no SLA was set.
+func (t *Trace) RemainingSLA() time.Duration {
+	return remainingBudget(t.sla, t.startTime, t.endTime)
+}
+
+// Budget returns the span's latency budget, or 0 if none was set.
+func (s *Span) Budget() time.Duration {
+	return s.budget
+}
+
+// RemainingBudget returns how much of the span's latency budget is left. It
+// is negative once the budget is exceeded, and 0 if no budget was set.
+func (s *Span) RemainingBudget() time.Duration {
+	return remainingBudget(s.budget, s.startTime, s.endTime)
+}
+
+func remainingBudget(budget time.Duration, start time.Time, end *time.Time) time.Duration {
+	if budget <= 0 {
+		return 0
+	}
+	if end != nil {
+		return budget - end.Sub(start)
+	}
+	return budget - time.Since(start)
+}
+
+// recordLatencyBudget adds the latency budget keys to metadata. It does
+// nothing if no budget is set.
+func recordLatencyBudget(metadata map[string]any, budget, latency time.Duration) {
+	if budget <= 0 {
+		return
+	}
+	metadata[MetadataLatencyBudgetMs] = budget.Milliseconds()
+	metadata[MetadataLatencyMs] = latency.Milliseconds()
+	metadata[MetadataOverBudget] = latency > budget
+	metadata[MetadataOverageMs] = max(latency-budget, 0).Milliseconds()
+}
@@ -0,0 +1,118 @@
+package opik
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+// newUpdateServer accepts every request and returns a function reporting the
+// metadata of the last PATCH batch update sent to a path.
+func newUpdateServer() (*testutil.MockServer, func(path string) map[string]any) {
+	ms := testutil.NewMockServer()
+	ms.OnUnmatched().Respond(http.StatusNoContent, nil)
+	return ms, func(path string) map[string]any {
+		reqs := ms.RequestsFor(http.MethodPatch, path)
+		if len(reqs) == 0 {
+			return nil
+		}
+		var body struct {
+			Update struct {
+				Metadata map[string]any `json:"metadata"`
+			} `json:"update"`
+		}
+		_ = reqs[len(reqs)-1].DecodeJSON(&body)
+		return body.Update.Metadata
+	}
+}
+
+func TestTraceSLA(t *testing.T) {
+	ts, metadata := newUpdateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	trace, err := client.Trace(ctx, "slow", WithTraceSLA(time.Millisecond))
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	if trace.SLA() != time.Millisecond {
+		t.Errorf("SLA() = %v", trace.SLA())
+	}
+	time.Sleep(5 * time.Millisecond)
+	if trace.RemainingSLA() >= 0 {
+		t.Errorf("RemainingSLA() = %v, want negative", trace.RemainingSLA())
+	}
+	if err := trace.End(ctx); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+
+	md := metadata("/v1/private/traces/batch")
+	if md[MetadataOverBudget] != true {
+		t.Errorf("%s = %v, want true", MetadataOverBudget, md[MetadataOverBudget])
+	}
+	if md[MetadataLatencyBudgetMs] != float64(1) {
+		t.Errorf("%s = %v, want 1", MetadataLatencyBudgetMs, md[MetadataLatencyBudgetMs])
+	}
+	latency, _ := md[MetadataLatencyMs].(float64)
+	overage, _ := md[MetadataOverageMs].(float64)
+	if latency < 5 || overage != latency-1 {
+		t.Errorf("latency = %v, overage = %v", latency, overage)
+	}
+}
+
+func TestSpanBudget(t *testing.T) {
+	ts, metadata := newUpdateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	trace, err := client.Trace(ctx, "fast", WithTraceSLA(time.Hour))
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	span, err := trace.Span(ctx, "step", WithSpanBudget(trace.RemainingSLA()), WithSpanMetadata(nil))
+	if err != nil {
+		t.Fatalf("Span error: %v", err)
+	}
+	if b := span.Budget(); b <= 0 || b > time.Hour {
+		t.Errorf("Budget() = %v", b)
+	}
+	if err := span.End(ctx); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+	if span.RemainingBudget() <= 0 {
+		t.Errorf("RemainingBudget() = %v, want positive", span.RemainingBudget())
+	}
+
+	md := metadata("/v1/private/spans/batch")
+	if md[MetadataOverBudget] != false || md[MetadataOverageMs] != float64(0) {
+		t.Errorf("span metadata = %v", md)
+	}
+
+	// Without a budget no keys are added.
+	plain, err := trace.Span(ctx, "plain")
+	if err != nil {
+		t.Fatalf("Span error: %v", err)
+	}
+	if err := plain.End(ctx); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+	if md := metadata("/v1/private/spans/batch"); md != nil {
+		t.Errorf("metadata without budget = %v, want none", md)
+	}
+	if plain.RemainingBudget() != 0 {
+		t.Errorf("RemainingBudget() without budget = %v", plain.RemainingBudget())
+	}
+}
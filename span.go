@@ -2,13 +2,15 @@ package opik
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/plexusone/opik-go/internal/api"
+	"github.com/plexusone/opik-go/metadata"
 )
 
 // Span represents a span within a trace in Opik.
@@ -28,7 +30,18 @@ type Span struct {
 	model        string
 	provider     string
 	usage        map[string]int
+	cost         *float64
+	reasoning    *string
+	events       []SpanEvent
+	eventsMu     sync.Mutex // guards events and ended
+	eventsSendMu sync.Mutex // serializes End's update and those sent by AddEvent after it
+	budget       time.Duration
+	redact       RedactFunc
+	projectName  string
+	route        *ProjectRoute
+	held         *heldTrace
 	ended        bool
+	noop         bool
 }
 
 // ID returns the span ID.
@@ -68,34 +81,33 @@ func (s *Span) EndTime() *time.Time {
 
 // End ends the span with optional output.
 func (s *Span) End(ctx context.Context, opts ...SpanOption) error {
-	if s.ended {
+	if s.ended || s.noop {
 		return nil
 	}
 
-	options := &spanOptions{
-		metadata: make(map[string]any),
-	}
+	options := &spanOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
 
 	endTime := time.Now()
 	s.endTime = &endTime
-	s.ended = true
 
 	// Merge output and metadata
 	if options.output != nil {
 		s.output = options.output
 	}
-	for k, v := range options.metadata {
-		s.metadata[k] = v
+	if s.metadata == nil {
+		s.metadata = make(map[string]any)
 	}
+	metadata.Metadata(s.metadata).Merge(options.metadata)
 	if options.model != "" {
 		s.model = options.model
 	}
 	if options.provider != "" {
 		s.provider = options.provider
 	}
+	recordLatencyBudget(s.metadata, s.budget, endTime.Sub(s.startTime))
 
 	// Prepare update request
 	spanUUID, err := uuid.Parse(s.id)
@@ -112,41 +124,45 @@ func (s *Span) End(ctx context.Context, opts ...SpanOption) error {
 	// An empty JsonListString produces malformed JSON in the generated encoder.
 	nullJSON := api.JsonListString([]byte("null"))
 
-	outputJSON := nullJSON
+	update := api.SpanUpdate{
+		TraceID:  traceUUID,
+		EndTime:  api.NewOptDateTime(endTime),
+		Input:    nullJSON, // Required field, must be valid JSON
+		Model:    api.NewOptString(s.model),
+		Provider: api.NewOptString(s.provider),
+	}
+	s.applyUsage(&update)
+	s.applyReasoning()
+	// Hold the send lock until End's update is sent, so an event added
+	// once the span is marked ended is sent after it.
+	s.eventsSendMu.Lock()
+	defer s.eventsSendMu.Unlock()
+	s.endEvents()
+
+	update.Output = nullJSON
 	if s.output != nil {
-		data, _ := json.Marshal(s.output)
-		outputJSON = api.JsonListString(data)
+		data := s.client.marshalPayload(s.redact, s.output, payloadOutput)
+		update.Output = api.JsonListString(data)
 	}
 
-	metadataJSON := nullJSON
+	update.Metadata = nullJSON
 	if len(s.metadata) > 0 {
-		data, _ := json.Marshal(s.metadata)
-		metadataJSON = api.JsonListString(data)
+		data := s.client.marshalMetadata(s.metadata)
+		update.Metadata = api.JsonListString(data)
 	}
 
-	// Create update request - SpanBatchUpdate uses Ids + single Update
-	req := api.SpanBatchUpdate{
-		Ids: []uuid.UUID{spanUUID},
-		Update: api.SpanUpdate{
-			TraceID:  traceUUID,
-			EndTime:  api.NewOptDateTime(endTime),
-			Input:    nullJSON, // Required field, must be valid JSON
-			Output:   outputJSON,
-			Metadata: metadataJSON,
-			Model:    api.NewOptString(s.model),
-			Provider: api.NewOptString(s.provider),
-		},
+	if options.err != nil {
+		update.ErrorInfo = api.NewOptErrorInfo(s.client.errorInfo(s.redact, options.err))
 	}
-
-	_, err = s.client.apiClient.BatchUpdateSpans(ctx, api.NewOptSpanBatchUpdate(req))
-	return err
+	return s.held.updateSpan(ctx, s.client, spanUUID, update)
 }
 
 // Update updates the span with new data.
 func (s *Span) Update(ctx context.Context, opts ...SpanOption) error {
-	options := &spanOptions{
-		metadata: make(map[string]any),
+	if s.noop {
+		return nil
 	}
+	options := &spanOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
@@ -162,9 +178,10 @@ func (s *Span) Update(ctx context.Context, opts ...SpanOption) error {
 	}
 
 	// Merge metadata
-	for k, v := range options.metadata {
-		s.metadata[k] = v
+	if s.metadata == nil && len(options.metadata) > 0 {
+		s.metadata = make(map[string]any, len(options.metadata))
 	}
+	metadata.Metadata(s.metadata).Merge(options.metadata)
 	if options.output != nil {
 		s.output = options.output
 	}
@@ -174,6 +191,7 @@ func (s *Span) Update(ctx context.Context, opts ...SpanOption) error {
 	if options.provider != "" {
 		s.provider = options.provider
 	}
+	s.applyEvents()
 
 	// IMPORTANT: JsonListString fields must be set to valid JSON (including "null")
 	// An empty JsonListString produces malformed JSON in the generated encoder.
@@ -181,40 +199,45 @@ func (s *Span) Update(ctx context.Context, opts ...SpanOption) error {
 
 	outputJSON := nullJSON
 	if s.output != nil {
-		data, _ := json.Marshal(s.output)
+		data := s.client.marshalPayload(s.redact, s.output, payloadOutput)
 		outputJSON = api.JsonListString(data)
 	}
 
 	metadataJSON := nullJSON
 	if len(s.metadata) > 0 {
-		data, _ := json.Marshal(s.metadata)
+		data := s.client.marshalMetadata(s.metadata)
 		metadataJSON = api.JsonListString(data)
 	}
 
-	req := api.SpanBatchUpdate{
-		Ids: []uuid.UUID{spanUUID},
-		Update: api.SpanUpdate{
-			TraceID:  traceUUID,
-			Input:    nullJSON, // Required field, must be valid JSON
-			Output:   outputJSON,
-			Metadata: metadataJSON,
-			Model:    api.NewOptString(s.model),
-			Provider: api.NewOptString(s.provider),
-			Tags:     options.tags,
-		},
+	update := api.SpanUpdate{
+		TraceID:  traceUUID,
+		Input:    nullJSON, // Required field, must be valid JSON
+		Output:   outputJSON,
+		Metadata: metadataJSON,
+		Model:    api.NewOptString(s.model),
+		Provider: api.NewOptString(s.provider),
+		Tags:     options.tags,
 	}
-
-	_, err = s.client.apiClient.BatchUpdateSpans(ctx, api.NewOptSpanBatchUpdate(req))
-	return err
+	if options.err != nil {
+		update.ErrorInfo = api.NewOptErrorInfo(s.client.errorInfo(s.redact, options.err))
+	}
+	return s.held.updateSpan(ctx, s.client, spanUUID, update)
 }
 
 // Span creates a child span within this span.
 func (s *Span) Span(ctx context.Context, name string, opts ...SpanOption) (*Span, error) {
-	return s.client.createSpan(ctx, s.traceID, s.id, name, opts...)
+	if s.noop {
+		return noopSpan, nil
+	}
+	return s.client.createSpan(ctx, s.traceID, s.id, s.projectName, s.route, s.held, name, opts...)
 }
 
-// AddFeedbackScore adds a feedback score to this span.
+// AddFeedbackScore adds a feedback score to this span. Scores are dropped
+// as described for Trace.AddFeedbackScore.
 func (s *Span) AddFeedbackScore(ctx context.Context, name string, value float64, reason string) error {
+	if s.noop || !s.held.sends() {
+		return nil
+	}
 	spanUUID, err := uuid.Parse(s.id)
 	if err != nil {
 		return err
@@ -227,25 +250,134 @@ func (s *Span) AddFeedbackScore(ctx context.Context, name string, value float64,
 		Source: api.FeedbackScoreSourceSdk,
 	}
 
-	return s.client.apiClient.AddSpanFeedbackScore(ctx, api.NewOptFeedbackScore(req), api.AddSpanFeedbackScoreParams{
+	err = s.client.apiClient.AddSpanFeedbackScore(ctx, api.NewOptFeedbackScore(req), api.AddSpanFeedbackScoreParams{
 		ID: spanUUID,
 	})
+	s.client.audit(AuditCreate, AuditEntityFeedbackScore, s.id, name, err)
+	return err
 }
 
-// SetUsage sets LLM usage metrics for this span.
+// SetUsage sets LLM usage metrics for this span. Use NormalizeUsage to
+// convert a provider's usage object. The usage is sent when the span ends.
 func (s *Span) SetUsage(usage map[string]int) {
-	s.usage = usage
+	if !s.noop {
+		s.usage = usage
+	}
+}
+
+// Usage returns the LLM usage metrics set on this span.
+func (s *Span) Usage() map[string]int {
+	return s.usage
+}
+
+// SetCost sets the estimated cost of this span in USD, overriding the cost
+// computed from the model's price.
+func (s *Span) SetCost(cost float64) {
+	if s.noop {
+		return
+	}
+	c := cost
+	s.cost = &c
+}
+
+// SetReasoning sets the reasoning summary returned by a reasoning model.
+// It is recorded in the span's "reasoning_summary" metadata only if the
+// client was created with WithReasoningCapture(true); otherwise the span
+// records that a summary was redacted.
+func (s *Span) SetReasoning(summary string) {
+	if s.noop {
+		return
+	}
+	r := summary
+	s.reasoning = &r
+}
+
+// applyReasoning records the reasoning summary, or that it was redacted.
+func (s *Span) applyReasoning() {
+	if s.reasoning == nil {
+		return
+	}
+	if s.metadata == nil {
+		s.metadata = make(map[string]any)
+	}
+	if s.client != nil && s.client.captureReasoning {
+		s.metadata[MetadataReasoningSummary] = *s.reasoning
+	} else {
+		s.metadata[MetadataReasoningRedacted] = true
+	}
+}
+
+// EstimatedCost returns the span's cost in USD: the cost set with SetCost,
+// or else the cost of its usage at its model's price. The price comes from
+// the client's WithCostTable, RegisterModelPrice, or the built-in prices of
+// the cost package, in that order. It reports false if the span has no
+// cost set and no usage, or its model has no price.
+func (s *Span) EstimatedCost() (float64, bool) {
+	if s.cost != nil {
+		return *s.cost, true
+	}
+	if len(s.usage) == 0 || s.model == "" {
+		return 0, false
+	}
+	price, ok := s.client.modelPrice(s.provider, s.model)
+	if !ok {
+		return 0, false
+	}
+	return price.Cost(s.usage), true
+}
+
+// applyUsage adds the usage and estimated cost to a span update. The cost is
+// also recorded in metadata, along with cache savings and the cost of
+// reasoning tokens when it is computed from the model's price.
+func (s *Span) applyUsage(update *api.SpanUpdate) {
+	if len(s.usage) > 0 {
+		usage := make(api.SpanUpdateUsage, len(s.usage))
+		for k, v := range s.usage {
+			usage[k] = int32(v) //nolint:gosec // G115: token counts fit in int32
+		}
+		update.Usage = api.NewOptSpanUpdateUsage(usage)
+	}
+
+	cost, ok := s.EstimatedCost()
+	if !ok {
+		return
+	}
+	update.TotalEstimatedCost = api.NewOptFloat64(cost)
+	if s.metadata == nil {
+		s.metadata = make(map[string]any)
+	}
+	s.metadata[MetadataEstimatedCost] = cost
+	if s.cost != nil {
+		return
+	}
+	price, _ := s.client.modelPrice(s.provider, s.model)
+	if s.usage[UsageCacheReadTokens] > 0 || s.usage[UsageCacheWriteTokens] > 0 {
+		s.metadata["cache_savings_usd"] = price.CacheSavings(s.usage)
+	}
+	if s.usage[UsageReasoningTokens] > 0 {
+		s.metadata["reasoning_cost_usd"] = price.ReasoningCost(s.usage)
+	}
 }
 
 // createSpan is a helper to create spans (used by both Client and Trace).
-func (c *Client) createSpan(ctx context.Context, traceID, parentSpanID, name string, opts ...SpanOption) (*Span, error) {
+// Spans go to projectName, the project of their trace, or if it is empty,
+// as when continuing a distributed trace, to the project of ctx or the
+// default project. The span options of route, the trace's project
+// route, are applied before opts.
+func (c *Client) createSpan(ctx context.Context, traceID, parentSpanID, projectName string, route *ProjectRoute, held *heldTrace, name string, opts ...SpanOption) (*Span, error) {
 	if c.config.TracingDisabled {
-		return nil, ErrTracingDisabled
+		return noopSpan, nil
 	}
 
-	options := defaultSpanOptions()
-	for _, opt := range opts {
-		opt(options)
+	if route != nil && len(route.SpanOptions) > 0 {
+		opts = append(slices.Clip(route.SpanOptions), opts...)
+	}
+	options := c.newSpanOptions(opts)
+	if projectName == "" {
+		projectName = ProjectFromContext(ctx)
+	}
+	if projectName == "" {
+		projectName = c.ProjectName()
 	}
 
 	// Generate span ID (must be UUID v7 for Opik API)
@@ -261,24 +393,25 @@ func (c *Client) createSpan(ctx context.Context, traceID, parentSpanID, name str
 
 	// Prepare input/output/metadata as JSON
 	// Note: JsonListStringWrite is raw JSON bytes - use null for empty values
-	nullJSON := api.JsonListStringWrite([]byte("null"))
-	inputJSON := nullJSON
-	outputJSON := nullJSON
-	metadataJSON := nullJSON
+	inputJSON := nullJSONWrite
+	outputJSON := nullJSONWrite
+	metadataJSON := nullJSONWrite
 
 	if options.input != nil {
-		data, _ := json.Marshal(options.input)
+		data := c.marshalPayload(options.redact, options.input, payloadInput)
 		inputJSON = api.JsonListStringWrite(data)
 	}
 	if options.output != nil {
-		data, _ := json.Marshal(options.output)
+		data := c.marshalPayload(options.redact, options.output, payloadOutput)
 		outputJSON = api.JsonListStringWrite(data)
 	}
 	if len(options.metadata) > 0 {
-		data, _ := json.Marshal(options.metadata)
+		data := c.marshalMetadata(options.metadata)
 		metadataJSON = api.JsonListStringWrite(data)
 	}
 
+	tags := indexedTags(c.indexedMetadata, options.metadata, options.tags)
+
 	startTime := time.Now()
 
 	// Determine span type
@@ -287,7 +420,7 @@ func (c *Client) createSpan(ctx context.Context, traceID, parentSpanID, name str
 	// Create span request
 	spanWrite := api.SpanWrite{
 		ID:          api.NewOptUUID(spanUUID),
-		ProjectName: api.NewOptString(c.ProjectName()),
+		ProjectName: api.NewOptString(projectName),
 		TraceID:     api.NewOptUUID(traceUUID),
 		Name:        api.NewOptString(name),
 		Type:        api.NewOptSpanWriteType(spanType),
@@ -295,7 +428,7 @@ func (c *Client) createSpan(ctx context.Context, traceID, parentSpanID, name str
 		Input:       inputJSON,
 		Output:      outputJSON,
 		Metadata:    metadataJSON,
-		Tags:        options.tags,
+		Tags:        tags,
 		Model:       api.NewOptString(options.model),
 		Provider:    api.NewOptString(options.provider),
 	}
@@ -308,13 +441,12 @@ func (c *Client) createSpan(ctx context.Context, traceID, parentSpanID, name str
 		spanWrite.ParentSpanID = api.NewOptUUID(parentUUID)
 	}
 
-	req := api.SpanBatchWrite{
-		Spans: []api.SpanWrite{spanWrite},
+	if options.err != nil {
+		spanWrite.ErrorInfo = api.NewOptErrorInfoWrite(api.ErrorInfoWrite(c.errorInfo(options.redact, options.err)))
 	}
 
-	// Send to API
-	err = c.apiClient.CreateSpans(ctx, api.NewOptSpanBatchWrite(req))
-	if err != nil {
+	// Send to API, or queue when batching
+	if err := held.writeSpan(ctx, c, spanWrite); err != nil {
 		return nil, err
 	}
 
@@ -329,8 +461,13 @@ func (c *Client) createSpan(ctx context.Context, traceID, parentSpanID, name str
 		input:        options.input,
 		output:       options.output,
 		metadata:     options.metadata,
-		tags:         options.tags,
+		tags:         tags,
 		model:        options.model,
 		provider:     options.provider,
+		budget:       options.budget,
+		redact:       options.redact,
+		projectName:  projectName,
+		route:        route,
+		held:         held,
 	}, nil
 }
@@ -0,0 +1,136 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// MetadataEvents is the metadata key under which span events added with
+// Span.AddEvent are recorded.
+const MetadataEvents = "events"
+
+// SpanEvent is a timestamped event within a span, such as a tool
+// selection, a retry, or a guardrail trigger: a step too small to be a
+// span of its own.
+type SpanEvent struct {
+	Name       string         `json:"name"`
+	Time       time.Time      `json:"time"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// AddEvent records an event on the span at the current time.
+//
+//	span.AddEvent(ctx, "tool_selected", map[string]any{"tool": "search"})
+//
+// Opik has no field for span events, so they are recorded in order in the
+// span's metadata under MetadataEvents when the span is updated or ended.
+// An event added after the span ended is sent right away with an update.
+// AddEvent may be called from several goroutines.
+func (s *Span) AddEvent(ctx context.Context, name string, attributes map[string]any) error {
+	if s.noop {
+		return nil
+	}
+	s.eventsMu.Lock()
+	s.events = append(s.events, SpanEvent{Name: name, Time: time.Now(), Attributes: maps.Clone(attributes)})
+	ended := s.ended
+	s.eventsMu.Unlock()
+	if ended {
+		return s.sendEvents(ctx)
+	}
+	return nil
+}
+
+// sendEvents sends the events of an ended span in an update of the metadata
+// it ended with. Updates are sent one at a time, after End's, and each
+// takes the events when it is sent, so the last one carries every event.
+func (s *Span) sendEvents(ctx context.Context) error {
+	spanUUID, err := uuid.Parse(s.id)
+	if err != nil {
+		return err
+	}
+	traceUUID, err := uuid.Parse(s.traceID)
+	if err != nil {
+		return err
+	}
+
+	s.eventsSendMu.Lock()
+	defer s.eventsSendMu.Unlock()
+
+	// End sent its update, with the metadata, before releasing the lock.
+	s.eventsMu.Lock()
+	md := maps.Clone(s.metadata)
+	if md == nil {
+		md = make(map[string]any, 1)
+	}
+	md[MetadataEvents] = slices.Clone(s.events)
+	s.eventsMu.Unlock()
+
+	nullJSON := api.JsonListString([]byte("null"))
+	update := api.SpanUpdate{
+		TraceID:  traceUUID,
+		Input:    nullJSON, // Required field, must be valid JSON
+		Output:   nullJSON,
+		Metadata: api.JsonListString(s.client.marshalMetadata(md)),
+	}
+	return s.held.updateSpan(ctx, s.client, spanUUID, update)
+}
+
+// Events returns the events added to the span, in order.
+func (s *Span) Events() []SpanEvent {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+	return append([]SpanEvent(nil), s.events...)
+}
+
+// endEvents marks the span ended and records its events in its metadata.
+// Both happen under the events lock, so an event is either in the metadata
+// End sends or is sent by AddEvent.
+func (s *Span) endEvents() {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+	s.ended = true
+	if len(s.events) == 0 {
+		return
+	}
+	if s.metadata == nil {
+		s.metadata = make(map[string]any)
+	}
+	s.metadata[MetadataEvents] = slices.Clone(s.events)
+}
+
+// applyEvents records the span's events in its metadata.
+func (s *Span) applyEvents() {
+	events := s.Events()
+	if len(events) == 0 {
+		return
+	}
+	if s.metadata == nil {
+		s.metadata = make(map[string]any)
+	}
+	s.metadata[MetadataEvents] = events
+}
+
+// Events returns the events recorded in the span's metadata by
+// Span.AddEvent, or nil if there are none.
+func (s *SpanInfo) Events() []SpanEvent {
+	metadata, ok := s.Metadata.(map[string]any)
+	if !ok || metadata[MetadataEvents] == nil {
+		return nil
+	}
+	data, err := json.Marshal(metadata[MetadataEvents])
+	if err != nil {
+		return nil
+	}
+	var events []SpanEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil
+	}
+	return events
+}
@@ -0,0 +1,207 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSpanAddEvent(t *testing.T) {
+	ts, created := newCreateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "agent")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	span, err := trace.Span(ctx, "plan")
+	if err != nil {
+		t.Fatalf("Span error: %v", err)
+	}
+
+	attrs := map[string]any{"tool": "search"}
+	if err := span.AddEvent(ctx, "tool_selected", attrs); err != nil {
+		t.Fatalf("AddEvent error: %v", err)
+	}
+	attrs["tool"] = "changed" // events keep a copy of their attributes
+	if err := AddSpanEvent(ContextWithSpan(ctx, span), "retry", nil); err != nil {
+		t.Fatalf("AddSpanEvent error: %v", err)
+	}
+
+	events := span.Events()
+	if len(events) != 2 || events[0].Name != "tool_selected" || events[1].Name != "retry" {
+		t.Fatalf("events = %+v", events)
+	}
+	if events[0].Time.IsZero() || events[1].Time.Before(events[0].Time) {
+		t.Errorf("event times = %v, %v", events[0].Time, events[1].Time)
+	}
+	if events[0].Attributes["tool"] != "search" {
+		t.Errorf("attributes = %v", events[0].Attributes)
+	}
+
+	if err := span.End(ctx, WithSpanMetadata(map[string]any{"step": 1})); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+	metadata := created("PATCH /v1/private/spans/batch").Metadata
+	info := &SpanInfo{Metadata: metadata}
+	got := info.Events()
+	if len(got) != 2 || got[0].Name != "tool_selected" || !reflect.DeepEqual(got[0].Attributes, map[string]any{"tool": "search"}) {
+		t.Errorf("sent events = %+v", got)
+	}
+	if !got[0].Time.Equal(events[0].Time) {
+		t.Errorf("sent time = %v, want %v", got[0].Time, events[0].Time)
+	}
+	if metadata["step"] != 1.0 {
+		t.Errorf("metadata = %v, want the end metadata kept", metadata)
+	}
+
+	// An event added after the span ended is sent with an update.
+	if err := span.AddEvent(ctx, "guardrail_triggered", map[string]any{"rule": "pii"}); err != nil {
+		t.Fatalf("AddEvent after End error: %v", err)
+	}
+	info = &SpanInfo{Metadata: created("PATCH /v1/private/spans/batch").Metadata}
+	if got := info.Events(); len(got) != 3 || got[2].Name != "guardrail_triggered" {
+		t.Errorf("events after End = %+v", got)
+	}
+}
+
+func TestSpanAddEventConcurrent(t *testing.T) {
+	span := &Span{}
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = span.AddEvent(context.Background(), "step", nil)
+		}()
+	}
+	wg.Wait()
+	if n := len(span.Events()); n != 10 {
+		t.Errorf("got %d events, want 10", n)
+	}
+}
+
+func TestSpanAddEventDuringEnd(t *testing.T) {
+	ts, created := newCreateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "agent")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	span, err := trace.Span(ctx, "plan")
+	if err != nil {
+		t.Fatalf("Span error: %v", err)
+	}
+
+	// Events added while the span ends are sent either with End or after it.
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = span.AddEvent(ctx, "step", map[string]any{"n": 1})
+		}()
+	}
+	if err := span.End(ctx, WithSpanMetadata(map[string]any{"step": 1})); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+	wg.Wait()
+	if err := span.AddEvent(ctx, "last", nil); err != nil {
+		t.Fatalf("AddEvent after End error: %v", err)
+	}
+
+	metadata := created("PATCH /v1/private/spans/batch").Metadata
+	if got := (&SpanInfo{Metadata: metadata}).Events(); len(got) != 11 || got[10].Name != "last" {
+		t.Errorf("sent %d events, want 11 ending with last", len(got))
+	}
+	if metadata["step"] != 1.0 {
+		t.Errorf("metadata = %v, want the end metadata kept", metadata)
+	}
+}
+
+func TestSpanAddEventRacingEnd(t *testing.T) {
+	ts, _ := newCreateServer()
+	defer ts.Close()
+
+	// While End's update is in flight, add an event to the ended span.
+	var span *Span
+	var first atomic.Bool
+	var added sync.WaitGroup
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Method == http.MethodPatch && first.CompareAndSwap(false, true) {
+			added.Add(1)
+			go func() {
+				defer added.Done()
+				_ = span.AddEvent(context.Background(), "late", nil)
+			}()
+			time.Sleep(50 * time.Millisecond)
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "agent")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	span, err = trace.Span(ctx, "plan")
+	if err != nil {
+		t.Fatalf("Span error: %v", err)
+	}
+	if err := span.AddEvent(ctx, "step", nil); err != nil {
+		t.Fatalf("AddEvent error: %v", err)
+	}
+	if err := span.End(ctx); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+	added.Wait()
+
+	// The last update the server received carries every event.
+	updates := ts.RequestsFor(http.MethodPatch, "/v1/private/spans/batch")
+	var body struct {
+		Update createdEntity `json:"update"`
+	}
+	if len(updates) != 2 || updates[1].DecodeJSON(&body) != nil {
+		t.Fatalf("got %d span updates, want 2", len(updates))
+	}
+	if got := (&SpanInfo{Metadata: body.Update.Metadata}).Events(); len(got) != 2 || got[1].Name != "late" {
+		t.Errorf("last update events = %+v, want step and late", got)
+	}
+}
+
+// roundTripFunc adapts a function to an http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestSpanEventsNoop(t *testing.T) {
+	ctx := context.Background()
+	if err := noopSpan.AddEvent(ctx, "ignored", nil); err != nil || len(noopSpan.Events()) != 0 {
+		t.Errorf("noop span AddEvent = %v, events %v", err, noopSpan.Events())
+	}
+	if err := AddSpanEvent(ctx, "orphan", nil); !errors.Is(err, ErrNoActiveSpan) {
+		t.Errorf("AddSpanEvent without a span = %v, want ErrNoActiveSpan", err)
+	}
+	if events := (&SpanInfo{Metadata: map[string]any{"other": 1}}).Events(); events != nil {
+		t.Errorf("events = %v, want nil", events)
+	}
+}
@@ -5,6 +5,20 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/plexusone/opik-go/metadata"
+)
+
+// Metadata keys written when a stream is aborted before it finished, so
+// cancelled streams can be filtered with metadata.status = "cancelled".
+const (
+	MetadataStreamStatus         = "status"
+	MetadataAbortReason          = "abort_reason"
+	MetadataPartialContentLength = "partial_content_length"
+	MetadataElapsedMs            = "elapsed_ms"
+
+	// StreamStatusCancelled is the status of an aborted stream.
+	StreamStatusCancelled = "cancelled"
 )
 
 // StreamChunk represents a chunk of streaming data.
@@ -56,9 +70,7 @@ func (a *StreamAccumulator) AddChunk(chunk StreamChunk) {
 		a.finishReason = chunk.FinishReason
 	}
 
-	for k, v := range chunk.Metadata {
-		a.metadata[k] = v
-	}
+	metadata.Metadata(a.metadata).Merge(chunk.Metadata)
 }
 
 // Content returns the accumulated content.
@@ -113,11 +125,7 @@ func (a *StreamAccumulator) FinishReason() string {
 func (a *StreamAccumulator) Metadata() map[string]any {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	result := make(map[string]any, len(a.metadata))
-	for k, v := range a.metadata {
-		result[k] = v
-	}
-	return result
+	return metadata.Metadata(a.metadata).Clone()
 }
 
 // ToOutput returns the accumulator as a span output.
@@ -139,6 +147,11 @@ type StreamingSpan struct {
 	accumulator *StreamAccumulator
 	startTime   time.Time
 	onChunk     func(chunk StreamChunk)
+
+	mu       sync.Mutex
+	metrics  []StreamingMetric
+	signals  []StreamSignal
+	onSignal func(signal StreamSignal)
 }
 
 // NewStreamingSpan creates a new streaming span wrapper.
@@ -170,12 +183,73 @@ func (s *StreamingSpan) AddChunk(content string, opts ...StreamChunkOption) {
 	}
 
 	s.accumulator.AddChunk(chunk)
+	s.observe(chunk)
 
 	if s.onChunk != nil {
 		s.onChunk(chunk)
 	}
 }
 
+// AddMetrics adds streaming metrics that observe every chunk received from
+// now on. Their signals are delivered to the OnSignal callback as they are
+// raised and recorded in the span's metadata when it ends.
+func (s *StreamingSpan) AddMetrics(metrics ...StreamingMetric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics = append(s.metrics, metrics...)
+}
+
+// OnSignal sets a callback for signals raised by streaming metrics, for
+// example to abort the stream on a policy violation.
+func (s *StreamingSpan) OnSignal(fn func(signal StreamSignal)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onSignal = fn
+}
+
+// Signals returns the signals raised so far by streaming metrics.
+func (s *StreamingSpan) Signals() []StreamSignal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]StreamSignal(nil), s.signals...)
+}
+
+// observe runs the streaming metrics on a chunk that was just accumulated.
+func (s *StreamingSpan) observe(chunk StreamChunk) {
+	s.mu.Lock()
+	metrics, onSignal := s.metrics, s.onSignal
+	s.mu.Unlock()
+	if len(metrics) == 0 {
+		return
+	}
+
+	content := s.accumulator.Content()
+	progress := StreamProgress{
+		Chunk:    chunk,
+		Content:  content,
+		Previous: content[:len(content)-len(chunk.Content)],
+		Elapsed:  time.Since(s.startTime),
+	}
+	for _, m := range metrics {
+		signal := m.Observe(progress)
+		if signal == nil {
+			continue
+		}
+		if signal.Metric == "" {
+			signal.Metric = m.Name()
+		}
+		signal.ChunkIndex = chunk.Index
+		signal.ElapsedMs = progress.Elapsed.Milliseconds()
+
+		s.mu.Lock()
+		s.signals = append(s.signals, *signal)
+		s.mu.Unlock()
+		if onSignal != nil {
+			onSignal(*signal)
+		}
+	}
+}
+
 // StreamChunkOption configures a stream chunk.
 type StreamChunkOption func(*StreamChunk)
 
@@ -203,7 +277,36 @@ func WithChunkMetadata(key string, value any) StreamChunkOption {
 
 // End ends the streaming span with accumulated data.
 func (s *StreamingSpan) End(ctx context.Context, opts ...SpanOption) error {
-	// Add streaming metadata
+	allOpts := append([]SpanOption{
+		WithSpanOutput(s.accumulator.ToOutput()),
+		WithSpanMetadata(s.metadata()),
+	}, opts...)
+
+	return s.span.End(ctx, allOpts...)
+}
+
+// Abort ends the streaming span before the stream finished, for example
+// because the client cancelled the request or the connection dropped. The
+// partial output is recorded along with a "cancelled" status, the reason,
+// the length of the partial content and the elapsed time. The span is
+// recorded even if ctx is already cancelled.
+func (s *StreamingSpan) Abort(ctx context.Context, reason string, opts ...SpanOption) error {
+	metadata := s.metadata()
+	metadata[MetadataStreamStatus] = StreamStatusCancelled
+	metadata[MetadataAbortReason] = reason
+	metadata[MetadataPartialContentLength] = len(s.accumulator.Content())
+	metadata[MetadataElapsedMs] = time.Since(s.startTime).Milliseconds()
+
+	allOpts := append([]SpanOption{
+		WithSpanOutput(s.accumulator.ToOutput()),
+		WithSpanMetadata(metadata),
+	}, opts...)
+
+	return s.span.End(context.WithoutCancel(ctx), allOpts...)
+}
+
+// metadata returns the streaming metadata recorded when the span ends.
+func (s *StreamingSpan) metadata() map[string]any {
 	metadata := map[string]any{
 		"streaming":           true,
 		"chunk_count":         s.accumulator.ChunkCount(),
@@ -211,13 +314,10 @@ func (s *StreamingSpan) End(ctx context.Context, opts ...SpanOption) error {
 		"stream_duration_ms":  s.accumulator.Duration().Milliseconds(),
 		"total_tokens":        s.accumulator.TotalTokens(),
 	}
-
-	allOpts := append([]SpanOption{
-		WithSpanOutput(s.accumulator.ToOutput()),
-		WithSpanMetadata(metadata),
-	}, opts...)
-
-	return s.span.End(ctx, allOpts...)
+	if signals := s.Signals(); len(signals) > 0 {
+		metadata[MetadataStreamSignals] = signals
+	}
+	return metadata
 }
 
 // Span returns the underlying span.
@@ -281,6 +381,7 @@ func NewTracingStreamHandler(inner StreamHandler, span *Span) *TracingStreamHand
 // HandleChunk processes a chunk and tracks it.
 func (h *TracingStreamHandler) HandleChunk(chunk StreamChunk) error {
 	h.streamingSpan.accumulator.AddChunk(chunk)
+	h.streamingSpan.observe(chunk)
 	return h.inner.HandleChunk(chunk)
 }
 
@@ -0,0 +1,133 @@
+package opik
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MetadataStreamSignals is the metadata key under which the signals raised by
+// streaming metrics are recorded when a streaming span ends.
+const MetadataStreamSignals = "stream_signals"
+
+// StreamProgress is the state of a stream passed to a StreamingMetric after
+// each chunk.
+type StreamProgress struct {
+	// Chunk is the chunk just received.
+	Chunk StreamChunk
+	// Content is the accumulated content, including Chunk.
+	Content string
+	// Previous is the accumulated content before Chunk.
+	Previous string
+	// Elapsed is the time since the stream started.
+	Elapsed time.Duration
+}
+
+// StreamSignal is a quality signal raised by a StreamingMetric while a
+// stream is in progress.
+type StreamSignal struct {
+	// Metric is the name of the metric that raised the signal.
+	Metric string `json:"metric"`
+	// Value is the metric's value, such as a latency in milliseconds or a
+	// score.
+	Value float64 `json:"value"`
+	// Reason explains the signal.
+	Reason string `json:"reason,omitempty"`
+	// Violation reports whether the signal is a policy violation that may
+	// warrant aborting the stream.
+	Violation bool `json:"violation,omitempty"`
+	// ChunkIndex is the index of the chunk that raised the signal.
+	ChunkIndex int `json:"chunk_index"`
+	// ElapsedMs is the time since the stream started, in milliseconds.
+	ElapsedMs int64 `json:"elapsed_ms"`
+}
+
+// StreamingMetric evaluates a stream incrementally, so quality signals are
+// available before the stream finishes. Observe is called after each chunk
+// and returns a signal when the metric has something to report, or nil.
+//
+// Metrics receive the content before and after each chunk, so most can
+// detect the chunk at which they fire without keeping state and a single
+// metric can be shared between streams.
+type StreamingMetric interface {
+	Name() string
+	Observe(progress StreamProgress) *StreamSignal
+}
+
+// TimeToFirstUsefulToken returns a streaming metric that reports the time
+// until the stream produced its first content that is not whitespace or one
+// of the given filler prefixes (such as "Sure," or "Let me think"). The value
+// is in milliseconds.
+func TimeToFirstUsefulToken(fillers ...string) StreamingMetric {
+	return &firstUsefulTokenMetric{fillers: fillers}
+}
+
+type firstUsefulTokenMetric struct {
+	fillers []string
+}
+
+func (m *firstUsefulTokenMetric) Name() string {
+	return "time_to_first_useful_token"
+}
+
+func (m *firstUsefulTokenMetric) Observe(p StreamProgress) *StreamSignal {
+	if m.useful(p.Previous) || !m.useful(p.Content) {
+		return nil
+	}
+	return &StreamSignal{
+		Metric: m.Name(),
+		Value:  float64(p.Elapsed.Milliseconds()),
+	}
+}
+
+// useful reports whether content has anything beyond whitespace and fillers.
+func (m *firstUsefulTokenMetric) useful(content string) bool {
+	rest := strings.TrimSpace(content)
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, f := range m.fillers {
+			if f != "" && strings.HasPrefix(rest, f) {
+				rest = strings.TrimSpace(strings.TrimPrefix(rest, f))
+				trimmed = true
+			}
+		}
+	}
+	if rest == "" {
+		return false
+	}
+	// A partial filler may still be completed by the next chunk.
+	for _, f := range m.fillers {
+		if strings.HasPrefix(f, rest) {
+			return false
+		}
+	}
+	return true
+}
+
+// PolicyViolation returns a streaming metric that raises a violation as soon
+// as the accumulated content matches pattern, for example a leaked secret or
+// a banned phrase. Matches spanning chunk boundaries are detected.
+func PolicyViolation(name string, pattern *regexp.Regexp) StreamingMetric {
+	return &policyViolationMetric{name: name, pattern: pattern}
+}
+
+type policyViolationMetric struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+func (m *policyViolationMetric) Name() string {
+	return m.name
+}
+
+func (m *policyViolationMetric) Observe(p StreamProgress) *StreamSignal {
+	if m.pattern.MatchString(p.Previous) || !m.pattern.MatchString(p.Content) {
+		return nil
+	}
+	return &StreamSignal{
+		Metric:    m.name,
+		Value:     1,
+		Reason:    "content matched " + m.pattern.String(),
+		Violation: true,
+	}
+}
@@ -0,0 +1,96 @@
+package opik
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestTimeToFirstUsefulToken(t *testing.T) {
+	s := NewStreamingSpan(&Span{id: "span-123"})
+	s.AddMetrics(TimeToFirstUsefulToken("Sure,", "Let me think."))
+
+	for _, chunk := range []string{" ", "Su", "re, ", "Let me think. ", "Paris", " is the capital."} {
+		s.AddChunk(chunk)
+	}
+
+	signals := s.Signals()
+	if len(signals) != 1 {
+		t.Fatalf("signals = %+v, want one", signals)
+	}
+	if signals[0].Metric != "time_to_first_useful_token" || signals[0].ChunkIndex != 4 || signals[0].Violation {
+		t.Errorf("signal = %+v, want first useful token at chunk 4", signals[0])
+	}
+}
+
+func TestPolicyViolation(t *testing.T) {
+	s := NewStreamingSpan(&Span{id: "span-123"})
+	s.AddMetrics(PolicyViolation("secret_leak", regexp.MustCompile(`sk-[a-z0-9]{8}`)))
+
+	var received []StreamSignal
+	s.OnSignal(func(signal StreamSignal) {
+		received = append(received, signal)
+	})
+
+	// The match spans two chunks and is reported once.
+	for _, chunk := range []string{"Your key is sk-ab", "cd1234", " and sk-zzzz9999"} {
+		s.AddChunk(chunk)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("received = %+v, want one signal", received)
+	}
+	if !received[0].Violation || received[0].Metric != "secret_leak" || received[0].ChunkIndex != 1 {
+		t.Errorf("signal = %+v", received[0])
+	}
+	if len(s.Signals()) != 1 {
+		t.Errorf("Signals() = %+v", s.Signals())
+	}
+}
+
+func TestTracingStreamHandlerMetrics(t *testing.T) {
+	handler := NewTracingStreamHandler(NewBufferingStreamHandler(nil), &Span{id: "span-123"})
+	handler.StreamingSpan().AddMetrics(TimeToFirstUsefulToken())
+
+	_ = handler.HandleChunk(StreamChunk{Content: "\n"})
+	_ = handler.HandleChunk(StreamChunk{Index: 1, Content: "Hi"})
+
+	signals := handler.StreamingSpan().Signals()
+	if len(signals) != 1 || signals[0].ChunkIndex != 1 {
+		t.Errorf("signals = %+v", signals)
+	}
+}
+
+func TestStreamingSpanEndRecordsSignals(t *testing.T) {
+	ts, metadata := newUpdateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "stream")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	span, err := trace.Span(ctx, "llm")
+	if err != nil {
+		t.Fatalf("Span error: %v", err)
+	}
+
+	s := NewStreamingSpan(span)
+	s.AddMetrics(TimeToFirstUsefulToken())
+	s.AddChunk("Hello")
+	if err := s.End(ctx); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+
+	signals, ok := metadata("/v1/private/spans/batch")[MetadataStreamSignals].([]any)
+	if !ok || len(signals) != 1 {
+		t.Fatalf("%s = %v", MetadataStreamSignals, metadata("/v1/private/spans/batch")[MetadataStreamSignals])
+	}
+	if signal := signals[0].(map[string]any); signal["metric"] != "time_to_first_useful_token" {
+		t.Errorf("signal = %v", signal)
+	}
+}
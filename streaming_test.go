@@ -1,6 +1,7 @@
 package opik
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -377,3 +378,50 @@ func TestTracingStreamHandlerHandleChunk(t *testing.T) {
 		t.Error("inner handler should receive chunk")
 	}
 }
+
+func TestStreamingSpanAbort(t *testing.T) {
+	ts, metadata := newUpdateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	trace, err := client.Trace(context.Background(), "stream")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = ContextWithTrace(ctx, trace)
+	_, streamingSpan, err := StartStreamingSpan(ctx, "llm")
+	if err != nil {
+		t.Fatalf("StartStreamingSpan error: %v", err)
+	}
+	streamingSpan.AddChunk("Hello ")
+	streamingSpan.AddChunk("wor")
+	cancel()
+
+	if err := streamingSpan.Abort(ctx, "client disconnected"); err != nil {
+		t.Fatalf("Abort error: %v", err)
+	}
+	if streamingSpan.Span().EndTime() == nil {
+		t.Fatal("span should be ended")
+	}
+
+	m := metadata("/v1/private/spans/batch")
+	if m[MetadataStreamStatus] != StreamStatusCancelled || m[MetadataAbortReason] != "client disconnected" {
+		t.Errorf("metadata = %v", m)
+	}
+	if m[MetadataPartialContentLength] != float64(9) || m["chunk_count"] != float64(2) {
+		t.Errorf("metadata = %v, want partial length 9 and 2 chunks", m)
+	}
+	if _, ok := m[MetadataElapsedMs]; !ok {
+		t.Errorf("metadata = %v, want elapsed time", m)
+	}
+
+	// Ending an aborted span is a no-op.
+	if err := streamingSpan.End(context.Background()); err != nil {
+		t.Errorf("End after Abort error: %v", err)
+	}
+}
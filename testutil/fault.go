@@ -0,0 +1,290 @@
+package testutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Fault is a failure injected into a response. The zero Fault injects
+// nothing. Latency is added before any other fault.
+type Fault struct {
+	// Latency delays the response.
+	Latency time.Duration
+	// Status replaces the response with an error response of this status,
+	// such as 429 or 503.
+	Status int
+	// RetryAfter is sent as the Retry-After header of a Status response.
+	RetryAfter time.Duration
+	// Reset drops the connection without a response. Go's transport
+	// resends a GET or other idempotent request once if its connection was
+	// reused, so the request may show up twice.
+	Reset bool
+	// Truncate cuts the response body in half, so JSON responses are
+	// partial.
+	Truncate bool
+}
+
+// IsZero reports whether f injects nothing.
+func (f Fault) IsZero() bool {
+	return f == Fault{}
+}
+
+// merge combines f with g: latencies add up, and for other fields f wins.
+func (f Fault) merge(g Fault) Fault {
+	f.Latency += g.Latency
+	if f.Status == 0 {
+		f.Status, f.RetryAfter = g.Status, g.RetryAfter
+	}
+	f.Reset = f.Reset || g.Reset
+	f.Truncate = f.Truncate || g.Truncate
+	return f
+}
+
+// FaultRule returns the fault to inject into request n, counting from 0,
+// or the zero Fault to leave it alone.
+type FaultRule func(n int, req *http.Request) Fault
+
+// Always injects f into every request.
+func Always(f Fault) FaultRule {
+	return func(int, *http.Request) Fault { return f }
+}
+
+// Burst injects f into count requests, starting with request start. A
+// burst of 429s is Burst(0, 3, Fault{Status: http.StatusTooManyRequests}).
+func Burst(start, count int, f Fault) FaultRule {
+	return func(n int, _ *http.Request) Fault {
+		if n >= start && n < start+count {
+			return f
+		}
+		return Fault{}
+	}
+}
+
+// EveryNth injects f into every nth request: requests n-1, 2n-1, and so
+// on.
+func EveryNth(n int, f Fault) FaultRule {
+	return func(i int, _ *http.Request) Fault {
+		if n > 0 && (i+1)%n == 0 {
+			return f
+		}
+		return Fault{}
+	}
+}
+
+// Randomly injects f into requests with probability p. The same seed
+// injects into the same requests, so failures can be reproduced.
+func Randomly(p float64, seed uint64, f Fault) FaultRule {
+	var mu sync.Mutex
+	rng := rand.New(rand.NewPCG(seed, seed)) //nolint:gosec // G404: reproducible test faults
+	return func(int, *http.Request) Fault {
+		mu.Lock()
+		defer mu.Unlock()
+		if rng.Float64() < p {
+			return f
+		}
+		return Fault{}
+	}
+}
+
+// OnPath limits rule to requests for path.
+func OnPath(path string, rule FaultRule) FaultRule {
+	return func(n int, req *http.Request) Fault {
+		if req.URL.Path != path {
+			return Fault{}
+		}
+		return rule(n, req)
+	}
+}
+
+// faultPlan numbers requests and picks their faults.
+type faultPlan struct {
+	mu       sync.Mutex
+	rules    []FaultRule
+	requests int
+	injected int
+}
+
+func (p *faultPlan) next(req *http.Request) Fault {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := p.requests
+	p.requests++
+	var f Fault
+	for _, rule := range p.rules {
+		f = f.merge(rule(n, req))
+	}
+	if !f.IsZero() {
+		p.injected++
+	}
+	return f
+}
+
+func (p *faultPlan) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.injected
+}
+
+// InjectFaults makes the server inject the faults of rules into its
+// responses, numbering requests from 0 in the order they arrive. Faults
+// apply to every route, and to requests without a route. Requests are
+// recorded even if their response is replaced.
+func (ms *MockServer) InjectFaults(rules ...FaultRule) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.faults = &faultPlan{rules: rules}
+}
+
+// InjectedFaults returns the number of responses the server has injected a
+// fault into.
+func (ms *MockServer) InjectedFaults() int {
+	ms.mu.Lock()
+	plan := ms.faults
+	ms.mu.Unlock()
+	if plan == nil {
+		return 0
+	}
+	return plan.count()
+}
+
+// serveFault writes the response of next with f injected.
+func serveFault(w http.ResponseWriter, r *http.Request, f Fault, next http.HandlerFunc) {
+	if f.Latency > 0 {
+		timer := time.NewTimer(f.Latency)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	switch {
+	case f.Reset:
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				if tcp, ok := conn.(*net.TCPConn); ok {
+					// Close with RST rather than FIN.
+					_ = tcp.SetLinger(0)
+				}
+				_ = conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	case f.Status != 0:
+		if f.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int((f.RetryAfter+time.Second-1)/time.Second)))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(f.Status)
+		_, _ = fmt.Fprintf(w, `{"errors":["injected fault: %s"]}`, http.StatusText(f.Status))
+	case f.Truncate:
+		rec := httptest.NewRecorder()
+		next(rec, r)
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.Header().Del("Content-Length")
+		w.WriteHeader(rec.Code)
+		body := rec.Body.Bytes()
+		_, _ = w.Write(body[:len(body)/2]) //nolint:gosec // G705: Test mock server, not production web
+	default:
+		next(w, r)
+	}
+}
+
+// FaultyTransport is an http.RoundTripper that injects faults into the
+// requests of a real client, for example
+//
+//	transport := testutil.NewFaultyTransport(nil,
+//		testutil.Burst(0, 3, testutil.Fault{Status: http.StatusTooManyRequests}))
+//	client, _ := opik.NewClient(opik.WithHTTPClient(&http.Client{Transport: transport}))
+//
+// Status faults are answered without sending the request, resets fail the
+// request with ECONNRESET, and truncated responses are cut after the real
+// response arrives.
+type FaultyTransport struct {
+	inner http.RoundTripper
+	plan  *faultPlan
+}
+
+// NewFaultyTransport returns a transport that sends requests with inner,
+// or http.DefaultTransport if inner is nil, and injects the faults of
+// rules.
+func NewFaultyTransport(inner http.RoundTripper, rules ...FaultRule) *FaultyTransport {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	return &FaultyTransport{inner: inner, plan: &faultPlan{rules: rules}}
+}
+
+// InjectedFaults returns the number of requests the transport has injected
+// a fault into.
+func (t *FaultyTransport) InjectedFaults() int {
+	return t.plan.count()
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *FaultyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f := t.plan.next(req)
+	if f.IsZero() {
+		return t.inner.RoundTrip(req)
+	}
+
+	if f.Latency > 0 {
+		timer := time.NewTimer(f.Latency)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			closeBody(req)
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	switch {
+	case f.Reset:
+		closeBody(req)
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	case f.Status != 0:
+		closeBody(req)
+		rec := httptest.NewRecorder()
+		serveFault(rec, req, Fault{Status: f.Status, RetryAfter: f.RetryAfter}, nil)
+		resp := rec.Result()
+		resp.Request = req
+		return resp, nil
+	case f.Truncate:
+		resp, err := t.inner.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body[:len(body)/2]))
+		resp.ContentLength = int64(len(body) / 2)
+		resp.Header.Del("Content-Length")
+		return resp, nil
+	}
+	return t.inner.RoundTrip(req)
+}
+
+// closeBody closes the body of a request that is not sent, as a
+// RoundTripper must.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
@@ -0,0 +1,165 @@
+package testutil
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMockServerFaults(t *testing.T) {
+	ms := NewMockServer()
+	defer ms.Close()
+	ms.OnGet("/api/item").RespondJSON(http.StatusOK, map[string]string{"id": "item-1", "name": "a long enough name"})
+	ms.InjectFaults(
+		Burst(0, 2, Fault{Status: http.StatusTooManyRequests, RetryAfter: 2 * time.Second}),
+		Burst(2, 1, Fault{Reset: true}),
+		Burst(3, 1, Fault{Truncate: true}),
+		Burst(4, 1, Fault{Latency: 50 * time.Millisecond}),
+	)
+	// Without keep-alives, the reset request is not resent on a new
+	// connection.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	for i := range 2 {
+		resp, err := client.Get(ms.URL() + "/api/item")
+		if err != nil {
+			t.Fatalf("request %d error: %v", i, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "2" {
+			t.Errorf("request %d: status %d, Retry-After %q, want 429 and 2", i, resp.StatusCode, resp.Header.Get("Retry-After"))
+		}
+	}
+
+	if resp, err := client.Get(ms.URL() + "/api/item"); err == nil {
+		resp.Body.Close()
+		t.Error("reset request succeeded, want a connection error")
+	}
+
+	resp, err := client.Get(ms.URL() + "/api/item")
+	if err != nil {
+		t.Fatalf("truncated request error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	var v map[string]string
+	if err := json.Unmarshal(body, &v); err == nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("truncated response %d %q decoded, want partial JSON", resp.StatusCode, body)
+	}
+
+	start := time.Now()
+	resp, err = client.Get(ms.URL() + "/api/item")
+	if err != nil {
+		t.Fatalf("slow request error: %v", err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || resp.StatusCode != http.StatusOK {
+		t.Errorf("slow request took %v with status %d, want at least 50ms and 200", elapsed, resp.StatusCode)
+	}
+
+	if got := ms.InjectedFaults(); got != 5 {
+		t.Errorf("InjectedFaults = %d, want 5", got)
+	}
+	if got := ms.RouteCallCount("GET", "/api/item"); got != 5 {
+		t.Errorf("RouteCallCount = %d, want every request recorded", got)
+	}
+}
+
+func TestFaultyTransport(t *testing.T) {
+	ms := NewMockServer()
+	defer ms.Close()
+	ms.OnPost("/api/create").RespondJSON(http.StatusCreated, map[string]string{"id": "123"})
+
+	transport := NewFaultyTransport(nil,
+		Burst(0, 1, Fault{Status: http.StatusServiceUnavailable}),
+		Burst(1, 1, Fault{Reset: true}),
+		Burst(2, 1, Fault{Truncate: true}),
+	)
+	client := &http.Client{Transport: transport}
+	post := func() (*http.Response, error) {
+		return client.Post(ms.URL()+"/api/create", "application/json", strings.NewReader(`{}`))
+	}
+
+	resp, err := post()
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("first request = %v, %v, want 503", resp, err)
+	}
+	resp.Body.Close()
+
+	if _, err := post(); err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Errorf("second request error = %v, want a connection reset", err)
+	}
+
+	resp, err = post()
+	if err != nil {
+		t.Fatalf("third request error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if json.Valid(body) {
+		t.Errorf("third response %q is valid JSON, want it truncated", body)
+	}
+
+	resp, err = post()
+	if err != nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("fourth request = %v, %v, want 201", resp, err)
+	}
+	resp.Body.Close()
+
+	// Status and reset faults are answered without reaching the server.
+	if got := ms.RequestCount(); got != 2 {
+		t.Errorf("server got %d requests, want 2", got)
+	}
+	if got := transport.InjectedFaults(); got != 3 {
+		t.Errorf("InjectedFaults = %d, want 3", got)
+	}
+}
+
+func TestFaultRules(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/a", nil)
+	fault := Fault{Reset: true}
+
+	var nth []int
+	rule := EveryNth(3, fault)
+	for i := range 9 {
+		if !rule(i, req).IsZero() {
+			nth = append(nth, i)
+		}
+	}
+	if len(nth) != 3 || nth[0] != 2 || nth[2] != 8 {
+		t.Errorf("EveryNth(3) faulted %v, want [2 5 8]", nth)
+	}
+
+	pick := func(r FaultRule) []bool {
+		out := make([]bool, 50)
+		for i := range out {
+			out[i] = !r(i, req).IsZero()
+		}
+		return out
+	}
+	a, b := pick(Randomly(0.3, 7, fault)), pick(Randomly(0.3, 7, fault))
+	hits := 0
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatal("Randomly with the same seed faulted different requests")
+		}
+		if a[i] {
+			hits++
+		}
+	}
+	if hits == 0 || hits == len(a) {
+		t.Errorf("Randomly(0.3) faulted %d of %d requests", hits, len(a))
+	}
+
+	if !OnPath("/b", Always(fault))(0, req).IsZero() {
+		t.Error("OnPath(/b) faulted a request for /a")
+	}
+
+	merged := Fault{Latency: time.Second}.merge(Fault{Latency: time.Second, Status: 429, Truncate: true})
+	if merged != (Fault{Latency: 2 * time.Second, Status: 429, Truncate: true}) {
+		t.Errorf("merge = %+v", merged)
+	}
+}
@@ -0,0 +1,141 @@
+package testutil
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// LeakOption configures WithGoroutineLeakCheck.
+type LeakOption func(*leakConfig)
+
+type leakConfig struct {
+	timeout time.Duration
+	ignore  []string
+}
+
+// LeakCheckTimeout sets how long to wait for goroutines to exit before
+// reporting a leak (default 1s).
+func LeakCheckTimeout(d time.Duration) LeakOption {
+	return func(c *leakConfig) {
+		c.timeout = d
+	}
+}
+
+// IgnoreGoroutines ignores goroutines whose stack contains any of the given
+// substrings, such as a function name.
+func IgnoreGoroutines(substrings ...string) LeakOption {
+	return func(c *leakConfig) {
+		c.ignore = append(c.ignore, substrings...)
+	}
+}
+
+// defaultIgnoredGoroutines are started by the runtime, the testing package,
+// and net/http, and are not owned by the code under test.
+var defaultIgnoredGoroutines = []string{
+	"testing.(*T).Run",
+	"testing.tRunner",
+	"testing.runTests",
+	"testing.(*M).",
+	"runtime.goexit0",
+	"os/signal.signal_recv",
+	"net/http.(*persistConn)",
+	"net/http/httptest.(*Server)",
+	"net/http.(*Server).Serve",
+	"net/http.(*conn).serve",
+}
+
+// WithGoroutineLeakCheck fails the test if goroutines started during the
+// test are still running when it finishes. Call it first in the test so its
+// check runs after all other cleanups, such as closing test servers:
+//
+//	func TestEngineCancel(t *testing.T) {
+//	    testutil.WithGoroutineLeakCheck(t)
+//	    ...
+//	}
+//
+// Goroutines may take a moment to exit after cancellation, so the check
+// waits up to the timeout before failing. Tests using it must not run in
+// parallel with other tests.
+func WithGoroutineLeakCheck(t *testing.T, opts ...LeakOption) {
+	t.Helper()
+	cfg := &leakConfig{timeout: time.Second, ignore: defaultIgnoredGoroutines}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	before := make(map[string]bool)
+	for _, g := range goroutines() {
+		before[g.id] = true
+	}
+
+	t.Cleanup(func() {
+		t.Helper()
+		deadline := time.Now().Add(cfg.timeout)
+		for {
+			leaked := leakedGoroutines(before, cfg.ignore)
+			if len(leaked) == 0 {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("%d goroutine(s) leaked:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+type goroutine struct {
+	id    string
+	stack string
+}
+
+func leakedGoroutines(before map[string]bool, ignore []string) []string {
+	var leaked []string
+	for _, g := range goroutines() {
+		if before[g.id] || ignored(g.stack, ignore) {
+			continue
+		}
+		leaked = append(leaked, g.stack)
+	}
+	return leaked
+}
+
+func ignored(stack string, ignore []string) bool {
+	for _, s := range ignore {
+		if strings.Contains(stack, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// goroutines returns all goroutines except the calling one.
+func goroutines() []goroutine {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := bytes.Split(buf, []byte("\n\n"))
+	result := make([]goroutine, 0, len(stacks))
+	// The first stack is the calling goroutine.
+	for _, s := range stacks[1:] {
+		stack := string(s)
+		// Stacks start with "goroutine 123 [state]:".
+		fields := strings.Fields(stack)
+		if len(fields) < 2 || fields[0] != "goroutine" {
+			continue
+		}
+		result = append(result, goroutine{id: fields[1], stack: stack})
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package testutil
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func blockedInLeakTest(stop <-chan struct{}) {
+	<-stop
+}
+
+func TestLeakedGoroutines(t *testing.T) {
+	before := make(map[string]bool)
+	for _, g := range goroutines() {
+		before[g.id] = true
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		blockedInLeakTest(stop)
+	}()
+
+	var leaked []string
+	deadline := time.Now().Add(time.Second)
+	for {
+		leaked = leakedGoroutines(before, defaultIgnoredGoroutines)
+		if len(leaked) == 1 && strings.Contains(leaked[0], "blockedInLeakTest") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("leaked = %v, want the blocked goroutine", leaked)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := leakedGoroutines(before, []string{"blockedInLeakTest"}); len(got) != 0 {
+		t.Errorf("ignored goroutine reported: %v", got)
+	}
+
+	close(stop)
+	<-done
+	deadline = time.Now().Add(time.Second)
+	for len(leakedGoroutines(before, defaultIgnoredGoroutines)) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("goroutine still reported after exiting")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWithGoroutineLeakCheck(t *testing.T) {
+	WithGoroutineLeakCheck(t, LeakCheckTimeout(time.Second))
+
+	// A goroutine that exits shortly after the test body is not a leak.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+	}()
+}
@@ -1,9 +1,12 @@
 package testutil
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"sync"
 )
 
@@ -14,12 +17,16 @@ type MockServer struct {
 	mu       sync.Mutex
 	requests []*RecordedRequest
 	routes   map[string]*Route
+	// unmatched serves requests matching no route, if set.
+	unmatched *Route
+	faults    *faultPlan
 }
 
 // RecordedRequest captures details of an incoming request.
 type RecordedRequest struct {
 	Method  string
 	Path    string
+	Query   url.Values
 	Headers http.Header
 	Body    []byte
 }
@@ -96,6 +103,17 @@ func (ms *MockServer) On(method, path string) *Route {
 	return route
 }
 
+// OnUnmatched registers the route for requests that match no other route,
+// which otherwise get 404 Not Found. It is useful for recording every
+// request a client sends, such as OnUnmatched().Respond(204, nil).
+func (ms *MockServer) OnUnmatched() *Route {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.unmatched = &Route{StatusCode: http.StatusOK}
+	return ms.unmatched
+}
+
 // Respond sets the response for a route.
 func (r *Route) Respond(statusCode int, body any) *Route {
 	r.StatusCode = statusCode
@@ -120,7 +138,8 @@ func (r *Route) WithHeaders(headers map[string]string) *Route {
 	return r
 }
 
-// WithHandler sets a custom handler.
+// WithHandler sets a custom handler. The request body it is passed can be
+// read again, although the server has recorded it.
 func (r *Route) WithHandler(handler http.HandlerFunc) *Route {
 	r.Handler = handler
 	return r
@@ -131,12 +150,14 @@ func (ms *MockServer) handler(w http.ResponseWriter, r *http.Request) {
 	body := make([]byte, 0)
 	if r.Body != nil {
 		body, _ = readBody(r)
+		r.Body = io.NopCloser(bytes.NewReader(body))
 	}
 
 	ms.mu.Lock()
 	ms.requests = append(ms.requests, &RecordedRequest{
 		Method:  r.Method,
 		Path:    r.URL.Path,
+		Query:   r.URL.Query(),
 		Headers: r.Header,
 		Body:    body,
 	})
@@ -144,16 +165,31 @@ func (ms *MockServer) handler(w http.ResponseWriter, r *http.Request) {
 	// Find matching route
 	key := r.Method + " " + r.URL.Path
 	route, ok := ms.routes[key]
+	if !ok && ms.unmatched != nil {
+		route, ok = ms.unmatched, true
+	}
 	if ok {
 		route.CallCount++
 	}
+	plan := ms.faults
 	ms.mu.Unlock()
 
-	if !ok {
-		http.NotFound(w, r)
+	serve := func(w http.ResponseWriter, r *http.Request) {
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		route.serve(w, r)
+	}
+	if plan != nil {
+		serveFault(w, r, plan.next(r), serve)
 		return
 	}
+	serve(w, r)
+}
 
+// serve writes the route's response.
+func (route *Route) serve(w http.ResponseWriter, r *http.Request) {
 	// Use custom handler if set
 	if route.Handler != nil {
 		route.Handler(w, r)
@@ -234,6 +270,26 @@ func (ms *MockServer) RequestsForPath(path string) []*RecordedRequest {
 	return result
 }
 
+// RequestsFor returns requests matching a method and path, in the order
+// they were received.
+func (ms *MockServer) RequestsFor(method, path string) []*RecordedRequest {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	result := make([]*RecordedRequest, 0)
+	for _, req := range ms.requests {
+		if req.Method == method && req.Path == path {
+			result = append(result, req)
+		}
+	}
+	return result
+}
+
+// DecodeJSON decodes the JSON body of the request into v.
+func (r *RecordedRequest) DecodeJSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
 // Reset clears all recorded requests.
 func (ms *MockServer) Reset() {
 	ms.mu.Lock()
@@ -242,6 +298,9 @@ func (ms *MockServer) Reset() {
 	for _, route := range ms.routes {
 		route.CallCount = 0
 	}
+	if ms.unmatched != nil {
+		ms.unmatched.CallCount = 0
+	}
 }
 
 // RouteCallCount returns how many times a route was called.
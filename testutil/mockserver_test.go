@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -95,6 +96,25 @@ func TestMockServerRoutes(t *testing.T) {
 		}
 	})
 
+	t.Run("handler reads body", func(t *testing.T) {
+		var got string
+		ms.OnPost("/api/echo").WithHandler(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			got = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		resp, err := http.Post(ms.URL()+"/api/echo", "text/plain", strings.NewReader("ping"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if got != "ping" {
+			t.Errorf("handler read body %q, want %q", got, "ping")
+		}
+	})
+
 	t.Run("with headers", func(t *testing.T) {
 		ms.OnGet("/api/headers").Respond(200, "OK").WithHeaders(map[string]string{
 			"X-Custom": "value",
@@ -120,7 +140,7 @@ func TestMockServerRecording(t *testing.T) {
 
 	// Make some requests
 	body := bytes.NewBufferString(`{"data":"test"}`)
-	req, _ := http.NewRequest("POST", ms.URL()+"/api/record", body)
+	req, _ := http.NewRequest("POST", ms.URL()+"/api/record?page=2", body)
 	req.Header.Set("Authorization", "Bearer token123")
 	_, _ = http.DefaultClient.Do(req) //nolint:gosec // G704: Test code hitting local mock server
 
@@ -141,6 +161,9 @@ func TestMockServerRecording(t *testing.T) {
 		if last.Path != "/api/record" {
 			t.Errorf("Path = %q, want /api/record", last.Path)
 		}
+		if last.Query.Get("page") != "2" {
+			t.Errorf("Query = %v, want page=2", last.Query)
+		}
 		if last.Headers.Get("Authorization") != "Bearer token123" {
 			t.Error("Authorization header not recorded")
 		}
@@ -264,3 +287,31 @@ func TestMockServerHTTPMethods(t *testing.T) {
 		})
 	}
 }
+
+func TestMockServerUnmatched(t *testing.T) {
+	ms := NewMockServer()
+	defer ms.Close()
+
+	ms.OnGet("/a").Respond(200, "")
+	ms.OnUnmatched().Respond(http.StatusNoContent, nil)
+
+	for _, path := range []string{"/a", "/b", "/b"} {
+		resp, err := http.Post(ms.URL()+path, "application/json", bytes.NewBufferString(`{"n": 1}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("POST %s status = %d, want 204", path, resp.StatusCode)
+		}
+	}
+
+	reqs := ms.RequestsFor("POST", "/b")
+	if len(reqs) != 2 || len(ms.RequestsFor("GET", "/a")) != 0 {
+		t.Fatalf("RequestsFor = %d requests, want 2", len(reqs))
+	}
+	var body struct{ N int }
+	if err := reqs[0].DecodeJSON(&body); err != nil || body.N != 1 {
+		t.Errorf("DecodeJSON = %+v, %v", body, err)
+	}
+}
@@ -0,0 +1,252 @@
+// Package tracetest provides assertions on the spans an instrumented
+// program records, for tests of instrumentation.
+//
+// ExpectSpan starts an expectation over the spans of an
+// opik.LocalRecording, and each With method narrows it to the spans that
+// also meet a condition. The test fails as soon as no span is left, with a
+// message naming the conditions and listing the recorded spans:
+//
+//	rec := client.Recording()
+//	// ... run the code under test with client ...
+//	tracetest.ExpectSpan(t, rec).
+//	    WithName("gollm.chat").
+//	    WithModel("gpt-4o").
+//	    WithUsageTotalAtLeast(10).
+//	    WithOutputContaining("hello")
+//
+// The package is separate from testutil because it imports opik, which
+// testutil must not: the SDK's own tests use testutil.
+package tracetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// SpanExpectation is a set of conditions on a recorded span, and the spans
+// that meet them so far.
+type SpanExpectation struct {
+	t          testing.TB
+	all        []*opik.RecordedSpan
+	spans      []*opik.RecordedSpan
+	conditions []string
+	failed     bool
+}
+
+// ExpectSpan starts an expectation that rec has recorded a span. It fails
+// the test if rec has no spans.
+func ExpectSpan(t testing.TB, rec *opik.LocalRecording) *SpanExpectation {
+	t.Helper()
+	spans := rec.Spans()
+	slices.SortStableFunc(spans, func(a, b *opik.RecordedSpan) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	e := &SpanExpectation{t: t, all: spans, spans: spans}
+	if len(spans) == 0 {
+		e.failed = true
+		t.Errorf("tracetest: expected a span, but none were recorded")
+	}
+	return e
+}
+
+// Where narrows the expectation to spans for which match returns true.
+// description names the condition in failure messages.
+func (e *SpanExpectation) Where(description string, match func(*opik.RecordedSpan) bool) *SpanExpectation {
+	e.t.Helper()
+	e.conditions = append(e.conditions, description)
+	if e.failed {
+		return e
+	}
+	var kept []*opik.RecordedSpan
+	for _, span := range e.spans {
+		if match(span) {
+			kept = append(kept, span)
+		}
+	}
+	e.spans = kept
+	if len(kept) == 0 {
+		e.failed = true
+		e.t.Errorf("tracetest: no span %s\nrecorded spans:\n%s", strings.Join(e.conditions, ", "), describe(e.all))
+	}
+	return e
+}
+
+// WithName narrows the expectation to spans named name.
+func (e *SpanExpectation) WithName(name string) *SpanExpectation {
+	e.t.Helper()
+	return e.Where(fmt.Sprintf("named %q", name), func(s *opik.RecordedSpan) bool {
+		return s.Name == name
+	})
+}
+
+// WithType narrows the expectation to spans of the span type, such as
+// opik.SpanTypeLLM.
+func (e *SpanExpectation) WithType(spanType string) *SpanExpectation {
+	e.t.Helper()
+	return e.Where(fmt.Sprintf("of type %q", spanType), func(s *opik.RecordedSpan) bool {
+		return s.Type == spanType
+	})
+}
+
+// WithModel narrows the expectation to spans of the model.
+func (e *SpanExpectation) WithModel(model string) *SpanExpectation {
+	e.t.Helper()
+	return e.Where(fmt.Sprintf("with model %q", model), func(s *opik.RecordedSpan) bool {
+		return s.Model == model
+	})
+}
+
+// WithProvider narrows the expectation to spans of the provider.
+func (e *SpanExpectation) WithProvider(provider string) *SpanExpectation {
+	e.t.Helper()
+	return e.Where(fmt.Sprintf("with provider %q", provider), func(s *opik.RecordedSpan) bool {
+		return s.Provider == provider
+	})
+}
+
+// WithParent narrows the expectation to children of the span parent.
+func (e *SpanExpectation) WithParent(parent *opik.RecordedSpan) *SpanExpectation {
+	e.t.Helper()
+	return e.Where(fmt.Sprintf("under span %q", parent.Name), func(s *opik.RecordedSpan) bool {
+		return s.ParentSpanID == parent.ID
+	})
+}
+
+// WithTag narrows the expectation to spans with the tag.
+func (e *SpanExpectation) WithTag(tag string) *SpanExpectation {
+	e.t.Helper()
+	return e.Where(fmt.Sprintf("tagged %q", tag), func(s *opik.RecordedSpan) bool {
+		return slices.Contains(s.Tags, tag)
+	})
+}
+
+// WithMetadata narrows the expectation to spans whose metadata has key set
+// to value. Values are compared in their JSON form, so 1 and 1.0 are equal.
+func (e *SpanExpectation) WithMetadata(key string, value any) *SpanExpectation {
+	e.t.Helper()
+	want := jsonText(value)
+	return e.Where(fmt.Sprintf("with metadata %s=%s", key, want), func(s *opik.RecordedSpan) bool {
+		got, ok := s.Metadata[key]
+		return ok && jsonText(got) == want
+	})
+}
+
+// WithInputContaining narrows the expectation to spans whose input contains
+// substr: a string input directly, and any other input in its JSON form.
+func (e *SpanExpectation) WithInputContaining(substr string) *SpanExpectation {
+	e.t.Helper()
+	return e.Where(fmt.Sprintf("with input containing %q", substr), func(s *opik.RecordedSpan) bool {
+		return s.Input != nil && strings.Contains(text(s.Input), substr)
+	})
+}
+
+// WithOutputContaining narrows the expectation to spans whose output
+// contains substr, as WithInputContaining does for input.
+func (e *SpanExpectation) WithOutputContaining(substr string) *SpanExpectation {
+	e.t.Helper()
+	return e.Where(fmt.Sprintf("with output containing %q", substr), func(s *opik.RecordedSpan) bool {
+		return s.Output != nil && strings.Contains(text(s.Output), substr)
+	})
+}
+
+// WithUsageTotalAtLeast narrows the expectation to spans whose usage has at
+// least n total tokens.
+func (e *SpanExpectation) WithUsageTotalAtLeast(n int) *SpanExpectation {
+	e.t.Helper()
+	return e.WithUsageAtLeast(opik.UsageTotalTokens, n)
+}
+
+// WithUsageAtLeast narrows the expectation to spans whose usage has at
+// least n of the usage key, such as opik.UsagePromptTokens.
+func (e *SpanExpectation) WithUsageAtLeast(key string, n int) *SpanExpectation {
+	e.t.Helper()
+	return e.Where(fmt.Sprintf("with at least %d %s", n, key), func(s *opik.RecordedSpan) bool {
+		got, ok := s.Usage[key]
+		return ok && got >= n
+	})
+}
+
+// WithFeedback narrows the expectation to spans with a feedback score
+// named name.
+func (e *SpanExpectation) WithFeedback(name string) *SpanExpectation {
+	e.t.Helper()
+	return e.Where(fmt.Sprintf("with feedback %q", name), func(s *opik.RecordedSpan) bool {
+		return slices.ContainsFunc(s.Feedback, func(f *opik.RecordedFeedback) bool { return f.Name == name })
+	})
+}
+
+// Ended narrows the expectation to spans that have ended.
+func (e *SpanExpectation) Ended() *SpanExpectation {
+	e.t.Helper()
+	return e.Where("that ended", func(s *opik.RecordedSpan) bool {
+		return !s.EndTime.IsZero()
+	})
+}
+
+// Count fails the test unless exactly n spans meet the expectation.
+func (e *SpanExpectation) Count(n int) *SpanExpectation {
+	e.t.Helper()
+	if !e.failed && len(e.spans) != n {
+		e.failed = true
+		e.t.Errorf("tracetest: %d spans %s, want %d\nrecorded spans:\n%s", len(e.spans), strings.Join(e.conditions, ", "), n, describe(e.all))
+	}
+	return e
+}
+
+// Span returns the first span, by start time, that meets the expectation,
+// or nil if none does.
+func (e *SpanExpectation) Span() *opik.RecordedSpan {
+	if e.failed {
+		return nil
+	}
+	return e.spans[0]
+}
+
+// Spans returns the spans that meet the expectation, by start time.
+func (e *SpanExpectation) Spans() []*opik.RecordedSpan {
+	if e.failed {
+		return nil
+	}
+	return e.spans
+}
+
+// describe lists spans for failure messages, one per line.
+func describe(spans []*opik.RecordedSpan) string {
+	var b strings.Builder
+	for _, s := range spans {
+		fmt.Fprintf(&b, "  - %q type=%s", s.Name, s.Type)
+		if s.Model != "" {
+			fmt.Fprintf(&b, " model=%s", s.Model)
+		}
+		if s.Provider != "" {
+			fmt.Fprintf(&b, " provider=%s", s.Provider)
+		}
+		if n, ok := s.Usage[opik.UsageTotalTokens]; ok {
+			fmt.Fprintf(&b, " total_tokens=%d", n)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// text returns a string value itself and any other value in its JSON form.
+func text(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return jsonText(v)
+}
+
+// jsonText returns v in its JSON form.
+func jsonText(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,91 @@
+package tracetest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	opik "github.com/plexusone/opik-go"
+)
+
+// recorder records the failures of a test instead of failing it.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func record(t *testing.T) *opik.LocalRecording {
+	t.Helper()
+	ctx := context.Background()
+	client := opik.NewRecordingClient("test")
+	trace, _ := client.Trace(ctx, "request")
+	agent, _ := trace.Span(ctx, "agent")
+	chat, _ := agent.Span(ctx, "gollm.chat",
+		opik.WithSpanType(opik.SpanTypeLLM),
+		opik.WithSpanInput(map[string]any{"prompt": "say hello"}),
+		opik.WithSpanMetadata(map[string]any{"temperature": 0.2}),
+	)
+	chat.SetUsage(map[string]int{opik.UsageTotalTokens: 12})
+	_ = chat.End(ctx, opik.WithSpanModel("gpt-4o"), opik.WithSpanProvider("openai"), opik.WithSpanOutput("hello there"))
+	_ = chat.AddFeedbackScore(ctx, "helpful", 1, "")
+	_ = agent.End(ctx)
+	_ = trace.End(ctx)
+	return client.Recording()
+}
+
+func TestExpectSpan(t *testing.T) {
+	rec := record(t)
+	agent := ExpectSpan(t, rec).WithName("agent").Span()
+
+	span := ExpectSpan(t, rec).
+		WithName("gollm.chat").
+		WithType(opik.SpanTypeLLM).
+		WithModel("gpt-4o").
+		WithProvider("openai").
+		WithParent(agent).
+		WithMetadata("temperature", 0.2).
+		WithInputContaining("say hello").
+		WithOutputContaining("hello").
+		WithUsageTotalAtLeast(10).
+		WithFeedback("helpful").
+		Ended().
+		Count(1).
+		Span()
+	if span == nil || span.Name != "gollm.chat" {
+		t.Errorf("Span = %+v, want gollm.chat", span)
+	}
+}
+
+func TestExpectSpanFailure(t *testing.T) {
+	rec := record(t)
+	r := &recorder{TB: t}
+
+	e := ExpectSpan(r, rec).WithName("gollm.chat").WithModel("gpt-4o-mini").WithUsageTotalAtLeast(100)
+	if len(r.errors) != 1 {
+		t.Fatalf("errors = %q, want one", r.errors)
+	}
+	msg := r.errors[0]
+	for _, want := range []string{`named "gollm.chat", with model "gpt-4o-mini"`, `"gollm.chat" type=llm model=gpt-4o provider=openai total_tokens=12`, `"agent"`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message %q does not contain %q", msg, want)
+		}
+	}
+	if e.Span() != nil || e.Spans() != nil {
+		t.Error("failed expectation returned spans")
+	}
+
+	r = &recorder{TB: t}
+	ExpectSpan(r, rec).WithMetadata("temperature", "0.2")
+	ExpectSpan(r, rec).Count(1)
+	ExpectSpan(r, opik.NewLocalRecording())
+	if len(r.errors) != 3 {
+		t.Errorf("errors = %q, want three", r.errors)
+	}
+}
@@ -0,0 +1,41 @@
+package opik
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// AddThreadFeedback adds a feedback score to a conversation thread, such as
+// an end user's thumbs up or down on the whole conversation, rather than to
+// one of its traces. The thread is looked up in the project set on ctx with
+// ContextWithProject, or in the client's default project.
+func (c *Client) AddThreadFeedback(ctx context.Context, threadID, name string, value float64, reason string) error {
+	if threadID == "" {
+		return fmt.Errorf("%w: thread feedback needs a thread ID", ErrInvalidInput)
+	}
+	if err := c.RequireFeature(ctx, FeatureThreads); err != nil {
+		return err
+	}
+
+	projectName := ProjectFromContext(ctx)
+	if projectName == "" {
+		projectName = c.ProjectName()
+	}
+	score := api.FeedbackScoreBatchItemThread{
+		ProjectName: api.NewOptString(projectName),
+		Name:        name,
+		Value:       value,
+		Source:      api.FeedbackScoreBatchItemThreadSourceSdk,
+		ThreadID:    threadID,
+	}
+	if reason != "" {
+		score.Reason = api.NewOptString(reason)
+	}
+	err := c.apiClient.ScoreBatchOfThreads(ctx, api.NewOptFeedbackScoreBatchThread(api.FeedbackScoreBatchThread{
+		Scores: []api.FeedbackScoreBatchItemThread{score},
+	}))
+	c.audit(AuditCreate, AuditEntityFeedbackScore, threadID, name, err)
+	return err
+}
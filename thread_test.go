@@ -0,0 +1,115 @@
+package opik
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+// newThreadServer accepts every request.
+func newThreadServer(t *testing.T) *testutil.MockServer {
+	t.Helper()
+	ms := testutil.NewMockServer()
+	ms.OnUnmatched().Respond(http.StatusNoContent, nil)
+	t.Cleanup(ms.Close)
+	return ms
+}
+
+// threadIDs returns the thread ID of each trace created on ms.
+func threadIDs(ms *testutil.MockServer) []any {
+	var threads []any
+	for _, r := range ms.RequestsFor(http.MethodPost, "/v1/private/traces/batch") {
+		var body struct {
+			Traces []map[string]any `json:"traces"`
+		}
+		_ = r.DecodeJSON(&body)
+		for _, trace := range body.Traces {
+			threads = append(threads, trace["thread_id"])
+		}
+	}
+	return threads
+}
+
+// threadScores returns the thread feedback scores sent to ms.
+func threadScores(ms *testutil.MockServer) []map[string]any {
+	var scores []map[string]any
+	for _, r := range ms.RequestsFor(http.MethodPut, "/v1/private/traces/threads/feedback-scores") {
+		var body struct {
+			Scores []map[string]any `json:"scores"`
+		}
+		_ = r.DecodeJSON(&body)
+		scores = append(scores, body.Scores...)
+	}
+	return scores
+}
+
+func TestContextWithThreadID(t *testing.T) {
+	s := newThreadServer(t)
+	client, err := NewClient(WithURL(s.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	ctx := context.Background()
+	if got := ThreadIDFromContext(ctx); got != "" {
+		t.Errorf("ThreadIDFromContext = %q, want empty", got)
+	}
+	ctx = ContextWithThreadID(ctx, "conversation-1")
+	if got := ThreadIDFromContext(ctx); got != "conversation-1" {
+		t.Errorf("ThreadIDFromContext = %q, want conversation-1", got)
+	}
+
+	// Traces started downstream inherit the thread unless they set their own.
+	if _, err := client.Trace(ctx, "turn-1"); err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	if _, _, err := StartTrace(context.WithoutCancel(ctx), client, "turn-2"); err != nil {
+		t.Fatalf("StartTrace error: %v", err)
+	}
+	if _, err := client.Trace(ctx, "other", WithTraceThreadID("conversation-2")); err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	if _, err := client.Trace(context.Background(), "unthreaded"); err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+
+	threads := threadIDs(s)
+	want := []any{"conversation-1", "conversation-1", "conversation-2", nil}
+	if len(threads) != len(want) {
+		t.Fatalf("thread IDs = %v, want %v", threads, want)
+	}
+	for i := range want {
+		if threads[i] != want[i] {
+			t.Errorf("trace %d thread_id = %v, want %v", i, threads[i], want[i])
+		}
+	}
+}
+
+func TestAddThreadFeedback(t *testing.T) {
+	s := newThreadServer(t)
+	client, err := NewClient(WithURL(s.URL()), WithAPIKey("test-key"), WithProjectName("chat-app"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := client.AddThreadFeedback(ctx, "conversation-1", "user_rating", 1, "thumbs up"); err != nil {
+		t.Fatalf("AddThreadFeedback error: %v", err)
+	}
+	if err := client.AddThreadFeedback(ctx, "", "user_rating", 0, ""); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("AddThreadFeedback without thread error = %v, want ErrInvalidInput", err)
+	}
+
+	scores := threadScores(s)
+	if len(scores) != 1 {
+		t.Fatalf("scores = %v, want 1", scores)
+	}
+	score := scores[0]
+	if score["thread_id"] != "conversation-1" || score["name"] != "user_rating" || score["value"] != 1.0 ||
+		score["reason"] != "thumbs up" || score["project_name"] != "chat-app" || score["source"] != "sdk" {
+		t.Errorf("score = %v", score)
+	}
+}
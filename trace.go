@@ -2,12 +2,12 @@ package opik
 
 import (
 	"context"
-	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/plexusone/opik-go/internal/api"
+	"github.com/plexusone/opik-go/metadata"
 )
 
 // Trace represents an execution trace in Opik.
@@ -22,7 +22,12 @@ type Trace struct {
 	output      any
 	metadata    map[string]any
 	tags        []string
+	sla         time.Duration
+	redact      RedactFunc
+	route       *ProjectRoute
+	held        *heldTrace
 	ended       bool
+	noop        bool
 }
 
 // ID returns the trace ID.
@@ -52,13 +57,11 @@ func (t *Trace) EndTime() *time.Time {
 
 // End ends the trace with optional output.
 func (t *Trace) End(ctx context.Context, opts ...TraceOption) error {
-	if t.ended {
+	if t.ended || t.noop {
 		return nil
 	}
 
-	options := &traceOptions{
-		metadata: make(map[string]any),
-	}
+	options := &traceOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
@@ -71,9 +74,11 @@ func (t *Trace) End(ctx context.Context, opts ...TraceOption) error {
 	if options.output != nil {
 		t.output = options.output
 	}
-	for k, v := range options.metadata {
-		t.metadata[k] = v
+	if t.metadata == nil {
+		t.metadata = make(map[string]any)
 	}
+	metadata.Metadata(t.metadata).Merge(options.metadata)
+	recordLatencyBudget(t.metadata, t.sla, endTime.Sub(t.startTime))
 
 	// Prepare update request
 	traceUUID, err := uuid.Parse(t.id)
@@ -87,36 +92,34 @@ func (t *Trace) End(ctx context.Context, opts ...TraceOption) error {
 
 	outputJSON := nullJSON
 	if t.output != nil {
-		data, _ := json.Marshal(t.output)
+		data := t.client.marshalPayload(t.redact, t.output, payloadOutput)
 		outputJSON = api.JsonListString(data)
 	}
 
 	metadataJSON := nullJSON
 	if len(t.metadata) > 0 {
-		data, _ := json.Marshal(t.metadata)
+		data := t.client.marshalMetadata(t.metadata)
 		metadataJSON = api.JsonListString(data)
 	}
 
-	// Create update request - TraceBatchUpdate uses Ids + single Update
-	req := api.TraceBatchUpdate{
-		Ids: []uuid.UUID{traceUUID},
-		Update: api.TraceUpdate{
-			EndTime:  api.NewOptDateTime(endTime),
-			Input:    nullJSON, // Required field, must be valid JSON
-			Output:   outputJSON,
-			Metadata: metadataJSON,
-		},
+	update := api.TraceUpdate{
+		EndTime:  api.NewOptDateTime(endTime),
+		Input:    nullJSON, // Required field, must be valid JSON
+		Output:   outputJSON,
+		Metadata: metadataJSON,
 	}
-
-	_, err = t.client.apiClient.BatchUpdateTraces(ctx, api.NewOptTraceBatchUpdate(req))
-	return err
+	if options.err != nil {
+		update.ErrorInfo = api.NewOptErrorInfo(t.client.errorInfo(t.redact, options.err))
+	}
+	return t.held.updateTrace(ctx, t.client, traceUUID, update, true)
 }
 
 // Update updates the trace with new data.
 func (t *Trace) Update(ctx context.Context, opts ...TraceOption) error {
-	options := &traceOptions{
-		metadata: make(map[string]any),
+	if t.noop {
+		return nil
 	}
+	options := &traceOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
@@ -127,9 +130,10 @@ func (t *Trace) Update(ctx context.Context, opts ...TraceOption) error {
 	}
 
 	// Merge metadata
-	for k, v := range options.metadata {
-		t.metadata[k] = v
+	if t.metadata == nil && len(options.metadata) > 0 {
+		t.metadata = make(map[string]any, len(options.metadata))
 	}
+	metadata.Metadata(t.metadata).Merge(options.metadata)
 	if options.output != nil {
 		t.output = options.output
 	}
@@ -140,37 +144,43 @@ func (t *Trace) Update(ctx context.Context, opts ...TraceOption) error {
 
 	outputJSON := nullJSON
 	if t.output != nil {
-		data, _ := json.Marshal(t.output)
+		data := t.client.marshalPayload(t.redact, t.output, payloadOutput)
 		outputJSON = api.JsonListString(data)
 	}
 
 	metadataJSON := nullJSON
 	if len(t.metadata) > 0 {
-		data, _ := json.Marshal(t.metadata)
+		data := t.client.marshalMetadata(t.metadata)
 		metadataJSON = api.JsonListString(data)
 	}
 
-	req := api.TraceBatchUpdate{
-		Ids: []uuid.UUID{traceUUID},
-		Update: api.TraceUpdate{
-			Input:    nullJSON, // Required field, must be valid JSON
-			Output:   outputJSON,
-			Metadata: metadataJSON,
-			Tags:     options.tags,
-		},
+	update := api.TraceUpdate{
+		Input:    nullJSON, // Required field, must be valid JSON
+		Output:   outputJSON,
+		Metadata: metadataJSON,
+		Tags:     options.tags,
 	}
-
-	_, err = t.client.apiClient.BatchUpdateTraces(ctx, api.NewOptTraceBatchUpdate(req))
-	return err
+	if options.err != nil {
+		update.ErrorInfo = api.NewOptErrorInfo(t.client.errorInfo(t.redact, options.err))
+	}
+	return t.held.updateTrace(ctx, t.client, traceUUID, update, false)
 }
 
 // Span creates a new span within this trace.
 func (t *Trace) Span(ctx context.Context, name string, opts ...SpanOption) (*Span, error) {
-	return t.client.createSpan(ctx, t.id, "", name, opts...)
+	if t.noop {
+		return noopSpan, nil
+	}
+	return t.client.createSpan(ctx, t.id, "", t.projectName, t.route, t.held, name, opts...)
 }
 
-// AddFeedbackScore adds a feedback score to this trace.
+// AddFeedbackScore adds a feedback score to this trace. Scores on a trace
+// held by a SamplingKeepOnError decision are dropped unless the trace has
+// been sent.
 func (t *Trace) AddFeedbackScore(ctx context.Context, name string, value float64, reason string) error {
+	if t.noop || !t.held.sends() {
+		return nil
+	}
 	traceUUID, err := uuid.Parse(t.id)
 	if err != nil {
 		return err
@@ -183,7 +193,9 @@ func (t *Trace) AddFeedbackScore(ctx context.Context, name string, value float64
 		Source: api.FeedbackScoreSourceSdk,
 	}
 
-	return t.client.apiClient.AddTraceFeedbackScore(ctx, api.NewOptFeedbackScore(req), api.AddTraceFeedbackScoreParams{
+	err = t.client.apiClient.AddTraceFeedbackScore(ctx, api.NewOptFeedbackScore(req), api.AddTraceFeedbackScoreParams{
 		ID: traceUUID,
 	})
+	t.client.audit(AuditCreate, AuditEntityFeedbackScore, t.id, name, err)
+	return err
 }
@@ -0,0 +1,216 @@
+package opik
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ToMermaid renders the trace's span tree as a Mermaid flowchart, for
+// embedding pipeline structure in Markdown docs and incident reports. Each
+// span is a node labeled with its name, type, model, and duration, shaped
+// by type: LLM calls are stadiums, tool calls hexagons, guardrails
+// trapezoids, and agents subroutines. Dashed edges mark agent hand-offs;
+// see TraceTree.Handoffs.
+func (t *TraceTree) ToMermaid() string {
+	g := t.graph()
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	fmt.Fprintf(&b, "    trace([\"%s\"])\n", mermaidText(g.traceLabel))
+	for _, n := range g.nodes {
+		left, right := mermaidShape(n.kind)
+		fmt.Fprintf(&b, "    %s%s\"%s\"%s\n", n.id, left, mermaidText(n.label), right)
+	}
+	for _, n := range g.nodes {
+		fmt.Fprintf(&b, "    %s --> %s\n", n.parent, n.id)
+	}
+	for _, h := range g.handoffs {
+		fmt.Fprintf(&b, "    %s -.->|hand-off| %s\n", h[0], h[1])
+	}
+	return b.String()
+}
+
+// ToDOT renders the trace's span tree as a Graphviz DOT graph, with the
+// nodes and edges of ToMermaid.
+func (t *TraceTree) ToDOT() string {
+	g := t.graph()
+	var b strings.Builder
+	b.WriteString("digraph trace {\n")
+	b.WriteString("    rankdir=TB;\n")
+	b.WriteString("    node [fontname=\"Helvetica\"];\n")
+	fmt.Fprintf(&b, "    trace [label=%s, shape=box, style=\"rounded,bold\"];\n", dotString(g.traceLabel))
+	for _, n := range g.nodes {
+		fmt.Fprintf(&b, "    %s [label=%s, shape=%s];\n", n.id, dotString(n.label), dotShape(n.kind))
+	}
+	for _, n := range g.nodes {
+		fmt.Fprintf(&b, "    %s -> %s;\n", n.parent, n.id)
+	}
+	for _, h := range g.handoffs {
+		fmt.Fprintf(&b, "    %s -> %s [style=dashed, label=\"hand-off\"];\n", h[0], h[1])
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Handoffs returns the agent hand-offs of the trace: pairs of agent spans
+// with the same parent, where the second is the next agent to start after
+// the first. A span is an agent if its metadata has an "agent" entry or it
+// is tagged "agent".
+func (t *TraceTree) Handoffs() [][2]*SpanInfo {
+	var handoffs [][2]*SpanInfo
+	var visit func(nodes []*SpanNode)
+	visit = func(nodes []*SpanNode) {
+		var prev *SpanInfo
+		for _, n := range nodes {
+			if isAgentSpan(n.Span) {
+				if prev != nil {
+					handoffs = append(handoffs, [2]*SpanInfo{prev, n.Span})
+				}
+				prev = n.Span
+			}
+			visit(n.Children)
+		}
+	}
+	visit(t.Roots)
+	return handoffs
+}
+
+// isAgentSpan reports whether span represents an agent.
+func isAgentSpan(span *SpanInfo) bool {
+	if m, ok := span.Metadata.(map[string]any); ok {
+		if _, ok := m["agent"]; ok {
+			return true
+		}
+	}
+	return slices.Contains(span.Tags, "agent")
+}
+
+// Node kinds of a trace graph, which decide node shapes.
+const (
+	graphNodeGeneral = iota
+	graphNodeLLM
+	graphNodeTool
+	graphNodeGuardrail
+	graphNodeAgent
+)
+
+type traceGraph struct {
+	traceLabel string
+	nodes      []graphNode
+	// handoffs holds the node IDs of each hand-off.
+	handoffs [][2]string
+}
+
+type graphNode struct {
+	id, parent, label string
+	kind              int
+}
+
+// graph lays out the span tree. Nodes are numbered in depth-first
+// start-time order, so the output is stable for the same trace.
+func (t *TraceTree) graph() traceGraph {
+	g := traceGraph{traceLabel: "trace"}
+	if t.Trace != nil {
+		g.traceLabel = t.Trace.Name
+		if d := spanDuration(t.Trace.StartTime, t.Trace.EndTime); d != "" {
+			g.traceLabel += "\n" + d
+		}
+	}
+
+	ids := make(map[*SpanInfo]string)
+	var walk func(nodes []*SpanNode, parent string)
+	walk = func(nodes []*SpanNode, parent string) {
+		for _, n := range nodes {
+			id := fmt.Sprintf("s%d", len(g.nodes))
+			ids[n.Span] = id
+			g.nodes = append(g.nodes, graphNode{id: id, parent: parent, label: spanLabel(n.Span), kind: spanKind(n.Span)})
+			walk(n.Children, id)
+		}
+	}
+	walk(t.Roots, "trace")
+
+	for _, h := range t.Handoffs() {
+		g.handoffs = append(g.handoffs, [2]string{ids[h[0]], ids[h[1]]})
+	}
+	return g
+}
+
+func spanKind(span *SpanInfo) int {
+	switch {
+	case isAgentSpan(span):
+		return graphNodeAgent
+	case span.Type == SpanTypeLLM:
+		return graphNodeLLM
+	case span.Type == SpanTypeTool:
+		return graphNodeTool
+	case span.Type == SpanTypeGuardrail:
+		return graphNodeGuardrail
+	}
+	return graphNodeGeneral
+}
+
+// spanLabel returns a node label of the span's name, then its type, model,
+// and duration on a second line.
+func spanLabel(span *SpanInfo) string {
+	details := []string{}
+	if span.Type != "" {
+		details = append(details, span.Type)
+	}
+	if span.Model != "" {
+		details = append(details, span.Model)
+	}
+	if d := spanDuration(span.StartTime, span.EndTime); d != "" {
+		details = append(details, d)
+	}
+	if len(details) == 0 {
+		return span.Name
+	}
+	return span.Name + "\n" + strings.Join(details, " · ")
+}
+
+func spanDuration(start, end time.Time) string {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return ""
+	}
+	return end.Sub(start).Round(time.Millisecond).String()
+}
+
+func mermaidShape(kind int) (string, string) {
+	switch kind {
+	case graphNodeLLM:
+		return "([", "])"
+	case graphNodeTool:
+		return "{{", "}}"
+	case graphNodeGuardrail:
+		return "[/", "\\]"
+	case graphNodeAgent:
+		return "[[", "]]"
+	}
+	return "[", "]"
+}
+
+func dotShape(kind int) string {
+	switch kind {
+	case graphNodeLLM:
+		return "ellipse"
+	case graphNodeTool:
+		return "hexagon"
+	case graphNodeGuardrail:
+		return "trapezium"
+	case graphNodeAgent:
+		return "component"
+	}
+	return "box"
+}
+
+// mermaidText prepares a label for a quoted Mermaid string: quotes become
+// entity codes, which Mermaid decodes, and line breaks become <br/>.
+func mermaidText(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", "<br/>").Replace(s)
+}
+
+// dotString quotes s as a DOT string, with line breaks kept as \n.
+func dotString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
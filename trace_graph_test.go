@@ -0,0 +1,82 @@
+package opik
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// graphTree returns a trace in which an orchestrator hands off from a
+// research agent to a writer agent, which calls a tool and an LLM.
+func graphTree() *TraceTree {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	spans := []*SpanInfo{
+		{ID: "orch", Name: "orchestrator", Type: SpanTypeGeneral, StartTime: at(0), EndTime: at(900)},
+		{ID: "research", ParentSpanID: "orch", Name: "research", Metadata: map[string]any{"agent": "research"}, StartTime: at(10), EndTime: at(400)},
+		{ID: "search", ParentSpanID: "research", Name: "web_search", Type: SpanTypeTool, StartTime: at(20), EndTime: at(150)},
+		{ID: "writer", ParentSpanID: "orch", Name: "writer", Tags: []string{"agent"}, StartTime: at(410), EndTime: at(880)},
+		{ID: "llm", ParentSpanID: "writer", Name: `draft "summary"`, Type: SpanTypeLLM, Model: "gpt-4o", StartTime: at(420), EndTime: at(870)},
+		{ID: "check", ParentSpanID: "orch", Name: "pii_check", Type: SpanTypeGuardrail, StartTime: at(885)},
+	}
+	return &TraceTree{
+		Trace: &TraceInfo{Name: "report", StartTime: at(0), EndTime: at(1000)},
+		Spans: spans,
+		Roots: BuildSpanTree(spans),
+	}
+}
+
+func TestTraceTreeToMermaid(t *testing.T) {
+	want := `flowchart TD
+    trace(["report<br/>1s"])
+    s0["orchestrator<br/>general · 900ms"]
+    s1[["research<br/>390ms"]]
+    s2{{"web_search<br/>tool · 130ms"}}
+    s3[["writer<br/>470ms"]]
+    s4(["draft #quot;summary#quot;<br/>llm · gpt-4o · 450ms"])
+    s5[/"pii_check<br/>guardrail"\]
+    trace --> s0
+    s0 --> s1
+    s1 --> s2
+    s0 --> s3
+    s3 --> s4
+    s0 --> s5
+    s1 -.->|hand-off| s3
+`
+	if got := graphTree().ToMermaid(); got != want {
+		t.Errorf("ToMermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTraceTreeToDOT(t *testing.T) {
+	got := graphTree().ToDOT()
+	for _, line := range []string{
+		`trace [label="report\n1s", shape=box, style="rounded,bold"];`,
+		`s1 [label="research\n390ms", shape=component];`,
+		`s2 [label="web_search\ntool · 130ms", shape=hexagon];`,
+		`s4 [label="draft \"summary\"\nllm · gpt-4o · 450ms", shape=ellipse];`,
+		`s5 [label="pii_check\nguardrail", shape=trapezium];`,
+		`trace -> s0;`,
+		`s3 -> s4;`,
+		`s1 -> s3 [style=dashed, label="hand-off"];`,
+	} {
+		if !strings.Contains(got, "    "+line+"\n") {
+			t.Errorf("ToDOT() is missing %s\n%s", line, got)
+		}
+	}
+	if !strings.HasPrefix(got, "digraph trace {\n") || !strings.HasSuffix(got, "}\n") {
+		t.Errorf("ToDOT() is not a digraph:\n%s", got)
+	}
+}
+
+func TestTraceTreeHandoffs(t *testing.T) {
+	handoffs := graphTree().Handoffs()
+	if len(handoffs) != 1 || handoffs[0][0].ID != "research" || handoffs[0][1].ID != "writer" {
+		t.Errorf("Handoffs() = %v, want research to writer", handoffs)
+	}
+
+	empty := &TraceTree{}
+	if got := empty.ToMermaid(); got != "flowchart TD\n    trace([\"trace\"])\n" {
+		t.Errorf("empty ToMermaid() = %q", got)
+	}
+}
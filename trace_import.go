@@ -0,0 +1,422 @@
+package opik
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// Record types of TraceRecord.
+const (
+	RecordTypeTrace = "trace"
+	RecordTypeSpan  = "span"
+)
+
+// TraceRecord is one line of the JSON Lines format read by ImportTraces, a
+// trace or a span. Programs without an SDK, such as shell scripts, write it
+// to log traces through `opik traces ingest`:
+//
+//	{"type": "trace", "id": "job", "name": "nightly-backup", "start_time": "2024-05-01T02:00:00Z", "end_time": "2024-05-01T02:04:10Z"}
+//	{"type": "span", "trace_id": "job", "name": "pg_dump", "span_type": "tool", "output": {"bytes": 1048576}}
+//
+// IDs are UUIDs, which keep their value, or any other string, which refers
+// to a record of the same import and is replaced by a generated UUID.
+type TraceRecord struct {
+	// Type is RecordTypeTrace or RecordTypeSpan.
+	Type string `json:"type"`
+	// ID identifies the record so spans can refer to it. It may be omitted
+	// for records nothing refers to.
+	ID string `json:"id,omitempty"`
+	// TraceID is the trace of a span. It is required for spans.
+	TraceID string `json:"trace_id,omitempty"`
+	// ParentSpanID is the parent span of a span, if it is not a root span.
+	ParentSpanID string `json:"parent_span_id,omitempty"`
+	// Project is the project of a trace; empty is the client's project.
+	// Spans are logged to the project of their trace when it is in the
+	// same import.
+	Project string `json:"project,omitempty"`
+	Name    string `json:"name"`
+	// SpanType is the type of a span: general, llm, tool, or guardrail.
+	// Empty is general.
+	SpanType string `json:"span_type,omitempty"`
+	// StartTime defaults to the time of the import; EndTime is unset if
+	// omitted.
+	StartTime *time.Time     `json:"start_time,omitempty"`
+	EndTime   *time.Time     `json:"end_time,omitempty"`
+	Input     any            `json:"input,omitempty"`
+	Output    any            `json:"output,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	Tags      []string       `json:"tags,omitempty"`
+	// ThreadID groups a trace into a conversation thread.
+	ThreadID string `json:"thread_id,omitempty"`
+	// Model, Provider, and Usage describe the LLM call of a span. Usage may
+	// use provider key names, as NormalizeUsage accepts; the span's cost is
+	// estimated from it as for spans logged with the SDK.
+	Model    string         `json:"model,omitempty"`
+	Provider string         `json:"provider,omitempty"`
+	Usage    map[string]any `json:"usage,omitempty"`
+	// Error marks the record as failed, with the error message.
+	Error string `json:"error,omitempty"`
+}
+
+// TraceImportResult reports the outcome of ImportTraces.
+type TraceImportResult struct {
+	// Traces and Spans are the numbers of traces and spans logged.
+	Traces int
+	Spans  int
+	// TraceIDs are the IDs of the traces logged, in the order of the
+	// records.
+	TraceIDs []string
+	// IDs maps the record IDs that are not UUIDs to the UUIDs they were
+	// logged with.
+	IDs map[string]string
+	// Failed lists the records that were not logged, by their 0-based line
+	// number, excluding blank lines.
+	Failed []ItemError
+}
+
+// ImportTraces logs the traces and spans of r, which holds one TraceRecord
+// per line, in batches. Blank lines are skipped. Records that do not decode
+// or are invalid, such as a span whose trace ID is neither a UUID nor the
+// ID of a trace in r, fail without being sent, and the rest are still
+// logged. A span of a failed trace fails too. The returned error reports a
+// failure to read r, or ErrTracingDisabled; failed records are in the
+// result.
+//
+// Traces are sent before spans, and a record may refer to a record on a
+// later line. The records are held in memory until r is read.
+func (c *Client) ImportTraces(ctx context.Context, r io.Reader) (*TraceImportResult, error) {
+	if c.config.TracingDisabled {
+		return nil, ErrTracingDisabled
+	}
+
+	var records []TraceRecord
+	var decodeErrs []ItemError
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec TraceRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			decodeErrs = append(decodeErrs, ItemError{Index: len(records), Err: fmt.Errorf("invalid JSON: %w", err)})
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading trace records: %w", err)
+	}
+	return c.importRecords(ctx, records, decodeErrs), nil
+}
+
+// importRecords validates and sends records. failed are records already
+// known to be invalid.
+func (c *Client) importRecords(ctx context.Context, records []TraceRecord, failed []ItemError) *TraceImportResult {
+	result := &TraceImportResult{IDs: make(map[string]string)}
+	bad := make(map[int]bool, len(failed))
+	for _, f := range failed {
+		bad[f.Index] = true
+	}
+	fail := func(i int, format string, args ...any) {
+		bad[i] = true
+		failed = append(failed, ItemError{Index: i, Err: fmt.Errorf(format, args...)})
+	}
+
+	// Assign IDs first, so records can refer to later ones.
+	ids := make([]uuid.UUID, len(records))
+	refs := make(map[string]uuid.UUID)
+	index := make(map[uuid.UUID]int) // record of each ID
+	for i, rec := range records {
+		if bad[i] {
+			continue
+		}
+		switch rec.Type {
+		case RecordTypeTrace, RecordTypeSpan:
+		default:
+			fail(i, "unknown record type %q: use %q or %q", rec.Type, RecordTypeTrace, RecordTypeSpan)
+			continue
+		}
+		id, err := recordID(rec.ID, refs)
+		if err != nil {
+			fail(i, "%v", err)
+			continue
+		}
+		if _, dup := index[id]; dup {
+			fail(i, "duplicate id %q", rec.ID)
+			continue
+		}
+		ids[i] = id
+		index[id] = i
+		if rec.ID != "" && rec.ID != id.String() {
+			result.IDs[rec.ID] = id.String()
+		}
+	}
+	resolve := func(ref string) (uuid.UUID, bool) {
+		if id, ok := refs[ref]; ok {
+			return id, true
+		}
+		id, err := uuid.Parse(ref)
+		return id, err == nil
+	}
+
+	now := time.Now()
+	traces := make([]api.TraceWrite, len(records))
+	spans := make([]api.SpanWrite, len(records))
+	var traceIndexes, spanIndexes []int
+	for i, rec := range records {
+		if bad[i] || rec.Type != RecordTypeTrace {
+			continue
+		}
+		if rec.Name == "" {
+			fail(i, "trace has no name")
+			continue
+		}
+		traces[i] = c.traceWrite(rec, ids[i], now)
+		traceIndexes = append(traceIndexes, i)
+	}
+	for i, rec := range records {
+		if bad[i] || rec.Type != RecordTypeSpan {
+			continue
+		}
+		if rec.Name == "" {
+			fail(i, "span has no name")
+			continue
+		}
+		traceID, ok := resolve(rec.TraceID)
+		if !ok {
+			fail(i, "span has trace_id %q, which is not a UUID or the id of a trace", rec.TraceID)
+			continue
+		}
+		project := c.projectName
+		if t, ok := index[traceID]; ok {
+			if records[t].Type != RecordTypeTrace || bad[t] {
+				fail(i, "span's trace %q is not a valid trace", rec.TraceID)
+				continue
+			}
+			if records[t].Project != "" {
+				project = records[t].Project
+			}
+		}
+		var parentID uuid.UUID
+		if rec.ParentSpanID != "" {
+			if parentID, ok = resolve(rec.ParentSpanID); !ok {
+				fail(i, "span has parent_span_id %q, which is not a UUID or the id of a span", rec.ParentSpanID)
+				continue
+			}
+		}
+		switch api.SpanWriteType(rec.SpanType) {
+		case "", api.SpanWriteTypeGeneral, api.SpanWriteTypeLlm, api.SpanWriteTypeTool, api.SpanWriteTypeGuardrail:
+		default:
+			fail(i, "unknown span_type %q", rec.SpanType)
+			continue
+		}
+		spans[i] = c.spanWrite(rec, ids[i], traceID, parentID, project, now)
+		spanIndexes = append(spanIndexes, i)
+	}
+
+	traceResult := sendInBatches(ctx, c.batchConfig, traceIndexes, func(i int) int {
+		return len(traces[i].Input) + len(traces[i].Output) + len(traces[i].Metadata) + 256
+	}, func(ctx context.Context, indexes []int) error {
+		req := api.TraceBatchWrite{Traces: make([]api.TraceWrite, len(indexes))}
+		batchIDs := make([]uuid.UUID, len(indexes))
+		for i, j := range indexes {
+			req.Traces[i] = traces[j]
+			batchIDs[i] = traces[j].ID.Value
+		}
+		err := c.apiClient.CreateTraces(ctx, api.NewOptTraceBatchWrite(req))
+		c.auditBatch(AuditCreate, AuditEntityTrace, batchIDs, err)
+		return err
+	})
+	result.Traces = traceResult.Succeeded
+	failed = append(failed, traceResult.Failed...)
+	unsent := make(map[uuid.UUID]bool, len(traceResult.Failed))
+	for _, f := range traceResult.Failed {
+		unsent[ids[f.Index]] = true
+	}
+	for _, i := range traceIndexes {
+		if !unsent[ids[i]] {
+			result.TraceIDs = append(result.TraceIDs, ids[i].String())
+		}
+	}
+
+	// A span of a trace that failed to send fails too.
+	spanIndexes = keepIndexes(spanIndexes, func(i int) bool {
+		if unsent[spans[i].TraceID.Value] {
+			failed = append(failed, ItemError{Index: i, Err: errors.New("span's trace was not logged")})
+			return false
+		}
+		return true
+	})
+
+	spanResult := sendInBatches(ctx, c.batchConfig, spanIndexes, func(i int) int {
+		return len(spans[i].Input) + len(spans[i].Output) + len(spans[i].Metadata) + 256
+	}, func(ctx context.Context, indexes []int) error {
+		req := api.SpanBatchWrite{Spans: make([]api.SpanWrite, len(indexes))}
+		batchIDs := make([]uuid.UUID, len(indexes))
+		for i, j := range indexes {
+			req.Spans[i] = spans[j]
+			batchIDs[i] = spans[j].ID.Value
+		}
+		err := c.apiClient.CreateSpans(ctx, api.NewOptSpanBatchWrite(req))
+		c.auditBatch(AuditCreate, AuditEntitySpan, batchIDs, err)
+		return err
+	})
+	result.Spans = spanResult.Succeeded
+	failed = append(failed, spanResult.Failed...)
+
+	sort.Slice(failed, func(i, j int) bool { return failed[i].Index < failed[j].Index })
+	result.Failed = failed
+	return result
+}
+
+// recordID returns the UUID of a record ID: the ID itself if it is a UUID,
+// otherwise a UUID generated for it, or for a record without an ID.
+func recordID(ref string, refs map[string]uuid.UUID) (uuid.UUID, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		return id, nil
+	}
+	if _, dup := refs[ref]; dup && ref != "" {
+		return uuid.UUID{}, fmt.Errorf("duplicate id %q", ref)
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("failed to generate UUID: %w", err)
+	}
+	if ref != "" {
+		refs[ref] = id
+	}
+	return id, nil
+}
+
+// traceWrite returns the create request of a trace record.
+func (c *Client) traceWrite(rec TraceRecord, id uuid.UUID, now time.Time) api.TraceWrite {
+	project := rec.Project
+	if project == "" {
+		project = c.projectName
+	}
+	write := api.TraceWrite{
+		ID:          api.NewOptUUID(id),
+		ProjectName: api.NewOptString(project),
+		Name:        api.NewOptString(rec.Name),
+		StartTime:   recordTime(rec.StartTime, now),
+		Input:       nullJSONWrite,
+		Output:      nullJSONWrite,
+		Metadata:    nullJSONWrite,
+		Tags:        indexedTags(c.indexedMetadata, rec.Metadata, rec.Tags),
+	}
+	if rec.EndTime != nil {
+		write.EndTime = api.NewOptDateTime(*rec.EndTime)
+	}
+	if rec.Input != nil {
+		write.Input = api.JsonListStringWrite(c.marshalPayload(nil, rec.Input, payloadInput))
+	}
+	if rec.Output != nil {
+		write.Output = api.JsonListStringWrite(c.marshalPayload(nil, rec.Output, payloadOutput))
+	}
+	if len(rec.Metadata) > 0 {
+		write.Metadata = api.JsonListStringWrite(c.marshalMetadata(rec.Metadata))
+	}
+	if rec.ThreadID != "" {
+		write.ThreadID = api.NewOptString(rec.ThreadID)
+	}
+	if rec.Error != "" {
+		write.ErrorInfo = api.NewOptErrorInfoWrite(c.recordError(rec.Error))
+	}
+	return write
+}
+
+// spanWrite returns the create request of a span record.
+func (c *Client) spanWrite(rec TraceRecord, id, traceID, parentID uuid.UUID, project string, now time.Time) api.SpanWrite {
+	spanType := api.SpanWriteType(rec.SpanType)
+	if spanType == "" {
+		spanType = api.SpanWriteTypeGeneral
+	}
+	metadata := rec.Metadata
+	usage := NormalizeUsage(rec.Usage)
+	write := api.SpanWrite{
+		ID:          api.NewOptUUID(id),
+		ProjectName: api.NewOptString(project),
+		TraceID:     api.NewOptUUID(traceID),
+		Name:        api.NewOptString(rec.Name),
+		Type:        api.NewOptSpanWriteType(spanType),
+		StartTime:   recordTime(rec.StartTime, now),
+		Input:       nullJSONWrite,
+		Output:      nullJSONWrite,
+		Metadata:    nullJSONWrite,
+		Tags:        indexedTags(c.indexedMetadata, rec.Metadata, rec.Tags),
+		Model:       api.NewOptString(rec.Model),
+		Provider:    api.NewOptString(rec.Provider),
+	}
+	if parentID != uuid.Nil {
+		write.ParentSpanID = api.NewOptUUID(parentID)
+	}
+	if rec.EndTime != nil {
+		write.EndTime = api.NewOptDateTime(*rec.EndTime)
+	}
+	if rec.Input != nil {
+		write.Input = api.JsonListStringWrite(c.marshalPayload(nil, rec.Input, payloadInput))
+	}
+	if rec.Output != nil {
+		write.Output = api.JsonListStringWrite(c.marshalPayload(nil, rec.Output, payloadOutput))
+	}
+	if len(usage) > 0 {
+		apiUsage := make(api.SpanWriteUsage, len(usage))
+		for k, v := range usage {
+			apiUsage[k] = int32(v) //nolint:gosec // G115: token counts fit in int32
+		}
+		write.Usage = api.NewOptSpanWriteUsage(apiUsage)
+		if price, ok := c.modelPrice(rec.Provider, rec.Model); ok {
+			cost := price.Cost(usage)
+			write.TotalEstimatedCost = api.NewOptFloat64(cost)
+			metadata = make(map[string]any, len(rec.Metadata)+1)
+			for k, v := range rec.Metadata {
+				metadata[k] = v
+			}
+			metadata[MetadataEstimatedCost] = cost
+		}
+	}
+	if len(metadata) > 0 {
+		write.Metadata = api.JsonListStringWrite(c.marshalMetadata(metadata))
+	}
+	if rec.Error != "" {
+		write.ErrorInfo = api.NewOptErrorInfoWrite(c.recordError(rec.Error))
+	}
+	return write
+}
+
+// recordError returns the error info of a record's error message, after
+// the client's redaction pipeline.
+func (c *Client) recordError(message string) api.ErrorInfoWrite {
+	return api.ErrorInfoWrite{ExceptionType: "Error", Message: api.NewOptString(c.RedactString(message))}
+}
+
+// recordTime returns t, or now if t is nil.
+func recordTime(t *time.Time, now time.Time) time.Time {
+	if t == nil {
+		return now
+	}
+	return *t
+}
+
+// keepIndexes returns the indexes for which keep returns true.
+func keepIndexes(indexes []int, keep func(i int) bool) []int {
+	kept := indexes[:0]
+	for _, i := range indexes {
+		if keep(i) {
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,195 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/opik-go/testutil"
+)
+
+// importedEntity is a trace or span as sent by ImportTraces.
+type importedEntity struct {
+	ID           string         `json:"id"`
+	TraceID      string         `json:"trace_id"`
+	ParentSpanID string         `json:"parent_span_id"`
+	ProjectName  string         `json:"project_name"`
+	Name         string         `json:"name"`
+	Type         string         `json:"type"`
+	StartTime    time.Time      `json:"start_time"`
+	EndTime      *time.Time     `json:"end_time"`
+	Output       any            `json:"output"`
+	Metadata     map[string]any `json:"metadata"`
+	Usage        map[string]int `json:"usage"`
+	Cost         float64        `json:"total_estimated_cost"`
+	ErrorInfo    *struct {
+		Message string `json:"message"`
+	} `json:"error_info"`
+}
+
+// newTraceImportServer records the traces and spans created, and fails trace
+// batches holding a trace named failName.
+func newTraceImportServer(failName string) (*testutil.MockServer, func() (traces, spans []importedEntity)) {
+	type batch struct {
+		Traces []importedEntity `json:"traces"`
+		Spans  []importedEntity `json:"spans"`
+	}
+	rejected := func(b batch) bool {
+		return slices.ContainsFunc(b.Traces, func(t importedEntity) bool { return t.Name == failName })
+	}
+	ms := testutil.NewMockServer()
+	ms.OnUnmatched().WithHandler(func(w http.ResponseWriter, r *http.Request) {
+		var b batch
+		_ = json.NewDecoder(r.Body).Decode(&b)
+		if rejected(b) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return ms, func() (traces, spans []importedEntity) {
+		for _, r := range ms.Requests() {
+			var b batch
+			if r.DecodeJSON(&b) != nil || rejected(b) {
+				continue
+			}
+			traces = append(traces, b.Traces...)
+			spans = append(spans, b.Spans...)
+		}
+		return traces, spans
+	}
+}
+
+func TestImportTraces(t *testing.T) {
+	ts, created := newTraceImportServer("")
+	defer ts.Close()
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithProjectName("scripts"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	const existing = "0190a6f0-0000-7000-8000-000000000001"
+	records := `{"type": "trace", "id": "job", "name": "nightly-backup", "start_time": "2024-05-01T02:00:00Z", "end_time": "2024-05-01T02:04:10Z", "project": "ops"}
+
+{"type": "span", "id": "dump", "trace_id": "job", "name": "pg_dump", "span_type": "tool", "output": {"bytes": 1048576}}
+{"type": "span", "trace_id": "job", "parent_span_id": "dump", "name": "summarize", "span_type": "llm", "model": "gpt-4o", "usage": {"prompt_tokens": 1000, "completion_tokens": 100}, "error": "rate limited"}
+{"type": "span", "trace_id": "` + existing + `", "name": "late step"}
+`
+	result, err := client.ImportTraces(context.Background(), strings.NewReader(records))
+	if err != nil {
+		t.Fatalf("ImportTraces error: %v", err)
+	}
+	if result.Traces != 1 || result.Spans != 3 || len(result.Failed) != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	traces, spans := created()
+	if len(traces) != 1 || len(spans) != 3 {
+		t.Fatalf("created %d traces and %d spans, want 1 and 3", len(traces), len(spans))
+	}
+	trace := traces[0]
+	if trace.ID != result.IDs["job"] || len(result.TraceIDs) != 1 || result.TraceIDs[0] != trace.ID {
+		t.Errorf("trace ID %s, result IDs %v, trace IDs %v", trace.ID, result.IDs, result.TraceIDs)
+	}
+	if trace.ProjectName != "ops" || !trace.StartTime.Equal(time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)) || trace.EndTime == nil {
+		t.Errorf("trace = %+v", trace)
+	}
+
+	dump, summarize, late := spans[0], spans[1], spans[2]
+	if dump.TraceID != trace.ID || dump.ProjectName != "ops" || dump.Type != "tool" || dump.ID != result.IDs["dump"] {
+		t.Errorf("dump span = %+v", dump)
+	}
+	if summarize.ParentSpanID != dump.ID || summarize.Usage["prompt_tokens"] != 1000 {
+		t.Errorf("summarize span = %+v", summarize)
+	}
+	if summarize.Cost <= 0 || summarize.Metadata[MetadataEstimatedCost] == nil {
+		t.Errorf("summarize span has no estimated cost: %+v", summarize)
+	}
+	if summarize.ErrorInfo == nil || summarize.ErrorInfo.Message != "rate limited" {
+		t.Errorf("summarize error_info = %+v", summarize.ErrorInfo)
+	}
+	if late.TraceID != existing || late.ProjectName != "scripts" || late.Type != "general" {
+		t.Errorf("late span = %+v", late)
+	}
+}
+
+func TestImportTracesInvalidRecords(t *testing.T) {
+	ts, created := newTraceImportServer("")
+	defer ts.Close()
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	records := strings.Join([]string{
+		`{"type": "trace", "id": "ok", "name": "ok"}`,
+		`not json`,
+		`{"type": "event", "name": "x"}`,
+		`{"type": "trace", "id": "unnamed"}`,
+		`{"type": "span", "trace_id": "unnamed", "name": "orphan"}`,
+		`{"type": "span", "trace_id": "missing", "name": "dangling"}`,
+		`{"type": "span", "trace_id": "ok", "name": "bad type", "span_type": "db"}`,
+		`{"type": "trace", "id": "ok", "name": "duplicate"}`,
+		`{"type": "span", "trace_id": "ok", "name": "fine"}`,
+	}, "\n")
+	result, err := client.ImportTraces(context.Background(), strings.NewReader(records))
+	if err != nil {
+		t.Fatalf("ImportTraces error: %v", err)
+	}
+	if result.Traces != 1 || result.Spans != 1 {
+		t.Errorf("logged %d traces and %d spans, want 1 and 1", result.Traces, result.Spans)
+	}
+	want := []int{1, 2, 3, 4, 5, 6, 7}
+	if got := (&BatchResult{Failed: result.Failed}).FailedIndexes(); len(got) != len(want) {
+		t.Fatalf("failed = %v, want %v", result.Failed, want)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("failed = %v, want %v", got, want)
+				break
+			}
+		}
+	}
+	if _, spans := created(); len(spans) != 1 || spans[0].Name != "fine" {
+		t.Errorf("spans = %+v", spans)
+	}
+}
+
+func TestImportTracesSpanOfFailedTrace(t *testing.T) {
+	ts, created := newTraceImportServer("rejected")
+	defer ts.Close()
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	client.batchConfig.retryDelay = time.Millisecond
+
+	records := `{"type": "trace", "id": "a", "name": "rejected"}
+{"type": "span", "trace_id": "a", "name": "child"}
+`
+	result, err := client.ImportTraces(context.Background(), strings.NewReader(records))
+	if err != nil {
+		t.Fatalf("ImportTraces error: %v", err)
+	}
+	if result.Traces != 0 || result.Spans != 0 || len(result.Failed) != 2 || len(result.TraceIDs) != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if traces, spans := created(); len(traces) != 0 || len(spans) != 0 {
+		t.Errorf("created %d traces and %d spans", len(traces), len(spans))
+	}
+}
+
+func TestImportTracesDisabled(t *testing.T) {
+	client, err := NewClient(WithTracingDisabled(true))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if _, err := client.ImportTraces(context.Background(), strings.NewReader("")); !errors.Is(err, ErrTracingDisabled) {
+		t.Errorf("error = %v, want ErrTracingDisabled", err)
+	}
+}
@@ -0,0 +1,134 @@
+package opik
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/plexusone/opik-go/internal/api"
+)
+
+// traceTreePageSize is the page size used to fetch a trace's spans.
+const traceTreePageSize = 100
+
+// TraceTree is a trace with all of its spans, arranged by parent.
+type TraceTree struct {
+	Trace *TraceInfo
+	// Spans holds every span of the trace, in the order returned by the API.
+	Spans []*SpanInfo
+	// Roots holds the top-level spans, ordered by start time.
+	Roots []*SpanNode
+}
+
+// SpanNode is a span and its child spans, ordered by start time.
+type SpanNode struct {
+	Span     *SpanInfo
+	Children []*SpanNode
+	// Depth is 0 for root spans, 1 for their children, and so on.
+	Depth int
+}
+
+// Walk calls fn for each span in the tree, depth-first in start-time order.
+func (t *TraceTree) Walk(fn func(node *SpanNode)) {
+	var walk func(nodes []*SpanNode)
+	walk = func(nodes []*SpanNode) {
+		for _, n := range nodes {
+			fn(n)
+			walk(n.Children)
+		}
+	}
+	walk(t.Roots)
+}
+
+// GetTraceTree retrieves a trace with all of its spans in one call. Spans
+// are fetched page by page until every span of the trace has been read, and
+// arranged into a tree with BuildSpanTree.
+func (c *Client) GetTraceTree(ctx context.Context, traceID string) (*TraceTree, error) {
+	traceUUID, err := uuid.Parse(traceID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.apiClient.GetTraceById(ctx, api.GetTraceByIdParams{ID: traceUUID})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, ErrTraceNotFound
+	}
+
+	spans, err := c.traceSpans(ctx, traceUUID, resp.ProjectID, c.projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TraceTree{
+		Trace: traceInfoFromAPI(resp),
+		Spans: spans,
+		Roots: BuildSpanTree(spans),
+	}, nil
+}
+
+// traceSpans reads every span of a trace, page by page. The project is
+// looked up by projectID if set, and by projectName otherwise.
+func (c *Client) traceSpans(ctx context.Context, traceUUID uuid.UUID, projectID api.OptUUID, projectName string) ([]*SpanInfo, error) {
+	params := api.GetSpansByProjectParams{
+		TraceID: api.NewOptUUID(traceUUID),
+		Size:    api.NewOptInt32(traceTreePageSize),
+	}
+	if projectID.Set {
+		params.ProjectID = projectID
+	} else {
+		params.ProjectName = api.NewOptString(projectName)
+	}
+
+	var spans []*SpanInfo
+	for page := int32(1); ; page++ {
+		params.Page = api.NewOptInt32(page)
+		spanPage, err := c.apiClient.GetSpansByProject(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range spanPage.Content {
+			spans = append(spans, spanInfoFromAPI(&s))
+		}
+		if len(spanPage.Content) < traceTreePageSize ||
+			(spanPage.Total.Set && int64(len(spans)) >= spanPage.Total.Value) {
+			return spans, nil
+		}
+	}
+}
+
+// BuildSpanTree arranges spans by parent, with children ordered by start
+// time. Spans whose parent is not in the list are treated as roots, so a
+// partial list still produces a usable tree.
+func BuildSpanTree(spans []*SpanInfo) []*SpanNode {
+	nodes := make(map[string]*SpanNode, len(spans))
+	for _, s := range spans {
+		nodes[s.ID] = &SpanNode{Span: s}
+	}
+
+	var roots []*SpanNode
+	for _, s := range spans {
+		node := nodes[s.ID]
+		if parent, ok := nodes[s.ParentSpanID]; ok && parent != node {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	var order func(nodes []*SpanNode, depth int)
+	order = func(nodes []*SpanNode, depth int) {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return nodes[i].Span.StartTime.Before(nodes[j].Span.StartTime)
+		})
+		for _, n := range nodes {
+			n.Depth = depth
+			order(n.Children, depth+1)
+		}
+	}
+	order(roots, 0)
+	return roots
+}
@@ -0,0 +1,125 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGetTraceTree(t *testing.T) {
+	traceID := uuid.Must(uuid.NewV7()).String()
+	projectID := uuid.Must(uuid.NewV7()).String()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 150 spans: one root with 149 children, returned newest first across
+	// two pages.
+	rootID := uuid.Must(uuid.NewV7()).String()
+	spans := []map[string]any{}
+	for i := 149; i >= 1; i-- {
+		spans = append(spans, map[string]any{
+			"id":             uuid.Must(uuid.NewV7()).String(),
+			"trace_id":       traceID,
+			"parent_span_id": rootID,
+			"name":           fmt.Sprintf("child-%d", i),
+			"type":           "llm",
+			"start_time":     base.Add(time.Duration(i) * time.Second),
+		})
+	}
+	spans = append(spans, map[string]any{
+		"id": rootID, "trace_id": traceID, "name": "root", "type": "general", "start_time": base,
+	})
+
+	var pages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/private/traces/"+traceID:
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": traceID, "project_id": projectID, "name": "request", "start_time": base,
+				"output": map[string]any{"answer": 42},
+			})
+		case r.URL.Path == "/v1/private/spans":
+			q := r.URL.Query()
+			if q.Get("trace_id") != traceID || q.Get("project_id") != projectID {
+				http.Error(w, "bad query "+r.URL.RawQuery, http.StatusBadRequest)
+				return
+			}
+			pages = append(pages, q.Get("page"))
+			page, _ := strconv.Atoi(q.Get("page"))
+			size, _ := strconv.Atoi(q.Get("size"))
+			start := min((page-1)*size, len(spans))
+			end := min(start+size, len(spans))
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"page": page, "size": end - start, "total": len(spans), "content": spans[start:end],
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	tree, err := client.GetTraceTree(context.Background(), traceID)
+	if err != nil {
+		t.Fatalf("GetTraceTree error: %v", err)
+	}
+	if strings.Join(pages, ",") != "1,2" {
+		t.Errorf("pages fetched = %v, want [1 2]", pages)
+	}
+	if tree.Trace.ID != traceID || tree.Trace.Name != "request" {
+		t.Errorf("Trace = %+v", tree.Trace)
+	}
+	if len(tree.Spans) != 150 {
+		t.Fatalf("len(Spans) = %d, want 150", len(tree.Spans))
+	}
+	if len(tree.Roots) != 1 || tree.Roots[0].Span.Name != "root" {
+		t.Fatalf("Roots = %+v", tree.Roots)
+	}
+	children := tree.Roots[0].Children
+	if len(children) != 149 || children[0].Span.Name != "child-1" || children[0].Depth != 1 {
+		t.Errorf("first child = %+v of %d", children[0].Span, len(children))
+	}
+
+	count := 0
+	tree.Walk(func(*SpanNode) { count++ })
+	if count != 150 {
+		t.Errorf("Walk visited %d spans, want 150", count)
+	}
+
+	if _, err := client.GetTraceTree(context.Background(), "not-a-uuid"); err == nil {
+		t.Error("expected error for invalid trace ID")
+	}
+}
+
+func TestBuildSpanTree(t *testing.T) {
+	base := time.Now()
+	roots := BuildSpanTree([]*SpanInfo{
+		{ID: "c", ParentSpanID: "a", Name: "child-2", StartTime: base.Add(2 * time.Second)},
+		{ID: "a", Name: "root", StartTime: base},
+		{ID: "b", ParentSpanID: "a", Name: "child-1", StartTime: base.Add(time.Second)},
+		{ID: "d", ParentSpanID: "b", Name: "grandchild", StartTime: base.Add(time.Second)},
+		{ID: "e", ParentSpanID: "missing", Name: "orphan", StartTime: base.Add(3 * time.Second)},
+	})
+
+	tree := &TraceTree{Roots: roots}
+	var got []string
+	tree.Walk(func(n *SpanNode) {
+		got = append(got, strings.Repeat("  ", n.Depth)+n.Span.Name)
+	})
+	want := []string{"root", "  child-1", "    grandchild", "  child-2", "orphan"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,246 @@
+package opik
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/plexusone/opik-go/cost"
+)
+
+// Usage keys recorded on spans. NormalizeUsage maps provider-specific usage
+// fields to these keys.
+const (
+	UsagePromptTokens     = cost.PromptTokens
+	UsageCompletionTokens = cost.CompletionTokens
+	UsageTotalTokens      = "total_tokens"
+	// UsageCacheReadTokens counts prompt tokens served from the provider's prompt cache.
+	UsageCacheReadTokens = cost.CacheReadTokens
+	// UsageCacheWriteTokens counts prompt tokens written to the provider's prompt cache.
+	UsageCacheWriteTokens = cost.CacheWriteTokens
+	// UsageReasoningTokens counts completion tokens spent on hidden reasoning
+	// by reasoning models. They are included in UsageCompletionTokens.
+	UsageReasoningTokens = cost.ReasoningTokens
+)
+
+// MetadataEstimatedCost is the span metadata key of the span's estimated cost
+// in USD, as returned by Span.EstimatedCost.
+const MetadataEstimatedCost = "estimated_cost_usd"
+
+// Metadata keys for reasoning summaries set with Span.SetReasoning.
+const (
+	MetadataReasoningSummary  = "reasoning_summary"
+	MetadataReasoningRedacted = "reasoning_redacted"
+)
+
+// NormalizeUsage converts a provider usage object, as decoded from an OpenAI
+// or Anthropic JSON response, to the SDK's usage keys.
+//
+// Prompt tokens always include cached tokens: Anthropic reports cache reads
+// and writes separately from input_tokens, so they are added back, while
+// OpenAI's prompt_tokens already include prompt_tokens_details.cached_tokens.
+// Reasoning tokens are read from OpenAI's completion_tokens_details or
+// output_tokens_details. Unknown integer fields are passed through unchanged.
+func NormalizeUsage(raw map[string]any) map[string]int {
+	usage := make(map[string]int)
+	for k, v := range raw {
+		if n, ok := toInt(v); ok {
+			usage[k] = n
+		}
+	}
+
+	// Anthropic
+	if n, ok := usage["cache_read_input_tokens"]; ok {
+		usage[UsageCacheReadTokens] = n
+		delete(usage, "cache_read_input_tokens")
+	}
+	if n, ok := usage["cache_creation_input_tokens"]; ok {
+		usage[UsageCacheWriteTokens] = n
+		delete(usage, "cache_creation_input_tokens")
+	}
+	if n, ok := usage["input_tokens"]; ok {
+		if _, ok := usage[UsagePromptTokens]; !ok {
+			usage[UsagePromptTokens] = n + usage[UsageCacheReadTokens] + usage[UsageCacheWriteTokens]
+		}
+		delete(usage, "input_tokens")
+	}
+	if n, ok := usage["output_tokens"]; ok {
+		if _, ok := usage[UsageCompletionTokens]; !ok {
+			usage[UsageCompletionTokens] = n
+		}
+		delete(usage, "output_tokens")
+	}
+
+	// OpenAI
+	if details, ok := raw["prompt_tokens_details"].(map[string]any); ok {
+		if n, ok := toInt(details["cached_tokens"]); ok && n > 0 {
+			usage[UsageCacheReadTokens] = n
+		}
+	}
+	for _, key := range []string{"completion_tokens_details", "output_tokens_details"} {
+		if details, ok := raw[key].(map[string]any); ok {
+			if n, ok := toInt(details["reasoning_tokens"]); ok && n > 0 {
+				usage[UsageReasoningTokens] = n
+			}
+		}
+	}
+
+	if _, ok := usage[UsageTotalTokens]; !ok && len(usage) > 0 {
+		usage[UsageTotalTokens] = usage[UsagePromptTokens] + usage[UsageCompletionTokens]
+	}
+	return usage
+}
+
+// UsageExtractor reads token usage from a provider HTTP response. Some
+// gateways report usage only in response headers or trailers, so tracing
+// integrations use extractors to fill in what the body omits. They call
+// ExtractUsage after reading the body, so trailers are available.
+type UsageExtractor interface {
+	ExtractUsage(resp *http.Response) map[string]int
+}
+
+// UsageExtractorFunc adapts a function to a UsageExtractor.
+type UsageExtractorFunc func(resp *http.Response) map[string]int
+
+// ExtractUsage calls f(resp).
+func (f UsageExtractorFunc) ExtractUsage(resp *http.Response) map[string]int {
+	return f(resp)
+}
+
+// HeaderUsageExtractor returns a UsageExtractor that reads integer usage
+// values from response headers, or trailers if the header is absent. fields
+// maps header names to usage keys, for example
+// {"X-Usage-Prompt-Tokens": opik.UsagePromptTokens}.
+func HeaderUsageExtractor(fields map[string]string) UsageExtractor {
+	return UsageExtractorFunc(func(resp *http.Response) map[string]int {
+		usage := make(map[string]int)
+		for header, key := range fields {
+			v := resp.Header.Get(header)
+			if v == "" {
+				v = resp.Trailer.Get(header)
+			}
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				usage[key] = n
+			}
+		}
+		return usage
+	})
+}
+
+// MergeUsage returns usage with the keys of extra that it lacks added, so
+// usage reported in a response body takes precedence. The total is
+// recomputed if extra adds prompt or completion tokens without a total.
+func MergeUsage(usage, extra map[string]int) map[string]int {
+	if len(extra) == 0 {
+		return usage
+	}
+	merged := make(map[string]int, len(usage)+len(extra))
+	for k, n := range usage {
+		merged[k] = n
+	}
+	added := false
+	for k, n := range extra {
+		if _, ok := merged[k]; ok {
+			continue
+		}
+		merged[k] = n
+		added = added || k == UsagePromptTokens || k == UsageCompletionTokens
+	}
+	_, extraTotal := extra[UsageTotalTokens]
+	if _, ok := merged[UsageTotalTokens]; !ok || (added && !extraTotal) {
+		merged[UsageTotalTokens] = merged[UsagePromptTokens] + merged[UsageCompletionTokens]
+	}
+	return merged
+}
+
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
+
+// ModelPrice is the price of a model in USD per million tokens.
+// Zero cache prices mean cached tokens are billed at the input price, and a
+// zero reasoning price means reasoning tokens are billed at the output price.
+type ModelPrice = cost.Price
+
+var (
+	modelPricesMu sync.RWMutex
+	modelPrices   = make(cost.Table)
+)
+
+// RegisterModelPrice sets the price used to compute span costs for model,
+// overriding the built-in price from cost.Default. Spans whose model matches
+// a registered name, or starts with it followed by a hyphen (for dated
+// versions such as "claude-sonnet-4-20250514"), get an estimated cost and
+// cache savings when they end.
+func RegisterModelPrice(model string, price ModelPrice) {
+	modelPricesMu.Lock()
+	defer modelPricesMu.Unlock()
+	modelPrices[model] = price
+}
+
+// WithCostTable sets prices used to compute the cost of the client's spans,
+// in USD per million tokens. They override registered and built-in prices,
+// and a name qualified by its provider, such as "azure/gpt-4o", prices the
+// model at that provider only:
+//
+//	client, err := opik.NewClient(opik.WithCostTable(cost.Table{
+//	    "gpt-4o":       {Input: 2.25, Output: 9},  // negotiated discount
+//	    "azure/gpt-4o": {Input: 2.75, Output: 11}, // regional deployment
+//	}))
+//
+// Multiple calls add to the table.
+func WithCostTable(table cost.Table) Option {
+	return func(o *clientOptions) {
+		if o.costTable == nil {
+			o.costTable = make(cost.Table, len(table))
+		}
+		for name, price := range table {
+			if price.Input < 0 || price.Output < 0 || price.CacheRead < 0 || price.CacheWrite < 0 || price.Reasoning < 0 {
+				o.problems.Addf("cost table price for %q is negative", name)
+			}
+			o.costTable[name] = price
+		}
+	}
+}
+
+// LookupModelPrice returns the price for model: the registered price,
+// matching the longest registered name that equals model or prefixes it
+// before a hyphen, or else the built-in price.
+func LookupModelPrice(model string) (ModelPrice, bool) {
+	return lookupModelPrice("", model)
+}
+
+// lookupModelPrice returns the registered or built-in price of model at
+// provider, as cost.Table.Lookup.
+func lookupModelPrice(provider, model string) (ModelPrice, bool) {
+	modelPricesMu.RLock()
+	price, ok := modelPrices.Lookup(provider, model)
+	modelPricesMu.RUnlock()
+	if ok {
+		return price, true
+	}
+	return cost.Lookup(provider, model)
+}
+
+// modelPrice returns the price of model at provider, from the client's cost
+// table, the registered prices, or the built-in prices, in that order.
+func (c *Client) modelPrice(provider, model string) (ModelPrice, bool) {
+	if c != nil {
+		if price, ok := c.costTable.Lookup(provider, model); ok {
+			return price, true
+		}
+	}
+	return lookupModelPrice(provider, model)
+}
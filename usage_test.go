@@ -0,0 +1,369 @@
+package opik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/plexusone/opik-go/cost"
+)
+
+func TestNormalizeUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want map[string]int
+	}{
+		{
+			name: "openai",
+			raw:  `{"prompt_tokens": 100, "completion_tokens": 20, "total_tokens": 120}`,
+			want: map[string]int{"prompt_tokens": 100, "completion_tokens": 20, "total_tokens": 120},
+		},
+		{
+			name: "openai cached",
+			raw:  `{"prompt_tokens": 2000, "completion_tokens": 50, "total_tokens": 2050, "prompt_tokens_details": {"cached_tokens": 1536}}`,
+			want: map[string]int{"prompt_tokens": 2000, "completion_tokens": 50, "total_tokens": 2050, "cache_read_tokens": 1536},
+		},
+		{
+			name: "anthropic",
+			raw:  `{"input_tokens": 10, "output_tokens": 5}`,
+			want: map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+		},
+		{
+			name: "anthropic cached",
+			raw:  `{"input_tokens": 10, "output_tokens": 5, "cache_read_input_tokens": 1000, "cache_creation_input_tokens": 200}`,
+			want: map[string]int{"prompt_tokens": 1210, "completion_tokens": 5, "total_tokens": 1215, "cache_read_tokens": 1000, "cache_write_tokens": 200},
+		},
+		{
+			name: "empty",
+			raw:  `{}`,
+			want: map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw map[string]any
+			if err := json.Unmarshal([]byte(tt.raw), &raw); err != nil {
+				t.Fatal(err)
+			}
+			if got := NormalizeUsage(raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeUsage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelPriceCost(t *testing.T) {
+	price := ModelPrice{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75}
+	usage := map[string]int{
+		UsagePromptTokens:     1_210_000,
+		UsageCompletionTokens: 100_000,
+		UsageCacheReadTokens:  1_000_000,
+		UsageCacheWriteTokens: 200_000,
+	}
+
+	// 10k uncached * 3 + 1M cached * 0.3 + 200k written * 3.75 + 100k output * 15
+	want := 0.03 + 0.3 + 0.75 + 1.5
+	if got := price.Cost(usage); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Cost() = %v, want %v", got, want)
+	}
+
+	// Without caching: 1.21M * 3 + 100k * 15
+	wantSavings := 3.63 + 1.5 - want
+	if got := price.CacheSavings(usage); math.Abs(got-wantSavings) > 1e-9 {
+		t.Errorf("CacheSavings() = %v, want %v", got, wantSavings)
+	}
+
+	noCachePrice := ModelPrice{Input: 3, Output: 15}
+	if got := noCachePrice.CacheSavings(usage); math.Abs(got) > 1e-9 {
+		t.Errorf("CacheSavings() without cache prices = %v, want 0", got)
+	}
+}
+
+func TestLookupModelPrice(t *testing.T) {
+	RegisterModelPrice("test-model", ModelPrice{Input: 1})
+	RegisterModelPrice("test-model-large", ModelPrice{Input: 2})
+
+	tests := []struct {
+		model string
+		want  float64
+		found bool
+	}{
+		{"test-model", 1, true},
+		{"test-model-20250101", 1, true},
+		{"test-model-large-20250101", 2, true},
+		{"test-modelx", 0, false},
+		{"other", 0, false},
+	}
+	for _, tt := range tests {
+		price, ok := LookupModelPrice(tt.model)
+		if ok != tt.found || price.Input != tt.want {
+			t.Errorf("LookupModelPrice(%q) = %v, %v; want %v, %v", tt.model, price.Input, ok, tt.want, tt.found)
+		}
+	}
+}
+
+func TestSpanEndSendsUsageAndCost(t *testing.T) {
+	var mu sync.Mutex
+	var update map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			body, _ := io.ReadAll(r.Body)
+			var req struct {
+				Update map[string]any `json:"update"`
+			}
+			_ = json.Unmarshal(body, &req)
+			mu.Lock()
+			update = req.Update
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL), WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	RegisterModelPrice("priced-model", ModelPrice{Input: 3, Output: 15, CacheRead: 0.3})
+
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "trace")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	span, err := trace.Span(ctx, "llm", WithSpanModel("priced-model-2025"))
+	if err != nil {
+		t.Fatalf("Span error: %v", err)
+	}
+	span.SetUsage(map[string]int{UsagePromptTokens: 1_000_000, UsageCompletionTokens: 0, UsageCacheReadTokens: 1_000_000})
+	if err := span.End(ctx); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+
+	mu.Lock()
+	usage, _ := update["usage"].(map[string]any)
+	if usage["cache_read_tokens"] != float64(1_000_000) {
+		t.Errorf("usage = %v", update["usage"])
+	}
+	if cost, _ := update["total_estimated_cost"].(float64); math.Abs(cost-0.3) > 1e-9 {
+		t.Errorf("total_estimated_cost = %v, want 0.3", update["total_estimated_cost"])
+	}
+	var metadata map[string]any
+	if raw, ok := update["metadata"].(map[string]any); ok {
+		metadata = raw
+	}
+	if savings, _ := metadata["cache_savings_usd"].(float64); math.Abs(savings-2.7) > 1e-9 {
+		t.Errorf("cache_savings_usd = %v, want 2.7", metadata["cache_savings_usd"])
+	}
+	mu.Unlock()
+
+	span2, _ := trace.Span(ctx, "llm", WithSpanModel("priced-model"))
+	span2.SetUsage(map[string]int{UsagePromptTokens: 10})
+	span2.SetCost(1.25)
+	if err := span2.End(ctx); err != nil {
+		t.Fatalf("End error: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if update["total_estimated_cost"] != 1.25 {
+		t.Errorf("total_estimated_cost = %v, want explicit 1.25", update["total_estimated_cost"])
+	}
+}
+
+func TestSpanBuiltinCost(t *testing.T) {
+	ts, metadata := newUpdateServer()
+	defer ts.Close()
+
+	client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithCostTable(cost.Table{
+		"azure/gpt-4o": {Input: 5, Output: 20},
+	}))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+	trace, err := client.Trace(ctx, "trace")
+	if err != nil {
+		t.Fatalf("Trace error: %v", err)
+	}
+	usage := map[string]int{UsagePromptTokens: 1_000_000, UsageCompletionTokens: 1_000_000}
+
+	tests := []struct {
+		provider string
+		want     float64
+	}{
+		{"openai", 12.5}, // built-in price
+		{"azure", 25},    // client cost table
+	}
+	for _, tt := range tests {
+		span, err := trace.Span(ctx, "llm", WithSpanModel("gpt-4o-2024-08-06"), WithSpanProvider(tt.provider))
+		if err != nil {
+			t.Fatalf("Span error: %v", err)
+		}
+		span.SetUsage(usage)
+		if got, ok := span.EstimatedCost(); !ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: EstimatedCost = %v, %v; want %v", tt.provider, got, ok, tt.want)
+		}
+		if err := span.End(ctx); err != nil {
+			t.Fatalf("End error: %v", err)
+		}
+		if got, _ := metadata("/v1/private/spans/batch")[MetadataEstimatedCost].(float64); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: %s = %v, want %v", tt.provider, MetadataEstimatedCost, got, tt.want)
+		}
+	}
+
+	span, _ := trace.Span(ctx, "llm", WithSpanModel("unpriced-model"))
+	span.SetUsage(usage)
+	if _, ok := span.EstimatedCost(); ok {
+		t.Error("EstimatedCost reported a cost for a model without a price")
+	}
+}
+
+func TestWithCostTableNegative(t *testing.T) {
+	_, err := NewClient(WithURL("http://localhost"), WithAPIKey("test-key"), WithCostTable(cost.Table{"m": {Input: -1}}))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("NewClient error = %v, want ValidationError", err)
+	}
+}
+
+func TestNormalizeUsageReasoningTokens(t *testing.T) {
+	chat := NormalizeUsage(map[string]any{
+		"prompt_tokens":             float64(100),
+		"completion_tokens":         float64(500),
+		"completion_tokens_details": map[string]any{"reasoning_tokens": float64(384)},
+	})
+	if chat[UsageReasoningTokens] != 384 || chat[UsageCompletionTokens] != 500 {
+		t.Errorf("chat completions usage = %v", chat)
+	}
+
+	responses := NormalizeUsage(map[string]any{
+		"input_tokens":          float64(100),
+		"output_tokens":         float64(500),
+		"output_tokens_details": map[string]any{"reasoning_tokens": float64(200)},
+	})
+	if responses[UsageReasoningTokens] != 200 || responses[UsageTotalTokens] != 600 {
+		t.Errorf("responses usage = %v", responses)
+	}
+}
+
+func TestModelPriceReasoningCost(t *testing.T) {
+	usage := map[string]int{UsagePromptTokens: 1_000_000, UsageCompletionTokens: 1_000_000, UsageReasoningTokens: 400_000}
+
+	// Reasoning tokens are billed at the output price by default.
+	p := ModelPrice{Input: 1, Output: 4}
+	if got := p.Cost(usage); math.Abs(got-5) > 1e-9 {
+		t.Errorf("Cost = %v, want 5", got)
+	}
+	if got := p.ReasoningCost(usage); math.Abs(got-1.6) > 1e-9 {
+		t.Errorf("ReasoningCost = %v, want 1.6", got)
+	}
+
+	p.Reasoning = 10
+	if got := p.Cost(usage); math.Abs(got-(1+2.4+4)) > 1e-9 {
+		t.Errorf("Cost = %v, want 7.4", got)
+	}
+	if got := p.CacheSavings(usage); math.Abs(got) > 1e-9 {
+		t.Errorf("CacheSavings = %v, want 0 without cached tokens", got)
+	}
+}
+
+func TestSpanReasoningCapture(t *testing.T) {
+	ts, metadata := newUpdateServer()
+	defer ts.Close()
+	RegisterModelPrice("reasoning-model", ModelPrice{Input: 1, Output: 4})
+
+	for _, capture := range []bool{false, true} {
+		client, err := NewClient(WithURL(ts.URL()), WithAPIKey("test-key"), WithReasoningCapture(capture))
+		if err != nil {
+			t.Fatalf("NewClient error: %v", err)
+		}
+		ctx := context.Background()
+		trace, err := client.Trace(ctx, "trace")
+		if err != nil {
+			t.Fatalf("Trace error: %v", err)
+		}
+		span, err := trace.Span(ctx, "llm", WithSpanModel("reasoning-model"))
+		if err != nil {
+			t.Fatalf("Span error: %v", err)
+		}
+		span.SetUsage(map[string]int{UsagePromptTokens: 10, UsageCompletionTokens: 1_000_000, UsageReasoningTokens: 500_000})
+		span.SetReasoning("The user wants a haiku.")
+		if err := span.End(ctx); err != nil {
+			t.Fatalf("End error: %v", err)
+		}
+
+		m := metadata("/v1/private/spans/batch")
+		if capture {
+			if m[MetadataReasoningSummary] != "The user wants a haiku." || m[MetadataReasoningRedacted] != nil {
+				t.Errorf("capture enabled: metadata = %v", m)
+			}
+		} else if m[MetadataReasoningSummary] != nil || m[MetadataReasoningRedacted] != true {
+			t.Errorf("capture disabled: metadata = %v", m)
+		}
+		if cost, _ := m["reasoning_cost_usd"].(float64); math.Abs(cost-2) > 1e-9 {
+			t.Errorf("reasoning_cost_usd = %v, want 2", m["reasoning_cost_usd"])
+		}
+	}
+}
+
+func TestHeaderUsageExtractor(t *testing.T) {
+	extractor := HeaderUsageExtractor(map[string]string{
+		"X-Usage-Prompt-Tokens":     UsagePromptTokens,
+		"X-Usage-Completion-Tokens": UsageCompletionTokens,
+		"X-Usage-Cached-Tokens":     UsageCacheReadTokens,
+	})
+	resp := &http.Response{
+		Header:  http.Header{"X-Usage-Prompt-Tokens": {"120"}, "X-Usage-Cached-Tokens": {"many"}},
+		Trailer: http.Header{"X-Usage-Completion-Tokens": {" 30 "}},
+	}
+	got := extractor.ExtractUsage(resp)
+	want := map[string]int{UsagePromptTokens: 120, UsageCompletionTokens: 30}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractUsage = %v, want %v", got, want)
+	}
+}
+
+func TestMergeUsage(t *testing.T) {
+	tests := []struct {
+		name         string
+		usage, extra map[string]int
+		want         map[string]int
+	}{
+		{
+			name:  "no extra",
+			usage: map[string]int{UsagePromptTokens: 1, UsageTotalTokens: 1},
+			want:  map[string]int{UsagePromptTokens: 1, UsageTotalTokens: 1},
+		},
+		{
+			name:  "body omits usage",
+			extra: map[string]int{UsagePromptTokens: 100, UsageCompletionTokens: 20},
+			want:  map[string]int{UsagePromptTokens: 100, UsageCompletionTokens: 20, UsageTotalTokens: 120},
+		},
+		{
+			name:  "body takes precedence",
+			usage: map[string]int{UsagePromptTokens: 100, UsageCompletionTokens: 20, UsageTotalTokens: 120},
+			extra: map[string]int{UsagePromptTokens: 99, UsageCacheReadTokens: 64},
+			want:  map[string]int{UsagePromptTokens: 100, UsageCompletionTokens: 20, UsageTotalTokens: 120, UsageCacheReadTokens: 64},
+		},
+		{
+			name:  "total recomputed",
+			usage: map[string]int{UsagePromptTokens: 100, UsageTotalTokens: 100},
+			extra: map[string]int{UsageCompletionTokens: 20},
+			want:  map[string]int{UsagePromptTokens: 100, UsageCompletionTokens: 20, UsageTotalTokens: 120},
+		},
+	}
+	for _, tt := range tests {
+		if got := MergeUsage(tt.usage, tt.extra); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: MergeUsage = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
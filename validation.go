@@ -0,0 +1,51 @@
+package opik
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/plexusone/opik-go/evaluation"
+)
+
+// ValidationError lists every problem found in a client's options, so they
+// can all be fixed at once rather than one per attempt. It is the
+// evaluation package's ValidationError: for the client, it matches
+// ErrInvalidInput with errors.Is, as well as each problem it holds, such
+// as ErrMissingURL.
+type ValidationError = evaluation.ValidationError
+
+// invalidOptions returns a ValidationError holding the problems, or nil if
+// there are none.
+func invalidOptions(p evaluation.Problems) error {
+	return p.ErrWithPrefix("opik: invalid options", ErrInvalidInput)
+}
+
+// validate checks the options once they have all been applied, and returns
+// a ValidationError listing every problem found.
+func (o *clientOptions) validate() error {
+	p := o.problems
+	p.Add("", o.config.Validate())
+	p = append(p, o.config.tracingProblems()...)
+	if o.config.URL != "" {
+		if u, err := url.Parse(o.config.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			p.Addf("URL %q is not an absolute http or https URL", o.config.URL)
+		}
+	}
+	if o.timeout < 0 {
+		p.Addf("timeout must not be negative: %v", o.timeout)
+	}
+	for _, key := range o.indexedMetadata {
+		if strings.TrimSpace(key) == "" {
+			p.Addf("indexed metadata keys must not be empty")
+			break
+		}
+	}
+	if o.projectRoutesSet > 1 {
+		p.Addf("project routes are set %d times; WithProjectRoutes and WithCanaryProject replace each other", o.projectRoutesSet)
+	}
+	p = append(p, projectRouteProblems(o.projectRoutes)...)
+	if o.canaryProject != "" && o.canaryProject == o.config.ProjectName {
+		p.Addf("canary project %q is the default project", o.canaryProject)
+	}
+	return invalidOptions(p)
+}
@@ -0,0 +1,60 @@
+package opik
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewClientValidation(t *testing.T) {
+	_, err := NewClient(
+		WithConfig(nil),
+		WithURL(""),
+		WithTimeout(-1),
+		WithIndexedMetadata("tenant", " "),
+		WithProjectName("main"),
+		WithProjectRoutes(ProjectRoute{Project: "a", Weight: 1}),
+		WithCanaryProject("main", 1.5),
+	)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("NewClient error = %v, want *ValidationError", err)
+	}
+	want := []string{
+		"WithConfig: config is nil",
+		"missing API URL",
+		"timeout must not be negative",
+		"indexed metadata keys must not be empty",
+		"project routes are set 2 times",
+		"canary project \"main\" is the default project",
+		"canary fraction must be between 0 and 1: 1.5",
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("error does not mention %q:\n%v", w, err)
+		}
+	}
+	if len(verr.Problems) != len(want) {
+		t.Errorf("got %d problems, want %d:\n%v", len(verr.Problems), len(want), err)
+	}
+	if !errors.Is(err, ErrInvalidInput) || !errors.Is(err, ErrMissingURL) {
+		t.Errorf("errors.Is(ErrInvalidInput, ErrMissingURL) = %v, %v, want true", errors.Is(err, ErrInvalidInput), errors.Is(err, ErrMissingURL))
+	}
+}
+
+func TestNewClientValidationSingleProblem(t *testing.T) {
+	_, err := NewClient(WithURL("localhost:5173"), WithAPIKey("k"))
+	if err == nil {
+		t.Fatal("NewClient accepted a URL without a scheme")
+	}
+	if want := `opik: invalid options: URL "localhost:5173" is not an absolute http or https URL`; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestNewClientValidOptions(t *testing.T) {
+	_, err := NewClient(WithURL("http://localhost:5173/api"), WithTimeout(0), WithCanaryProject("canary", 0.1))
+	if err != nil {
+		t.Errorf("NewClient error = %v, want nil", err)
+	}
+}